@@ -0,0 +1,202 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/gen"
+	"go-skeleton/pkg/migrate"
+	"go-skeleton/pkg/seed"
+	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/services"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 命令行任务，eg: ./go-skeleton search:reindex
+type command struct {
+	desc string
+	run  func(ctx context.Context, args []string) error
+}
+
+var commands = map[string]command{
+	"search:reindex": {
+		desc: "重建文章搜索索引",
+		run: func(ctx context.Context, args []string) error {
+			total, err := services.SearchService.ReindexAll(ctx)
+			fmt.Printf("已索引%d篇文章\n", total)
+			return err
+		},
+	},
+	"migrate": {
+		desc: "数据库迁移，up | down [n] | status | create {name}",
+		run:  runMigrate,
+	},
+	"gen": {
+		desc: "根据模型生成dao、service、控制器，{Model} [--force]",
+		run: func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("usage: gen {Model} [--force]")
+			}
+			m, err := gen.Parse("model", args[0])
+			if err != nil {
+				return err
+			}
+			paths, err := gen.Generate(".", m, len(args) > 1 && args[1] == "--force")
+			for _, p := range paths {
+				fmt.Println("生成", p)
+			}
+			if err != nil {
+				return err
+			}
+			routes, err := gen.Routes(m)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\n在router/admin_router.go中添加路由:\n\n%s\n", routes)
+			return nil
+		},
+	},
+	"seed": {
+		desc: "加载测试数据，[--fresh [--force]] [文件或目录...]，默认为fixtures目录，release模式下--fresh需要--force",
+		run: func(ctx context.Context, args []string) error {
+			var fresh, force bool
+			for len(args) > 0 && (args[0] == "--fresh" || args[0] == "--force") {
+				fresh = fresh || args[0] == "--fresh"
+				force = force || args[0] == "--force"
+				args = args[1:]
+			}
+			// 避免误清空生产数据
+			if fresh && !force && config.Conf.AppMode == gin.ReleaseMode {
+				return fmt.Errorf("seed --fresh truncates tables, use --force in %s mode", gin.ReleaseMode)
+			}
+			if len(args) == 0 {
+				args = []string{"fixtures"}
+			}
+			l := seed.NewLoader(simpleDb.DB())
+			for _, p := range args {
+				if err := l.Add(p); err != nil {
+					return err
+				}
+			}
+			if fresh {
+				if err := l.Truncate(); err != nil {
+					return err
+				}
+			}
+			if err := l.Load(); err != nil {
+				return err
+			}
+			fmt.Println("测试数据加载完成")
+			return nil
+		},
+	},
+	"encrypt:rotate": {
+		desc: "使用当前密钥重新加密手机号等加密字段，轮换密钥或加密旧数据时执行",
+		run: func(ctx context.Context, args []string) error {
+			total, err := services.EncryptService.Rotate(ctx)
+			fmt.Printf("已重新加密%d行\n", total)
+			return err
+		},
+	},
+	"shard:create": {
+		desc: "预先创建database.Shards中配置的分表，[months]按月分表创建的月数，默认3",
+		run:  runShardCreate,
+	},
+}
+
+// runShardCreate 创建当前月及之后months个月的按月分表，以及所有hash分表，已存在的跳过
+func runShardCreate(ctx context.Context, args []string) error {
+	months := 3
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid months: %s", args[0])
+		}
+		months = n
+	}
+	db := simpleDb.DB().WithContext(ctx)
+	for _, r := range simpleDb.Shards() {
+		created, err := simpleDb.CreateShardTables(db, r, r.Upcoming(months))
+		for _, table := range created {
+			fmt.Println("创建", table)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runMigrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		args = []string{"up"}
+	}
+	db := simpleDb.DB()
+	switch args[0] {
+	case "up":
+		if err := migrate.Up(db); err != nil {
+			return err
+		}
+		fmt.Println("迁移完成")
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid steps: %s", args[1])
+			}
+			steps = n
+		}
+		if err := migrate.Down(db, steps); err != nil {
+			return err
+		}
+		fmt.Println("回滚完成")
+	case "status":
+		states, err := migrate.Status(db)
+		if err != nil {
+			return err
+		}
+		for _, s := range states {
+			status := "pending"
+			if s.Applied {
+				status = "applied"
+			}
+			fmt.Printf("  %-8s %s\n", status, s.ID)
+		}
+	case "create":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: migrate create {name}")
+		}
+		up, down, err := migrate.Create(config.Conf.MigrateConfig.Dir, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println(up)
+		fmt.Println(down)
+	default:
+		return fmt.Errorf("unknown migrate action: %s", args[0])
+	}
+	return nil
+}
+
+// RunCommand 执行命令行任务，未知命令时输出可用的命令
+func RunCommand(name string, args []string) error {
+	cmd, ok := commands[name]
+	if !ok {
+		names := make([]string, 0, len(commands))
+		for n := range commands {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		fmt.Fprintln(os.Stderr, "可用的命令:")
+		for _, n := range names {
+			fmt.Fprintf(os.Stderr, "  %-20s %s\n", n, commands[n].desc)
+		}
+		return fmt.Errorf("unknown command: %s", name)
+	}
+	return cmd.run(context.Background(), args)
+}
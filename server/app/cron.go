@@ -1,83 +1,63 @@
 package app
 
 import (
-	"context"
-	"fmt"
-	"go-skeleton/pkg/gredis"
-	"time"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/cron"
+	"go-skeleton/pkg/outbox"
+	"go-skeleton/services"
 
-	"github.com/bsm/redislock"
-
-	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
 )
 
-type Logger struct {
-}
-
-func startSchedule() {
-	//SkipIfStillRunning为前面任务没执行完，则跳过当前任务,分布式的时候还是需要分布式锁
-	c := cron.New(
-		cron.WithSeconds(),
-		cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+// registerCronJobs 注册定时任务，执行时间可以在配置文件cron.Jobs中按名称覆盖，
+// 默认使用分布式锁，负载均衡部署时同一时间只有一个实例执行
+func registerCronJobs() {
+	//cron.Register("demo", "*/10 * * * * *", func(ctx context.Context) error {
+	//	fmt.Println("----计划任务执行---")
+	//	//模拟长时间执行
+	//	time.Sleep(time.Second * 20)
+	//	return nil
+	//})
 
-	//c.AddFunc("1/2 * * * * ?", func() {
-	//	fmt.Println("2s一次:", carbon.Now().ToDateTimeString())
-	//	time.Sleep(time.Second * 10)
+	//cron.Register("exchange_code", "0/5 * * * * ?", func(ctx context.Context) error {
+	//	return services.CourseExchangeCodeService.GenerateCodeFromRedis()
 	//})
 
-	//加锁保证多机器只执行一次
-	addCronFunc(c, "* * * * * ?", func() {
-		redis := gredis.GetRedis()
-		locker := redislock.New(redis)
-		ctx := context.Background()
-		//互斥锁
-		lock, err := locker.Obtain(ctx, "my_key", time.Minute, nil)
-		if err != nil {
-			fmt.Println("----未获得锁，计划任务不执行----", err)
-			return
-		}
-		defer func() {
-			_ = lock.Release(ctx)
-		}()
-		fmt.Println("----计划任务执行---")
-		//模拟长时间执行
-		time.Sleep(time.Second * 20)
-		return
+	// 清理已发送的outbox事件
+	if config.Conf.OutboxConfig.Enable {
+		cron.Register("outbox.cleanup", "0 30 3 * * *", outbox.Cleanup)
+	}
 
-		//nx, _ := gredis.GetRedis().SetNX(context.TODO(), "test_cron", 1, time.Minute).Result()
-		//fmt.Println("锁结果", nx)
-		//if nx {
-		//	defer func() {
-		//		fmt.Println("15s后，执行成功释放锁", nx)
-		//		gredis.GetRedis().Del(context.TODO(), "test_cron")
-		//	}()
-		//	fmt.Println("计划任务执行")
-		//} else {
-		//	fmt.Println("----计划任务不执行----")
-		//}
-		//return
-	})
+	// 刷新后台仪表盘统计缓存
+	cron.Register("dashboard.refresh", "0 */5 * * * *", services.DashboardService.Refresh)
 
-	//addCronFunc(c , "0/5 * * * * ?" , func() {
-	//	course := services.CourseExchangeCodeService
-	//	_ = course.GenerateCodeFromRedis()
-	//})
+	// 点赞、收藏的计数增量同步到数据库
+	cron.Register("reaction.sync", "0 * * * * *", services.ReactionService.Sync)
 
-	//addCronFunc(c, "@every 30m", func() {
-	//})
+	// 文章浏览量增量批量写入数据库
+	cron.Register("view.flush", "30 * * * * *", services.ViewService.Flush)
+
+	// 计算文章热度排行
+	cron.Register("ranking.compute", "0 */10 * * * *", services.RankingService.Compute)
+
+	// 发布到时间的定时发布文章，任务队列的兜底
+	cron.Register("article.publishScheduled", "15 * * * * *", services.ScheduleService.PublishDue)
+
+	// 清理文章历史版本
+	cron.Register("revision.prune", "0 30 3 * * *", services.RevisionService.Prune)
 
 	// Generate sitemap
-	//addCronFunc(c, "0 0 4 ? * *", func() {
+	//cron.Register("sitemap", "0 0 4 ? * *", func(ctx context.Context) error {
+	//	return nil
 	//})
-
-	c.Start()
 }
 
-//如果是负载均衡部署，cmd这里添加的计划任务都应该用redis或者etcd等加锁，防止任务重复执行
-func addCronFunc(c *cron.Cron, sepc string, cmd func()) {
-	_, err := c.AddFunc(sepc, cmd)
-	if err != nil {
-		zap.L().Error("添加计划任务失败", zap.NamedError("error:", err))
+func startSchedule() {
+	registerCronJobs()
+	if err := cron.Start(); err != nil {
+		zap.L().Error("启动计划任务失败", zap.Error(err))
+		return
 	}
+	//停止调度并等待执行中的任务完成
+	OnShutdown("cron", cron.Stop)
 }
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"go-skeleton/logger"
+	"go-skeleton/middleware"
+	"go-skeleton/pkg/apiversion"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/upload"
+	"go-skeleton/router"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewHandler 创建gin引擎，未带版本的/api请求按请求头改写到对应的版本
+func NewHandler() http.Handler {
+	return apiversion.Negotiate(NewEngine(), "api", config.Conf.ApiVersionConfig.DefaultVersion)
+}
+
+// NewEngine 创建gin引擎，加载中间件和路由
+func NewEngine() *gin.Engine {
+	gin.SetMode(config.Conf.ServerConfig.AppMode)
+	r := gin.New()
+	//客户端ip由RealIP按可信代理解析
+	r.ForwardedByClientIP = false
+	r.StaticFS("/upload/images", http.Dir(upload.GetImageFullPath()))
+	if s, err := upload.GetStorage(); err == nil {
+		if local, ok := s.(*upload.LocalStorage); ok {
+			r.StaticFS("/upload/files", http.Dir(local.Root()))
+		}
+	}
+
+	r.Use(middleware.RealIPFromConfig())
+	r.Use(middleware.Metrics(), middleware.Tracing(config.Conf.TraceConfig.ServiceName), middleware.RequestID(), middleware.BodyLimitFromConfig(), middleware.I18n(), middleware.Timezone(), middleware.GeoIP(), middleware.FeatureFlags(), middleware.Experiments())
+	r.Use(logger.GinLogger(), logger.GinRecovery(true), middleware.ErrorHandler())
+	//租户在跨域之后解析，预检请求由Cors处理，解析时的panic由GinRecovery处理
+	r.Use(middleware.Cors(), middleware.Tenant(), middleware.RateLimitFromConfig(), middleware.CompressFromConfig(), middleware.ETagFromConfig())
+
+	//前端静态资源，优先返回构建时预压缩的文件
+	if cfg := config.Conf.CompressConfig; cfg.StaticDir != "" && cfg.StaticPrefix != "" {
+		static := middleware.PrecompressedStatic(cfg.StaticDir)
+		r.GET(strings.TrimRight(cfg.StaticPrefix, "/")+"/*filepath", static)
+		r.HEAD(strings.TrimRight(cfg.StaticPrefix, "/")+"/*filepath", static)
+	}
+
+	//加载路由
+	router.LoadDefault(r)
+	router.LoadAdminRouter(r)
+	router.LoadApiRouter(r)
+	return r
+}
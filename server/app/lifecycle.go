@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/health"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 默认的优雅关闭超时时间
+const defaultShutdownTimeout = 5 * time.Second
+
+// shutdownHook 关闭时执行的回调
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+var (
+	hooksMu sync.Mutex
+	hooks   []shutdownHook
+)
+
+// OnShutdown 注册关闭回调，按注册的相反顺序执行，
+// 先初始化的资源(数据库、redis)后关闭，保证关闭任务时依赖的资源仍可用
+func OnShutdown(name string, fn func(ctx context.Context) error) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, shutdownHook{name: name, fn: fn})
+}
+
+// Run 启动http服务，收到SIGINT/SIGTERM后等待处理中的请求完成，再依次执行关闭回调
+func Run(handler http.Handler) error {
+	cfg := config.Conf.ServerConfig
+	server := &http.Server{
+		Addr:           fmt.Sprintf(":%s", cfg.HttpPort),
+		Handler:        handler,
+		ReadTimeout:    cfg.ReadTimeout * time.Second,
+		WriteTimeout:   cfg.WriteTimeout * time.Second,
+		MaxHeaderBytes: 1 << 20, //1048576 = 1mb
+	}
+	zap.L().Info(fmt.Sprintf("Listening and serving HTTP on %s", server.Addr))
+
+	errCh := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+
+	// kill 默认会发送 syscall.SIGTERM 信号，Ctrl+C 发送 syscall.SIGINT 信号
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	var serveErr error
+	select {
+	case serveErr = <-errCh:
+		zap.L().Error("http server stopped", zap.Error(serveErr))
+	case sig := <-quit:
+		zap.L().Info("Shutdown Server ...", zap.String("signal", sig.String()))
+	}
+
+	health.MarkShuttingDown()
+	// 就绪检查失败后继续处理请求，等负载均衡摘除实例后再关闭监听
+	if delay := cfg.ShutdownDelay * time.Second; delay > 0 && serveErr == nil {
+		zap.L().Info("waiting for load balancer to drain", zap.Duration("delay", delay))
+		time.Sleep(delay)
+	}
+
+	timeout := cfg.ShutdownTimeout * time.Second
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// 停止接收新请求，等待处理中的请求完成
+	if err := server.Shutdown(ctx); err != nil {
+		zap.L().Error("Server Shutdown", zap.Error(err))
+	}
+	Shutdown(ctx)
+	zap.L().Info("Server exiting")
+	_ = zap.L().Sync()
+	return serveErr
+}
+
+// Shutdown 按注册的相反顺序执行关闭回调，单个回调失败不影响后续回调
+func Shutdown(ctx context.Context) {
+	hooksMu.Lock()
+	list := hooks
+	hooks = nil
+	hooksMu.Unlock()
+
+	for i := len(list) - 1; i >= 0; i-- {
+		h := list[i]
+		start := time.Now()
+		if err := h.fn(ctx); err != nil {
+			zap.L().Error("shutdown hook failed", zap.String("name", h.name), zap.Error(err))
+			continue
+		}
+		zap.L().Info("shutdown hook done", zap.String("name", h.name), zap.Duration("cost", time.Since(start)))
+	}
+}
@@ -3,8 +3,8 @@ package app
 import "go-skeleton/pkg/config"
 
 func StartOn() {
-	//本地无需定时任务，如果需要打开即可
-	if config.Conf.ServerConfig.AppMode == "debug" {
+	//本地无需定时任务，需要时在配置文件中打开cron.Enable即可
+	if !config.Conf.CronConfig.Enable {
 		return
 	}
 
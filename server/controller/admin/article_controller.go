@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"go-skeleton/model"
 	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
 	"go-skeleton/pkg/gcache"
 	"go-skeleton/pkg/gredis"
 	"go-skeleton/pkg/jsonresult"
 	"go-skeleton/pkg/queue"
+	"go-skeleton/pkg/resp"
 	"go-skeleton/pkg/simpleDb"
 	"go-skeleton/pkg/upload"
 	"go-skeleton/services"
@@ -49,7 +51,7 @@ func (a *ArticleController) GetArticle(c *gin.Context) {
 			TTL:   60,
 			Do: func(item *cache.Item) (interface{}, error) {
 				serv := services.ArticleService
-				articleInfo, err = serv.GetArticleById(id)
+				articleInfo, err = serv.GetArticleById(c.Request.Context(), id)
 				return articleInfo, nil
 			},
 		})
@@ -59,7 +61,7 @@ func (a *ArticleController) GetArticle(c *gin.Context) {
 	data, err := gredis.GetRedis().Get(context.TODO(), key).Result()
 	if data == "" || err != nil {
 		serv := services.ArticleService
-		articleInfo, err = serv.GetArticleById(id)
+		articleInfo, err = serv.GetArticleById(c.Request.Context(), id)
 		if articleInfo != (model.Article{}) {
 			cacheValue, _ := json.Marshal(articleInfo)
 			_ = gredis.GetRedis().Set(context.TODO(), key, cacheValue, 60*60*time.Second).Err()
@@ -108,7 +110,7 @@ func (a *ArticleController) GetArticleList(c *gin.Context) {
 
 	params := simpleDb.NewQueryParams(c)
 	params.LikeByReq("title").PageByReq().Desc("id")
-	articleList3, paging := services.ArticleService.FindPageByParams(params)
+	articleList3, paging := services.ArticleService.FindPageByParams(c.Request.Context(), params)
 
 	//pageSize, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	//page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -130,12 +132,70 @@ func (a *ArticleController) GetArticleList(c *gin.Context) {
 	})
 }
 
+// @Tags 后台-文章
+// @Summary 回收站文章列表
+// @Param title query string false "标题"
+// @Param page query int false "页码"
+// @Param limit query int false "每页条数"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=simpleDb.PageResult{results=[]model.Article}}
+// @Router /admin/article/trash [get]
+func (a *ArticleController) GetTrashList(c *gin.Context) {
+	params := simpleDb.NewQueryParams(c)
+	params.LikeByReq("title").PageByReq().Desc("deleted_at")
+	params.OnlyTrashed()
+	list, paging := services.ArticleService.FindPageByParams(c.Request.Context(), params)
+	resp.Page(c, list, paging)
+}
+
+// 删除文章，放入回收站
+func (a *ArticleController) DeleteArticle(c *gin.Context) {
+	id, err := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if err := services.ArticleService.Delete(c.Request.Context(), id); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// 从回收站恢复文章
+func (a *ArticleController) RestoreArticle(c *gin.Context) {
+	id, err := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if err := services.ArticleService.Restore(c.Request.Context(), id); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// 彻底删除文章
+func (a *ArticleController) ForceDeleteArticle(c *gin.Context) {
+	id, err := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if err := services.ArticleService.ForceDelete(c.Request.Context(), id); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
 func (a *ArticleController) SearchArticle(c *gin.Context) {
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	title := c.Query("title")
 
-	list, total, err := services.ArticleService.SearchArticle2(title, pageSize, page)
+	list, total, err := services.ArticleService.SearchArticle2(c.Request.Context(), title, pageSize, page)
 	fmt.Printf("%+v\n\n", err)
 	c.JSON(http.StatusOK, gin.H{
 		"list":  list,
@@ -186,7 +246,7 @@ func (a *ArticleController) EditArticle(c *gin.Context) {
 	jsonStr, _ := json.Marshal(artForm)
 	var art model.Article
 	json.Unmarshal(jsonStr, &art)
-	result, err := services.ArticleService.EditArticle(int(art.ID), &art)
+	result, err := services.ArticleService.EditArticle(c.Request.Context(), int(art.ID), &art)
 	c.JSON(http.StatusOK, gin.H{
 		"article": artForm,
 		"artJson": string(jsonStr),
@@ -229,8 +289,8 @@ func (a *ArticleController) MyChan(c *gin.Context) {
 
 	id1, _ := strconv.Atoi(c.DefaultQuery("id1", "3"))
 	id2, _ := strconv.Atoi(c.DefaultQuery("id2", "4"))
-	art1Chan := a.getArt(id1)
-	art2Chan := a.getArt(id2)
+	art1Chan := a.getArt(c.Request.Context(), id1)
+	art2Chan := a.getArt(c.Request.Context(), id2)
 
 	art1 := <-art1Chan
 	art2 := <-art2Chan
@@ -247,11 +307,11 @@ func (a *ArticleController) MyChan(c *gin.Context) {
 	//context.WithValue(ctx, "anc", "aaaa")
 }
 
-func (a *ArticleController) getArt(id int) <-chan model.Article {
+func (a *ArticleController) getArt(ctx context.Context, id int) <-chan model.Article {
 	artChan := make(chan model.Article)
 	go func(id int) {
 
-		art, _ := services.ArticleService.GetArticleById(id)
+		art, _ := services.ArticleService.GetArticleById(ctx, id)
 		artChan <- art
 	}(id)
 	return artChan
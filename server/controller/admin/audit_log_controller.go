@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogController struct {
+}
+
+// 审计日志支持的过滤条件，时间为unix秒
+var auditLogFilter = simpleDb.FilterSpec{
+	Fields: map[string][]simpleDb.FilterOp{
+		"actor_id":   {simpleDb.OpEq, simpleDb.OpIn},
+		"entity":     {simpleDb.OpEq},
+		"entity_id":  {simpleDb.OpEq},
+		"action":     {simpleDb.OpEq, simpleDb.OpIn},
+		"created_at": {simpleDb.OpGte, simpleDb.OpLte, simpleDb.OpBetween},
+	},
+	Sorts:       []string{"id"},
+	DefaultSort: "-id",
+}
+
+// @Tags 后台-审计日志
+// @Summary 审计日志列表
+// @Param actor_id query int false "操作人id"
+// @Param entity query string false "表名，eg: article"
+// @Param entity_id query string false "数据主键"
+// @Param action query string false "create | update | delete"
+// @Param created_at_gte query int false "开始时间"
+// @Param created_at_lte query int false "结束时间"
+// @Param page query int false "页码"
+// @Param limit query int false "每页条数"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=simpleDb.PageResult{results=[]model.AuditLog}}
+// @Router /admin/audit/list [get]
+func (a *AuditLogController) List(c *gin.Context) {
+	cnd, err := simpleDb.NewSqlCndFromQuery(c, auditLogFilter)
+	if err != nil {
+		resp.Error(c, errors.NewError(errors.InvalidParamsError.Code, err.Error()))
+		return
+	}
+	list, paging := services.AuditLogService.FindPageByCnd(c.Request.Context(), cnd)
+	resp.Page(c, list, paging)
+}
+
+// @Tags 后台-审计日志
+// @Summary 审计日志详情
+// @Param id query int true "日志id"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=model.AuditLog}
+// @Router /admin/audit/info [get]
+func (a *AuditLogController) Info(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Query("id"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	log := services.AuditLogService.Get(c.Request.Context(), id)
+	if log == nil {
+		resp.Error(c, errors.NotFoundError)
+		return
+	}
+	resp.OK(c, log)
+}
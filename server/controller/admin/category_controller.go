@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryController struct {
+}
+
+// @Tags 后台-分类
+// @Summary 分类列表，包含已发布的文章数
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=[]services.CategoryCount}
+// @Router /admin/category/list [get]
+func (cc *CategoryController) List(c *gin.Context) {
+	resp.OK(c, services.CategoryService.List(c.Request.Context()))
+}
+
+// @Tags 后台-分类
+// @Summary 创建分类
+// @Param name formData string true "名称"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=model.Category}
+// @Router /admin/category/create [post]
+func (cc *CategoryController) Create(c *gin.Context) {
+	category, err := services.CategoryService.Create(c.Request.Context(), c.PostForm("name"))
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, category)
+}
+
+// @Tags 后台-分类
+// @Summary 修改分类
+// @Param id formData int true "分类id"
+// @Param name formData string true "名称"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /admin/category/update [post]
+func (cc *CategoryController) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.PostForm("id"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if err := services.CategoryService.Update(c.Request.Context(), id, c.PostForm("name")); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// @Tags 后台-分类
+// @Summary 删除分类，分类下还有文章时不能删除
+// @Param id formData int true "分类id"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /admin/category/delete [post]
+func (cc *CategoryController) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.PostForm("id"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if err := services.CategoryService.Delete(c.Request.Context(), id); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
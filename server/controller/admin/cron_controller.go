@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"go-skeleton/pkg/cron"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CronController struct {
+}
+
+// 定时任务列表及运行状态
+func (cc *CronController) List(c *gin.Context) {
+	resp.OK(c, cron.List())
+}
+
+// 立即执行一次
+func (cc *CronController) Trigger(c *gin.Context) {
+	cc.do(c, cron.Trigger)
+}
+
+// 暂停任务，只影响当前实例
+func (cc *CronController) Pause(c *gin.Context) {
+	cc.do(c, cron.Pause)
+}
+
+// 恢复任务
+func (cc *CronController) Resume(c *gin.Context) {
+	cc.do(c, cron.Resume)
+}
+
+func (cc *CronController) do(c *gin.Context, fn func(name string) error) {
+	if err := fn(c.PostForm("name")); err != nil {
+		if err == cron.ErrJobNotFound {
+			resp.Error(c, errors.NotFoundError)
+			return
+		}
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DashboardController struct {
+}
+
+// @Tags 后台-仪表盘
+// @Summary 统计概览，数据由定时任务每5分钟刷新
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=services.DashboardStats}
+// @Router /admin/dashboard/overview [get]
+func (d *DashboardController) Overview(c *gin.Context) {
+	stats, err := services.DashboardService.Stats(c.Request.Context())
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, stats)
+}
+
+// @Tags 后台-仪表盘
+// @Summary 每日新增文章、用户、评论数
+// @Param days query int false "最近几天，默认7，最大30"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=[]services.DashboardDaily}
+// @Router /admin/dashboard/daily [get]
+func (d *DashboardController) Daily(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days <= 0 || days > services.DashboardDays {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	stats, err := services.DashboardService.Stats(c.Request.Context())
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	daily := stats.Daily
+	if len(daily) > days {
+		daily = daily[len(daily)-days:]
+	}
+	resp.OK(c, daily)
+}
+
+// @Tags 后台-仪表盘
+// @Summary 各表行数
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=map[string]int64}
+// @Router /admin/dashboard/totals [get]
+func (d *DashboardController) Totals(c *gin.Context) {
+	stats, err := services.DashboardService.Stats(c.Request.Context())
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, stats.Totals)
+}
+
+// @Tags 后台-仪表盘
+// @Summary 最近的审计日志
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=[]model.AuditLog}
+// @Router /admin/dashboard/audit [get]
+func (d *DashboardController) Audit(c *gin.Context) {
+	stats, err := services.DashboardService.Stats(c.Request.Context())
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, stats.AuditLogs)
+}
@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"go-skeleton/model"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeatureFlagController struct {
+}
+
+type featureFlagForm struct {
+	Name        string `form:"name" json:"name" binding:"required"`
+	Description string `form:"description" json:"description"`
+	Enabled     bool   `form:"enabled" json:"enabled"`
+	Percentage  int    `form:"percentage" json:"percentage"`
+	Users       string `form:"users" json:"users"`
+	Roles       string `form:"roles" json:"roles"`
+}
+
+func (f *featureFlagForm) toModel(id int64) *model.FeatureFlag {
+	return &model.FeatureFlag{
+		ID:          id,
+		Name:        f.Name,
+		Description: f.Description,
+		Enabled:     f.Enabled,
+		Percentage:  f.Percentage,
+		Users:       f.Users,
+		Roles:       f.Roles,
+	}
+}
+
+// @Tags 后台-功能开关
+// @Summary 功能开关列表，不包含配置文件中的开关
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=[]model.FeatureFlag}
+// @Router /admin/feature/list [get]
+func (fc *FeatureFlagController) List(c *gin.Context) {
+	resp.OK(c, services.FeatureFlagService.List(c.Request.Context()))
+}
+
+// @Tags 后台-功能开关
+// @Summary 创建功能开关
+// @Param object body featureFlagForm true "开关，users、roles为逗号分隔的用户id和角色code"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=model.FeatureFlag}
+// @Router /admin/feature/create [post]
+func (fc *FeatureFlagController) Create(c *gin.Context) {
+	var form featureFlagForm
+	if err := c.ShouldBind(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	flag := form.toModel(0)
+	if err := services.FeatureFlagService.Create(c.Request.Context(), flag); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, flag)
+}
+
+// @Tags 后台-功能开关
+// @Summary 修改功能开关
+// @Param id query int true "开关id"
+// @Param object body featureFlagForm true "开关"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=model.FeatureFlag}
+// @Router /admin/feature/update [post]
+func (fc *FeatureFlagController) Update(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Query("id"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	var form featureFlagForm
+	if err := c.ShouldBind(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	flag := form.toModel(id)
+	if err := services.FeatureFlagService.Update(c.Request.Context(), flag); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, flag)
+}
+
+// @Tags 后台-功能开关
+// @Summary 删除功能开关
+// @Param id formData int true "开关id"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /admin/feature/delete [post]
+func (fc *FeatureFlagController) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if err := services.FeatureFlagService.Delete(c.Request.Context(), id); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"go-skeleton/middleware"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewController struct {
+}
+
+// @Tags 后台-文章审核
+// @Summary 按状态筛选文章，默认为待审核，按提交时间正序
+// @Param status query int false "状态 3待审核 4审核通过 5驳回"
+// @Param reviewerId query int false "审核人，-1为未分配"
+// @Param page query int false "页码"
+// @Param limit query int false "每页条数"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=simpleDb.PageResult{results=[]model.Article}}
+// @Router /admin/review/list [get]
+func (r *ReviewController) List(c *gin.Context) {
+	var q services.ReviewQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if q.Limit <= 0 || q.Limit > 100 {
+		q.Limit = 20
+	}
+	list, paging := services.ReviewService.List(c.Request.Context(), &q)
+	resp.Page(c, list, paging)
+}
+
+// @Tags 后台-文章审核
+// @Summary 分配审核人
+// @Param id formData int true "文章id"
+// @Param reviewerId formData int true "审核人id，需要有article:review权限"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /admin/review/assign [post]
+func (r *ReviewController) Assign(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	reviewerId, _ := strconv.ParseInt(c.PostForm("reviewerId"), 10, 64)
+	if id <= 0 || reviewerId <= 0 {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if err := services.ReviewService.Assign(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id, reviewerId); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// @Tags 后台-文章审核
+// @Summary 审核通过
+// @Param id formData int true "文章id"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /admin/review/approve [post]
+func (r *ReviewController) Approve(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err := services.ReviewService.Approve(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// @Tags 后台-文章审核
+// @Summary 驳回
+// @Param id formData int true "文章id"
+// @Param reason formData string true "驳回原因，不超过255个字"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /admin/review/reject [post]
+func (r *ReviewController) Reject(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err := services.ReviewService.Reject(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id, c.PostForm("reason")); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// @Tags 后台-文章审核
+// @Summary 文章的状态变更记录
+// @Param id query int true "文章id"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=[]model.ArticleStatusLog}
+// @Router /admin/review/logs [get]
+func (r *ReviewController) Logs(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Query("id"), 10, 64)
+	logs, err := services.ReviewService.Logs(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, logs)
+}
@@ -0,0 +1,144 @@
+package admin
+
+import (
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/excel"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type TagController struct {
+}
+
+// @Tags 后台-标签
+// @Summary 标签列表
+// @Param name query string false "名称前缀"
+// @Param page query int false "页码"
+// @Param limit query int false "每页条数"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=simpleDb.PageResult{results=[]model.Tag}}
+// @Router /admin/tag/list [get]
+func (t *TagController) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	cnd := simpleDb.NewSqlCnd().Desc("id").Page(page, limit)
+	if name := c.Query("name"); name != "" {
+		cnd.Starting("name", name)
+	}
+	list, paging := services.TagService.FindPageByCnd(c.Request.Context(), cnd)
+	resp.Page(c, list, paging)
+}
+
+// @Tags 后台-标签
+// @Summary 创建标签
+// @Param name formData string true "名称"
+// @Param description formData string false "描述"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=model.Tag}
+// @Router /admin/tag/create [post]
+func (t *TagController) Create(c *gin.Context) {
+	tag, err := services.TagService.Create(c.Request.Context(), c.PostForm("name"), c.PostForm("description"))
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, tag)
+}
+
+// @Tags 后台-标签
+// @Summary 修改标签
+// @Param id formData int true "标签id"
+// @Param name formData string true "名称"
+// @Param description formData string false "描述"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /admin/tag/update [post]
+func (t *TagController) Update(c *gin.Context) {
+	id, err := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if err := services.TagService.Update(c.Request.Context(), id, c.PostForm("name"), c.PostForm("description")); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// @Tags 后台-标签
+// @Summary 删除标签，同时删除与文章的关联
+// @Param id formData int true "标签id"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /admin/tag/delete [post]
+func (t *TagController) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if err := services.TagService.Delete(c.Request.Context(), id); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// 导入文件的大小限制
+const tagImportMaxSize = 10 << 20
+
+// @Tags 后台-标签
+// @Summary 导出标签为xlsx
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security ApiKeyAuth
+// @Success 200 {file} file
+// @Router /admin/tag/export [get]
+func (t *TagController) Export(c *gin.Context) {
+	w := resp.Attachment(c, "标签-"+time.Now().Format("20060102")+".xlsx", excel.ContentType)
+	if _, err := services.TagService.Export(c.Request.Context(), w); err != nil {
+		if w.Written() {
+			zap.L().Error("export tags failed", zap.Error(err))
+			return
+		}
+		resp.Error(c, err)
+	}
+}
+
+// @Tags 后台-标签
+// @Summary 从xlsx导入标签，表头为：名称、描述，返回每一行的导入结果
+// @Accept multipart/form-data
+// @Param file formData file true "xlsx文件"
+// @Security ApiKeyAuth
+// @Success 200 {object} jsonresult.JsonResult{data=excel.Report}
+// @Router /admin/tag/import [post]
+func (t *TagController) Import(c *gin.Context) {
+	fh, err := c.FormFile("file")
+	if err != nil || fh.Size > tagImportMaxSize {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	file, err := fh.Open()
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	defer file.Close()
+
+	report, err := services.TagService.Import(c.Request.Context(), file)
+	if err == excel.ErrTooManyRows {
+		resp.Error(c, errors.NewError(errors.InvalidParamsError.Code, "超出最大导入行数"))
+		return
+	}
+	if err != nil {
+		resp.Error(c, errors.NewError(errors.InvalidParamsError.Code, "文件格式错误"))
+		return
+	}
+	resp.OK(c, report)
+}
@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserController struct {
+}
+
+// 解除登录失败导致的账号锁定
+func (u *UserController) Unlock(c *gin.Context) {
+	id, err := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if err := services.UserService.Unlock(id); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// @Tags 后台-用户
+// @Summary 导出用户为csv，手机号、邮箱脱敏
+// @Produce text/csv
+// @Param status query int false "状态，0正常 1禁用，不传导出全部"
+// @Param encoding query string false "编码 utf-8-bom|utf-8|gbk，默认utf-8-bom"
+// @Security ApiKeyAuth
+// @Success 200 {file} file
+// @Router /admin/user/export [get]
+func (u *UserController) Export(c *gin.Context) {
+	status := -1
+	if s := c.Query("status"); s != "" {
+		var err error
+		if status, err = strconv.Atoi(s); err != nil || status < 0 {
+			resp.Error(c, errors.InvalidParamsError)
+			return
+		}
+	}
+	filename := "用户-" + time.Now().Format("20060102") + ".csv"
+	resp.CSV(c, filename, c.Query("encoding"), services.UserRow{}, services.UserService.ExportFetch(c.Request.Context(), status))
+}
@@ -0,0 +1,50 @@
+package admin
+
+import (
+	"encoding/json"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/pkg/ws"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WsController struct {
+}
+
+type wsSendForm struct {
+	// 为空时广播
+	UserIds []int64         `json:"userIds"`
+	Type    string          `json:"type" binding:"required"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// 发送消息给指定用户，userIds为空时广播
+func (w *WsController) Send(c *gin.Context) {
+	var form wsSendForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	msg := &ws.Message{Type: form.Type, Data: form.Data}
+	var err error
+	if len(form.UserIds) == 0 {
+		err = ws.Broadcast(c, msg)
+	} else {
+		err = ws.SendToUser(c, msg, form.UserIds...)
+	}
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// 本实例的在线用户数和连接数
+func (w *WsController) Online(c *gin.Context) {
+	users, conns := 0, 0
+	if hub := ws.Default(); hub != nil {
+		users, conns = hub.Count()
+	}
+	resp.OK(c, gin.H{"users": users, "conns": conns})
+}
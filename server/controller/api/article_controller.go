@@ -4,13 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"go-skeleton/middleware"
 	"go-skeleton/model"
+	"go-skeleton/model/constants"
 	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
 	"go-skeleton/pkg/gcache"
 	"go-skeleton/pkg/gredis"
 	"go-skeleton/pkg/jsonresult"
 	"go-skeleton/pkg/queue"
+	"go-skeleton/pkg/resp"
 	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/pkg/sse"
 	"go-skeleton/pkg/upload"
 	"go-skeleton/services"
 	"go-skeleton/utils"
@@ -31,6 +36,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/opentracing/opentracing-go"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
 type ArticleController struct {
@@ -60,7 +66,7 @@ func (a *ArticleController) Create(c *gin.Context) {
 		CommentCount: 0,
 		ReadCount:    0,
 	}
-	err := services.ArticleService.CreateArticle(&article)
+	err := services.ArticleService.CreateArticle(c.Request.Context(), &article)
 	if err != nil {
 		c.JSON(http.StatusOK, jsonresult.JsonError(err))
 		return
@@ -76,11 +82,11 @@ func (a *ArticleController) Create(c *gin.Context) {
 // @Param id query int true "标题"
 // @Param Authorization header string false "Bearer 用户令牌"
 // @Failure 400 {string} json "{"errorCode":0,"data:"",success":false}"
-// @Success 200 {object} utils.JsonResult{data=model.Article} "文章"
+// @Success 200 {object} jsonresult.JsonResult{data=model.Article} "文章"
 // @Router /api/article/getArticleById [get]
 func (a *ArticleController) GetArticleById(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Query("id"))
-	articleInfo, _ := services.ArticleService.GetArticleById(id)
+	articleInfo, _ := services.ArticleService.GetArticleById(c.Request.Context(), id)
 
 	artJson, err := json.Marshal(articleInfo)
 	fmt.Println("json字符串的结果是：", string(artJson), err)
@@ -122,7 +128,7 @@ func (a *ArticleController) GetArticleByCache(c *gin.Context) {
 		TTL:   time.Minute * 5,
 		Do: func(item *cache.Item) (interface{}, error) {
 			fmt.Println("我来查询文章了")
-			return services.ArticleService.GetArticleById(id)
+			return services.ArticleService.GetArticleById(c.Request.Context(), id)
 		},
 	})
 	if err != nil {
@@ -140,7 +146,7 @@ func (a *ArticleController) GetArticle(c *gin.Context) {
 	key := "article:" + strconv.Itoa(id)
 	data, err := gredis.GetRedis().Get(context.TODO(), key).Result()
 	if data == "" || err != nil {
-		articleInfo, err = services.ArticleService.GetArticleById(id)
+		articleInfo, err = services.ArticleService.GetArticleById(c.Request.Context(), id)
 		if articleInfo != (model.Article{}) {
 			cacheValue, _ := json.Marshal(articleInfo)
 			_ = gredis.GetRedis().Set(context.TODO(), key, cacheValue, 60*60*time.Second).Err()
@@ -189,7 +195,7 @@ func (a *ArticleController) GetArticleList(c *gin.Context) {
 
 	params := simpleDb.NewQueryParams(c)
 	params.LikeByReq("title").PageByReq().Desc("id")
-	articleList3, paging := services.ArticleService.FindPageByParams(params)
+	articleList3, paging := services.ArticleService.FindPageByParams(c.Request.Context(), params)
 
 	//pageSize, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	//page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -216,7 +222,7 @@ func (a *ArticleController) SearchArticle(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	title := c.Query("title")
 
-	list, total, err := services.ArticleService.SearchArticle2(title, pageSize, page)
+	list, total, err := services.ArticleService.SearchArticle2(c.Request.Context(), title, pageSize, page)
 	fmt.Printf("%+v\n\n", err)
 	c.JSON(http.StatusOK, gin.H{
 		"list":  list,
@@ -267,7 +273,7 @@ func (a *ArticleController) EditArticle(c *gin.Context) {
 	jsonStr, _ := json.Marshal(artForm)
 	var art model.Article
 	json.Unmarshal(jsonStr, &art)
-	result, err := services.ArticleService.EditArticle(int(art.ID), &art)
+	result, err := services.ArticleService.EditArticle(c.Request.Context(), int(art.ID), &art)
 	c.JSON(http.StatusOK, gin.H{
 		"article": artForm,
 		"artJson": string(jsonStr),
@@ -310,8 +316,8 @@ func (a *ArticleController) MyChan(c *gin.Context) {
 
 	id1, _ := strconv.Atoi(c.DefaultQuery("id1", "3"))
 	id2, _ := strconv.Atoi(c.DefaultQuery("id2", "4"))
-	art1Chan := a.getArt(id1)
-	art2Chan := a.getArt(id2)
+	art1Chan := a.getArt(c.Request.Context(), id1)
+	art2Chan := a.getArt(c.Request.Context(), id2)
 
 	art1 := <-art1Chan
 	art2 := <-art2Chan
@@ -328,11 +334,11 @@ func (a *ArticleController) MyChan(c *gin.Context) {
 	//context.WithValue(ctx, "anc", "aaaa")
 }
 
-func (a *ArticleController) getArt(id int) <-chan model.Article {
+func (a *ArticleController) getArt(ctx context.Context, id int) <-chan model.Article {
 	artChan := make(chan model.Article)
 	go func(id int) {
 
-		art, _ := services.ArticleService.GetArticleById(id)
+		art, _ := services.ArticleService.GetArticleById(ctx, id)
 		artChan <- art
 	}(id)
 	return artChan
@@ -565,3 +571,385 @@ func (a *ArticleController) TryRedisLock(c *gin.Context) {
 	}))
 	return
 }
+
+// @Tags 文章接口
+// @Summary 创建草稿
+// @Accept application/json
+// @Produce application/json
+// @Param object body services.ArticleForm true "文章"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=model.Article}
+// @Router /api/article/add [post]
+func (a *ArticleController) AddArticle(c *gin.Context) {
+	var form services.ArticleForm
+	if err := c.ShouldBind(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	article, err := services.ArticleService.Create(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), &form)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, article)
+}
+
+// @Tags 文章接口
+// @Summary 修改文章
+// @Accept application/json
+// @Produce application/json
+// @Param id query int true "文章id"
+// @Param object body services.ArticleForm true "文章"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=model.Article}
+// @Router /api/article/update [post]
+func (a *ArticleController) UpdateArticle(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Query("id"), 10, 64)
+	var form services.ArticleForm
+	if err := c.ShouldBind(&form); err != nil || id <= 0 {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	article, err := services.ArticleService.Edit(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id, &form)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, article)
+}
+
+// @Tags 文章接口
+// @Summary 自动保存编辑中的内容，不修改文章，间隔内的多次自动保存合并为一个版本
+// @Accept application/json
+// @Produce application/json
+// @Param id query int true "文章id"
+// @Param object body services.ArticleForm true "文章"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=model.ArticleRevision}
+// @Router /api/article/autosave [post]
+func (a *ArticleController) Autosave(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Query("id"), 10, 64)
+	var form services.ArticleForm
+	if err := c.ShouldBind(&form); err != nil || id <= 0 {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	revision, err := services.RevisionService.Autosave(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id, &form)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, revision)
+}
+
+// @Tags 文章接口
+// @Summary 文章的历史版本，按时间倒序，不返回内容
+// @Param id query int true "文章id"
+// @Param page query int false "页码"
+// @Param limit query int false "每页条数"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=simpleDb.PageResult{results=[]model.ArticleRevision}}
+// @Router /api/article/revisions [get]
+func (a *ArticleController) Revisions(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Query("id"), 10, 64)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	list, paging, err := services.RevisionService.List(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id, page, limit)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Page(c, list, paging)
+}
+
+// @Tags 文章接口
+// @Summary 预览历史版本
+// @Param id query int true "版本id"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=model.ArticleRevision}
+// @Router /api/article/revision [get]
+func (a *ArticleController) Revision(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Query("id"), 10, 64)
+	revision, err := services.RevisionService.Get(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, revision)
+}
+
+// @Tags 文章接口
+// @Summary 恢复到历史版本，标签和slug不变，恢复后生成新的版本
+// @Param id formData int true "版本id"
+// @Param version formData int false "文章当前的版本号，用于检查是否已被修改"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=model.Article}
+// @Router /api/article/revision/restore [post]
+func (a *ArticleController) RestoreRevision(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	var version *int64
+	if v, err := strconv.ParseInt(c.PostForm("version"), 10, 64); err == nil {
+		version = &v
+	}
+	article, err := services.RevisionService.Restore(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id, version)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, article)
+}
+
+// @Tags 文章接口
+// @Summary 发布草稿
+// @Param id formData int true "文章id"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /api/article/publish [post]
+func (a *ArticleController) PublishArticle(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err := services.ArticleService.Publish(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// @Tags 文章接口
+// @Summary 定时发布草稿，已设置过时修改为新的时间
+// @Param id formData int true "文章id"
+// @Param publishAt formData string true "发布时间，格式2006-01-02 15:04:05或unix时间戳"
+// @Param timezone formData string false "publishAt的时区，eg: Asia/Shanghai，默认为请求的时区"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=model.Article}
+// @Router /api/article/schedule [post]
+func (a *ArticleController) ScheduleArticle(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	publishAt, err := parsePublishAt(c, c.PostForm("publishAt"), c.PostForm("timezone"))
+	if err != nil || id <= 0 {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	article, err := services.ScheduleService.Schedule(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id, publishAt)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, article)
+}
+
+// @Tags 文章接口
+// @Summary 取消定时发布
+// @Param id formData int true "文章id"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /api/article/schedule/cancel [post]
+func (a *ArticleController) CancelSchedule(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err := services.ScheduleService.Cancel(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// parsePublishAt 解析定时发布时间，时间戳直接使用，日期时间按timezone或请求的时区解析
+func parsePublishAt(c *gin.Context, value, timezone string) (time.Time, error) {
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+	loc := utils.LocationFrom(c)
+	if timezone != "" {
+		l, err := utils.LoadLocation(timezone)
+		if err != nil {
+			return time.Time{}, err
+		}
+		loc = l
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05", value, loc)
+}
+
+// @Tags 文章接口
+// @Summary 提交审核，草稿或被驳回的文章可以提交
+// @Param id formData int true "文章id"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /api/article/submit [post]
+func (a *ArticleController) SubmitArticle(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err := services.ArticleService.Submit(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// @Tags 文章接口
+// @Summary 撤回审核中或被驳回的文章，改为草稿
+// @Param id formData int true "文章id"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /api/article/withdraw [post]
+func (a *ArticleController) WithdrawArticle(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err := services.ArticleService.Withdraw(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// @Tags 文章接口
+// @Summary 文章的审核、发布记录
+// @Param id query int true "文章id"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=[]model.ArticleStatusLog}
+// @Router /api/article/statusLogs [get]
+func (a *ArticleController) StatusLogs(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Query("id"), 10, 64)
+	logs, err := services.ReviewService.Logs(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, logs)
+}
+
+// @Tags 文章接口
+// @Summary 归档文章
+// @Param id formData int true "文章id"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /api/article/archive [post]
+func (a *ArticleController) ArchiveArticle(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err := services.ArticleService.Archive(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// @Tags 文章接口
+// @Summary 我的文章
+// @Param status query int false "状态 0草稿 1已发布 2已归档 3待审核 4审核通过 5驳回，不传返回全部"
+// @Param page query int false "页码"
+// @Param limit query int false "每页条数"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=simpleDb.PageResult{results=[]model.Article}}
+// @Router /api/article/mine [get]
+func (a *ArticleController) MyArticles(c *gin.Context) {
+	status, err := strconv.Atoi(c.DefaultQuery("status", "-1"))
+	if err != nil {
+		status = -1
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	list, paging := services.ArticleService.ListByUser(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), status, page, limit)
+	resp.Page(c, list, paging)
+}
+
+// @Tags 文章接口
+// @Summary 已发布的文章
+// @Param cid query int false "分类id"
+// @Param page query int false "页码"
+// @Param limit query int false "每页条数"
+// @Success 200 {object} jsonresult.JsonResult{data=simpleDb.PageResult{results=[]model.Article}}
+// @Router /api/article/published [get]
+func (a *ArticleController) PublishedArticles(c *gin.Context) {
+	cid, _ := strconv.ParseUint(c.Query("cid"), 10, 64)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	list, paging := services.ArticleService.ListPublished(c.Request.Context(), cid, page, limit)
+	resp.Page(c, list, paging)
+}
+
+// @Tags 文章接口
+// @Summary 已发布的文章详情，同时记录浏览量
+// @Param id query int true "文章id"
+// @Success 200 {object} jsonresult.JsonResult{data=model.Article}
+// @Router /api/article/detail [get]
+func (a *ArticleController) Detail(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Query("id"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	article := services.ArticleService.Get(c.Request.Context(), id)
+	if article == nil || article.Status != constants.ArticleStatusPublished {
+		resp.Error(c, errors.NotFoundError)
+		return
+	}
+	ctx := c.Request.Context()
+	if _, err := services.ViewService.Record(ctx, id, int64(c.GetInt(middleware.UidKey)), c.ClientIP()); err != nil {
+		utils.Logger(c).Warn("record article view failed", zap.Int64("id", id), zap.Error(err))
+	}
+	article.ReadCount += services.ViewService.Pending(ctx, id)
+	resp.OK(c, article)
+}
+
+// @Tags 文章接口
+// @Summary 浏览量排行
+// @Param period query string false "day: 今天 | week: 最近7天，默认day"
+// @Param limit query int false "条数，默认10，最大50"
+// @Success 200 {object} jsonresult.JsonResult{data=[]services.HotArticle}
+// @Router /api/article/hot [get]
+func (a *ArticleController) Hot(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+	list, err := services.ViewService.Hot(c.Request.Context(), c.DefaultQuery("period", services.ViewRankDay), limit)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, list)
+}
+
+// @Tags 文章接口
+// @Summary 热度排行，综合浏览量、点赞、收藏、评论并按发布时间衰减，每10分钟更新
+// @Param period query string false "day | week，默认day"
+// @Param page query int false "页码"
+// @Param limit query int false "每页条数"
+// @Success 200 {object} jsonresult.JsonResult{data=simpleDb.PageResult{results=[]model.Article}}
+// @Router /api/articles/trending [get]
+func (a *ArticleController) Trending(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	list, paging, err := services.RankingService.Trending(c.Request.Context(), c.DefaultQuery("period", services.ViewRankDay), page, limit)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Page(c, list, paging)
+}
+
+// ExportProgress SSE推送导出进度的示例，断线重连时从Last-Event-ID之后继续推送
+func (a *ArticleController) ExportProgress(c *gin.Context) {
+	start, _ := strconv.Atoi(sse.LastEventID(c))
+	events := make(chan sse.Event)
+	ctx := c.Request.Context()
+	go func() {
+		defer close(events)
+		for i := start + 1; i <= 10; i++ {
+			time.Sleep(500 * time.Millisecond)
+			e := sse.Event{ID: strconv.Itoa(i), Event: "progress", Data: gin.H{"percent": i * 10}}
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case events <- sse.Event{Event: "done", Data: gin.H{"url": "/export/articles.xlsx"}}:
+		case <-ctx.Done():
+		}
+	}()
+	sse.Stream(c, events)
+}
@@ -1,45 +1,24 @@
 package api
 
 import (
-	"context"
 	"go-skeleton/pkg/gcaptcha"
-	"go-skeleton/pkg/gredis"
 	"go-skeleton/pkg/jsonresult"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/mojocn/base64Captcha"
 )
 
 type CaptchaController struct {
 }
 
-//生成验证码
+//生成验证码，type为string、math、slider，为空时使用配置的默认类型
 func (captcha *CaptchaController) GenerateCaptcha(c *gin.Context) {
-	var driver base64Captcha.Driver
-	driverString := base64Captcha.DriverString{
-		Height:          80,
-		Width:           240,
-		NoiseCount:      5,
-		ShowLineOptions: base64Captcha.OptionShowSlimeLine,
-		Length:          5,
-		Source:          base64Captcha.TxtSimpleCharaters,
-		//BgColor:         nil,
-		//Fonts:           nil,
-	}
-	driver = driverString.ConvertFonts()
-
-	store := gcaptcha.NewRedisStore(context.TODO(), gredis.GetRedis())
-	captchaObj := base64Captcha.NewCaptcha(driver, store)
-	id, b64s, err := captchaObj.Generate()
+	captchaObj, err := gcaptcha.Generate(c, c.Query("type"))
 	if err != nil {
 		c.JSON(http.StatusOK, jsonresult.JsonError(err))
 		return
 	}
-	c.JSON(http.StatusOK, jsonresult.JsonData(gin.H{
-		"captchaId": id,
-		"data":      b64s,
-	}))
+	c.JSON(http.StatusOK, jsonresult.JsonData(captchaObj))
 	return
 }
 
@@ -48,7 +27,7 @@ type VerifyCaptcha struct {
 	Value string `form:"value"`
 }
 
-//验证验证码
+//验证验证码，滑动验证码的value为拼图块的x坐标
 func (captcha *CaptchaController) VerifyCaptcha(c *gin.Context) {
 	var verify VerifyCaptcha
 	err := c.ShouldBindQuery(&verify)
@@ -56,8 +35,7 @@ func (captcha *CaptchaController) VerifyCaptcha(c *gin.Context) {
 		c.JSON(http.StatusOK, jsonresult.JsonError(err))
 		return
 	}
-	store := gcaptcha.NewRedisStore(context.TODO(), gredis.GetRedis())
-	result := store.Verify(verify.Id, verify.Value, true)
+	result := gcaptcha.Verify(c, verify.Id, verify.Value)
 	c.JSON(http.StatusOK, jsonresult.JsonData(gin.H{
 		"verify_result": result,
 	}))
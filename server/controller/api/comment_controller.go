@@ -0,0 +1,97 @@
+package api
+
+import (
+	"go-skeleton/middleware"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentController struct {
+}
+
+// @Tags 评论
+// @Summary 文章的评论，一级评论分页，附带最早的几条回复
+// @Param articleId query int true "文章id"
+// @Param page query int false "页码"
+// @Param limit query int false "每页条数"
+// @Success 200 {object} jsonresult.JsonResult{data=simpleDb.PageResult{results=[]model.CommentThread}}
+// @Router /api/comment/list [get]
+func (cc *CommentController) List(c *gin.Context) {
+	articleId, err := strconv.ParseInt(c.Query("articleId"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	list, paging := services.CommentService.ListByArticle(c.Request.Context(), articleId, page, limit)
+	resp.Page(c, list, paging)
+}
+
+// @Tags 评论
+// @Summary 一级评论下的回复
+// @Param rootId query int true "一级评论id"
+// @Param page query int false "页码"
+// @Param limit query int false "每页条数"
+// @Success 200 {object} jsonresult.JsonResult{data=simpleDb.PageResult{results=[]model.Comment}}
+// @Router /api/comment/replies [get]
+func (cc *CommentController) Replies(c *gin.Context) {
+	rootId, err := strconv.ParseInt(c.Query("rootId"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	list, paging := services.CommentService.ListReplies(c.Request.Context(), rootId, page, limit)
+	resp.Page(c, list, paging)
+}
+
+type commentForm struct {
+	ArticleId int64  `form:"articleId" json:"articleId" binding:"required"`
+	ParentId  int64  `form:"parentId" json:"parentId"`
+	Content   string `form:"content" json:"content" binding:"required"`
+}
+
+// @Tags 评论
+// @Summary 发表评论，parentId不为0时为回复
+// @Param object body commentForm true "评论"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=model.Comment}
+// @Router /api/comment/create [post]
+func (cc *CommentController) Create(c *gin.Context) {
+	var form commentForm
+	if err := c.ShouldBind(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	comment, err := services.CommentService.Create(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), form.ArticleId, form.ParentId, form.Content)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, comment)
+}
+
+// @Tags 评论
+// @Summary 删除评论
+// @Param id formData int true "评论id"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /api/comment/delete [post]
+func (cc *CommentController) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if err := services.CommentService.Delete(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
@@ -2,8 +2,9 @@ package api
 
 import (
 	"go-skeleton/pkg/auth"
-	"go-skeleton/pkg/errors"
+	"go-skeleton/middleware"
 	"go-skeleton/pkg/jsonresult"
+	"go-skeleton/pkg/resp"
 	"go-skeleton/services"
 
 	"github.com/gin-gonic/gin"
@@ -20,56 +21,52 @@ type LoginForm struct {
 func (l *LoginController) Login(c *gin.Context) {
 	var form LoginForm
 	if c.ShouldBind(&form) == nil {
-		userService := services.UserService
-		user := userService.Take(map[string]interface{}{"username": form.User})
-
-		//真实情况应该这里是登陆验证逻辑
-		if form.User == "user" && form.Password == "password" {
-			uid := 1
-			token, err := auth.GenerateToken(uid)
-			if err != nil {
-				c.JSON(200, jsonresult.JsonError(err))
-				return
-			}
-			//refreshToken应该放入数据库或者缓存中
-			refreshToken, err := services.UserTokenService.GenerateRefreshToken(int64(uid))
-			if err != nil {
-				c.JSON(200, jsonresult.JsonError(err))
-				return
-			}
-			c.JSON(200, jsonresult.JsonData(gin.H{
-				"refreshToken": refreshToken,
-				"token":        token,
-				"user":         user,
-			}))
+		user, err := services.UserService.SignIn(c.Request.Context(), form.User, form.Password, c.ClientIP())
+		if err != nil {
+			resp.Error(c, err)
 			return
-		} else {
-			c.JSON(200, jsonresult.JsonErrorMsg("unauthorized"))
+		}
+		pair, err := auth.GenerateTokenPair(int(user.ID))
+		if err != nil {
+			c.JSON(200, jsonresult.JsonError(err))
 			return
 		}
+		c.JSON(200, jsonresult.JsonData(gin.H{
+			"refreshToken": pair.RefreshToken,
+			"token":        pair.Token,
+			"expiresAt":    pair.ExpiresAt,
+			"user":         user,
+		}))
 	} else {
 		c.JSON(200, jsonresult.JsonErrorMsg("缺少参数"))
 	}
 }
 
-//刷新token
+//刷新token，旧的refreshToken会失效
 func (l *LoginController) RefreshAccessToken(c *gin.Context) {
 	refreshToken := c.PostForm("refreshToken")
 	if refreshToken == "" {
 		c.JSON(200, jsonresult.JsonErrorMsg("refreshToken不能为空"))
-	}
-	userId, err := services.UserTokenService.GetUserIdByToken(refreshToken)
-	if userId == 0 {
-		c.JSON(200, jsonresult.JsonCodeError(errors.RefreshTokenError))
 		return
 	}
-	token, err := auth.GenerateToken(int(userId))
+	pair, err := auth.Refresh(refreshToken)
 	if err != nil {
-		c.JSON(200, jsonresult.JsonError(err))
+		c.JSON(200, jsonresult.JsonCodeError(err))
 		return
 	}
-	c.JSON(200, jsonresult.JsonData(gin.H{
-		"token": token,
-	}))
-	return
+	c.JSON(200, jsonresult.JsonData(pair))
+}
+
+//退出登录，吊销当前token和refreshToken
+func (l *LoginController) Logout(c *gin.Context) {
+	if claims := middleware.GetClaims(c); claims != nil {
+		if err := auth.Revoke(claims); err != nil {
+			c.JSON(200, jsonresult.JsonError(err))
+			return
+		}
+	}
+	if refreshToken := c.PostForm("refreshToken"); refreshToken != "" {
+		_ = auth.RevokeToken(refreshToken)
+	}
+	c.JSON(200, jsonresult.JsonSuccess())
 }
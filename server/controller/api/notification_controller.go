@@ -0,0 +1,111 @@
+package api
+
+import (
+	"go-skeleton/middleware"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/services"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+}
+
+// @Tags 通知
+// @Summary 我的通知，按时间倒序
+// @Param unread query int false "1只返回未读的"
+// @Param page query int false "页码"
+// @Param limit query int false "每页条数"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param If-None-Match header string false "上次响应的ETag，未变化时返回304"
+// @Success 200 {object} jsonresult.JsonResult{data=simpleDb.PageResult{results=[]model.Notification}}
+// @Router /api/notification/list [get]
+func (nc *NotificationController) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	userId := int64(c.GetInt(middleware.UidKey))
+	version, err := services.NotificationService.Version(c.Request.Context(), userId)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	if resp.NotModified(c, version) {
+		return
+	}
+	list, paging, err := services.NotificationService.List(c.Request.Context(), userId, c.Query("unread") == "1", page, limit)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Page(c, list, paging)
+}
+
+// @Tags 通知
+// @Summary 未读通知数
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param If-None-Match header string false "上次响应的ETag，未变化时返回304"
+// @Success 200 {object} jsonresult.JsonResult{data=int}
+// @Router /api/notification/unreadCount [get]
+func (nc *NotificationController) UnreadCount(c *gin.Context) {
+	userId := int64(c.GetInt(middleware.UidKey))
+	version, err := services.NotificationService.Version(c.Request.Context(), userId)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	if resp.NotModified(c, version) {
+		return
+	}
+	count, err := services.NotificationService.UnreadCount(c.Request.Context(), userId)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, count)
+}
+
+// @Tags 通知
+// @Summary 标记为已读
+// @Param ids formData string true "通知id，多个用逗号分隔"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /api/notification/read [post]
+func (nc *NotificationController) Read(c *gin.Context) {
+	var ids []int64
+	for _, s := range strings.Split(c.PostForm("ids"), ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil || id <= 0 {
+			resp.Error(c, errors.InvalidParamsError)
+			return
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) > 100 {
+		resp.Error(c, errors.NewError(errors.InvalidParamsError.Code, "一次最多标记100条"))
+		return
+	}
+	if err := services.NotificationService.MarkRead(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), ids); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// @Tags 通知
+// @Summary 全部标记为已读
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /api/notification/readAll [post]
+func (nc *NotificationController) ReadAll(c *gin.Context) {
+	if err := services.NotificationService.MarkAllRead(c.Request.Context(), int64(c.GetInt(middleware.UidKey))); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
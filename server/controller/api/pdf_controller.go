@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"go-skeleton/middleware"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PdfController struct {
+}
+
+type pdfForm struct {
+	// 模板名称，对应pdf.TemplateDir中的{template}.html
+	Template string `json:"template" binding:"required"`
+	// 下载时的文件名，为空时使用{template}.pdf
+	Filename string `json:"filename" binding:"max=100"`
+	// 模板数据
+	Data json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// @Tags pdf
+// @Summary 提交生成pdf的任务，通过/api/pdf/task查询结果
+// @Param object body pdfForm true "模板及数据"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=services.PdfTask}
+// @Router /api/pdf/create [post]
+func (p *PdfController) Create(c *gin.Context) {
+	var form pdfForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	task, err := services.PdfService.Submit(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), form.Template, form.Filename, form.Data)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, task)
+}
+
+// @Tags pdf
+// @Summary 查询生成pdf的任务，status为done时url为下载地址
+// @Param id query string true "任务id"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=services.PdfTask}
+// @Router /api/pdf/task [get]
+func (p *PdfController) Task(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	task, err := services.PdfService.Task(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), id)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, task)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/utils"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QrcodeController struct {
+}
+
+// 二维码、条形码的缓存时间，内容完全由参数决定
+const codeCacheMaxAge = 7 * 24 * time.Hour
+
+// 内容及尺寸的限制
+const (
+	qrcodeMaxText  = 1024
+	qrcodeMaxSize  = 1024
+	barcodeMaxText = 80
+)
+
+var qrLevels = map[string]utils.QRLevel{
+	"L": utils.QRLevelL,
+	"M": utils.QRLevelM,
+	"Q": utils.QRLevelQ,
+	"H": utils.QRLevelH,
+}
+
+// @Tags 工具
+// @Summary 生成二维码
+// @Produce image/png
+// @Param text query string true "内容"
+// @Param size query int false "边长，64~1024，默认256"
+// @Param level query string false "纠错等级 L|M|Q|H，默认M"
+// @Param format query string false "png|dataurl，dataurl时返回json"
+// @Success 200 {file} file
+// @Router /api/qrcode [get]
+func (q *QrcodeController) QRCode(c *gin.Context) {
+	text := c.Query("text")
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "256"))
+	level, ok := qrLevels[c.DefaultQuery("level", "M")]
+	if text == "" || utf8.RuneCountInString(text) > qrcodeMaxText || size < 64 || size > qrcodeMaxSize || !ok {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	b, err := utils.QRCodePNG(text, utils.QROptions{Size: size, Level: level})
+	if err != nil {
+		resp.Error(c, errors.NewError(errors.InvalidParamsError.Code, "内容过长"))
+		return
+	}
+	q.output(c, b)
+}
+
+// @Tags 工具
+// @Summary 生成code128条形码
+// @Produce image/png
+// @Param text query string true "内容，只支持ASCII字符"
+// @Param width query int false "宽度，默认为条码最小宽度的2倍"
+// @Param height query int false "高度，默认80"
+// @Param format query string false "png|dataurl，dataurl时返回json"
+// @Success 200 {file} file
+// @Router /api/barcode [get]
+func (q *QrcodeController) Barcode(c *gin.Context) {
+	text := c.Query("text")
+	width, _ := strconv.Atoi(c.DefaultQuery("width", "0"))
+	height, _ := strconv.Atoi(c.DefaultQuery("height", "80"))
+	if text == "" || len(text) > barcodeMaxText || width < 0 || width > qrcodeMaxSize || height <= 0 || height > qrcodeMaxSize {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	b, err := utils.Code128PNG(text, width, height)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	q.output(c, b)
+}
+
+func (q *QrcodeController) output(c *gin.Context, b []byte) {
+	if c.Query("format") == "dataurl" {
+		resp.OK(c, gin.H{"dataUrl": utils.PNGDataURL(b)})
+		return
+	}
+	resp.PNG(c, b, codeCacheMaxAge)
+}
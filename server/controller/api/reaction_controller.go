@@ -0,0 +1,57 @@
+package api
+
+import (
+	"go-skeleton/middleware"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReactionController struct {
+}
+
+type reactionForm struct {
+	EntityType string `form:"entityType" json:"entityType" binding:"required"`
+	EntityId   int64  `form:"entityId" json:"entityId" binding:"required"`
+	Type       string `form:"type" json:"type" binding:"required"`
+}
+
+// @Tags 点赞收藏
+// @Summary 点赞/收藏或取消，返回操作后的状态和计数
+// @Param object body reactionForm true "entityType: article | comment，type: like | favorite"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=services.ReactionState}
+// @Router /api/reaction/toggle [post]
+func (rc *ReactionController) Toggle(c *gin.Context) {
+	var form reactionForm
+	if err := c.ShouldBind(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	state, err := services.ReactionService.Toggle(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), form.EntityType, form.EntityId, form.Type)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, state)
+}
+
+// @Tags 点赞收藏
+// @Summary 我收藏的文章，按收藏时间倒序
+// @Param page query int false "页码"
+// @Param limit query int false "每页条数"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=simpleDb.PageResult{results=[]model.Article}}
+// @Router /api/reaction/favorites [get]
+func (rc *ReactionController) Favorites(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	list, paging := services.ReactionService.FavoriteArticles(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), page, limit)
+	resp.Page(c, list, paging)
+}
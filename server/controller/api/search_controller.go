@@ -0,0 +1,47 @@
+package api
+
+import (
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/pkg/search"
+	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/services"
+	"go-skeleton/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SearchController struct {
+}
+
+// @Tags 搜索
+// @Summary 全文搜索已发布的文章，关键词在highlight中使用<em>高亮
+// @Param q query string false "关键词，为空时按发布时间倒序"
+// @Param tags query string false "标签，多个以逗号分隔，需同时满足"
+// @Param cid query int false "分类id"
+// @Param page query int false "页码"
+// @Param limit query int false "每页条数"
+// @Success 200 {object} jsonresult.JsonResult{data=simpleDb.PageResult{results=[]search.Hit}}
+// @Router /api/articles/search [get]
+func (s *SearchController) Articles(c *gin.Context) {
+	q := &search.Query{Keyword: strings.TrimSpace(c.Query("q"))}
+	q.Cid, _ = strconv.ParseUint(c.Query("cid"), 10, 64)
+	q.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	q.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "20"))
+	for _, tag := range strings.Split(c.Query("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			q.Tags = append(q.Tags, tag)
+		}
+	}
+
+	ret, err := services.SearchService.Search(c, q)
+	if err != nil {
+		utils.Logger(c).Error("search articles failed", zap.Error(err))
+		resp.Error(c, errors.SearchUnavailableError)
+		return
+	}
+	resp.Page(c, ret.Hits, &simpleDb.Paging{Page: q.Page, Limit: q.Limit, Total: ret.Total})
+}
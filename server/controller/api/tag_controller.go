@@ -0,0 +1,109 @@
+package api
+
+import (
+	"go-skeleton/middleware"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagController struct {
+}
+
+// @Tags 标签
+// @Summary 热门标签及文章数
+// @Param limit query int false "数量，默认20"
+// @Success 200 {object} jsonresult.JsonResult{data=[]model.TagCount}
+// @Router /api/tag/hot [get]
+func (t *TagController) Hot(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	resp.OK(c, services.TagService.HotTags(c.Request.Context(), limit))
+}
+
+// @Tags 标签
+// @Summary 标签下的文章
+// @Param tagId query int true "标签id"
+// @Param page query int false "页码"
+// @Param limit query int false "每页条数"
+// @Success 200 {object} jsonresult.JsonResult{data=simpleDb.PageResult{results=[]model.Article}}
+// @Router /api/tag/articles [get]
+func (t *TagController) Articles(c *gin.Context) {
+	tagId, err := strconv.ParseInt(c.Query("tagId"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	list, paging := services.ArticleService.ListByTag(c.Request.Context(), tagId, page, limit)
+	resp.Page(c, list, paging)
+}
+
+// @Tags 标签
+// @Summary 文章的标签
+// @Param articleId query int true "文章id"
+// @Success 200 {object} jsonresult.JsonResult{data=[]model.Tag}
+// @Router /api/tag/ofArticle [get]
+func (t *TagController) OfArticle(c *gin.Context) {
+	articleId, err := strconv.ParseInt(c.Query("articleId"), 10, 64)
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	resp.OK(c, services.ArticleService.GetTags(c.Request.Context(), articleId))
+}
+
+type tagsForm struct {
+	ArticleId int64    `form:"articleId" json:"articleId" binding:"required"`
+	Tags      []string `form:"tags" json:"tags"`
+	TagIds    []int64  `form:"tagIds" json:"tagIds"`
+}
+
+// @Tags 标签
+// @Summary 为文章添加标签，不存在的标签自动创建
+// @Param object body tagsForm true "文章id和标签名称"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /api/tag/attach [post]
+func (t *TagController) Attach(c *gin.Context) {
+	var form tagsForm
+	if err := c.ShouldBind(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if err := services.ArticleService.AttachTags(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), form.ArticleId, form.Tags); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// @Tags 标签
+// @Summary 移除文章的标签
+// @Param object body tagsForm true "文章id和标签id"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /api/tag/detach [post]
+func (t *TagController) Detach(c *gin.Context) {
+	var form tagsForm
+	if err := c.ShouldBind(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if err := services.ArticleService.DetachTags(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), form.ArticleId, form.TagIds); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// @Tags 分类
+// @Summary 分类及已发布的文章数
+// @Success 200 {object} jsonresult.JsonResult{data=[]services.CategoryCount}
+// @Router /api/categories [get]
+func (t *TagController) Categories(c *gin.Context) {
+	resp.OK(c, services.CategoryService.List(c.Request.Context()))
+}
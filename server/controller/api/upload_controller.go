@@ -0,0 +1,141 @@
+package api
+
+import (
+	"go-skeleton/middleware"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/pkg/upload"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UploadController struct {
+}
+
+// 上传的保存目录，只允许以下几种，避免客户端随意指定路径
+var uploadDirs = map[string]bool{"images": true, "files": true, "videos": true}
+
+func uploadDir(c *gin.Context) string {
+	dir := c.DefaultPostForm("dir", "files")
+	if !uploadDirs[dir] {
+		return "files"
+	}
+	return dir
+}
+
+// @Summary 上传文件
+// @Tags 上传
+// @Accept multipart/form-data
+// @Param file formData file true "文件"
+// @Param dir formData string false "保存目录 images|files|videos"
+// @Success 200 {object} jsonresult.JsonResult
+// @Security ApiKeyAuth
+// @Router /api/upload [post]
+func (u *UploadController) Upload(c *gin.Context) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	dir := uploadDir(c)
+	var file *upload.File
+	if dir == "images" {
+		// 图片去掉EXIF信息，避免泄露拍摄地点
+		file, err = upload.SaveImage(c.Request.Context(), fh, dir, nil)
+	} else {
+		file, err = upload.Save(c.Request.Context(), fh, dir)
+	}
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, file)
+}
+
+type chunkForm struct {
+	UploadId string `form:"uploadId" binding:"required"`
+	Index    int    `form:"index" binding:"min=0"`
+}
+
+// @Summary 上传分片
+// @Tags 上传
+// @Accept multipart/form-data
+// @Param file formData file true "分片"
+// @Param uploadId formData string true "上传id，eg: 文件md5"
+// @Param index formData int true "分片序号，从0开始"
+// @Success 200 {object} jsonresult.JsonResult
+// @Security ApiKeyAuth
+// @Router /api/upload/chunk [post]
+func (u *UploadController) UploadChunk(c *gin.Context) {
+	var form chunkForm
+	if err := c.ShouldBind(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if err := upload.SaveChunk(int64(c.GetInt(middleware.UidKey)), form.UploadId, form.Index, fh); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
+
+// @Summary 已上传的分片，用于断点续传
+// @Tags 上传
+// @Param uploadId query string true "上传id"
+// @Param total query int true "分片总数"
+// @Success 200 {object} jsonresult.JsonResult
+// @Security ApiKeyAuth
+// @Router /api/upload/chunks [get]
+func (u *UploadController) UploadedChunks(c *gin.Context) {
+	var form struct {
+		UploadId string `form:"uploadId" binding:"required"`
+		Total    int    `form:"total" binding:"required,min=1,max=10000"`
+	}
+	if err := c.ShouldBindQuery(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	indexes, err := upload.UploadedChunks(int64(c.GetInt(middleware.UidKey)), form.UploadId, form.Total)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, gin.H{"uploaded": indexes})
+}
+
+type mergeForm struct {
+	UploadId string `form:"uploadId" json:"uploadId" binding:"required"`
+	Name     string `form:"name" json:"name" binding:"required"`
+	Total    int    `form:"total" json:"total" binding:"required,min=1,max=10000"`
+	Dir      string `form:"dir" json:"dir"`
+}
+
+// @Summary 合并分片
+// @Tags 上传
+// @Param uploadId formData string true "上传id"
+// @Param name formData string true "文件名"
+// @Param total formData int true "分片总数"
+// @Success 200 {object} jsonresult.JsonResult
+// @Security ApiKeyAuth
+// @Router /api/upload/merge [post]
+func (u *UploadController) MergeChunks(c *gin.Context) {
+	var form mergeForm
+	if err := c.ShouldBind(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if !uploadDirs[form.Dir] {
+		form.Dir = "files"
+	}
+	file, err := upload.MergeChunks(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), form.UploadId, form.Name, form.Total, form.Dir)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, file)
+}
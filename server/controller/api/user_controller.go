@@ -0,0 +1,157 @@
+package api
+
+import (
+	"go-skeleton/middleware"
+	"go-skeleton/pkg/auth"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/oauth"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserController struct {
+}
+
+// @Tags 用户
+// @Summary 当前登录用户的资料
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=model.User}
+// @Router /api/user/profile [get]
+func (u *UserController) Profile(c *gin.Context) {
+	user := services.UserService.Get(c.Request.Context(), int64(c.GetInt(middleware.UidKey)))
+	if user == nil {
+		resp.Error(c, errors.NotFoundError)
+		return
+	}
+	resp.OK(c, user)
+}
+
+type profileForm struct {
+	Nickname string `form:"nickname" json:"nickname"`
+	Avatar   string `form:"avatar" json:"avatar"`
+	Email    string `form:"email" json:"email"`
+	// 界面语言，eg: zh, en
+	Locale string `form:"locale" json:"locale"`
+	// 时区，eg: Asia/Shanghai
+	Timezone string `form:"timezone" json:"timezone"`
+}
+
+// @Tags 用户
+// @Summary 修改个人资料，只修改不为空的字段
+// @Param object body profileForm true "资料"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=model.User}
+// @Router /api/user/profile [post]
+func (u *UserController) UpdateProfile(c *gin.Context) {
+	var form profileForm
+	if err := c.ShouldBind(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	user, err := services.UserService.UpdateProfile(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), form.Nickname, form.Avatar, form.Email, form.Locale, form.Timezone)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, user)
+}
+
+type passwordForm struct {
+	OldPassword string `form:"oldPassword" json:"oldPassword"`
+	Password    string `form:"password" json:"password" binding:"required"`
+	RePassword  string `form:"rePassword" json:"rePassword" binding:"required"`
+}
+
+// @Tags 用户
+// @Summary 修改密码，成功后当前token失效，需要重新登录
+// @Param object body passwordForm true "密码"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /api/user/password [post]
+func (u *UserController) ChangePassword(c *gin.Context) {
+	var form passwordForm
+	if err := c.ShouldBind(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	err := services.UserService.ChangePassword(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), form.OldPassword, form.Password, form.RePassword)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	if claims := middleware.GetClaims(c); claims != nil {
+		_ = auth.Revoke(claims)
+	}
+	resp.Success(c)
+}
+
+// @Tags 用户
+// @Summary 绑定第三方账号的授权地址
+// @Param provider query string true "github、google、wechat"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /api/user/oauth/authorize [get]
+func (u *UserController) OAuthAuthorize(c *gin.Context) {
+	url, state, err := services.OAuthService.AuthURL(c.Request.Context(), c.Query("provider"), int64(c.GetInt(middleware.UidKey)))
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	oauth.SetStateCookie(c, state)
+	resp.OK(c, gin.H{"url": url})
+}
+
+// @Tags 用户
+// @Summary 已绑定的第三方账号
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=[]model.UserOAuth}
+// @Router /api/user/oauth [get]
+func (u *UserController) OAuthBindings(c *gin.Context) {
+	resp.OK(c, services.OAuthService.Bindings(c.Request.Context(), int64(c.GetInt(middleware.UidKey))))
+}
+
+type oauthBindForm struct {
+	Provider string `form:"provider" json:"provider" binding:"required"`
+	Code     string `form:"code" json:"code" binding:"required"`
+	State    string `form:"state" json:"state" binding:"required"`
+}
+
+// @Tags 用户
+// @Summary 绑定第三方账号
+// @Param object body oauthBindForm true "授权信息"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult{data=model.UserOAuth}
+// @Router /api/user/oauth/bind [post]
+func (u *UserController) OAuthBind(c *gin.Context) {
+	var form oauthBindForm
+	if err := c.ShouldBind(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if !oauth.CheckStateCookie(c, form.State) {
+		resp.Error(c, errors.OAuthStateError)
+		return
+	}
+	binding, err := services.OAuthService.Bind(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), form.Provider, form.Code, form.State)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, binding)
+}
+
+// @Tags 用户
+// @Summary 解绑第三方账号
+// @Param provider formData string true "github、google、wechat"
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /api/user/oauth/unbind [post]
+func (u *UserController) OAuthUnbind(c *gin.Context) {
+	if err := services.OAuthService.Unbind(c.Request.Context(), int64(c.GetInt(middleware.UidKey)), c.PostForm("provider")); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
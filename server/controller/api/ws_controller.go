@@ -0,0 +1,72 @@
+package api
+
+import (
+	"go-skeleton/middleware"
+	"go-skeleton/pkg/auth"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/pkg/ws"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type WsController struct {
+}
+
+// @Tags websocket
+// @Summary 获取建立websocket连接的一次性凭证，有效期30秒
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 200 {object} jsonresult.JsonResult
+// @Router /api/ws/ticket [post]
+func (w *WsController) Ticket(c *gin.Context) {
+	ticket, err := ws.NewTicket(c.Request.Context(), int64(c.GetInt(middleware.UidKey)))
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, gin.H{"ticket": ticket, "expiresIn": int(ws.TicketTTL.Seconds())})
+}
+
+// @Tags websocket
+// @Summary 建立websocket连接，浏览器无法设置请求头，可以通过ticket参数传递/api/ws/ticket获取的凭证
+// @Param ticket query string false "一次性连接凭证，或使用Authorization: Bearer"
+// @Router /api/ws [get]
+func (w *WsController) Connect(c *gin.Context) {
+	var uid int64
+	if ticket := c.Query("ticket"); ticket != "" {
+		id, err := ws.ConsumeTicket(c.Request.Context(), ticket)
+		if err == errors.WsTicketError {
+			resp.Abort(c, http.StatusUnauthorized, errors.WsTicketError)
+			return
+		}
+		if err != nil {
+			zap.L().Error("consume ws ticket failed", zap.Error(err))
+			resp.Abort(c, http.StatusServiceUnavailable, errors.InternalError)
+			return
+		}
+		uid = id
+	} else {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token == "" {
+			resp.Abort(c, http.StatusUnauthorized, errors.TokenExistError)
+			return
+		}
+		claims, err := auth.CheckToken(token)
+		if err != nil {
+			resp.Abort(c, http.StatusUnauthorized, err)
+			return
+		}
+		uid = int64(claims.Id)
+	}
+	if ws.Default() == nil {
+		resp.Abort(c, http.StatusServiceUnavailable, errors.InternalError)
+		return
+	}
+	if err := ws.Default().Serve(c.Writer, c.Request, uid); err != nil {
+		// 升级失败时upgrader已经写入了响应
+		zap.L().Debug("ws upgrade failed", zap.Error(err))
+	}
+}
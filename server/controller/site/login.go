@@ -3,10 +3,10 @@ package site
 import (
 	"fmt"
 	"go-skeleton/pkg/auth"
-	"go-skeleton/pkg/errors"
+	"go-skeleton/middleware"
 	"go-skeleton/pkg/jsonresult"
+	"go-skeleton/pkg/resp"
 	"go-skeleton/services"
-	"go-skeleton/utils"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -20,7 +20,7 @@ type LoginForm struct {
 func Register(c *gin.Context) {
 	var form LoginForm
 	if c.ShouldBind(&form) == nil {
-		user, err := services.UserService.SignUp(form.User, form.Password, form.Password)
+		user, err := services.UserService.SignUp(c.Request.Context(), form.User, form.Password, form.Password)
 		if err != nil {
 			c.JSON(http.StatusOK, jsonresult.JsonError(err))
 			return
@@ -36,57 +36,56 @@ func Login(c *gin.Context) {
 	var form LoginForm
 	if c.ShouldBind(&form) == nil {
 		//测试账号 admin  123456
-		userService := services.UserService
-		user := userService.Take(map[string]interface{}{"username": form.User})
-
-		if user != nil && utils.ValidatePassword(user.Password, form.Password) {
-			token, err := auth.GenerateToken(int(user.ID))
-			if err != nil {
-				c.JSON(200, jsonresult.JsonError(err))
-				return
-			}
-			//refreshToken应该放入数据库或者缓存中
-			refreshToken, err := services.UserTokenService.GenerateRefreshToken(int64(user.ID))
-			if err != nil {
-				c.JSON(200, jsonresult.JsonError(err))
-				return
-			}
-			c.JSON(200, jsonresult.JsonData(gin.H{
-				"refreshToken": refreshToken,
-				"token":        token,
-				"user":         user,
-			}))
+		user, err := services.UserService.SignIn(c.Request.Context(), form.User, form.Password, c.ClientIP())
+		if err != nil {
+			resp.Error(c, err)
 			return
-		} else {
-			c.JSON(200, jsonresult.JsonErrorMsg("unauthorized"))
+		}
+		pair, err := auth.GenerateTokenPair(int(user.ID))
+		if err != nil {
+			c.JSON(200, jsonresult.JsonError(err))
 			return
 		}
+		c.JSON(200, jsonresult.JsonData(gin.H{
+			"refreshToken": pair.RefreshToken,
+			"token":        pair.Token,
+			"expiresAt":    pair.ExpiresAt,
+			"user":         user,
+		}))
+		return
 	} else {
 		c.JSON(200, jsonresult.JsonErrorMsg("缺少参数"))
 		return
 	}
 }
 
-//刷新token
+//刷新token，旧的refreshToken会失效
 func RefreshAccessToken(c *gin.Context) {
 	refreshToken := c.PostForm("refreshToken")
 	if refreshToken == "" {
 		c.JSON(200, jsonresult.JsonErrorMsg("refreshToken不能为空"))
-	}
-	userId, err := services.UserTokenService.GetUserIdByToken(refreshToken)
-	if userId == 0 {
-		c.JSON(200, jsonresult.JsonCodeError(errors.RefreshTokenError))
 		return
 	}
-	token, err := auth.GenerateToken(int(userId))
+	pair, err := auth.Refresh(refreshToken)
 	if err != nil {
-		c.JSON(200, jsonresult.JsonError(err))
+		c.JSON(200, jsonresult.JsonCodeError(err))
 		return
 	}
-	c.JSON(200, jsonresult.JsonData(gin.H{
-		"token": token,
-	}))
-	return
+	c.JSON(200, jsonresult.JsonData(pair))
+}
+
+//退出登录，吊销当前token和refreshToken
+func Logout(c *gin.Context) {
+	if claims := middleware.GetClaims(c); claims != nil {
+		if err := auth.Revoke(claims); err != nil {
+			c.JSON(200, jsonresult.JsonError(err))
+			return
+		}
+	}
+	if refreshToken := c.PostForm("refreshToken"); refreshToken != "" {
+		_ = auth.RevokeToken(refreshToken)
+	}
+	c.JSON(200, jsonresult.JsonSuccess())
 }
 
 func Upload(c *gin.Context) {
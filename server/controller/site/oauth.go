@@ -0,0 +1,63 @@
+package site
+
+import (
+	"go-skeleton/pkg/auth"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/oauth"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthProviders 已开启的第三方登录方式
+func OAuthProviders(c *gin.Context) {
+	resp.OK(c, oauth.Providers())
+}
+
+// OAuthAuthorize 第三方授权页面的地址，前端跳转后由RedirectUrl接收code和state，
+// state同时写入cookie，提交登录时需要带上该cookie
+func OAuthAuthorize(c *gin.Context) {
+	url, state, err := services.OAuthService.AuthURL(c.Request.Context(), c.Query("provider"), 0)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	oauth.SetStateCookie(c, state)
+	resp.OK(c, gin.H{"url": url})
+}
+
+type oauthForm struct {
+	Provider string `form:"provider" json:"provider" binding:"required"`
+	Code     string `form:"code" json:"code" binding:"required"`
+	State    string `form:"state" json:"state" binding:"required"`
+}
+
+// OAuthLogin 第三方登录，未绑定的第三方账号自动注册
+func OAuthLogin(c *gin.Context) {
+	var form oauthForm
+	if err := c.ShouldBind(&form); err != nil {
+		resp.Error(c, errors.InvalidParamsError)
+		return
+	}
+	if !oauth.CheckStateCookie(c, form.State) {
+		resp.Error(c, errors.OAuthStateError)
+		return
+	}
+	user, err := services.OAuthService.Login(c.Request.Context(), form.Provider, form.Code, form.State, c.ClientIP())
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	pair, err := auth.GenerateTokenPair(int(user.ID))
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, gin.H{
+		"refreshToken": pair.RefreshToken,
+		"token":        pair.Token,
+		"expiresAt":    pair.ExpiresAt,
+		"user":         user,
+	})
+}
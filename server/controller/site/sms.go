@@ -0,0 +1,25 @@
+package site
+
+import (
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/i18n"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/pkg/sms"
+	"go-skeleton/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SendSmsCode 发送短信验证码，scene为login、register、reset_password
+func SendSmsCode(c *gin.Context) {
+	phone := c.PostForm("phone")
+	if !utils.IsMobile(phone) {
+		resp.Error(c, errors.NewError(errors.InvalidParamsError.Code, i18n.T(c, "sms.invalid_phone")))
+		return
+	}
+	if err := sms.SendCode(c, c.PostForm("scene"), phone); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.Success(c)
+}
@@ -1,9 +1,17 @@
 package dao
 
 import (
+	"context"
+	"errors"
 	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/gcache"
 	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/pkg/tenant"
+	"strings"
+	"time"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -16,13 +24,94 @@ func newArticleDao() *articleDao {
 type articleDao struct {
 }
 
+// 按id查询文章的缓存，按租户区分，避免不同租户共用缓存
+var articleCache = gcache.NewCached("article", time.Hour).PerTenant()
+
+// Get 按id查询，优先读取缓存
 func (c *articleDao) Get(db *gorm.DB, id int64) *model.Article {
+	return c.GetCtx(db.Statement.Context, db, id)
+}
+
+// GetCtx 查询和缓存都使用ctx，请求取消或超时后不再等待。
+// 事务中直接查询数据库，不读写缓存，避免读到旧数据或将未提交的数据写入缓存
+func (c *articleDao) GetCtx(ctx context.Context, db *gorm.DB, id int64) *model.Article {
+	if simpleDb.InTx(db) {
+		ret, err := c.load(db.WithContext(ctx), id)
+		if err != nil || ret == nil {
+			return nil
+		}
+		return ret
+	}
+	ret := &model.Article{}
+	err := articleCache.Get(ctx, id, ret, func() (interface{}, error) {
+		if code, err := c.load(db.WithContext(ctx), id); err != nil || code != nil {
+			return code, err
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil
+	}
+	return ret
+}
+
+// load 文章不存在时返回nil
+func (c *articleDao) load(db *gorm.DB, id int64) (*model.Article, error) {
 	code := &model.Article{}
-	res := db.First(code, id)
-	if res.Error != nil {
+	if err := db.First(code, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return code, nil
+}
+
+// Forget 删除文章缓存，不通过dao修改文章后调用
+func (c *articleDao) Forget(db *gorm.DB, ids ...int64) {
+	c.forget(db, ids...)
+}
+
+// forget 数据变更后删除缓存，在simpleDb.Transaction中时提交后再删除。
+// db的context中没有租户时(定时任务、队列等)查出文章所属的租户再删除
+func (c *articleDao) forget(db *gorm.DB, ids ...int64) {
+	if len(ids) == 0 {
+		return
+	}
+	ctx := db.Statement.Context
+	rows := c.tenantRows(db, ids)
+	simpleDb.AfterCommit(db, func() {
+		if err := articleCache.Del(ctx, c.cacheIds(ids)...); err != nil {
+			zap.L().Warn("delete article cache failed", zap.Int64s("ids", ids), zap.Error(err))
+		}
+		for _, row := range rows {
+			if err := articleCache.Del(tenant.WithTenant(context.Background(), row.TenantId), row.ID); err != nil {
+				zap.L().Warn("delete article cache failed", zap.Uint("id", row.ID), zap.Error(err))
+			}
+		}
+	})
+}
+
+// tenantRows context中没有租户且开启了多租户时，查出文章所属的租户
+func (c *articleDao) tenantRows(db *gorm.DB, ids []int64) (rows []model.Article) {
+	if _, ok := db.Config.Plugins["tenant"]; !ok {
 		return nil
 	}
-	return code
+	ctx := db.Statement.Context
+	if _, ok := tenant.FromContext(ctx); ok && !tenant.Skipped(ctx) {
+		return nil
+	}
+	db.Session(&gorm.Session{NewDB: true}).WithContext(tenant.SkipContext(ctx)).Unscoped().
+		Select("id", "tenant_id").Where("id IN ?", ids).Find(&rows)
+	return rows
+}
+
+func (c *articleDao) cacheIds(ids []int64) []interface{} {
+	ret := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		ret = append(ret, id)
+	}
+	return ret
 }
 
 func (c *articleDao) Take(db *gorm.DB, where ...interface{}) *model.Article {
@@ -52,22 +141,95 @@ func (c *articleDao) Create(db *gorm.DB, t *model.Article) (err error) {
 }
 
 func (c *articleDao) Update(db *gorm.DB, t *model.Article) (err error) {
-	err = db.Save(t).Error
+	if err = db.Save(t).Error; err == nil {
+		c.forget(db, int64(t.ID))
+	}
 	return
 }
 
 func (c *articleDao) Updates(db *gorm.DB, id int64, columns map[string]interface{}) (err error) {
-	err = db.Model(&model.Article{}).Where("id = ?", id).Updates(columns).Error
+	if err = db.Model(&model.Article{}).Where("id = ?", id).Updates(columns).Error; err == nil {
+		c.forget(db, id)
+	}
+	return
+}
+
+// UpdateWithVersion 按版本号更新整条记录，已被其他请求修改时返回simpleDb.ErrStaleObject
+func (c *articleDao) UpdateWithVersion(db *gorm.DB, t *model.Article) (err error) {
+	if err = simpleDb.UpdateWithVersion(db, t); err == nil {
+		c.forget(db, int64(t.ID))
+	}
+	return
+}
+
+// UpdatesWithVersion 按版本号更新部分字段，已被其他请求修改时返回simpleDb.ErrStaleObject
+func (c *articleDao) UpdatesWithVersion(db *gorm.DB, id, version int64, columns map[string]interface{}) (err error) {
+	if err = simpleDb.UpdatesWithVersion(db, &model.Article{}, id, version, columns); err == nil {
+		c.forget(db, id)
+	}
 	return
 }
 
 func (c *articleDao) UpdateColumn(db *gorm.DB, id int64, name string, value interface{}) (err error) {
-	err = db.Model(&model.Article{}).Where("id = ?", id).UpdateColumn(name, value).Error
+	if err = db.Model(&model.Article{}).Where("id = ?", id).UpdateColumn(name, value).Error; err == nil {
+		c.forget(db, id)
+	}
+	return
+}
+
+// Delete 软删除，设置deleted_at
+func (c *articleDao) Delete(db *gorm.DB, id int64) (err error) {
+	if err = db.Delete(&model.Article{}, "id = ?", id).Error; err == nil {
+		c.forget(db, id)
+	}
+	return
+}
+
+// UpdatesByCnd 按条件批量更新，返回影响的行数，条件为空时返回simpleDb.ErrEmptyCondition
+func (c *articleDao) UpdatesByCnd(db *gorm.DB, cnd *simpleDb.SqlCnd, columns map[string]interface{}) (int64, error) {
+	ids := c.pluckIds(db, cnd)
+	n, err := cnd.Updates(db, &model.Article{}, columns)
+	if err == nil {
+		c.forget(db, ids...)
+	}
+	return n, err
+}
+
+// DeleteByCnd 按条件批量软删除，返回影响的行数，条件为空时返回simpleDb.ErrEmptyCondition
+func (c *articleDao) DeleteByCnd(db *gorm.DB, cnd *simpleDb.SqlCnd) (int64, error) {
+	ids := c.pluckIds(db, cnd)
+	n, err := cnd.Delete(db, &model.Article{})
+	if err == nil {
+		c.forget(db, ids...)
+	}
+	return n, err
+}
+
+// pluckIds 批量修改前查出受影响的id，用于删除缓存
+func (c *articleDao) pluckIds(db *gorm.DB, cnd *simpleDb.SqlCnd) (ids []int64) {
+	if len(cnd.Params) == 0 {
+		return
+	}
+	ids = []int64{}
+	q := &simpleDb.SqlCnd{Params: cnd.Params, Trashed: cnd.Trashed}
+	q.Cols("id").Find(db.Model(&model.Article{}), &ids)
+	return
+}
+
+// Restore 恢复软删除的数据
+func (c *articleDao) Restore(db *gorm.DB, id int64) (err error) {
+	if err = db.Unscoped().Model(&model.Article{}).Where("id = ?", id).Update("deleted_at", nil).Error; err == nil {
+		c.forget(db, id)
+	}
 	return
 }
 
-func (c *articleDao) Delete(db *gorm.DB, id int64) {
-	db.Delete(&model.Article{}, "id = ?", id)
+// ForceDelete 物理删除
+func (c *articleDao) ForceDelete(db *gorm.DB, id int64) (err error) {
+	if err = db.Unscoped().Delete(&model.Article{}, "id = ?", id).Error; err == nil {
+		c.forget(db, id)
+	}
+	return
 }
 
 // BatchSave 批量插入数据
@@ -108,3 +270,113 @@ func (c *articleDao) FindPageByCnd(db *gorm.DB, cnd *simpleDb.SqlCnd) (list []mo
 	}
 	return
 }
+
+// FindPageByCndCtx 同FindPageByCnd，ctx取消或超时后查询中止
+func (c *articleDao) FindPageByCndCtx(ctx context.Context, db *gorm.DB, cnd *simpleDb.SqlCnd) ([]model.Article, *simpleDb.Paging) {
+	return c.FindPageByCnd(db.WithContext(ctx), cnd)
+}
+
+// FindCursorPage 游标分页，适用于深度翻页，paging.Cursor为下一页的游标，游标无效时返回simpleDb.ErrInvalidCursor
+func (c *articleDao) FindCursorPage(db *gorm.DB, cnd *simpleDb.SqlCnd) (list []model.Article, paging *simpleDb.Paging, err error) {
+	cursor, hasMore, err := cnd.FindCursor(db, &list)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paging = &simpleDb.Paging{
+		Limit:   cnd.Paging.Limit,
+		Cursor:  cursor,
+		HasMore: hasMore,
+	}
+	return
+}
+
+// SlugExists slug是否已被其他文章使用，包含已软删除的文章
+func (c *articleDao) SlugExists(db *gorm.DB, slug string, excludeId int64) bool {
+	var count int64
+	db.Unscoped().Model(&model.Article{}).Where("slug = ? AND id <> ?", slug, excludeId).Count(&count)
+	return count > 0
+}
+
+// AddArticleTags 添加文章标签
+func (c *articleDao) AddArticleTags(db *gorm.DB, articleId int64, tagIds []int64) error {
+	if len(tagIds) == 0 {
+		return nil
+	}
+	list := make([]model.ArticleTag, 0, len(tagIds))
+	for _, tagId := range tagIds {
+		list = append(list, model.ArticleTag{ArticleId: articleId, TagId: tagId, Status: constants.StatusOk})
+	}
+	return db.Create(&list).Error
+}
+
+// AttachTags 为文章添加标签，已关联的标签会忽略
+func (c *articleDao) AttachTags(db *gorm.DB, articleId int64, tagIds []int64) error {
+	if len(tagIds) == 0 {
+		return nil
+	}
+	var exists []int64
+	if err := db.Model(&model.ArticleTag{}).Where("article_id = ? AND tag_id IN ?", articleId, tagIds).
+		Pluck("tag_id", &exists).Error; err != nil {
+		return err
+	}
+	attached := make(map[int64]bool, len(exists))
+	for _, id := range exists {
+		attached[id] = true
+	}
+	var newIds []int64
+	for _, id := range tagIds {
+		if !attached[id] {
+			attached[id] = true
+			newIds = append(newIds, id)
+		}
+	}
+	return c.AddArticleTags(db, articleId, newIds)
+}
+
+// DetachTags 移除文章的指定标签
+func (c *articleDao) DetachTags(db *gorm.DB, articleId int64, tagIds []int64) error {
+	if len(tagIds) == 0 {
+		return nil
+	}
+	return db.Unscoped().Where("article_id = ? AND tag_id IN ?", articleId, tagIds).Delete(&model.ArticleTag{}).Error
+}
+
+// DeleteArticleTags 删除文章的所有标签
+func (c *articleDao) DeleteArticleTags(db *gorm.DB, articleId int64) error {
+	return db.Unscoped().Where("article_id = ?", articleId).Delete(&model.ArticleTag{}).Error
+}
+
+// IncrCommentCount 原子增减文章的评论数
+func (c *articleDao) IncrCommentCount(db *gorm.DB, articleId, n int64) (err error) {
+	if err = db.Model(&model.Article{}).Where("id = ?", articleId).
+		UpdateColumn("comment_count", gorm.Expr("GREATEST(comment_count + ?, 0)", n)).Error; err == nil {
+		c.forget(db, articleId)
+	}
+	return
+}
+
+// IncrReadCounts 批量增加文章的阅读数，key为文章id，一条update语句完成
+func (c *articleDao) IncrReadCounts(db *gorm.DB, deltas map[int64]int64) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+	var (
+		sql  strings.Builder
+		args = make([]interface{}, 0, len(deltas)*2+1)
+		ids  = make([]int64, 0, len(deltas))
+	)
+	sql.WriteString("CASE id")
+	for id, n := range deltas {
+		sql.WriteString(" WHEN ? THEN ?")
+		args = append(args, id, n)
+		ids = append(ids, id)
+	}
+	sql.WriteString(" ELSE 0 END")
+	err := db.Model(&model.Article{}).Where("id IN ?", ids).
+		UpdateColumn("read_count", gorm.Expr("read_count + "+sql.String(), args...)).Error
+	if err == nil {
+		c.forget(db, ids...)
+	}
+	return err
+}
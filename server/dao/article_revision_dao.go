@@ -0,0 +1,102 @@
+package dao
+
+import (
+	"go-skeleton/model"
+	"go-skeleton/pkg/simpleDb"
+
+	"gorm.io/gorm"
+)
+
+var ArticleRevisionDao = newArticleRevisionDao()
+
+func newArticleRevisionDao() *articleRevisionDao {
+	return &articleRevisionDao{}
+}
+
+type articleRevisionDao struct {
+}
+
+func (c *articleRevisionDao) Get(db *gorm.DB, id int64) *model.ArticleRevision {
+	ret := &model.ArticleRevision{}
+	if err := db.First(ret, "id = ?", id).Error; err != nil {
+		return nil
+	}
+	return ret
+}
+
+func (c *articleRevisionDao) Create(db *gorm.DB, t *model.ArticleRevision) error {
+	return db.Create(t).Error
+}
+
+// Latest 文章最新的版本，autosave为nil时不区分是否自动保存
+func (c *articleRevisionDao) Latest(db *gorm.DB, articleId int64, autosave *bool) *model.ArticleRevision {
+	ret := &model.ArticleRevision{}
+	q := db.Where("article_id = ?", articleId)
+	if autosave != nil {
+		q = q.Where("autosave = ?", *autosave)
+	}
+	if err := q.Order("id DESC").First(ret).Error; err != nil {
+		return nil
+	}
+	return ret
+}
+
+// Overwrite 覆盖版本的内容和差异，用于合并间隔内的自动保存
+func (c *articleRevisionDao) Overwrite(db *gorm.DB, t *model.ArticleRevision) error {
+	return db.Model(&model.ArticleRevision{}).Where("id = ?", t.ID).Updates(map[string]interface{}{
+		"user_id":       t.UserId,
+		"version":       t.Version,
+		"title":         t.Title,
+		"cid":           t.Cid,
+		"desc":          t.Desc,
+		"content":       t.Content,
+		"img":           t.Img,
+		"fields":        t.Fields,
+		"lines_added":   t.LinesAdded,
+		"lines_removed": t.LinesRemoved,
+		"created_at":    t.CreatedAt,
+	}).Error
+}
+
+// FindPageByCnd 列表不查询内容
+func (c *articleRevisionDao) FindPageByCnd(db *gorm.DB, cnd *simpleDb.SqlCnd) (list []model.ArticleRevision, paging *simpleDb.Paging) {
+	cnd.Find(db.Omit("content"), &list)
+	count := cnd.Count(db, &model.ArticleRevision{})
+
+	paging = &simpleDb.Paging{
+		Page:  cnd.Paging.Page,
+		Limit: cnd.Paging.Limit,
+		Total: count,
+	}
+	return
+}
+
+// FindPrunableArticleIds 版本数超过keep的文章
+func (c *articleRevisionDao) FindPrunableArticleIds(db *gorm.DB, keep int, afterId int64, limit int) (ids []int64, err error) {
+	err = db.Model(&model.ArticleRevision{}).
+		Where("article_id > ?", afterId).
+		Group("article_id").
+		Having("COUNT(*) > ?", keep).
+		Order("article_id").
+		Limit(limit).
+		Pluck("article_id", &ids).Error
+	return
+}
+
+// Prune 删除文章最近keep个之外且早于before的版本，返回删除的数量
+func (c *articleRevisionDao) Prune(db *gorm.DB, articleId int64, keep int, before int64) (int64, error) {
+	var boundary []int64
+	err := db.Model(&model.ArticleRevision{}).
+		Where("article_id = ?", articleId).
+		Order("id DESC").Offset(keep).Limit(1).
+		Pluck("id", &boundary).Error
+	if err != nil || len(boundary) == 0 {
+		return 0, err
+	}
+	q := db.Where("article_id = ? AND id <= ?", articleId, boundary[0])
+	if before > 0 {
+		q = q.Where("created_at < ?", before)
+	}
+	ret := q.Delete(&model.ArticleRevision{})
+	return ret.RowsAffected, ret.Error
+}
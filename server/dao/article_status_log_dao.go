@@ -0,0 +1,26 @@
+package dao
+
+import (
+	"go-skeleton/model"
+
+	"gorm.io/gorm"
+)
+
+var ArticleStatusLogDao = newArticleStatusLogDao()
+
+func newArticleStatusLogDao() *articleStatusLogDao {
+	return &articleStatusLogDao{}
+}
+
+type articleStatusLogDao struct {
+}
+
+func (c *articleStatusLogDao) Create(db *gorm.DB, t *model.ArticleStatusLog) error {
+	return db.Create(t).Error
+}
+
+// FindByArticleId 文章的状态变更记录，按时间正序
+func (c *articleStatusLogDao) FindByArticleId(db *gorm.DB, articleId int64) (list []model.ArticleStatusLog) {
+	db.Where("article_id = ?", articleId).Order("id").Find(&list)
+	return
+}
@@ -0,0 +1,42 @@
+package dao
+
+import (
+	"go-skeleton/model"
+	"go-skeleton/pkg/simpleDb"
+
+	"gorm.io/gorm"
+)
+
+var AuditLogDao = newAuditLogDao()
+
+func newAuditLogDao() *auditLogDao {
+	return &auditLogDao{}
+}
+
+type auditLogDao struct {
+}
+
+func (c *auditLogDao) Get(db *gorm.DB, id int64) *model.AuditLog {
+	ret := &model.AuditLog{}
+	if err := db.First(ret, id).Error; err != nil {
+		return nil
+	}
+	return ret
+}
+
+func (c *auditLogDao) Find(db *gorm.DB, cnd *simpleDb.SqlCnd) (list []model.AuditLog) {
+	cnd.Find(db, &list)
+	return
+}
+
+func (c *auditLogDao) FindPageByCnd(db *gorm.DB, cnd *simpleDb.SqlCnd) (list []model.AuditLog, paging *simpleDb.Paging) {
+	cnd.Find(db, &list)
+	count := cnd.Count(db, &model.AuditLog{})
+
+	paging = &simpleDb.Paging{
+		Page:  cnd.Paging.Page,
+		Limit: cnd.Paging.Limit,
+		Total: count,
+	}
+	return
+}
@@ -0,0 +1,69 @@
+package dao
+
+import (
+	"go-skeleton/model"
+	"go-skeleton/pkg/simpleDb"
+
+	"gorm.io/gorm"
+)
+
+var CategoryDao = newCategoryDao()
+
+func newCategoryDao() *categoryDao {
+	return &categoryDao{}
+}
+
+type categoryDao struct {
+}
+
+func (c *categoryDao) Get(db *gorm.DB, id uint64) *model.Category {
+	ret := &model.Category{}
+	if err := db.First(ret, id).Error; err != nil {
+		return nil
+	}
+	return ret
+}
+
+func (c *categoryDao) Find(db *gorm.DB, cnd *simpleDb.SqlCnd) (list []model.Category) {
+	cnd.Find(db, &list)
+	return
+}
+
+func (c *categoryDao) FindAll(db *gorm.DB) (list []model.Category) {
+	db.Order("id ASC").Find(&list)
+	return
+}
+
+// NameExists 分类名称是否已被其他分类使用
+func (c *categoryDao) NameExists(db *gorm.DB, name string, excludeId uint64) bool {
+	var count int64
+	db.Model(&model.Category{}).Where("name = ? AND id <> ?", name, excludeId).Count(&count)
+	return count > 0
+}
+
+func (c *categoryDao) Create(db *gorm.DB, t *model.Category) error {
+	return db.Create(t).Error
+}
+
+func (c *categoryDao) Updates(db *gorm.DB, id uint64, columns map[string]interface{}) error {
+	return db.Model(&model.Category{}).Where("id = ?", id).Updates(columns).Error
+}
+
+func (c *categoryDao) Delete(db *gorm.DB, id uint64) error {
+	return db.Delete(&model.Category{}, "id = ?", id).Error
+}
+
+// CountArticles 各分类下的文章数，key为分类id
+func (c *categoryDao) CountArticles(db *gorm.DB, status int) map[uint64]int64 {
+	var rows []struct {
+		Cid   uint64
+		Count int64
+	}
+	db.Model(&model.Article{}).Select("cid, COUNT(*) AS count").
+		Where("status = ?", status).Group("cid").Scan(&rows)
+	ret := make(map[uint64]int64, len(rows))
+	for _, row := range rows {
+		ret[row.Cid] = row.Count
+	}
+	return ret
+}
@@ -0,0 +1,97 @@
+package dao
+
+import (
+	"context"
+	"go-skeleton/model"
+	"go-skeleton/pkg/simpleDb"
+
+	"gorm.io/gorm"
+)
+
+var CommentDao = newCommentDao()
+
+func newCommentDao() *commentDao {
+	return &commentDao{}
+}
+
+type commentDao struct {
+}
+
+func (c *commentDao) Get(db *gorm.DB, id int64) *model.Comment {
+	ret := &model.Comment{}
+	if err := db.First(ret, id).Error; err != nil {
+		return nil
+	}
+	return ret
+}
+
+// GetCtx 同Get，ctx取消或超时后查询中止
+func (c *commentDao) GetCtx(ctx context.Context, db *gorm.DB, id int64) *model.Comment {
+	return c.Get(db.WithContext(ctx), id)
+}
+
+func (c *commentDao) Create(db *gorm.DB, t *model.Comment) error {
+	return db.Create(t).Error
+}
+
+func (c *commentDao) FindPageByCnd(db *gorm.DB, cnd *simpleDb.SqlCnd) (list []model.Comment, paging *simpleDb.Paging) {
+	cnd.Find(db, &list)
+	count := cnd.Count(db, &model.Comment{})
+
+	paging = &simpleDb.Paging{
+		Page:  cnd.Paging.Page,
+		Limit: cnd.Paging.Limit,
+		Total: count,
+	}
+	return
+}
+
+// FindPageByCndCtx 同FindPageByCnd，ctx取消或超时后查询中止
+func (c *commentDao) FindPageByCndCtx(ctx context.Context, db *gorm.DB, cnd *simpleDb.SqlCnd) ([]model.Comment, *simpleDb.Paging) {
+	return c.FindPageByCnd(db.WithContext(ctx), cnd)
+}
+
+// FindReplies 一级评论最早的limit条回复，key为一级评论id
+func (c *commentDao) FindReplies(db *gorm.DB, rootIds []int64, limit int) map[int64][]model.Comment {
+	ret := make(map[int64][]model.Comment, len(rootIds))
+	if len(rootIds) == 0 || limit <= 0 {
+		return ret
+	}
+	// 每个一级评论只取前limit条，避免热门评论的回复全部查出
+	for _, rootId := range rootIds {
+		var list []model.Comment
+		db.Where("root_id = ?", rootId).Order("id ASC").Limit(limit).Find(&list)
+		if len(list) > 0 {
+			ret[rootId] = list
+		}
+	}
+	return ret
+}
+
+// Delete 软删除评论，返回删除的条数
+func (c *commentDao) Delete(db *gorm.DB, id int64) (int64, error) {
+	ret := db.Delete(&model.Comment{}, "id = ?", id)
+	return ret.RowsAffected, ret.Error
+}
+
+// DeleteReplies 软删除一级评论下的所有回复，返回删除的条数
+func (c *commentDao) DeleteReplies(db *gorm.DB, rootId int64) (int64, error) {
+	ret := db.Where("root_id = ?", rootId).Delete(&model.Comment{})
+	return ret.RowsAffected, ret.Error
+}
+
+// UpdatesByCnd 按条件批量更新，返回影响的行数，条件为空时返回simpleDb.ErrEmptyCondition
+func (c *commentDao) UpdatesByCnd(db *gorm.DB, cnd *simpleDb.SqlCnd, columns map[string]interface{}) (int64, error) {
+	return cnd.Updates(db, &model.Comment{}, columns)
+}
+
+// DeleteByCnd 按条件批量软删除，返回影响的行数，条件为空时返回simpleDb.ErrEmptyCondition
+func (c *commentDao) DeleteByCnd(db *gorm.DB, cnd *simpleDb.SqlCnd) (int64, error) {
+	return cnd.Delete(db, &model.Comment{})
+}
+
+// IncrReplyCount 原子增减一级评论的回复数
+func (c *commentDao) IncrReplyCount(db *gorm.DB, rootId, n int64) error {
+	return db.Model(&model.Comment{}).Where("id = ?", rootId).
+		UpdateColumn("reply_count", gorm.Expr("GREATEST(reply_count + ?, 0)", n)).Error
+}
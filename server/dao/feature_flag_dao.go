@@ -0,0 +1,48 @@
+package dao
+
+import (
+	"go-skeleton/model"
+
+	"gorm.io/gorm"
+)
+
+var FeatureFlagDao = newFeatureFlagDao()
+
+func newFeatureFlagDao() *featureFlagDao {
+	return &featureFlagDao{}
+}
+
+type featureFlagDao struct {
+}
+
+func (c *featureFlagDao) Get(db *gorm.DB, id int64) *model.FeatureFlag {
+	ret := &model.FeatureFlag{}
+	if err := db.First(ret, id).Error; err != nil {
+		return nil
+	}
+	return ret
+}
+
+func (c *featureFlagDao) FindAll(db *gorm.DB) (list []model.FeatureFlag) {
+	db.Order("name ASC").Find(&list)
+	return
+}
+
+// NameExists 开关名称是否已被其他开关使用
+func (c *featureFlagDao) NameExists(db *gorm.DB, name string, excludeId int64) bool {
+	var count int64
+	db.Model(&model.FeatureFlag{}).Where("name = ? AND id <> ?", name, excludeId).Count(&count)
+	return count > 0
+}
+
+func (c *featureFlagDao) Create(db *gorm.DB, t *model.FeatureFlag) error {
+	return db.Create(t).Error
+}
+
+func (c *featureFlagDao) Save(db *gorm.DB, t *model.FeatureFlag) error {
+	return db.Save(t).Error
+}
+
+func (c *featureFlagDao) Delete(db *gorm.DB, id int64) error {
+	return db.Delete(&model.FeatureFlag{}, "id = ?", id).Error
+}
@@ -0,0 +1,131 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"go-skeleton/model"
+	"go-skeleton/pkg/simpleDb"
+
+	"gorm.io/gorm"
+)
+
+var NotificationDao = newNotificationDao()
+
+func newNotificationDao() *notificationDao {
+	return &notificationDao{}
+}
+
+type notificationDao struct {
+}
+
+// ErrNotificationShard notification的分表规则不是按hash分表
+var ErrNotificationShard = errors.New("dao: notification must be sharded by hash")
+
+// 配置了database.Shards.notification时按user_id分表，同一用户的通知在同一张分表，
+// 分表时主键需要使用snowflake，避免各分表的自增id重复。
+// 分表规则错误时返回错误，不回退到逻辑表，逻辑表只作为创建分表的模板
+func (c *notificationDao) table(db *gorm.DB, userId int64) (*gorm.DB, error) {
+	r := simpleDb.Shard((&model.Notification{}).TableName())
+	if r == nil {
+		return db, nil
+	}
+	if _, ok := r.(*simpleDb.HashShard); !ok {
+		return nil, ErrNotificationShard
+	}
+	return simpleDb.ShardDB(db, r, userId)
+}
+
+// tables 所有分表，未分表时为逻辑表
+func (c *notificationDao) tables() ([]string, error) {
+	base := (&model.Notification{}).TableName()
+	r := simpleDb.Shard(base)
+	if r == nil {
+		return []string{base}, nil
+	}
+	hash, ok := r.(*simpleDb.HashShard)
+	if !ok {
+		return nil, ErrNotificationShard
+	}
+	return hash.Tables(), nil
+}
+
+func (c *notificationDao) Create(db *gorm.DB, t *model.Notification) error {
+	tx, err := c.table(db, t.UserId)
+	if err != nil {
+		return err
+	}
+	return tx.Create(t).Error
+}
+
+// FindPageByCnd 查询用户的通知
+func (c *notificationDao) FindPageByCnd(db *gorm.DB, userId int64, cnd *simpleDb.SqlCnd) (list []model.Notification, paging *simpleDb.Paging, err error) {
+	tx, err := c.table(db, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+	// 查询和计数都从分表开始，不共用条件
+	tx = tx.Session(&gorm.Session{})
+	cnd.Find(tx, &list)
+	count := cnd.Count(tx, &model.Notification{})
+
+	paging = &simpleDb.Paging{
+		Page:  cnd.Paging.Page,
+		Limit: cnd.Paging.Limit,
+		Total: count,
+	}
+	return
+}
+
+// FindPageAcrossShards 跨分表查询所有用户的通知，按cnd的排序合并，只适合查询前几页
+func (c *notificationDao) FindPageAcrossShards(db *gorm.DB, cnd *simpleDb.SqlCnd) (list []model.Notification, paging *simpleDb.Paging, err error) {
+	tables, err := c.tables()
+	if err != nil {
+		return nil, nil, err
+	}
+	paging, err = simpleDb.FindShardPage(db, tables, cnd, &list)
+	return
+}
+
+// CountUnread 用户的未读通知数
+func (c *notificationDao) CountUnread(db *gorm.DB, userId int64) (count int64, err error) {
+	tx, err := c.table(db, userId)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.Model(&model.Notification{}).Where("user_id = ? AND read_at = 0", userId).Count(&count).Error
+	return
+}
+
+// Version 用户通知的版本，新增、删除或标记已读后会变化，用于生成ETag
+func (c *notificationDao) Version(db *gorm.DB, userId int64) (string, error) {
+	tx, err := c.table(db, userId)
+	if err != nil {
+		return "", err
+	}
+	var v struct {
+		Count  int64
+		MaxId  int64
+		ReadAt int64
+	}
+	err = tx.Model(&model.Notification{}).
+		Select("COUNT(*) AS count, COALESCE(MAX(id), 0) AS max_id, COALESCE(MAX(read_at), 0) AS read_at").
+		Where("user_id = ?", userId).Scan(&v).Error
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d-%d-%d", userId, v.Count, v.MaxId, v.ReadAt), nil
+}
+
+// MarkRead 将用户的通知标记为已读，ids为空时标记所有未读的通知，返回影响的行数
+func (c *notificationDao) MarkRead(db *gorm.DB, userId int64, ids []int64, readAt int64) (int64, error) {
+	tx, err := c.table(db, userId)
+	if err != nil {
+		return 0, err
+	}
+	q := tx.Model(&model.Notification{}).Where("user_id = ? AND read_at = 0", userId)
+	if len(ids) > 0 {
+		q = q.Where("id IN ?", ids)
+	}
+	ret := q.UpdateColumn("read_at", readAt)
+	return ret.RowsAffected, ret.Error
+}
@@ -0,0 +1,70 @@
+package dao
+
+import (
+	"go-skeleton/model"
+
+	"gorm.io/gorm"
+)
+
+var PermissionDao = newPermissionDao()
+
+func newPermissionDao() *permissionDao {
+	return &permissionDao{}
+}
+
+type permissionDao struct {
+}
+
+// FindRoleIdsByUserId 用户的角色id
+func (c *permissionDao) FindRoleIdsByUserId(db *gorm.DB, userId int64) (roleIds []int64) {
+	db.Model(&model.UserRole{}).Where("user_id = ?", userId).Pluck("role_id", &roleIds)
+	return
+}
+
+// FindUserIdsByRoleId 拥有角色的用户id
+func (c *permissionDao) FindUserIdsByRoleId(db *gorm.DB, roleId int64) (userIds []int64) {
+	db.Model(&model.UserRole{}).Where("role_id = ?", roleId).Pluck("user_id", &userIds)
+	return
+}
+
+// FindCodesByRoleIds 角色拥有的权限code
+func (c *permissionDao) FindCodesByRoleIds(db *gorm.DB, roleIds []int64) (codes []string) {
+	if len(roleIds) == 0 {
+		return
+	}
+	db.Model(&model.Permission{}).
+		Joins("JOIN role_permission ON role_permission.permission_id = permission.id").
+		Where("role_permission.role_id IN ?", roleIds).
+		Distinct().
+		Pluck("permission.code", &codes)
+	return
+}
+
+// FindRoleCodesByIds 角色的code
+func (c *permissionDao) FindRoleCodesByIds(db *gorm.DB, roleIds []int64) (codes []string) {
+	if len(roleIds) == 0 {
+		return
+	}
+	db.Model(&model.Role{}).Where("id IN ?", roleIds).Pluck("code", &codes)
+	return
+}
+
+// AddUserRole 给用户分配角色
+func (c *permissionDao) AddUserRole(db *gorm.DB, userId, roleId int64) error {
+	return db.Where(model.UserRole{UserId: userId, RoleId: roleId}).FirstOrCreate(&model.UserRole{}).Error
+}
+
+// RemoveUserRole 移除用户角色
+func (c *permissionDao) RemoveUserRole(db *gorm.DB, userId, roleId int64) error {
+	return db.Where("user_id = ? AND role_id = ?", userId, roleId).Delete(&model.UserRole{}).Error
+}
+
+// AddRolePermission 给角色分配权限
+func (c *permissionDao) AddRolePermission(db *gorm.DB, roleId, permissionId int64) error {
+	return db.Where(model.RolePermission{RoleId: roleId, PermissionId: permissionId}).FirstOrCreate(&model.RolePermission{}).Error
+}
+
+// RemoveRolePermission 移除角色权限
+func (c *permissionDao) RemoveRolePermission(db *gorm.DB, roleId, permissionId int64) error {
+	return db.Where("role_id = ? AND permission_id = ?", roleId, permissionId).Delete(&model.RolePermission{}).Error
+}
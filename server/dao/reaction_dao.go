@@ -0,0 +1,81 @@
+package dao
+
+import (
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/simpleDb"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ReactionDao = newReactionDao()
+
+func newReactionDao() *reactionDao {
+	return &reactionDao{}
+}
+
+type reactionDao struct {
+}
+
+// Create 添加，已存在时不处理，返回是否新增
+func (c *reactionDao) Create(db *gorm.DB, t *model.Reaction) (bool, error) {
+	ret := db.Clauses(clause.OnConflict{DoNothing: true}).Create(t)
+	return ret.RowsAffected > 0, ret.Error
+}
+
+// Delete 取消，返回是否删除了数据
+func (c *reactionDao) Delete(db *gorm.DB, userId int64, entityType string, entityId int64, typ string) (bool, error) {
+	ret := db.Where("user_id = ? AND entity_type = ? AND entity_id = ? AND type = ?", userId, entityType, entityId, typ).
+		Delete(&model.Reaction{})
+	return ret.RowsAffected > 0, ret.Error
+}
+
+// FindEntityIds 用户对entityIds中的哪些实体有过操作
+func (c *reactionDao) FindEntityIds(db *gorm.DB, userId int64, entityType string, entityIds []int64, typ string) map[int64]bool {
+	ret := make(map[int64]bool)
+	if len(entityIds) == 0 {
+		return ret
+	}
+	var ids []int64
+	db.Model(&model.Reaction{}).
+		Where("user_id = ? AND entity_type = ? AND entity_id IN ? AND type = ?", userId, entityType, entityIds, typ).
+		Pluck("entity_id", &ids)
+	for _, id := range ids {
+		ret[id] = true
+	}
+	return ret
+}
+
+func (c *reactionDao) FindPageByCnd(db *gorm.DB, cnd *simpleDb.SqlCnd) (list []model.Reaction, paging *simpleDb.Paging) {
+	cnd.Find(db, &list)
+	count := cnd.Count(db, &model.Reaction{})
+
+	paging = &simpleDb.Paging{
+		Page:  cnd.Paging.Page,
+		Limit: cnd.Paging.Limit,
+		Total: count,
+	}
+	return
+}
+
+// 保存计数的实体表
+var reactionCounterModels = map[string]interface{}{
+	constants.EntityArticle: &model.Article{},
+	constants.EntityComment: &model.Comment{},
+}
+
+// IncrCount 原子增减实体的{type}_count字段
+func (c *reactionDao) IncrCount(db *gorm.DB, entityType string, entityId int64, typ string, n int64) error {
+	m, ok := reactionCounterModels[entityType]
+	if !ok {
+		return nil
+	}
+	column := typ + "_count"
+	err := db.Model(m).Where("id = ?", entityId).
+		UpdateColumn(column, gorm.Expr("GREATEST("+column+" + ?, 0)", n)).Error
+	if err == nil && entityType == constants.EntityArticle {
+		ArticleDao.forget(db, entityId)
+	}
+	return err
+}
@@ -0,0 +1,39 @@
+package dao
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var StatDao = newStatDao()
+
+func newStatDao() *statDao {
+	return &statDao{}
+}
+
+type statDao struct {
+}
+
+// DailyCount 按天统计的数量，Date格式为2006-01-02
+type DailyCount struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+// CountByDay 按created_at统计since之后每天新增的数量，没有数据的日期不返回
+func (d *statDao) CountByDay(db *gorm.DB, model interface{}, since time.Time) (list []DailyCount, err error) {
+	err = db.Model(model).
+		Select("DATE_FORMAT(created_at, '%Y-%m-%d') AS date, COUNT(*) AS count").
+		Where("created_at >= ?", since).
+		Group("date").
+		Order("date ASC").
+		Scan(&list).Error
+	return
+}
+
+// Count 表的行数，软删除的数据不计入
+func (d *statDao) Count(db *gorm.DB, model interface{}) (count int64, err error) {
+	err = db.Model(model).Count(&count).Error
+	return
+}
@@ -0,0 +1,199 @@
+package dao
+
+import (
+	"context"
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/simpleDb"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var TagDao = newTagDao()
+
+func newTagDao() *tagDao {
+	return &tagDao{}
+}
+
+type tagDao struct {
+}
+
+func (c *tagDao) Get(db *gorm.DB, id int64) *model.Tag {
+	ret := &model.Tag{}
+	if err := db.First(ret, id).Error; err != nil {
+		return nil
+	}
+	return ret
+}
+
+// GetCtx 同Get，ctx取消或超时后查询中止
+func (c *tagDao) GetCtx(ctx context.Context, db *gorm.DB, id int64) *model.Tag {
+	return c.Get(db.WithContext(ctx), id)
+}
+
+func (c *tagDao) Find(db *gorm.DB, cnd *simpleDb.SqlCnd) (list []model.Tag) {
+	cnd.Find(db, &list)
+	return
+}
+
+func (c *tagDao) FindPageByCnd(db *gorm.DB, cnd *simpleDb.SqlCnd) (list []model.Tag, paging *simpleDb.Paging) {
+	cnd.Find(db, &list)
+	count := cnd.Count(db, &model.Tag{})
+
+	paging = &simpleDb.Paging{
+		Page:  cnd.Paging.Page,
+		Limit: cnd.Paging.Limit,
+		Total: count,
+	}
+	return
+}
+
+// FindPageByCndCtx 同FindPageByCnd，ctx取消或超时后查询中止
+func (c *tagDao) FindPageByCndCtx(ctx context.Context, db *gorm.DB, cnd *simpleDb.SqlCnd) ([]model.Tag, *simpleDb.Paging) {
+	return c.FindPageByCnd(db.WithContext(ctx), cnd)
+}
+
+func (c *tagDao) Create(db *gorm.DB, t *model.Tag) error {
+	return db.Create(t).Error
+}
+
+func (c *tagDao) Updates(db *gorm.DB, id int64, columns map[string]interface{}) error {
+	return db.Model(&model.Tag{}).Where("id = ?", id).Updates(columns).Error
+}
+
+// UpdatesByCnd 按条件批量更新，返回影响的行数，条件为空时返回simpleDb.ErrEmptyCondition
+func (c *tagDao) UpdatesByCnd(db *gorm.DB, cnd *simpleDb.SqlCnd, columns map[string]interface{}) (int64, error) {
+	return cnd.Updates(db, &model.Tag{}, columns)
+}
+
+// Delete 删除标签及其与文章的关联，名称有唯一索引所以直接物理删除
+func (c *tagDao) Delete(db *gorm.DB, id int64) error {
+	if err := db.Unscoped().Where("tag_id = ?", id).Delete(&model.ArticleTag{}).Error; err != nil {
+		return err
+	}
+	return db.Unscoped().Delete(&model.Tag{}, "id = ?", id).Error
+}
+
+// NameExists 标签名称是否已被其他标签使用
+func (c *tagDao) NameExists(db *gorm.DB, name string, excludeId int64) bool {
+	var count int64
+	db.Unscoped().Model(&model.Tag{}).Where("name = ? AND id <> ?", name, excludeId).Count(&count)
+	return count > 0
+}
+
+// FindWithCount 标签及其指定状态的文章数，按文章数倒序
+func (c *tagDao) FindWithCount(db *gorm.DB, articleStatus int, limit int) (list []model.TagCount) {
+	db.Model(&model.Tag{}).
+		Select("tag.*, COUNT(article.id) AS article_count").
+		Joins("LEFT JOIN article_tag ON article_tag.tag_id = tag.id AND article_tag.status = ?", constants.StatusOk).
+		Joins("LEFT JOIN article ON article.id = article_tag.article_id AND article.status = ? AND article.deleted_at IS NULL", articleStatus).
+		Where("tag.status = ?", constants.StatusOk).
+		Group("tag.id").
+		Order("article_count DESC").
+		Limit(limit).
+		Scan(&list)
+	return
+}
+
+// CountArticles 指定标签下指定状态的文章数，key为标签id
+func (c *tagDao) CountArticles(db *gorm.DB, articleStatus int, tagIds []int64) map[int64]int64 {
+	ret := make(map[int64]int64, len(tagIds))
+	if len(tagIds) == 0 {
+		return ret
+	}
+	var rows []struct {
+		TagId int64
+		Count int64
+	}
+	db.Model(&model.ArticleTag{}).
+		Select("article_tag.tag_id, COUNT(*) AS count").
+		Joins("JOIN article ON article.id = article_tag.article_id AND article.deleted_at IS NULL").
+		Where("article_tag.tag_id IN ? AND article_tag.status = ? AND article.status = ?", tagIds, constants.StatusOk, articleStatus).
+		Group("article_tag.tag_id").
+		Scan(&rows)
+	for _, row := range rows {
+		ret[row.TagId] = row.Count
+	}
+	return ret
+}
+
+func (c *tagDao) FindByNames(db *gorm.DB, names []string) (list []model.Tag) {
+	if len(names) == 0 {
+		return
+	}
+	db.Where("name in ?", names).Find(&list)
+	return
+}
+
+// GetOrCreates 按名称获取标签id，不存在的标签会自动创建，返回的id与names顺序一致(已去重)
+func (c *tagDao) GetOrCreates(db *gorm.DB, names []string) ([]int64, error) {
+	names = uniqueNames(names)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	tags := make([]model.Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, model.Tag{Name: name, Status: constants.StatusOk})
+	}
+	// name有唯一索引，并发创建同名标签时忽略冲突
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]int64, len(names))
+	for _, tag := range c.FindByNames(db, names) {
+		ids[tag.Name] = int64(tag.ID)
+	}
+	ret := make([]int64, 0, len(names))
+	for _, name := range names {
+		if id, ok := ids[name]; ok {
+			ret = append(ret, id)
+		}
+	}
+	return ret, nil
+}
+
+// FindByArticleId 文章的标签
+func (c *tagDao) FindByArticleId(db *gorm.DB, articleId int64) (list []model.Tag) {
+	db.Joins("JOIN article_tag ON article_tag.tag_id = tag.id").
+		Where("article_tag.article_id = ? AND article_tag.status = ?", articleId, constants.StatusOk).
+		Find(&list)
+	return
+}
+
+// FindNamesByArticleIds 文章的标签名称，key为文章id
+func (c *tagDao) FindNamesByArticleIds(db *gorm.DB, articleIds []int64) map[int64][]string {
+	ret := make(map[int64][]string, len(articleIds))
+	if len(articleIds) == 0 {
+		return ret
+	}
+	var rows []struct {
+		ArticleId int64
+		Name      string
+	}
+	db.Model(&model.Tag{}).Select("article_tag.article_id, tag.name").
+		Joins("JOIN article_tag ON article_tag.tag_id = tag.id").
+		Where("article_tag.article_id IN ? AND article_tag.status = ?", articleIds, constants.StatusOk).
+		Scan(&rows)
+	for _, row := range rows {
+		ret[row.ArticleId] = append(ret[row.ArticleId], row.Name)
+	}
+	return ret
+}
+
+func uniqueNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	ret := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		ret = append(ret, name)
+	}
+	return ret
+}
@@ -0,0 +1,39 @@
+package dao
+
+import (
+	"go-skeleton/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var TenantDao = newTenantDao()
+
+func newTenantDao() *tenantDao {
+	return &tenantDao{}
+}
+
+type tenantDao struct {
+}
+
+// GetByCode 按code查询，不存在时返回nil
+func (c *tenantDao) GetByCode(db *gorm.DB, code string) *model.Tenant {
+	ret := &model.Tenant{}
+	if err := db.Take(ret, "code = ?", code).Error; err != nil {
+		return nil
+	}
+	return ret
+}
+
+// IsMember 用户是否属于租户
+func (c *tenantDao) IsMember(db *gorm.DB, tenantId, userId int64) bool {
+	var count int64
+	db.Model(&model.TenantUser{}).Where("tenant_id = ? AND user_id = ?", tenantId, userId).Count(&count)
+	return count > 0
+}
+
+// AddMember 将用户加入租户，已加入时不处理
+func (c *tenantDao) AddMember(db *gorm.DB, tenantId, userId int64) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.TenantUser{TenantId: tenantId, UserId: userId}).Error
+}
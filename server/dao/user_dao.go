@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"context"
 	"go-skeleton/model"
 	"go-skeleton/pkg/simpleDb"
 
@@ -25,6 +26,11 @@ func (c *userDao) Get(db *gorm.DB, id int64) *model.User {
 	return code
 }
 
+// GetCtx 同Get，ctx取消或超时后查询中止
+func (c *userDao) GetCtx(ctx context.Context, db *gorm.DB, id int64) *model.User {
+	return c.Get(db.WithContext(ctx), id)
+}
+
 func (c *userDao) Take(db *gorm.DB, where ...interface{}) *model.User {
 	ret := &model.User{}
 	if err := db.Take(ret, where...).Error; err != nil {
@@ -66,8 +72,32 @@ func (c *userDao) UpdateColumn(db *gorm.DB, id int64, name string, value interfa
 	return
 }
 
-func (c *userDao) Delete(db *gorm.DB, id int64) {
-	db.Delete(&model.User{}, "id = ?", id)
+// Delete 软删除，设置deleted_at
+func (c *userDao) Delete(db *gorm.DB, id int64) (err error) {
+	err = db.Delete(&model.User{}, "id = ?", id).Error
+	return
+}
+
+// UpdatesByCnd 按条件批量更新，返回影响的行数，条件为空时返回simpleDb.ErrEmptyCondition
+func (c *userDao) UpdatesByCnd(db *gorm.DB, cnd *simpleDb.SqlCnd, columns map[string]interface{}) (int64, error) {
+	return cnd.Updates(db, &model.User{}, columns)
+}
+
+// DeleteByCnd 按条件批量软删除，返回影响的行数，条件为空时返回simpleDb.ErrEmptyCondition
+func (c *userDao) DeleteByCnd(db *gorm.DB, cnd *simpleDb.SqlCnd) (int64, error) {
+	return cnd.Delete(db, &model.User{})
+}
+
+// Restore 恢复软删除的数据
+func (c *userDao) Restore(db *gorm.DB, id int64) (err error) {
+	err = db.Unscoped().Model(&model.User{}).Where("id = ?", id).Update("deleted_at", nil).Error
+	return
+}
+
+// ForceDelete 物理删除
+func (c *userDao) ForceDelete(db *gorm.DB, id int64) (err error) {
+	err = db.Unscoped().Delete(&model.User{}, "id = ?", id).Error
+	return
 }
 
 // BatchSave 批量插入数据
@@ -91,6 +121,26 @@ func (c *userDao) FindPageByCnd(db *gorm.DB, cnd *simpleDb.SqlCnd) (list []model
 	return
 }
 
+// FindPageByCndCtx 同FindPageByCnd，ctx取消或超时后查询中止
+func (c *userDao) FindPageByCndCtx(ctx context.Context, db *gorm.DB, cnd *simpleDb.SqlCnd) ([]model.User, *simpleDb.Paging) {
+	return c.FindPageByCnd(db.WithContext(ctx), cnd)
+}
+
 func (r *userDao) GetByUsername(db *gorm.DB, username string) *model.User {
 	return r.Take(db, "username = ?", username)
 }
+
+// FindCursorPage 游标分页，适用于深度翻页，paging.Cursor为下一页的游标，游标无效时返回simpleDb.ErrInvalidCursor
+func (c *userDao) FindCursorPage(db *gorm.DB, cnd *simpleDb.SqlCnd) (list []model.User, paging *simpleDb.Paging, err error) {
+	cursor, hasMore, err := cnd.FindCursor(db, &list)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paging = &simpleDb.Paging{
+		Limit:   cnd.Paging.Limit,
+		Cursor:  cursor,
+		HasMore: hasMore,
+	}
+	return
+}
@@ -0,0 +1,54 @@
+package dao
+
+import (
+	"go-skeleton/model"
+
+	"gorm.io/gorm"
+)
+
+var UserOAuthDao = newUserOAuthDao()
+
+func newUserOAuthDao() *userOAuthDao {
+	return &userOAuthDao{}
+}
+
+type userOAuthDao struct {
+}
+
+// GetByOpenId 根据第三方平台的openid查找绑定
+func (c *userOAuthDao) GetByOpenId(db *gorm.DB, provider, openId string) *model.UserOAuth {
+	ret := &model.UserOAuth{}
+	if err := db.Take(ret, "provider = ? AND open_id = ?", provider, openId).Error; err != nil {
+		return nil
+	}
+	return ret
+}
+
+// GetByUserId 用户在第三方平台的绑定
+func (c *userOAuthDao) GetByUserId(db *gorm.DB, userId int64, provider string) *model.UserOAuth {
+	ret := &model.UserOAuth{}
+	if err := db.Take(ret, "user_id = ? AND provider = ?", userId, provider).Error; err != nil {
+		return nil
+	}
+	return ret
+}
+
+// FindByUserId 用户绑定的所有第三方账号
+func (c *userOAuthDao) FindByUserId(db *gorm.DB, userId int64) (list []model.UserOAuth) {
+	db.Where("user_id = ?", userId).Order("id asc").Find(&list)
+	return
+}
+
+func (c *userOAuthDao) Create(db *gorm.DB, t *model.UserOAuth) error {
+	return db.Create(t).Error
+}
+
+// Updates 更新第三方平台的昵称、头像等信息
+func (c *userOAuthDao) Updates(db *gorm.DB, id int64, columns map[string]interface{}) error {
+	return db.Model(&model.UserOAuth{}).Where("id = ?", id).Updates(columns).Error
+}
+
+// Delete 解绑，物理删除以便重新绑定
+func (c *userOAuthDao) Delete(db *gorm.DB, userId int64, provider string) error {
+	return db.Unscoped().Where("user_id = ? AND provider = ?", userId, provider).Delete(&model.UserOAuth{}).Error
+}
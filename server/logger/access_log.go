@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"bytes"
+	"go-skeleton/pkg/config"
+	"go-skeleton/utils"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 脱敏后的值
+const maskedValue = "***"
+
+// bodyMasker 对json和表单格式的请求体及url参数中的敏感字段脱敏，请求体被截断时也能处理。
+// json的值为字符串、数字、布尔、null及不嵌套的对象、数组时整体替换
+type bodyMasker struct {
+	jsonRegexp *regexp.Regexp
+	formRegexp *regexp.Regexp
+}
+
+func newBodyMasker(fields []string) *bodyMasker {
+	if len(fields) == 0 {
+		return &bodyMasker{}
+	}
+	quoted := make([]string, 0, len(fields))
+	for _, f := range fields {
+		quoted = append(quoted, regexp.QuoteMeta(f))
+	}
+	names := strings.Join(quoted, "|")
+	return &bodyMasker{
+		jsonRegexp: regexp.MustCompile(`(?i)("(?:` + names + `)"\s*:\s*)(?:"(?:[^"\\]|\\.)*"?|\{[^{}]*\}?|\[[^\[\]]*\]?|[^\s,}\]]+)`),
+		formRegexp: regexp.MustCompile(`(?i)((?:^|&)(?:` + names + `)=)[^&]*`),
+	}
+}
+
+// maskQuery url参数中的敏感字段脱敏，eg: token=xxx&page=1 => token=***&page=1
+func (m *bodyMasker) maskQuery(query string) string {
+	if m.formRegexp == nil || query == "" {
+		return query
+	}
+	return m.formRegexp.ReplaceAllString(query, "${1}"+maskedValue)
+}
+
+func (m *bodyMasker) mask(body []byte) string {
+	if m.jsonRegexp == nil {
+		return string(body)
+	}
+	body = m.jsonRegexp.ReplaceAll(body, []byte(`${1}"`+maskedValue+`"`))
+	body = m.formRegexp.ReplaceAll(body, []byte("${1}"+maskedValue))
+	return string(body)
+}
+
+// readBody 读取最多max字节的请求体用于记录，并还原请求体供后续处理，BodyLimit已缓存时直接使用缓存
+func readBody(c *gin.Context, max int) []byte {
+	if max <= 0 || c.Request.Body == nil {
+		return nil
+	}
+	ct := c.ContentType()
+	if ct != gin.MIMEJSON && ct != gin.MIMEPOSTForm {
+		return nil
+	}
+	if body, ok := utils.CachedBody(c); ok {
+		if len(body) > max {
+			body = body[:max]
+		}
+		return body
+	}
+	buf, _ := ioutil.ReadAll(io.LimitReader(c.Request.Body, int64(max)))
+	c.Request.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(buf), c.Request.Body))
+	return buf
+}
+
+// newAccessLogger 按配置创建访问日志使用的logger，开启采样时返回采样的logger
+func newAccessLogger(cfg config.AccessLogConfig) *zap.Logger {
+	if cfg.SampleInitial <= 0 {
+		return lg
+	}
+	return lg.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewSamplerWithOptions(core, time.Second, cfg.SampleInitial, cfg.SampleThereafter)
+	}))
+}
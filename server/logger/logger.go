@@ -3,6 +3,9 @@ package logger
 import (
 	"fmt"
 	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/jsonresult"
+	"go-skeleton/utils"
 	"net"
 	"net/http"
 	"net/http/httputil"
@@ -19,21 +22,32 @@ import (
 
 var lg *zap.Logger
 
+// 日志级别，修改配置文件中的log.Level后无需重启即可生效
+var level = zap.NewAtomicLevel()
+
 // InitLogger 初始化Logger
 func InitLogger() (err error) {
+	config.OnChange("log.Level", func(old, new interface{}) {
+		if err := level.UnmarshalText([]byte(fmt.Sprint(new))); err != nil {
+			zap.L().Error("修改日志级别失败", zap.Any("level", new), zap.Error(err))
+		}
+	})
+
 	if config.Conf.AppMode == "debug" {
-		lg, _ = zap.NewDevelopment()
+		level.SetLevel(zapcore.DebugLevel)
+		cfg := zap.NewDevelopmentConfig()
+		cfg.Level = level
+		lg, _ = cfg.Build()
 		zap.ReplaceGlobals(lg) // 替换zap包中全局的logger实例，后续在其他包中只需使用zap.L()调用即可
 		return
 	}
-	writeSyncer := getLogWriter(config.Conf.Filename, config.Conf.MaxSize, config.Conf.MaxBackups, config.Conf.MaxAge)
+	writeSyncer := getLogWriter(config.Conf.Filename, config.Conf.LogConfig.MaxSize, config.Conf.MaxBackups, config.Conf.MaxAge)
 	encoder := getEncoder()
-	var l = new(zapcore.Level)
-	err = l.UnmarshalText([]byte(config.Conf.Level))
+	err = level.UnmarshalText([]byte(config.Conf.Level))
 	if err != nil {
 		return
 	}
-	core := zapcore.NewCore(encoder, writeSyncer, l)
+	core := zapcore.NewCore(encoder, writeSyncer, level)
 
 	lg = zap.New(core, zap.AddCaller())
 	zap.ReplaceGlobals(lg) // 替换zap包中全局的logger实例，后续在其他包中只需使用zap.L()调用即可
@@ -80,25 +94,51 @@ func getLogWriter(filename string, maxSize, maxBackup, maxAge int) zapcore.Write
 	return zapcore.AddSync(lumberJackLogger)
 }
 
-// GinLogger 接收gin框架默认的日志
+// GinLogger 访问日志，记录请求、响应状态、耗时、用户等信息，配置见log.Access
 func GinLogger() gin.HandlerFunc {
+	cfg := config.Conf.LogConfig.Access
+	masker := newBodyMasker(cfg.MaskFields)
+	sampled := newAccessLogger(cfg)
+	skip := make(map[string]bool, len(cfg.SkipPaths))
+	for _, path := range cfg.SkipPaths {
+		skip[path] = true
+	}
+
 	return func(c *gin.Context) {
+		if skip[c.FullPath()] {
+			c.Next()
+			return
+		}
 		start := time.Now()
 		path := c.Request.URL.Path
-		query := c.Request.URL.RawQuery
+		query := masker.maskQuery(c.Request.URL.RawQuery)
+		body := readBody(c, cfg.BodyMaxSize)
 		c.Next()
 
 		cost := time.Since(start)
-		lg.Info(path,
-			zap.Int("status", c.Writer.Status()),
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.Int("status", status),
 			zap.String("method", c.Request.Method),
 			zap.String("path", path),
 			zap.String("query", query),
 			zap.String("ip", c.ClientIP()),
 			zap.String("user-agent", c.Request.UserAgent()),
+			zap.String("request-id", utils.RequestID(c)),
+			zap.Int("uid", c.GetInt("uid")),
+			zap.Int("size", c.Writer.Size()),
 			zap.String("errors", c.Errors.ByType(gin.ErrorTypePrivate).String()),
 			zap.Duration("cost", cost),
-		)
+		}
+		if len(body) > 0 {
+			fields = append(fields, zap.String("body", masker.mask(body)))
+		}
+		// 服务端错误不采样
+		if status >= 500 {
+			lg.Error(path, fields...)
+			return
+		}
+		sampled.Info(path, fields...)
 	}
 }
 
@@ -119,6 +159,7 @@ func GinRecovery(stack bool) gin.HandlerFunc {
 				}
 
 				httpRequest, _ := httputil.DumpRequest(c.Request, false)
+				lg := lg.With(zap.String("request-id", utils.RequestID(c)))
 				if brokenPipe {
 					lg.Error(c.Request.URL.Path,
 						zap.Any("error", err),
@@ -142,7 +183,13 @@ func GinRecovery(stack bool) gin.HandlerFunc {
 						zap.String("request", string(httpRequest)),
 					)
 				}
-				c.AbortWithStatus(http.StatusInternalServerError)
+				// 开发模式返回panic信息，生产模式隐藏内部错误
+				if config.Conf.AppMode == "debug" {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, jsonresult.JsonErrorData(
+						errors.InternalError.Code, fmt.Sprint(err), strings.Split(string(debug.Stack()), "\n")))
+				} else {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, jsonresult.JsonCodeError(errors.InternalError))
+				}
 			}
 		}()
 		c.Next()
@@ -6,22 +6,34 @@ import (
 	"go-skeleton/app"
 	"go-skeleton/logger"
 	"go-skeleton/middleware"
+	_ "go-skeleton/migrations"
+	"go-skeleton/model"
+	"go-skeleton/pkg/audit"
+	"go-skeleton/pkg/broker"
+	"go-skeleton/pkg/cache"
 	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/eventbus"
+	"go-skeleton/pkg/experiment"
+	"go-skeleton/pkg/featureflag"
+	"go-skeleton/pkg/geoip"
 	"go-skeleton/pkg/gredis"
+	"go-skeleton/pkg/health"
+	"go-skeleton/pkg/i18n"
+	"go-skeleton/pkg/metrics"
+	"go-skeleton/pkg/migrate"
+	"go-skeleton/pkg/outbox"
+	"go-skeleton/pkg/queue"
+	"go-skeleton/pkg/sensitive"
 	"go-skeleton/pkg/simpleDb"
-	"go-skeleton/pkg/upload"
-	"go-skeleton/router"
+	"go-skeleton/pkg/tenant"
+	"go-skeleton/pkg/tracing"
+	"go-skeleton/pkg/ws"
+	"go-skeleton/rpc"
+	"go-skeleton/services"
 	"go-skeleton/utils"
 	"log"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
-
-	"go.uber.org/zap"
-
-	"github.com/gin-gonic/gin"
+	"strings"
 )
 
 func init() {
@@ -40,12 +52,51 @@ func init() {
 	}
 	//log结束
 
+	//密码哈希参数
+	pwd := config.Conf.UserConfig.Password
+	utils.SetPasswordOptions(utils.PasswordOptions{
+		Algorithm:     pwd.Algorithm,
+		BcryptCost:    pwd.BcryptCost,
+		Argon2Time:    pwd.Argon2Time,
+		Argon2Memory:  pwd.Argon2Memory,
+		Argon2Threads: pwd.Argon2Threads,
+	})
+
+	//字段加密密钥
+	if err := initKeyring(); err != nil {
+		fmt.Printf("init encrypt keys failed, err:%v\n", err)
+		os.Exit(0)
+		return
+	}
+
+	//跨域配置，允许全部来源时不能携带凭证
+	if err := middleware.ValidateCors(); err != nil {
+		fmt.Printf("invalid cors config, err:%v\n", err)
+		os.Exit(0)
+		return
+	}
+
+	//链路追踪
+	if err := tracing.InitTracing(); err != nil {
+		fmt.Printf("init tracing failed, err:%v\n", err)
+		return
+	}
+	app.OnShutdown("tracing", tracing.Shutdown)
+
 	//redis
 	if err := gredis.InitRedis(); err != nil {
 		fmt.Printf("init redis failed, err:%v\n", err)
 		os.Exit(0)
 		return
 	}
+	app.OnShutdown("redis", func(ctx context.Context) error {
+		gredis.CloseRedis()
+		return nil
+	})
+	metrics.RegisterRedisPool(gredis.PoolStats)
+	health.Register("redis", func(ctx context.Context) error {
+		return gredis.GetRedis().Ping(ctx).Err()
+	})
 
 	//数据库初始化
 	if err := simpleDb.InitDb(); err != nil {
@@ -53,8 +104,137 @@ func init() {
 		os.Exit(0)
 		return
 	}
+	app.OnShutdown("mysql", func(ctx context.Context) error {
+		simpleDb.CloseDB()
+		return nil
+	})
+	health.Register("mysql", func(ctx context.Context) error {
+		sqlDB, err := simpleDb.DB().DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+
+	//审计日志，记录后台对文章、用户的修改
+	//计数和版本号的变更不记录，避免每次阅读、点赞都写审计日志
+	audit.Register(&model.Article{}, "read_count", "comment_count", "like_count", "favorite_count", "version")
+	audit.Register(&model.User{}, "password", "phone", "last_login_at", "last_login_ip")
+	if err := simpleDb.DB().Use(audit.Plugin{}); err != nil {
+		fmt.Printf("init audit failed, err:%v\n", err)
+		os.Exit(0)
+		return
+	}
+	//新增、修改时填充创建人、修改人及create_time、update_time
+	if err := simpleDb.DB().Use(audit.OperatorPlugin{}); err != nil {
+		fmt.Printf("init audit operator failed, err:%v\n", err)
+		os.Exit(0)
+		return
+	}
+
+	//接口响应缓存，模型写入后删除关联表的缓存
+	if config.Conf.CacheConfig.Enable {
+		if err := simpleDb.DB().Use(cache.Plugin{}); err != nil {
+			fmt.Printf("init response cache failed, err:%v\n", err)
+			os.Exit(0)
+			return
+		}
+		cache.Init(config.Conf.CacheConfig.PurgeDelay)
+	}
+
+	//多租户，为带tenant_id列的模型加上租户条件
+	if config.Conf.TenantConfig.Enable {
+		if err := simpleDb.DB().Use(tenant.Plugin{Strict: config.Conf.TenantConfig.Strict}); err != nil {
+			fmt.Printf("init tenant failed, err:%v\n", err)
+			os.Exit(0)
+			return
+		}
+	}
+
+	//启动时执行数据库迁移，命令行执行migrate时跳过
+	if config.Conf.MigrateConfig.AutoRun && (len(os.Args) < 2 || os.Args[1] != "migrate") {
+		if err := migrate.Up(simpleDb.DB()); err != nil {
+			fmt.Printf("数据库迁移失败:%v\n", err)
+			os.Exit(0)
+			return
+		}
+	}
+
+	//redis任务队列，任务处理方法通过queue.Handle注册
+	if config.Conf.QueueConfig.Enable {
+		queue.StartWorker()
+		app.OnShutdown("jobs", queue.StopWorker)
+	}
+
+	//消息中间件，领域事件转发到kafka或rabbitmq，消费者通过broker.Subscribe注册
+	broker.Forward(eventbus.ArticlePublished{}, eventbus.UserRegistered{}, eventbus.ExperimentExposed{})
+	if err := broker.Init(); err != nil {
+		fmt.Printf("init broker failed, err:%v\n", err)
+		os.Exit(0)
+		return
+	}
+	app.OnShutdown("broker", broker.Shutdown)
+
+	//事务发件箱，轮询outbox_event表中未发送的事件并发送到消息中间件
+	if config.Conf.OutboxConfig.Enable {
+		outbox.Start()
+		app.OnShutdown("outbox", outbox.Stop)
+	}
+
+	//敏感词过滤，评论通过CommentService的过滤器检查，文章在保存前检查
+	if config.Conf.SensitiveConfig.Enable {
+		if err := sensitive.Init(); err != nil {
+			fmt.Printf("init sensitive words failed, err:%v\n", err)
+			os.Exit(0)
+			return
+		}
+		services.CommentService.AddFilter(services.SensitiveCommentFilter)
+		app.OnShutdown("sensitive", sensitive.Stop)
+	}
+
+	//功能开关，后台修改后通过redis发布订阅通知所有实例重新加载
+	if config.Conf.FeatureConfig.Enable {
+		if err := featureflag.Init(gredis.GetRedis()); err != nil {
+			fmt.Printf("init feature flags failed, err:%v\n", err)
+			os.Exit(0)
+			return
+		}
+		app.OnShutdown("featureflag", featureflag.Stop)
+	}
+
+	//A/B实验，分组的曝光事件可以通过broker转发到消息中间件统计
+	if config.Conf.ExperimentConfig.Enable {
+		if err := experiment.Init(); err != nil {
+			fmt.Printf("init experiments failed, err:%v\n", err)
+			os.Exit(0)
+			return
+		}
+	}
+
+	//ip地理位置，数据库文件更新后自动重新加载
+	if config.Conf.GeoIPConfig.Enable {
+		if err := geoip.Init(); err != nil {
+			fmt.Printf("init geoip failed, err:%v\n", err)
+			os.Exit(0)
+			return
+		}
+		app.OnShutdown("geoip", geoip.Close)
+	}
+
+	//websocket，多实例部署时通过redis发布订阅转发消息
+	if err := ws.Init(gredis.GetRedis(), middleware.CheckOrigin); err != nil {
+		fmt.Printf("init websocket failed, err:%v\n", err)
+		os.Exit(0)
+		return
+	}
+	app.OnShutdown("websocket", ws.Default().Close)
 
-	//翻译
+	//翻译，错误提示和校验信息按请求的语言输出
+	if err := i18n.Init(config.Conf.I18nConfig.Dir, config.Conf.I18nConfig.DefaultLocale); err != nil {
+		fmt.Printf("init i18n failed, err:%v\n", err)
+		os.Exit(0)
+		return
+	}
 	_ = utils.InitTrans("zh")
 
 	//队列任务初始化，如果要使用队列打开注释即可，task目录为处理方法，在InitQueue中注册即可
@@ -65,21 +245,6 @@ func init() {
 	//}
 }
 
-func initGin() *gin.Engine {
-	gin.SetMode(config.Conf.ServerConfig.AppMode)
-	r := gin.New()
-	r.StaticFS("/upload/images", http.Dir(upload.GetImageFullPath()))
-
-	r.Use(logger.GinLogger(), logger.GinRecovery(true))
-	r.Use(middleware.Cors())
-
-	//加载路由
-	router.LoadDefault(r)
-	router.LoadAdminRouter(r)
-	router.LoadApiRouter(r)
-	return r
-}
-
 // @title go-skeleton
 // @version 1.0
 // @description go-skeleton
@@ -94,47 +259,36 @@ func initGin() *gin.Engine {
 
 // @host localhost:8000
 // @BasePath /
-func main() {
-	app.StartOn()
 
-	handler := initGin()
-	address := fmt.Sprintf(":%s", config.Conf.ServerConfig.HttpPort)
-	readTimeout := config.Conf.ServerConfig.ReadTimeout * time.Second
-	writeTimeout := config.Conf.ServerConfig.WriteTimeout * time.Second
-	maxHeaderBytes := 1 << 20 //1048576 = 1mb
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
 
-	server := &http.Server{
-		Addr:           address,
-		Handler:        handler,
-		ReadTimeout:    readTimeout,
-		WriteTimeout:   writeTimeout,
-		MaxHeaderBytes: maxHeaderBytes,
+// 生成接口文档到docs目录，修改注释后重新执行 go generate
+//
+//go:generate swag init
+func main() {
+	// 带参数时执行命令行任务，eg: ./go-skeleton search:reindex
+	if len(os.Args) > 1 {
+		if err := app.RunCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("%s: %v\n", os.Args[1], err)
+		}
+		return
 	}
-	zap.L().Info(fmt.Sprintf("Listening and serving HTTP on %s\n", address))
 
-	go func() {
-		if err := server.ListenAndServe(); err != nil {
-			log.Fatalf("listen: %s\n", err)
+	app.StartOn()
+
+	//gRPC服务，与http服务共用services
+	if config.Conf.GrpcConfig.Enable {
+		if err := rpc.Start(); err != nil {
+			log.Fatalf("grpc listen: %s\n", err)
 		}
-	}()
-
-	// 等待中断信号来优雅地关闭服务器，为关闭服务器操作设置一个5秒的超时
-	quit := make(chan os.Signal, 1) // 创建一个接收信号的通道
-	// kill 默认会发送 syscall.SIGTERM 信号
-	// kill -2 发送 syscall.SIGINT 信号，我们常用的Ctrl+C就是触发系统SIGINT信号
-	// kill -9 发送 syscall.SIGKILL 信号，但是不能被捕获，所以不需要添加它
-	// signal.Notify把收到的 syscall.SIGINT或syscall.SIGTERM 信号转发给quit
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM) // 此处不会阻塞
-	<-quit                                               // 阻塞在此，当接收到上述两种信号时才会往下执行
-	log.Println("Shutdown Server ...")
-	// 创建一个5秒超时的context
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	// 5秒内优雅关闭服务（将未处理完的请求处理完再关闭服务），超过5秒就超时退出
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatal("Server Shutdown: ", err)
-	}
-	log.Println("Server exiting")
+		app.OnShutdown("grpc", rpc.Stop)
+	}
+
+	if err := app.Run(app.NewHandler()); err != nil {
+		log.Fatalf("listen: %s\n", err)
+	}
 
 	// 默认endless服务器会监听下列信号：
 	//	"github.com/fvbock/endless"
@@ -150,3 +304,25 @@ func main() {
 	//	log.Fatalf("listen: %s\n", err)
 	//}
 }
+
+// initKeyring 按配置encrypt设置model.EncryptedString的密钥，未配置时不能保存加密字段
+func initKeyring() error {
+	cfg := config.Conf.EncryptConfig
+	if len(cfg.Keys) == 0 {
+		return nil
+	}
+	keys := make(map[string][]byte, len(cfg.Keys))
+	for id, key := range cfg.Keys {
+		b, err := utils.Base64Decode(key)
+		if err != nil {
+			return fmt.Errorf("key %s: %w", id, err)
+		}
+		keys[strings.ToLower(id)] = b
+	}
+	k, err := utils.NewKeyring(strings.ToLower(cfg.KeyId), keys)
+	if err != nil {
+		return err
+	}
+	model.SetKeyring(k)
+	return nil
+}
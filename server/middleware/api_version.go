@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/apiversion"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/metrics"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// ApiVersionKey gin.Context中保存请求版本的key，eg: v1
+	ApiVersionKey = "apiVersion"
+	// DeprecatedKey 请求的接口已弃用
+	DeprecatedKey = "deprecated"
+)
+
+// matchPaths 按路径配置的规则匹配的路径：请求的路径及去掉版本后的路径，
+// 未带版本的请求已被apiversion.Negotiate改写，规则按/api/article/list配置时也能匹配
+func matchPaths(path string) []string {
+	if unversioned := apiversion.Unversioned(path); unversioned != path {
+		return []string{path, unversioned}
+	}
+	return []string{path}
+}
+
+// 弃用配置的日期格式
+const deprecationDateLayout = "2006-01-02"
+
+// ApiVersion 标记路由组的版本，响应头返回版本，并按版本统计请求数，用于确认旧版本是否还有调用
+func ApiVersion(version string) gin.HandlerFunc {
+	version = apiversion.Normalize(version)
+	return func(c *gin.Context) {
+		c.Set(ApiVersionKey, version)
+		c.Header(apiversion.Header, version)
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ApiVersionRequests.WithLabelValues(version, route, strconv.FormatBool(c.GetBool(DeprecatedKey))).Inc()
+	}
+}
+
+// Deprecated 标记接口已弃用，响应头带上Deprecation、Sunset(计划下线的时间)及迁移说明的链接，
+// since、sunset为零值时不输出对应的日期，eg:
+//
+//	apiRouter.GET("/article/myHttp", middleware.Deprecated(time.Time{}, sunset, ""), art.MyHttp)
+func Deprecated(since, sunset time.Time, link string) gin.HandlerFunc {
+	deprecation := "true"
+	if !since.IsZero() {
+		deprecation = since.UTC().Format(http.TimeFormat)
+	}
+	return func(c *gin.Context) {
+		c.Set(DeprecatedKey, true)
+		c.Header("Deprecation", deprecation)
+		if !sunset.IsZero() {
+			c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		if link != "" {
+			c.Header("Link", "<"+link+`>; rel="deprecation"`)
+		}
+		c.Next()
+	}
+}
+
+// DeprecatedVersion 按配置apiversion.Deprecated标记已弃用的版本，未配置时不处理
+func DeprecatedVersion(version string) gin.HandlerFunc {
+	d, ok := config.Conf.ApiVersionConfig.Deprecated[apiversion.Normalize(version)]
+	if !ok {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	return Deprecated(parseDeprecationDate(version, d.Since), parseDeprecationDate(version, d.Sunset), d.Link)
+}
+
+func parseDeprecationDate(version, s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.ParseInLocation(deprecationDateLayout, s, time.UTC)
+	if err != nil {
+		zap.L().Warn("invalid api version deprecation date", zap.String("version", version), zap.String("date", s), zap.Error(err))
+	}
+	return t
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditActor 将当前登录用户写入请求的context，放在JwtToken之后，
+// 通过db.WithContext(c.Request.Context())执行的修改会记录操作人
+func AuditActor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := audit.Actor{Ip: c.ClientIP()}
+		if claims := GetClaims(c); claims != nil {
+			actor.Id = int64(claims.Id)
+		}
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), actor))
+		c.Next()
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// http.MaxBytesReader超出限制时的错误
+const bodyTooLarge = "http: request body too large"
+
+// BodyLimit 限制请求体大小，超出时返回413。json、表单等请求体一次读入并缓存，之后绑定、验签、幂等、日志
+// 通过utils.ReadBody各自读取；multipart上传不缓存，按maxUpload限制，读取超出时返回错误。max、maxUpload为0时不限制
+func BodyLimit(max, maxUpload int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		multipart := strings.HasPrefix(c.ContentType(), gin.MIMEMultipartPOSTForm)
+		limit := max
+		if multipart {
+			limit = maxUpload
+		}
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > limit {
+			abortTooLarge(c, limit)
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		if !multipart {
+			if _, err := utils.ReadBody(c); err != nil {
+				if err.Error() == bodyTooLarge {
+					abortTooLarge(c, limit)
+				} else {
+					resp.Abort(c, http.StatusBadRequest, errors.InvalidParamsError)
+				}
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// BodyLimitFromConfig 按server.MaxBodySize、server.MaxUploadSize限制请求体大小
+func BodyLimitFromConfig() gin.HandlerFunc {
+	cfg := config.Conf.ServerConfig
+	return BodyLimit(cfg.MaxBodySize, cfg.MaxUploadSize)
+}
+
+// abortTooLarge 在访问日志之前中止，单独记录被拒绝的请求
+func abortTooLarge(c *gin.Context, limit int64) {
+	utils.Logger(c).Warn("request body too large", zap.String("path", c.Request.URL.Path),
+		zap.Int64("contentLength", c.Request.ContentLength), zap.Int64("limit", limit))
+	c.Header("Connection", "close")
+	resp.Abort(c, http.StatusRequestEntityTooLarge, errors.RequestTooLargeError)
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-skeleton/pkg/cache"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/pkg/i18n"
+	"go-skeleton/pkg/tenant"
+	"go-skeleton/services"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// 未登录用户的角色
+const guestRole = "guest"
+
+// Cache 缓存GET请求成功的响应ttl时间，key按路径、查询参数、用户角色、租户和语言区分，
+// tables中的表有写入时自动删除，见cache.Plugin。需要登录的接口放在JwtToken之后，eg:
+//
+//	apiRouter.GET("/tag/hot", middleware.Cache(time.Minute, "tag", "article_tag", "article"), tag.Hot)
+func Cache(ttl time.Duration, tables ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.Conf.CacheConfig
+		if !cfg.Enable || c.Request.Method != http.MethodGet || gredis.GetRedis() == nil {
+			c.Next()
+			return
+		}
+		ctx := c.Request.Context()
+		key := cache.Key(c.Request.URL.Path, c.Request.URL.Query(), cacheVary(c))
+		e, err := cache.Get(ctx, key)
+		if err == nil {
+			c.Header("X-Cache", "HIT")
+			c.Data(e.Status, e.ContentType, e.Body)
+			c.Abort()
+			return
+		}
+		if err != redis.Nil {
+			zap.L().Warn("response cache get failed", zap.String("key", key), zap.Error(err))
+		}
+
+		w := &cacheWriter{ResponseWriter: c.Writer, max: cfg.MaxBody}
+		c.Writer = w
+		c.Header("X-Cache", "MISS")
+		c.Next()
+		c.Writer = w.ResponseWriter
+		if w.overflow || w.Status() != http.StatusOK || c.IsAborted() || len(c.Errors) > 0 || !succeeded(w.Header(), w.buf.Bytes()) {
+			return
+		}
+		e = &cache.Entry{Status: w.Status(), ContentType: w.Header().Get("Content-Type"), Body: w.buf.Bytes()}
+		if err := cache.Set(ctx, key, e, ttl, tables...); err != nil {
+			zap.L().Warn("response cache set failed", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+// cacheVary 区分用户的部分，eg: admin,editor|t1|zh
+func cacheVary(c *gin.Context) string {
+	roles := []string{guestRole}
+	if uid := c.GetInt(UidKey); uid > 0 {
+		roles = append([]string(nil), services.PermissionService.GetUserRoles(c.Request.Context(), int64(uid))...)
+		sort.Strings(roles)
+	}
+	vary := strings.Join(roles, ",")
+	if tid, ok := tenant.FromContext(c.Request.Context()); ok {
+		vary += fmt.Sprintf("|t%d", tid)
+	}
+	return vary + "|" + i18n.LocaleFrom(c)
+}
+
+// succeeded json响应中success为true时才缓存，失败的结果(eg: 数据库错误)不缓存
+func succeeded(h http.Header, body []byte) bool {
+	if !strings.HasPrefix(h.Get("Content-Type"), "application/json") {
+		return true
+	}
+	var result struct {
+		Success bool `json:"success"`
+	}
+	return json.Unmarshal(body, &result) == nil && result.Success
+}
+
+// cacheWriter 输出响应的同时保存一份，超过max字节时不再保存
+type cacheWriter struct {
+	gin.ResponseWriter
+	max      int
+	buf      bytes.Buffer
+	overflow bool
+}
+
+func (w *cacheWriter) Write(b []byte) (int, error) {
+	if !w.overflow {
+		if w.max > 0 && w.buf.Len()+len(b) > w.max {
+			w.overflow = true
+			w.buf.Reset()
+		} else {
+			w.buf.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
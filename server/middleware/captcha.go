@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/gcaptcha"
+	"go-skeleton/pkg/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireCaptcha 校验图形验证码，验证码id和答案从请求头X-Captcha-Id、X-Captcha或参数captchaId、captcha中获取，
+// json请求需要使用请求头，eg: e.POST("/site/login", middleware.RequireCaptcha(), site.Login)
+func RequireCaptcha() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := captchaParam(c, "X-Captcha-Id", "captchaId")
+		answer := captchaParam(c, "X-Captcha", "captcha")
+		if !gcaptcha.Verify(c, id, answer) {
+			resp.Error(c, errors.CaptchaError)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func captchaParam(c *gin.Context, header, name string) string {
+	if v := c.GetHeader(header); v != "" {
+		return v
+	}
+	if v, ok := c.GetPostForm(name); ok {
+		return v
+	}
+	return c.Query(name)
+}
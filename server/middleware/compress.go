@@ -0,0 +1,389 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"go-skeleton/pkg/config"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// 支持的压缩方式，按优先级排列
+const (
+	encodingBrotli  = "br"
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+var compressEncodings = []string{encodingBrotli, encodingGzip, encodingDeflate}
+
+// 默认压缩的Content-Type前缀
+var defaultCompressTypes = []string{
+	"text/", "application/json", "application/javascript", "application/xml", "image/svg+xml",
+}
+
+// 默认的最小压缩字节数，更小的响应压缩后收益不大
+const defaultCompressMinSize = 1024
+
+// CompressOptions 响应压缩的选项
+type CompressOptions struct {
+	// 压缩级别，0为各算法的默认级别
+	Level int
+	// 小于该字节数的响应不压缩
+	MinSize int
+	// 压缩的Content-Type前缀
+	Types []string
+	// 不压缩的路径前缀
+	ExcludePaths []string
+}
+
+// Compress 按Accept-Encoding使用br、gzip或deflate压缩响应。响应写入超过MinSize后才决定是否压缩，
+// 已设置Content-Encoding、Content-Type不在Types中的响应不压缩；websocket、SSE及ExcludePaths中的路径跳过
+func Compress(opts CompressOptions) gin.HandlerFunc {
+	if opts.MinSize <= 0 {
+		opts.MinSize = defaultCompressMinSize
+	}
+	if len(opts.Types) == 0 {
+		opts.Types = defaultCompressTypes
+	}
+	pools := newCompressorPools(opts.Level)
+	return func(c *gin.Context) {
+		if skipCompress(c, opts.ExcludePaths) {
+			c.Next()
+			return
+		}
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+		w := &compressWriter{ResponseWriter: c.Writer, opts: &opts, pools: pools, encoding: encoding, status: http.StatusOK}
+		c.Writer = w
+		defer func() {
+			w.close()
+			c.Writer = w.ResponseWriter
+		}()
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		c.Next()
+	}
+}
+
+// CompressFromConfig 按配置compress压缩响应，未开启时不处理
+func CompressFromConfig() gin.HandlerFunc {
+	cfg := config.Conf.CompressConfig
+	if !cfg.Enable {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	return Compress(CompressOptions{
+		Level:        cfg.CompressLevel,
+		MinSize:      cfg.MinSize,
+		Types:        cfg.Types,
+		ExcludePaths: cfg.ExcludePaths,
+	})
+}
+
+func skipCompress(c *gin.Context, excludes []string) bool {
+	if c.Request.Method == http.MethodHead || c.GetHeader("Range") != "" {
+		return true
+	}
+	if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") ||
+		strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+		return true
+	}
+	for _, prefix := range excludes {
+		if strings.HasPrefix(c.Request.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding 按Accept-Encoding选择压缩方式，不支持压缩时返回空字符串
+func negotiateEncoding(header string) string {
+	if encodings := acceptedEncodings(header); len(encodings) > 0 {
+		return encodings[0]
+	}
+	return ""
+}
+
+// acceptedEncodings 客户端支持的压缩方式，按q值从高到低排列，q值相同时按br、gzip、deflate的顺序
+func acceptedEncodings(header string) []string {
+	if header == "" {
+		return nil
+	}
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, q := part, 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			name = part[:i]
+			param := strings.TrimSpace(part[i+1:])
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+	encodings := make([]string, 0, len(compressEncodings))
+	weights := make(map[string]float64, len(compressEncodings))
+	for _, encoding := range compressEncodings {
+		q, ok := accepted[encoding]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > 0 {
+			encodings = append(encodings, encoding)
+			weights[encoding] = q
+		}
+	}
+	sort.SliceStable(encodings, func(i, j int) bool {
+		return weights[encodings[i]] > weights[encodings[j]]
+	})
+	return encodings
+}
+
+// compressor 可复用的压缩器
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+type compressorPools map[string]*sync.Pool
+
+func newCompressorPools(level int) compressorPools {
+	return compressorPools{
+		encodingBrotli: {New: func() interface{} {
+			l := brotli.DefaultCompression
+			if level > 0 {
+				l = level
+			}
+			return brotli.NewWriterLevel(nil, l)
+		}},
+		encodingGzip: {New: func() interface{} {
+			w, err := gzip.NewWriterLevel(nil, level)
+			if err != nil || level == 0 {
+				w = gzip.NewWriter(nil)
+			}
+			return w
+		}},
+		encodingDeflate: {New: func() interface{} {
+			w, err := flate.NewWriter(nil, level)
+			if err != nil || level == 0 {
+				w, _ = flate.NewWriter(nil, flate.DefaultCompression)
+			}
+			return w
+		}},
+	}
+}
+
+// compressWriter 先缓存MinSize字节的响应，足够大时开始压缩，请求结束时仍不足的按原样输出
+type compressWriter struct {
+	gin.ResponseWriter
+	opts     *CompressOptions
+	pools    compressorPools
+	encoding string
+
+	status  int
+	buf     bytes.Buffer
+	decided bool
+	cw      compressor
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if !w.decided {
+		w.status = code
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	w.decide(true)
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *compressWriter) Status() int {
+	if !w.decided {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *compressWriter) Written() bool {
+	return w.decided && w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		// 已知不压缩的类型直接输出，eg: 图片、SSE
+		if ct := w.Header().Get("Content-Type"); ct != "" && !w.compressible(ct) {
+			w.decide(false)
+		} else {
+			w.buf.Write(b)
+			if w.buf.Len() < w.opts.MinSize {
+				return len(b), nil
+			}
+			w.decide(true)
+			return len(b), w.flushBuffer()
+		}
+	}
+	if w.cw != nil {
+		return w.cw.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush 流式输出时立即决定是否压缩，已压缩的数据同时刷新
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(w.buf.Len() > 0)
+		_ = w.flushBuffer()
+	}
+	if w.cw != nil {
+		_ = w.cw.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	return w.ResponseWriter.Hijack()
+}
+
+// decide 决定是否压缩并输出响应头，want为false或响应不适合压缩时按原样输出
+func (w *compressWriter) decide(want bool) {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	h := w.Header()
+	if h.Get("Content-Type") == "" && w.buf.Len() > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf.Bytes()))
+	}
+	if want && h.Get("Content-Encoding") == "" && w.compressible(h.Get("Content-Type")) &&
+		w.status != http.StatusNoContent && w.status != http.StatusNotModified {
+		w.cw = w.pools[w.encoding].Get().(compressor)
+		w.cw.Reset(w.ResponseWriter)
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		h.Del("Accept-Ranges")
+		weakenETag(h)
+	} else if w.status == http.StatusNotModified {
+		// 与压缩后的200响应保持一致
+		weakenETag(h)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// weakenETag 压缩后的内容与原内容不同，强ETag改为弱ETag
+func weakenETag(h http.Header) {
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+}
+
+func (w *compressWriter) flushBuffer() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.cw != nil {
+		_, err = w.cw.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+// close 请求结束时输出缓存的响应并关闭压缩器
+func (w *compressWriter) close() {
+	if !w.decided {
+		w.decide(false)
+		if w.buf.Len() > 0 {
+			w.Header().Set("Content-Length", strconv.Itoa(w.buf.Len()))
+		}
+		_ = w.flushBuffer()
+		return
+	}
+	if w.cw != nil {
+		_ = w.cw.Close()
+		w.cw.Reset(nil)
+		w.pools[w.encoding].Put(w.cw)
+		w.cw = nil
+	}
+}
+
+func (w *compressWriter) compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	// SSE需要逐条推送，压缩会缓存消息
+	if strings.HasPrefix(contentType, "text/event-stream") {
+		return false
+	}
+	for _, prefix := range w.opts.Types {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// 预压缩文件的扩展名
+var precompressedExt = map[string]string{encodingBrotli: ".br", encodingGzip: ".gz"}
+
+// PrecompressedStatic 静态文件，目录中存在构建时生成的x.br、x.gz时按Accept-Encoding优先返回，eg:
+//
+//	r.GET("/static/*filepath", middleware.PrecompressedStatic("./public"))
+func PrecompressedStatic(root string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := filepath.Join(root, filepath.FromSlash(path.Clean("/"+c.Param("filepath"))))
+		info, err := os.Stat(name)
+		if err != nil || info.IsDir() {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		if c.GetHeader("Range") == "" {
+			for _, encoding := range acceptedEncodings(c.GetHeader("Accept-Encoding")) {
+				ext, ok := precompressedExt[encoding]
+				if !ok {
+					continue
+				}
+				f, err := os.Open(name + ext)
+				if err != nil {
+					continue
+				}
+				defer f.Close()
+				if ct := mime.TypeByExtension(filepath.Ext(name)); ct != "" {
+					c.Header("Content-Type", ct)
+				}
+				c.Header("Content-Encoding", encoding)
+				http.ServeContent(c.Writer, c.Request, name, info.ModTime(), f)
+				return
+			}
+		}
+		c.File(name)
+	}
+}
@@ -1,21 +1,162 @@
 package middleware
 
 import (
+	"fmt"
+	"go-skeleton/pkg/config"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
+// 未配置时的默认跨域策略
+var defaultCorsPolicy = config.CorsPolicy{
+	AllowOrigins:  []string{"*"},
+	AllowMethods:  []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+	AllowHeaders:  []string{"*"},
+	ExposeHeaders: []string{"Content-Length", "Authorization", "Content-Type"},
+	MaxAge:        12 * time.Hour,
+}
+
+type corsRoute struct {
+	prefix  string
+	handler gin.HandlerFunc
+}
+
+// Cors 跨域中间件，策略见配置文件cors，按路由前缀匹配Routes中的策略，最长前缀优先，配置需先通过ValidateCors检查
 func Cors() gin.HandlerFunc {
-	return cors.New(
-		cors.Config{
-			AllowAllOrigins:  true,
-			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			AllowHeaders:     []string{"*"},
-			ExposeHeaders:    []string{"Content-Length", "text/plain", "Authorization", "Content-Type"},
-			AllowCredentials: true,
-			MaxAge:           12 * time.Hour,
+	cfg := config.Conf.CorsConfig
+	base := mergeCorsPolicy(defaultCorsPolicy, cfg.CorsPolicy)
+	handler := newCors(base)
+
+	routes := make([]corsRoute, 0, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		routes = append(routes, corsRoute{
+			prefix:  route.Path,
+			handler: newCors(mergeCorsPolicy(base, route.CorsPolicy)),
+		})
+	}
+
+	return func(c *gin.Context) {
+		h, matched := handler, ""
+		for _, path := range matchPaths(c.Request.URL.Path) {
+			for _, route := range routes {
+				if strings.HasPrefix(path, route.prefix) && len(route.prefix) > len(matched) {
+					h, matched = route.handler, route.prefix
+				}
+			}
+		}
+		h(c)
+	}
+}
+
+// ValidateCors 检查跨域配置，允许全部来源(*)的策略不能同时允许携带凭证，否则任意网站都可以带着用户的cookie调用接口
+func ValidateCors() error {
+	cfg := config.Conf.CorsConfig
+	base := mergeCorsPolicy(defaultCorsPolicy, cfg.CorsPolicy)
+	if err := validateCorsPolicy("cors", base); err != nil {
+		return err
+	}
+	for _, route := range cfg.Routes {
+		if err := validateCorsPolicy("cors route "+route.Path, mergeCorsPolicy(base, route.CorsPolicy)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateCorsPolicy(name string, policy config.CorsPolicy) error {
+	if !corsCredentials(policy) {
+		return nil
+	}
+	for _, origin := range policy.AllowOrigins {
+		if origin == "*" {
+			return fmt.Errorf("%s: AllowOrigins * cannot be used with AllowCredentials", name)
+		}
+	}
+	return nil
+}
+
+// corsCredentials 是否允许携带凭证，未配置时不允许
+func corsCredentials(policy config.CorsPolicy) bool {
+	return policy.AllowCredentials != nil && *policy.AllowCredentials
+}
+
+// CheckOrigin 请求的Origin是否在跨域配置中允许，用于websocket等不经过Cors中间件的场景，没有Origin时允许
+func CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	cfg := config.Conf.CorsConfig
+	policy, matched := mergeCorsPolicy(defaultCorsPolicy, cfg.CorsPolicy), ""
+	base := policy
+	for _, path := range matchPaths(r.URL.Path) {
+		for _, route := range cfg.Routes {
+			if strings.HasPrefix(path, route.Path) && len(route.Path) > len(matched) {
+				policy, matched = mergeCorsPolicy(base, route.CorsPolicy), route.Path
+			}
+		}
+	}
+	return matchOrigin(policy.AllowOrigins, origin)
+}
+
+// mergeCorsPolicy 用override中已配置的项覆盖base
+func mergeCorsPolicy(base, override config.CorsPolicy) config.CorsPolicy {
+	if len(override.AllowOrigins) > 0 {
+		base.AllowOrigins = override.AllowOrigins
+	}
+	if len(override.AllowMethods) > 0 {
+		base.AllowMethods = override.AllowMethods
+	}
+	if len(override.AllowHeaders) > 0 {
+		base.AllowHeaders = override.AllowHeaders
+	}
+	if len(override.ExposeHeaders) > 0 {
+		base.ExposeHeaders = override.ExposeHeaders
+	}
+	if override.AllowCredentials != nil {
+		base.AllowCredentials = override.AllowCredentials
+	}
+	if override.MaxAge > 0 {
+		base.MaxAge = override.MaxAge
+	}
+	return base
+}
+
+func newCors(policy config.CorsPolicy) gin.HandlerFunc {
+	return cors.New(cors.Config{
+		// 使用AllowOriginFunc回写请求的Origin，支持通配子域名
+		AllowOriginFunc: func(origin string) bool {
+			return matchOrigin(policy.AllowOrigins, origin)
 		},
-	)
+		AllowMethods:     policy.AllowMethods,
+		AllowHeaders:     policy.AllowHeaders,
+		ExposeHeaders:    policy.ExposeHeaders,
+		AllowCredentials: corsCredentials(policy),
+		MaxAge:           policy.MaxAge,
+	})
+}
+
+// matchOrigin 来源是否在允许列表中，支持*和通配子域名，eg: https://*.example.com
+func matchOrigin(patterns []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+		i := strings.Index(pattern, "*.")
+		if i < 0 {
+			continue
+		}
+		prefix, suffix := pattern[:i], pattern[i+1:]
+		if strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) &&
+			len(origin) > len(prefix)+len(suffix) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"go-skeleton/model"
+	"go-skeleton/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUserKey 当前请求已查询的登录用户
+const currentUserKey = "currentUser"
+
+type cachedUser struct {
+	uid  int
+	user *model.User
+}
+
+// currentUser 获取登录用户的资料，同一个请求只查询一次，未登录时返回nil
+func currentUser(c *gin.Context) *model.User {
+	uid := c.GetInt(UidKey)
+	if uid <= 0 {
+		return nil
+	}
+	if v, ok := c.Get(currentUserKey); ok {
+		if cached := v.(*cachedUser); cached.uid == uid {
+			return cached.user
+		}
+	}
+	user := services.UserService.Get(c.Request.Context(), int64(uid))
+	c.Set(currentUserKey, &cachedUser{uid: uid, user: user})
+	return user
+}
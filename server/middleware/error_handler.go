@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	stderrors "errors"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/i18n"
+	"go-skeleton/pkg/jsonresult"
+	"go-skeleton/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrorHandler 统一处理handler通过c.Error(err)记录的错误，eg:
+//
+//	if err := services.ArticleService.Delete(id); err != nil {
+//		_ = c.Error(err)
+//		return
+//	}
+//
+// *errors.CodeError按错误码返回，gorm未找到数据、参数校验失败转换为对应的错误码，
+// 其他错误视为内部错误，生产模式下不返回错误详情
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+
+		var (
+			ce  *errors.CodeError
+			ves validator.ValidationErrors
+		)
+		switch {
+		case stderrors.As(err, &ce):
+		case stderrors.Is(err, gorm.ErrRecordNotFound):
+			ce = errors.NotFoundError
+		case stderrors.As(err, &ves):
+			ce = errors.NewErrorData(errors.InvalidParamsError.Code, errors.InvalidParamsError.Message, ves.Error())
+		default:
+			utils.Logger(c).Error(c.Request.URL.Path, zap.Error(err))
+			if config.Conf.AppMode == "debug" {
+				ce = errors.NewErrorData(errors.InternalError.Code, errors.InternalError.Message, err.Error())
+			} else {
+				ce = errors.InternalError
+			}
+		}
+		c.JSON(errors.HttpStatus(ce.Code), jsonresult.JsonCodeError(i18n.Error(c, ce)))
+	}
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"go-skeleton/pkg/config"
+	"go-skeleton/utils"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 默认缓存的最大响应字节数，更大的响应直接输出，不生成ETag
+const defaultETagMaxBuffer = 1 << 20
+
+// ETag 为GET请求成功的响应生成ETag，请求的If-None-Match一致时返回304且不输出内容，
+// handler已设置ETag时使用handler的，见resp.NotModified。需要在Compress之后注册，按压缩前的内容计算
+func ETag(weak bool, maxBuffer int) gin.HandlerFunc {
+	if maxBuffer <= 0 {
+		maxBuffer = defaultETagMaxBuffer
+	}
+	return func(c *gin.Context) {
+		if (c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead) ||
+			strings.EqualFold(c.GetHeader("Upgrade"), "websocket") ||
+			strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+			c.Next()
+			return
+		}
+		w := &etagWriter{ResponseWriter: c.Writer, max: maxBuffer, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+		if w.passthrough {
+			return
+		}
+		w.finish(c.GetHeader("If-None-Match"), weak)
+	}
+}
+
+// ETagFromConfig 按配置etag生成ETag，未开启时不处理
+func ETagFromConfig() gin.HandlerFunc {
+	cfg := config.Conf.EtagConfig
+	if !cfg.Enable {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	return ETag(cfg.Weak, cfg.MaxBuffer)
+}
+
+// etagWriter 缓存响应直到请求结束，超过max字节、Flush或SSE响应时改为直接输出
+type etagWriter struct {
+	gin.ResponseWriter
+	max         int
+	status      int
+	buf         bytes.Buffer
+	passthrough bool
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+}
+
+// WriteHeaderNow 缓存时响应头在请求结束时输出
+func (w *etagWriter) WriteHeaderNow() {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *etagWriter) Status() int {
+	if w.passthrough {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *etagWriter) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	return w.buf.Len()
+}
+
+func (w *etagWriter) Written() bool {
+	return w.passthrough && w.ResponseWriter.Written()
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if !w.passthrough {
+		if w.buf.Len()+len(b) <= w.max && !strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+			return w.buf.Write(b)
+		}
+		if err := w.startPassthrough(); err != nil {
+			return 0, err
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *etagWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush 流式输出的响应不生成ETag
+func (w *etagWriter) Flush() {
+	if !w.passthrough {
+		_ = w.startPassthrough()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *etagWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.passthrough = true
+	return w.ResponseWriter.Hijack()
+}
+
+// startPassthrough 输出响应头和已缓存的内容，之后的写入直接输出
+func (w *etagWriter) startPassthrough() error {
+	w.passthrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// finish 成功且可以缓存的响应设置ETag，与ifNoneMatch一致时返回304
+func (w *etagWriter) finish(ifNoneMatch string, weak bool) {
+	h := w.Header()
+	if w.status == http.StatusOK && !strings.Contains(h.Get("Cache-Control"), "no-store") {
+		etag := h.Get("ETag")
+		if etag == "" {
+			etag = utils.ETag(weak, w.buf.Bytes())
+			h.Set("ETag", etag)
+		}
+		if utils.ETagMatch(ifNoneMatch, etag) {
+			h.Del("Content-Type")
+			h.Del("Content-Length")
+			w.ResponseWriter.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/experiment"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Experiments 将当前请求的实验分组保存到context，handler中通过experiment.VariantFor(c, "new_checkout")获取分组。
+// 用户id在第一次获取分组时才读取，可以在JwtToken之前注册
+func Experiments() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		a := experiment.NewAssignments(func() int64 {
+			return int64(c.GetInt(UidKey))
+		})
+		c.Set(experiment.AssignmentsKey, a)
+		c.Request = c.Request.WithContext(experiment.NewContext(c.Request.Context(), a))
+		c.Next()
+	}
+}
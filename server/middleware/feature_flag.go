@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/featureflag"
+	"go-skeleton/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureFlags 将当前用户保存到context，handler中通过featureflag.Enabled(c, "new_editor")判断开关。
+// 用户在第一次判断开关时才获取，可以在JwtToken之前注册，未登录时按匿名用户判断
+func FeatureFlags() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fn := featureflag.CachedSubject(func() int64 {
+			return int64(c.GetInt(UidKey))
+		}, func(uid int64) *featureflag.Subject {
+			s := &featureflag.Subject{UserId: uid}
+			if uid > 0 {
+				s.Roles = services.PermissionService.GetUserRoles(c.Request.Context(), uid)
+			}
+			return s
+		})
+		c.Set(featureflag.SubjectKey, fn)
+		c.Request = c.Request.WithContext(featureflag.WithSubjectFunc(c.Request.Context(), fn))
+		c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/geoip"
+	"go-skeleton/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GeoIP 按客户端ip查询地理位置，handler中通过geoip.FromContext(c)获取，未启用geoip时不处理，eg:
+//
+//	if loc := geoip.FromContext(c); loc != nil && loc.CountryCode != "CN" {}
+func GeoIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reader := geoip.Default()
+		if reader == nil {
+			c.Next()
+			return
+		}
+		loc, err := reader.Lookup(c.ClientIP())
+		if err != nil {
+			utils.Logger(c).Debug("geoip lookup failed", zap.String("ip", c.ClientIP()), zap.Error(err))
+			c.Next()
+			return
+		}
+		c.Set(geoip.LocationKey, loc)
+		c.Request = c.Request.WithContext(geoip.NewContext(c.Request.Context(), loc))
+		c.Next()
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LangParam 指定语言的url参数
+const LangParam = "lang"
+
+// I18n 确定请求使用的语言，优先级: lang参数 > 登录用户设置的语言 > Accept-Language。
+// 语言在第一次调用i18n.T时才确定，因此放在JwtToken之前也能获取到登录用户的语言
+func I18n() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r := &requestLocale{c: c}
+		c.Set(i18n.LocaleKey, r)
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), r))
+		c.Next()
+	}
+}
+
+// requestLocale 延迟确定请求的语言
+type requestLocale struct {
+	c *gin.Context
+}
+
+func (r *requestLocale) Locale() string {
+	if lang := r.c.Query(LangParam); lang != "" {
+		return i18n.Match(lang)
+	}
+	if user := currentUser(r.c); user != nil && user.Locale != "" {
+		return i18n.Match(user.Locale)
+	}
+	return i18n.Match(r.c.GetHeader("Accept-Language"))
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 幂等请求头
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+// 第一次请求处理的最长时间
+const idempotencyLockTTL = time.Minute
+
+// idempotencyRecord 保存的第一次请求的响应，Status为0表示第一次请求仍在处理
+type idempotencyRecord struct {
+	Hash   string `json:"hash"`
+	Status int    `json:"status,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Body   []byte `json:"body,omitempty"`
+}
+
+// idempotencyWriter 记录响应内容
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency 幂等中间件，相同Idempotency-Key的请求只执行一次，ttl内的重复请求直接返回第一次的响应，
+// 未携带Idempotency-Key时不处理。key按用户(未登录时按ip)隔离，同一个key用于不同的请求参数时返回422，
+// 第一次请求返回5xx时不保存，允许客户端重试，eg:
+//	apiRouter.POST("/article/add", middleware.JwtToken(), middleware.Idempotency(24*time.Hour), art.AddArticle)
+func Idempotency(ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || len(key) > 255 {
+			c.Next()
+			return
+		}
+		body, err := utils.ReadBody(c)
+		if err != nil {
+			resp.Abort(c, http.StatusBadRequest, errors.InvalidParamsError)
+			return
+		}
+
+		hash := requestFingerprint(c.Request, body)
+		redisKey := idempotencyKey(c, key)
+		rdb := gredis.GetRedis()
+
+		// 处理中的标记使用较短的过期时间，避免进程异常退出后key一直不可用
+		lockTTL := idempotencyLockTTL
+		if ttl < lockTTL {
+			lockTTL = ttl
+		}
+		processing, _ := json.Marshal(idempotencyRecord{Hash: hash})
+		ok, err := rdb.SetNX(c, redisKey, processing, lockTTL).Result()
+		if err != nil {
+			// redis不可用时不影响正常请求
+			zap.L().Warn("idempotency: redis error", zap.Error(err))
+			c.Next()
+			return
+		}
+		if !ok {
+			replayIdempotent(c, rdb.Get(c, redisKey).Val(), hash)
+			return
+		}
+
+		w := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		status := w.Status()
+		if status >= http.StatusInternalServerError {
+			rdb.Del(c, redisKey)
+			return
+		}
+		record, _ := json.Marshal(idempotencyRecord{
+			Hash:   hash,
+			Status: status,
+			Type:   w.Header().Get("Content-Type"),
+			Body:   w.body.Bytes(),
+		})
+		if err := rdb.Set(c, redisKey, record, ttl).Err(); err != nil {
+			zap.L().Warn("idempotency: save response failed", zap.Error(err))
+		}
+	}
+}
+
+func idempotencyKey(c *gin.Context, key string) string {
+	scope := "ip:" + c.ClientIP()
+	if uid, ok := c.Get(UidKey); ok {
+		scope = fmt.Sprintf("user:%v", uid)
+	}
+	return "idempotency:" + scope + ":" + utils.SHA256Hex([]byte(key))
+}
+
+// replayIdempotent 重复请求，返回第一次请求的响应
+func replayIdempotent(c *gin.Context, val, hash string) {
+	var record idempotencyRecord
+	if err := json.Unmarshal([]byte(val), &record); err != nil {
+		// 第一次请求的记录刚好过期
+		resp.Abort(c, http.StatusConflict, errors.IdempotencyProcessingError)
+		return
+	}
+	if record.Hash != hash {
+		resp.Abort(c, http.StatusUnprocessableEntity, errors.IdempotencyMismatchError)
+		return
+	}
+	if record.Status == 0 {
+		resp.Abort(c, http.StatusConflict, errors.IdempotencyProcessingError)
+		return
+	}
+	c.Header(IdempotencyReplayedHeader, "true")
+	c.Data(record.Status, record.Type, record.Body)
+	c.Abort()
+}
+
+// requestFingerprint 请求的摘要，方法、路径、查询参数或请求体不同时视为不同的请求
+func requestFingerprint(r *http.Request, body []byte) string {
+	return utils.SHA256Hex(append([]byte(r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery+"\n"), body...))
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestFingerprint(t *testing.T) {
+	body := []byte(`{"title":"go"}`)
+	base := requestFingerprint(httptest.NewRequest("POST", "/api/article/add?id=1", nil), body)
+
+	assert.Equal(t, base, requestFingerprint(httptest.NewRequest("POST", "/api/article/add?id=1", nil), body))
+	// 查询参数、方法、路径、请求体不同时都不能复用同一个Idempotency-Key
+	assert.NotEqual(t, base, requestFingerprint(httptest.NewRequest("POST", "/api/article/add?id=2", nil), body))
+	assert.NotEqual(t, base, requestFingerprint(httptest.NewRequest("POST", "/api/article/add", nil), body))
+	assert.NotEqual(t, base, requestFingerprint(httptest.NewRequest("PUT", "/api/article/add?id=1", nil), body))
+	assert.NotEqual(t, base, requestFingerprint(httptest.NewRequest("POST", "/api/article/update?id=1", nil), body))
+	assert.NotEqual(t, base, requestFingerprint(httptest.NewRequest("POST", "/api/article/add?id=1", nil), []byte(`{"title":"gin"}`)))
+}
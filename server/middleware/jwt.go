@@ -4,13 +4,23 @@ import (
 	"go-skeleton/pkg/auth"
 	"go-skeleton/pkg/errors"
 	"go-skeleton/pkg/jsonresult"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/pkg/tenant"
+	"go-skeleton/services"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-// jwt中间件
+// 上下文中保存登录信息的key
+const (
+	UidKey    = "uid"
+	ClaimsKey = "claims"
+	TokenKey  = "token"
+)
+
+// jwt中间件，验证通过后将uid、claims、token写入上下文，开启多租户时用户不属于当前租户返回403
 func JwtToken() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenHeader := c.Request.Header.Get("Authorization")
@@ -38,7 +48,25 @@ func JwtToken() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-		c.Set("uid", key.Id)
+		// 请求头或子域名指定的租户必须是用户所属的租户
+		ctx := c.Request.Context()
+		if tid, ok := tenant.FromContext(ctx); ok && !services.TenantService.IsMember(ctx, tid, int64(key.Id)) {
+			resp.Abort(c, http.StatusForbidden, errors.PermissionDeniedError)
+			return
+		}
+		c.Set(UidKey, key.Id)
+		c.Set(ClaimsKey, key)
+		c.Set(TokenKey, checkToken[1])
 		c.Next()
 	}
 }
+
+// GetClaims 获取JwtToken中间件写入的claims，未登录时返回nil
+func GetClaims(c *gin.Context) *auth.MyClaims {
+	if v, ok := c.Get(ClaimsKey); ok {
+		if claims, ok := v.(*auth.MyClaims); ok {
+			return claims
+		}
+	}
+	return nil
+}
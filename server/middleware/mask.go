@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/mask"
+	"go-skeleton/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Unmask 关闭响应数据脱敏，需在JwtToken之后使用。不传权限时所有请求都输出原始数据，eg: 用户查看自己的资料；
+// 传入权限时拥有任意一个权限的用户才输出原始数据
+func Unmask(codes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(codes) == 0 {
+			mask.Disable(c)
+			c.Next()
+			return
+		}
+		if uid := c.GetInt(UidKey); uid > 0 {
+			for _, code := range codes {
+				if services.PermissionService.HasPermission(c.Request.Context(), int64(uid), code) {
+					mask.Disable(c)
+					break
+				}
+			}
+		}
+		c.Next()
+	}
+}
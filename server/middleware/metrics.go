@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics 按路由统计请求数和耗时，未匹配路由的请求记为unmatched，避免路径过多
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		metrics.HttpRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HttpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission 权限中间件，需在JwtToken之后使用，拥有任意一个权限即可访问
+// eg: adminRouter.POST("/article/delete", middleware.RequirePermission("article:delete"), art.DeleteArticle)
+func RequirePermission(codes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uid := c.GetInt(UidKey)
+		if uid <= 0 {
+			resp.Abort(c, http.StatusUnauthorized, errors.UnauthorizedError)
+			return
+		}
+		for _, code := range codes {
+			if services.PermissionService.HasPermission(c.Request.Context(), int64(uid), code) {
+				c.Next()
+				return
+			}
+		}
+		resp.Abort(c, http.StatusForbidden, errors.PermissionDeniedError)
+	}
+}
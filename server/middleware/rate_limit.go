@@ -1,17 +1,30 @@
 package middleware
 
 import (
+	"fmt"
+	"go-skeleton/pkg/auth"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/pkg/ratelimit"
+	"go-skeleton/pkg/resp"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/juju/ratelimit"
+	jujuratelimit "github.com/juju/ratelimit"
+	"go.uber.org/zap"
 )
 
 //限流中间件 RateLimitMiddleware(time.Second,1),表示1秒钟一个请求
 func RateLimitMiddleware(fillInterval time.Duration, cap int64) func(c *gin.Context) {
 	//创建指定填充速率和容量大小的令牌桶
-	bucket := ratelimit.NewBucket(fillInterval, cap)
+	bucket := jujuratelimit.NewBucket(fillInterval, cap)
 	return func(c *gin.Context) {
 		// 如果取不到令牌就中断本次请求返回 rate limit...
 		if bucket.TakeAvailable(1) < 1 {
@@ -25,3 +38,137 @@ func RateLimitMiddleware(fillInterval time.Duration, cap int64) func(c *gin.Cont
 		c.Next()
 	}
 }
+
+// RateLimitKeyFunc 限流的维度，返回空字符串时不限流
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RateLimitByIP 按客户端ip限流
+func RateLimitByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimitByUser 按登录用户限流，在JwtToken之前使用时从Authorization解析用户，未登录时按ip限流
+func RateLimitByUser(c *gin.Context) string {
+	if uid, ok := c.Get(UidKey); ok {
+		return fmt.Sprintf("user:%v", uid)
+	}
+	if parts := strings.Split(c.GetHeader("Authorization"), " "); len(parts) == 2 && parts[0] == "Bearer" {
+		if claims, err := auth.CheckToken(parts[1]); err == nil {
+			return fmt.Sprintf("user:%d", claims.Id)
+		}
+	}
+	return RateLimitByIP(c)
+}
+
+var (
+	limiterOnce sync.Once
+	limiter     ratelimit.Limiter
+)
+
+// rateLimiter 根据配置选择限流器，redis适合多实例部署
+func rateLimiter() ratelimit.Limiter {
+	limiterOnce.Do(func() {
+		if config.Conf.RateLimitConfig.Store == "redis" {
+			limiter = ratelimit.NewRedisLimiter(gredis.GetRedis())
+			return
+		}
+		limiter = ratelimit.NewMemoryLimiter()
+	})
+	return limiter
+}
+
+// RateLimit 限流中间件，scope区分不同的限流规则，eg:
+//
+//	r.POST("/login", middleware.RateLimit("login", 5, time.Minute, middleware.RateLimitByIP), ...)
+func RateLimit(scope string, limit int, window time.Duration, keyFunc RateLimitKeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !allow(c, scope, limit, window, keyFunc) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimitFromConfig 按配置文件的ratelimit规则限流，配置修改后自动生效
+func RateLimitFromConfig() gin.HandlerFunc {
+	var current atomic.Value
+	current.Store(config.Conf.RateLimitConfig)
+	config.OnChange("ratelimit", func(old, new interface{}) {
+		var cfg config.RateLimitConfig
+		if err := config.UnmarshalKey("ratelimit", &cfg); err != nil {
+			zap.L().Error("解析限流配置失败", zap.Error(err))
+			return
+		}
+		current.Store(cfg)
+	})
+
+	return func(c *gin.Context) {
+		cfg := current.Load().(config.RateLimitConfig)
+		if !cfg.Enable {
+			c.Next()
+			return
+		}
+
+		scope, limit, window, by := "default", cfg.Limit, cfg.Window, cfg.By
+		for _, rule := range cfg.Rules {
+			if matchRule(c, rule) {
+				scope, limit, window, by = rule.Method+rule.Path, rule.Limit, rule.Window, rule.By
+				break
+			}
+		}
+		keyFunc := RateLimitByIP
+		if by == "user" {
+			keyFunc = RateLimitByUser
+		}
+		if !allow(c, scope, limit, window, keyFunc) {
+			return
+		}
+		c.Next()
+	}
+}
+
+func matchRule(c *gin.Context, rule config.RateLimitRule) bool {
+	if rule.Method != "" && !strings.EqualFold(rule.Method, c.Request.Method) {
+		return false
+	}
+	for _, path := range append(matchPaths(c.Request.URL.Path), matchPaths(c.FullPath())...) {
+		if strings.HasSuffix(rule.Path, "*") {
+			if strings.HasPrefix(path, strings.TrimSuffix(rule.Path, "*")) {
+				return true
+			}
+		} else if path == rule.Path {
+			return true
+		}
+	}
+	return false
+}
+
+// allow 判断是否放行并写入X-RateLimit-*响应头，超限时中止请求
+func allow(c *gin.Context, scope string, limit int, window time.Duration, keyFunc RateLimitKeyFunc) bool {
+	if limit <= 0 || window <= 0 {
+		return true
+	}
+	key := keyFunc(c)
+	if key == "" {
+		return true
+	}
+
+	ret, err := rateLimiter().Allow(c.Request.Context(), scope+":"+key, limit, window)
+	if err != nil {
+		// 限流器故障时放行，避免影响正常业务
+		zap.L().Error("限流失败", zap.String("key", key), zap.Error(err))
+		return true
+	}
+
+	h := c.Writer.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(ret.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(ret.Remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(int64(math.Ceil(ret.ResetAfter.Seconds())), 10))
+	if ret.Allowed {
+		return true
+	}
+
+	h.Set("Retry-After", strconv.FormatInt(int64(math.Ceil(ret.RetryAfter.Seconds())), 10))
+	resp.Abort(c, errors.HttpStatus(errors.TooManyRequestsError.Code), errors.TooManyRequestsError)
+	return false
+}
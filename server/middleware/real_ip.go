@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/config"
+	"go-skeleton/utils"
+	"net"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RealIP 按可信代理解析客户端的真实ip并写回RemoteAddr，之后c.ClientIP()返回的即为真实ip。
+// 需要关闭engine.ForwardedByClientIP，否则gin会直接使用X-Forwarded-For中的第一个地址
+func RealIP(trusted []*net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := utils.RealIP(c.Request, trusted)
+		_, port, err := net.SplitHostPort(c.Request.RemoteAddr)
+		if err != nil {
+			port = "0"
+		}
+		c.Request.RemoteAddr = net.JoinHostPort(ip, port)
+		c.Next()
+	}
+}
+
+// RealIPFromConfig 使用server.TrustedProxies中配置的可信代理
+func RealIPFromConfig() gin.HandlerFunc {
+	trusted, err := utils.ParseCIDRs(config.Conf.ServerConfig.TrustedProxies...)
+	if err != nil {
+		zap.L().Error("解析可信代理配置失败", zap.Error(err))
+	}
+	return RealIP(trusted)
+}
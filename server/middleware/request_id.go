@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"go-skeleton/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 请求id的header
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID 读取或生成请求id，写入上下文和响应头，用于串联跨服务的日志
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 64 {
+			id = utils.UUID()
+		}
+		c.Set(utils.RequestIDKey, id)
+		c.Request = c.Request.WithContext(utils.WithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
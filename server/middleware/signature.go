@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 开放接口的参数名及上下文中保存合作方的key
+const (
+	AppKeyParam = "app_key"
+	AppKeyKey   = "app_key"
+	// 非表单请求的body以sha256(body)作为body参数参与签名
+	BodyHashParam = "body"
+)
+
+// SignatureAuth 开放接口签名校验，参数包括app_key、timestamp(秒)、nonce、sign，签名算法以合作方配置的SignType为准，
+// 请求方法、路径及query和表单参数按utils.SignRequest签名，json等其他body以sha256 hex作为body参数参与签名，
+// 时间戳超出openapi.TimestampSkew、nonce重复使用或无法校验nonce时拒绝请求，eg:
+//
+//	openRouter := e.Group("open").Use(middleware.SignatureAuth())
+func SignatureAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.Conf.OpenApiConfig
+		skew := cfg.TimestampSkew
+		if skew <= 0 {
+			skew = 5 * time.Minute
+		}
+
+		params, err := signParams(c)
+		if err != nil {
+			resp.Abort(c, http.StatusBadRequest, errors.InvalidParamsError)
+			return
+		}
+		appKey := params[AppKeyParam]
+		partner, ok := findPartner(cfg.Partners, appKey)
+		if !ok || params[utils.SignKey] == "" || params[utils.NonceKey] == "" {
+			resp.Abort(c, http.StatusUnauthorized, errors.SignatureError)
+			return
+		}
+		ts, err := strconv.ParseInt(params[utils.TimestampKey], 10, 64)
+		if err != nil {
+			resp.Abort(c, http.StatusUnauthorized, errors.SignatureError)
+			return
+		}
+		if d := time.Since(time.Unix(ts, 0)); d > skew || d < -skew {
+			resp.Abort(c, http.StatusUnauthorized, errors.SignatureExpiredError)
+			return
+		}
+
+		// 不使用请求中的sign_type，避免被降级为md5签名
+		signType := utils.SignType(partner.SignType)
+		if !utils.VerifyRequestSign(signType, c.Request.Method, c.Request.URL.Path, params, partner.Secret, params[utils.SignKey]) {
+			resp.Abort(c, http.StatusUnauthorized, errors.SignatureError)
+			return
+		}
+
+		// 签名通过后再记录nonce，避免伪造的请求占用nonce
+		key := "openapi:nonce:" + appKey + ":" + params[utils.NonceKey]
+		fresh, err := gredis.GetRedis().SetNX(c, key, 1, 2*skew).Result()
+		if err != nil {
+			// 无法确认nonce未被使用时拒绝请求，避免redis不可用期间可以重放
+			zap.L().Error("signature: redis error", zap.Error(err))
+			resp.Abort(c, http.StatusServiceUnavailable, errors.InternalError)
+			return
+		}
+		if !fresh {
+			resp.Abort(c, http.StatusUnauthorized, errors.SignatureExpiredError)
+			return
+		}
+
+		c.Set(AppKeyKey, appKey)
+		c.Next()
+	}
+}
+
+// signParams 参与签名的参数，同名参数有多个值时以","连接
+func signParams(c *gin.Context) (map[string]string, error) {
+	var body []byte
+	if c.Request.Body != nil && !isFormRequest(c.ContentType()) {
+		var err error
+		if body, err = utils.ReadBody(c); err != nil {
+			return nil, err
+		}
+	}
+	if strings.HasPrefix(c.ContentType(), gin.MIMEMultipartPOSTForm) {
+		if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+			return nil, err
+		}
+	} else if err := c.Request.ParseForm(); err != nil {
+		return nil, err
+	}
+	params := utils.ToParams(c.Request.Form)
+	if len(body) > 0 {
+		params[BodyHashParam] = utils.SHA256Hex(body)
+	}
+	return params, nil
+}
+
+func isFormRequest(contentType string) bool {
+	return contentType == gin.MIMEPOSTForm || strings.HasPrefix(contentType, gin.MIMEMultipartPOSTForm)
+}
+
+func findPartner(partners []config.OpenApiPartner, appKey string) (config.OpenApiPartner, bool) {
+	if appKey == "" {
+		return config.OpenApiPartner{}, false
+	}
+	for _, p := range partners {
+		if p.AppKey == appKey {
+			return p, true
+		}
+	}
+	return config.OpenApiPartner{}, false
+}
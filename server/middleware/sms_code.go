@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/resp"
+	"go-skeleton/pkg/sms"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 短信验证通过的手机号
+const SmsPhoneKey = "smsPhone"
+
+// SmsCode 校验表单中的phone和smsCode，通过后可以用c.GetString(middleware.SmsPhoneKey)获取手机号，
+// eg: e.POST("/site/register", middleware.SmsCode(sms.SceneRegister), site.Register)
+func SmsCode(scene string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phone := c.PostForm("phone")
+		if err := sms.VerifyCode(c, scene, phone, c.PostForm("smsCode")); err != nil {
+			resp.Error(c, err)
+			c.Abort()
+			return
+		}
+		c.Set(SmsPhoneKey, phone)
+		c.Next()
+	}
+}
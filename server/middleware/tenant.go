@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/pkg/tenant"
+	"go-skeleton/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tenant 按请求头或子域名解析租户并写入请求的context，
+// 通过db.WithContext(c.Request.Context())执行的语句只会访问当前租户的数据。
+// 严格模式下解析不到租户时返回404，跨域的预检请求不带自定义请求头，不解析租户
+func Tenant() gin.HandlerFunc {
+	cfg := config.Conf.TenantConfig
+	return func(c *gin.Context) {
+		if !cfg.Enable || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+		code := ""
+		if cfg.Header != "" {
+			code = c.GetHeader(cfg.Header)
+		}
+		if code == "" {
+			code = tenant.Subdomain(c.Request.Host, cfg.Domain)
+		}
+		t := services.TenantService.Resolve(c.Request.Context(), code)
+		if t == nil {
+			if cfg.Strict {
+				resp.Abort(c, http.StatusNotFound, errors.NotFoundError)
+				return
+			}
+			c.Next()
+			return
+		}
+		c.Set(tenant.IDKey, t.ID)
+		c.Request = c.Request.WithContext(tenant.WithTenant(c.Request.Context(), t.ID))
+		c.Next()
+	}
+}
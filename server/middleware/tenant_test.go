@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"go-skeleton/pkg/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantPreflight(t *testing.T) {
+	old := config.Conf.TenantConfig
+	defer func() { config.Conf.TenantConfig = old }()
+	config.Conf.TenantConfig = config.TenantConfig{Enable: true, Strict: true, Header: "X-Tenant"}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Tenant())
+	r.OPTIONS("/api/article/list", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	// 浏览器的预检请求不会携带X-Tenant，严格模式下也不能被拦截
+	req := httptest.NewRequest(http.MethodOptions, "/api/article/list", nil)
+	req.Header.Set("Origin", "https://acme.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	req.Header.Set("Access-Control-Request-Headers", "X-Tenant")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"go-skeleton/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimezoneHeader 客户端时区的请求头，值为IANA名称，eg: Asia/Shanghai
+const TimezoneHeader = "X-Timezone"
+
+// Timezone 确定请求使用的时区，优先级: 登录用户设置的时区 > X-Timezone > 服务器时区，
+// handler中通过utils.LocationFrom(c)获取，eg:
+//
+//	utils.DateIn(art.CreatedAt.Unix(), utils.LocationFrom(c))
+func Timezone() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r := &requestLocation{c: c}
+		c.Set(utils.LocationKey, r)
+		c.Request = c.Request.WithContext(utils.WithLocation(c.Request.Context(), r))
+		c.Next()
+	}
+}
+
+// requestLocation 延迟确定请求的时区，与requestLocale相同
+type requestLocation struct {
+	c *gin.Context
+}
+
+func (r *requestLocation) Location() *time.Location {
+	if user := currentUser(r.c); user != nil && user.Timezone != "" {
+		if loc, err := utils.LoadLocation(user.Timezone); err == nil {
+			return loc
+		}
+	}
+	if name := r.c.GetHeader(TimezoneHeader); name != "" {
+		if loc, err := utils.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
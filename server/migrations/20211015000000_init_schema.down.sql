@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS `user_oauth`;
+DROP TABLE IF EXISTS `messages`;
+DROP TABLE IF EXISTS `comment`;
+DROP TABLE IF EXISTS `article_tag`;
+DROP TABLE IF EXISTS `tag`;
+DROP TABLE IF EXISTS `article`;
+DROP TABLE IF EXISTS `category`;
+DROP TABLE IF EXISTS `user_role`;
+DROP TABLE IF EXISTS `role_permission`;
+DROP TABLE IF EXISTS `permission`;
+DROP TABLE IF EXISTS `role`;
+DROP TABLE IF EXISTS `user`;
@@ -0,0 +1,181 @@
+-- 初始表结构，已有数据库(document/article.sql导入)的表不会重复创建
+
+CREATE TABLE IF NOT EXISTS `user` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `username` varchar(20) NOT NULL,
+  `password` varchar(500) NOT NULL,
+  `role` bigint(20) DEFAULT 2,
+  `nickname` varchar(32) NOT NULL DEFAULT '',
+  `avatar` varchar(255) NOT NULL DEFAULT '',
+  `email` varchar(128) NOT NULL DEFAULT '',
+  `phone` varchar(20) NOT NULL DEFAULT '',
+  `status` tinyint(4) NOT NULL DEFAULT 0,
+  `last_login_at` datetime(3) NULL,
+  `last_login_ip` varchar(64) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`),
+  INDEX `idx_user_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `role` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `name` varchar(32) NOT NULL,
+  `code` varchar(32) NOT NULL,
+  `description` varchar(255),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `code` (`code`),
+  INDEX `idx_role_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `permission` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `name` varchar(32) NOT NULL,
+  `code` varchar(64) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `code` (`code`),
+  INDEX `idx_permission_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `role_permission` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `role_id` bigint NOT NULL,
+  `permission_id` bigint NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_role_permission` (`role_id`, `permission_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `user_role` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `role_id` bigint NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_user_role` (`user_id`, `role_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `category` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(20) NOT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `article` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `title` varchar(100) NOT NULL,
+  `cid` bigint unsigned NOT NULL,
+  `desc` varchar(200),
+  `content` longtext,
+  `img` varchar(100),
+  `comment_count` bigint(20) NOT NULL DEFAULT 0,
+  `read_count` bigint(20) NOT NULL DEFAULT 0,
+  `user_id` bigint NOT NULL DEFAULT 0,
+  `slug` varchar(128),
+  `status` bigint NOT NULL DEFAULT 0,
+  `published_at` bigint NOT NULL DEFAULT 0,
+  `version` bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`),
+  INDEX `fk_article_category` (`cid`),
+  INDEX `idx_article_user_id` (`user_id`),
+  UNIQUE INDEX `uk_article_slug` (`slug`),
+  INDEX `idx_article_status` (`status`),
+  INDEX `idx_article_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_article_category` FOREIGN KEY (`cid`) REFERENCES `category` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `tag` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `name` varchar(32) NOT NULL,
+  `description` varchar(1024),
+  `status` bigint NOT NULL,
+  `create_time` bigint,
+  `update_time` bigint,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `name` (`name`),
+  INDEX `idx_tag_deleted_at` (`deleted_at`),
+  INDEX `idx_tag_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `article_tag` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `article_id` bigint NOT NULL,
+  `tag_id` bigint NOT NULL,
+  `status` bigint NOT NULL,
+  `create_time` bigint,
+  PRIMARY KEY (`id`),
+  INDEX `idx_article_tag_tag_id` (`tag_id`),
+  INDEX `idx_article_tag_status` (`status`),
+  INDEX `idx_article_tag_deleted_at` (`deleted_at`),
+  INDEX `idx_article_id` (`article_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `comment` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `article_id` bigint NOT NULL,
+  `user_id` bigint NOT NULL,
+  `root_id` bigint NOT NULL DEFAULT 0,
+  `parent_id` bigint NOT NULL DEFAULT 0,
+  `reply_user_id` bigint NOT NULL DEFAULT 0,
+  `content` text NOT NULL,
+  `reply_count` bigint NOT NULL DEFAULT 0,
+  `status` bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`),
+  INDEX `idx_comment_deleted_at` (`deleted_at`),
+  INDEX `idx_comment_article_id` (`article_id`),
+  INDEX `idx_comment_user_id` (`user_id`),
+  INDEX `idx_comment_root_id` (`root_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `messages` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `from_id` bigint NOT NULL,
+  `user_id` bigint NOT NULL,
+  `title` varchar(1024),
+  `content` text NOT NULL,
+  `quote_content` text,
+  `type` bigint NOT NULL,
+  `extra_data` text,
+  `status` bigint NOT NULL,
+  `create_time` bigint,
+  PRIMARY KEY (`id`),
+  INDEX `idx_messages_deleted_at` (`deleted_at`),
+  INDEX `idx_message_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `user_oauth` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `user_id` bigint NOT NULL,
+  `provider` varchar(32) NOT NULL,
+  `open_id` varchar(128) NOT NULL,
+  `union_id` varchar(128) NOT NULL DEFAULT '',
+  `nickname` varchar(64) NOT NULL DEFAULT '',
+  `avatar` varchar(255) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`),
+  INDEX `idx_user_oauth_deleted_at` (`deleted_at`),
+  INDEX `idx_user_oauth_user_id` (`user_id`),
+  UNIQUE INDEX `uk_user_oauth_open_id` (`provider`, `open_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS `audit_log` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `actor_id` bigint NOT NULL DEFAULT 0,
+  `action` varchar(16) NOT NULL,
+  `entity` varchar(64) NOT NULL,
+  `entity_id` varchar(64) NOT NULL,
+  `before` text,
+  `after` text,
+  `diff` text,
+  `ip` varchar(64) NOT NULL DEFAULT '',
+  `request_id` varchar(64) NOT NULL DEFAULT '',
+  `created_at` bigint,
+  PRIMARY KEY (`id`),
+  INDEX `idx_audit_log_actor_id` (`actor_id`),
+  INDEX `idx_audit_log_entity` (`entity`, `entity_id`),
+  INDEX `idx_audit_log_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
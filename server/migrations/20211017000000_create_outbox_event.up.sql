@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `outbox_event` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `topic` varchar(128) NOT NULL,
+  `key` varchar(128) NOT NULL DEFAULT '',
+  `payload` text NOT NULL,
+  `attempts` bigint NOT NULL DEFAULT 0,
+  `last_error` varchar(512) NOT NULL DEFAULT '',
+  `next_attempt_at` bigint NOT NULL DEFAULT 0,
+  `sent_at` bigint NOT NULL DEFAULT 0,
+  `created_at` bigint,
+  PRIMARY KEY (`id`),
+  INDEX `idx_outbox_event_pending` (`sent_at`, `next_attempt_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
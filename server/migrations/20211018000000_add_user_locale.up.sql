@@ -0,0 +1 @@
+ALTER TABLE `user` ADD COLUMN `locale` varchar(16) NOT NULL DEFAULT '' AFTER `last_login_ip`;
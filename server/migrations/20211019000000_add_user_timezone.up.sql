@@ -0,0 +1 @@
+ALTER TABLE `user` ADD COLUMN `timezone` varchar(64) NOT NULL DEFAULT '' AFTER `locale`;
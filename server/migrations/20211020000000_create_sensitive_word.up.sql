@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS `sensitive_word` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `word` varchar(64) NOT NULL,
+  `created_at` bigint,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uk_sensitive_word` (`word`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
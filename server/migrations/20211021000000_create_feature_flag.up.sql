@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `feature_flag` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `name` varchar(64) NOT NULL,
+  `description` varchar(255) NOT NULL DEFAULT '',
+  `enabled` tinyint(1) NOT NULL DEFAULT 0,
+  `percentage` int NOT NULL DEFAULT 0,
+  `users` text,
+  `roles` varchar(255) NOT NULL DEFAULT '',
+  `created_at` bigint,
+  `updated_at` bigint,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uk_feature_flag_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
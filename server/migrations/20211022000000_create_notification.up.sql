@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `notification` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `actor_id` bigint NOT NULL DEFAULT 0,
+  `type` varchar(32) NOT NULL,
+  `article_id` bigint NOT NULL DEFAULT 0,
+  `comment_id` bigint NOT NULL DEFAULT 0,
+  `content` varchar(512) NOT NULL DEFAULT '',
+  `read_at` bigint NOT NULL DEFAULT 0,
+  `created_at` bigint,
+  PRIMARY KEY (`id`),
+  INDEX `idx_notification_user_id` (`user_id`, `read_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,3 @@
+ALTER TABLE `comment` DROP COLUMN `favorite_count`, DROP COLUMN `like_count`;
+ALTER TABLE `article` DROP COLUMN `favorite_count`, DROP COLUMN `like_count`;
+DROP TABLE IF EXISTS `reaction`;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS `reaction` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `entity_type` varchar(16) NOT NULL,
+  `entity_id` bigint NOT NULL,
+  `type` varchar(16) NOT NULL,
+  `created_at` bigint,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uk_reaction` (`user_id`, `entity_type`, `entity_id`, `type`),
+  INDEX `idx_reaction_entity` (`entity_type`, `entity_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+ALTER TABLE `article` ADD COLUMN `like_count` bigint NOT NULL DEFAULT 0 AFTER `read_count`,
+  ADD COLUMN `favorite_count` bigint NOT NULL DEFAULT 0 AFTER `like_count`;
+ALTER TABLE `comment` ADD COLUMN `like_count` bigint NOT NULL DEFAULT 0 AFTER `reply_count`,
+  ADD COLUMN `favorite_count` bigint NOT NULL DEFAULT 0 AFTER `like_count`;
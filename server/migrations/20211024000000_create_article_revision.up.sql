@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS `article_revision` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `article_id` bigint NOT NULL,
+  `user_id` bigint NOT NULL DEFAULT 0,
+  `version` bigint NOT NULL DEFAULT 0,
+  `autosave` tinyint(1) NOT NULL DEFAULT 0,
+  `title` varchar(100) NOT NULL,
+  `cid` bigint unsigned NOT NULL DEFAULT 0,
+  `desc` varchar(200),
+  `content` longtext,
+  `img` varchar(100),
+  `fields` varchar(255) NOT NULL DEFAULT '',
+  `lines_added` int NOT NULL DEFAULT 0,
+  `lines_removed` int NOT NULL DEFAULT 0,
+  `created_at` bigint,
+  PRIMARY KEY (`id`),
+  INDEX `idx_article_revision_article_id` (`article_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
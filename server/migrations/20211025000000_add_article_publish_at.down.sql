@@ -0,0 +1 @@
+ALTER TABLE `article` DROP INDEX `idx_article_publish_at`, DROP COLUMN `publish_at`;
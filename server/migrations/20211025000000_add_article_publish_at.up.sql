@@ -0,0 +1,2 @@
+ALTER TABLE `article` ADD COLUMN `publish_at` bigint NOT NULL DEFAULT 0 AFTER `published_at`,
+  ADD INDEX `idx_article_publish_at` (`publish_at`);
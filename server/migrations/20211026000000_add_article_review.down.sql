@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `article_status_log`;
+ALTER TABLE `article` DROP INDEX `idx_article_reviewer_id`, DROP COLUMN `reject_reason`, DROP COLUMN `reviewer_id`;
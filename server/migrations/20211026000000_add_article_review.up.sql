@@ -0,0 +1,15 @@
+ALTER TABLE `article` ADD COLUMN `reviewer_id` bigint NOT NULL DEFAULT 0 AFTER `publish_at`,
+  ADD COLUMN `reject_reason` varchar(255) NOT NULL DEFAULT '' AFTER `reviewer_id`,
+  ADD INDEX `idx_article_reviewer_id` (`reviewer_id`);
+CREATE TABLE IF NOT EXISTS `article_status_log` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `article_id` bigint NOT NULL,
+  `actor_id` bigint NOT NULL DEFAULT 0,
+  `action` varchar(16) NOT NULL,
+  `from_status` int NOT NULL DEFAULT 0,
+  `to_status` int NOT NULL DEFAULT 0,
+  `remark` varchar(255) NOT NULL DEFAULT '',
+  `created_at` bigint,
+  PRIMARY KEY (`id`),
+  INDEX `idx_article_status_log_article_id` (`article_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,5 @@
+ALTER TABLE `comment` DROP INDEX `idx_comment_tenant_id`, DROP COLUMN `tenant_id`;
+ALTER TABLE `tag` DROP INDEX `uk_tag_name`, DROP COLUMN `tenant_id`, ADD UNIQUE INDEX `name` (`name`);
+ALTER TABLE `category` DROP INDEX `idx_category_tenant_id`, DROP COLUMN `tenant_id`;
+ALTER TABLE `article` DROP INDEX `uk_article_slug`, DROP COLUMN `tenant_id`, ADD UNIQUE INDEX `uk_article_slug` (`slug`);
+DROP TABLE IF EXISTS `tenant`;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS `tenant` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `code` varchar(64) NOT NULL,
+  `name` varchar(128) NOT NULL,
+  `status` int NOT NULL DEFAULT 0,
+  `created_at` bigint,
+  `updated_at` bigint,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uk_tenant_code` (`code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+ALTER TABLE `article` ADD COLUMN `tenant_id` bigint NOT NULL DEFAULT 0 AFTER `deleted_at`,
+  DROP INDEX `uk_article_slug`,
+  ADD UNIQUE INDEX `uk_article_slug` (`tenant_id`, `slug`);
+ALTER TABLE `category` ADD COLUMN `tenant_id` bigint NOT NULL DEFAULT 0 AFTER `id`,
+  ADD INDEX `idx_category_tenant_id` (`tenant_id`);
+ALTER TABLE `tag` ADD COLUMN `tenant_id` bigint NOT NULL DEFAULT 0 AFTER `deleted_at`,
+  DROP INDEX `name`,
+  ADD UNIQUE INDEX `uk_tag_name` (`tenant_id`, `name`);
+ALTER TABLE `comment` ADD COLUMN `tenant_id` bigint NOT NULL DEFAULT 0 AFTER `deleted_at`,
+  ADD INDEX `idx_comment_tenant_id` (`tenant_id`);
@@ -0,0 +1,2 @@
+ALTER TABLE `article` DROP COLUMN `created_by`, DROP COLUMN `updated_by`;
+ALTER TABLE `tag` DROP COLUMN `created_by`, DROP COLUMN `updated_by`;
@@ -0,0 +1,4 @@
+ALTER TABLE `article` ADD COLUMN `created_by` bigint NOT NULL DEFAULT 0 AFTER `deleted_at`,
+  ADD COLUMN `updated_by` bigint NOT NULL DEFAULT 0 AFTER `created_by`;
+ALTER TABLE `tag` ADD COLUMN `created_by` bigint NOT NULL DEFAULT 0 AFTER `deleted_at`,
+  ADD COLUMN `updated_by` bigint NOT NULL DEFAULT 0 AFTER `created_by`;
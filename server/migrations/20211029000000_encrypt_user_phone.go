@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"fmt"
+	"go-skeleton/model"
+	"go-skeleton/pkg/migrate"
+	"go-skeleton/utils"
+
+	"gorm.io/gorm"
+)
+
+// 手机号加密保存，密文比明文长，回滚时先解密再缩短列，否则密文会被截断且无法恢复
+func init() {
+	migrate.Register("20211029000000_encrypt_user_phone", func(tx *gorm.DB) error {
+		return tx.Exec("ALTER TABLE `user` MODIFY COLUMN `phone` varchar(255) NOT NULL DEFAULT ''").Error
+	}, func(tx *gorm.DB) error {
+		if err := decryptUserPhone(tx); err != nil {
+			return err
+		}
+		return tx.Exec("ALTER TABLE `user` MODIFY COLUMN `phone` varchar(20) NOT NULL DEFAULT ''").Error
+	})
+}
+
+// decryptUserPhone 将加密的手机号还原为明文，没有配置密钥或解密失败时返回错误，不修改列
+func decryptUserPhone(tx *gorm.DB) error {
+	var lastId int64
+	for {
+		var rows []struct {
+			Id    int64
+			Phone string
+		}
+		err := tx.Table("user").Select("id, phone").Where("id > ? AND phone <> ''", lastId).
+			Order("id ASC").Limit(500).Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		for _, row := range rows {
+			phone := model.EncryptedString(row.Phone)
+			_, encrypted := utils.KeyId(row.Phone)
+			if encrypted {
+				if err := phone.Scan(row.Phone); err != nil {
+					return fmt.Errorf("decrypt user %d phone: %w", row.Id, err)
+				}
+			}
+			if len(phone) > 20 {
+				return fmt.Errorf("user %d phone is longer than 20 characters", row.Id)
+			}
+			if !encrypted {
+				continue
+			}
+			if err := tx.Table("user").Where("id = ?", row.Id).UpdateColumn("phone", phone.String()).Error; err != nil {
+				return err
+			}
+		}
+		lastId = rows[len(rows)-1].Id
+	}
+}
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `tenant_user` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `tenant_id` bigint NOT NULL,
+  `user_id` bigint NOT NULL,
+  `created_at` bigint,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uk_tenant_user` (`tenant_id`, `user_id`),
+  INDEX `idx_tenant_user_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+INSERT IGNORE INTO `tenant_user` (`tenant_id`, `user_id`, `created_at`)
+  SELECT DISTINCT `tenant_id`, `user_id`, UNIX_TIMESTAMP() FROM `article` WHERE `tenant_id` > 0;
+INSERT IGNORE INTO `tenant_user` (`tenant_id`, `user_id`, `created_at`)
+  SELECT DISTINCT `tenant_id`, `user_id`, UNIX_TIMESTAMP() FROM `comment` WHERE `tenant_id` > 0;
@@ -0,0 +1,16 @@
+package migrations
+
+import (
+	"embed"
+	"go-skeleton/pkg/migrate"
+)
+
+// SQL迁移文件，通过 ./go-skeleton migrate create {name} 生成，文件编译时嵌入，新增后需要重新编译
+// Go实现的迁移在本包内的init中调用migrate.Register注册
+
+//go:embed *.sql
+var files embed.FS
+
+func init() {
+	migrate.RegisterFS(files)
+}
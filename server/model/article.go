@@ -2,41 +2,79 @@ package model
 
 type Article struct {
 	Model
-	Title        string   `gorm:"column:title;type:varchar(100);not null" json:"title"`
-	Cid          uint64   `gorm:"index:fk_article_category;column:cid;type:bigint(20) unsigned;not null" json:"cid"`
-	Desc         string   `gorm:"column:desc;type:varchar(200)" json:"desc"`
-	Content      string   `gorm:"column:content;type:longtext" json:"content"`
-	Img          string   `gorm:"column:img;type:varchar(100)" json:"img"`
-	CommentCount int64    `gorm:"column:comment_count;type:bigint(20);not null;default:0" json:"comment_count"`
-	ReadCount    int64    `gorm:"column:read_count;type:bigint(20);not null;default:0" json:"read_count"`
-	Category     Category `gorm:"foreignKey:Cid" json:"category"`
+	Operator
+	TenantId      int64    `gorm:"uniqueIndex:uk_article_slug,priority:1;column:tenant_id;not null;default:0" json:"tenant_id"` // 所属租户，0为未开启多租户
+	Title         string   `gorm:"column:title;type:varchar(100);not null" json:"title"`
+	Cid           uint64   `gorm:"index:fk_article_category;column:cid;type:bigint(20) unsigned;not null" json:"cid"`
+	Desc          string   `gorm:"column:desc;type:varchar(200)" json:"desc"`
+	Content       string   `gorm:"column:content;type:longtext" json:"content"`
+	Img           string   `gorm:"column:img;type:varchar(100)" json:"img"`
+	CommentCount  int64    `gorm:"column:comment_count;type:bigint(20);not null;default:0" json:"comment_count"`
+	ReadCount     int64    `gorm:"column:read_count;type:bigint(20);not null;default:0" json:"read_count"`
+	LikeCount     int64    `gorm:"column:like_count;not null;default:0" json:"like_count"`
+	FavoriteCount int64    `gorm:"column:favorite_count;not null;default:0" json:"favorite_count"`
+	UserId        int64    `gorm:"index:idx_article_user_id;column:user_id;not null;default:0" json:"user_id"`
+	Slug          string   `gorm:"uniqueIndex:uk_article_slug;column:slug;type:varchar(128)" json:"slug"`
+	Status        int      `gorm:"index:idx_article_status;column:status;not null;default:0" json:"status"`
+	PublishedAt   int64    `gorm:"column:published_at;not null;default:0" json:"published_at"`
+	PublishAt     int64    `gorm:"index:idx_article_publish_at;column:publish_at;not null;default:0" json:"publish_at"`    // 定时发布时间，0为未设置
+	ReviewerId    int64    `gorm:"index:idx_article_reviewer_id;column:reviewer_id;not null;default:0" json:"reviewer_id"` // 审核人，0为未分配
+	RejectReason  string   `gorm:"column:reject_reason;type:varchar(255);not null;default:''" json:"reject_reason"`
+	Version       int64    `gorm:"column:version;not null;default:0" json:"version"` // 乐观锁版本号
+	Category      Category `gorm:"foreignKey:Cid" json:"category"`
 }
 
 // ArticleColumns get sql column name.获取数据库列名
 var ArticleColumns = struct {
-	ID           string
-	CreatedAt    string
-	UpdatedAt    string
-	DeletedAt    string
-	Title        string
-	Cid          string
-	Desc         string
-	Content      string
-	Img          string
-	CommentCount string
-	ReadCount    string
+	ID            string
+	CreatedAt     string
+	CreatedBy     string
+	UpdatedBy     string
+	TenantId      string
+	UpdatedAt     string
+	DeletedAt     string
+	Title         string
+	Cid           string
+	Desc          string
+	Content       string
+	Img           string
+	CommentCount  string
+	ReadCount     string
+	LikeCount     string
+	FavoriteCount string
+	UserId        string
+	Slug          string
+	Status        string
+	PublishedAt   string
+	PublishAt     string
+	ReviewerId    string
+	RejectReason  string
+	Version       string
 }{
-	ID:           "id",
-	CreatedAt:    "created_at",
-	UpdatedAt:    "updated_at",
-	DeletedAt:    "deleted_at",
-	Title:        "title",
-	Cid:          "cid",
-	Desc:         "desc",
-	Content:      "content",
-	Img:          "img",
-	CommentCount: "comment_count",
-	ReadCount:    "read_count",
+	ID:            "id",
+	CreatedAt:     "created_at",
+	CreatedBy:     "created_by",
+	UpdatedBy:     "updated_by",
+	TenantId:      "tenant_id",
+	UpdatedAt:     "updated_at",
+	DeletedAt:     "deleted_at",
+	Title:         "title",
+	Cid:           "cid",
+	Desc:          "desc",
+	Content:       "content",
+	Img:           "img",
+	CommentCount:  "comment_count",
+	ReadCount:     "read_count",
+	LikeCount:     "like_count",
+	FavoriteCount: "favorite_count",
+	UserId:        "user_id",
+	Slug:          "slug",
+	Status:        "status",
+	PublishedAt:   "published_at",
+	PublishAt:     "publish_at",
+	ReviewerId:    "reviewer_id",
+	RejectReason:  "reject_reason",
+	Version:       "version",
 }
 
 // TableName get sql table name.获取数据库表名
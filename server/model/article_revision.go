@@ -0,0 +1,24 @@
+package model
+
+// 文章历史版本，每次保存生成一条，自动保存的草稿Autosave为true
+type ArticleRevision struct {
+	ID           int64  `gorm:"primarykey" json:"id"`
+	ArticleId    int64  `gorm:"index:idx_article_revision_article_id;not null" json:"articleId"`
+	UserId       int64  `gorm:"not null;default:0" json:"userId"`  // 保存人
+	Version      int64  `gorm:"not null;default:0" json:"version"` // 保存后文章的版本号，自动保存时为保存时的版本号
+	Autosave     bool   `gorm:"not null;default:false" json:"autosave"`
+	Title        string `gorm:"type:varchar(100);not null" json:"title"`
+	Cid          uint64 `gorm:"not null;default:0" json:"cid"`
+	Desc         string `gorm:"type:varchar(200)" json:"desc"`
+	Content      string `gorm:"type:longtext" json:"content,omitempty"`
+	Img          string `gorm:"type:varchar(100)" json:"img"`
+	Fields       string `gorm:"size:255;not null;default:''" json:"fields"` // 与上一个版本相比修改的字段，逗号分隔
+	LinesAdded   int    `gorm:"not null;default:0" json:"linesAdded"`       // 内容新增的行数
+	LinesRemoved int    `gorm:"not null;default:0" json:"linesRemoved"`     // 内容删除的行数
+	CreatedAt    int64  `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *ArticleRevision) TableName() string {
+	return "article_revision"
+}
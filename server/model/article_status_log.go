@@ -0,0 +1,18 @@
+package model
+
+// 文章状态变更记录，审核、发布等操作的轨迹
+type ArticleStatusLog struct {
+	ID         int64  `gorm:"primarykey" json:"id"`
+	ArticleId  int64  `gorm:"index:idx_article_status_log_article_id;not null" json:"articleId"`
+	ActorId    int64  `gorm:"not null;default:0" json:"actorId"` // 操作人，0为系统，eg: 定时发布
+	Action     string `gorm:"size:16;not null" json:"action"`    // submit、approve、reject等
+	FromStatus int    `gorm:"not null;default:0" json:"fromStatus"`
+	ToStatus   int    `gorm:"not null;default:0" json:"toStatus"`
+	Remark     string `gorm:"size:255;not null;default:''" json:"remark"` // 驳回原因、分配的审核人等
+	CreatedAt  int64  `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *ArticleStatusLog) TableName() string {
+	return "article_status_log"
+}
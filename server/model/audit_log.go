@@ -0,0 +1,23 @@
+package model
+
+import "encoding/json"
+
+// 审计日志，记录谁在什么时候修改了哪条数据
+type AuditLog struct {
+	ID        int64           `gorm:"primarykey" json:"id"`
+	ActorId   int64           `gorm:"index:idx_audit_log_actor_id;not null;default:0" json:"actorId"` // 操作人，0为系统或未登录
+	Action    string          `gorm:"size:16;not null" json:"action"`                                 // create、update、delete
+	Entity    string          `gorm:"size:64;index:idx_audit_log_entity;not null" json:"entity"`      // 表名
+	EntityId  string          `gorm:"size:64;index:idx_audit_log_entity;not null" json:"entityId"`    // 主键
+	Before    json.RawMessage `gorm:"type:text" json:"before" swaggertype:"object"`                   // 修改前的数据
+	After     json.RawMessage `gorm:"type:text" json:"after" swaggertype:"object"`                    // 修改后的数据
+	Diff      json.RawMessage `gorm:"type:text" json:"diff" swaggertype:"object"`                     // 变更的字段，{"title":["旧","新"]}
+	Ip        string          `gorm:"size:64;not null;default:''" json:"ip"`
+	RequestId string          `gorm:"size:64;not null;default:''" json:"requestId"`
+	CreatedAt int64           `gorm:"index:idx_audit_log_created_at;autoCreateTime" json:"createdAt"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *AuditLog) TableName() string {
+	return "audit_log"
+}
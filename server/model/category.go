@@ -2,8 +2,9 @@ package model
 
 // Category [...]
 type Category struct {
-	ID   uint64 `gorm:"primaryKey" json:"-"`
-	Name string `json:"name"`
+	ID       uint64 `gorm:"primaryKey" json:"-"`
+	TenantId int64  `gorm:"index:idx_category_tenant_id;not null;default:0" json:"-"`
+	Name     string `json:"name"`
 }
 
 // TableName get sql table name.获取数据库表名
@@ -13,9 +14,11 @@ func (m *Category) TableName() string {
 
 // CategoryColumns get sql column name.获取数据库列名
 var CategoryColumns = struct {
-	ID   string
-	Name string
+	ID       string
+	TenantId string
+	Name     string
 }{
-	ID:   "id",
-	Name: "name",
+	ID:       "id",
+	TenantId: "tenant_id",
+	Name:     "name",
 }
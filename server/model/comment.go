@@ -0,0 +1,28 @@
+package model
+
+// 评论，一级评论的RootId和ParentId为0，回复的RootId为所属的一级评论
+type Comment struct {
+	Model
+	TenantId      int64  `gorm:"index:idx_comment_tenant_id;not null;default:0" json:"tenant_id"`
+	ArticleId     int64  `gorm:"index:idx_comment_article_id;not null" json:"article_id"`
+	UserId        int64  `gorm:"index:idx_comment_user_id;not null" json:"user_id"`
+	RootId        int64  `gorm:"index:idx_comment_root_id;not null;default:0" json:"root_id"` // 所属的一级评论
+	ParentId      int64  `gorm:"not null;default:0" json:"parent_id"`                         // 回复的评论
+	ReplyUserId   int64  `gorm:"not null;default:0" json:"reply_user_id"`                     // 被回复的用户
+	Content       string `gorm:"type:text;not null" json:"content"`
+	ReplyCount    int64  `gorm:"not null;default:0" json:"reply_count"` // 一级评论的回复数
+	LikeCount     int64  `gorm:"not null;default:0" json:"like_count"`
+	FavoriteCount int64  `gorm:"not null;default:0" json:"favorite_count"`
+	Status        int    `gorm:"not null;default:0" json:"status"`
+}
+
+// 一级评论及部分回复
+type CommentThread struct {
+	Comment
+	Replies []Comment `json:"replies"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *Comment) TableName() string {
+	return "comment"
+}
@@ -55,12 +55,40 @@ const (
 	StatusPending = 2 // 待审核
 )
 
+// 文章状态，按 草稿→待审核→审核通过/驳回→已发布→已归档 流转，未开启审核时草稿可以直接发布
+const (
+	ArticleStatusDraft         = 0 // 草稿
+	ArticleStatusPublished     = 1 // 已发布
+	ArticleStatusArchived      = 2 // 已归档
+	ArticleStatusPendingReview = 3 // 待审核
+	ArticleStatusApproved      = 4 // 审核通过
+	ArticleStatusRejected      = 5 // 驳回
+)
+
+// 文章状态变更的操作
+const (
+	ArticleActionSubmit   = "submit"
+	ArticleActionWithdraw = "withdraw"
+	ArticleActionAssign   = "assign"
+	ArticleActionApprove  = "approve"
+	ArticleActionReject   = "reject"
+	ArticleActionPublish  = "publish"
+	ArticleActionArchive  = "archive"
+	ArticleActionEdit     = "edit" // 修改审核通过或已发布的文章，需要重新审核
+)
+
 // 用户类型
 const (
 	UserTypeNormal = 0 // 普通用户
 	UserTypeGzh    = 1 // 公众号用户
 )
 
+// 用户状态
+const (
+	UserStatusNormal   = 0 // 正常
+	UserStatusDisabled = 1 // 禁用
+)
+
 // 内容类型
 const (
 	ContentTypeHtml     = "html"
@@ -74,6 +102,19 @@ const (
 	MsgStatusHaveRead = 1 // 消息已读
 )
 
+// 用户对文章、评论的操作
+const (
+	ReactionLike     = "like"     // 点赞
+	ReactionFavorite = "favorite" // 收藏
+)
+
+// 站内通知类型
+const (
+	NotificationCommentReply   = "comment.reply"   // 评论被回复
+	NotificationArticleComment = "article.comment" // 文章收到评论
+	NotificationArticleLiked   = "article.liked"   // 文章被点赞
+)
+
 // 消息类型
 const (
 	MsgTypeTopicComment   = 0 // 收到话题评论
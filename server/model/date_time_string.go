@@ -9,6 +9,8 @@ import (
 
 //carbon的database类型都没有UnmarshalJSON，这里自定义实现
 //2012-08-05 13:14:15
+//
+// Deprecated: 使用Time，零值输出为null且支持修改格式
 type DateTime struct {
 	carbon.Carbon
 }
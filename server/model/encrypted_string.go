@@ -0,0 +1,93 @@
+package model
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"go-skeleton/utils"
+	"sync"
+)
+
+// EncryptedString 数据库中加密保存的字符串，eg: 手机号、身份证号。
+// 写入时使用当前密钥AES-GCM加密，读取时按密文中的密钥id解密，json中为明文。
+// 同样的值每次加密的结果不同，不能作为查询条件；未加密的旧数据原样读取，重新保存时加密
+type EncryptedString string
+
+// ErrNoEncryptKey 没有配置加密密钥
+var ErrNoEncryptKey = errors.New("model: encrypt key not configured")
+
+var (
+	keyringMu sync.RWMutex
+	keyring   *utils.Keyring
+)
+
+// SetKeyring 设置EncryptedString使用的密钥，启动时按配置encrypt设置
+func SetKeyring(k *utils.Keyring) {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	keyring = k
+}
+
+func getKeyring() *utils.Keyring {
+	keyringMu.RLock()
+	defer keyringMu.RUnlock()
+	return keyring
+}
+
+// EncryptKeyId 当前加密使用的密钥id，没有配置密钥时为空
+func EncryptKeyId() string {
+	if k := getKeyring(); k != nil {
+		return k.CurrentKeyId()
+	}
+	return ""
+}
+
+func (s EncryptedString) String() string {
+	return string(s)
+}
+
+// Value 空字符串不加密，保持列的默认值
+func (s EncryptedString) Value() (driver.Value, error) {
+	if s == "" {
+		return "", nil
+	}
+	k := getKeyring()
+	if k == nil {
+		return nil, ErrNoEncryptKey
+	}
+	return k.Encrypt([]byte(s))
+}
+
+func (s *EncryptedString) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case nil:
+		*s = ""
+		return nil
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return fmt.Errorf("model.EncryptedString: unsupported type %T", value)
+	}
+	if _, ok := utils.KeyId(raw); !ok {
+		*s = EncryptedString(raw)
+		return nil
+	}
+	k := getKeyring()
+	if k == nil {
+		return ErrNoEncryptKey
+	}
+	plain, err := k.Decrypt(raw)
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plain)
+	return nil
+}
+
+// GormDataType 密文比明文长，列需要足够的长度，eg: varchar(255)
+func (EncryptedString) GormDataType() string {
+	return "string"
+}
@@ -0,0 +1,19 @@
+package model
+
+// 功能开关，由featureflag包加载，同名时覆盖配置文件中的开关
+type FeatureFlag struct {
+	ID          int64  `gorm:"primarykey" json:"id"`
+	Name        string `gorm:"uniqueIndex:uk_feature_flag_name;size:64;not null" json:"name"`
+	Description string `gorm:"size:255;not null;default:''" json:"description"`
+	Enabled     bool   `gorm:"not null;default:false" json:"enabled"`
+	Percentage  int    `gorm:"not null;default:0" json:"percentage"`      // 灰度比例0~100
+	Users       string `gorm:"type:text" json:"users"`                    // 指定开启的用户id，逗号分隔
+	Roles       string `gorm:"size:255;not null;default:''" json:"roles"` // 指定开启的角色code，逗号分隔
+	CreatedAt   int64  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt   int64  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *FeatureFlag) TableName() string {
+	return "feature_flag"
+}
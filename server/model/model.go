@@ -6,7 +6,21 @@ import (
 
 type Model struct {
 	ID        uint           `gorm:"primarykey" json:"id"`
-	CreatedAt DateTime       `json:"created_at" swaggertype:"primitive,integer"`
-	UpdatedAt DateTime       `json:"updated_at" swaggertype:"primitive,integer"`
+	CreatedAt Time           `json:"created_at" swaggertype:"primitive,string"`
+	UpdatedAt Time           `json:"updated_at" swaggertype:"primitive,string"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at" swaggertype:"primitive,integer"`
 }
+
+// Operator 嵌入后由audit.OperatorPlugin自动记录创建人、最后修改人
+type Operator struct {
+	CreatedBy int64 `gorm:"not null;default:0" json:"createdBy"`
+	UpdatedBy int64 `gorm:"not null;default:0" json:"updatedBy"`
+}
+
+func (o *Operator) SetCreatedBy(id int64) {
+	o.CreatedBy = id
+}
+
+func (o *Operator) SetUpdatedBy(id int64) {
+	o.UpdatedBy = id
+}
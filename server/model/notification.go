@@ -0,0 +1,19 @@
+package model
+
+// 站内通知，ReadAt为0时未读
+type Notification struct {
+	ID        int64  `gorm:"primarykey" json:"id"`
+	UserId    int64  `gorm:"index:idx_notification_user_id;not null" json:"userId"` // 接收人
+	ActorId   int64  `gorm:"not null;default:0" json:"actorId"`                     // 触发通知的用户，0为系统
+	Type      string `gorm:"size:32;not null" json:"type"`                          // eg: comment.reply
+	ArticleId int64  `gorm:"not null;default:0" json:"articleId"`
+	CommentId int64  `gorm:"not null;default:0" json:"commentId"`
+	Content   string `gorm:"size:512;not null;default:''" json:"content"` // 摘要，eg: 回复的内容
+	ReadAt    int64  `gorm:"index:idx_notification_user_id;not null;default:0" json:"readAt"`
+	CreatedAt int64  `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *Notification) TableName() string {
+	return "notification"
+}
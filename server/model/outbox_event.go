@@ -0,0 +1,19 @@
+package model
+
+// 事务发件箱中待发送到消息中间件的事件
+type OutboxEvent struct {
+	ID            int64  `gorm:"primarykey" json:"id"`
+	Topic         string `gorm:"size:128;not null" json:"topic"`
+	Key           string `gorm:"size:128;not null;default:''" json:"key"`
+	Payload       string `gorm:"type:text;not null" json:"payload"` // json编码的事件
+	Attempts      int    `gorm:"not null;default:0" json:"attempts"`
+	LastError     string `gorm:"size:512;not null;default:''" json:"lastError"`
+	NextAttemptAt int64  `gorm:"index:idx_outbox_event_pending,priority:2;not null;default:0" json:"nextAttemptAt"`
+	SentAt        int64  `gorm:"index:idx_outbox_event_pending,priority:1;not null;default:0" json:"sentAt"` // 0为未发送
+	CreatedAt     int64  `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *OutboxEvent) TableName() string {
+	return "outbox_event"
+}
@@ -0,0 +1,50 @@
+package model
+
+// 角色
+type Role struct {
+	Model
+	Name        string `gorm:"size:32;not null" json:"name" form:"name"`
+	Code        string `gorm:"size:32;unique;not null" json:"code" form:"code"`
+	Description string `gorm:"size:255" json:"description" form:"description"`
+}
+
+// 权限，Code格式为 资源:操作，eg: article:write
+type Permission struct {
+	Model
+	Name string `gorm:"size:32;not null" json:"name" form:"name"`
+	Code string `gorm:"size:64;unique;not null" json:"code" form:"code"`
+}
+
+// 角色权限
+type RolePermission struct {
+	ID           uint  `gorm:"primarykey" json:"id"`
+	RoleId       int64 `gorm:"not null;uniqueIndex:idx_role_permission" json:"roleId" form:"roleId"`
+	PermissionId int64 `gorm:"not null;uniqueIndex:idx_role_permission" json:"permissionId" form:"permissionId"`
+}
+
+// 用户角色
+type UserRole struct {
+	ID     uint  `gorm:"primarykey" json:"id"`
+	UserId int64 `gorm:"not null;uniqueIndex:idx_user_role" json:"userId" form:"userId"`
+	RoleId int64 `gorm:"not null;uniqueIndex:idx_user_role" json:"roleId" form:"roleId"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *Role) TableName() string {
+	return "role"
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *Permission) TableName() string {
+	return "permission"
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *RolePermission) TableName() string {
+	return "role_permission"
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *UserRole) TableName() string {
+	return "user_role"
+}
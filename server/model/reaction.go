@@ -0,0 +1,16 @@
+package model
+
+// 用户对文章、评论的点赞和收藏，计数保存在实体表的{type}_count字段中
+type Reaction struct {
+	ID         int64  `gorm:"primarykey" json:"id"`
+	UserId     int64  `gorm:"uniqueIndex:uk_reaction;not null" json:"userId"`
+	EntityType string `gorm:"uniqueIndex:uk_reaction;index:idx_reaction_entity;size:16;not null" json:"entityType"` // article、comment
+	EntityId   int64  `gorm:"uniqueIndex:uk_reaction;index:idx_reaction_entity;not null" json:"entityId"`
+	Type       string `gorm:"uniqueIndex:uk_reaction;size:16;not null" json:"type"` // like、favorite
+	CreatedAt  int64  `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *Reaction) TableName() string {
+	return "reaction"
+}
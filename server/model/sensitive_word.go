@@ -0,0 +1,13 @@
+package model
+
+// 敏感词，修改后由sensitive包定期重新加载
+type SensitiveWord struct {
+	ID        int64  `gorm:"primarykey" json:"id"`
+	Word      string `gorm:"uniqueIndex:uk_sensitive_word;size:64;not null" json:"word"`
+	CreatedAt int64  `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *SensitiveWord) TableName() string {
+	return "sensitive_word"
+}
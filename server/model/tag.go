@@ -3,7 +3,9 @@ package model
 // 标签
 type Tag struct {
 	Model
-	Name        string `gorm:"size:32;unique;not null" json:"name" form:"name"`
+	Operator
+	TenantId    int64  `gorm:"uniqueIndex:uk_tag_name,priority:1;not null;default:0" json:"tenantId"`
+	Name        string `gorm:"uniqueIndex:uk_tag_name;size:32;not null" json:"name" form:"name"`
 	Description string `gorm:"size:1024" json:"description" form:"description"`
 	Status      int    `gorm:"index:idx_tag_status;not null" json:"status" form:"status"`
 	CreateTime  int64  `json:"createTime" form:"createTime"`
@@ -18,3 +20,19 @@ type ArticleTag struct {
 	Status     int64 `gorm:"not null;index:idx_article_tag_status" json:"status" form:"status"` // 状态：正常、删除
 	CreateTime int64 `json:"createTime" form:"createTime"`                                      // 创建时间
 }
+
+// 标签及其文章数
+type TagCount struct {
+	Tag
+	ArticleCount int64 `json:"articleCount"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *Tag) TableName() string {
+	return "tag"
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *ArticleTag) TableName() string {
+	return "article_tag"
+}
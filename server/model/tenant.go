@@ -0,0 +1,29 @@
+package model
+
+// 租户，Code用于子域名和请求头，eg: acme.example.com、X-Tenant: acme
+type Tenant struct {
+	ID        int64  `gorm:"primarykey" json:"id"`
+	Code      string `gorm:"uniqueIndex:uk_tenant_code;size:64;not null" json:"code"`
+	Name      string `gorm:"size:128;not null" json:"name"`
+	Status    int    `gorm:"not null;default:0" json:"status"` // 0正常 1禁用
+	CreatedAt int64  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt int64  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *Tenant) TableName() string {
+	return "tenant"
+}
+
+// 租户的用户，登录用户只能访问所属租户的数据
+type TenantUser struct {
+	ID        int64 `gorm:"primarykey" json:"id"`
+	TenantId  int64 `gorm:"uniqueIndex:uk_tenant_user;not null" json:"tenantId"`
+	UserId    int64 `gorm:"uniqueIndex:uk_tenant_user;index;not null" json:"userId"`
+	CreatedAt int64 `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *TenantUser) TableName() string {
+	return "tenant_user"
+}
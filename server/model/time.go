@@ -0,0 +1,97 @@
+package model
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// TimeFormat Time在json中的格式，启动时可修改，eg: model.TimeFormat = time.RFC3339
+var TimeFormat = "2006-01-02 15:04:05"
+
+// Time json格式为TimeFormat的时间，零值输出为null，数据库中零值保存为NULL
+type Time struct {
+	time.Time
+}
+
+// NewTime 包装time.Time
+func NewTime(t time.Time) Time {
+	return Time{t}
+}
+
+// Now 当前时间
+func Now() Time {
+	return Time{time.Now()}
+}
+
+// String 按TimeFormat格式化，零值为空字符串
+func (t Time) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(TimeFormat)
+}
+
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	b := make([]byte, 0, len(TimeFormat)+2)
+	b = append(b, '"')
+	b = t.Local().AppendFormat(b, TimeFormat)
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON 支持TimeFormat和RFC3339格式，null和空字符串为零值
+func (t *Time) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) || bytes.Equal(b, []byte(`""`)) {
+		*t = Time{}
+		return nil
+	}
+	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
+		return fmt.Errorf("model.Time: invalid json %s", b)
+	}
+	return t.parse(string(b[1 : len(b)-1]))
+}
+
+func (t *Time) parse(s string) error {
+	parsed, err := time.ParseInLocation(TimeFormat, s, time.Local)
+	if err != nil {
+		if parsed, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			return fmt.Errorf("model.Time: %w", err)
+		}
+	}
+	*t = Time{parsed}
+	return nil
+}
+
+// Scan implements the sql.Scanner interface
+func (t *Time) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*t = Time{}
+	case time.Time:
+		*t = Time{v}
+	case []byte:
+		return t.parse(string(v))
+	case string:
+		return t.parse(v)
+	default:
+		return fmt.Errorf("model.Time: cannot scan %T", value)
+	}
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (t Time) Value() (driver.Value, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return t.Time, nil
+}
+
+// GormDataType 数据库中为datetime类型，同时使gorm的autoCreateTime、autoUpdateTime生效
+func (Time) GormDataType() string {
+	return "time"
+}
@@ -1,13 +1,33 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // User [...]
 type User struct {
-	gorm.Model
-	Username string `gorm:"column:username;type:varchar(20);not null" json:"username"`
-	Password string `gorm:"column:password;type:varchar(500);not null" json:"-"`
-	Role     int64  `gorm:"column:role;type:bigint(20);default:2" json:"role"`
+	// 同gorm.Model，展开后swag不需要解析gorm的依赖
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt  `gorm:"index" swaggertype:"string"`
+	Username  string          `gorm:"column:username;type:varchar(20);not null" json:"username"`
+	Password  string          `gorm:"column:password;type:varchar(500);not null" json:"-"`
+	Role      int64           `gorm:"column:role;type:bigint(20);default:2" json:"role"` // 不用于权限判断，角色见user_role
+	Nickname  string          `gorm:"column:nickname;type:varchar(32);not null;default:''" json:"nickname"`
+	Avatar    string          `gorm:"column:avatar;type:varchar(255);not null;default:''" json:"avatar"`
+	Email     string          `gorm:"column:email;type:varchar(128);not null;default:''" json:"email" mask:"email"`
+	Phone     EncryptedString `gorm:"column:phone;type:varchar(255);not null;default:''" json:"phone" mask:"phone"` // 加密保存
+	// 0正常 1禁用
+	Status      int        `gorm:"column:status;type:tinyint(4);not null;default:0" json:"status"`
+	LastLoginAt *time.Time `gorm:"column:last_login_at" json:"lastLoginAt"`
+	LastLoginIp string     `gorm:"column:last_login_ip;type:varchar(64);not null;default:''" json:"-"`
+	// 界面语言，eg: zh, en，为空时按Accept-Language
+	Locale string `gorm:"column:locale;type:varchar(16);not null;default:''" json:"locale"`
+	// 时区，IANA名称，eg: Asia/Shanghai，为空时按X-Timezone请求头
+	Timezone string `gorm:"column:timezone;type:varchar(64);not null;default:''" json:"timezone"`
 }
 
 // TableName get sql table name.获取数据库表名
@@ -17,19 +37,37 @@ func (m *User) TableName() string {
 
 // UserColumns get sql column name.获取数据库列名
 var UserColumns = struct {
-	ID        string
-	CreatedAt string
-	UpdatedAt string
-	DeletedAt string
-	Username  string
-	Password  string
-	Role      string
+	ID          string
+	CreatedAt   string
+	UpdatedAt   string
+	DeletedAt   string
+	Username    string
+	Password    string
+	Role        string
+	Nickname    string
+	Avatar      string
+	Email       string
+	Phone       string
+	Status      string
+	LastLoginAt string
+	LastLoginIp string
+	Locale      string
+	Timezone    string
 }{
-	ID:        "id",
-	CreatedAt: "created_at",
-	UpdatedAt: "updated_at",
-	DeletedAt: "deleted_at",
-	Username:  "username",
-	Password:  "password",
-	Role:      "role",
+	ID:          "id",
+	CreatedAt:   "created_at",
+	UpdatedAt:   "updated_at",
+	DeletedAt:   "deleted_at",
+	Username:    "username",
+	Password:    "password",
+	Role:        "role",
+	Nickname:    "nickname",
+	Avatar:      "avatar",
+	Email:       "email",
+	Phone:       "phone",
+	Status:      "status",
+	LastLoginAt: "last_login_at",
+	LastLoginIp: "last_login_ip",
+	Locale:      "locale",
+	Timezone:    "timezone",
 }
@@ -0,0 +1,17 @@
+package model
+
+// 用户绑定的第三方账号
+type UserOAuth struct {
+	Model
+	UserId   int64  `gorm:"index:idx_user_oauth_user_id;not null" json:"user_id"`
+	Provider string `gorm:"type:varchar(32);uniqueIndex:uk_user_oauth_open_id;not null" json:"provider"`
+	OpenId   string `gorm:"type:varchar(128);uniqueIndex:uk_user_oauth_open_id;not null" json:"-"`
+	UnionId  string `gorm:"type:varchar(128);not null;default:''" json:"-"`
+	Nickname string `gorm:"type:varchar(64);not null;default:''" json:"nickname"`
+	Avatar   string `gorm:"type:varchar(255);not null;default:''" json:"avatar"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *UserOAuth) TableName() string {
+	return "user_oauth"
+}
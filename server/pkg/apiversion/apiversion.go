@@ -0,0 +1,114 @@
+package apiversion
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// API版本，路径中带版本的请求(/api/v2/...)直接匹配对应的路由组，
+// 未带版本的请求(/api/...)按请求头选择版本后改写路径，优先级: X-API-Version > Accept > 默认版本，eg:
+//
+//	X-API-Version: 2
+//	Accept: application/vnd.go-skeleton.v2+json
+//
+// 请求的版本未注册时不改写，返回404
+
+// Header 指定版本的请求头，响应中同样返回实际使用的版本
+const Header = "X-API-Version"
+
+// Accept中的版本，eg: application/vnd.go-skeleton.v2+json
+var acceptVersion = regexp.MustCompile(`application/vnd\.[\w.-]+?\.(v\d+)\+json`)
+
+var (
+	mu       sync.RWMutex
+	versions = make(map[string]map[string]bool)
+)
+
+// Register 注册prefix下的版本，eg: Register("api", "v2")
+func Register(prefix, version string) {
+	mu.Lock()
+	defer mu.Unlock()
+	prefix = strings.Trim(prefix, "/")
+	if versions[prefix] == nil {
+		versions[prefix] = make(map[string]bool)
+	}
+	versions[prefix][Normalize(version)] = true
+}
+
+// Registered prefix下是否注册了version
+func Registered(prefix, version string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return versions[strings.Trim(prefix, "/")][Normalize(version)]
+}
+
+// Normalize 统一版本的格式，eg: 2、V2 => v2
+func Normalize(version string) string {
+	version = strings.ToLower(strings.TrimSpace(version))
+	if version != "" && !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return version
+}
+
+// FromRequest 请求指定的版本，未指定时返回空字符串
+func FromRequest(r *http.Request) string {
+	if v := r.Header.Get(Header); v != "" {
+		return Normalize(v)
+	}
+	if m := acceptVersion.FindStringSubmatch(r.Header.Get("Accept")); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// Unversioned 去掉路径中已注册的版本，eg: /api/v1/article/list => /api/article/list，
+// 限流、跨域等按路径配置的规则使用，规则不需要为每个版本及改写后的路径重复配置
+func Unversioned(path string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	for prefix, registered := range versions {
+		base := "/" + prefix + "/"
+		rest := strings.TrimPrefix(path, base)
+		if rest == path {
+			continue
+		}
+		first, tail := rest, ""
+		if i := strings.Index(rest, "/"); i >= 0 {
+			first, tail = rest[:i], rest[i:]
+		}
+		if registered[first] {
+			return "/" + prefix + tail
+		}
+	}
+	return path
+}
+
+// Negotiate 将prefix下未带版本的请求改写到请求指定的版本或默认版本def
+func Negotiate(next http.Handler, prefix, def string) http.Handler {
+	base := "/" + strings.Trim(prefix, "/") + "/"
+	def = Normalize(def)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rest := strings.TrimPrefix(r.URL.Path, base); rest != r.URL.Path {
+			first := rest
+			if i := strings.Index(rest, "/"); i >= 0 {
+				first = rest[:i]
+			}
+			if !Registered(prefix, first) {
+				version := FromRequest(r)
+				if version == "" {
+					version = def
+				}
+				if Registered(prefix, version) {
+					r.URL.Path = base + version + "/" + rest
+					r.URL.RawPath = ""
+					// 同一路径的响应随请求头变化
+					w.Header().Add("Vary", Header)
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"context"
+	"go-skeleton/model"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// 审计日志：通过gorm回调记录注册模型的新增、修改、删除，eg:
+//
+//	audit.Register(&model.User{}, "password")
+//	simpleDb.DB().Use(audit.Plugin{})
+//
+// 操作人从语句的context中读取，需要db.WithContext(ctx)，ctx由中间件通过WithActor写入，
+// 没有操作人时ActorId为0。通过db.Table("x")、db.Exec执行的sql不会记录
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Actor 操作人
+type Actor struct {
+	Id int64
+	Ip string
+}
+
+type actorKey struct{}
+
+// WithActor 将操作人写入context
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom 读取context中的操作人
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	if ctx == nil {
+		return Actor{}, false
+	}
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+// Writer 审计日志的写入方式，db为产生日志的语句所用的连接，在事务中时为事务
+type Writer interface {
+	Write(db *gorm.DB, logs []model.AuditLog) error
+}
+
+// DbWriter 写入audit_log表，和业务数据在同一事务中，事务回滚时日志也不会保存
+type DbWriter struct{}
+
+func (w DbWriter) Write(db *gorm.DB, logs []model.AuditLog) error {
+	return db.Session(&gorm.Session{NewDB: true}).Create(&logs).Error
+}
+
+// WriterFunc 函数形式的Writer，eg: 投递到消息队列
+type WriterFunc func(db *gorm.DB, logs []model.AuditLog) error
+
+func (f WriterFunc) Write(db *gorm.DB, logs []model.AuditLog) error {
+	return f(db, logs)
+}
+
+var (
+	mu       sync.RWMutex
+	writer   Writer = DbWriter{}
+	entities        = make(map[reflect.Type]map[string]bool)
+)
+
+// SetWriter 替换默认的DbWriter
+func SetWriter(w Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	writer = w
+}
+
+// Register 记录模型的变更，ignore为不记录的列名，eg: 密码
+func Register(model interface{}, ignore ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	columns := make(map[string]bool, len(ignore))
+	for _, column := range ignore {
+		columns[column] = true
+	}
+	entities[indirectType(model)] = columns
+}
+
+// lookup 模型未注册时返回false
+func lookup(typ reflect.Type) (map[string]bool, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	ignore, ok := entities[typ]
+	return ignore, ok
+}
+
+func getWriter() Writer {
+	mu.RLock()
+	defer mu.RUnlock()
+	return writer
+}
+
+func indirectType(v interface{}) reflect.Type {
+	typ := reflect.TypeOf(v)
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	return typ
+}
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Operated 需要记录操作人的模型，嵌入model.Operator即可实现。
+// 新增时填充创建人和修改人，修改时填充修改人，操作人从语句的context中读取，没有操作人时不修改
+type Operated interface {
+	SetCreatedBy(id int64)
+	SetUpdatedBy(id int64)
+}
+
+// 未使用autoCreateTime、autoUpdateTime的旧时间列，按unix秒填充
+const (
+	createTimeColumn = "create_time"
+	updateTimeColumn = "update_time"
+)
+
+var operatedType = reflect.TypeOf((*Operated)(nil)).Elem()
+
+// OperatorPlugin 新增、修改时自动填充操作人和create_time、update_time，
+// 通过UpdateColumn(s)修改的计数等字段不会填充(SkipHooks)
+type OperatorPlugin struct{}
+
+func (p OperatorPlugin) Name() string {
+	return "audit:operator"
+}
+
+func (p OperatorPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("audit:operator_create", fillCreate); err != nil {
+		return err
+	}
+	return cb.Update().Before("gorm:update").Register("audit:operator_update", fillUpdate)
+}
+
+// AsActor 以指定用户作为语句的操作人，用于没有经过AuditActor中间件、但已知当前用户的调用，eg: 前台用户编辑文章
+func AsActor(db *gorm.DB, userId int64) *gorm.DB {
+	ctx := db.Statement.Context
+	actor, _ := ActorFrom(ctx)
+	actor.Id = userId
+	return db.WithContext(WithActor(ctx, actor))
+}
+
+func fillCreate(db *gorm.DB) {
+	sch := db.Statement.Schema
+	if db.Error != nil || sch == nil {
+		return
+	}
+	actor, _ := ActorFrom(db.Statement.Context)
+	operated := actor.Id > 0 && reflect.PtrTo(sch.ModelType).Implements(operatedType)
+	createTime, updateTime := timeField(sch, createTimeColumn), timeField(sch, updateTimeColumn)
+	if !operated && createTime == nil && updateTime == nil {
+		return
+	}
+
+	now := time.Now().Unix()
+	fill := func(rv reflect.Value) {
+		if operated && rv.CanAddr() {
+			if m, ok := rv.Addr().Interface().(Operated); ok {
+				m.SetCreatedBy(actor.Id)
+				m.SetUpdatedBy(actor.Id)
+			}
+		}
+		for _, field := range []*schema.Field{createTime, updateTime} {
+			if field == nil {
+				continue
+			}
+			if _, zero := field.ValueOf(rv); zero {
+				_ = field.Set(rv, now)
+			}
+		}
+	}
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			fill(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		fill(rv)
+	}
+}
+
+func fillUpdate(db *gorm.DB) {
+	sch := db.Statement.Schema
+	if db.Error != nil || sch == nil || db.Statement.SkipHooks {
+		return
+	}
+	if field := timeField(sch, updateTimeColumn); field != nil && !updating(db, field) {
+		db.Statement.SetColumn(field.DBName, time.Now().Unix(), true)
+	}
+	actor, _ := ActorFrom(db.Statement.Context)
+	if actor.Id <= 0 || !reflect.PtrTo(sch.ModelType).Implements(operatedType) {
+		return
+	}
+	if field := sch.LookUpField("updated_by"); field != nil {
+		db.Statement.SetColumn(field.DBName, actor.Id, true)
+	}
+}
+
+// timeField 未由gorm自动填充的int64时间列
+func timeField(sch *schema.Schema, column string) *schema.Field {
+	field := sch.LookUpField(column)
+	if field == nil || field.AutoCreateTime != 0 || field.AutoUpdateTime != 0 || field.FieldType.Kind() != reflect.Int64 {
+		return nil
+	}
+	return field
+}
+
+// updating 语句已指定该列的值，eg: Updates(map)中包含update_time
+func updating(db *gorm.DB, field *schema.Field) bool {
+	if m, ok := db.Statement.Dest.(map[string]interface{}); ok {
+		_, ok1 := m[field.DBName]
+		_, ok2 := m[field.Name]
+		return ok1 || ok2
+	}
+	return false
+}
@@ -0,0 +1,276 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-skeleton/model"
+	"go-skeleton/utils"
+	"reflect"
+	"sort"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	beforeKey = "audit:before"
+	// 单条语句最多记录的行数，批量修改超出的部分不记录
+	maxRows = 500
+)
+
+// 不参与比较的列，只有这些列变化时不记录
+var skipDiff = map[string]bool{"updated_at": true, "update_time": true}
+
+type row = map[string]interface{}
+
+// Plugin 注册到gorm的审计回调，修改和删除前查询受影响的数据，执行后再查询一次并比较
+type Plugin struct{}
+
+func (p Plugin) Name() string {
+	return "audit"
+}
+
+func (p Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("audit:after_create", afterCreate); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("audit:before_update", snapshot); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("audit:after_update", afterUpdate); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("audit:before_delete", snapshot); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Register("audit:after_delete", afterDelete)
+}
+
+// target 语句对应的已注册模型，ignore为不记录的列
+func target(db *gorm.DB) (sch *schema.Schema, ignore map[string]bool, ok bool) {
+	sch = db.Statement.Schema
+	if db.Error != nil || db.DryRun || sch == nil || sch.PrioritizedPrimaryField == nil {
+		return nil, nil, false
+	}
+	ignore, ok = lookup(sch.ModelType)
+	return sch, ignore, ok
+}
+
+// snapshot 修改、删除前保存受影响的数据
+func snapshot(db *gorm.DB) {
+	sch, _, ok := target(db)
+	if !ok {
+		return
+	}
+	exprs := whereExprs(db, sch)
+	if len(exprs) == 0 {
+		return
+	}
+	if field := sch.LookUpField("deleted_at"); field != nil && !db.Statement.Unscoped {
+		exprs = append(exprs, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: nil})
+	}
+	if rows := load(db, sch, exprs); len(rows) > 0 {
+		db.InstanceSet(beforeKey, rows)
+	}
+}
+
+// whereExprs 语句的where条件，db.Save、db.Model(&obj)的主键条件在gorm:update中才会加入，这里手动加上
+func whereExprs(db *gorm.DB, sch *schema.Schema) []clause.Expression {
+	var exprs []clause.Expression
+	if c, ok := db.Statement.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			exprs = append(exprs, where.Exprs...)
+		}
+	}
+	if db.Statement.Model != nil {
+		if rv := reflect.Indirect(reflect.ValueOf(db.Statement.Model)); rv.Kind() == reflect.Struct {
+			if v, zero := sch.PrioritizedPrimaryField.ValueOf(rv); !zero {
+				exprs = append(exprs, pkEq(sch, v))
+			}
+		}
+	}
+	return exprs
+}
+
+func afterCreate(db *gorm.DB) {
+	sch, ignore, ok := target(db)
+	if !ok {
+		return
+	}
+	var ids []interface{}
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if v, zero := sch.PrioritizedPrimaryField.ValueOf(reflect.Indirect(rv.Index(i))); !zero {
+				ids = append(ids, v)
+			}
+		}
+	case reflect.Struct:
+		if v, zero := sch.PrioritizedPrimaryField.ValueOf(rv); !zero {
+			ids = append(ids, v)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	afters := load(db, sch, []clause.Expression{pkIn(sch, ids)})
+	var logs []model.AuditLog
+	for _, id := range sortedKeys(afters) {
+		logs = append(logs, newLog(db, ActionCreate, sch.Table, id, nil, afters[id], nil, ignore))
+	}
+	write(db, logs)
+}
+
+func afterUpdate(db *gorm.DB) {
+	sch, ignore, ok := target(db)
+	befores := takeBefore(db)
+	if !ok || len(befores) == 0 {
+		return
+	}
+	ids := make([]interface{}, 0, len(befores))
+	for _, r := range befores {
+		ids = append(ids, r[sch.PrioritizedPrimaryField.DBName])
+	}
+	afters := load(db, sch, []clause.Expression{pkIn(sch, ids)})
+	var logs []model.AuditLog
+	for _, id := range sortedKeys(befores) {
+		after, ok := afters[id]
+		if !ok {
+			continue
+		}
+		changes := diff(befores[id], after, ignore)
+		if len(changes) == 0 {
+			continue
+		}
+		logs = append(logs, newLog(db, ActionUpdate, sch.Table, id, befores[id], after, changes, ignore))
+	}
+	write(db, logs)
+}
+
+func afterDelete(db *gorm.DB) {
+	sch, ignore, ok := target(db)
+	befores := takeBefore(db)
+	if !ok || len(befores) == 0 {
+		return
+	}
+	var logs []model.AuditLog
+	for _, id := range sortedKeys(befores) {
+		logs = append(logs, newLog(db, ActionDelete, sch.Table, id, befores[id], nil, nil, ignore))
+	}
+	write(db, logs)
+}
+
+func takeBefore(db *gorm.DB) map[string]row {
+	if v, ok := db.InstanceGet(beforeKey); ok {
+		return v.(map[string]row)
+	}
+	return nil
+}
+
+// load 在同一连接(事务)中查询数据，key为主键
+func load(db *gorm.DB, sch *schema.Schema, exprs []clause.Expression) map[string]row {
+	tx := db.Session(&gorm.Session{NewDB: true}).Table(sch.Table)
+	tx.Statement.AddClause(clause.Where{Exprs: exprs})
+	var list []row
+	if err := tx.Limit(maxRows).Find(&list).Error; err != nil {
+		zap.L().Warn("audit: load rows failed", zap.String("table", sch.Table), zap.Error(err))
+		return nil
+	}
+	ret := make(map[string]row, len(list))
+	for _, r := range list {
+		for k, v := range r {
+			if b, ok := v.([]byte); ok {
+				r[k] = string(b)
+			}
+		}
+		ret[fmt.Sprint(r[sch.PrioritizedPrimaryField.DBName])] = r
+	}
+	return ret
+}
+
+func newLog(db *gorm.DB, action, entity, id string, before, after row, changes map[string][2]interface{}, ignore map[string]bool) model.AuditLog {
+	ctx := db.Statement.Context
+	actor, _ := ActorFrom(ctx)
+	log := model.AuditLog{
+		ActorId:  actor.Id,
+		Action:   action,
+		Entity:   entity,
+		EntityId: id,
+		Ip:       actor.Ip,
+	}
+	if ctx != nil {
+		log.RequestId = utils.RequestID(ctx)
+	}
+	if before != nil {
+		log.Before = marshal(clean(before, ignore))
+	}
+	if after != nil {
+		log.After = marshal(clean(after, ignore))
+	}
+	if changes != nil {
+		log.Diff = marshal(changes)
+	}
+	return log
+}
+
+func write(db *gorm.DB, logs []model.AuditLog) {
+	if len(logs) == 0 {
+		return
+	}
+	if err := getWriter().Write(db, logs); err != nil {
+		zap.L().Error("audit: write logs failed", zap.String("entity", logs[0].Entity), zap.Error(err))
+	}
+}
+
+// diff 比较前后的数据，返回变化的列及其新旧值
+func diff(before, after row, ignore map[string]bool) map[string][2]interface{} {
+	ret := make(map[string][2]interface{})
+	for column, v := range after {
+		if ignore[column] || skipDiff[column] {
+			continue
+		}
+		if old := before[column]; !reflect.DeepEqual(old, v) {
+			ret[column] = [2]interface{}{old, v}
+		}
+	}
+	return ret
+}
+
+func clean(r row, ignore map[string]bool) row {
+	ret := make(row, len(r))
+	for k, v := range r {
+		if !ignore[k] {
+			ret[k] = v
+		}
+	}
+	return ret
+}
+
+func marshal(v interface{}) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func pkEq(sch *schema.Schema, v interface{}) clause.Expression {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: sch.PrioritizedPrimaryField.DBName}, Value: v}
+}
+
+func pkIn(sch *schema.Schema, ids []interface{}) clause.Expression {
+	return clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: sch.PrioritizedPrimaryField.DBName}, Values: ids}
+}
+
+func sortedKeys(rows map[string]row) []string {
+	keys := make([]string, 0, len(rows))
+	for k := range rows {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,45 +1,212 @@
 package auth
 
 import (
+	"context"
+	"crypto/rsa"
+	"fmt"
 	"go-skeleton/pkg/config"
 	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/utils"
+	"io/ioutil"
+	"sync"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/golang-module/carbon"
+	"go.uber.org/zap"
 )
 
-var JwtKey = []byte(config.Conf.AppConfig.JwtKey)
+// token类型
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+)
+
+// 默认有效期
+const (
+	defaultAccessTTL  = 10 * time.Minute
+	defaultRefreshTTL = 30 * 24 * time.Hour
+)
 
 type MyClaims struct {
-	Id int `json:"id"`
+	Id   int    `json:"id"`
+	Type string `json:"typ,omitempty"`
 	jwt.StandardClaims
 }
 
-//生成jwt token 有效期10分钟
-func GenerateToken(id int) (string, error) {
-	expireTime := carbon.Now().AddMinutes(10).ToTimestamp()
+// TokenPair 登录、刷新时返回的token
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresAt    int64  `json:"expiresAt"`
+}
+
+// 签名和验证用的密钥，首次使用时根据配置加载
+var (
+	keyOnce   sync.Once
+	keyErr    error
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+)
+
+func loadKeys() error {
+	keyOnce.Do(func() {
+		cfg := config.Conf.JwtConfig
+		switch cfg.Alg {
+		case "", "HS256":
+			method = jwt.SigningMethodHS256
+			signKey = []byte(config.Conf.AppConfig.JwtKey)
+			verifyKey = signKey
+		case "RS256":
+			method = jwt.SigningMethodRS256
+			var private *rsa.PrivateKey
+			var public *rsa.PublicKey
+			if private, keyErr = readPrivateKey(cfg.PrivateKey); keyErr != nil {
+				return
+			}
+			if public, keyErr = readPublicKey(cfg.PublicKey); keyErr != nil {
+				return
+			}
+			signKey, verifyKey = private, public
+		default:
+			keyErr = fmt.Errorf("unsupported jwt alg: %s", cfg.Alg)
+		}
+	})
+	return keyErr
+}
+
+func readPrivateKey(file string) (*rsa.PrivateKey, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.ParseRSAPrivateKeyFromPEM(b)
+}
+
+func readPublicKey(file string) (*rsa.PublicKey, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.ParseRSAPublicKeyFromPEM(b)
+}
+
+func ttl(typ string) time.Duration {
+	cfg := config.Conf.JwtConfig
+	if typ == RefreshToken {
+		if cfg.RefreshTTL > 0 {
+			return cfg.RefreshTTL
+		}
+		return defaultRefreshTTL
+	}
+	if cfg.AccessTTL > 0 {
+		return cfg.AccessTTL
+	}
+	return defaultAccessTTL
+}
+
+func issuer() string {
+	if config.Conf.JwtConfig.Issuer != "" {
+		return config.Conf.JwtConfig.Issuer
+	}
+	return "gin_test"
+}
+
+// newToken 签发指定类型的token
+func newToken(id int, typ string) (string, int64, error) {
+	if err := loadKeys(); err != nil {
+		return "", 0, err
+	}
+	now := carbon.Now().ToTimestamp()
+	expireTime := now + int64(ttl(typ)/time.Second)
 
 	SetClaims := MyClaims{
-		Id: id,
+		Id:   id,
+		Type: typ,
 		StandardClaims: jwt.StandardClaims{
+			Id:        utils.UUID(),
+			IssuedAt:  now,
 			ExpiresAt: expireTime,
-			Issuer:    "gin_test",
+			Issuer:    issuer(),
 		},
 	}
-	reqClaim := jwt.NewWithClaims(jwt.SigningMethodHS256, SetClaims)
-	token, err := reqClaim.SignedString(JwtKey)
+	reqClaim := jwt.NewWithClaims(method, SetClaims)
+	token, err := reqClaim.SignedString(signKey)
+	if err != nil {
+		return "", 0, err
+	}
+	return token, expireTime, nil
+}
+
+//生成jwt token，默认有效期10分钟
+func GenerateToken(id int) (string, error) {
+	token, _, err := newToken(id, AccessToken)
+	return token, err
+}
+
+// GenerateTokenPair 生成access token和refresh token
+func GenerateTokenPair(id int) (*TokenPair, error) {
+	token, expiresAt, err := newToken(id, AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, _, err := newToken(id, RefreshToken)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return token, nil
+	return &TokenPair{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+	}, nil
 }
 
-// 验证token
+// 验证access token
 func CheckToken(token string) (*MyClaims, *errors.CodeError) {
+	return parseToken(token, AccessToken)
+}
+
+// Refresh 使用refresh token换取新的token，旧的refresh token会被吊销。
+// 吊销和检查是一个原子操作，同一个refresh token并发刷新时只有一个请求能换到新的token
+func Refresh(refreshToken string) (*TokenPair, *errors.CodeError) {
+	claims, cerr := parseToken(refreshToken, RefreshToken)
+	if cerr == errors.InternalError {
+		return nil, cerr
+	}
+	if cerr != nil {
+		return nil, errors.RefreshTokenError
+	}
+	ok, err := claim(claims)
+	if err != nil {
+		zap.L().Error("claim refresh token failed", zap.String("jti", claims.StandardClaims.Id), zap.Error(err))
+		return nil, errors.InternalError
+	}
+	if !ok {
+		return nil, errors.TokenRevokedError
+	}
+	pair, err := GenerateTokenPair(claims.Id)
+	if err != nil {
+		zap.L().Error("generate token pair failed", zap.Int("uid", claims.Id), zap.Error(err))
+		return nil, errors.InternalError
+	}
+	return pair, nil
+}
+
+func parseToken(token string, typ string) (*MyClaims, *errors.CodeError) {
+	// 密钥加载失败不是令牌的问题，错误信息中有文件路径，只记录日志
+	if err := loadKeys(); err != nil {
+		zap.L().Error("load jwt keys failed", zap.Error(err))
+		return nil, errors.InternalError
+	}
 	var claims MyClaims
 
 	claimsToken, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
-		return JwtKey, nil
+		if token.Method.Alg() != method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %s", token.Method.Alg())
+		}
+		return verifyKey, nil
 	})
 
 	if err != nil {
@@ -61,9 +228,77 @@ func CheckToken(token string) (*MyClaims, *errors.CodeError) {
 		return nil, errors.TokenWrongError
 	}
 
-	if claim, ok := claimsToken.Claims.(*MyClaims); ok && claimsToken.Valid {
-		return claim, nil
-	} else {
+	claim, ok := claimsToken.Claims.(*MyClaims)
+	if !ok || !claimsToken.Valid {
 		return nil, errors.TokenWrongError
 	}
+	// 兼容没有typ的旧token，视为access token
+	if claim.Type != typ && !(claim.Type == "" && typ == AccessToken) {
+		return nil, errors.TokenTypeWrongError
+	}
+	if IsRevoked(claim) {
+		return nil, errors.TokenRevokedError
+	}
+	return claim, nil
+}
+
+func revokedKey(jti string) string {
+	return "jwt:revoked:" + jti
+}
+
+// Revoke 吊销token，加入redis黑名单直到token过期
+func Revoke(claims *MyClaims) error {
+	rdb := gredis.GetRedis()
+	if rdb == nil || claims.StandardClaims.Id == "" {
+		return nil
+	}
+	expiration := time.Until(time.Unix(claims.ExpiresAt, 0))
+	if expiration <= 0 {
+		return nil
+	}
+	return rdb.Set(context.TODO(), revokedKey(claims.StandardClaims.Id), 1, expiration).Err()
+}
+
+// claim 通过SETNX吊销token，返回false表示token已被吊销或没有jti
+func claim(claims *MyClaims) (bool, error) {
+	if claims.StandardClaims.Id == "" {
+		return false, nil
+	}
+	rdb := gredis.GetRedis()
+	if rdb == nil {
+		return true, nil
+	}
+	expiration := time.Until(time.Unix(claims.ExpiresAt, 0))
+	if expiration <= 0 {
+		return false, nil
+	}
+	return rdb.SetNX(context.TODO(), revokedKey(claims.StandardClaims.Id), 1, expiration).Result()
+}
+
+// RevokeToken 吊销token字符串，token无效时忽略
+func RevokeToken(token string) error {
+	if err := loadKeys(); err != nil {
+		return err
+	}
+	var claims MyClaims
+	if _, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		return verifyKey, nil
+	}); err != nil {
+		return nil
+	}
+	return Revoke(&claims)
+}
+
+// IsRevoked token是否已被吊销，读取redis出错时视为已吊销
+func IsRevoked(claims *MyClaims) bool {
+	rdb := gredis.GetRedis()
+	if rdb == nil || claims.StandardClaims.Id == "" {
+		return false
+	}
+	n, err := rdb.Exists(context.TODO(), revokedKey(claims.StandardClaims.Id)).Result()
+	if err != nil {
+		zap.L().Warn("check revoked token failed", zap.String("jti", claims.StandardClaims.Id), zap.Error(err))
+		return true
+	}
+	return n > 0
 }
@@ -0,0 +1,95 @@
+package bind
+
+import (
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/i18n"
+	"go-skeleton/pkg/resp"
+	"go-skeleton/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// Validator 绑定后执行的校验，使用valid tag，gin的binding tag在绑定时校验
+var Validator = utils.NewValidator()
+
+// 调试日志中请求体的最大长度
+const maxLogBody = 4096
+
+// JSON 绑定json请求体并校验，失败时已输出错误信息，调用方直接返回即可，eg:
+//	var req createReq
+//	if !bind.JSON(c, &req) {
+//		return
+//	}
+func JSON(c *gin.Context, req interface{}) bool {
+	return With(c, req, binding.JSON)
+}
+
+// Query 绑定url参数并校验
+func Query(c *gin.Context, req interface{}) bool {
+	return With(c, req, binding.Query)
+}
+
+// Form 绑定表单并校验
+func Form(c *gin.Context, req interface{}) bool {
+	return With(c, req, binding.Form)
+}
+
+// With 使用指定的binding绑定并校验，格式错误时输出400，校验失败时输出422及每个字段的错误信息
+func With(c *gin.Context, req interface{}, b binding.Binding) bool {
+	logBody(c)
+
+	if err := c.ShouldBindWith(req, b); err != nil {
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			abort(c, translate(errs, i18n.LocaleFrom(c)))
+			return false
+		}
+		resp.Abort(c, http.StatusBadRequest, errors.NewErrorData(errors.InvalidParamsError.Code, errors.InvalidParamsError.Message, err.Error()))
+		return false
+	}
+	fields, err := Validator.ValidateFieldsLocale(req, i18n.LocaleFrom(c))
+	if err != nil {
+		resp.Abort(c, http.StatusBadRequest, errors.NewErrorData(errors.InvalidParamsError.Code, errors.InvalidParamsError.Message, err.Error()))
+		return false
+	}
+	if len(fields) > 0 {
+		abort(c, fields)
+		return false
+	}
+	return true
+}
+
+func abort(c *gin.Context, fields map[string]string) {
+	resp.Abort(c, http.StatusUnprocessableEntity, errors.NewErrorData(errors.ValidationError.Code, errors.ValidationError.Message, fields))
+}
+
+// translate 按语言翻译gin绑定时的校验错误，未初始化翻译器时使用原始错误信息
+func translate(errs validator.ValidationErrors, locale string) map[string]string {
+	if trans := utils.GetTrans(locale); trans != nil {
+		return utils.RemoveTopStruct(errs.Translate(trans))
+	}
+	fields := make(map[string]string, len(errs))
+	for _, e := range errs {
+		fields[e.Field()] = e.(error).Error()
+	}
+	return fields
+}
+
+// logBody debug级别时记录原始请求体，读取后恢复请求体供绑定使用
+func logBody(c *gin.Context) {
+	lg := utils.Logger(c)
+	if c.Request.Body == nil || c.Request.Body == http.NoBody || !lg.Core().Enabled(zap.DebugLevel) {
+		return
+	}
+	body, err := utils.ReadBody(c)
+	if err != nil {
+		return
+	}
+	if len(body) > maxLogBody {
+		body = body[:maxLogBody]
+	}
+	lg.Debug("bind request body", zap.String("path", c.Request.URL.Path), zap.ByteString("body", body))
+}
@@ -0,0 +1,274 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/eventbus"
+	"go-skeleton/utils"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 消息中间件(kafka、rabbitmq)，用于和其他服务交换领域事件，eg:
+//
+//	broker.Subscribe("article.published", "search", func(ctx context.Context, msg *broker.Message) error {
+//		var e eventbus.ArticlePublished
+//		if err := msg.Bind(&e); err != nil {
+//			return fmt.Errorf("%w: %v", broker.ErrSkipRetry, err)
+//		}
+//		return index(ctx, e.ArticleId)
+//	})
+//	broker.Publish(ctx, "article.published", "1", event)
+//
+// Subscribe需要在Init之前调用，Init后开始消费，同一group的多个实例分摊消息
+
+const (
+	HeaderContentType = "content-type"
+	HeaderRequestID   = "x-request-id"
+)
+
+// Message 消息
+type Message struct {
+	Topic string
+	// 分区键，kafka中相同key的消息发送到同一分区，保证顺序
+	Key     string
+	Body    []byte
+	Headers map[string]string
+}
+
+// Bind 按content-type头解码消息体
+func (m *Message) Bind(v interface{}) error {
+	return codecByContentType(m.Headers[HeaderContentType]).Unmarshal(m.Body, v)
+}
+
+// Handler 消息处理方法，返回错误时按broker.HandleRetry重试
+type Handler func(ctx context.Context, msg *Message) error
+
+// Driver 消息中间件的实现
+type Driver interface {
+	// Publish 发送消息，返回nil时消息已被服务端确认
+	Publish(ctx context.Context, msg *Message) error
+	// Consume 以group消费topic，直到ctx取消或连接断开，handler返回错误的消息不会重复投递
+	Consume(ctx context.Context, topic, group string, handler Handler) error
+	Close() error
+}
+
+var (
+	// ErrDisabled 没有配置broker.Driver
+	ErrDisabled = errors.New("broker: disabled")
+	// ErrSkipRetry 处理方法返回此错误(或包装了此错误)时不再重试
+	ErrSkipRetry = errors.New("broker: skip retry")
+)
+
+var drivers = map[string]func(cfg config.BrokerConfig) (Driver, error){
+	"kafka":    newKafka,
+	"rabbitmq": newRabbitMQ,
+}
+
+type subscription struct {
+	topic   string
+	group   string
+	handler Handler
+}
+
+var (
+	mu      sync.RWMutex
+	driver  Driver
+	codec   Codec = JSONCodec{}
+	subs    []subscription
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+)
+
+// Subscribe 注册消费者，Init时启动
+func Subscribe(topic, group string, handler Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+	subs = append(subs, subscription{topic: topic, group: group, handler: handler})
+}
+
+// Init 按配置连接消息中间件并启动已注册的消费者，Driver为空时不启用
+func Init() error {
+	cfg := config.Conf.BrokerConfig
+	if cfg.Driver == "" {
+		return nil
+	}
+	newDriver, ok := drivers[cfg.Driver]
+	if !ok {
+		return fmt.Errorf("broker: unknown driver %q", cfg.Driver)
+	}
+	c := Codec(JSONCodec{})
+	if cfg.Codec != "" {
+		if c, ok = codecs[cfg.Codec]; !ok {
+			return fmt.Errorf("broker: unknown codec %q", cfg.Codec)
+		}
+	}
+	d, err := newDriver(cfg)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	driver, codec = d, c
+	var ctx context.Context
+	ctx, cancel = context.WithCancel(context.Background())
+	for _, s := range subs {
+		running.Add(1)
+		go consume(ctx, d, s, cfg)
+	}
+	return nil
+}
+
+// Shutdown 停止消费，等待处理中的消息完成后关闭连接
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	d := driver
+	driver = nil
+	if cancel != nil {
+		cancel()
+	}
+	mu.Unlock()
+	if d == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	return d.Close()
+}
+
+// Publish 使用配置的Codec编码v并发送，失败时按broker.MaxRetry重试
+func Publish(ctx context.Context, topic, key string, v interface{}) error {
+	mu.RLock()
+	c := codec
+	mu.RUnlock()
+	return publish(ctx, c, topic, key, v)
+}
+
+// PublishJSON 使用json编码发送，不使用配置的Codec，用于领域事件
+func PublishJSON(ctx context.Context, topic, key string, v interface{}) error {
+	return publish(ctx, JSONCodec{}, topic, key, v)
+}
+
+func publish(ctx context.Context, c Codec, topic, key string, v interface{}) error {
+	mu.RLock()
+	d := driver
+	mu.RUnlock()
+	if d == nil {
+		return ErrDisabled
+	}
+	body, err := c.Marshal(v)
+	if err != nil {
+		return err
+	}
+	msg := &Message{
+		Topic:   topic,
+		Key:     key,
+		Body:    body,
+		Headers: map[string]string{HeaderContentType: c.ContentType()},
+	}
+	if id := utils.RequestID(ctx); id != "" {
+		msg.Headers[HeaderRequestID] = id
+	}
+
+	cfg := config.Conf.BrokerConfig
+	for i := 0; ; i++ {
+		if err = d.Publish(ctx, msg); err == nil || i >= cfg.MaxRetry {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(cfg.RetryBackoff * time.Duration(i+1)):
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("broker: publish %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Forward 将领域事件通过任务队列转发到消息中间件，topic为事件名，消息使用json编码，
+// 只转发broker.Events中配置的事件
+func Forward(events ...eventbus.Event) {
+	cfg := config.Conf.BrokerConfig
+	if cfg.Driver == "" {
+		return
+	}
+	enabled := make(map[string]bool, len(cfg.Events))
+	for _, name := range cfg.Events {
+		enabled[name] = true
+	}
+	for _, e := range events {
+		if !enabled[e.EventName()] {
+			continue
+		}
+		eventbus.SubscribeAsync(e, "broker", func(ctx context.Context, event eventbus.Event) error {
+			return PublishJSON(ctx, event.EventName(), "", event)
+		})
+	}
+}
+
+// consume 消费者异常退出后间隔一段时间重新连接
+func consume(ctx context.Context, d Driver, s subscription, cfg config.BrokerConfig) {
+	defer running.Done()
+	handler := withRetry(s, cfg.HandleRetry, cfg.RetryBackoff)
+	for {
+		err := d.Consume(ctx, s.topic, s.group, handler)
+		if ctx.Err() != nil {
+			return
+		}
+		zap.L().Error("broker: consumer stopped, restarting", zap.String("topic", s.topic),
+			zap.String("group", s.group), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// withRetry 处理失败时重试，仍然失败时记录日志，由driver跳过或拒绝消息
+func withRetry(s subscription, retry int, backoff time.Duration) Handler {
+	return func(ctx context.Context, msg *Message) (err error) {
+		for i := 0; ; i++ {
+			if err = safeHandle(s.handler, ctx, msg); err == nil {
+				return nil
+			}
+			if errors.Is(err, ErrSkipRetry) || i >= retry || ctx.Err() != nil {
+				break
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(backoff * time.Duration(i+1)):
+			}
+		}
+		zap.L().Error("broker: handle message failed", zap.String("topic", msg.Topic), zap.String("group", s.group),
+			zap.String("key", msg.Key), zap.String("request_id", msg.Headers[HeaderRequestID]), zap.Error(err))
+		return err
+	}
+}
+
+func safeHandle(handler Handler, ctx context.Context, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: panic: %v", ErrSkipRetry, r)
+		}
+	}()
+	if id := msg.Headers[HeaderRequestID]; id != "" {
+		ctx = utils.WithRequestID(ctx, id)
+	}
+	return handler(ctx, msg)
+}
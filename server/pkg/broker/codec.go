@@ -0,0 +1,67 @@
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec 消息体的编码方式，发送时写入content-type头，消费时按头选择解码方式
+type Codec interface {
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var codecs = map[string]Codec{
+	"json":     JSONCodec{},
+	"protobuf": ProtoCodec{},
+}
+
+// codecByContentType 没有content-type时按json处理
+func codecByContentType(contentType string) Codec {
+	for _, c := range codecs {
+		if c.ContentType() == contentType {
+			return c
+		}
+	}
+	return JSONCodec{}
+}
+
+type JSONCodec struct{}
+
+func (JSONCodec) ContentType() string {
+	return "application/json"
+}
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// ProtoCodec v需要是protoc生成的消息
+type ProtoCodec struct{}
+
+func (ProtoCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (ProtoCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("broker: %T is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (ProtoCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("broker: %T is not a proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
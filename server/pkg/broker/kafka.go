@@ -0,0 +1,77 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"go-skeleton/pkg/config"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type kafkaDriver struct {
+	cfg    config.KafkaConfig
+	writer *kafka.Writer
+}
+
+func newKafka(cfg config.BrokerConfig) (Driver, error) {
+	if len(cfg.Kafka.Brokers) == 0 {
+		return nil, errors.New("broker: kafka brokers not configured")
+	}
+	return &kafkaDriver{
+		cfg: cfg.Kafka,
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(cfg.Kafka.Brokers...),
+			Balancer: &kafka.Hash{},
+			// 所有副本确认后才返回，重试由Publish处理
+			RequiredAcks: kafka.RequireAll,
+			MaxAttempts:  1,
+			BatchTimeout: 10 * time.Millisecond,
+		},
+	}, nil
+}
+
+func (k *kafkaDriver) topic(topic string) string {
+	return k.cfg.TopicPrefix + topic
+}
+
+func (k *kafkaDriver) Publish(ctx context.Context, msg *Message) error {
+	headers := make([]kafka.Header, 0, len(msg.Headers))
+	for key, value := range msg.Headers {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   k.topic(msg.Topic),
+		Key:     []byte(msg.Key),
+		Value:   msg.Body,
+		Headers: headers,
+	})
+}
+
+// Consume 处理完成(包括重试后仍失败)后提交位点
+func (k *kafkaDriver) Consume(ctx context.Context, topic, group string, handler Handler) error {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: k.cfg.Brokers,
+		GroupID: group,
+		Topic:   k.topic(topic),
+	})
+	defer reader.Close()
+	for {
+		m, err := reader.FetchMessage(ctx)
+		if err != nil {
+			return err
+		}
+		msg := &Message{Topic: topic, Key: string(m.Key), Body: m.Value, Headers: make(map[string]string, len(m.Headers))}
+		for _, h := range m.Headers {
+			msg.Headers[h.Key] = string(h.Value)
+		}
+		_ = handler(ctx, msg)
+		if err := reader.CommitMessages(ctx, m); err != nil {
+			return err
+		}
+	}
+}
+
+func (k *kafkaDriver) Close() error {
+	return k.writer.Close()
+}
@@ -0,0 +1,180 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// rabbitDriver 消息发送到topic类型的交换机，routing key为topic，
+// 每个group声明一个队列{group}.{topic}绑定到交换机，同一group的消费者竞争消费
+type rabbitDriver struct {
+	cfg config.RabbitMQConfig
+
+	mu       sync.Mutex
+	conn     *amqp.Connection
+	ch       *amqp.Channel
+	confirms chan amqp.Confirmation
+}
+
+func newRabbitMQ(cfg config.BrokerConfig) (Driver, error) {
+	if cfg.RabbitMQ.Exchange == "" {
+		return nil, errors.New("broker: rabbitmq exchange not configured")
+	}
+	r := &rabbitDriver{cfg: cfg.RabbitMQ}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.connect(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// connect 建立连接和开启确认模式的发送通道，需要持有锁
+func (r *rabbitDriver) connect() error {
+	if r.conn == nil || r.conn.IsClosed() {
+		conn, err := amqp.Dial(r.cfg.Url)
+		if err != nil {
+			return err
+		}
+		r.conn = conn
+	}
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return err
+	}
+	if err := ch.ExchangeDeclare(r.cfg.Exchange, amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+		_ = ch.Close()
+		return err
+	}
+	if err := ch.Confirm(false); err != nil {
+		_ = ch.Close()
+		return err
+	}
+	r.ch = ch
+	r.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	return nil
+}
+
+// Publish 等待服务端确认，通道异常时重新连接
+func (r *rabbitDriver) Publish(ctx context.Context, msg *Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ch == nil {
+		if err := r.connect(); err != nil {
+			return err
+		}
+	}
+	headers := make(amqp.Table, len(msg.Headers))
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	err := r.ch.Publish(r.cfg.Exchange, msg.Topic, false, false, amqp.Publishing{
+		Headers:      headers,
+		ContentType:  msg.Headers[HeaderContentType],
+		MessageId:    msg.Key,
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+		Body:         msg.Body,
+	})
+	if err != nil {
+		r.reset()
+		return err
+	}
+	select {
+	case confirm, ok := <-r.confirms:
+		if !ok {
+			r.reset()
+			return errors.New("broker: rabbitmq channel closed")
+		}
+		if !confirm.Ack {
+			return fmt.Errorf("broker: rabbitmq nack, delivery tag %d", confirm.DeliveryTag)
+		}
+		return nil
+	case <-ctx.Done():
+		// 未等到确认的通道不再使用，避免后续消息收到这条的确认
+		r.reset()
+		return ctx.Err()
+	}
+}
+
+func (r *rabbitDriver) reset() {
+	if r.ch != nil {
+		_ = r.ch.Close()
+		r.ch = nil
+	}
+}
+
+// Consume 处理成功时ack，失败时reject且不重新入队，队列配置了死信交换机时进入死信队列
+func (r *rabbitDriver) Consume(ctx context.Context, topic, group string, handler Handler) error {
+	r.mu.Lock()
+	if r.conn == nil || r.conn.IsClosed() {
+		r.reset()
+		if err := r.connect(); err != nil {
+			r.mu.Unlock()
+			return err
+		}
+	}
+	ch, err := r.conn.Channel()
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	queue := group + "." + topic
+	if r.cfg.Prefetch > 0 {
+		if err := ch.Qos(r.cfg.Prefetch, 0, false); err != nil {
+			return err
+		}
+	}
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		return err
+	}
+	if err := ch.QueueBind(queue, topic, r.cfg.Exchange, false, nil); err != nil {
+		return err
+	}
+	deliveries, err := ch.Consume(queue, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case d, ok := <-deliveries:
+			if !ok {
+				return errors.New("broker: rabbitmq delivery channel closed")
+			}
+			msg := &Message{Topic: topic, Key: d.MessageId, Body: d.Body, Headers: make(map[string]string, len(d.Headers))}
+			for key, value := range d.Headers {
+				if s, ok := value.(string); ok {
+					msg.Headers[key] = s
+				}
+			}
+			if msg.Headers[HeaderContentType] == "" {
+				msg.Headers[HeaderContentType] = d.ContentType
+			}
+			if err := handler(ctx, msg); err != nil {
+				_ = d.Reject(false)
+			} else {
+				_ = d.Ack(false)
+			}
+		}
+	}
+}
+
+func (r *rabbitDriver) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reset()
+	if r.conn != nil && !r.conn.IsClosed() {
+		return r.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"go-skeleton/pkg/gredis"
+	"net/url"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 接口响应缓存，middleware.Cache将GET请求渲染后的响应保存到redis，key按路径、查询参数及用户角色等区分，eg:
+//
+//	apiRouter.GET("/tag/hot", middleware.Cache(time.Minute, "tag", "article_tag"), tag.Hot)
+//
+// 数据修改后可以用Purge按路径删除，注册的表通过Plugin在写入后发布eventbus.ModelChanged，
+// 由Init订阅并删除关联该表的缓存
+
+const (
+	keyPrefix = "httpcache:"
+	// 表关联的缓存key集合，eg: httpcache:table:tag
+	tableKeyPrefix = keyPrefix + "table:"
+	// Purge每次扫描的key数量
+	scanBatch = 500
+)
+
+// Entry 缓存的响应
+type Entry struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType"`
+	Body        []byte `json:"body"`
+}
+
+// 保存响应并加入各个表的key集合，集合的过期时间不短于其中的响应
+var setScript = redis.NewScript(`
+redis.call("set", KEYS[1], ARGV[1], "px", ARGV[2])
+for i = 2, #KEYS do
+	redis.call("sadd", KEYS[i], KEYS[1])
+	if redis.call("pttl", KEYS[i]) < tonumber(ARGV[2]) then
+		redis.call("pexpire", KEYS[i], ARGV[2])
+	end
+end
+return 1
+`)
+
+// Key 缓存key，查询参数按名称排序，vary为区分用户的部分，eg: httpcache:/api/v1/tag/hot?limit=10#guest|zh
+func Key(path string, query url.Values, vary string) string {
+	return keyPrefix + path + "?" + query.Encode() + "#" + vary
+}
+
+// Get 读取缓存的响应，不存在时返回redis.Nil
+func Get(ctx context.Context, key string) (*Entry, error) {
+	b, err := gredis.GetRedis().Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	e := &Entry{}
+	if err := json.Unmarshal(b, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Set 保存响应，tables中的表有写入时删除
+func Set(ctx context.Context, key string, e *Entry, ttl time.Duration, tables ...string) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(tables)+1)
+	keys = append(keys, key)
+	for _, table := range tables {
+		keys = append(keys, tableKeyPrefix+table)
+	}
+	return setScript.Run(ctx, gredis.GetRedis(), keys, b, ttl.Milliseconds()).Err()
+}
+
+// Purge 删除路径匹配pattern的缓存，pattern为redis的glob，eg: /api/*/article/*，返回删除的数量
+func Purge(ctx context.Context, pattern string) (int, error) {
+	rdb := gredis.GetRedis()
+	match := keyPrefix + pattern + "[?]*"
+	var (
+		cursor uint64
+		total  int
+	)
+	for {
+		keys, next, err := rdb.Scan(ctx, cursor, match, scanBatch).Result()
+		if err != nil {
+			return total, err
+		}
+		if len(keys) > 0 {
+			n, err := rdb.Del(ctx, keys...).Result()
+			if err != nil {
+				return total, err
+			}
+			total += int(n)
+		}
+		if cursor = next; cursor == 0 {
+			return total, nil
+		}
+	}
+}
+
+// PurgeTables 删除关联了tables的缓存，返回删除的数量
+func PurgeTables(ctx context.Context, tables ...string) (int, error) {
+	rdb := gredis.GetRedis()
+	total := 0
+	for _, table := range tables {
+		setKey := tableKeyPrefix + table
+		keys, err := rdb.SMembers(ctx, setKey).Result()
+		if err != nil {
+			return total, err
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		keys = append(keys, setKey)
+		n, err := rdb.Del(ctx, keys...).Result()
+		if err != nil {
+			return total, err
+		}
+		// 不计入表的key集合
+		total += int(n) - 1
+	}
+	return total, nil
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"go-skeleton/pkg/eventbus"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Plugin 注册到gorm的回调，新增、修改、删除成功后发布eventbus.ModelChanged。
+// db.Exec执行的sql不会触发，需要手动调用PurgeTables
+type Plugin struct{}
+
+func (p Plugin) Name() string {
+	return "cache"
+}
+
+func (p Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("cache:after_create", changed("create")); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("cache:after_update", changed("update")); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Register("cache:after_delete", changed("delete"))
+}
+
+func changed(action string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.DryRun || db.RowsAffected == 0 || db.Statement.Table == "" {
+			return
+		}
+		ctx := db.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		e := eventbus.ModelChanged{Table: db.Statement.Table, Action: action}
+		if err := eventbus.Publish(ctx, e); err != nil {
+			zap.L().Warn("cache: publish model changed failed", zap.String("table", e.Table), zap.Error(err))
+		}
+	}
+}
+
+// Init 订阅eventbus.ModelChanged删除关联的缓存。事务中的写入在提交前就会发布事件，
+// 期间的读取可能把旧数据重新缓存，因此在delay后再删除一次，delay为0时不重复删除
+func Init(delay time.Duration) {
+	eventbus.Subscribe(eventbus.ModelChanged{}, func(ctx context.Context, e eventbus.Event) error {
+		table := e.(eventbus.ModelChanged).Table
+		if delay > 0 {
+			time.AfterFunc(delay, func() {
+				if _, err := PurgeTables(context.Background(), table); err != nil {
+					zap.L().Warn("cache: delayed purge failed", zap.String("table", table), zap.Error(err))
+				}
+			})
+		}
+		_, err := PurgeTables(ctx, table)
+		return err
+	})
+}
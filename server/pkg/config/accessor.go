@@ -0,0 +1,65 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// 类型化的配置读取方法，key使用点号分隔，eg: config.GetString("database.DbHost")
+
+func Get(key string) interface{} {
+	return viper.Get(key)
+}
+
+func GetString(key string) string {
+	return viper.GetString(key)
+}
+
+func GetInt(key string) int {
+	return viper.GetInt(key)
+}
+
+func GetInt64(key string) int64 {
+	return viper.GetInt64(key)
+}
+
+func GetFloat64(key string) float64 {
+	return viper.GetFloat64(key)
+}
+
+func GetBool(key string) bool {
+	return viper.GetBool(key)
+}
+
+// GetDuration 支持"1h30m"格式的字符串，纯数字按纳秒处理
+func GetDuration(key string) time.Duration {
+	return viper.GetDuration(key)
+}
+
+func GetStringSlice(key string) []string {
+	return viper.GetStringSlice(key)
+}
+
+func GetStringMap(key string) map[string]interface{} {
+	return viper.GetStringMap(key)
+}
+
+func GetStringMapString(key string) map[string]string {
+	return viper.GetStringMapString(key)
+}
+
+// IsSet 配置项是否存在
+func IsSet(key string) bool {
+	return viper.IsSet(key)
+}
+
+// UnmarshalKey 将指定配置节点解析到结构体，字段使用mapstructure标签
+func UnmarshalKey(key string, rawVal interface{}) error {
+	return viper.UnmarshalKey(key, rawVal)
+}
+
+// Unmarshal 将全部配置解析到结构体
+func Unmarshal(rawVal interface{}) error {
+	return viper.Unmarshal(rawVal)
+}
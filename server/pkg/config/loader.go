@@ -0,0 +1,131 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// 配置文件目录
+var configPath = "./configs"
+
+// 支持的配置文件格式，按顺序查找
+var configExts = []string{"yml", "yaml", "toml", "json"}
+
+// 环境变量占位符，eg: ${DB_HOST} 或 ${DB_HOST:127.0.0.1}
+var envRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::([^}]*))?\}`)
+
+// load 读取config.{ext}，再合并当前环境的config.{env}.{ext}，最后解析到Conf
+func load() error {
+	file, err := findConfigFile("config")
+	if err != nil {
+		return err
+	}
+	settings, err := readSettings(file)
+	if err != nil {
+		return err
+	}
+
+	if envFile, err := findConfigFile("config." + Env); err == nil {
+		envSettings, err := readSettings(envFile)
+		if err != nil {
+			return err
+		}
+		mergeSettings(settings, envSettings)
+	}
+
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	viper.SetConfigType("json")
+	if err := viper.ReadConfig(bytes.NewReader(b)); err != nil {
+		return err
+	}
+
+	return viper.Unmarshal(Conf)
+}
+
+// bindEnvs 按mapstructure标签绑定环境变量，使配置文件中为空的配置项也能被环境变量覆盖
+func bindEnvs(t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tags := strings.Split(field.Tag.Get("mapstructure"), ",")
+		key := tags[0]
+		if len(tags) > 1 && tags[1] == "squash" {
+			bindEnvs(field.Type, prefix)
+			continue
+		}
+		if key == "" {
+			key = field.Name
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			bindEnvs(field.Type, key)
+			continue
+		}
+		if field.Type.Kind() == reflect.Map || field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct {
+			continue
+		}
+		_ = viper.BindEnv(key)
+	}
+}
+
+// findConfigFile 在配置目录中查找指定名称的配置文件
+func findConfigFile(name string) (string, error) {
+	for _, ext := range configExts {
+		file := filepath.Join(configPath, name+"."+ext)
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		}
+	}
+	return "", viper.ConfigFileNotFoundError{}
+}
+
+// readSettings 读取配置文件，替换其中的环境变量占位符
+func readSettings(file string) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	v := viper.New()
+	v.SetConfigType(strings.TrimPrefix(filepath.Ext(file), "."))
+	if err := v.ReadConfig(bytes.NewReader(ExpandEnv(b))); err != nil {
+		return nil, err
+	}
+	return v.AllSettings(), nil
+}
+
+// mergeSettings 将src深度合并到dst，同名配置以src为准
+func mergeSettings(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, ok1 := v.(map[string]interface{})
+		dstMap, ok2 := dst[k].(map[string]interface{})
+		if ok1 && ok2 {
+			mergeSettings(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// ExpandEnv 替换${NAME}和${NAME:default}形式的环境变量，未设置时使用默认值
+func ExpandEnv(b []byte) []byte {
+	return envRegexp.ReplaceAllFunc(b, func(m []byte) []byte {
+		sub := envRegexp.FindSubmatch(m)
+		if v, ok := os.LookupEnv(string(sub[1])); ok {
+			return []byte(v)
+		}
+		return sub[2]
+	})
+}
@@ -1,23 +1,56 @@
 package config
 
 import (
-	"fmt"
+	"os"
+	"reflect"
+	"strings"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
-	"go.uber.org/zap"
 )
 
-//tag:   https://github.com/mitchellh/mapstructure
-//全局配置
+// tag:   https://github.com/mitchellh/mapstructure
+// 全局配置
 type Config struct {
-	ServerConfig `mapstructure:"server"`
-	DbConfig     `mapstructure:"database"`
-	LogConfig    `mapstructure:"log"`
-	QiniuConfig  `mapstructure:"qiniu"`
-	RedisConfig  `mapstructure:"redis"`
-	AppConfig    `mapstructure:"app"`
+	ServerConfig     `mapstructure:"server"`
+	DbConfig         `mapstructure:"database"`
+	LogConfig        `mapstructure:"log"`
+	QiniuConfig      `mapstructure:"qiniu"`
+	RedisConfig      `mapstructure:"redis"`
+	AppConfig        `mapstructure:"app"`
+	JwtConfig        `mapstructure:"jwt"`
+	TraceConfig      `mapstructure:"trace"`
+	RateLimitConfig  `mapstructure:"ratelimit"`
+	CorsConfig       `mapstructure:"cors"`
+	UploadConfig     `mapstructure:"upload"`
+	SearchConfig     `mapstructure:"search"`
+	QueueConfig      `mapstructure:"queue"`
+	CronConfig       `mapstructure:"cron"`
+	MailConfig       `mapstructure:"mail"`
+	SmsConfig        `mapstructure:"sms"`
+	WebsocketConfig  `mapstructure:"websocket"`
+	UserConfig       `mapstructure:"user"`
+	OAuthConfig      `mapstructure:"oauth"`
+	CaptchaConfig    `mapstructure:"captcha"`
+	MigrateConfig    `mapstructure:"migrate"`
+	BrokerConfig     `mapstructure:"broker"`
+	OutboxConfig     `mapstructure:"outbox"`
+	OpenApiConfig    `mapstructure:"openapi"`
+	I18nConfig       `mapstructure:"i18n"`
+	GeoIPConfig      `mapstructure:"geoip"`
+	SensitiveConfig  `mapstructure:"sensitive"`
+	PdfConfig        `mapstructure:"pdf"`
+	FeatureConfig    `mapstructure:"feature"`
+	ExperimentConfig `mapstructure:"experiment"`
+	RevisionConfig   `mapstructure:"revision"`
+	ReviewConfig     `mapstructure:"review"`
+	TenantConfig     `mapstructure:"tenant"`
+	EncryptConfig    `mapstructure:"encrypt"`
+	CompressConfig   `mapstructure:"compress"`
+	EtagConfig       `mapstructure:"etag"`
+	CacheConfig      `mapstructure:"cache"`
+	ApiVersionConfig `mapstructure:"apiversion"`
+	GrpcConfig       `mapstructure:"grpc"`
 	//Other map[string]interface{} `mapstructure:",remain"`
 }
 
@@ -37,14 +70,557 @@ type AppConfig struct {
 	FontSavePath   string `mapstructure:"FontSavePath"`
 }
 
+// jwt配置，Alg为HS256时使用app.JwtKey签名，RS256时使用PrivateKey、PublicKey指定的pem文件
+type JwtConfig struct {
+	Alg        string        `mapstructure:"Alg"`
+	Issuer     string        `mapstructure:"Issuer"`
+	PrivateKey string        `mapstructure:"PrivateKey"`
+	PublicKey  string        `mapstructure:"PublicKey"`
+	AccessTTL  time.Duration `mapstructure:"AccessTTL"`
+	RefreshTTL time.Duration `mapstructure:"RefreshTTL"`
+}
+
+// 链路追踪配置
+type TraceConfig struct {
+	Enable      bool   `mapstructure:"Enable"`
+	ServiceName string `mapstructure:"ServiceName"`
+	// otlp | jaeger
+	Exporter string `mapstructure:"Exporter"`
+	// otlp为collector的grpc地址，eg: 127.0.0.1:4317；jaeger为collector地址，eg: http://127.0.0.1:14268/api/traces
+	Endpoint string `mapstructure:"Endpoint"`
+	// 采样率 0~1
+	SampleRatio float64 `mapstructure:"SampleRatio"`
+}
+
+// websocket配置
+type WebsocketConfig struct {
+	// 多实例部署时通过redis发布订阅转发消息，连接在任意实例上都能收到
+	PubSub  bool   `mapstructure:"PubSub"`
+	Channel string `mapstructure:"Channel"`
+	// 服务端发送ping的间隔，超过PongTimeout未收到任何消息时断开
+	PingInterval time.Duration `mapstructure:"PingInterval"`
+	PongTimeout  time.Duration `mapstructure:"PongTimeout"`
+	WriteTimeout time.Duration `mapstructure:"WriteTimeout"`
+	// 客户端消息的最大字节数
+	MaxMessageSize int64 `mapstructure:"MaxMessageSize"`
+	// 每个连接待发送消息的缓冲数，写满时断开慢连接
+	SendBuffer int `mapstructure:"SendBuffer"`
+}
+
+// 用户账号配置
+type UserConfig struct {
+	// 连续登录失败MaxLoginAttempts次后锁定账号LockDuration，0为不锁定
+	MaxLoginAttempts int64         `mapstructure:"MaxLoginAttempts"`
+	LockDuration     time.Duration `mapstructure:"LockDuration"`
+	// 密码哈希参数，修改后旧密码在登录成功时自动重新计算
+	Password PasswordConfig `mapstructure:"Password"`
+}
+
+type PasswordConfig struct {
+	// bcrypt | argon2id
+	Algorithm  string `mapstructure:"Algorithm"`
+	BcryptCost int    `mapstructure:"BcryptCost"`
+	// argon2id的迭代次数、内存(KiB)和并行度
+	Argon2Time    uint32 `mapstructure:"Argon2Time"`
+	Argon2Memory  uint32 `mapstructure:"Argon2Memory"`
+	Argon2Threads uint8  `mapstructure:"Argon2Threads"`
+}
+
+// 敏感词过滤配置，文章和评论保存前检查
+type SensitiveConfig struct {
+	Enable bool `mapstructure:"Enable"`
+	// 词库文件，每行一个词
+	Files []string `mapstructure:"Files"`
+	// 是否加载sensitive_word表中的词
+	Db bool `mapstructure:"Db"`
+	// replace 替换为* | reject 拒绝保存
+	Mode string `mapstructure:"Mode"`
+	// 匹配时忽略的字符，eg: 敏*感*词
+	SkipChars string `mapstructure:"SkipChars"`
+	// 重新加载词库的间隔，0为不重新加载
+	ReloadInterval time.Duration `mapstructure:"ReloadInterval"`
+}
+
+// pdf生成配置，html模板通过wkhtmltopdf转换
+type PdfConfig struct {
+	// wkhtmltopdf的路径，为空时从PATH中查找
+	Binary string `mapstructure:"Binary"`
+	// 模板目录，模板文件为{name}.html
+	TemplateDir string `mapstructure:"TemplateDir"`
+	// 单个文件的生成超时
+	Timeout time.Duration `mapstructure:"Timeout"`
+	// 传给wkhtmltopdf的其他参数，eg: [--page-size, A4]
+	Options []string `mapstructure:"Options"`
+	// 保存到上传存储中的目录
+	Dir string `mapstructure:"Dir"`
+	// 异步任务结果的保存时间
+	ResultTTL time.Duration `mapstructure:"ResultTTL"`
+}
+
+// 功能开关配置，配置文件中的开关可以被feature_flag表中同名的开关覆盖
+type FeatureConfig struct {
+	Enable bool `mapstructure:"Enable"`
+	// 是否加载feature_flag表中的开关
+	Db bool `mapstructure:"Db"`
+	// 开关在redis中的缓存时间
+	CacheTTL time.Duration `mapstructure:"CacheTTL"`
+	// 本地重新加载的间隔，0为只在收到失效通知时加载
+	ReloadInterval time.Duration `mapstructure:"ReloadInterval"`
+	// 开关修改后通知其他实例重新加载的redis频道
+	Channel string `mapstructure:"Channel"`
+	// 开关名称需要小写，viper读取时会转换为小写
+	Flags map[string]FeatureFlagConfig `mapstructure:"Flags"`
+}
+
+type FeatureFlagConfig struct {
+	Enabled bool `mapstructure:"Enabled"`
+	// 灰度比例0~100，按用户id分桶，100为所有人
+	Percentage int `mapstructure:"Percentage"`
+	// 指定开启的用户id
+	Users []int64 `mapstructure:"Users"`
+	// 指定开启的角色code
+	Roles []string `mapstructure:"Roles"`
+}
+
+// A/B实验配置，实验名称需要小写，viper读取时会转换为小写
+type ExperimentConfig struct {
+	Enable      bool                      `mapstructure:"Enable"`
+	Experiments map[string]ExperimentItem `mapstructure:"Experiments"`
+}
+
+type ExperimentItem struct {
+	Enabled bool `mapstructure:"Enabled"`
+	// 分组及权重，第一个分组为对照组，未参与实验的用户也使用对照组
+	Variants []ExperimentVariant `mapstructure:"Variants"`
+}
+
+type ExperimentVariant struct {
+	Name   string `mapstructure:"Name"`
+	Weight int    `mapstructure:"Weight"`
+}
+
+// 文章历史版本配置，定时任务revision.prune按配置清理
+type RevisionConfig struct {
+	// 每篇文章至少保留的最近版本数
+	Keep int `mapstructure:"Keep"`
+	// 超过Keep的版本保留的时长，0为只按Keep保留
+	Retention time.Duration `mapstructure:"Retention"`
+	// 自动保存的最小间隔，间隔内的自动保存覆盖上一次
+	AutosaveInterval time.Duration `mapstructure:"AutosaveInterval"`
+}
+
+// 文章审核配置
+type ReviewConfig struct {
+	// 开启后草稿需要提交审核，审核通过后才能发布，修改已发布的文章后需要重新审核
+	Enable bool `mapstructure:"Enable"`
+}
+
+// 多租户配置，先按请求头解析租户，没有时按子域名解析
+type TenantConfig struct {
+	Enable bool `mapstructure:"Enable"`
+	// 为true时没有租户的请求返回404，访问租户数据的语句没有租户时返回错误
+	Strict bool `mapstructure:"Strict"`
+	// 租户code所在的请求头，eg: X-Tenant
+	Header string `mapstructure:"Header"`
+	// 主域名，eg: example.com，acme.example.com解析为租户acme，为空时不按子域名解析
+	Domain string `mapstructure:"Domain"`
+}
+
+// 字段加密配置，model.EncryptedString使用，密钥id使用小写
+type EncryptConfig struct {
+	// 加密使用的密钥id，轮换时新增密钥并修改KeyId，再执行encrypt:rotate
+	KeyId string `mapstructure:"KeyId"`
+	// 密钥id => base64编码的16、24或32字节密钥，解密旧数据的密钥需要保留
+	Keys map[string]string `mapstructure:"Keys"`
+}
+
+// 响应压缩配置，按Accept-Encoding使用br、gzip或deflate
+type CompressConfig struct {
+	Enable bool `mapstructure:"Enable"`
+	// 压缩级别，0为默认级别，gzip、deflate为1-9，br为1-11
+	CompressLevel int `mapstructure:"CompressLevel"`
+	// 小于该字节数的响应不压缩
+	MinSize int `mapstructure:"MinSize"`
+	// 压缩的Content-Type前缀，为空时压缩文本、json、js、xml和svg
+	Types []string `mapstructure:"Types"`
+	// 不压缩的路径前缀，websocket和SSE请求总是不压缩
+	ExcludePaths []string `mapstructure:"ExcludePaths"`
+	// 静态资源目录和访问前缀，目录中构建时生成的x.br、x.gz文件按Accept-Encoding优先返回
+	StaticDir    string `mapstructure:"StaticDir"`
+	StaticPrefix string `mapstructure:"StaticPrefix"`
+}
+
+// ETag配置，GET请求成功的响应按内容生成ETag，If-None-Match一致时返回304
+type EtagConfig struct {
+	Enable bool `mapstructure:"Enable"`
+	// 是否生成弱ETag，压缩后的响应总是弱ETag
+	Weak bool `mapstructure:"Weak"`
+	// 缓存的最大响应字节数，更大的响应不生成ETag
+	MaxBuffer int `mapstructure:"MaxBuffer"`
+}
+
+// 接口响应缓存配置，见middleware.Cache
+type CacheConfig struct {
+	Enable bool `mapstructure:"Enable"`
+	// 表写入后再次删除缓存的延迟，避免事务提交前的读取把旧数据重新缓存，0为不再次删除
+	PurgeDelay time.Duration `mapstructure:"PurgeDelay"`
+	// 缓存的最大响应字节数，0为不限制
+	MaxBody int `mapstructure:"MaxBody"`
+}
+
+// API版本配置，未带版本的/api请求按X-API-Version或Accept选择版本，见apiversion包
+type ApiVersionConfig struct {
+	// 请求未指定版本时使用的版本
+	DefaultVersion string `mapstructure:"DefaultVersion"`
+	// 已弃用的版本 => 弃用信息，版本使用小写，eg: v1
+	Deprecated map[string]DeprecatedVersion `mapstructure:"Deprecated"`
+}
+
+// 已弃用版本的响应头信息，日期格式为2006-01-02
+type DeprecatedVersion struct {
+	Since string `mapstructure:"Since"`
+	// 计划下线的日期
+	Sunset string `mapstructure:"Sunset"`
+	// 迁移说明的链接
+	Link string `mapstructure:"Link"`
+}
+
+// gRPC服务配置，与http服务使用不同的端口
+type GrpcConfig struct {
+	Enable   bool   `mapstructure:"Enable"`
+	GrpcPort string `mapstructure:"GrpcPort"`
+	// 是否开启服务反射，开启后可以用grpcurl调试，生产环境建议关闭
+	Reflection bool `mapstructure:"Reflection"`
+}
+
+// ip地理位置配置，使用MaxMind GeoLite2的mmdb文件
+type GeoIPConfig struct {
+	Enable bool `mapstructure:"Enable"`
+	// GeoLite2-City.mmdb或GeoLite2-Country.mmdb
+	CityDb string `mapstructure:"CityDb"`
+	// GeoLite2-ASN.mmdb，为空时不查询ASN
+	AsnDb string `mapstructure:"AsnDb"`
+	// 国家、城市名称的语言，eg: zh-CN, en
+	Language string `mapstructure:"Language"`
+	// 检查数据库文件是否更新的间隔，0为不检查
+	ReloadInterval time.Duration `mapstructure:"ReloadInterval"`
+}
+
+// 多语言配置，Dir下的文件名为语言，eg: zh.json, en.toml
+type I18nConfig struct {
+	Dir string `mapstructure:"Dir"`
+	// 找不到请求的语言或消息时使用的语言
+	DefaultLocale string `mapstructure:"DefaultLocale"`
+}
+
+// 开放接口配置，合作方请求需要按utils.SignRequest签名
+type OpenApiConfig struct {
+	// 请求时间戳允许的误差，nonce在该时间的2倍内不能重复使用
+	TimestampSkew time.Duration    `mapstructure:"TimestampSkew"`
+	Partners      []OpenApiPartner `mapstructure:"Partners"`
+}
+
+// 合作方，SignType为MD5或HMAC-SHA256，为空时使用HMAC-SHA256
+type OpenApiPartner struct {
+	AppKey   string `mapstructure:"AppKey"`
+	Secret   string `mapstructure:"Secret"`
+	SignType string `mapstructure:"SignType"`
+}
+
+// 图形验证码配置
+type CaptchaConfig struct {
+	// 默认类型，string | math | slider
+	Type string        `mapstructure:"Type"`
+	TTL  time.Duration `mapstructure:"TTL"`
+	// 字符验证码的长度
+	Length int `mapstructure:"Length"`
+	Width  int `mapstructure:"Width"`
+	Height int `mapstructure:"Height"`
+	// 滑动验证码允许的误差，单位像素
+	SliderTolerance int `mapstructure:"SliderTolerance"`
+}
+
+// 第三方登录配置
+type OAuthConfig struct {
+	// 授权state的有效期
+	StateTTL time.Duration `mapstructure:"StateTTL"`
+	// key为github、google、wechat，未配置ClientId的不可用
+	Providers map[string]OAuthProviderConfig `mapstructure:"Providers"`
+}
+
+type OAuthProviderConfig struct {
+	// 微信为AppID和AppSecret
+	ClientId     string `mapstructure:"ClientId"`
+	ClientSecret string `mapstructure:"ClientSecret"`
+	// 授权后跳转的前端地址，由前端将code和state提交给后端
+	RedirectUrl string   `mapstructure:"RedirectUrl"`
+	Scopes      []string `mapstructure:"Scopes"`
+}
+
+// 短信配置
+type SmsConfig struct {
+	// aliyun | tencent | log(只打印日志，用于开发环境)
+	Driver   string `mapstructure:"Driver"`
+	SignName string `mapstructure:"SignName"`
+	// 场景对应的模板，eg: login: SMS_123456
+	Templates map[string]string `mapstructure:"Templates"`
+	// 验证码长度和有效期
+	CodeLength int           `mapstructure:"CodeLength"`
+	CodeTTL    time.Duration `mapstructure:"CodeTTL"`
+	// 同一手机号的发送间隔和每天最多发送次数
+	SendInterval time.Duration `mapstructure:"SendInterval"`
+	DailyLimit   int64         `mapstructure:"DailyLimit"`
+	// 验证码最多可以校验的次数，超过后失效
+	MaxAttempts int64 `mapstructure:"MaxAttempts"`
+
+	Aliyun  AliyunSmsConfig  `mapstructure:"Aliyun"`
+	Tencent TencentSmsConfig `mapstructure:"Tencent"`
+}
+
+type AliyunSmsConfig struct {
+	AccessKeyId     string `mapstructure:"AccessKeyId"`
+	AccessKeySecret string `mapstructure:"AccessKeySecret"`
+	RegionId        string `mapstructure:"RegionId"`
+}
+
+type TencentSmsConfig struct {
+	SecretId  string `mapstructure:"SecretId"`
+	SecretKey string `mapstructure:"SecretKey"`
+	SdkAppId  string `mapstructure:"SdkAppId"`
+	Region    string `mapstructure:"Region"`
+}
+
+// 消息中间件配置
+type BrokerConfig struct {
+	// kafka | rabbitmq，为空时不启用
+	Driver string `mapstructure:"Driver"`
+	// 消息编码，json | protobuf
+	Codec string `mapstructure:"Codec"`
+	// 发送失败的重试次数及间隔，间隔按次数递增
+	MaxRetry     int           `mapstructure:"MaxRetry"`
+	RetryBackoff time.Duration `mapstructure:"RetryBackoff"`
+	// 处理消息失败的重试次数，超过后kafka提交位点跳过，rabbitmq拒绝消息(配置了死信交换机时进入死信队列)
+	HandleRetry int `mapstructure:"HandleRetry"`
+	// 转发到消息中间件的领域事件，eg: article.published
+	Events []string `mapstructure:"Events"`
+
+	Kafka    KafkaConfig    `mapstructure:"Kafka"`
+	RabbitMQ RabbitMQConfig `mapstructure:"RabbitMQ"`
+}
+
+// 事务发件箱配置，事件和业务数据在同一事务中写入outbox_event表，再由relay转发到消息中间件
+type OutboxConfig struct {
+	Enable bool `mapstructure:"Enable"`
+	// 轮询未发送事件的间隔及每次最多发送的条数
+	PollInterval time.Duration `mapstructure:"PollInterval"`
+	BatchSize    int           `mapstructure:"BatchSize"`
+	// 发送失败的最大次数，超过后不再发送，需要人工处理
+	MaxAttempts int `mapstructure:"MaxAttempts"`
+	// 已发送的事件保留时长，由定时任务outbox.cleanup清理
+	Retention time.Duration `mapstructure:"Retention"`
+}
+
+type KafkaConfig struct {
+	Brokers []string `mapstructure:"Brokers"`
+	// topic前缀，eg: blog.
+	TopicPrefix string `mapstructure:"TopicPrefix"`
+}
+
+type RabbitMQConfig struct {
+	Url string `mapstructure:"Url"`
+	// topic类型的交换机，消息的routing key为topic
+	Exchange string `mapstructure:"Exchange"`
+	// 每个消费者未确认的最大消息数
+	Prefetch int `mapstructure:"Prefetch"`
+}
+
+// 邮件配置
+type MailConfig struct {
+	// smtp | sendgrid，其他服务商可以通过mail.RegisterDriver注册
+	Driver   string `mapstructure:"Driver"`
+	From     string `mapstructure:"From"`
+	FromName string `mapstructure:"FromName"`
+	// 邮件模板目录，模板文件为{name}.html
+	TemplateDir string `mapstructure:"TemplateDir"`
+
+	Smtp     SmtpConfig     `mapstructure:"Smtp"`
+	SendGrid SendGridConfig `mapstructure:"SendGrid"`
+}
+
+type SmtpConfig struct {
+	Host     string `mapstructure:"Host"`
+	Port     int    `mapstructure:"Port"`
+	Username string `mapstructure:"Username"`
+	Password string `mapstructure:"Password"`
+	// 465端口使用ssl直连，其他端口服务器支持时使用STARTTLS
+	SSL bool `mapstructure:"SSL"`
+}
+
+type SendGridConfig struct {
+	ApiKey string `mapstructure:"ApiKey"`
+}
+
+// 定时任务配置
+type CronConfig struct {
+	Enable bool `mapstructure:"Enable"`
+	// 按任务名称覆盖代码中注册的配置
+	Jobs map[string]CronJobConfig `mapstructure:"Jobs"`
+}
+
+type CronJobConfig struct {
+	// 执行时间，支持秒，eg: 0 */5 * * * * 或 @every 5m
+	Spec string `mapstructure:"Spec"`
+	// 启动时暂停，可以通过管理接口恢复
+	Paused bool `mapstructure:"Paused"`
+	// 是否使用分布式锁保证多实例部署时只有一个实例执行，默认开启
+	Lock *bool `mapstructure:"Lock"`
+	// 锁的过期时间，执行期间自动续期
+	LockTTL time.Duration `mapstructure:"LockTTL"`
+}
+
+// redis任务队列配置
+type QueueConfig struct {
+	// 是否在本进程启动worker，关闭时仍然可以投递任务
+	Enable bool `mapstructure:"Enable"`
+	// 处理的队列，靠前的优先处理
+	Queues      []string `mapstructure:"Queues"`
+	Concurrency int      `mapstructure:"Concurrency"`
+	// 默认的最大重试次数，超过后进入死信队列
+	MaxRetry int `mapstructure:"MaxRetry"`
+	// 单个任务的执行超时，超时未确认的任务会重新投递
+	Timeout time.Duration `mapstructure:"Timeout"`
+	// 队列为空时的轮询间隔
+	PollInterval time.Duration `mapstructure:"PollInterval"`
+	// 死信队列保留的最大任务数
+	DeadLetterMax int64 `mapstructure:"DeadLetterMax"`
+}
+
+// 全文搜索配置，使用Elasticsearch 7.x
+type SearchConfig struct {
+	Enable bool `mapstructure:"Enable"`
+	// eg: http://127.0.0.1:9200
+	Url      string `mapstructure:"Url"`
+	Username string `mapstructure:"Username"`
+	Password string `mapstructure:"Password"`
+	// 文章索引名称
+	Index string `mapstructure:"Index"`
+	// 文本字段的分词器，安装了ik插件时可以使用ik_max_word
+	Analyzer string        `mapstructure:"Analyzer"`
+	Timeout  time.Duration `mapstructure:"Timeout"`
+}
+
+// 上传配置
+type UploadConfig struct {
+	// local | oss | s3
+	Driver string `mapstructure:"Driver"`
+	// 单个文件的最大尺寸，MB
+	MaxSize int `mapstructure:"MaxSize"`
+	// 允许的MIME类型，支持image/*这样的通配，并且需要是upload.Extension支持的类型
+	AllowTypes []string `mapstructure:"AllowTypes"`
+	// 图片的最大宽高，0为不限制
+	MaxWidth  int `mapstructure:"MaxWidth"`
+	MaxHeight int `mapstructure:"MaxHeight"`
+	// 分片上传的临时目录和合并后文件的最大尺寸，MB
+	ChunkDir     string `mapstructure:"ChunkDir"`
+	ChunkMaxSize int    `mapstructure:"ChunkMaxSize"`
+
+	Local LocalStorageConfig `mapstructure:"Local"`
+	Oss   OssStorageConfig   `mapstructure:"Oss"`
+	S3    S3StorageConfig    `mapstructure:"S3"`
+}
+
+type LocalStorageConfig struct {
+	Root string `mapstructure:"Root"`
+	// 访问地址前缀，eg: http://127.0.0.1:8000/upload/files
+	BaseURL string `mapstructure:"BaseURL"`
+}
+
+type OssStorageConfig struct {
+	Endpoint        string `mapstructure:"Endpoint"`
+	AccessKeyId     string `mapstructure:"AccessKeyId"`
+	AccessKeySecret string `mapstructure:"AccessKeySecret"`
+	Bucket          string `mapstructure:"Bucket"`
+	// 自定义域名，为空时使用bucket的默认域名
+	BaseURL string `mapstructure:"BaseURL"`
+}
+
+type S3StorageConfig struct {
+	Region string `mapstructure:"Region"`
+	// 兼容s3的服务填写，eg: minio
+	Endpoint  string `mapstructure:"Endpoint"`
+	AccessKey string `mapstructure:"AccessKey"`
+	SecretKey string `mapstructure:"SecretKey"`
+	Bucket    string `mapstructure:"Bucket"`
+	BaseURL   string `mapstructure:"BaseURL"`
+}
+
+// 跨域配置
+type CorsConfig struct {
+	CorsPolicy `mapstructure:",squash"`
+	// 按路由覆盖的策略
+	Routes []CorsRouteConfig `mapstructure:"Routes"`
+}
+
+// 跨域策略，AllowCredentials默认为false，为true时AllowOrigins不能包含*
+type CorsPolicy struct {
+	// 允许的来源，*表示全部，支持通配子域名，eg: https://*.example.com
+	AllowOrigins     []string      `mapstructure:"AllowOrigins"`
+	AllowMethods     []string      `mapstructure:"AllowMethods"`
+	AllowHeaders     []string      `mapstructure:"AllowHeaders"`
+	ExposeHeaders    []string      `mapstructure:"ExposeHeaders"`
+	AllowCredentials *bool         `mapstructure:"AllowCredentials"`
+	MaxAge           time.Duration `mapstructure:"MaxAge"`
+}
+
+// 路由的跨域策略，未配置的项沿用默认策略
+type CorsRouteConfig struct {
+	// 路由前缀，eg: /api/article/，带版本的请求按去掉版本后的路径匹配
+	Path       string `mapstructure:"Path"`
+	CorsPolicy `mapstructure:",squash"`
+}
+
+// 限流配置
+type RateLimitConfig struct {
+	Enable bool `mapstructure:"Enable"`
+	// memory 单机令牌桶 | redis 集群滑动窗口
+	Store string `mapstructure:"Store"`
+	// 默认规则，Limit为0时不限制
+	Limit  int           `mapstructure:"Limit"`
+	Window time.Duration `mapstructure:"Window"`
+	// ip | user
+	By    string          `mapstructure:"By"`
+	Rules []RateLimitRule `mapstructure:"Rules"`
+}
+
+// 按路由单独配置的限流规则
+type RateLimitRule struct {
+	// 路由，eg: /api/article/list，以*结尾时按前缀匹配，/api/v1/article/list等带版本的请求也会匹配
+	Path string `mapstructure:"Path"`
+	// 为空时匹配所有请求方法
+	Method string        `mapstructure:"Method"`
+	Limit  int           `mapstructure:"Limit"`
+	Window time.Duration `mapstructure:"Window"`
+	By     string        `mapstructure:"By"`
+}
+
 type ServerConfig struct {
 	AppMode      string        `mapstructure:"AppMode"`
 	HttpPort     string        `mapstructure:"HttpPort"`
 	ReadTimeout  time.Duration `mapstructure:"ReadTimeout"`
 	WriteTimeout time.Duration `mapstructure:"WriteTimeout"`
+	// 优雅关闭时等待处理中请求的最长时间，单位秒
+	ShutdownTimeout time.Duration `mapstructure:"ShutdownTimeout"`
+	// 收到关闭信号后/readyz先返回503，等待负载均衡摘除实例后再停止接收请求，单位秒，
+	// 需要大于负载均衡探测的间隔，0为不等待
+	ShutdownDelay time.Duration `mapstructure:"ShutdownDelay"`
+	// 可信的反向代理，ip或网段，只有来自这些地址的请求才读取X-Forwarded-For
+	TrustedProxies []string `mapstructure:"TrustedProxies"`
+	// 请求体的最大字节数，multipart上传使用MaxUploadSize，0为不限制
+	MaxBodySize   int64 `mapstructure:"MaxBodySize"`
+	MaxUploadSize int64 `mapstructure:"MaxUploadSize"`
 }
 
-//数据库配置
+// 数据库配置
 type DbConfig struct {
 	Db         string `mapstructure:"Db"`
 	DbHost     string `mapstructure:"DbHost"`
@@ -52,9 +628,67 @@ type DbConfig struct {
 	DbUser     string `mapstructure:"DbUser"`
 	DbPassWord string `mapstructure:"DbPassWord"`
 	DbName     string `mapstructure:"DbName"`
+
+	// 连接池配置，时长支持"1h"、"30m"格式
+	MaxOpenConns    int           `mapstructure:"MaxOpenConns"`
+	MaxIdleConns    int           `mapstructure:"MaxIdleConns"`
+	ConnMaxLifetime time.Duration `mapstructure:"ConnMaxLifetime"`
+	ConnMaxIdleTime time.Duration `mapstructure:"ConnMaxIdleTime"`
+	// 单条sql的超时时间，0为不限制
+	QueryTimeout time.Duration `mapstructure:"QueryTimeout"`
+	// 主键生成方式：auto(数据库自增)、snowflake，WorkerId为snowflake的机器id(0~1023)，多实例部署时不能重复
+	IdGenerator string `mapstructure:"IdGenerator"`
+	WorkerId    int64  `mapstructure:"WorkerId"`
+
+	// 慢查询日志，只读取默认连接的配置，所有连接共用，修改后自动生效
+	SlowQuery SlowQueryConfig `mapstructure:"SlowQuery"`
+
+	// 其他命名连接，通过simpleDb.Use(name)获取
+	Connections map[string]DbConfig `mapstructure:"Connections"`
+
+	// 读写分离：Replicas为全局只读库，Resolvers可为指定的表单独配置主从
+	Replicas  []DbConfig       `mapstructure:"Replicas"`
+	Resolvers []ResolverConfig `mapstructure:"Resolvers"`
+
+	// 分表：逻辑表名 => 分表规则，只读取默认连接的配置
+	Shards map[string]ShardConfig `mapstructure:"Shards"`
+}
+
+// 慢查询日志配置
+type SlowQueryConfig struct {
+	Enable bool `mapstructure:"Enable"`
+	// 耗时超过该值的sql记录日志
+	Threshold time.Duration `mapstructure:"Threshold"`
+	// 日志中不输出参数值，只保留占位符
+	RedactArgs bool `mapstructure:"RedactArgs"`
 }
 
-//日志配置
+// 数据库迁移配置
+type MigrateConfig struct {
+	// 迁移文件目录，migrate create生成的文件放在这里
+	Dir string `mapstructure:"Dir"`
+	// 记录已执行迁移的表
+	TableName string `mapstructure:"TableName"`
+	// 启动时自动执行未执行的迁移
+	AutoRun bool `mapstructure:"AutoRun"`
+}
+
+// 按表配置的主从库
+type ResolverConfig struct {
+	Tables   []string   `mapstructure:"Tables"`
+	Sources  []DbConfig `mapstructure:"Sources"`
+	Replicas []DbConfig `mapstructure:"Replicas"`
+}
+
+// 分表规则
+type ShardConfig struct {
+	// hash(按分片键取模，默认)、month(按月)
+	Type string `mapstructure:"Type"`
+	// hash分表的分表数，上线后不能修改
+	Count int `mapstructure:"Count"`
+}
+
+// 日志配置
 type LogConfig struct {
 	Level       string `mapstructure:"Level"`
 	Filename    string `mapstructure:"Filename"`
@@ -63,6 +697,21 @@ type LogConfig struct {
 	MaxBackups  int    `mapstructure:"MaxBackups"`
 	LogSavePath string `mapstructure:"LogSavePath"`
 	TimeFormat  string `mapstructure:"TimeFormat"`
+
+	Access AccessLogConfig `mapstructure:"Access"`
+}
+
+// 访问日志配置
+type AccessLogConfig struct {
+	// 记录的请求体最大字节数，0不记录
+	BodyMaxSize int `mapstructure:"BodyMaxSize"`
+	// 需要脱敏的请求体字段及url参数
+	MaskFields []string `mapstructure:"MaskFields"`
+	// 采样：每秒前Initial条全部记录，之后每Thereafter条记录一条，Initial为0不采样
+	SampleInitial    int `mapstructure:"SampleInitial"`
+	SampleThereafter int `mapstructure:"SampleThereafter"`
+	// 不记录的路径
+	SkipPaths []string `mapstructure:"SkipPaths"`
 }
 
 type QiniuConfig struct {
@@ -78,28 +727,39 @@ type RedisConfig struct {
 	MaxIdle     int           `mapstructure:"MaxIdle"`
 	MaxActive   int           `mapstructure:"MaxActive"`
 	IdleTimeout time.Duration `mapstructure:"IdleTimeout"`
+
+	DB           int `mapstructure:"DB"`
+	PoolSize     int `mapstructure:"PoolSize"`
+	MinIdleConns int `mapstructure:"MinIdleConns"`
+
+	// 其他命名实例，通过gredis.Use(name)获取
+	Connections map[string]RedisConfig `mapstructure:"Connections"`
 }
 
 var Conf = new(Config)
 
+// Env 运行环境，通过环境变量APP_ENV指定: dev | test | prod
+var Env = "dev"
+
+// 环境变量前缀，eg: APP_DATABASE_DBHOST 会覆盖 database.DbHost
+const envPrefix = "APP"
+
 func InitConfig() error {
-	//env := pflag.String("env" , "dev" , "环境变量: dev | test | product")
-	//pflag.Parse()
-	//fmt.Printf("%+v\n",*env)
-	//env := os.Getenv("env")
-	viper.SetConfigName("config")
-	viper.SetConfigType("yml")
-	viper.AddConfigPath("./configs")
-	err := viper.ReadInConfig()
-	if err != nil {
+	if env := os.Getenv(envPrefix + "_ENV"); env != "" {
+		Env = env
+	}
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+	bindEnvs(reflect.TypeOf(Config{}), "")
+
+	if err := load(); err != nil {
+		return err
+	}
+	//监听配置文件改动
+	if err := watch(); err != nil {
 		return err
 	}
-	_ = viper.Unmarshal(&Conf)
-	viper.WatchConfig() //监听配置文件改动
-	viper.OnConfigChange(func(in fsnotify.Event) {
-		zap.L().Info(fmt.Sprintf("配置文件修改成功:%s", in.Name))
-		_ = viper.Unmarshal(Conf)
-	})
 	//配置中心示例
 	//viper.AddRemoteProvider("etcd", "http://127.0.0.1:4001","/config/hugo.json")
 	//viper.SetConfigType("json") // because there is no file extension in a stream of bytes, supported extensions are "json", "toml", "yaml", "yml", "properties", "props", "prop", "env", "dotenv"
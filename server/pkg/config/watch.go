@@ -0,0 +1,104 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// ChangeFunc 配置项变更回调，old、new为变更前后的值
+type ChangeFunc func(old, new interface{})
+
+var (
+	callbackMu sync.RWMutex
+	callbacks  = make(map[string][]ChangeFunc)
+)
+
+// OnChange 注册配置项变更回调，配置文件重新加载后值发生变化时调用，key不区分大小写
+// eg: config.OnChange("log.Level", func(old, new interface{}) {...})
+func OnChange(key string, fn ChangeFunc) {
+	callbackMu.Lock()
+	defer callbackMu.Unlock()
+	key = strings.ToLower(key)
+	callbacks[key] = append(callbacks[key], fn)
+}
+
+// reload 重新加载配置，并对发生变化的配置项执行回调
+func reload() error {
+	callbackMu.RLock()
+	defer callbackMu.RUnlock()
+
+	olds := make(map[string]interface{}, len(callbacks))
+	for key := range callbacks {
+		olds[key] = viper.Get(key)
+	}
+
+	if err := load(); err != nil {
+		return err
+	}
+
+	for key, fns := range callbacks {
+		old, val := olds[key], viper.Get(key)
+		if reflect.DeepEqual(old, val) {
+			continue
+		}
+		zap.L().Info("配置项已变更", zap.String("key", key), zap.Any("old", old), zap.Any("new", val))
+		for _, fn := range fns {
+			runCallback(key, fn, old, val)
+		}
+	}
+	return nil
+}
+
+// runCallback 执行回调，避免回调panic导致监听协程退出
+func runCallback(key string, fn ChangeFunc, old, new interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			zap.L().Error("配置变更回调执行失败", zap.String("key", key), zap.Any("panic", r))
+		}
+	}()
+	fn(old, new)
+}
+
+// watch 监听配置目录，config.*文件改动后重新加载
+func watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(configPath); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !strings.HasPrefix(filepath.Base(event.Name), "config.") ||
+					event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				if err := reload(); err != nil {
+					zap.L().Error("重新加载配置文件失败", zap.String("file", event.Name), zap.Error(err))
+					continue
+				}
+				zap.L().Info("配置文件修改成功:" + event.Name)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				zap.L().Error("监听配置文件失败", zap.Error(err))
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,261 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/tenant"
+	"go-skeleton/utils"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	robfig "github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// 定时任务，通过Register注册，配置文件cron.Jobs中的同名配置优先，eg:
+//	cron.Register("sitemap", "0 0 4 * * *", func(ctx context.Context) error {
+//		return services.SitemapService.Generate(ctx)
+//	})
+
+// Func 任务方法，ctx在调度器停止时不会取消，长时间运行的任务需要自行控制超时
+type Func func(ctx context.Context) error
+
+var ErrJobNotFound = errors.New("cron: job not found")
+
+// 默认的锁过期时间
+const defaultLockTTL = time.Minute
+
+// JobInfo 任务状态
+type JobInfo struct {
+	Name      string `json:"name"`
+	Spec      string `json:"spec"`
+	Paused    bool   `json:"paused"`
+	Running   bool   `json:"running"`
+	Lock      bool   `json:"lock"`
+	NextRun   int64  `json:"nextRun"`
+	LastRun   int64  `json:"lastRun"`
+	LastCost  string `json:"lastCost"`
+	LastError string `json:"lastError"`
+}
+
+type job struct {
+	name    string
+	spec    string
+	fn      Func
+	lock    bool
+	lockTTL time.Duration
+	entryId robfig.EntryID
+
+	paused  int32
+	running int32
+
+	mu        sync.Mutex
+	lastRun   time.Time
+	lastCost  time.Duration
+	lastError string
+}
+
+var (
+	mu        sync.RWMutex
+	jobs      = map[string]*job{}
+	scheduler *robfig.Cron
+)
+
+// Register 注册任务，spec支持秒，eg: 0 */5 * * * * 或 @every 5m，需要在Start之前注册
+func Register(name, spec string, fn Func) {
+	mu.Lock()
+	defer mu.Unlock()
+	jobs[name] = &job{name: name, spec: spec, fn: fn, lock: true, lockTTL: defaultLockTTL}
+}
+
+// Start 按配置启动调度器
+func Start() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if scheduler != nil {
+		return nil
+	}
+	c := robfig.New(robfig.WithSeconds())
+	for name, j := range jobs {
+		if cfg, ok := config.Conf.CronConfig.Jobs[name]; ok {
+			if cfg.Spec != "" {
+				j.spec = cfg.Spec
+			}
+			if cfg.Lock != nil {
+				j.lock = *cfg.Lock
+			}
+			if cfg.LockTTL > 0 {
+				j.lockTTL = cfg.LockTTL
+			}
+			if cfg.Paused {
+				atomic.StoreInt32(&j.paused, 1)
+			}
+		}
+		j := j
+		id, err := c.AddFunc(j.spec, func() {
+			if atomic.LoadInt32(&j.paused) == 1 {
+				return
+			}
+			j.run()
+		})
+		if err != nil {
+			return fmt.Errorf("cron: add job %s: %w", name, err)
+		}
+		j.entryId = id
+	}
+	c.Start()
+	scheduler = c
+	zap.L().Info("cron started", zap.Int("jobs", len(jobs)))
+	return nil
+}
+
+// Stop 停止调度并等待执行中的任务完成
+func Stop(ctx context.Context) error {
+	mu.Lock()
+	c := scheduler
+	scheduler = nil
+	mu.Unlock()
+	if c == nil {
+		return nil
+	}
+	select {
+	case <-c.Stop().Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// List 所有任务的状态，按名称排序
+func List() []JobInfo {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]JobInfo, 0, len(jobs))
+	for _, j := range jobs {
+		j.mu.Lock()
+		info := JobInfo{
+			Name:      j.name,
+			Spec:      j.spec,
+			Paused:    atomic.LoadInt32(&j.paused) == 1,
+			Running:   atomic.LoadInt32(&j.running) == 1,
+			Lock:      j.lock,
+			LastError: j.lastError,
+		}
+		if !j.lastRun.IsZero() {
+			info.LastRun = j.lastRun.Unix()
+			info.LastCost = j.lastCost.String()
+		}
+		j.mu.Unlock()
+		if scheduler != nil && !info.Paused {
+			if next := scheduler.Entry(j.entryId).Next; !next.IsZero() {
+				info.NextRun = next.Unix()
+			}
+		}
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, k int) bool {
+		return list[i].Name < list[k].Name
+	})
+	return list
+}
+
+// Trigger 立即异步执行一次，暂停的任务也可以手动执行
+func Trigger(name string) error {
+	j, err := get(name)
+	if err != nil {
+		return err
+	}
+	go j.run()
+	return nil
+}
+
+// Pause 暂停任务，只影响当前实例
+func Pause(name string) error {
+	j, err := get(name)
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt32(&j.paused, 1)
+	return nil
+}
+
+// Resume 恢复暂停的任务
+func Resume(name string) error {
+	j, err := get(name)
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt32(&j.paused, 0)
+	return nil
+}
+
+func get(name string) (*job, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	j, ok := jobs[name]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return j, nil
+}
+
+// run 执行任务，上次未执行完时跳过，开启锁时只有获得锁的实例执行
+func (j *job) run() {
+	logger := zap.L().With(zap.String("cron", j.name))
+	if !atomic.CompareAndSwapInt32(&j.running, 0, 1) {
+		logger.Info("cron job still running, skip")
+		return
+	}
+	defer atomic.StoreInt32(&j.running, 0)
+
+	// 定时任务处理所有租户的数据
+	ctx := tenant.SkipContext(context.Background())
+	if j.lock {
+		lock := utils.NewDistributedLock("cron:"+j.name, j.lockTTL)
+		ok, err := lock.TryLock(ctx)
+		if err != nil {
+			logger.Error("cron job lock failed", zap.Error(err))
+			return
+		}
+		if !ok {
+			logger.Debug("cron job locked by other instance, skip")
+			return
+		}
+		defer func() {
+			_ = lock.Unlock(ctx)
+		}()
+	}
+
+	start := time.Now()
+	err := j.call(ctx)
+	cost := time.Since(start)
+
+	j.mu.Lock()
+	j.lastRun, j.lastCost, j.lastError = start, cost, ""
+	if err != nil {
+		j.lastError = err.Error()
+	}
+	j.mu.Unlock()
+
+	if err != nil {
+		logger.Error("cron job failed", zap.Duration("cost", cost), zap.Error(err))
+		return
+	}
+	logger.Info("cron job done", zap.Duration("cost", cost))
+}
+
+// call 执行任务方法，panic时返回错误
+func (j *job) call(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			zap.L().Error("cron job panic", zap.String("cron", j.name), zap.Any("panic", r), zap.String("stack", string(debug.Stack())))
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.fn(ctx)
+}
@@ -1,12 +1,61 @@
 package errors
 
 var (
+	//通用
+	InvalidParamsError = Register(1002, "参数错误")
+	NotFoundError      = Register(1003, "数据不存在")
+	InternalError      = Register(9999, "服务器内部错误")
 	//refreshToken相关
-	RefreshTokenError = NewError(1001, "refreshToken不合法")
+	RefreshTokenError = Register(1001, "refreshToken不合法")
 	//token相关
-	TokenExistError     = NewError(1004, "token不存在")
-	TokenRuntimeError   = NewError(1005, "token已过期")
-	TokenWrongError     = NewError(1006, "token不正确")
-	TokenTypeWrongError = NewError(1007, "token格式不正确")
-	CaptchaError        = NewError(1000, "验证码错误")
+	TokenExistError     = Register(1004, "token不存在")
+	TokenRuntimeError   = Register(1005, "token已过期")
+	TokenWrongError     = Register(1006, "token不正确")
+	TokenTypeWrongError = Register(1007, "token格式不正确")
+	TokenRevokedError   = Register(1008, "token已失效")
+	CaptchaError        = Register(1000, "验证码错误")
+	//权限相关
+	UnauthorizedError     = Register(1009, "请先登录")
+	PermissionDeniedError = Register(1010, "没有权限")
+	//限流
+	TooManyRequestsError = Register(1011, "访问太快了，请慢一点")
+	//上传
+	UploadSizeError      = Register(1012, "文件大小超出限制")
+	UploadTypeError      = Register(1013, "不支持的文件类型")
+	UploadImageSizeError = Register(1014, "图片尺寸超出限制")
+	UploadChunkError     = Register(1015, "文件分片不完整")
+	//文章
+	ArticleStatusError = Register(1016, "文章当前状态不允许该操作")
+	//评论
+	CommentRejectedError = Register(1017, "评论内容不合法")
+	//搜索
+	SearchUnavailableError = Register(1018, "搜索服务暂不可用")
+	//短信
+	SmsCodeError        = Register(1019, "短信验证码错误或已过期")
+	SmsTooFrequentError = Register(1020, "短信发送太频繁，请稍后再试")
+	//用户
+	LoginError        = Register(1021, "用户名或密码错误")
+	UserLockedError   = Register(1022, "登录失败次数过多，账号已锁定，请稍后再试")
+	UserDisabledError = Register(1023, "账号已被禁用")
+	OldPasswordError  = Register(1024, "原密码错误")
+	//第三方登录
+	OAuthStateError    = Register(1025, "授权已过期，请重新授权")
+	OAuthBoundError    = Register(1026, "该第三方账号已绑定其他用户")
+	OAuthProviderError = Register(1027, "不支持的第三方登录方式")
+	//参数校验
+	ValidationError = Register(1028, "参数校验失败")
+	//幂等
+	IdempotencyProcessingError = Register(1029, "请求正在处理中，请勿重复提交")
+	IdempotencyMismatchError   = Register(1030, "Idempotency-Key已用于其他请求")
+	//乐观锁
+	StaleObjectError = Register(1031, "数据已被修改，请刷新后重试")
+	//开放接口签名
+	SignatureError        = Register(1032, "签名错误")
+	SignatureExpiredError = Register(1033, "请求已过期或重复提交")
+	//内容审核
+	ContentRejectedError = Register(1034, "内容包含敏感词")
+	//请求体大小
+	RequestTooLargeError = Register(1035, "请求内容过大")
+	//websocket连接凭证
+	WsTicketError = Register(1036, "连接凭证无效或已过期")
 )
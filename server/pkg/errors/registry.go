@@ -0,0 +1,47 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// 错误码注册表，保证错误码唯一，并可根据错误码查询默认提示
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[int]*CodeError)
+)
+
+// Register 注册错误码，重复注册会panic，应在包初始化时调用
+func Register(code int, text string) *CodeError {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if e, ok := registry[code]; ok {
+		panic(fmt.Sprintf("errors: code %d already registered as %q", code, e.Message))
+	}
+	e := NewError(code, text)
+	registry[code] = e
+	return e
+}
+
+// Lookup 根据错误码查询注册的错误
+func Lookup(code int) (*CodeError, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	e, ok := registry[code]
+	return e, ok
+}
+
+// All 所有注册的错误，按错误码排序，可用于生成错误码文档
+func All() []*CodeError {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	list := make([]*CodeError, 0, len(registry))
+	for _, e := range registry {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Code < list[j].Code
+	})
+	return list
+}
@@ -0,0 +1,45 @@
+package errors
+
+import "net/http"
+
+// 错误码对应的http状态码，未配置的业务错误使用200
+var httpStatus = map[int]int{
+	InvalidParamsError.Code:         http.StatusBadRequest,
+	ValidationError.Code:            http.StatusUnprocessableEntity,
+	NotFoundError.Code:              http.StatusNotFound,
+	InternalError.Code:              http.StatusInternalServerError,
+	TokenExistError.Code:            http.StatusUnauthorized,
+	TokenRuntimeError.Code:          http.StatusUnauthorized,
+	TokenWrongError.Code:            http.StatusUnauthorized,
+	TokenTypeWrongError.Code:        http.StatusUnauthorized,
+	TokenRevokedError.Code:          http.StatusUnauthorized,
+	UnauthorizedError.Code:          http.StatusUnauthorized,
+	PermissionDeniedError.Code:      http.StatusForbidden,
+	TooManyRequestsError.Code:       http.StatusTooManyRequests,
+	SearchUnavailableError.Code:     http.StatusServiceUnavailable,
+	SmsTooFrequentError.Code:        http.StatusTooManyRequests,
+	IdempotencyProcessingError.Code: http.StatusConflict,
+	IdempotencyMismatchError.Code:   http.StatusUnprocessableEntity,
+	StaleObjectError.Code:           http.StatusConflict,
+	UserLockedError.Code:            http.StatusForbidden,
+	UserDisabledError.Code:          http.StatusForbidden,
+	RequestTooLargeError.Code:       http.StatusRequestEntityTooLarge,
+	WsTicketError.Code:              http.StatusUnauthorized,
+}
+
+// SetHttpStatus 设置错误码对应的http状态码
+func SetHttpStatus(code, status int) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	httpStatus[code] = status
+}
+
+// HttpStatus 错误码对应的http状态码
+func HttpStatus(code int) int {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if status, ok := httpStatus[code]; ok {
+		return status
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,181 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/queue"
+	"go-skeleton/pkg/tenant"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 进程内的事件总线，同步订阅者在Publish中依次执行，异步订阅者通过任务队列执行，eg:
+//
+//	eventbus.Subscribe(eventbus.UserRegistered{}, func(ctx context.Context, e eventbus.Event) error {
+//		user := e.(eventbus.UserRegistered)
+//		...
+//	})
+//	eventbus.SubscribeAsync(eventbus.ArticlePublished{}, "search.index", handler)
+//	eventbus.Publish(ctx, eventbus.ArticlePublished{ArticleId: id})
+//
+// 事件需要是可以json编码的结构体(非指针)，异步订阅者收到的是解码后的副本
+
+// Event 事件，EventName为事件名
+type Event interface {
+	EventName() string
+}
+
+// Handler 事件处理方法
+type Handler func(ctx context.Context, event Event) error
+
+// Middleware 包装处理方法，eg: 日志、recover
+type Middleware func(name string, next Handler) Handler
+
+type subscriber struct {
+	name    string
+	handler Handler
+	async   bool
+}
+
+var (
+	mu          sync.RWMutex
+	subscribers = make(map[string][]subscriber)
+	middlewares []Middleware
+)
+
+func init() {
+	Use(Recover(), Logging())
+}
+
+// Use 添加中间件，先添加的在外层，只对之后订阅的处理方法生效
+func Use(mws ...Middleware) {
+	mu.Lock()
+	defer mu.Unlock()
+	middlewares = append(middlewares, mws...)
+}
+
+// Subscribe 同步订阅，在Publish的协程中执行
+func Subscribe(event Event, handler Handler) {
+	subscribe(event, "", handler, false)
+}
+
+// SubscribeAsync 异步订阅，name在同一事件中唯一，作为任务类型event.{事件名}.{name}，
+// 开启任务队列时失败按队列配置重试，否则在协程中执行
+func SubscribeAsync(event Event, name string, handler Handler) {
+	subscribe(event, name, handler, true)
+}
+
+func subscribe(event Event, name string, handler Handler, async bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	eventName := event.EventName()
+	if name == "" {
+		name = fmt.Sprintf("%s#%d", eventName, len(subscribers[eventName]))
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](name, handler)
+	}
+	subscribers[eventName] = append(subscribers[eventName], subscriber{name: name, handler: handler, async: async})
+	if async {
+		registerJob(reflect.TypeOf(event), jobType(eventName, name), handler)
+	}
+}
+
+// Publish 发布事件，依次执行同步订阅者并投递异步订阅者，订阅者的错误不影响其他订阅者，返回第一个错误
+func Publish(ctx context.Context, event Event) error {
+	mu.RLock()
+	subs := subscribers[event.EventName()]
+	mu.RUnlock()
+
+	var first error
+	for _, sub := range subs {
+		var err error
+		if sub.async {
+			if err = dispatch(ctx, event, sub); err != nil {
+				zap.L().Error("eventbus: dispatch event failed", zap.String("event", event.EventName()),
+					zap.String("subscriber", sub.name), zap.Error(err))
+			}
+		} else {
+			err = sub.handler(ctx, event)
+		}
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func jobType(eventName, name string) string {
+	return "event." + eventName + "." + name
+}
+
+// registerJob 注册异步订阅者的任务处理方法，任务参数解码为事件类型
+func registerJob(typ reflect.Type, jobType string, handler Handler) {
+	queue.Handle(jobType, func(ctx context.Context, job *queue.Job) error {
+		ptr := reflect.New(typ)
+		if err := job.Bind(ptr.Interface()); err != nil {
+			return fmt.Errorf("%w: %v", queue.ErrSkipRetry, err)
+		}
+		return handler(ctx, ptr.Elem().Interface().(Event))
+	})
+}
+
+func dispatch(ctx context.Context, event Event, sub subscriber) error {
+	if config.Conf.QueueConfig.Enable {
+		_, err := queue.Enqueue(ctx, jobType(event.EventName(), sub.name), event)
+		return err
+	}
+	// 和队列一样传递副本，避免订阅者修改发布者的数据
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ptr := reflect.New(reflect.TypeOf(event))
+	if err := json.Unmarshal(b, ptr.Interface()); err != nil {
+		return err
+	}
+	go func() {
+		// 和队列任务一样不按租户过滤，订阅者按事件中的id处理数据
+		ctx, cancel := context.WithTimeout(tenant.SkipContext(context.Background()), time.Minute)
+		defer cancel()
+		_ = sub.handler(ctx, ptr.Elem().Interface().(Event))
+	}()
+	return nil
+}
+
+// Recover 处理方法panic时转为错误，不影响发布者和其他订阅者
+func Recover() Middleware {
+	return func(name string, next Handler) Handler {
+		return func(ctx context.Context, event Event) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("eventbus: %s panic: %v", name, r)
+				}
+			}()
+			return next(ctx, event)
+		}
+	}
+}
+
+// Logging 记录处理失败的事件和耗时
+func Logging() Middleware {
+	return func(name string, next Handler) Handler {
+		return func(ctx context.Context, event Event) error {
+			start := time.Now()
+			err := next(ctx, event)
+			if err != nil {
+				zap.L().Error("eventbus: handle event failed", zap.String("event", event.EventName()),
+					zap.String("subscriber", name), zap.Duration("elapsed", time.Since(start)), zap.Error(err))
+			} else {
+				zap.L().Debug("eventbus: event handled", zap.String("event", event.EventName()),
+					zap.String("subscriber", name), zap.Duration("elapsed", time.Since(start)))
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package eventbus
+
+// ArticlePublished 文章已发布
+type ArticlePublished struct {
+	ArticleId   int64 `json:"articleId"`
+	UserId      int64 `json:"userId"`
+	PublishedAt int64 `json:"publishedAt"`
+}
+
+func (e ArticlePublished) EventName() string {
+	return "article.published"
+}
+
+// UserRegistered 用户已注册，Provider为第三方登录的平台，账号密码注册时为空
+type UserRegistered struct {
+	UserId   int64  `json:"userId"`
+	Username string `json:"username"`
+	Provider string `json:"provider,omitempty"`
+}
+
+func (e UserRegistered) EventName() string {
+	return "user.registered"
+}
+
+// ExperimentExposed 用户在请求中命中了实验分组，用于统计各分组的转化
+type ExperimentExposed struct {
+	Experiment string `json:"experiment"`
+	Variant    string `json:"variant"`
+	UserId     int64  `json:"userId"`
+	ExposedAt  int64  `json:"exposedAt"`
+}
+
+func (e ExperimentExposed) EventName() string {
+	return "experiment.exposed"
+}
+
+// CommentCreated 发表了评论，ReplyUserId为被回复的用户，一级评论时为0
+type CommentCreated struct {
+	CommentId   int64  `json:"commentId"`
+	ArticleId   int64  `json:"articleId"`
+	UserId      int64  `json:"userId"`
+	ReplyUserId int64  `json:"replyUserId"`
+	Content     string `json:"content"`
+}
+
+func (e CommentCreated) EventName() string {
+	return "comment.created"
+}
+
+// ArticleLiked 文章被点赞
+type ArticleLiked struct {
+	ArticleId int64 `json:"articleId"`
+	UserId    int64 `json:"userId"`
+}
+
+func (e ArticleLiked) EventName() string {
+	return "article.liked"
+}
+
+// ModelChanged 表的数据有写入，Action为create、update或delete，由cache.Plugin发布，用于删除接口缓存
+type ModelChanged struct {
+	Table  string `json:"table"`
+	Action string `json:"action"`
+}
+
+func (e ModelChanged) EventName() string {
+	return "model.changed"
+}
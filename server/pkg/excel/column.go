@@ -0,0 +1,100 @@
+package excel
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TagName 结构体字段的excel标签，eg: `excel:"名称,width=20"`，没有标签的字段不导出也不导入
+const TagName = "excel"
+
+// 默认的列宽
+const defaultWidth = 16
+
+// Column 由结构体字段生成的列
+type Column struct {
+	// Title 表头，导入时按表头名称对应到字段
+	Title string
+	// Width 列宽
+	Width float64
+	// Field json名称，用于将校验错误对应到列
+	Field string
+
+	index []int
+}
+
+// Columns 解析结构体的excel标签，model为结构体或结构体指针，匿名嵌入的结构体会展开
+func Columns(model interface{}) ([]Column, error) {
+	typ := reflect.TypeOf(model)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("excel: model must be a struct, got %T", model)
+	}
+	columns, err := parseColumns(typ, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("excel: %s has no field with excel tag", typ)
+	}
+	return columns, nil
+}
+
+func parseColumns(typ reflect.Type, parent []int) ([]Column, error) {
+	var columns []Column
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		index := append(append([]int{}, parent...), i)
+		tag, ok := field.Tag.Lookup(TagName)
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				embedded, err := parseColumns(field.Type, index)
+				if err != nil {
+					return nil, err
+				}
+				columns = append(columns, embedded...)
+			}
+			continue
+		}
+		if tag == "-" || field.PkgPath != "" {
+			continue
+		}
+
+		col := Column{Width: defaultWidth, Field: jsonName(field), index: index}
+		parts := strings.Split(tag, ",")
+		col.Title = strings.TrimSpace(parts[0])
+		if col.Title == "" {
+			col.Title = field.Name
+		}
+		for _, opt := range parts[1:] {
+			kv := strings.SplitN(strings.TrimSpace(opt), "=", 2)
+			switch kv[0] {
+			case "width":
+				if len(kv) != 2 {
+					return nil, fmt.Errorf("excel: %s.%s: width requires a value", typ, field.Name)
+				}
+				width, err := strconv.ParseFloat(kv[1], 64)
+				if err != nil {
+					return nil, fmt.Errorf("excel: %s.%s: invalid width %q", typ, field.Name, kv[1])
+				}
+				col.Width = width
+			default:
+				return nil, fmt.Errorf("excel: %s.%s: unknown option %q", typ, field.Name, opt)
+			}
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
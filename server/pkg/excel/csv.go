@@ -0,0 +1,215 @@
+package excel
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// csv的编码
+const (
+	// EncodingUTF8 不带BOM的UTF-8
+	EncodingUTF8 = "utf-8"
+	// EncodingUTF8BOM 带BOM的UTF-8，Windows的Excel直接打开不会乱码
+	EncodingUTF8BOM = "utf-8-bom"
+	// EncodingGBK 兼容旧版本的Excel及WPS
+	EncodingGBK = "gbk"
+)
+
+// CSVContentType csv的MIME类型
+const CSVContentType = "text/csv"
+
+// ProgressInterval 导出时每写入多少行记录一次进度日志
+var ProgressInterval = 10000
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// bomWriter 第一次写入时先写入BOM，查询第一页出错时还没有任何输出
+type bomWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (b *bomWriter) Write(p []byte) (int, error) {
+	if !b.written {
+		b.written = true
+		if _, err := b.w.Write(utf8BOM); err != nil {
+			return 0, err
+		}
+	}
+	return b.w.Write(p)
+}
+
+// CSVWriter 按model的excel标签逐行写入csv，每次Write后刷新缓冲区，
+// out实现了http.Flusher时(eg: gin的响应)同时刷新到客户端
+type CSVWriter struct {
+	out     io.Writer
+	closer  io.Closer
+	csv     *csv.Writer
+	columns []Column
+	typ     reflect.Type
+	rows    int
+}
+
+// NewCSVWriter 创建CSVWriter并写入表头，charset为空时使用EncodingUTF8BOM
+func NewCSVWriter(out io.Writer, model interface{}, charset string) (*CSVWriter, error) {
+	columns, err := Columns(model)
+	if err != nil {
+		return nil, err
+	}
+	w := &CSVWriter{out: out, columns: columns, typ: structType(model)}
+
+	dst := out
+	switch strings.ToLower(charset) {
+	case "", EncodingUTF8BOM:
+		dst = &bomWriter{w: out}
+	case EncodingUTF8:
+	case EncodingGBK:
+		// GBK中没有的字符(eg: emoji)替换为替换字符，避免整个导出失败
+		tw := transform.NewWriter(out, encoding.ReplaceUnsupported(simplifiedchinese.GBK.NewEncoder()))
+		dst, w.closer = tw, tw
+	default:
+		return nil, fmt.Errorf("excel: unsupported csv encoding %q", charset)
+	}
+	w.csv = csv.NewWriter(dst)
+	// Excel按CRLF换行
+	w.csv.UseCRLF = true
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Title
+	}
+	if err := w.csv.Write(header); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write 写入一页数据，list为model类型的结构体切片或结构体指针切片
+func (w *CSVWriter) Write(list interface{}) error {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("excel: Write expects a slice, got %T", list)
+	}
+	record := make([]string, len(w.columns))
+	for i := 0; i < v.Len(); i++ {
+		item := reflect.Indirect(v.Index(i))
+		if item.Type() != w.typ {
+			return fmt.Errorf("excel: Write expects %s, got %s", w.typ, item.Type())
+		}
+		for j, col := range w.columns {
+			record[j] = EscapeCSV(cellString(item.FieldByIndex(col.index)))
+		}
+		if err := w.csv.Write(record); err != nil {
+			return err
+		}
+		w.rows++
+	}
+	return w.flush()
+}
+
+func (w *CSVWriter) flush() error {
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	// transform.Writer在Close时才写出剩余的数据，按行写入时每行都是完整的字符，不会有剩余
+	if f, ok := w.out.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// Rows 已写入的数据行数，不包括表头
+func (w *CSVWriter) Rows() int {
+	return w.rows
+}
+
+// Close 刷新缓冲区，不会关闭out
+func (w *CSVWriter) Close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if w.closer != nil {
+		return w.closer.Close()
+	}
+	return nil
+}
+
+// ExportCSV 逐页查询并写入csv，每写入ProgressInterval行记录一次进度，返回导出的行数
+func ExportCSV(out io.Writer, model interface{}, charset string, fetch Fetch) (int, error) {
+	w, err := NewCSVWriter(out, model, charset)
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	logged := 0
+	for page := 1; ; page++ {
+		list, err := fetch(page)
+		if err != nil {
+			return w.Rows(), err
+		}
+		v := reflect.ValueOf(list)
+		if !v.IsValid() || v.Len() == 0 {
+			break
+		}
+		if err := w.Write(list); err != nil {
+			return w.Rows(), err
+		}
+		if ProgressInterval > 0 && w.Rows()-logged >= ProgressInterval {
+			logged = w.Rows()
+			zap.L().Info("csv export progress", zap.String("model", w.typ.String()),
+				zap.Int("rows", logged), zap.Duration("elapsed", time.Since(start)))
+		}
+	}
+	if err := w.Close(); err != nil {
+		return w.Rows(), err
+	}
+	zap.L().Info("csv export finished", zap.String("model", w.typ.String()),
+		zap.Int("rows", w.Rows()), zap.Duration("elapsed", time.Since(start)))
+	return w.Rows(), nil
+}
+
+// EscapeCSV 以=、+、-、@、制表符、回车开头的内容前加上'，防止在Excel中被当作公式执行(CSV注入)，
+// 引号、逗号、换行由encoding/csv处理
+func EscapeCSV(s string) string {
+	if s == "" {
+		return s
+	}
+	switch s[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		// 负数不需要转义
+		if s[0] == '-' && isNumber(s) {
+			return s
+		}
+		return "'" + s
+	}
+	return s
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// cellString 字段转换为csv中的文本
+func cellString(v reflect.Value) string {
+	switch x := cellValue(v).(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	default:
+		return fmt.Sprint(x)
+	}
+}
@@ -0,0 +1,301 @@
+package excel
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-skeleton/utils"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// MaxRows 导入的最大行数(不包括表头)，超出时返回ErrTooManyRows
+var MaxRows = 100000
+
+// Validator 导入时对每一行执行的校验，使用valid tag
+var Validator = utils.NewValidator()
+
+var (
+	// ErrTooManyRows 超出MaxRows
+	ErrTooManyRows = errors.New("excel: too many rows")
+	// ErrEmptyFile 没有表头
+	ErrEmptyFile = errors.New("excel: empty file")
+)
+
+// 导入时支持的时间格式，01-02-06为excel日期单元格的默认显示格式
+var timeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02", "2006/01/02 15:04:05", "2006/01/02", "01-02-06"}
+
+// RowError 某一行的错误，Row为excel中的行号(表头为第1行)，Column为空时表示整行的错误
+type RowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("第%d行: %s", e.Row, e.Message)
+	}
+	return fmt.Sprintf("第%d行[%s]: %s", e.Row, e.Column, e.Message)
+}
+
+// Report 导入结果
+type Report struct {
+	Total   int        `json:"total"`
+	Success int        `json:"success"`
+	Errors  []RowError `json:"errors"`
+}
+
+// Failed 失败的行数
+func (r *Report) Failed() int {
+	return r.Total - r.Success
+}
+
+func (r *Report) addError(row int, column, message string) {
+	r.Errors = append(r.Errors, RowError{Row: row, Column: column, Message: message})
+}
+
+// Handler 处理一行数据，v为model类型的结构体指针，返回的错误记录到导入结果中，不会中断导入
+type Handler func(row int, v interface{}) error
+
+// Import 读取第一个工作表，第一行为表头，按表头名称对应到model的excel标签，
+// 每一行转换并校验通过后调用handle，转换、校验及handle的错误按行收集到Report中，
+// 只有文件本身无法读取时才返回error
+func Import(r io.Reader, model interface{}, handle Handler) (*Report, error) {
+	columns, err := Columns(model)
+	if err != nil {
+		return nil, err
+	}
+	typ := structType(model)
+
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("excel: %w", err)
+	}
+	rows, err := file.Rows(file.GetSheetName(0))
+	if err != nil {
+		return nil, fmt.Errorf("excel: %w", err)
+	}
+	if !rows.Next() {
+		return nil, ErrEmptyFile
+	}
+	header, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("excel: %w", err)
+	}
+	// 表头中每一列对应的字段，没有对应字段的列忽略
+	mapping := make([]*Column, len(header))
+	for i, title := range header {
+		for j := range columns {
+			if columns[j].Title == strings.TrimSpace(title) {
+				mapping[i] = &columns[j]
+				break
+			}
+		}
+	}
+
+	report := &Report{Errors: []RowError{}}
+	for line := 2; rows.Next(); line++ {
+		cells, err := rows.Columns()
+		if err != nil {
+			return report, fmt.Errorf("excel: %w", err)
+		}
+		if isBlank(cells) {
+			continue
+		}
+		if report.Total >= MaxRows {
+			return report, ErrTooManyRows
+		}
+		report.Total++
+
+		v := reflect.New(typ)
+		if !decodeRow(v.Elem(), mapping, cells, line, report) || !validateRow(v.Interface(), columns, line, report) {
+			continue
+		}
+		if err := handle(line, v.Interface()); err != nil {
+			report.addError(line, "", err.Error())
+			continue
+		}
+		report.Success++
+	}
+	if err := rows.Error(); err != nil {
+		return report, fmt.Errorf("excel: %w", err)
+	}
+	return report, nil
+}
+
+// Read 读取所有合法的行到out，out为结构体切片的指针，eg: var list []TagRow; Read(r, &list)
+func Read(r io.Reader, out interface{}) (*Report, error) {
+	ptr := reflect.ValueOf(out)
+	if ptr.Kind() != reflect.Ptr || ptr.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("excel: Read expects a pointer to slice, got %T", out)
+	}
+	slice := ptr.Elem()
+	elem := slice.Type().Elem()
+	isPtr := elem.Kind() == reflect.Ptr
+	model := reflect.Zero(elem).Interface()
+	if isPtr {
+		model = reflect.New(elem.Elem()).Interface()
+	}
+	return Import(r, model, func(row int, v interface{}) error {
+		item := reflect.ValueOf(v)
+		if !isPtr {
+			item = item.Elem()
+		}
+		slice.Set(reflect.Append(slice, item))
+		return nil
+	})
+}
+
+func decodeRow(v reflect.Value, mapping []*Column, cells []string, line int, report *Report) bool {
+	ok := true
+	for i, cell := range cells {
+		if i >= len(mapping) || mapping[i] == nil {
+			continue
+		}
+		cell = strings.TrimSpace(cell)
+		if cell == "" {
+			continue
+		}
+		if err := setValue(v.FieldByIndex(mapping[i].index), cell); err != nil {
+			report.addError(line, mapping[i].Title, err.Error())
+			ok = false
+		}
+	}
+	return ok
+}
+
+func validateRow(v interface{}, columns []Column, line int, report *Report) bool {
+	fields, err := Validator.ValidateFields(v)
+	if err != nil {
+		report.addError(line, "", err.Error())
+		return false
+	}
+	if len(fields) == 0 {
+		return true
+	}
+	for _, col := range columns {
+		if msg, ok := fields[col.Field]; ok {
+			report.addError(line, col.Title, msg)
+			delete(fields, col.Field)
+		}
+	}
+	// 没有对应列的字段
+	for field, msg := range fields {
+		report.addError(line, field, msg)
+	}
+	return false
+}
+
+// setValue 将单元格的文本转换为字段的类型
+func setValue(field reflect.Value, s string) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+	// time.Time及嵌入了time.Time的类型(eg: model.Time)按timeLayouts解析
+	if i := timeFieldIndex(field.Type()); i != nil {
+		t, err := parseTime(s)
+		if err != nil {
+			return err
+		}
+		field.FieldByIndex(i).Set(reflect.ValueOf(t))
+		return nil
+	}
+	if field.CanAddr() {
+		switch u := field.Addr().Interface().(type) {
+		case encoding.TextUnmarshaler:
+			return u.UnmarshalText([]byte(s))
+		case json.Unmarshaler:
+			// eg: utils.Money
+			return u.UnmarshalJSON([]byte(strconv.Quote(s)))
+		}
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Bool:
+		switch strings.ToLower(s) {
+		case "1", "true", "yes", "y", "是":
+			field.SetBool(true)
+		case "0", "false", "no", "n", "否":
+			field.SetBool(false)
+		default:
+			return fmt.Errorf("无效的布尔值%q", s)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(trimDecimal(s), 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("无效的整数%q", s)
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(trimDecimal(s), 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("无效的整数%q", s)
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("无效的数字%q", s)
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("不支持的字段类型%s", field.Type())
+	}
+	return nil
+}
+
+// trimDecimal excel中的整数可能带有.0
+func trimDecimal(s string) string {
+	if i := strings.IndexByte(s, '.'); i >= 0 && strings.Trim(s[i+1:], "0") == "" {
+		return s[:i]
+	}
+	return s
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无效的时间%q", s)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// timeFieldIndex time.Time返回空的下标，嵌入了time.Time的类型返回嵌入字段的下标，其他类型返回nil
+func timeFieldIndex(typ reflect.Type) []int {
+	if typ == timeType {
+		return []int{}
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if f := typ.Field(i); f.Anonymous && f.Type == timeType {
+			return []int{i}
+		}
+	}
+	return nil
+}
+
+func isBlank(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package excel
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// DefaultSheet 默认的工作表名称
+	DefaultSheet = "Sheet1"
+	// ContentType xlsx的MIME类型
+	ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// TimeFormat 导出时间字段的格式
+var TimeFormat = "2006-01-02 15:04:05"
+
+// headerStyle 表头样式：加粗、灰色背景、居中、带边框
+var headerStyle = &excelize.Style{
+	Font:      &excelize.Font{Bold: true},
+	Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#E7E6E6"}},
+	Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+	Border: []excelize.Border{
+		{Type: "left", Color: "#BFBFBF", Style: 1},
+		{Type: "top", Color: "#BFBFBF", Style: 1},
+		{Type: "right", Color: "#BFBFBF", Style: 1},
+		{Type: "bottom", Color: "#BFBFBF", Style: 1},
+	},
+}
+
+// Writer 使用流式写入生成xlsx，数据写入临时文件而不是全部保存在内存中，适合10万行以上的导出
+type Writer struct {
+	file    *excelize.File
+	stream  *excelize.StreamWriter
+	columns []Column
+	typ     reflect.Type
+	// 下一行的行号，从1开始
+	row int
+}
+
+// NewWriter 按model的excel标签创建Writer并写入表头
+func NewWriter(model interface{}) (*Writer, error) {
+	columns, err := Columns(model)
+	if err != nil {
+		return nil, err
+	}
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter(DefaultSheet)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{file: file, stream: stream, columns: columns, typ: structType(model), row: 1}
+	if err := w.writeHeader(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) writeHeader() error {
+	style, err := w.file.NewStyle(headerStyle)
+	if err != nil {
+		return err
+	}
+	header := make([]interface{}, len(w.columns))
+	for i, col := range w.columns {
+		// 列宽必须在写入行之前设置
+		if err := w.stream.SetColWidth(i+1, i+1, col.Width); err != nil {
+			return err
+		}
+		header[i] = excelize.Cell{StyleID: style, Value: col.Title}
+	}
+	return w.setRow(header, excelize.RowOpts{Height: 20})
+}
+
+// Write 写入一页数据，list为model类型的结构体切片或结构体指针切片
+func (w *Writer) Write(list interface{}) error {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("excel: Write expects a slice, got %T", list)
+	}
+	values := make([]interface{}, len(w.columns))
+	for i := 0; i < v.Len(); i++ {
+		item := reflect.Indirect(v.Index(i))
+		if item.Type() != w.typ {
+			return fmt.Errorf("excel: Write expects %s, got %s", w.typ, item.Type())
+		}
+		for j, col := range w.columns {
+			values[j] = cellValue(item.FieldByIndex(col.index))
+		}
+		if err := w.setRow(values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Writer) setRow(values []interface{}, opts ...excelize.RowOpts) error {
+	axis, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	if err := w.stream.SetRow(axis, values, opts...); err != nil {
+		return err
+	}
+	w.row++
+	return nil
+}
+
+// Rows 已写入的数据行数，不包括表头
+func (w *Writer) Rows() int {
+	return w.row - 2
+}
+
+// WriteTo 结束写入并输出xlsx，之后不能再调用Write
+func (w *Writer) WriteTo(out io.Writer) (int64, error) {
+	if err := w.stream.Flush(); err != nil {
+		return 0, err
+	}
+	return w.file.WriteTo(out)
+}
+
+// Fetch 按页查询数据，page从1开始，返回空切片时结束导出，
+// 数据量大时建议在闭包中记录最后一条的id按id分页，避免offset越往后越慢
+type Fetch func(page int) (interface{}, error)
+
+// Export 逐页查询并导出为xlsx，返回导出的行数
+func Export(out io.Writer, model interface{}, fetch Fetch) (int, error) {
+	w, err := NewWriter(model)
+	if err != nil {
+		return 0, err
+	}
+	for page := 1; ; page++ {
+		list, err := fetch(page)
+		if err != nil {
+			return w.Rows(), err
+		}
+		v := reflect.ValueOf(list)
+		if !v.IsValid() || v.Len() == 0 {
+			break
+		}
+		if err := w.Write(list); err != nil {
+			return w.Rows(), err
+		}
+	}
+	_, err = w.WriteTo(out)
+	return w.Rows(), err
+}
+
+// cellValue 字段转换为单元格的值，数字、字符串、布尔原样写入，时间按TimeFormat格式化，实现了String方法的类型使用String
+func cellValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch x := v.Interface().(type) {
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		return x.Local().Format(TimeFormat)
+	case fmt.Stringer:
+		return x.String()
+	}
+	switch v.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return v.Interface()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func structType(model interface{}) reflect.Type {
+	typ := reflect.TypeOf(model)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
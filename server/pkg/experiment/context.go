@@ -0,0 +1,100 @@
+package experiment
+
+import (
+	"context"
+	"go-skeleton/pkg/eventbus"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AssignmentsKey 请求的分组结果在gin.Context中的key
+const AssignmentsKey = "experiment"
+
+type ctxKey struct{}
+
+// Assignments 一次请求中用户的分组，同一个实验只计算和发布一次曝光
+type Assignments struct {
+	userId func() int64
+
+	mu       sync.Mutex
+	variants map[string]string
+}
+
+// NewAssignments 创建分组结果，userId在第一次获取分组时才调用，中间件可以在登录校验之前注册
+func NewAssignments(userId func() int64) *Assignments {
+	return &Assignments{userId: userId, variants: map[string]string{}}
+}
+
+// Variant 用户在实验中的分组，实验不存在时返回空字符串。
+// 用户参与实验时第一次获取会发布曝光事件，未参与时返回对照组且不发布
+func (a *Assignments) Variant(ctx context.Context, name string) string {
+	e := Get(name)
+	if e == nil {
+		return ""
+	}
+	uid := a.userId()
+
+	a.mu.Lock()
+	if v, ok := a.variants[name]; ok {
+		a.mu.Unlock()
+		return v
+	}
+	v, ok := e.Assign(uid)
+	a.variants[name] = v
+	a.mu.Unlock()
+
+	if ok {
+		err := eventbus.Publish(ctx, eventbus.ExperimentExposed{
+			Experiment: name,
+			Variant:    v,
+			UserId:     uid,
+			ExposedAt:  time.Now().Unix(),
+		})
+		if err != nil {
+			zap.L().Warn("publish experiment exposure failed", zap.String("experiment", name), zap.Error(err))
+		}
+	}
+	return v
+}
+
+// All 用户在所有实验中的分组，不发布曝光事件，eg: 返回给前端时由前端在展示时上报
+func (a *Assignments) All() map[string]string {
+	uid := a.userId()
+	ret := make(map[string]string, len(experiments))
+	for name, e := range experiments {
+		ret[name], _ = e.Assign(uid)
+	}
+	return ret
+}
+
+// NewContext 将分组结果保存到context
+func NewContext(ctx context.Context, a *Assignments) context.Context {
+	return context.WithValue(ctx, ctxKey{}, a)
+}
+
+// FromContext 获取context中的分组结果，不存在时返回nil
+func FromContext(ctx context.Context) *Assignments {
+	if ctx == nil {
+		return nil
+	}
+	if a, ok := ctx.Value(ctxKey{}).(*Assignments); ok {
+		return a
+	}
+	if a, ok := ctx.Value(AssignmentsKey).(*Assignments); ok {
+		return a
+	}
+	return nil
+}
+
+// VariantFor 获取ctx中的用户在实验中的分组，未注册中间件时按未登录用户返回对照组
+func VariantFor(ctx context.Context, name string) string {
+	if a := FromContext(ctx); a != nil {
+		return a.Variant(ctx, name)
+	}
+	if e := Get(name); e != nil {
+		return e.Control()
+	}
+	return ""
+}
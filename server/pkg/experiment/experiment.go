@@ -0,0 +1,123 @@
+package experiment
+
+import (
+	"fmt"
+	"go-skeleton/pkg/config"
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// A/B实验，按用户id和实验名称的hash分组，同一个用户在同一个实验中的分组固定，eg:
+//
+//	switch experiment.VariantFor(c, "new_checkout") {
+//	case "one_page":
+//	default: // control
+//	}
+//
+// 第一次获取分组时通过事件总线发布eventbus.ExperimentExposed，用于统计各分组的转化
+
+// Variant 实验分组
+type Variant struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+}
+
+// Experiment 实验，Variants的第一个分组为对照组
+type Experiment struct {
+	Name     string    `json:"name"`
+	Enabled  bool      `json:"enabled"`
+	Variants []Variant `json:"variants"`
+	total    int
+}
+
+// New 创建实验，至少需要一个分组，权重不能为负数且总和大于0
+func New(name string, enabled bool, variants ...Variant) (*Experiment, error) {
+	if len(variants) == 0 {
+		return nil, fmt.Errorf("experiment: %s has no variants", name)
+	}
+	e := &Experiment{Name: name, Enabled: enabled, Variants: variants}
+	seen := make(map[string]bool, len(variants))
+	for _, v := range variants {
+		if v.Name == "" || seen[v.Name] {
+			return nil, fmt.Errorf("experiment: %s has empty or duplicate variant %q", name, v.Name)
+		}
+		if v.Weight < 0 {
+			return nil, fmt.Errorf("experiment: %s variant %s has negative weight", name, v.Name)
+		}
+		seen[v.Name] = true
+		e.total += v.Weight
+	}
+	if e.total <= 0 {
+		return nil, fmt.Errorf("experiment: %s total weight is 0", name)
+	}
+	return e, nil
+}
+
+// Control 对照组
+func (e *Experiment) Control() string {
+	return e.Variants[0].Name
+}
+
+// Assign 用户所在的分组，实验未开启或未登录时返回对照组，ok为false表示未参与实验
+func (e *Experiment) Assign(userId int64) (variant string, ok bool) {
+	if !e.Enabled || userId <= 0 {
+		return e.Control(), false
+	}
+	n := int(bucket(e.Name, userId) % uint32(e.total))
+	for _, v := range e.Variants {
+		if n < v.Weight {
+			return v.Name, true
+		}
+		n -= v.Weight
+	}
+	return e.Control(), false
+}
+
+func bucket(name string, userId int64) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{':'})
+	_, _ = h.Write([]byte(strconv.FormatInt(userId, 10)))
+	return h.Sum32()
+}
+
+var experiments = map[string]*Experiment{}
+
+// Init 加载配置文件experiment.Experiments中的实验
+func Init() error {
+	loaded := make(map[string]*Experiment, len(config.Conf.ExperimentConfig.Experiments))
+	for name, item := range config.Conf.ExperimentConfig.Experiments {
+		variants := make([]Variant, 0, len(item.Variants))
+		for _, v := range item.Variants {
+			variants = append(variants, Variant{Name: v.Name, Weight: v.Weight})
+		}
+		e, err := New(name, item.Enabled, variants...)
+		if err != nil {
+			return err
+		}
+		loaded[name] = e
+	}
+	experiments = loaded
+	return nil
+}
+
+// Register 注册实验，同名时覆盖，需要在启动时调用
+func Register(e *Experiment) {
+	experiments[e.Name] = e
+}
+
+// Get 实验，不存在时返回nil
+func Get(name string) *Experiment {
+	return experiments[name]
+}
+
+// List 所有实验，按名称排序
+func List() []*Experiment {
+	list := make([]*Experiment, 0, len(experiments))
+	for _, e := range experiments {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
@@ -0,0 +1,58 @@
+package featureflag
+
+import (
+	"context"
+	"sync"
+)
+
+// SubjectKey 判断开关的用户在gin.Context中的key
+const SubjectKey = "featureflag"
+
+type ctxKey struct{}
+
+// SubjectFunc 获取判断开关的用户，在第一次判断开关时才调用，
+// 中间件可以在登录校验之前注册
+type SubjectFunc func() *Subject
+
+// NewContext 将用户保存到context，eg: 队列任务中按任务所属的用户判断开关
+func NewContext(ctx context.Context, s *Subject) context.Context {
+	return context.WithValue(ctx, ctxKey{}, SubjectFunc(func() *Subject { return s }))
+}
+
+// WithSubjectFunc 将获取用户的方法保存到context
+func WithSubjectFunc(ctx context.Context, fn SubjectFunc) context.Context {
+	return context.WithValue(ctx, ctxKey{}, fn)
+}
+
+// FromContext 获取context中的用户，不存在时返回nil
+func FromContext(ctx context.Context) *Subject {
+	if ctx == nil {
+		return nil
+	}
+	if fn, ok := ctx.Value(ctxKey{}).(SubjectFunc); ok {
+		return fn()
+	}
+	if fn, ok := ctx.Value(SubjectKey).(SubjectFunc); ok {
+		return fn()
+	}
+	return nil
+}
+
+// CachedSubject 缓存key相同时的结果，key变化时重新获取，eg: 登录校验之后用户id才确定
+func CachedSubject(key func() int64, load func(key int64) *Subject) SubjectFunc {
+	var (
+		mu      sync.Mutex
+		loaded  bool
+		lastKey int64
+		subject *Subject
+	)
+	return func() *Subject {
+		k := key()
+		mu.Lock()
+		defer mu.Unlock()
+		if !loaded || k != lastKey {
+			subject, lastKey, loaded = load(k), k, true
+		}
+		return subject
+	}
+}
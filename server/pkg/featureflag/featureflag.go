@@ -0,0 +1,286 @@
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-skeleton/model"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/simpleDb"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// 功能开关，eg:
+//	if featureflag.Enabled(c, "new_editor") {}
+// 开关来自配置文件feature.Flags和feature_flag表，表中的开关缓存在redis中，
+// 后台修改后调用Invalidate清除缓存并通知所有实例重新加载
+
+// cacheKey feature_flag表中开关的redis缓存
+const cacheKey = "featureflag:flags"
+
+// Manager 开关集合，Reload后整体替换，可以并发使用
+type Manager struct {
+	cfg   config.FeatureConfig
+	rdb   *redis.Client
+	flags atomic.Value
+
+	pubsub *redis.PubSub
+	quit   chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+var defaultManager *Manager
+
+// New 按配置创建开关集合并加载，rdb为nil时不使用缓存和失效通知
+func New(cfg config.FeatureConfig, rdb *redis.Client) (*Manager, error) {
+	if cfg.Channel == "" {
+		cfg.Channel = "featureflag:invalidate"
+	}
+	m := &Manager{cfg: cfg, rdb: rdb, quit: make(chan struct{})}
+	m.flags.Store(map[string]*Flag{})
+	if err := m.Reload(context.Background()); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Init 按配置文件feature创建默认的开关集合，订阅失效通知，配置了ReloadInterval时定期重新加载
+func Init(rdb *redis.Client) error {
+	m, err := New(config.Conf.FeatureConfig, rdb)
+	if err != nil {
+		return err
+	}
+	if err := m.Subscribe(context.Background()); err != nil {
+		return err
+	}
+	if m.cfg.ReloadInterval > 0 {
+		m.Start()
+	}
+	defaultManager = m
+	return nil
+}
+
+// Default 默认的开关集合，未启用时为nil
+func Default() *Manager {
+	return defaultManager
+}
+
+// Stop 停止默认开关集合的定期加载和订阅
+func Stop(ctx context.Context) error {
+	if defaultManager == nil {
+		return nil
+	}
+	return defaultManager.Stop(ctx)
+}
+
+// Enabled 开关对ctx中的用户是否开启，未启用功能开关或开关不存在时返回false
+func Enabled(ctx context.Context, name string) bool {
+	if defaultManager == nil {
+		return false
+	}
+	return defaultManager.Enabled(ctx, name)
+}
+
+// All ctx中的用户所有开关的状态，eg: 返回给前端控制页面展示
+func All(ctx context.Context) map[string]bool {
+	if defaultManager == nil {
+		return map[string]bool{}
+	}
+	return defaultManager.All(ctx)
+}
+
+// Invalidate 清除缓存并通知所有实例重新加载默认的开关集合，后台修改feature_flag表之后调用
+func Invalidate(ctx context.Context) error {
+	if defaultManager == nil {
+		return nil
+	}
+	return defaultManager.Invalidate(ctx)
+}
+
+// Get 开关的配置，不存在时返回nil
+func (m *Manager) Get(name string) *Flag {
+	return m.flags.Load().(map[string]*Flag)[name]
+}
+
+// List 所有开关，按名称排序
+func (m *Manager) List() []Flag {
+	flags := m.flags.Load().(map[string]*Flag)
+	list := make([]Flag, 0, len(flags))
+	for _, f := range flags {
+		list = append(list, *f)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Enabled 开关对ctx中的用户是否开启
+func (m *Manager) Enabled(ctx context.Context, name string) bool {
+	f := m.Get(name)
+	if f == nil {
+		return false
+	}
+	return f.Match(FromContext(ctx))
+}
+
+// All ctx中的用户所有开关的状态
+func (m *Manager) All(ctx context.Context) map[string]bool {
+	s := FromContext(ctx)
+	flags := m.flags.Load().(map[string]*Flag)
+	ret := make(map[string]bool, len(flags))
+	for name, f := range flags {
+		ret[name] = f.Match(s)
+	}
+	return ret
+}
+
+// Reload 加载配置文件和feature_flag表中的开关，加载失败时继续使用原来的开关
+func (m *Manager) Reload(ctx context.Context) error {
+	flags := make(map[string]*Flag, len(m.cfg.Flags))
+	for name, c := range m.cfg.Flags {
+		flags[name] = &Flag{
+			Name:       name,
+			Enabled:    c.Enabled,
+			Percentage: c.Percentage,
+			Users:      c.Users,
+			Roles:      c.Roles,
+		}
+	}
+	if m.cfg.Db {
+		list, err := m.loadDb(ctx)
+		if err != nil {
+			return err
+		}
+		for i := range list {
+			flags[list[i].Name] = &list[i]
+		}
+	}
+	m.flags.Store(flags)
+	zap.L().Debug("feature flags loaded", zap.Int("count", len(flags)))
+	return nil
+}
+
+// loadDb 读取feature_flag表中的开关，优先读取redis缓存
+func (m *Manager) loadDb(ctx context.Context) ([]Flag, error) {
+	if m.rdb != nil {
+		b, err := m.rdb.Get(ctx, cacheKey).Bytes()
+		if err == nil {
+			var list []Flag
+			if err := json.Unmarshal(b, &list); err == nil {
+				return list, nil
+			}
+		} else if err != redis.Nil {
+			zap.L().Warn("feature flags cache get failed", zap.Error(err))
+		}
+	}
+
+	var rows []model.FeatureFlag
+	if err := simpleDb.WithContext(ctx).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("featureflag: load flags from db: %w", err)
+	}
+	list := make([]Flag, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, Flag{
+			Name:        row.Name,
+			Description: row.Description,
+			Enabled:     row.Enabled,
+			Percentage:  row.Percentage,
+			Users:       ParseUsers(row.Users),
+			Roles:       ParseRoles(row.Roles),
+		})
+	}
+
+	if m.rdb != nil {
+		b, _ := json.Marshal(list)
+		if err := m.rdb.Set(ctx, cacheKey, b, m.cfg.CacheTTL).Err(); err != nil {
+			zap.L().Warn("feature flags cache set failed", zap.Error(err))
+		}
+	}
+	return list, nil
+}
+
+// Invalidate 清除redis缓存，重新加载并通知其他实例
+func (m *Manager) Invalidate(ctx context.Context) error {
+	if m.rdb != nil {
+		if err := m.rdb.Del(ctx, cacheKey).Err(); err != nil {
+			return err
+		}
+	}
+	if err := m.Reload(ctx); err != nil {
+		return err
+	}
+	if m.rdb != nil {
+		return m.rdb.Publish(ctx, m.cfg.Channel, time.Now().Unix()).Err()
+	}
+	return nil
+}
+
+// Subscribe 订阅失效通知，收到后重新加载
+func (m *Manager) Subscribe(ctx context.Context) error {
+	if m.rdb == nil {
+		return nil
+	}
+	pubsub := m.rdb.Subscribe(ctx, m.cfg.Channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return err
+	}
+	m.pubsub = pubsub
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		for range pubsub.Channel() {
+			if err := m.Reload(context.Background()); err != nil {
+				zap.L().Error("feature flags reload failed", zap.Error(err))
+			}
+		}
+	}()
+	return nil
+}
+
+// Start 定期重新加载，失效通知丢失时也能在ReloadInterval后生效
+func (m *Manager) Start() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.cfg.ReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.quit:
+				return
+			case <-ticker.C:
+				if err := m.Reload(context.Background()); err != nil {
+					zap.L().Error("feature flags reload failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止定期加载并取消订阅
+func (m *Manager) Stop(ctx context.Context) error {
+	m.once.Do(func() {
+		close(m.quit)
+		if m.pubsub != nil {
+			_ = m.pubsub.Close()
+		}
+	})
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
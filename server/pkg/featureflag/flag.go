@@ -0,0 +1,81 @@
+package featureflag
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Flag 功能开关，Enabled为总开关，开启后Users、Roles中的用户直接命中，
+// 其他用户按Percentage灰度，同一个用户在同一个开关下的结果是固定的
+type Flag struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	Percentage  int      `json:"percentage"`
+	Users       []int64  `json:"users"`
+	Roles       []string `json:"roles"`
+}
+
+// Subject 判断开关的用户，UserId为0表示未登录
+type Subject struct {
+	UserId int64
+	Roles  []string
+}
+
+// Match 开关对用户是否开启，s为nil时按未登录处理，未登录的用户只在Percentage为100时开启
+func (f *Flag) Match(s *Subject) bool {
+	if f == nil || !f.Enabled {
+		return false
+	}
+	if f.Percentage >= 100 {
+		return true
+	}
+	if s == nil || s.UserId <= 0 {
+		return false
+	}
+	for _, id := range f.Users {
+		if id == s.UserId {
+			return true
+		}
+	}
+	for _, role := range f.Roles {
+		for _, owned := range s.Roles {
+			if role == owned {
+				return true
+			}
+		}
+	}
+	return f.Percentage > 0 && bucket(f.Name, s.UserId) < f.Percentage
+}
+
+// bucket 用户在开关下的分桶0~99，加上开关名称避免同一批用户总是先拿到所有新功能
+func bucket(name string, userId int64) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{':'})
+	_, _ = h.Write([]byte(strconv.FormatInt(userId, 10)))
+	return int(h.Sum32() % 100)
+}
+
+// ParseUsers 解析逗号分隔的用户id，忽略无效的值
+func ParseUsers(s string) []int64 {
+	var ids []int64
+	for _, part := range strings.Split(s, ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64); err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// ParseRoles 解析逗号分隔的角色code
+func ParseRoles(s string) []string {
+	var roles []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			roles = append(roles, part)
+		}
+	}
+	return roles
+}
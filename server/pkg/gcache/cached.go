@@ -0,0 +1,152 @@
+package gcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/pkg/tenant"
+	"math/rand"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNotFound 数据不存在，不存在的结果也会被短暂缓存，防止缓存穿透
+var ErrNotFound = errors.New("gcache: not found")
+
+// 不存在的数据在缓存中的占位值
+var nullValue = []byte("null")
+
+// Cached 缓存旁路(cache-aside)读取，先查redis，未命中再通过load加载并回写。
+// 数据使用json序列化，json:"-"的字段不会被缓存，这类模型不要使用
+type Cached struct {
+	prefix  string
+	ttl     time.Duration
+	jitter  time.Duration
+	nullTTL time.Duration
+	group   singleflight.Group
+	// 按租户区分key
+	perTenant bool
+}
+
+// NewCached 创建缓存，prefix为key前缀，ttl为过期时间，实际过期时间会增加0~ttl/10的随机值，避免同时失效
+func NewCached(prefix string, ttl time.Duration) *Cached {
+	return &Cached{
+		prefix:  prefix,
+		ttl:     ttl,
+		jitter:  ttl / 10,
+		nullTTL: time.Minute,
+	}
+}
+
+// PerTenant 按ctx中的租户区分缓存，用于id在租户间重复或数据按租户统计的缓存，eg: cache:dashboard:t1:stats
+func (c *Cached) PerTenant() *Cached {
+	c.perTenant = true
+	return c
+}
+
+// Key 生成缓存key，eg: cache:article:1
+func (c *Cached) Key(id interface{}) string {
+	return fmt.Sprintf("cache:%s:%v", c.prefix, id)
+}
+
+// key 开启PerTenant且ctx中有租户时加上租户id
+func (c *Cached) key(ctx context.Context, id interface{}) string {
+	if c.perTenant {
+		if tid, ok := tenant.FromContext(ctx); ok && !tenant.Skipped(ctx) {
+			return fmt.Sprintf("cache:%s:t%d:%v", c.prefix, tid, id)
+		}
+	}
+	return c.Key(id)
+}
+
+// Get 读取id对应的数据到out，load返回nil表示数据不存在，此时返回ErrNotFound。
+// 同一个key的并发加载会被合并为一次(singleflight)，避免缓存击穿
+func (c *Cached) Get(ctx context.Context, id interface{}, out interface{}, load func() (interface{}, error)) error {
+	key := c.key(ctx, id)
+	rdb := gredis.GetRedis()
+
+	if rdb != nil {
+		b, err := rdb.Get(ctx, key).Bytes()
+		if err == nil {
+			return c.decode(b, out)
+		}
+		if err != redis.Nil {
+			zap.L().Warn("gcache get failed", zap.String("key", key), zap.Error(err))
+		}
+	}
+
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		val, err := load()
+		if err != nil {
+			return nil, err
+		}
+
+		b := nullValue
+		ttl := c.nullTTL
+		if val != nil {
+			if b, err = json.Marshal(val); err != nil {
+				return nil, err
+			}
+			ttl = c.expiration()
+		}
+		if rdb != nil {
+			if err := rdb.Set(ctx, key, b, ttl).Err(); err != nil {
+				zap.L().Warn("gcache set failed", zap.String("key", key), zap.Error(err))
+			}
+		}
+		return b, nil
+	})
+	if err != nil {
+		return err
+	}
+	return c.decode(v.([]byte), out)
+}
+
+// Set 直接写入缓存，用于定时任务等主动刷新的场景
+func (c *Cached) Set(ctx context.Context, id interface{}, val interface{}) error {
+	rdb := gredis.GetRedis()
+	if rdb == nil {
+		return nil
+	}
+	b, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	key := c.key(ctx, id)
+	c.group.Forget(key)
+	return rdb.Set(ctx, key, b, c.expiration()).Err()
+}
+
+// Del 删除缓存，数据更新或删除后调用
+func (c *Cached) Del(ctx context.Context, ids ...interface{}) error {
+	rdb := gredis.GetRedis()
+	if rdb == nil || len(ids) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		key := c.key(ctx, id)
+		keys = append(keys, key)
+		c.group.Forget(key)
+	}
+	return rdb.Del(ctx, keys...).Err()
+}
+
+func (c *Cached) decode(b []byte, out interface{}) error {
+	if string(b) == string(nullValue) {
+		return ErrNotFound
+	}
+	return json.Unmarshal(b, out)
+}
+
+func (c *Cached) expiration() time.Duration {
+	if c.jitter <= 0 {
+		return c.ttl
+	}
+	return c.ttl + time.Duration(rand.Int63n(int64(c.jitter)))
+}
@@ -0,0 +1,103 @@
+package gcaptcha
+
+import (
+	"context"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/gredis"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mojocn/base64Captcha"
+)
+
+// 验证码类型
+const (
+	TypeString = "string" // 字符
+	TypeMath   = "math"   // 算术题
+	TypeSlider = "slider" // 滑动拼图
+)
+
+// 滑动验证码保存的答案前缀，答案为缺口的x坐标
+const sliderPrefix = "slider:"
+
+// Captcha 生成的验证码
+type Captcha struct {
+	Id   string `json:"captchaId"`
+	Type string `json:"type"`
+	// base64编码的图片，滑动验证码为背景图
+	Data string `json:"data"`
+	// 滑动验证码的拼图块及其y坐标，用户拖动到的x坐标作为答案提交
+	Piece string `json:"piece,omitempty"`
+	Y     int    `json:"y,omitempty"`
+}
+
+func captchaTTL() time.Duration {
+	if ttl := config.Conf.CaptchaConfig.TTL; ttl > 0 {
+		return ttl
+	}
+	return 5 * time.Minute
+}
+
+// Generate 生成验证码并保存答案，typ为空时使用配置的默认类型
+func Generate(ctx context.Context, typ string) (*Captcha, error) {
+	cfg := config.Conf.CaptchaConfig
+	if typ == "" {
+		typ = cfg.Type
+	}
+	width, height := cfg.Width, cfg.Height
+	if width <= 0 || height <= 0 {
+		width, height = 240, 80
+	}
+	store := NewRedisStore(ctx, gredis.GetRedis())
+
+	var driver base64Captcha.Driver
+	switch typ {
+	case TypeSlider:
+		return generateSlider(store)
+	case TypeMath:
+		driver = base64Captcha.NewDriverMath(height, width, 5, base64Captcha.OptionShowSlimeLine, nil, nil).ConvertFonts()
+	default:
+		typ = TypeString
+		length := cfg.Length
+		if length <= 0 {
+			length = 5
+		}
+		driver = base64Captcha.NewDriverString(height, width, 5, base64Captcha.OptionShowSlimeLine,
+			length, base64Captcha.TxtSimpleCharaters, nil, nil).ConvertFonts()
+	}
+	id, b64s, err := base64Captcha.NewCaptcha(driver, store).Generate()
+	if err != nil {
+		return nil, err
+	}
+	return &Captcha{Id: id, Type: typ, Data: b64s}, nil
+}
+
+// Verify 校验验证码，无论是否正确验证码都会失效
+func Verify(ctx context.Context, id, answer string) bool {
+	if id == "" || answer == "" {
+		return false
+	}
+	store := NewRedisStore(ctx, gredis.GetRedis())
+	val := store.Get(id, true)
+	if val == "" {
+		return false
+	}
+	if strings.HasPrefix(val, sliderPrefix) {
+		x, err1 := strconv.Atoi(strings.TrimPrefix(val, sliderPrefix))
+		got, err2 := strconv.ParseFloat(strings.TrimSpace(answer), 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		return math.Abs(got-float64(x)) <= float64(sliderTolerance())
+	}
+	return strings.EqualFold(val, strings.TrimSpace(answer))
+}
+
+func sliderTolerance() int {
+	if t := config.Conf.CaptchaConfig.SliderTolerance; t > 0 {
+		return t
+	}
+	return 5
+}
@@ -11,12 +11,14 @@ import (
 type redisStore struct {
 	ctx   context.Context
 	redis *redis.Client
+	ttl   time.Duration
 }
 
 func NewRedisStore(ctx context.Context, redis *redis.Client) *redisStore {
 	return &redisStore{
 		ctx:   ctx,
 		redis: redis,
+		ttl:   captchaTTL(),
 	}
 }
 
@@ -27,20 +29,30 @@ func (r *redisStore) getKey(id string) string {
 
 // 设置验证码ID的数字
 func (r *redisStore) Set(id string, value string) {
-	_ = r.redis.Set(r.ctx, r.getKey(id), value, time.Minute*5).Err()
+	_ = r.redis.Set(r.ctx, r.getKey(id), value, r.ttl).Err()
 }
 
 //获取验证码ID的返回存储数字。清除表示是否必须从商店中删除验证码。
 func (r *redisStore) Get(id string, clear bool) string {
-	val, _ := r.redis.Get(r.ctx, r.getKey(id)).Result()
-	if clear {
-		_ = r.redis.Del(r.ctx, r.getKey(id)).Err()
+	if !clear {
+		val, _ := r.redis.Get(r.ctx, r.getKey(id)).Result()
+		return val
 	}
-	return val
+	// 读取和删除放在同一个事务中，避免并发请求重复使用同一个验证码
+	var get *redis.StringCmd
+	_, _ = r.redis.TxPipelined(r.ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(r.ctx, r.getKey(id))
+		pipe.Del(r.ctx, r.getKey(id))
+		return nil
+	})
+	return get.Val()
 }
 
 //直接验证验证码的答案,忽略大小写
 func (r *redisStore) Verify(id, answer string, clear bool) bool {
 	val := r.Get(id, clear)
-	return strings.ToLower(val) == strings.ToLower(answer)
+	if val == "" {
+		return false
+	}
+	return strings.ToLower(val) == strings.ToLower(strings.TrimSpace(answer))
 }
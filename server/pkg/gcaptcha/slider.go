@@ -0,0 +1,119 @@
+package gcaptcha
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"image/png"
+	"math/big"
+	mrand "math/rand"
+	"strconv"
+)
+
+// 滑动验证码的尺寸
+const (
+	sliderWidth  = 300
+	sliderHeight = 150
+	pieceSize    = 50
+)
+
+// generateSlider 生成带缺口的背景图和拼图块，答案为缺口的x坐标
+func generateSlider(store *redisStore) (*Captcha, error) {
+	// 缺口不出现在最左侧，避免不拖动直接通过
+	x, err := randInt(pieceSize+10, sliderWidth-pieceSize-10)
+	if err != nil {
+		return nil, err
+	}
+	y, err := randInt(10, sliderHeight-pieceSize-10)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := randInt(0, 1<<30)
+	if err != nil {
+		return nil, err
+	}
+
+	bg := sliderBackground(mrand.New(mrand.NewSource(int64(seed))))
+	piece := image.NewNRGBA(image.Rect(0, 0, pieceSize, pieceSize))
+	border := color.NRGBA{R: 255, G: 255, B: 255, A: 220}
+	for py := 0; py < pieceSize; py++ {
+		for px := 0; px < pieceSize; px++ {
+			edge := px < 2 || py < 2 || px >= pieceSize-2 || py >= pieceSize-2
+			if edge {
+				piece.SetNRGBA(px, py, border)
+			} else {
+				piece.Set(px, py, bg.At(x+px, y+py))
+			}
+			// 缺口处变暗
+			c := bg.NRGBAAt(x+px, y+py)
+			bg.SetNRGBA(x+px, y+py, color.NRGBA{R: c.R / 3, G: c.G / 3, B: c.B / 3, A: 255})
+		}
+	}
+
+	bgData, err := encodePNG(bg)
+	if err != nil {
+		return nil, err
+	}
+	pieceData, err := encodePNG(piece)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	id := hex.EncodeToString(b)
+	store.Set(id, sliderPrefix+strconv.Itoa(x))
+	return &Captcha{Id: id, Type: TypeSlider, Data: bgData, Piece: pieceData, Y: y}, nil
+}
+
+// sliderBackground 随机渐变色背景加干扰色块
+func sliderBackground(r *mrand.Rand) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, sliderWidth, sliderHeight))
+	from := color.NRGBA{R: uint8(r.Intn(128) + 64), G: uint8(r.Intn(128) + 64), B: uint8(r.Intn(128) + 64), A: 255}
+	to := color.NRGBA{R: uint8(r.Intn(128) + 64), G: uint8(r.Intn(128) + 64), B: uint8(r.Intn(128) + 64), A: 255}
+	for x := 0; x < sliderWidth; x++ {
+		t := float64(x) / sliderWidth
+		c := color.NRGBA{
+			R: uint8(float64(from.R)*(1-t) + float64(to.R)*t),
+			G: uint8(float64(from.G)*(1-t) + float64(to.G)*t),
+			B: uint8(float64(from.B)*(1-t) + float64(to.B)*t),
+			A: 255,
+		}
+		for y := 0; y < sliderHeight; y++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	for i := 0; i < 12; i++ {
+		cx, cy, radius := r.Intn(sliderWidth), r.Intn(sliderHeight), r.Intn(25)+5
+		c := color.NRGBA{R: uint8(r.Intn(256)), G: uint8(r.Intn(256)), B: uint8(r.Intn(256)), A: 255}
+		for y := cy - radius; y <= cy+radius; y++ {
+			for x := cx - radius; x <= cx+radius; x++ {
+				if (x-cx)*(x-cx)+(y-cy)*(y-cy) <= radius*radius && image.Pt(x, y).In(img.Rect) {
+					img.SetNRGBA(x, y, c)
+				}
+			}
+		}
+	}
+	return img
+}
+
+func encodePNG(img image.Image) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// randInt [min, max)之间的随机数
+func randInt(min, max int) (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max-min)))
+	if err != nil {
+		return 0, err
+	}
+	return min + int(n.Int64()), nil
+}
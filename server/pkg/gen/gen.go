@@ -0,0 +1,91 @@
+package gen
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// 根据模型生成dao、service(含请求和输出的DTO)、admin控制器，eg: ./go-skeleton gen Tag
+// 从数据表生成模型可以使用gormt目录下的配置
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+// 模板及生成的文件，%s为Model.File
+var outputs = []struct {
+	tmpl string
+	path string
+}{
+	{"dao.tmpl", "dao/%s_dao.go"},
+	{"service.tmpl", "services/%s_service.go"},
+	{"controller.tmpl", "controller/admin/%s_controller.go"},
+}
+
+// HasTime 是否有time.Time类型的字段
+func (m *Model) HasTime() bool {
+	for _, f := range m.Fields {
+		if strings.Contains(f.Type, "time.") {
+			return true
+		}
+	}
+	return false
+}
+
+// Route 路由路径
+func (m *Model) Route() string {
+	return m.Var
+}
+
+// Generate 在root目录下生成代码文件，已存在的文件在force为false时返回错误，不会覆盖
+func Generate(root string, m *Model, force bool) ([]string, error) {
+	files := make(map[string][]byte, len(outputs))
+	for _, o := range outputs {
+		path := filepath.Join(root, fmt.Sprintf(o.path, m.File))
+		if _, err := os.Stat(path); err == nil && !force {
+			return nil, fmt.Errorf("%s already exists", path)
+		}
+		src, err := render(o.tmpl, m)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = src
+	}
+	paths := make([]string, 0, len(files))
+	for _, o := range outputs {
+		path := filepath.Join(root, fmt.Sprintf(o.path, m.File))
+		if err := os.WriteFile(path, files[path], 0644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Routes 需要添加到router/admin_router.go的路由
+func Routes(m *Model) (string, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "router.tmpl", m); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func render(name string, m *Model) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, m); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return src, nil
+}
@@ -0,0 +1,116 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm/schema"
+)
+
+// Model 生成代码用到的模型信息
+type Model struct {
+	// 结构体名称，eg: UserOAuth
+	Name string
+	// 小写开头的名称，用于非导出的类型名，eg: userOAuth
+	Var string
+	// 文件名前缀，eg: user_o_auth
+	File string
+	// 可以通过表单修改的字段
+	Fields []Field
+}
+
+type Field struct {
+	Name   string
+	Type   string
+	Column string
+	Json   string
+	// json tag为"-"的字段不输出到接口
+	Hidden bool
+}
+
+// 不通过表单修改的字段
+var skipFields = map[string]bool{
+	"ID": true, "CreatedAt": true, "UpdatedAt": true, "DeletedAt": true, "Version": true,
+}
+
+// 支持生成表单的字段类型，其他类型(关联模型、切片等)跳过
+var basicTypes = map[string]bool{
+	"string": true, "bool": true, "float32": true, "float64": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"time.Time": true, "*time.Time": true,
+}
+
+var naming = schema.NamingStrategy{}
+
+// Parse 从dir目录的go源码中读取名为name的模型结构体
+func Parse(dir, name string) (*Model, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gd, ok := decl.(*ast.GenDecl)
+				if !ok || gd.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gd.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if st, ok := ts.Type.(*ast.StructType); ok && ts.Name.Name == name {
+						return newModel(name, st), nil
+					}
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("model %s not found in %s", name, dir)
+}
+
+func newModel(name string, st *ast.StructType) *Model {
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	m := &Model{
+		Name: name,
+		Var:  string(runes),
+		File: naming.ColumnName("", name),
+	}
+	for _, f := range st.Fields.List {
+		typ := types.ExprString(f.Type)
+		if len(f.Names) == 0 || !basicTypes[typ] {
+			continue
+		}
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(f.Tag.Value, "`"))
+		}
+		if tag.Get("gorm") == "-" {
+			continue
+		}
+		for _, ident := range f.Names {
+			if !ident.IsExported() || skipFields[ident.Name] {
+				continue
+			}
+			column := naming.ColumnName("", ident.Name)
+			for _, s := range strings.Split(tag.Get("gorm"), ";") {
+				if kv := strings.SplitN(s, ":", 2); len(kv) == 2 && strings.EqualFold(strings.TrimSpace(kv[0]), "column") {
+					column = strings.TrimSpace(kv[1])
+				}
+			}
+			jsonName := strings.Split(tag.Get("json"), ",")[0]
+			hidden := jsonName == "-"
+			if jsonName == "" || hidden {
+				jsonName = column
+			}
+			m.Fields = append(m.Fields, Field{Name: ident.Name, Type: typ, Column: column, Json: jsonName, Hidden: hidden})
+		}
+	}
+	return m
+}
@@ -0,0 +1,27 @@
+package geoip
+
+import "context"
+
+// LocationKey 客户端地理位置在gin.Context中的key
+const LocationKey = "geoip"
+
+type ctxKey struct{}
+
+// NewContext 将地理位置保存到context
+func NewContext(ctx context.Context, loc *Location) context.Context {
+	return context.WithValue(ctx, ctxKey{}, loc)
+}
+
+// FromContext 获取middleware.GeoIP保存的客户端地理位置，支持gin.Context和request.Context()，没有时返回nil
+func FromContext(ctx context.Context) *Location {
+	if ctx == nil {
+		return nil
+	}
+	if loc, ok := ctx.Value(ctxKey{}).(*Location); ok {
+		return loc
+	}
+	if loc, ok := ctx.Value(LocationKey).(*Location); ok {
+		return loc
+	}
+	return nil
+}
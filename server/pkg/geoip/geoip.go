@@ -0,0 +1,300 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oschwald/geoip2-golang"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrDisabled 未启用或未配置对应的数据库
+	ErrDisabled = errors.New("geoip: database not configured")
+	// ErrInvalidIP ip格式错误
+	ErrInvalidIP = errors.New("geoip: invalid ip")
+)
+
+// Location ip的地理位置，私有地址或数据库中没有的ip各字段为空
+type Location struct {
+	IP          string  `json:"ip"`
+	CountryCode string  `json:"countryCode"`
+	Country     string  `json:"country"`
+	Province    string  `json:"province"`
+	City        string  `json:"city"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	TimeZone    string  `json:"timeZone"`
+	ASN         uint    `json:"asn"`
+	Org         string  `json:"org"`
+}
+
+// Reader GeoLite2数据库，文件更新后调用Reload重新加载
+type Reader struct {
+	cfg config.GeoIPConfig
+
+	mu      sync.RWMutex
+	city    *geoip2.Reader
+	asn     *geoip2.Reader
+	modTime map[string]time.Time
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+var defaultReader *Reader
+
+// Open 按配置打开数据库，CityDb可以是GeoLite2-City或GeoLite2-Country，AsnDb为GeoLite2-ASN，未配置的不加载
+func Open(cfg config.GeoIPConfig) (*Reader, error) {
+	if cfg.Language == "" {
+		cfg.Language = "zh-CN"
+	}
+	r := &Reader{cfg: cfg, modTime: make(map[string]time.Time), quit: make(chan struct{})}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Init 按配置文件geoip打开默认的数据库，配置了ReloadInterval时定期检查文件是否更新
+func Init() error {
+	r, err := Open(config.Conf.GeoIPConfig)
+	if err != nil {
+		return err
+	}
+	defaultReader = r
+	if r.cfg.ReloadInterval > 0 {
+		r.Start()
+	}
+	return nil
+}
+
+// Default 默认的数据库，未初始化时为nil
+func Default() *Reader {
+	return defaultReader
+}
+
+// Close 关闭默认的数据库
+func Close(ctx context.Context) error {
+	if defaultReader == nil {
+		return nil
+	}
+	return defaultReader.Close(ctx)
+}
+
+// Lookup 使用默认的数据库查询
+func Lookup(ip string) (*Location, error) {
+	if defaultReader == nil {
+		return nil, ErrDisabled
+	}
+	return defaultReader.Lookup(ip)
+}
+
+// Reload 文件修改时间变化时重新打开数据库，旧的数据库在替换后关闭
+func (r *Reader) Reload() error {
+	city, err := r.reopen(r.cfg.CityDb, r.currentCity)
+	if err != nil {
+		return err
+	}
+	asn, err := r.reopen(r.cfg.AsnDb, r.currentAsn)
+	if err != nil {
+		if city != nil {
+			city.Close()
+			// 下次重新加载
+			delete(r.modTime, r.cfg.CityDb)
+		}
+		return err
+	}
+
+	r.mu.Lock()
+	oldCity, oldAsn := r.city, r.asn
+	if city != nil {
+		r.city = city
+	}
+	if asn != nil {
+		r.asn = asn
+	}
+	r.mu.Unlock()
+
+	if city != nil && oldCity != nil {
+		oldCity.Close()
+	}
+	if asn != nil && oldAsn != nil {
+		oldAsn.Close()
+	}
+	return nil
+}
+
+func (r *Reader) currentCity() *geoip2.Reader {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.city
+}
+
+func (r *Reader) currentAsn() *geoip2.Reader {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.asn
+}
+
+// reopen 文件有变化时打开新的数据库，没有变化时返回nil
+func (r *Reader) reopen(path string, current func() *geoip2.Reader) (*geoip2.Reader, error) {
+	if path == "" {
+		return nil, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: %w", err)
+	}
+	if current() != nil && info.ModTime().Equal(r.modTime[path]) {
+		return nil, nil
+	}
+	db, err := geoip2.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: open %s: %w", path, err)
+	}
+	r.modTime[path] = info.ModTime()
+	zap.L().Info("geoip database loaded", zap.String("path", path), zap.String("type", db.Metadata().DatabaseType))
+	return db, nil
+}
+
+// Start 定期检查数据库文件是否更新，eg: geoipupdate下载了新的数据库
+func (r *Reader) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.cfg.ReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.quit:
+				return
+			case <-ticker.C:
+				if err := r.Reload(); err != nil {
+					zap.L().Error("geoip reload failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Close 停止定期检查并关闭数据库
+func (r *Reader) Close(ctx context.Context) error {
+	r.once.Do(func() {
+		close(r.quit)
+	})
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.city != nil {
+		r.city.Close()
+		r.city = nil
+	}
+	if r.asn != nil {
+		r.asn.Close()
+		r.asn = nil
+	}
+	return nil
+}
+
+// Lookup 查询ip的国家、城市及ASN，只配置了其中一个数据库时只填充对应的字段
+func (r *Reader) Lookup(ip string) (*Location, error) {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return nil, ErrInvalidIP
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.city == nil && r.asn == nil {
+		return nil, ErrDisabled
+	}
+
+	loc := &Location{IP: parsed.String()}
+	if r.city != nil {
+		if err := r.lookupCity(parsed, loc); err != nil {
+			return nil, err
+		}
+	}
+	if r.asn != nil {
+		record, err := r.asn.ASN(parsed)
+		if err != nil {
+			return nil, err
+		}
+		loc.ASN = record.AutonomousSystemNumber
+		loc.Org = record.AutonomousSystemOrganization
+	}
+	return loc, nil
+}
+
+func (r *Reader) lookupCity(ip net.IP, loc *Location) error {
+	// Country数据库不能使用City方法查询
+	if !strings.Contains(r.city.Metadata().DatabaseType, "City") {
+		record, err := r.city.Country(ip)
+		if err != nil {
+			return err
+		}
+		loc.CountryCode = record.Country.IsoCode
+		loc.Country = r.name(record.Country.Names)
+		return nil
+	}
+
+	record, err := r.city.City(ip)
+	if err != nil {
+		return err
+	}
+	loc.CountryCode = record.Country.IsoCode
+	loc.Country = r.name(record.Country.Names)
+	if len(record.Subdivisions) > 0 {
+		loc.Province = r.name(record.Subdivisions[0].Names)
+	}
+	loc.City = r.name(record.City.Names)
+	loc.Latitude = record.Location.Latitude
+	loc.Longitude = record.Location.Longitude
+	loc.TimeZone = record.Location.TimeZone
+	return nil
+}
+
+// Country 查询国家代码，eg: CN
+func (r *Reader) Country(ip string) (string, error) {
+	loc, err := r.Lookup(ip)
+	if err != nil {
+		return "", err
+	}
+	return loc.CountryCode, nil
+}
+
+// ASN 查询自治系统号及所属机构
+func (r *Reader) ASN(ip string) (uint, string, error) {
+	loc, err := r.Lookup(ip)
+	if err != nil {
+		return 0, "", err
+	}
+	return loc.ASN, loc.Org, nil
+}
+
+// name 按配置的语言取名称，没有时使用英文
+func (r *Reader) name(names map[string]string) string {
+	if n, ok := names[r.cfg.Language]; ok {
+		return n
+	}
+	return names["en"]
+}
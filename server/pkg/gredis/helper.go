@@ -0,0 +1,97 @@
+package gredis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Client 带常用辅助方法的redis客户端
+type Client struct {
+	*redis.Client
+}
+
+// 仅当值与加锁时一致才删除，避免释放别人的锁
+var unlockScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+// 首次自增时设置过期时间
+var incrScript = redis.NewScript(`
+local n = redis.call("incrby", KEYS[1], ARGV[1])
+if n == tonumber(ARGV[1]) then
+	redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return n
+`)
+
+// GetJSON 读取json并解析到v，key不存在时返回redis.Nil
+func (c *Client) GetJSON(ctx context.Context, key string, v interface{}) error {
+	b, err := c.Get(ctx, key).Bytes()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// SetJSON 将v编码为json保存，ttl为0时不过期
+func (c *Client) SetJSON(ctx context.Context, key string, v interface{}, ttl time.Duration) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, key, b, ttl).Err()
+}
+
+// IncrExpire 自增计数，key首次创建时设置过期时间，常用于限流、计数窗口
+func (c *Client) IncrExpire(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error) {
+	return incrScript.Run(ctx, c, []string{key}, by, ttl.Milliseconds()).Int64()
+}
+
+// TryLock 使用SetNX加锁，成功时返回用于解锁的token
+func (c *Client) TryLock(ctx context.Context, key string, ttl time.Duration) (token string, ok bool, err error) {
+	b := make([]byte, 16)
+	if _, err = rand.Read(b); err != nil {
+		return "", false, err
+	}
+	token = hex.EncodeToString(b)
+	if ok, err = c.SetNX(ctx, key, token, ttl).Result(); err != nil || !ok {
+		return "", false, err
+	}
+	return token, true, nil
+}
+
+// Unlock 释放TryLock获取的锁，锁已过期或被他人持有时返回false
+func (c *Client) Unlock(ctx context.Context, key, token string) (bool, error) {
+	n, err := unlockScript.Run(ctx, c, []string{key}, token).Int64()
+	return n == 1, err
+}
+
+// 以下为默认实例的快捷方法
+
+func GetJSON(ctx context.Context, key string, v interface{}) error {
+	return Use(defaultConn).GetJSON(ctx, key, v)
+}
+
+func SetJSON(ctx context.Context, key string, v interface{}, ttl time.Duration) error {
+	return Use(defaultConn).SetJSON(ctx, key, v, ttl)
+}
+
+func IncrExpire(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error) {
+	return Use(defaultConn).IncrExpire(ctx, key, by, ttl)
+}
+
+func TryLock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	return Use(defaultConn).TryLock(ctx, key, ttl)
+}
+
+func Unlock(ctx context.Context, key, token string) (bool, error) {
+	return Use(defaultConn).Unlock(ctx, key, token)
+}
@@ -5,29 +5,108 @@ import (
 	"context"
 	"fmt"
 	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/tracing"
+	"sync"
 
 	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
 )
 
-//redisclient
-var client *redis.Client
+// 默认实例名
+const defaultConn = "default"
+
+var (
+	//redisclient
+	client  *redis.Client
+	clients = make(map[string]*Client)
+	mu      sync.RWMutex
+)
 
 func GetRedis() *redis.Client {
 	return client
 }
 
+// InitRedis 初始化默认实例及redis.Connections中配置的命名实例
 func InitRedis() error {
-	client = redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s", config.Conf.RedisConfig.Host),
-		Password: config.Conf.RedisConfig.Password, // no password set
-		DB:       0,                                // use default DB
-
-		//PoolSize:     10,
-		MinIdleConns: 10,
-		IdleTimeout:  config.Conf.RedisConfig.IdleTimeout, //关闭空闲连接时间
+	if err := Open(defaultConn, config.Conf.RedisConfig); err != nil {
+		return err
+	}
+	for name, c := range config.Conf.RedisConfig.Connections {
+		if err := Open(name, c); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Open 按配置创建客户端并以name注册，同名实例会被替换
+func Open(name string, c config.RedisConfig) error {
+	minIdleConns := c.MinIdleConns
+	if minIdleConns <= 0 {
+		minIdleConns = 10
+	}
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     c.Host,
+		Password: c.Password, // no password set
+		DB:       c.DB,       // use default DB
+
+		PoolSize:     c.PoolSize, //为0时使用默认值10*CPU核数
+		MinIdleConns: minIdleConns,
+		IdleTimeout:  c.IdleTimeout, //关闭空闲连接时间
 	})
-	if err := client.Ping(context.TODO()).Err(); err != nil {
+	rdb.AddHook(tracing.RedisHook{})
+	if err := rdb.Ping(context.TODO()).Err(); err != nil {
+		_ = rdb.Close()
 		return err
 	}
+	Register(name, rdb)
 	return nil
 }
+
+// Register 注册已创建的客户端
+func Register(name string, rdb *redis.Client) {
+	mu.Lock()
+	defer mu.Unlock()
+	if old, ok := clients[name]; ok && old.Client != rdb {
+		_ = old.Close()
+	}
+	clients[name] = &Client{Client: rdb}
+	if name == defaultConn {
+		client = rdb
+	}
+}
+
+// Use 获取指定名称的实例，不存在时返回nil
+func Use(name string) *Client {
+	mu.RLock()
+	defer mu.RUnlock()
+	c, ok := clients[name]
+	if !ok {
+		zap.L().Error("redis connection not found", zap.String("name", name))
+	}
+	return c
+}
+
+// PoolStats 所有实例的连接池状态
+func PoolStats() map[string]*redis.PoolStats {
+	mu.RLock()
+	defer mu.RUnlock()
+	stats := make(map[string]*redis.PoolStats, len(clients))
+	for name, c := range clients {
+		stats[name] = c.PoolStats()
+	}
+	return stats
+}
+
+// CloseRedis 关闭所有实例
+func CloseRedis() {
+	mu.Lock()
+	defer mu.Unlock()
+	for name, c := range clients {
+		if err := c.Close(); err != nil {
+			zap.L().Error("close redis failed", zap.String("name", name), zap.Error(err))
+		}
+		delete(clients, name)
+	}
+	client = nil
+}
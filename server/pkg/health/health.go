@@ -0,0 +1,153 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 单个依赖检查的默认超时时间
+const defaultTimeout = 2 * time.Second
+
+// Checker 依赖检查，返回nil表示正常
+type Checker func(ctx context.Context) error
+
+// Status 单个依赖的检查结果
+type Status struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report 检查报告
+type Report struct {
+	Healthy bool     `json:"healthy"`
+	Checks  []Status `json:"checks"`
+}
+
+var (
+	mu           sync.RWMutex
+	checkers     = make(map[string]Checker)
+	shuttingDown int32
+	startTime    = time.Now()
+)
+
+// Register 注册依赖检查，同名的会被替换，eg:
+//
+//	health.Register("mysql", func(ctx context.Context) error {
+//		sqlDB, err := simpleDb.DB().DB()
+//		if err != nil {
+//			return err
+//		}
+//		return sqlDB.PingContext(ctx)
+//	})
+func Register(name string, checker Checker) {
+	mu.Lock()
+	defer mu.Unlock()
+	checkers[name] = checker
+}
+
+// MarkShuttingDown 标记服务正在关闭，之后readiness检查返回失败，负载均衡不再转发流量
+func MarkShuttingDown() {
+	atomic.StoreInt32(&shuttingDown, 1)
+}
+
+// Check 并发执行所有依赖检查，每个检查最多等待timeout
+func Check(ctx context.Context, timeout time.Duration) *Report {
+	mu.RLock()
+	names := make([]string, 0, len(checkers))
+	for name := range checkers {
+		names = append(names, name)
+	}
+	list := make([]Checker, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		list = append(list, checkers[name])
+	}
+	mu.RUnlock()
+
+	report := &Report{Healthy: true, Checks: make([]Status, len(names))}
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			report.Checks[i] = run(ctx, names[i], list[i], timeout)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, s := range report.Checks {
+		if !s.Healthy {
+			report.Healthy = false
+		}
+	}
+	return report
+}
+
+func run(ctx context.Context, name string, checker Checker, timeout time.Duration) (status Status) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	status.Name = name
+	defer func() {
+		status.Latency = time.Since(start).String()
+	}()
+
+	errCh := make(chan error, 1)
+	go func() {
+		// 检查在单独的goroutine中执行，panic需要在这里恢复，否则会导致进程退出
+		defer func() {
+			if r := recover(); r != nil {
+				errCh <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		errCh <- checker(ctx)
+	}()
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		status.Error = err.Error()
+		return
+	}
+	status.Healthy = true
+	return
+}
+
+// Liveness /healthz，进程存活即返回200
+func Liveness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"healthy": true,
+			"uptime":  time.Since(startTime).Truncate(time.Second).String(),
+		})
+	}
+}
+
+// Readiness /readyz，所有依赖正常时返回200，否则返回503
+func Readiness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if atomic.LoadInt32(&shuttingDown) == 1 {
+			c.JSON(http.StatusServiceUnavailable, &Report{Healthy: false, Checks: []Status{}})
+			return
+		}
+		report := Check(c.Request.Context(), defaultTimeout)
+		status := http.StatusOK
+		if !report.Healthy {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
@@ -0,0 +1,24 @@
+package i18n
+
+import (
+	"context"
+	"go-skeleton/pkg/errors"
+	"strconv"
+)
+
+// Error 按ctx中的语言翻译错误码的提示，消息key为errors.<code>，eg: errors.1003。
+// 只翻译使用注册时默认提示的错误，自定义了提示的错误原样返回
+func Error(ctx context.Context, ce *errors.CodeError) *errors.CodeError {
+	if ce == nil {
+		return nil
+	}
+	registered, ok := errors.Lookup(ce.Code)
+	if !ok || registered.Message != ce.Message {
+		return ce
+	}
+	msg, ok := defaultBundle.Lookup(LocaleFrom(ctx), "errors."+strconv.Itoa(ce.Code))
+	if !ok || msg == ce.Message {
+		return ce
+	}
+	return errors.NewErrorData(ce.Code, msg, ce.Data)
+}
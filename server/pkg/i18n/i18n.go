@@ -0,0 +1,271 @@
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pelletier/go-toml"
+	"golang.org/x/text/language"
+)
+
+// LocaleKey gin.Context中保存当前语言的key
+const LocaleKey = "locale"
+
+type ctxKey struct{}
+
+// Resolver 延迟确定语言，eg: 登录后按用户资料中的语言
+type Resolver interface {
+	Locale() string
+}
+
+// Bundle 各语言的消息，key为点分隔的路径，eg: article.not_found
+type Bundle struct {
+	mu       sync.RWMutex
+	fallback string
+	messages map[string]map[string]string
+	tags     []language.Tag
+	matcher  language.Matcher
+}
+
+// NewBundle 创建消息包，fallback为找不到语言或消息时使用的默认语言
+func NewBundle(fallback string) *Bundle {
+	return &Bundle{
+		fallback: fallback,
+		messages: make(map[string]map[string]string),
+	}
+}
+
+var defaultBundle = NewBundle("zh")
+
+// Default 默认消息包
+func Default() *Bundle {
+	return defaultBundle
+}
+
+// Init 设置默认语言并加载dir下的消息文件
+func Init(dir, fallback string) error {
+	if fallback != "" {
+		defaultBundle.SetFallback(fallback)
+	}
+	if dir == "" {
+		return nil
+	}
+	return defaultBundle.LoadDir(dir)
+}
+
+// SetFallback 设置默认语言
+func (b *Bundle) SetFallback(locale string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.fallback = locale
+}
+
+// Fallback 默认语言
+func (b *Bundle) Fallback() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.fallback
+}
+
+// LoadDir 加载目录下的json、toml消息文件，文件名为语言，eg: zh.json, en.toml
+func (b *Bundle) LoadDir(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		if err := b.LoadFile(filepath.Join(dir, f.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile 加载单个消息文件，不支持的扩展名忽略
+func (b *Bundle) LoadFile(path string) error {
+	ext := filepath.Ext(path)
+	locale := strings.TrimSuffix(filepath.Base(path), ext)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]interface{}
+	switch strings.ToLower(ext) {
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	case ".toml":
+		var tree *toml.Tree
+		if tree, err = toml.LoadBytes(data); err == nil {
+			raw = tree.ToMap()
+		}
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("i18n: parse %s: %w", path, err)
+	}
+
+	messages := make(map[string]string)
+	flatten("", raw, messages)
+	b.AddMessages(locale, messages)
+	return nil
+}
+
+// AddMessages 添加消息，已存在的key会被覆盖
+func (b *Bundle) AddMessages(locale string, messages map[string]string) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return
+	}
+	locale = tag.String()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m, ok := b.messages[locale]
+	if !ok {
+		m = make(map[string]string, len(messages))
+		b.messages[locale] = m
+		b.tags = append(b.tags, tag)
+		b.matcher = language.NewMatcher(b.tags)
+	}
+	for k, v := range messages {
+		m[k] = v
+	}
+}
+
+// Locales 已加载的语言
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	locales := make([]string, 0, len(b.messages))
+	for l := range b.messages {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Match 按Accept-Language或语言代码匹配已加载的语言，没有匹配时返回默认语言
+func (b *Bundle) Match(accept string) string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if accept == "" || b.matcher == nil {
+		return b.fallback
+	}
+	tags, _, err := language.ParseAcceptLanguage(accept)
+	if err != nil || len(tags) == 0 {
+		return b.fallback
+	}
+	_, index, confidence := b.matcher.Match(tags...)
+	if confidence == language.No {
+		return b.fallback
+	}
+	return b.tags[index].String()
+}
+
+// Lookup 查找消息，当前语言没有时使用默认语言
+func (b *Bundle) Lookup(locale, key string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if msg, ok := b.messages[locale][key]; ok {
+		return msg, true
+	}
+	// zh-Hans-CN找不到时尝试zh
+	if tag, err := language.Parse(locale); err == nil {
+		if base, _ := tag.Base(); base.String() != locale {
+			if msg, ok := b.messages[base.String()][key]; ok {
+				return msg, true
+			}
+		}
+	}
+	msg, ok := b.messages[b.fallback][key]
+	return msg, ok
+}
+
+// Translate 翻译消息，args不为空时作为fmt.Sprintf的参数，找不到消息时返回key
+func (b *Bundle) Translate(locale, key string, args ...interface{}) string {
+	msg, ok := b.Lookup(locale, key)
+	if !ok {
+		msg = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// T 按ctx中的语言翻译消息，eg:
+//
+//	i18n.T(c, "article.not_found")
+//	i18n.T(c, "user.login_locked", 15)
+func T(ctx context.Context, key string, args ...interface{}) string {
+	return defaultBundle.Translate(LocaleFrom(ctx), key, args...)
+}
+
+// Tl 按指定语言翻译消息
+func Tl(locale, key string, args ...interface{}) string {
+	return defaultBundle.Translate(locale, key, args...)
+}
+
+// Match 匹配默认消息包中的语言
+func Match(accept string) string {
+	return defaultBundle.Match(accept)
+}
+
+// WithLocale 在ctx中保存语言，locale可以是string或Resolver
+func WithLocale(ctx context.Context, locale interface{}) context.Context {
+	return context.WithValue(ctx, ctxKey{}, locale)
+}
+
+// LocaleFrom 获取ctx中的语言，支持gin.Context和WithLocale返回的ctx，没有时返回默认语言
+func LocaleFrom(ctx context.Context) string {
+	if ctx != nil {
+		v := ctx.Value(ctxKey{})
+		if v == nil {
+			v = ctx.Value(LocaleKey)
+		}
+		switch l := v.(type) {
+		case string:
+			if l != "" {
+				return l
+			}
+		case Resolver:
+			if locale := l.Locale(); locale != "" {
+				return locale
+			}
+		}
+	}
+	return defaultBundle.Fallback()
+}
+
+// flatten 将嵌套的消息展开为点分隔的key
+func flatten(prefix string, raw map[string]interface{}, out map[string]string) {
+	for k, v := range raw {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			flatten(k, val, out)
+		case string:
+			out[k] = val
+		default:
+			out[k] = fmt.Sprint(val)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package jsonresult
 
-import "go-skeleton/pkg/errors"
+import (
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/simpleDb"
+)
 
 type JsonResult struct {
 	ErrorCode int         `json:"errorCode"`
@@ -26,19 +29,19 @@ func JsonData(data interface{}) *JsonResult {
 	}
 }
 
-//func JsonPageData(results interface{}, page *Paging) *JsonResult {
-//	return JsonData(&PageResult{
-//		Results: results,
-//		Page:    page,
-//	})
-//}
-//
-//func JsonCursorData(results interface{}, cursor string) *JsonResult {
-//	return JsonData(&CursorResult{
-//		Results: results,
-//		Cursor:  cursor,
-//	})
-//}
+func JsonPageData(results interface{}, page *simpleDb.Paging) *JsonResult {
+	return JsonData(&simpleDb.PageResult{
+		Results: results,
+		Page:    page,
+	})
+}
+
+func JsonCursorData(results interface{}, cursor string) *JsonResult {
+	return JsonData(&simpleDb.CursorResult{
+		Results: results,
+		Cursor:  cursor,
+	})
+}
 
 func JsonSuccess() *JsonResult {
 	return &JsonResult{
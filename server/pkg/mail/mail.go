@@ -0,0 +1,137 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/queue"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 邮件发送，eg:
+//	mail.SendTemplate(ctx, []string{"a@example.com"}, "register", map[string]interface{}{"Name": "kw"})
+//	mail.SendAsync(ctx, &mail.Message{To: []string{"a@example.com"}, Subject: "hi", HTML: "<b>hi</b>"})
+
+// 异步发送邮件的任务
+const JobSend = "email.send"
+
+var ErrNoRecipient = errors.New("mail: no recipient")
+
+// Message 邮件，From为空时使用配置的发件人，HTML和Text至少需要一个
+type Message struct {
+	From        string       `json:"from"`
+	FromName    string       `json:"fromName"`
+	To          []string     `json:"to"`
+	Cc          []string     `json:"cc"`
+	Bcc         []string     `json:"bcc"`
+	ReplyTo     string       `json:"replyTo"`
+	Subject     string       `json:"subject"`
+	HTML        string       `json:"html"`
+	Text        string       `json:"text"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+// Attachment 附件，ContentType为空时按文件名推断
+type Attachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Data        []byte `json:"data"`
+}
+
+// Sender 邮件服务商
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Driver 根据配置创建Sender
+type Driver func(cfg config.MailConfig) (Sender, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]Driver{
+		"smtp":     newSmtpSender,
+		"sendgrid": newSendGridSender,
+	}
+
+	once      sync.Once
+	sender    Sender
+	senderErr error
+)
+
+func init() {
+	queue.Handle(JobSend, func(ctx context.Context, job *queue.Job) error {
+		msg := &Message{}
+		if err := job.Bind(msg); err != nil {
+			return fmt.Errorf("%w: %v", queue.ErrSkipRetry, err)
+		}
+		return Send(ctx, msg)
+	})
+}
+
+// RegisterDriver 注册邮件服务商，eg: 阿里云邮件推送，需要在第一次发送前注册
+func RegisterDriver(name string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers[name] = driver
+}
+
+// GetSender 按配置的Driver创建的Sender
+func GetSender() (Sender, error) {
+	once.Do(func() {
+		cfg := config.Conf.MailConfig
+		driversMu.RLock()
+		driver, ok := drivers[cfg.Driver]
+		driversMu.RUnlock()
+		if !ok {
+			senderErr = fmt.Errorf("mail: unknown driver %q", cfg.Driver)
+			return
+		}
+		sender, senderErr = driver(cfg)
+	})
+	return sender, senderErr
+}
+
+// Send 同步发送邮件
+func Send(ctx context.Context, msg *Message) error {
+	if len(msg.To)+len(msg.Cc)+len(msg.Bcc) == 0 {
+		return ErrNoRecipient
+	}
+	s, err := GetSender()
+	if err != nil {
+		return err
+	}
+	if msg.From == "" {
+		msg.From = config.Conf.MailConfig.From
+		msg.FromName = config.Conf.MailConfig.FromName
+	}
+	return s.Send(ctx, msg)
+}
+
+// SendAsync 异步发送，开启了任务队列时投递email.send任务，失败后按队列配置重试，否则在协程中发送
+func SendAsync(ctx context.Context, msg *Message) error {
+	if config.Conf.QueueConfig.Enable {
+		_, err := queue.Enqueue(ctx, JobSend, msg)
+		return err
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := Send(ctx, msg); err != nil {
+			zap.L().Error("send mail failed", zap.Strings("to", msg.To), zap.String("subject", msg.Subject), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// SendTemplate 使用模板异步发送邮件，模板中定义的subject作为主题
+func SendTemplate(ctx context.Context, to []string, name string, data interface{}) error {
+	subject, body, err := Render(name, data)
+	if err != nil {
+		return err
+	}
+	return SendAsync(ctx, &Message{To: to, Subject: subject, HTML: body})
+}
@@ -0,0 +1,99 @@
+package mail
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"go-skeleton/pkg/config"
+	"go-skeleton/utils/httpclient"
+	"mime"
+	"net/http"
+	"path/filepath"
+)
+
+// SendGridSender 通过SendGrid v3 api发送
+type SendGridSender struct {
+	client *httpclient.Client
+}
+
+func newSendGridSender(cfg config.MailConfig) (Sender, error) {
+	if cfg.SendGrid.ApiKey == "" {
+		return nil, errors.New("mail: sendgrid api key is empty")
+	}
+	return &SendGridSender{client: httpclient.New(
+		httpclient.WithBaseURL("https://api.sendgrid.com"),
+		httpclient.WithHeader("Authorization", "Bearer "+cfg.SendGrid.ApiKey),
+	)}, nil
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+func (s *SendGridSender) Send(ctx context.Context, msg *Message) error {
+	addresses := func(list []string) []sendGridAddress {
+		ret := make([]sendGridAddress, 0, len(list))
+		for _, email := range list {
+			ret = append(ret, sendGridAddress{Email: email})
+		}
+		return ret
+	}
+	personalization := map[string]interface{}{"to": addresses(msg.To)}
+	if len(msg.Cc) > 0 {
+		personalization["cc"] = addresses(msg.Cc)
+	}
+	if len(msg.Bcc) > 0 {
+		personalization["bcc"] = addresses(msg.Bcc)
+	}
+
+	// text/plain需要在text/html之前
+	var content []map[string]string
+	if msg.Text != "" {
+		content = append(content, map[string]string{"type": "text/plain", "value": msg.Text})
+	}
+	if msg.HTML != "" {
+		content = append(content, map[string]string{"type": "text/html", "value": msg.HTML})
+	}
+	body := map[string]interface{}{
+		"personalizations": []interface{}{personalization},
+		"from":             sendGridAddress{Email: msg.From, Name: msg.FromName},
+		"subject":          msg.Subject,
+		"content":          content,
+	}
+	if msg.ReplyTo != "" {
+		body["reply_to"] = sendGridAddress{Email: msg.ReplyTo}
+	}
+	if len(msg.Attachments) > 0 {
+		attachments := make([]map[string]string, 0, len(msg.Attachments))
+		for _, a := range msg.Attachments {
+			contentType := a.ContentType
+			if contentType == "" {
+				contentType = mime.TypeByExtension(filepath.Ext(a.Name))
+			}
+			attachment := map[string]string{
+				"filename": a.Name,
+				"content":  base64.StdEncoding.EncodeToString(a.Data),
+			}
+			if contentType != "" {
+				attachment["type"] = contentType
+			}
+			attachments = append(attachments, attachment)
+		}
+		body["attachments"] = attachments
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	// 发送邮件不是幂等的，不重试，失败后由任务队列重试
+	_, err = s.client.Do(ctx, &httpclient.Request{
+		Method:  http.MethodPost,
+		URL:     "/v3/mail/send",
+		Header:  http.Header{"Content-Type": {"application/json"}},
+		Body:    b,
+		NoRetry: true,
+	})
+	return err
+}
@@ -0,0 +1,197 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SmtpSender 通过smtp发送
+type SmtpSender struct {
+	cfg config.SmtpConfig
+}
+
+func newSmtpSender(cfg config.MailConfig) (Sender, error) {
+	if cfg.Smtp.Host == "" {
+		return nil, errors.New("mail: smtp host is empty")
+	}
+	return &SmtpSender{cfg: cfg.Smtp}, nil
+}
+
+func (s *SmtpSender) Send(ctx context.Context, msg *Message) error {
+	body, err := buildMIME(msg)
+	if err != nil {
+		return err
+	}
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	if s.cfg.SSL {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: s.cfg.Host})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if !s.cfg.SSL {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+				return err
+			}
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(msg.From); err != nil {
+		return err
+	}
+	for _, rcpt := range recipients(msg) {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("mail: rcpt %s: %w", rcpt, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func recipients(msg *Message) []string {
+	list := make([]string, 0, len(msg.To)+len(msg.Cc)+len(msg.Bcc))
+	list = append(list, msg.To...)
+	list = append(list, msg.Cc...)
+	return append(list, msg.Bcc...)
+}
+
+// buildMIME 生成邮件内容，有附件时为multipart/mixed，同时有html和text时为multipart/alternative
+func buildMIME(msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(k, v string) {
+		buf.WriteString(k + ": " + v + "\r\n")
+	}
+	from := (&mail.Address{Name: msg.FromName, Address: msg.From}).String()
+	header("From", from)
+	header("To", strings.Join(msg.To, ", "))
+	if len(msg.Cc) > 0 {
+		header("Cc", strings.Join(msg.Cc, ", "))
+	}
+	if msg.ReplyTo != "" {
+		header("Reply-To", msg.ReplyTo)
+	}
+	header("Subject", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", "<"+randomBoundary()+"@"+domain(msg.From)+">")
+	header("MIME-Version", "1.0")
+
+	content := alternative(msg)
+	if len(msg.Attachments) == 0 {
+		buf.Write(content)
+		return buf.Bytes(), nil
+	}
+
+	boundary := randomBoundary()
+	header("Content-Type", `multipart/mixed; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n--" + boundary + "\r\n")
+	buf.Write(content)
+	for _, a := range msg.Attachments {
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = mime.TypeByExtension(filepath.Ext(a.Name))
+		}
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		name := mime.BEncoding.Encode("UTF-8", a.Name)
+		buf.WriteString("\r\n--" + boundary + "\r\n")
+		buf.WriteString("Content-Type: " + contentType + `; name="` + name + "\"\r\n")
+		buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+		buf.WriteString(`Content-Disposition: attachment; filename="` + name + "\"\r\n\r\n")
+		writeBase64(&buf, a.Data)
+	}
+	buf.WriteString("\r\n--" + boundary + "--\r\n")
+	return buf.Bytes(), nil
+}
+
+// alternative 正文部分，包含Content-Type头
+func alternative(msg *Message) []byte {
+	var buf bytes.Buffer
+	part := func(contentType, body string) {
+		buf.WriteString("Content-Type: " + contentType + "; charset=UTF-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+		writeBase64(&buf, []byte(body))
+	}
+	switch {
+	case msg.HTML != "" && msg.Text != "":
+		boundary := randomBoundary()
+		buf.WriteString(`Content-Type: multipart/alternative; boundary="` + boundary + "\"\r\n")
+		buf.WriteString("\r\n--" + boundary + "\r\n")
+		part("text/plain", msg.Text)
+		buf.WriteString("\r\n--" + boundary + "\r\n")
+		part("text/html", msg.HTML)
+		buf.WriteString("\r\n--" + boundary + "--\r\n")
+	case msg.HTML != "":
+		part("text/html", msg.HTML)
+	default:
+		part("text/plain", msg.Text)
+	}
+	return buf.Bytes()
+}
+
+// writeBase64 按76个字符换行写入base64
+func writeBase64(buf *bytes.Buffer, data []byte) {
+	s := base64.StdEncoding.EncodeToString(data)
+	for len(s) > 76 {
+		buf.WriteString(s[:76] + "\r\n")
+		s = s[76:]
+	}
+	buf.WriteString(s + "\r\n")
+}
+
+func randomBoundary() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func domain(addr string) string {
+	if i := strings.LastIndex(addr, "@"); i >= 0 {
+		return addr[i+1:]
+	}
+	return "localhost"
+}
@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"bytes"
+	"go-skeleton/pkg/config"
+	"html/template"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// 已解析的模板，debug模式下每次重新解析，方便修改模板
+var (
+	templatesMu sync.RWMutex
+	templates   = map[string]*template.Template{}
+)
+
+// Render 渲染模板目录中的{name}.html，模板可以通过{{define "subject"}}定义邮件主题，eg:
+//	{{define "subject"}}欢迎注册{{.Site}}{{end}}
+//	<p>你好，{{.Name}}</p>
+func Render(name string, data interface{}) (subject, body string, err error) {
+	t, err := loadTemplate(name)
+	if err != nil {
+		return "", "", err
+	}
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	body = buf.String()
+	if st := t.Lookup("subject"); st != nil {
+		buf.Reset()
+		if err = st.Execute(&buf, data); err != nil {
+			return "", "", err
+		}
+		subject = strings.TrimSpace(buf.String())
+	}
+	return subject, body, nil
+}
+
+func loadTemplate(name string) (*template.Template, error) {
+	debug := config.Conf.AppMode == "debug"
+	if !debug {
+		templatesMu.RLock()
+		t, ok := templates[name]
+		templatesMu.RUnlock()
+		if ok {
+			return t, nil
+		}
+	}
+	t, err := template.ParseFiles(filepath.Join(config.Conf.MailConfig.TemplateDir, name+".html"))
+	if err != nil {
+		return nil, err
+	}
+	if !debug {
+		templatesMu.Lock()
+		templates[name] = t
+		templatesMu.Unlock()
+	}
+	return t, nil
+}
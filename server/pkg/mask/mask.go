@@ -0,0 +1,148 @@
+package mask
+
+import (
+	"go-skeleton/utils"
+	"reflect"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 响应数据脱敏：字符串字段通过标签声明规则，eg:
+//
+//	Phone string `json:"phone" mask:"phone"`
+//
+// resp.OK、resp.Page等输出前按请求决定是否脱敏，默认脱敏，
+// 通过middleware.Unmask为管理员或指定的接口关闭。规则见utils.MaskBy
+
+// TagName 声明脱敏规则的结构体标签
+const TagName = "mask"
+
+// 请求关闭脱敏的context key
+const disabledKey = "mask:disabled"
+
+// Disable 当前请求输出原始数据
+func Disable(c *gin.Context) {
+	c.Set(disabledKey, true)
+}
+
+// Enabled 当前请求是否需要脱敏
+func Enabled(c *gin.Context) bool {
+	return !c.GetBool(disabledKey)
+}
+
+// Apply 返回脱敏后的副本，不修改原数据；不包含脱敏字段的类型原样返回
+func Apply(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if !hasMask(rv.Type()) {
+		return v
+	}
+	return apply(rv).Interface()
+}
+
+// 类型是否包含脱敏字段
+var types sync.Map
+
+func hasMask(t reflect.Type) bool {
+	if v, ok := types.Load(t); ok {
+		return v.(bool)
+	}
+	has := scan(t, map[reflect.Type]bool{})
+	types.Store(t, has)
+	return has
+}
+
+// scan interface{}的实际类型运行时才知道，按包含处理，eg: gin.H
+func scan(t reflect.Type, seen map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return scan(t.Elem(), seen)
+	case reflect.Interface:
+		return true
+	case reflect.Struct:
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+			if f.Tag.Get(TagName) != "" && f.Type.Kind() == reflect.String {
+				return true
+			}
+			if scan(f.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func apply(v reflect.Value) reflect.Value {
+	if !v.IsValid() || !hasMask(v.Type()) {
+		return v
+	}
+	t := v.Type()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(t.Elem())
+		out.Elem().Set(apply(v.Elem()))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(t).Elem()
+		out.Set(apply(v.Elem()))
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(apply(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(t).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(apply(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), apply(iter.Value()))
+		}
+		return out
+	case reflect.Struct:
+		out := reflect.New(t).Elem()
+		out.Set(v)
+		for i := 0; i < t.NumField(); i++ {
+			field := out.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+			if rule := t.Field(i).Tag.Get(TagName); rule != "" && field.Kind() == reflect.String {
+				field.SetString(utils.MaskBy(rule, field.String()))
+				continue
+			}
+			field.Set(apply(field))
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// HttpRequests 请求数
+	HttpRequests = NewCounter("http_requests_total", "Total number of HTTP requests.", "method", "route", "status")
+	// HttpDuration 请求耗时
+	HttpDuration = NewHistogram("http_request_duration_seconds", "HTTP request latency in seconds.", prometheus.DefBuckets, "method", "route")
+	// ApiVersionRequests 按api版本统计的请求数，deprecated为接口或版本是否已弃用
+	ApiVersionRequests = NewCounter("api_version_requests_total", "Total number of API requests by version.", "version", "route", "deprecated")
+	// DbDuration sql耗时
+	DbDuration = NewHistogram("db_query_duration_seconds", "Database query latency in seconds.",
+		[]float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5}, "operation", "table")
+	// DbSlowQueries 慢查询耗时，count即慢查询次数
+	DbSlowQueries = NewHistogram("db_slow_query_duration_seconds", "Slow database query latency in seconds.",
+		[]float64{.2, .5, 1, 2.5, 5, 10, 30}, "operation", "table")
+)
+
+// Handler /metrics接口，默认注册表已包含go运行时和进程的指标
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// NewCounter 创建并注册计数器，同名指标已注册时返回已有的，eg:
+//
+//	var articlePublished = metrics.NewCounter("article_published_total", "Published articles.", "category")
+//	articlePublished.WithLabelValues("news").Inc()
+func NewCounter(name, help string, labels ...string) *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels)
+	return register(c).(*prometheus.CounterVec)
+}
+
+// NewGauge 创建并注册仪表盘指标
+func NewGauge(name, help string, labels ...string) *prometheus.GaugeVec {
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labels)
+	return register(g).(*prometheus.GaugeVec)
+}
+
+// NewHistogram 创建并注册直方图，buckets为nil时使用默认的分桶
+func NewHistogram(name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: buckets}, labels)
+	return register(h).(*prometheus.HistogramVec)
+}
+
+func register(c prometheus.Collector) prometheus.Collector {
+	if err := prometheus.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector
+		}
+		panic(err)
+	}
+	return c
+}
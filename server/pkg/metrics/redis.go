@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// redisPoolCollector 采集redis连接池状态
+type redisPoolCollector struct {
+	stats func() map[string]*redis.PoolStats
+	descs map[string]*prometheus.Desc
+}
+
+// RegisterRedisPool 注册redis连接池指标，stats返回各实例的连接池状态，eg: metrics.RegisterRedisPool(gredis.PoolStats)
+func RegisterRedisPool(stats func() map[string]*redis.PoolStats) {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("redis_pool_"+name, help, []string{"instance"}, nil)
+	}
+	register(&redisPoolCollector{
+		stats: stats,
+		descs: map[string]*prometheus.Desc{
+			"hits":        desc("hits_total", "Number of times a free connection was found in the pool."),
+			"misses":      desc("misses_total", "Number of times a free connection was NOT found in the pool."),
+			"timeouts":    desc("timeouts_total", "Number of times a wait timeout occurred."),
+			"total_conns": desc("total_conns", "Number of total connections in the pool."),
+			"idle_conns":  desc("idle_conns", "Number of idle connections in the pool."),
+			"stale_conns": desc("stale_conns_total", "Number of stale connections removed from the pool."),
+		},
+	})
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range c.descs {
+		ch <- d
+	}
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, s := range c.stats() {
+		ch <- prometheus.MustNewConstMetric(c.descs["hits"], prometheus.CounterValue, float64(s.Hits), name)
+		ch <- prometheus.MustNewConstMetric(c.descs["misses"], prometheus.CounterValue, float64(s.Misses), name)
+		ch <- prometheus.MustNewConstMetric(c.descs["timeouts"], prometheus.CounterValue, float64(s.Timeouts), name)
+		ch <- prometheus.MustNewConstMetric(c.descs["total_conns"], prometheus.GaugeValue, float64(s.TotalConns), name)
+		ch <- prometheus.MustNewConstMetric(c.descs["idle_conns"], prometheus.GaugeValue, float64(s.IdleConns), name)
+		ch <- prometheus.MustNewConstMetric(c.descs["stale_conns"], prometheus.CounterValue, float64(s.StaleConns), name)
+	}
+}
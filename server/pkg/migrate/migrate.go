@@ -0,0 +1,212 @@
+package migrate
+
+import (
+	stderrors "errors"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// 数据库迁移，基于gormigrate
+// SQL迁移文件命名为 {版本号}_{名称}.up.sql / {版本号}_{名称}.down.sql，版本号为时间戳，按版本号顺序执行
+// 复杂的数据迁移可以用Register注册Go函数，和SQL文件一起按ID排序
+
+var (
+	migrations []*gormigrate.Migration
+	sources    []fs.FS
+
+	fileRegexp = regexp.MustCompile(`^(\d+_\w+)\.(up|down)\.sql$`)
+	nameRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Register 注册Go实现的迁移，id格式和SQL文件保持一致，eg: 20211015120000_fill_article_slug
+func Register(id string, up, down func(tx *gorm.DB) error) {
+	migrations = append(migrations, &gormigrate.Migration{ID: id, Migrate: up, Rollback: down})
+}
+
+// RegisterFS 注册SQL迁移文件所在的目录，一般为migrations包embed的文件
+func RegisterFS(fsys fs.FS) {
+	sources = append(sources, fsys)
+}
+
+// State 迁移的执行状态
+type State struct {
+	ID      string `json:"id"`
+	Applied bool   `json:"applied"`
+}
+
+// Up 执行所有未执行的迁移
+func Up(db *gorm.DB) error {
+	m, err := newMigrate(db)
+	if err != nil {
+		return err
+	}
+	return m.Migrate()
+}
+
+// Down 回滚最近执行的steps个迁移
+func Down(db *gorm.DB, steps int) error {
+	m, err := newMigrate(db)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < steps; i++ {
+		if err := m.RollbackLast(); err != nil {
+			if stderrors.Is(err, gormigrate.ErrNoRunMigration) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Status 所有迁移及是否已执行
+func Status(db *gorm.DB) ([]State, error) {
+	list, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[string]bool)
+	table := tableName()
+	if db.Migrator().HasTable(table) {
+		var ids []string
+		if err := db.Table(table).Pluck(gormigrate.DefaultOptions.IDColumnName, &ids).Error; err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			applied[id] = true
+		}
+	}
+	states := make([]State, 0, len(list))
+	for _, m := range list {
+		states = append(states, State{ID: m.ID, Applied: applied[m.ID]})
+	}
+	return states, nil
+}
+
+// Create 在dir目录下生成一对空的迁移文件，返回文件路径
+func Create(dir, name string) (up, down string, err error) {
+	name = strings.Trim(nameRegexp.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", stderrors.New("migration name is empty")
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	id := time.Now().Format("20060102150405") + "_" + name
+	up = filepath.Join(dir, id+".up.sql")
+	down = filepath.Join(dir, id+".down.sql")
+	if err = os.WriteFile(up, []byte("-- "+id+"\n"), 0644); err != nil {
+		return
+	}
+	err = os.WriteFile(down, []byte("-- "+id+"\n"), 0644)
+	return
+}
+
+func tableName() string {
+	if name := config.Conf.MigrateConfig.TableName; name != "" {
+		return name
+	}
+	return gormigrate.DefaultOptions.TableName
+}
+
+func newMigrate(db *gorm.DB) (*gormigrate.Gormigrate, error) {
+	list, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, gormigrate.ErrNoMigrationDefined
+	}
+	// MySQL的DDL会隐式提交，不使用事务
+	return gormigrate.New(db, &gormigrate.Options{TableName: tableName()}, list), nil
+}
+
+// loadMigrations 合并Go迁移和SQL文件迁移，按ID排序
+func loadMigrations() ([]*gormigrate.Migration, error) {
+	list := make([]*gormigrate.Migration, 0, len(migrations))
+	list = append(list, migrations...)
+	files := make(map[string]*gormigrate.Migration)
+	for _, fsys := range sources {
+		entries, err := fs.ReadDir(fsys, ".")
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			match := fileRegexp.FindStringSubmatch(entry.Name())
+			if entry.IsDir() || match == nil {
+				continue
+			}
+			data, err := fs.ReadFile(fsys, entry.Name())
+			if err != nil {
+				return nil, err
+			}
+			m, ok := files[match[1]]
+			if !ok {
+				m = &gormigrate.Migration{ID: match[1]}
+				files[match[1]] = m
+			}
+			if match[2] == "up" {
+				m.Migrate = execSql(entry.Name(), string(data))
+			} else {
+				m.Rollback = execSql(entry.Name(), string(data))
+			}
+		}
+	}
+	for id, m := range files {
+		if m.Migrate == nil {
+			return nil, fmt.Errorf("migration %s: missing up file", id)
+		}
+		list = append(list, m)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+// execSql 逐条执行文件中的SQL语句
+func execSql(file, sql string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, stmt := range splitStatements(sql) {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+		}
+		return nil
+	}
+}
+
+// splitStatements 按行尾的分号拆分语句，忽略--注释行
+func splitStatements(sql string) []string {
+	var (
+		stmts []string
+		buf   strings.Builder
+	)
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSpace(buf.String()))
+			buf.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(buf.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
@@ -0,0 +1,98 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/utils/httpclient"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Github https://docs.github.com/en/developers/apps/building-oauth-apps/authorizing-oauth-apps
+type Github struct {
+	name   string
+	cfg    config.OAuthProviderConfig
+	client *httpclient.Client
+}
+
+func newGithub(name string, cfg config.OAuthProviderConfig) Provider {
+	return &Github{name: name, cfg: cfg, client: httpclient.New()}
+}
+
+func (g *Github) AuthURL(state string) string {
+	q := url.Values{
+		"client_id":    {g.cfg.ClientId},
+		"redirect_uri": {g.cfg.RedirectUrl},
+		"scope":        {strings.Join(g.cfg.Scopes, " ")},
+		"state":        {state},
+	}
+	return "https://github.com/login/oauth/authorize?" + q.Encode()
+}
+
+func (g *Github) Exchange(ctx context.Context, code string) (*UserInfo, error) {
+	form := url.Values{
+		"client_id":     {g.cfg.ClientId},
+		"client_secret": {g.cfg.ClientSecret},
+		"code":          {code},
+		"redirect_uri":  {g.cfg.RedirectUrl},
+	}
+	resp, err := g.client.Do(ctx, &httpclient.Request{
+		Method: http.MethodPost,
+		URL:    "https://github.com/login/oauth/access_token",
+		Header: http.Header{
+			"Content-Type": {"application/x-www-form-urlencoded"},
+			"Accept":       {"application/json"},
+		},
+		Body:    []byte(form.Encode()),
+		NoRetry: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := resp.JSON(&token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("oauth: github %s: %s", token.Error, token.ErrorDescription)
+	}
+
+	resp, err = g.client.Do(ctx, &httpclient.Request{
+		URL: "https://api.github.com/user",
+		Header: http.Header{
+			"Authorization": {"token " + token.AccessToken},
+			"Accept":        {"application/vnd.github.v3+json"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var user struct {
+		Id        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarUrl string `json:"avatar_url"`
+		Email     string `json:"email"`
+	}
+	if err := resp.JSON(&user); err != nil {
+		return nil, err
+	}
+	nickname := user.Name
+	if nickname == "" {
+		nickname = user.Login
+	}
+	return &UserInfo{
+		Provider: g.name,
+		OpenId:   strconv.FormatInt(user.Id, 10),
+		Nickname: nickname,
+		Avatar:   user.AvatarUrl,
+		Email:    user.Email,
+	}, nil
+}
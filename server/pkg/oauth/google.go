@@ -0,0 +1,86 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/utils/httpclient"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Google https://developers.google.com/identity/protocols/oauth2/web-server
+type Google struct {
+	name   string
+	cfg    config.OAuthProviderConfig
+	client *httpclient.Client
+}
+
+func newGoogle(name string, cfg config.OAuthProviderConfig) Provider {
+	return &Google{name: name, cfg: cfg, client: httpclient.New()}
+}
+
+func (g *Google) AuthURL(state string) string {
+	q := url.Values{
+		"client_id":     {g.cfg.ClientId},
+		"redirect_uri":  {g.cfg.RedirectUrl},
+		"response_type": {"code"},
+		"scope":         {strings.Join(g.cfg.Scopes, " ")},
+		"state":         {state},
+	}
+	return "https://accounts.google.com/o/oauth2/v2/auth?" + q.Encode()
+}
+
+func (g *Google) Exchange(ctx context.Context, code string) (*UserInfo, error) {
+	form := url.Values{
+		"client_id":     {g.cfg.ClientId},
+		"client_secret": {g.cfg.ClientSecret},
+		"code":          {code},
+		"redirect_uri":  {g.cfg.RedirectUrl},
+		"grant_type":    {"authorization_code"},
+	}
+	resp, err := g.client.Do(ctx, &httpclient.Request{
+		Method:  http.MethodPost,
+		URL:     "https://oauth2.googleapis.com/token",
+		Header:  http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+		Body:    []byte(form.Encode()),
+		NoRetry: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := resp.JSON(&token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("oauth: google empty access token")
+	}
+
+	resp, err = g.client.Do(ctx, &httpclient.Request{
+		URL:    "https://openidconnect.googleapis.com/v1/userinfo",
+		Header: http.Header{"Authorization": {"Bearer " + token.AccessToken}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var user struct {
+		Sub     string `json:"sub"`
+		Name    string `json:"name"`
+		Picture string `json:"picture"`
+		Email   string `json:"email"`
+	}
+	if err := resp.JSON(&user); err != nil {
+		return nil, err
+	}
+	return &UserInfo{
+		Provider: g.name,
+		OpenId:   user.Sub,
+		Nickname: user.Name,
+		Avatar:   user.Picture,
+		Email:    user.Email,
+	}, nil
+}
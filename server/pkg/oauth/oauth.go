@@ -0,0 +1,85 @@
+package oauth
+
+import (
+	"context"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"sync"
+)
+
+// UserInfo 第三方平台的用户信息
+type UserInfo struct {
+	Provider string
+	OpenId   string
+	// 微信开放平台下同一主体的应用共用的id
+	UnionId  string
+	Nickname string
+	Avatar   string
+	Email    string
+}
+
+// Provider 第三方登录平台
+type Provider interface {
+	// AuthURL 跳转到第三方授权页面的地址
+	AuthURL(state string) string
+	// Exchange 用授权码换取用户信息
+	Exchange(ctx context.Context, code string) (*UserInfo, error)
+}
+
+// Driver 根据配置创建Provider
+type Driver func(name string, cfg config.OAuthProviderConfig) Provider
+
+var (
+	mu      sync.RWMutex
+	drivers = map[string]Driver{
+		"github": newGithub,
+		"google": newGoogle,
+		"wechat": newWechat,
+	}
+	providers = map[string]Provider{}
+)
+
+// RegisterDriver 注册第三方登录平台，name与配置oauth.Providers中的key对应
+func RegisterDriver(name string, driver Driver) {
+	mu.Lock()
+	defer mu.Unlock()
+	drivers[name] = driver
+	delete(providers, name)
+}
+
+// GetProvider 获取已配置的第三方登录平台
+func GetProvider(name string) (Provider, error) {
+	mu.RLock()
+	p, ok := providers[name]
+	mu.RUnlock()
+	if ok {
+		return p, nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if p, ok := providers[name]; ok {
+		return p, nil
+	}
+	cfg, ok := config.Conf.OAuthConfig.Providers[name]
+	driver, registered := drivers[name]
+	if !ok || !registered || cfg.ClientId == "" {
+		return nil, errors.OAuthProviderError
+	}
+	p = driver(name, cfg)
+	providers[name] = p
+	return p, nil
+}
+
+// Providers 已配置的第三方登录平台
+func Providers() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	var list []string
+	for name, cfg := range config.Conf.OAuthConfig.Providers {
+		if _, ok := drivers[name]; ok && cfg.ClientId != "" {
+			list = append(list, name)
+		}
+	}
+	return list
+}
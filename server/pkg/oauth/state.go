@@ -0,0 +1,89 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// StateCookie 保存state的cookie，回调时与提交的state比较，确认授权由当前浏览器发起
+const StateCookie = "oauth_state"
+
+func stateKey(state string) string {
+	return "oauth:state:" + state
+}
+
+func stateTTL() time.Duration {
+	if ttl := config.Conf.OAuthConfig.StateTTL; ttl > 0 {
+		return ttl
+	}
+	return 10 * time.Minute
+}
+
+// NewState 生成防CSRF的state，uid不为0时表示绑定到该用户
+func NewState(ctx context.Context, provider string, uid int64) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	state := hex.EncodeToString(b)
+	value := provider + ":" + strconv.FormatInt(uid, 10)
+	if err := gredis.GetRedis().Set(ctx, stateKey(state), value, stateTTL()).Err(); err != nil {
+		return "", err
+	}
+	return state, nil
+}
+
+// VerifyState 校验state并使其失效，返回生成state时的uid
+func VerifyState(ctx context.Context, provider, state string) (int64, error) {
+	if state == "" {
+		return 0, errors.OAuthStateError
+	}
+	var get *redis.StringCmd
+	_, err := gredis.GetRedis().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, stateKey(state))
+		pipe.Del(ctx, stateKey(state))
+		return nil
+	})
+	if err == redis.Nil {
+		return 0, errors.OAuthStateError
+	}
+	if err != nil {
+		return 0, err
+	}
+	i := strings.LastIndexByte(get.Val(), ':')
+	if i < 0 || get.Val()[:i] != provider {
+		return 0, errors.OAuthStateError
+	}
+	return strconv.ParseInt(get.Val()[i+1:], 10, 64)
+}
+
+// SetStateCookie 将state写入HttpOnly cookie，获取授权地址时调用
+func SetStateCookie(c *gin.Context, state string) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(StateCookie, state, int(stateTTL().Seconds()), "/", "", isHTTPS(c), true)
+}
+
+// CheckStateCookie 提交的state与浏览器cookie中的一致时返回true，校验后删除cookie，
+// 避免攻击者将自己的授权码和state发给用户完成登录或绑定
+func CheckStateCookie(c *gin.Context, state string) bool {
+	cookie, err := c.Cookie(StateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(StateCookie, "", -1, "/", "", isHTTPS(c), true)
+	return err == nil && state != "" && utils.SecureCompare(cookie, state)
+}
+
+func isHTTPS(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,104 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/utils/httpclient"
+	"net/url"
+)
+
+// Wechat 网站应用扫码登录和公众号网页授权
+// https://developers.weixin.qq.com/doc/oplatform/Website_App/WeChat_Login/Wechat_Login.html
+type Wechat struct {
+	name   string
+	cfg    config.OAuthProviderConfig
+	client *httpclient.Client
+}
+
+func newWechat(name string, cfg config.OAuthProviderConfig) Provider {
+	return &Wechat{name: name, cfg: cfg, client: httpclient.New()}
+}
+
+func (w *Wechat) scope() string {
+	if len(w.cfg.Scopes) > 0 {
+		return w.cfg.Scopes[0]
+	}
+	return "snsapi_login"
+}
+
+func (w *Wechat) AuthURL(state string) string {
+	q := url.Values{
+		"appid":         {w.cfg.ClientId},
+		"redirect_uri":  {w.cfg.RedirectUrl},
+		"response_type": {"code"},
+		"scope":         {w.scope()},
+		"state":         {state},
+	}
+	endpoint := "https://open.weixin.qq.com/connect/qrconnect?"
+	if w.scope() != "snsapi_login" {
+		endpoint = "https://open.weixin.qq.com/connect/oauth2/authorize?"
+	}
+	return endpoint + q.Encode() + "#wechat_redirect"
+}
+
+type wechatError struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+func (e wechatError) err() error {
+	if e.ErrCode == 0 {
+		return nil
+	}
+	return fmt.Errorf("oauth: wechat %d: %s", e.ErrCode, e.ErrMsg)
+}
+
+func (w *Wechat) Exchange(ctx context.Context, code string) (*UserInfo, error) {
+	var token struct {
+		wechatError
+		AccessToken string `json:"access_token"`
+		OpenId      string `json:"openid"`
+		UnionId     string `json:"unionid"`
+	}
+	err := w.client.GetJSON(ctx, "https://api.weixin.qq.com/sns/oauth2/access_token", url.Values{
+		"appid":      {w.cfg.ClientId},
+		"secret":     {w.cfg.ClientSecret},
+		"code":       {code},
+		"grant_type": {"authorization_code"},
+	}, &token)
+	if err != nil {
+		return nil, err
+	}
+	if err := token.err(); err != nil {
+		return nil, err
+	}
+	info := &UserInfo{Provider: w.name, OpenId: token.OpenId, UnionId: token.UnionId}
+	// snsapi_base只能获取openid
+	if w.scope() == "snsapi_base" {
+		return info, nil
+	}
+
+	var user struct {
+		wechatError
+		Nickname   string `json:"nickname"`
+		HeadImgUrl string `json:"headimgurl"`
+		UnionId    string `json:"unionid"`
+	}
+	err = w.client.GetJSON(ctx, "https://api.weixin.qq.com/sns/userinfo", url.Values{
+		"access_token": {token.AccessToken},
+		"openid":       {token.OpenId},
+	}, &user)
+	if err != nil {
+		return nil, err
+	}
+	if err := user.err(); err != nil {
+		return nil, err
+	}
+	info.Nickname = user.Nickname
+	info.Avatar = user.HeadImgUrl
+	if user.UnionId != "" {
+		info.UnionId = user.UnionId
+	}
+	return info, nil
+}
@@ -0,0 +1,219 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"go-skeleton/model"
+	"go-skeleton/pkg/broker"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/eventbus"
+	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/utils"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 事务发件箱：事件和业务数据在同一事务中写入outbox_event表，由relay轮询发送到消息中间件，eg:
+//
+//	err := simpleDb.DB().Transaction(func(tx *gorm.DB) error {
+//		if err := tx.Create(&order).Error; err != nil {
+//			return err
+//		}
+//		return outbox.AddEvent(tx, eventbus.OrderCreated{...})
+//	})
+//
+// 发送成功后才标记为已发送，进程崩溃时可能重复发送(至少一次)，消费者需要幂等
+
+const (
+	lockKey = "outbox:relay"
+	// 重试的最大等待时间
+	maxBackoff = 10 * time.Minute
+	// LastError的最大长度，和表结构一致
+	maxErrorLen = 512
+)
+
+// Add 在事务tx中写入待发送的消息，v使用json编码，未启用outbox时不写入
+func Add(tx *gorm.DB, topic, key string, v interface{}) error {
+	if !config.Conf.OutboxConfig.Enable {
+		return nil
+	}
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&model.OutboxEvent{Topic: topic, Key: key, Payload: string(payload)}).Error
+}
+
+// AddEvent 写入领域事件，topic为事件名
+func AddEvent(tx *gorm.DB, e eventbus.Event) error {
+	return Add(tx, e.EventName(), "", e)
+}
+
+// Relay 轮询未发送的事件并发送到消息中间件，多实例部署时通过分布式锁保证同一时间只有一个实例发送
+type Relay struct {
+	cfg  config.OutboxConfig
+	quit chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+var defaultRelay *Relay
+
+// NewRelay 按配置创建relay，未配置的项使用默认值
+func NewRelay(cfg config.OutboxConfig) *Relay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+	return &Relay{cfg: cfg, quit: make(chan struct{})}
+}
+
+// Start 按配置文件outbox启动relay
+func Start() {
+	defaultRelay = NewRelay(config.Conf.OutboxConfig)
+	defaultRelay.Start()
+}
+
+// Stop 停止Start启动的relay
+func Stop(ctx context.Context) error {
+	if defaultRelay == nil {
+		return nil
+	}
+	return defaultRelay.Stop(ctx)
+}
+
+// Start 启动轮询协程
+func (r *Relay) Start() {
+	r.wg.Add(1)
+	go r.loop()
+	zap.L().Info("outbox relay started", zap.Duration("interval", r.cfg.PollInterval), zap.Int("batch", r.cfg.BatchSize))
+}
+
+// Stop 停止轮询，等待发送中的批次完成，ctx超时后直接返回
+func (r *Relay) Stop(ctx context.Context) error {
+	r.once.Do(func() {
+		close(r.quit)
+	})
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Relay) loop() {
+	defer r.wg.Done()
+	for {
+		n, err := r.poll()
+		if err != nil {
+			zap.L().Error("outbox poll failed", zap.Error(err))
+		}
+		// 一批没有发送完时立即继续
+		wait := r.cfg.PollInterval
+		if n >= r.cfg.BatchSize {
+			wait = 0
+		}
+		select {
+		case <-r.quit:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// poll 获取锁后发送一批到期的事件，返回处理的条数
+func (r *Relay) poll() (int, error) {
+	ctx := context.Background()
+	lock := utils.NewDistributedLock(lockKey, r.cfg.PollInterval+time.Minute)
+	ok, err := lock.TryLock(ctx)
+	if err != nil || !ok {
+		return 0, err
+	}
+	defer lock.Unlock(ctx)
+
+	var events []model.OutboxEvent
+	err = simpleDb.DB().
+		Where("sent_at = 0 AND next_attempt_at <= ? AND attempts < ?", time.Now().Unix(), r.cfg.MaxAttempts).
+		Order("id").Limit(r.cfg.BatchSize).Find(&events).Error
+	if err != nil {
+		return 0, err
+	}
+	for i := range events {
+		select {
+		case <-r.quit:
+			return i, nil
+		default:
+		}
+		r.send(ctx, &events[i])
+	}
+	return len(events), nil
+}
+
+// send 发送一条事件，成功时标记为已发送，失败时按次数延后重试
+func (r *Relay) send(ctx context.Context, e *model.OutboxEvent) {
+	err := broker.PublishJSON(ctx, e.Topic, e.Key, json.RawMessage(e.Payload))
+	if err == nil {
+		if err := simpleDb.DB().Model(e).Update("sent_at", time.Now().Unix()).Error; err != nil {
+			zap.L().Error("outbox mark sent failed", zap.Int64("id", e.ID), zap.Error(err))
+		}
+		return
+	}
+
+	attempts := e.Attempts + 1
+	logger := zap.L().With(zap.Int64("id", e.ID), zap.String("topic", e.Topic), zap.Int("attempts", attempts), zap.Error(err))
+	if attempts >= r.cfg.MaxAttempts {
+		logger.Error("outbox event dead")
+	} else {
+		logger.Warn("outbox publish failed, retry later")
+	}
+	msg := err.Error()
+	if len(msg) > maxErrorLen {
+		msg = msg[:maxErrorLen]
+	}
+	err = simpleDb.DB().Model(e).Updates(map[string]interface{}{
+		"attempts":        attempts,
+		"last_error":      msg,
+		"next_attempt_at": time.Now().Add(backoff(attempts)).Unix(),
+	}).Error
+	if err != nil {
+		logger.Error("outbox mark failed failed", zap.NamedError("update_error", err))
+	}
+}
+
+// backoff 按失败次数指数增长的等待时间
+func backoff(attempts int) time.Duration {
+	d := time.Second << uint(attempts)
+	if d <= 0 || d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
+
+// Cleanup 删除超过保留时长的已发送事件，Retention为0时不清理
+func Cleanup(ctx context.Context) error {
+	retention := config.Conf.OutboxConfig.Retention
+	if retention <= 0 {
+		return nil
+	}
+	before := time.Now().Add(-retention).Unix()
+	ret := simpleDb.WithContext(ctx).Where("sent_at > 0 AND sent_at < ?", before).Delete(&model.OutboxEvent{})
+	if ret.Error != nil {
+		return ret.Error
+	}
+	zap.L().Info("outbox cleanup", zap.Int64("deleted", ret.RowsAffected))
+	return nil
+}
@@ -0,0 +1,185 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/upload"
+	"html/template"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// 由html模板生成pdf，eg:
+//	b, err := pdf.Render(ctx, "invoice", invoice)
+//	file, err := pdf.Generate(ctx, "invoice", invoice, "发票-2021001.pdf")
+
+// ContentType pdf的MIME类型
+const ContentType = "application/pdf"
+
+// ErrTemplateNotFound 模板不存在或名称不合法
+var ErrTemplateNotFound = errors.New("pdf: template not found")
+
+var templateNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Converter 将html转换为pdf，默认使用wkhtmltopdf，可以通过SetConverter替换，eg: chrome headless或纯go的实现
+type Converter interface {
+	Convert(ctx context.Context, html []byte, w io.Writer) error
+}
+
+// Wkhtmltopdf 调用wkhtmltopdf命令转换，html从标准输入读取，pdf输出到标准输出
+type Wkhtmltopdf struct {
+	Binary  string
+	Options []string
+}
+
+func (c *Wkhtmltopdf) Convert(ctx context.Context, html []byte, w io.Writer) error {
+	binary := c.Binary
+	if binary == "" {
+		binary = "wkhtmltopdf"
+	}
+	args := append([]string{"--quiet", "--encoding", "utf-8"}, c.Options...)
+	// 禁止访问本地文件，模板中的图片等资源使用http地址或data uri
+	args = append(args, "--disable-local-file-access", "-", "-")
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Stdin = bytes.NewReader(html)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("pdf: wkhtmltopdf: %w", ctx.Err())
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("pdf: wkhtmltopdf: %v: %s", err, msg)
+		}
+		return fmt.Errorf("pdf: wkhtmltopdf: %w", err)
+	}
+	return nil
+}
+
+var (
+	converterMu sync.RWMutex
+	converter   Converter
+
+	// 已解析的模板，debug模式下每次重新解析，方便修改模板
+	templatesMu sync.RWMutex
+	templates   = map[string]*template.Template{}
+)
+
+// SetConverter 替换默认的wkhtmltopdf
+func SetConverter(c Converter) {
+	converterMu.Lock()
+	defer converterMu.Unlock()
+	converter = c
+}
+
+func getConverter() Converter {
+	converterMu.RLock()
+	defer converterMu.RUnlock()
+	if converter != nil {
+		return converter
+	}
+	cfg := config.Conf.PdfConfig
+	return &Wkhtmltopdf{Binary: cfg.Binary, Options: cfg.Options}
+}
+
+// RenderHTML 渲染模板目录中的{name}.html
+func RenderHTML(name string, data interface{}) ([]byte, error) {
+	t, err := loadTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Render 渲染模板并转换为pdf，超时时间见配置pdf.Timeout
+func Render(ctx context.Context, name string, data interface{}) ([]byte, error) {
+	html, err := RenderHTML(name, data)
+	if err != nil {
+		return nil, err
+	}
+	if timeout := config.Conf.PdfConfig.Timeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	var buf bytes.Buffer
+	if err := getConverter().Convert(ctx, html, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Generate 生成pdf并保存到上传存储的pdf.Dir目录，filename为下载时的文件名
+func Generate(ctx context.Context, name string, data interface{}, filename string) (*upload.File, error) {
+	b, err := Render(ctx, name, data)
+	if err != nil {
+		return nil, err
+	}
+	s, err := upload.GetStorage()
+	if err != nil {
+		return nil, err
+	}
+	dir := config.Conf.PdfConfig.Dir
+	if dir == "" {
+		dir = "pdf"
+	}
+	file := &upload.File{
+		Key:      upload.GenerateKey(dir, ".pdf"),
+		Name:     filename,
+		Size:     int64(len(b)),
+		MimeType: ContentType,
+	}
+	if err := s.Put(ctx, file.Key, bytes.NewReader(b), file.Size, ContentType); err != nil {
+		return nil, err
+	}
+	file.URL = s.URL(file.Key)
+	return file, nil
+}
+
+// HasTemplate 模板是否存在
+func HasTemplate(name string) bool {
+	_, err := loadTemplate(name)
+	return err == nil
+}
+
+func loadTemplate(name string) (*template.Template, error) {
+	if !templateNameRegexp.MatchString(name) {
+		return nil, ErrTemplateNotFound
+	}
+	debug := config.Conf.AppMode == "debug"
+	if !debug {
+		templatesMu.RLock()
+		t, ok := templates[name]
+		templatesMu.RUnlock()
+		if ok {
+			return t, nil
+		}
+	}
+	t, err := template.ParseFiles(filepath.Join(config.Conf.PdfConfig.TemplateDir, name+".html"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	if !debug {
+		templatesMu.Lock()
+		templates[name] = t
+		templatesMu.Unlock()
+	}
+	return t, nil
+}
@@ -0,0 +1,187 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/utils"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 基于redis有序集合的任务队列，和machinery相互独立，eg:
+//	queue.Handle("email.send", func(ctx context.Context, job *queue.Job) error {
+//		var mail Mail
+//		if err := job.Bind(&mail); err != nil {
+//			return err
+//		}
+//		return send(ctx, &mail)
+//	})
+//	queue.Enqueue(ctx, "email.send", mail, queue.WithDelay(time.Minute))
+
+// 默认队列
+const DefaultQueue = "default"
+
+// 任务
+type Job struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Queue   string          `json:"queue"`
+	Payload json.RawMessage `json:"payload"`
+	// 已失败的次数
+	Attempts  int    `json:"attempts"`
+	MaxRetry  int    `json:"max_retry"`
+	CreatedAt int64  `json:"created_at"`
+	LastError string `json:"last_error,omitempty"`
+	FailedAt  int64  `json:"failed_at,omitempty"`
+
+	// 从redis中取出时的原始数据，用于确认任务
+	raw string
+}
+
+// Bind 将任务参数解析到v
+func (j *Job) Bind(v interface{}) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// JobHandler 任务处理方法，返回错误时按退避时间重试
+type JobHandler func(ctx context.Context, job *Job) error
+
+// ErrSkipRetry 处理方法返回此错误(或包装了此错误)时不再重试，直接进入死信队列
+var ErrSkipRetry = errors.New("queue: skip retry")
+
+var (
+	handlersMu sync.RWMutex
+	handlers   = map[string]JobHandler{}
+)
+
+// Handle 注册任务类型的处理方法，eg: article.index、email.send
+func Handle(jobType string, handler JobHandler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	handlers[jobType] = handler
+}
+
+func getHandler(jobType string) JobHandler {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	return handlers[jobType]
+}
+
+// EnqueueOption 投递选项
+type EnqueueOption func(o *enqueueOptions)
+
+type enqueueOptions struct {
+	queue    string
+	delay    time.Duration
+	maxRetry int
+}
+
+// WithQueue 投递到指定队列
+func WithQueue(name string) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.queue = name
+	}
+}
+
+// WithDelay 延迟执行
+func WithDelay(delay time.Duration) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.delay = delay
+	}
+}
+
+// WithMaxRetry 最大重试次数，0为不重试
+func WithMaxRetry(n int) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.maxRetry = n
+	}
+}
+
+// Enqueue 投递任务，payload编码为json
+func Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...EnqueueOption) (*Job, error) {
+	o := &enqueueOptions{queue: DefaultQueue, maxRetry: config.Conf.QueueConfig.MaxRetry}
+	for _, opt := range opts {
+		opt(o)
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	job := &Job{
+		ID:        utils.UUID(),
+		Type:      jobType,
+		Queue:     o.queue,
+		Payload:   b,
+		MaxRetry:  o.maxRetry,
+		CreatedAt: time.Now().Unix(),
+	}
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	runAt := time.Now().Add(o.delay)
+	if err := gredis.GetRedis().ZAdd(ctx, readyKey(job.Queue), redisZ(runAt, string(raw))).Err(); err != nil {
+		return nil, fmt.Errorf("queue: enqueue %s: %w", jobType, err)
+	}
+	return job, nil
+}
+
+// DeadJobs 死信队列中最近的任务
+func DeadJobs(ctx context.Context, queue string, limit int64) ([]*Job, error) {
+	raws, err := gredis.GetRedis().LRange(ctx, deadKey(queue), 0, limit-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*Job, 0, len(raws))
+	for _, raw := range raws {
+		job := &Job{}
+		if err := json.Unmarshal([]byte(raw), job); err != nil {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// RetryDead 将死信队列中的任务重新投递，失败次数清零，返回投递的任务数
+func RetryDead(ctx context.Context, queue string) (int, error) {
+	rdb := gredis.GetRedis()
+	count := 0
+	for {
+		raw, err := rdb.RPop(ctx, deadKey(queue)).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				return count, nil
+			}
+			return count, err
+		}
+		job := &Job{}
+		if err := json.Unmarshal([]byte(raw), job); err != nil {
+			continue
+		}
+		job.Attempts, job.LastError, job.FailedAt = 0, "", 0
+		b, _ := json.Marshal(job)
+		if err := rdb.ZAdd(ctx, readyKey(queue), redisZ(time.Now(), string(b))).Err(); err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+func readyKey(queue string) string {
+	return "jobs:" + queue
+}
+
+func processingKey(queue string) string {
+	return "jobs:" + queue + ":processing"
+}
+
+func deadKey(queue string) string {
+	return "jobs:" + queue + ":dead"
+}
@@ -9,7 +9,10 @@ import (
 	queueConfig "github.com/RichardKnop/machinery/v1/config"
 )
 
-var server *machinery.Server
+var (
+	server *machinery.Server
+	worker *machinery.Worker
+)
 
 func GetServer() *machinery.Server {
 	return server
@@ -62,14 +65,21 @@ func InitQueue() error {
 	_ = server.RegisterTask("error", task.ErrorTask)
 
 	//起一个协程执行注册worker执行任务
-	go func(s *machinery.Server) {
-		worker := s.NewWorker("default_queue_work", 10)
-		//处理错误handle
-		//worker.SetErrorHandler(func(err error) {
-		//
-		//})
-		_ = worker.Launch()
-	}(server)
+	worker = server.NewWorker("default_queue_work", 10)
+	//处理错误handle
+	//worker.SetErrorHandler(func(err error) {
+	//
+	//})
+	go func(w *machinery.Worker) {
+		_ = w.Launch()
+	}(worker)
 
 	return nil
 }
+
+// Stop 停止worker，等待执行中的任务完成
+func Stop() {
+	if worker != nil {
+		worker.Quit()
+	}
+}
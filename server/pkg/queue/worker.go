@@ -0,0 +1,273 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/pkg/tenant"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// 取出一个已到执行时间的任务，放入处理中集合，score为确认的截止时间
+var dequeueScript = redis.NewScript(`
+local jobs = redis.call("zrangebyscore", KEYS[1], "-inf", ARGV[1], "limit", 0, 1)
+if #jobs == 0 then
+	return false
+end
+redis.call("zrem", KEYS[1], jobs[1])
+redis.call("zadd", KEYS[2], ARGV[2], jobs[1])
+return jobs[1]
+`)
+
+// 将超时未确认的任务(eg: worker崩溃)放回队列
+var requeueScript = redis.NewScript(`
+local jobs = redis.call("zrangebyscore", KEYS[2], "-inf", ARGV[1], "limit", 0, 100)
+for _, job in ipairs(jobs) do
+	redis.call("zrem", KEYS[2], job)
+	redis.call("zadd", KEYS[1], ARGV[1], job)
+end
+return #jobs
+`)
+
+// 重试的最大等待时间
+const maxBackoff = 10 * time.Minute
+
+// Worker 从队列中取出任务并执行，Stop时等待执行中的任务完成
+type Worker struct {
+	client redis.Cmdable
+	cfg    config.QueueConfig
+	quit   chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+var defaultWorker *Worker
+
+// NewWorker 按配置创建worker，未配置的项使用默认值
+func NewWorker(client redis.Cmdable, cfg config.QueueConfig) *Worker {
+	if len(cfg.Queues) == 0 {
+		cfg.Queues = []string{DefaultQueue}
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 10
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Minute
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.DeadLetterMax <= 0 {
+		cfg.DeadLetterMax = 1000
+	}
+	return &Worker{client: client, cfg: cfg, quit: make(chan struct{})}
+}
+
+// StartWorker 按配置文件queue启动worker
+func StartWorker() {
+	defaultWorker = NewWorker(gredis.GetRedis(), config.Conf.QueueConfig)
+	defaultWorker.Start()
+}
+
+// StopWorker 停止StartWorker启动的worker
+func StopWorker(ctx context.Context) error {
+	if defaultWorker == nil {
+		return nil
+	}
+	return defaultWorker.Stop(ctx)
+}
+
+// Start 启动Concurrency个协程处理任务，以及一个协程回收超时的任务
+func (w *Worker) Start() {
+	for i := 0; i < w.cfg.Concurrency; i++ {
+		w.wg.Add(1)
+		go w.loop()
+	}
+	w.wg.Add(1)
+	go w.reap()
+	zap.L().Info("queue worker started", zap.Strings("queues", w.cfg.Queues), zap.Int("concurrency", w.cfg.Concurrency))
+}
+
+// Stop 不再取新任务，等待执行中的任务完成，ctx超时后直接返回，未确认的任务会在超时后重新投递
+func (w *Worker) Stop(ctx context.Context) error {
+	w.once.Do(func() {
+		close(w.quit)
+	})
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *Worker) loop() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.quit:
+			return
+		default:
+		}
+		job, err := w.dequeue()
+		if err != nil {
+			zap.L().Error("queue dequeue failed", zap.Error(err))
+		}
+		if job == nil {
+			select {
+			case <-w.quit:
+				return
+			case <-time.After(w.cfg.PollInterval):
+			}
+			continue
+		}
+		w.process(job)
+	}
+}
+
+// dequeue 按队列顺序取出一个任务，没有任务时返回nil
+func (w *Worker) dequeue() (*Job, error) {
+	ctx := context.Background()
+	now := time.Now()
+	deadline := now.Add(w.cfg.Timeout + w.cfg.PollInterval)
+	for _, queue := range w.cfg.Queues {
+		raw, err := dequeueScript.Run(ctx, w.client, []string{readyKey(queue), processingKey(queue)},
+			millis(now), millis(deadline)).Text()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		job := &Job{}
+		if err := json.Unmarshal([]byte(raw), job); err != nil {
+			// 无法解析的任务直接丢弃
+			zap.L().Error("queue invalid job", zap.String("queue", queue), zap.String("job", raw), zap.Error(err))
+			w.client.ZRem(ctx, processingKey(queue), raw)
+			continue
+		}
+		job.Queue, job.raw = queue, raw
+		return job, nil
+	}
+	return nil, nil
+}
+
+func (w *Worker) process(job *Job) {
+	start := time.Now()
+	err := w.run(job)
+	logger := zap.L().With(zap.String("id", job.ID), zap.String("type", job.Type), zap.String("queue", job.Queue),
+		zap.Int("attempts", job.Attempts), zap.Duration("cost", time.Since(start)))
+	if err == nil {
+		if err := w.client.ZRem(context.Background(), processingKey(job.Queue), job.raw).Err(); err != nil {
+			logger.Error("queue ack failed", zap.Error(err))
+		}
+		return
+	}
+
+	job.Attempts++
+	job.LastError = err.Error()
+	if job.Attempts > job.MaxRetry || errors.Is(err, ErrSkipRetry) {
+		logger.Error("queue job dead", zap.Error(err))
+		w.bury(job)
+		return
+	}
+	logger.Warn("queue job failed, retry later", zap.Error(err))
+	w.retry(job)
+}
+
+// run 执行任务，panic时返回错误
+func (w *Worker) run(job *Job) (err error) {
+	handler := getHandler(job.Type)
+	if handler == nil {
+		return fmt.Errorf("%w: no handler for %s", ErrSkipRetry, job.Type)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			zap.L().Error("queue job panic", zap.String("id", job.ID), zap.Any("panic", r), zap.String("stack", string(debug.Stack())))
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	// 任务按id处理数据，不按租户过滤
+	ctx, cancel := context.WithTimeout(tenant.SkipContext(context.Background()), w.cfg.Timeout)
+	defer cancel()
+	return handler(ctx, job)
+}
+
+// retry 按指数退避重新放回队列
+func (w *Worker) retry(job *Job) {
+	backoff := time.Duration(1<<uint(job.Attempts)) * time.Second
+	if backoff > maxBackoff || backoff <= 0 {
+		backoff = maxBackoff
+	}
+	raw, _ := json.Marshal(job)
+	ctx := context.Background()
+	_, err := w.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, processingKey(job.Queue), job.raw)
+		pipe.ZAdd(ctx, readyKey(job.Queue), redisZ(time.Now().Add(backoff), string(raw)))
+		return nil
+	})
+	if err != nil {
+		zap.L().Error("queue retry failed", zap.String("id", job.ID), zap.Error(err))
+	}
+}
+
+// bury 放入死信队列
+func (w *Worker) bury(job *Job) {
+	job.FailedAt = time.Now().Unix()
+	raw, _ := json.Marshal(job)
+	ctx := context.Background()
+	_, err := w.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, processingKey(job.Queue), job.raw)
+		pipe.LPush(ctx, deadKey(job.Queue), raw)
+		pipe.LTrim(ctx, deadKey(job.Queue), 0, w.cfg.DeadLetterMax-1)
+		return nil
+	})
+	if err != nil {
+		zap.L().Error("queue bury failed", zap.String("id", job.ID), zap.Error(err))
+	}
+}
+
+// reap 定期将超时未确认的任务放回队列
+func (w *Worker) reap() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.cfg.PollInterval * 5)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.quit:
+			return
+		case <-ticker.C:
+		}
+		for _, queue := range w.cfg.Queues {
+			n, err := requeueScript.Run(context.Background(), w.client,
+				[]string{readyKey(queue), processingKey(queue)}, millis(time.Now())).Int()
+			if err != nil {
+				zap.L().Error("queue requeue failed", zap.String("queue", queue), zap.Error(err))
+			} else if n > 0 {
+				zap.L().Warn("queue requeued timeout jobs", zap.String("queue", queue), zap.Int("count", n))
+			}
+		}
+	}
+}
+
+func millis(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
+
+func redisZ(t time.Time, member string) *redis.Z {
+	return &redis.Z{Score: float64(t.UnixNano() / int64(time.Millisecond)), Member: member}
+}
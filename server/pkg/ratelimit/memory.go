@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/juju/ratelimit"
+)
+
+// 超过该时间未访问的令牌桶会被清理
+const bucketIdleTimeout = 10 * time.Minute
+
+type bucket struct {
+	*ratelimit.Bucket
+	lastSeen time.Time
+}
+
+// MemoryLimiter 单机的令牌桶限流，每个key一个桶，适合单实例部署
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow 桶容量为limit，每window/limit补充一个令牌
+func (l *MemoryLimiter) Allow(_ context.Context, key string, limit int, window time.Duration) (*Result, error) {
+	if limit <= 0 || window <= 0 {
+		return &Result{Allowed: true}, nil
+	}
+	interval := window / time.Duration(limit)
+	if interval <= 0 {
+		interval = 1
+	}
+
+	b := l.get(fmt.Sprintf("%s:%d/%s", key, limit, window), interval, int64(limit))
+	ret := &Result{
+		Allowed: b.TakeAvailable(1) == 1,
+		Limit:   limit,
+	}
+	available := b.Available()
+	if available < 0 {
+		available = 0
+	}
+	ret.Remaining = int(available)
+	ret.ResetAfter = time.Duration(int64(limit)-available) * interval
+	if !ret.Allowed {
+		ret.RetryAfter = interval
+	}
+	return ret, nil
+}
+
+func (l *MemoryLimiter) get(key string, interval time.Duration, capacity int64) *bucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > time.Minute {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastSeen) > bucketIdleTimeout {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{Bucket: ratelimit.NewBucket(interval, capacity)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	return b
+}
@@ -0,0 +1,24 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Result 一次限流判断的结果
+type Result struct {
+	Allowed bool
+	// 窗口内允许的请求数
+	Limit int
+	// 剩余可用的请求数
+	Remaining int
+	// 被拒绝时需要等待的时间
+	RetryAfter time.Duration
+	// 额度完全恢复需要的时间
+	ResetAfter time.Duration
+}
+
+// Limiter 限流器，window时间内同一个key最多允许limit次请求
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (*Result, error)
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 滑动窗口：移除窗口外的记录后统计数量，未超限时记录本次请求
+// 返回 {是否允许, 剩余次数, 需要等待的毫秒数}
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call("zremrangebyscore", KEYS[1], 0, now - window)
+local count = redis.call("zcard", KEYS[1])
+if count < limit then
+	redis.call("zadd", KEYS[1], now, ARGV[4])
+	redis.call("pexpire", KEYS[1], window)
+	return {1, limit - count - 1, 0}
+end
+local oldest = redis.call("zrange", KEYS[1], 0, 0, "withscores")
+local wait = window
+if oldest[2] then
+	wait = tonumber(oldest[2]) + window - now
+end
+return {0, 0, wait}
+`)
+
+// RedisLimiter 基于redis有序集合的滑动窗口限流，多实例共享额度，适合集群部署
+type RedisLimiter struct {
+	client redis.Cmdable
+	prefix string
+}
+
+func NewRedisLimiter(client redis.Cmdable) *RedisLimiter {
+	return &RedisLimiter{client: client, prefix: "ratelimit:"}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (*Result, error) {
+	if limit <= 0 || window <= 0 {
+		return &Result{Allowed: true}, nil
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	member := strconv.FormatInt(now, 10) + "-" + hex.EncodeToString(b)
+	key = l.prefix + key + ":" + strconv.Itoa(limit) + "/" + window.String()
+
+	reply, err := slidingWindowScript.Run(ctx, l.client, []string{key}, now, window.Milliseconds(), limit, member).Result()
+	if err != nil {
+		return nil, err
+	}
+	vals, ok := reply.([]interface{})
+	if !ok || len(vals) != 3 {
+		return nil, fmt.Errorf("ratelimit: unexpected reply %v", reply)
+	}
+	allowed, _ := vals[0].(int64)
+	remaining, _ := vals[1].(int64)
+	wait, _ := vals[2].(int64)
+	ret := &Result{
+		Allowed:    allowed == 1,
+		Limit:      limit,
+		Remaining:  int(remaining),
+		ResetAfter: window,
+	}
+	if !ret.Allowed {
+		ret.RetryAfter = time.Duration(wait) * time.Millisecond
+		ret.ResetAfter = ret.RetryAfter
+	}
+	return ret, nil
+}
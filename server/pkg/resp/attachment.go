@@ -0,0 +1,87 @@
+package resp
+
+import (
+	"go-skeleton/pkg/excel"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AttachmentWriter 下载文件的响应，第一次写入时才设置响应头，写入之前出错时仍可以用Error输出json
+type AttachmentWriter struct {
+	c           *gin.Context
+	filename    string
+	contentType string
+	written     bool
+}
+
+// Attachment 以附件形式下载，filename可以包含中文，eg: resp.Attachment(c, "标签.xlsx", excel.ContentType)
+func Attachment(c *gin.Context, filename, contentType string) *AttachmentWriter {
+	return &AttachmentWriter{c: c, filename: filename, contentType: contentType}
+}
+
+func (w *AttachmentWriter) Write(p []byte) (int, error) {
+	if !w.written {
+		w.written = true
+		header := w.c.Writer.Header()
+		header.Set("Content-Type", w.contentType)
+		header.Set("Content-Disposition", contentDisposition(w.filename))
+		header.Set("Cache-Control", "no-store")
+	}
+	return w.c.Writer.Write(p)
+}
+
+// Flush 将已写入的数据发送到客户端
+func (w *AttachmentWriter) Flush() {
+	if w.written {
+		w.c.Writer.Flush()
+	}
+}
+
+// Written 是否已经开始输出
+func (w *AttachmentWriter) Written() bool {
+	return w.written
+}
+
+var (
+	_ io.Writer    = (*AttachmentWriter)(nil)
+	_ http.Flusher = (*AttachmentWriter)(nil)
+)
+
+// CSV 逐页查询并以csv附件的形式流式输出，charset见excel.EncodingUTF8BOM等，
+// 开始输出之前出错时返回json，之后出错只能中断响应并记录日志
+func CSV(c *gin.Context, filename, charset string, model interface{}, fetch excel.Fetch) {
+	contentType := excel.CSVContentType + "; charset=utf-8"
+	if strings.EqualFold(charset, excel.EncodingGBK) {
+		contentType = excel.CSVContentType + "; charset=gbk"
+	}
+	w := Attachment(c, filename, contentType)
+	rows, err := excel.ExportCSV(w, model, charset, fetch)
+	if err == nil {
+		return
+	}
+	if !w.Written() {
+		Error(c, err)
+		return
+	}
+	zap.L().Error("csv export aborted", zap.String("filename", filename), zap.Int("rows", rows), zap.Error(err))
+	c.Abort()
+}
+
+// contentDisposition 非ASCII的文件名使用RFC 5987编码，同时保留一个ASCII的文件名兼容旧浏览器
+func contentDisposition(filename string) string {
+	ascii := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+	if ascii == filename {
+		return `attachment; filename="` + filename + `"`
+	}
+	return `attachment; filename="` + ascii + `"; filename*=UTF-8''` + url.PathEscape(filename)
+}
@@ -0,0 +1,28 @@
+package resp
+
+import (
+	"go-skeleton/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotModified 按数据的版本生成弱ETag，与请求的If-None-Match一致时返回304，调用方不需要再查询数据，
+// version可以由update_time、最大id、条数等组成，查询参数不同的请求由客户端按url区分，eg:
+//
+//	version, _ := services.NotificationService.Version(ctx, userId)
+//	if resp.NotModified(c, version) {
+//		return
+//	}
+func NotModified(c *gin.Context, version string) bool {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+	etag := utils.ETag(true, []byte(version))
+	c.Header("ETag", etag)
+	if utils.ETagMatch(c.GetHeader("If-None-Match"), etag) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package resp
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PNG 输出png图片，设置Cache-Control和ETag，内容未变化(If-None-Match相同)时返回304，
+// 适合二维码、条形码这类由参数决定内容的图片
+func PNG(c *gin.Context, b []byte, maxAge time.Duration) {
+	Bytes(c, "image/png", b, maxAge)
+}
+
+// Bytes 输出二进制内容并设置缓存响应头，maxAge为0时不缓存
+func Bytes(c *gin.Context, contentType string, b []byte, maxAge time.Duration) {
+	sum := sha1.Sum(b)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	header := c.Writer.Header()
+	header.Set("ETag", etag)
+	if maxAge > 0 {
+		header.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+	} else {
+		header.Set("Cache-Control", "no-cache")
+	}
+	if etagMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, contentType, b)
+}
+
+// etagMatch If-None-Match中是否包含etag，支持多个值及弱校验W/前缀
+func etagMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, v := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(v), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
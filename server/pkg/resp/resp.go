@@ -0,0 +1,71 @@
+package resp
+
+import (
+	stderrors "errors"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/i18n"
+	"go-skeleton/pkg/jsonresult"
+	"go-skeleton/pkg/mask"
+	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 统一的响应输出，格式见jsonresult.JsonResult，数据按请求脱敏，见mask包
+
+// OK 成功并返回数据
+func OK(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusOK, jsonresult.JsonData(masked(c, data)))
+}
+
+// Success 成功，不返回数据
+func Success(c *gin.Context) {
+	c.JSON(http.StatusOK, jsonresult.JsonSuccess())
+}
+
+// Page 分页数据
+func Page(c *gin.Context, list interface{}, paging *simpleDb.Paging) {
+	c.JSON(http.StatusOK, jsonresult.JsonPageData(masked(c, list), paging))
+}
+
+// Cursor 游标分页数据
+func Cursor(c *gin.Context, list interface{}, cursor string) {
+	c.JSON(http.StatusOK, jsonresult.JsonCursorData(masked(c, list), cursor))
+}
+
+// Fail 失败，msg为空时使用错误码注册的提示，按请求的语言翻译
+func Fail(c *gin.Context, code int, msg string) {
+	if msg == "" {
+		if e, ok := errors.Lookup(code); ok {
+			msg = i18n.Error(c, e).Message
+		}
+	}
+	c.JSON(http.StatusOK, jsonresult.JsonErrorCode(code, msg))
+}
+
+// Error 根据err输出失败信息，*errors.CodeError保留其错误码，提示按请求的语言翻译。
+// 其他错误(数据库、网络等)可能包含内部信息，记录日志后返回errors.InternalError
+func Error(c *gin.Context, err error) {
+	var ce *errors.CodeError
+	if !stderrors.As(err, &ce) {
+		zap.L().Error(c.Request.URL.Path, zap.String("request-id", utils.RequestID(c)), zap.Error(err))
+		ce = errors.InternalError
+	}
+	c.JSON(http.StatusOK, jsonresult.JsonCodeError(i18n.Error(c, ce)))
+}
+
+// Abort 输出失败信息并中止后续的处理，用于中间件
+func Abort(c *gin.Context, status int, err *errors.CodeError) {
+	c.AbortWithStatusJSON(status, jsonresult.JsonCodeError(i18n.Error(c, err)))
+}
+
+// masked 未关闭脱敏的请求返回脱敏后的副本
+func masked(c *gin.Context, data interface{}) interface{} {
+	if !mask.Enabled(c) {
+		return data
+	}
+	return mask.Apply(data)
+}
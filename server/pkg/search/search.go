@@ -0,0 +1,282 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/utils/httpclient"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrDisabled 未开启全文搜索
+var ErrDisabled = errors.New("search: disabled")
+
+var (
+	once sync.Once
+	cli  *httpclient.Client
+)
+
+// 索引中的文章，content只用于检索和高亮，搜索结果中不返回
+type ArticleDoc struct {
+	Id          int64    `json:"id"`
+	Title       string   `json:"title"`
+	Desc        string   `json:"desc"`
+	Content     string   `json:"content,omitempty"`
+	Img         string   `json:"img"`
+	Cid         uint64   `json:"cid"`
+	UserId      int64    `json:"user_id"`
+	Slug        string   `json:"slug"`
+	Tags        []string `json:"tags"`
+	PublishedAt int64    `json:"published_at"`
+}
+
+// 搜索条件，Keyword为空时按发布时间倒序返回
+type Query struct {
+	Keyword string
+	Tags    []string
+	Cid     uint64
+	Page    int
+	Limit   int
+}
+
+// 搜索结果，Highlight的key为字段名，value为高亮片段，关键词使用<em>包裹
+type Hit struct {
+	ArticleDoc
+	Highlight map[string][]string `json:"highlight,omitempty"`
+}
+
+type Result struct {
+	Total int64 `json:"total"`
+	Hits  []Hit `json:"hits"`
+}
+
+// Enabled 是否开启了全文搜索
+func Enabled() bool {
+	return config.Conf.SearchConfig.Enable
+}
+
+func client() *httpclient.Client {
+	once.Do(func() {
+		cfg := config.Conf.SearchConfig
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		opts := []httpclient.Option{
+			httpclient.WithBaseURL(cfg.Url),
+			httpclient.WithTimeout(timeout),
+			httpclient.WithHeader("Content-Type", "application/json"),
+		}
+		if cfg.Username != "" {
+			auth := base64.StdEncoding.EncodeToString([]byte(cfg.Username + ":" + cfg.Password))
+			opts = append(opts, httpclient.WithHeader("Authorization", "Basic "+auth))
+		}
+		cli = httpclient.New(opts...)
+	})
+	return cli
+}
+
+func index() string {
+	return config.Conf.SearchConfig.Index
+}
+
+// request 发送请求到es，body不为[]byte时编码为json，out不为nil时解析响应
+func request(ctx context.Context, method, path string, body, out interface{}) error {
+	if !Enabled() {
+		return ErrDisabled
+	}
+	req := &httpclient.Request{Method: method, URL: path}
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		req.Body = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		req.Body = data
+	}
+	resp, err := client().Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return resp.JSON(out)
+}
+
+// EnsureIndex 索引不存在时按mapping创建
+func EnsureIndex(ctx context.Context) error {
+	err := request(ctx, http.MethodHead, "/"+index(), nil, nil)
+	if err == nil {
+		return nil
+	}
+	var se *httpclient.StatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusNotFound {
+		return err
+	}
+
+	analyzer := config.Conf.SearchConfig.Analyzer
+	if analyzer == "" {
+		analyzer = "standard"
+	}
+	text := map[string]interface{}{"type": "text", "analyzer": analyzer}
+	mapping := map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"id":           map[string]string{"type": "long"},
+				"title":        text,
+				"desc":         text,
+				"content":      text,
+				"img":          map[string]interface{}{"type": "keyword", "index": false},
+				"cid":          map[string]string{"type": "long"},
+				"user_id":      map[string]string{"type": "long"},
+				"slug":         map[string]string{"type": "keyword"},
+				"tags":         map[string]string{"type": "keyword"},
+				"published_at": map[string]string{"type": "long"},
+			},
+		},
+	}
+	return request(ctx, http.MethodPut, "/"+index(), mapping, nil)
+}
+
+// DeleteIndex 删除索引，重建索引前使用
+func DeleteIndex(ctx context.Context) error {
+	err := request(ctx, http.MethodDelete, "/"+index(), nil, nil)
+	var se *httpclient.StatusError
+	if errors.As(err, &se) && se.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// IndexArticle 添加或覆盖文章
+func IndexArticle(ctx context.Context, doc *ArticleDoc) error {
+	return request(ctx, http.MethodPut, "/"+index()+"/_doc/"+strconv.FormatInt(doc.Id, 10), doc, nil)
+}
+
+// DeleteArticle 从索引中删除文章，文章不存在时忽略
+func DeleteArticle(ctx context.Context, id int64) error {
+	err := request(ctx, http.MethodDelete, "/"+index()+"/_doc/"+strconv.FormatInt(id, 10), nil, nil)
+	var se *httpclient.StatusError
+	if errors.As(err, &se) && se.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// BulkIndex 批量添加或覆盖文章
+func BulkIndex(ctx context.Context, docs []ArticleDoc) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := range docs {
+		meta := map[string]interface{}{"index": map[string]interface{}{"_index": index(), "_id": docs[i].Id}}
+		if err := enc.Encode(meta); err != nil {
+			return err
+		}
+		if err := enc.Encode(&docs[i]); err != nil {
+			return err
+		}
+	}
+
+	var ret struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Id    string          `json:"_id"`
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := request(ctx, http.MethodPost, "/_bulk", buf.Bytes(), &ret); err != nil {
+		return err
+	}
+	if ret.Errors {
+		for _, item := range ret.Items {
+			for _, v := range item {
+				if len(v.Error) > 0 {
+					return fmt.Errorf("search: bulk index %s failed: %s", v.Id, v.Error)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// SearchArticles 按关键词搜索标题、摘要和内容，支持按标签、分类过滤
+func SearchArticles(ctx context.Context, q *Query) (*Result, error) {
+	if q.Page <= 0 {
+		q.Page = 1
+	}
+	if q.Limit <= 0 || q.Limit > 100 {
+		q.Limit = 20
+	}
+
+	filter := []interface{}{}
+	for _, tag := range q.Tags {
+		// 多个标签需要同时满足
+		filter = append(filter, map[string]interface{}{"term": map[string]string{"tags": tag}})
+	}
+	if q.Cid > 0 {
+		filter = append(filter, map[string]interface{}{"term": map[string]uint64{"cid": q.Cid}})
+	}
+	boolQuery := map[string]interface{}{"filter": filter}
+	body := map[string]interface{}{
+		"from":    (q.Page - 1) * q.Limit,
+		"size":    q.Limit,
+		"query":   map[string]interface{}{"bool": boolQuery},
+		"_source": map[string]interface{}{"excludes": []string{"content"}},
+	}
+	if q.Keyword != "" {
+		boolQuery["must"] = map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":  q.Keyword,
+				"fields": []string{"title^3", "desc^2", "content"},
+			},
+		}
+		// html编码高亮片段中的原文，前端可以直接渲染
+		body["highlight"] = map[string]interface{}{
+			"encoder":   "html",
+			"pre_tags":  []string{"<em>"},
+			"post_tags": []string{"</em>"},
+			"fields": map[string]interface{}{
+				"title":   map[string]interface{}{"number_of_fragments": 0},
+				"desc":    map[string]interface{}{"number_of_fragments": 0},
+				"content": map[string]interface{}{"fragment_size": 150, "number_of_fragments": 1},
+			},
+		}
+	} else {
+		body["sort"] = []interface{}{map[string]string{"published_at": "desc"}, map[string]string{"id": "desc"}}
+	}
+
+	var ret struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Source    ArticleDoc          `json:"_source"`
+				Highlight map[string][]string `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := request(ctx, http.MethodPost, "/"+index()+"/_search", body, &ret); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Total: ret.Hits.Total.Value, Hits: make([]Hit, 0, len(ret.Hits.Hits))}
+	for _, h := range ret.Hits.Hits {
+		result.Hits = append(result.Hits, Hit{ArticleDoc: h.Source, Highlight: h.Highlight})
+	}
+	return result, nil
+}
@@ -0,0 +1,249 @@
+package seed
+
+import (
+	"fmt"
+	"go-skeleton/model"
+	"go-skeleton/utils"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 测试数据加载，fixture文件为yaml或json，按类型分组，每条记录有一个逻辑名称：
+//
+//	users:
+//	  admin: {username: admin, password: "123456"}
+//	articles:
+//	  hello: {title: Hello, cid: "@categories.go", user_id: "@users.admin"}
+//
+// 字段名可以用列名或结构体字段名，"@类型.名称"引用已加载记录的id，"@@"开头表示普通的@字符串
+// 类型按注册顺序加载，被引用的类型需要先注册
+
+type kind struct {
+	name     string
+	newModel func() interface{}
+	// 赋值后、写入前对记录的处理
+	prepare func(m interface{}) error
+}
+
+var kinds []kind
+
+func init() {
+	Register("users", func() interface{} { return &model.User{} }, hashPassword)
+	Register("categories", func() interface{} { return &model.Category{} }, nil)
+	Register("tags", func() interface{} { return &model.Tag{} }, nil)
+	Register("articles", func() interface{} { return &model.Article{} }, nil)
+	Register("article_tags", func() interface{} { return &model.ArticleTag{} }, nil)
+	Register("comments", func() interface{} { return &model.Comment{} }, nil)
+}
+
+// Register 注册fixture类型，newModel返回模型的指针
+func Register(name string, newModel func() interface{}, prepare func(m interface{}) error) {
+	kinds = append(kinds, kind{name: name, newModel: newModel, prepare: prepare})
+}
+
+// 明文密码写入前做哈希
+func hashPassword(m interface{}) error {
+	user := m.(*model.User)
+	if user.Password == "" {
+		return nil
+	}
+	hash, err := utils.HashPassword(user.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hash
+	return nil
+}
+
+type Loader struct {
+	db       *gorm.DB
+	fixtures map[string]yaml.MapSlice
+	ids      map[string]int64
+}
+
+func NewLoader(db *gorm.DB) *Loader {
+	return &Loader{
+		db:       db,
+		fixtures: make(map[string]yaml.MapSlice),
+		ids:      make(map[string]int64),
+	}
+}
+
+// Load 加载文件或目录下的所有fixture并写入数据库
+func Load(db *gorm.DB, paths ...string) (*Loader, error) {
+	l := NewLoader(db)
+	for _, p := range paths {
+		if err := l.Add(p); err != nil {
+			return nil, err
+		}
+	}
+	return l, l.Load()
+}
+
+// Add 添加fixture文件，目录时添加其下的.yml、.yaml、.json文件，按文件名排序
+func (l *Loader) Add(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return l.addFile(path)
+	}
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yml", ".yaml", ".json":
+			if !entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := l.addFile(filepath.Join(path, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addFile json是yaml的子集，统一按yaml解析，MapSlice保留记录的顺序
+func (l *Loader) addFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, item := range doc {
+		name := fmt.Sprint(item.Key)
+		if !l.hasKind(name) {
+			return fmt.Errorf("%s: unknown fixture kind %q", path, name)
+		}
+		records, ok := item.Value.(yaml.MapSlice)
+		if !ok && item.Value != nil {
+			return fmt.Errorf("%s: %s must be a map of records", path, name)
+		}
+		l.fixtures[name] = append(l.fixtures[name], records...)
+	}
+	return nil
+}
+
+func (l *Loader) hasKind(name string) bool {
+	for _, k := range kinds {
+		if k.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Load 在一个事务中写入所有已添加的fixture
+func (l *Loader) Load() error {
+	return l.db.Transaction(func(tx *gorm.DB) error {
+		for _, k := range kinds {
+			for _, record := range l.fixtures[k.name] {
+				if err := l.insert(tx, k, fmt.Sprint(record.Key), record.Value); err != nil {
+					return fmt.Errorf("%s.%v: %w", k.name, record.Key, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Truncate 清空已添加的fixture涉及的表，按加载的逆序删除
+func (l *Loader) Truncate() error {
+	return l.db.Transaction(func(tx *gorm.DB) error {
+		for i := len(kinds) - 1; i >= 0; i-- {
+			if _, ok := l.fixtures[kinds[i].name]; !ok {
+				continue
+			}
+			if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().
+				Delete(kinds[i].newModel()).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ID 已加载记录的id，ref格式为"类型.名称"
+func (l *Loader) ID(ref string) int64 {
+	return l.ids[ref]
+}
+
+func (l *Loader) insert(tx *gorm.DB, k kind, name string, value interface{}) error {
+	fields, ok := value.(yaml.MapSlice)
+	if !ok && value != nil {
+		return fmt.Errorf("record must be a map")
+	}
+	m := k.newModel()
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(m); err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(m).Elem()
+	for _, item := range fields {
+		key := fmt.Sprint(item.Key)
+		field := stmt.Schema.LookUpField(key)
+		if field == nil {
+			return fmt.Errorf("unknown field %q", key)
+		}
+		v, err := l.resolve(item.Value)
+		if err != nil {
+			return err
+		}
+		if err := field.Set(rv, v); err != nil {
+			return fmt.Errorf("field %q: %w", key, err)
+		}
+	}
+	if k.prepare != nil {
+		if err := k.prepare(m); err != nil {
+			return err
+		}
+	}
+	if err := tx.Omit(clause.Associations).Create(m).Error; err != nil {
+		return err
+	}
+	if pk := stmt.Schema.PrioritizedPrimaryField; pk != nil {
+		id, _ := pk.ValueOf(rv)
+		switch n := reflect.ValueOf(id); n.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			l.ids[k.name+"."+name] = n.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			l.ids[k.name+"."+name] = int64(n.Uint())
+		}
+	}
+	return nil
+}
+
+// resolve 把"@类型.名称"替换为对应记录的id
+func (l *Loader) resolve(v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, "@") {
+		return v, nil
+	}
+	if strings.HasPrefix(s, "@@") {
+		return s[1:], nil
+	}
+	id, ok := l.ids[s[1:]]
+	if !ok {
+		return nil, fmt.Errorf("unresolved reference %s", s)
+	}
+	return id, nil
+}
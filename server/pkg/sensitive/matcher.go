@@ -0,0 +1,165 @@
+package sensitive
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Match 文本中匹配到的词，Start、End为rune的下标，包含End
+type Match struct {
+	Word  string
+	Start int
+	End   int
+}
+
+type node struct {
+	children map[rune]*node
+	fail     *node
+	// 以该节点结尾的词，非词尾时为空
+	word string
+	// 词的长度(rune)
+	depth int
+	// fail链上最近的词尾节点，用于输出所有后缀匹配
+	out *node
+}
+
+// Matcher Aho-Corasick多模式匹配，一次扫描找出文本中所有的词，创建后只读，可以并发使用
+type Matcher struct {
+	root *node
+	skip map[rune]bool
+	size int
+}
+
+// NewMatcher 创建匹配器，忽略大小写，skipChars中的字符在匹配时被跳过，eg: 敏*感*词 也能匹配 敏感词
+func NewMatcher(words []string, skipChars string) *Matcher {
+	m := &Matcher{root: &node{children: map[rune]*node{}}, skip: map[rune]bool{}}
+	for _, r := range skipChars {
+		m.skip[r] = true
+	}
+	for _, w := range words {
+		m.add(w)
+	}
+	m.build()
+	return m
+}
+
+// Len 词的数量
+func (m *Matcher) Len() int {
+	return m.size
+}
+
+func (m *Matcher) add(word string) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return
+	}
+	n, depth := m.root, 0
+	for _, r := range word {
+		if m.skip[r] {
+			continue
+		}
+		r = unicode.ToLower(r)
+		child, ok := n.children[r]
+		if !ok {
+			child = &node{children: map[rune]*node{}}
+			n.children[r] = child
+		}
+		depth++
+		child.depth = depth
+		n = child
+	}
+	if n != m.root && n.word == "" {
+		n.word = word
+		m.size++
+	}
+}
+
+// build 按层次遍历设置fail指针
+func (m *Matcher) build() {
+	queue := make([]*node, 0, len(m.root.children))
+	for _, child := range m.root.children {
+		child.fail = m.root
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n.fail.word != "" {
+			n.out = n.fail
+		} else {
+			n.out = n.fail.out
+		}
+		for r, child := range n.children {
+			f := n.fail
+			for f != nil && f.children[r] == nil {
+				f = f.fail
+			}
+			if f == nil {
+				child.fail = m.root
+			} else {
+				child.fail = f.children[r]
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+// FindAll 找出文本中所有的词，按结束位置排序，重叠的词都会返回
+func (m *Matcher) FindAll(text string) []Match {
+	if m.size == 0 {
+		return nil
+	}
+	var (
+		matches []Match
+		// 未跳过的字符在原文中的下标
+		positions []int
+		n         = m.root
+	)
+	for i, r := range []rune(text) {
+		if m.skip[r] {
+			continue
+		}
+		positions = append(positions, i)
+		r = unicode.ToLower(r)
+		for n != m.root && n.children[r] == nil {
+			n = n.fail
+		}
+		if next, ok := n.children[r]; ok {
+			n = next
+		}
+		for out := n; out != nil; out = out.out {
+			if out.word == "" {
+				continue
+			}
+			start := positions[len(positions)-out.depth]
+			matches = append(matches, Match{Word: out.word, Start: start, End: i})
+		}
+	}
+	return matches
+}
+
+// Contains 文本中是否包含任意一个词
+func (m *Matcher) Contains(text string) bool {
+	return len(m.FindAll(text)) > 0
+}
+
+// Replace 将匹配到的词替换为mask，返回替换后的文本和去重后的词
+func (m *Matcher) Replace(text string, mask rune) (string, []string) {
+	matches := m.FindAll(text)
+	if len(matches) == 0 {
+		return text, nil
+	}
+	runes := []rune(text)
+	seen := make(map[string]bool, len(matches))
+	hits := make([]string, 0, len(matches))
+	for _, match := range matches {
+		for i := match.Start; i <= match.End; i++ {
+			runes[i] = mask
+		}
+		if !seen[match.Word] {
+			seen[match.Word] = true
+			hits = append(hits, match.Word)
+		}
+	}
+	return string(runes), hits
+}
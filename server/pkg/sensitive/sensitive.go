@@ -0,0 +1,211 @@
+package sensitive
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go-skeleton/model"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/simpleDb"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 处理方式
+const (
+	// ModeReplace 将敏感词替换为*
+	ModeReplace = "replace"
+	// ModeReject 包含敏感词时拒绝保存
+	ModeReject = "reject"
+)
+
+// Mask 替换敏感词使用的字符
+const Mask = '*'
+
+// Dict 敏感词库，从文件和sensitive_word表加载，Reload后替换为新的词库
+type Dict struct {
+	cfg     config.SensitiveConfig
+	matcher atomic.Value
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+var defaultDict *Dict
+
+// New 按配置创建词库并加载
+func New(cfg config.SensitiveConfig) (*Dict, error) {
+	if cfg.Mode == "" {
+		cfg.Mode = ModeReplace
+	}
+	if cfg.Mode != ModeReplace && cfg.Mode != ModeReject {
+		return nil, fmt.Errorf("sensitive: unknown mode %q", cfg.Mode)
+	}
+	d := &Dict{cfg: cfg, quit: make(chan struct{})}
+	d.matcher.Store(NewMatcher(nil, cfg.SkipChars))
+	if err := d.Reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Init 按配置文件sensitive创建默认的词库，配置了ReloadInterval时定期重新加载词库
+func Init() error {
+	d, err := New(config.Conf.SensitiveConfig)
+	if err != nil {
+		return err
+	}
+	defaultDict = d
+	if d.cfg.ReloadInterval > 0 {
+		d.Start()
+	}
+	return nil
+}
+
+// Default 默认的词库，未启用时为nil
+func Default() *Dict {
+	return defaultDict
+}
+
+// Stop 停止默认词库的定期加载
+func Stop(ctx context.Context) error {
+	if defaultDict == nil {
+		return nil
+	}
+	return defaultDict.Stop(ctx)
+}
+
+// Reload 重新加载默认的词库，eg: 后台修改了sensitive_word表之后
+func Reload() error {
+	if defaultDict == nil {
+		return nil
+	}
+	return defaultDict.Reload()
+}
+
+// Mode 处理方式，replace或reject
+func (d *Dict) Mode() string {
+	return d.cfg.Mode
+}
+
+// Reload 从文件和数据库加载词库，加载失败时继续使用原来的词库
+func (d *Dict) Reload() error {
+	var words []string
+	for _, path := range d.cfg.Files {
+		list, err := readWords(path)
+		if err != nil {
+			return err
+		}
+		words = append(words, list...)
+	}
+	if d.cfg.Db {
+		var list []string
+		if err := simpleDb.DB().Model(&model.SensitiveWord{}).Pluck("word", &list).Error; err != nil {
+			return fmt.Errorf("sensitive: load words from db: %w", err)
+		}
+		words = append(words, list...)
+	}
+
+	m := NewMatcher(words, d.cfg.SkipChars)
+	d.matcher.Store(m)
+	zap.L().Debug("sensitive words loaded", zap.Int("count", m.Len()))
+	return nil
+}
+
+// Matcher 当前使用的词库
+func (d *Dict) Matcher() *Matcher {
+	return d.matcher.Load().(*Matcher)
+}
+
+// Filter 将敏感词替换为*，返回替换后的文本和命中的词，没有命中时hits为空
+func (d *Dict) Filter(text string) (clean string, hits []string) {
+	return d.Matcher().Replace(text, Mask)
+}
+
+// Contains 是否包含敏感词
+func (d *Dict) Contains(text string) bool {
+	return d.Matcher().Contains(text)
+}
+
+// Start 定期重新加载词库
+func (d *Dict) Start() {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(d.cfg.ReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.quit:
+				return
+			case <-ticker.C:
+				if err := d.Reload(); err != nil {
+					zap.L().Error("sensitive words reload failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止定期加载
+func (d *Dict) Stop(ctx context.Context) error {
+	d.once.Do(func() {
+		close(d.quit)
+	})
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Filter 使用默认的词库替换敏感词，未启用时原样返回
+func Filter(text string) (clean string, hits []string) {
+	if defaultDict == nil {
+		return text, nil
+	}
+	return defaultDict.Filter(text)
+}
+
+// Contains 使用默认的词库检查是否包含敏感词，未启用时返回false
+func Contains(text string) bool {
+	if defaultDict == nil {
+		return false
+	}
+	return defaultDict.Contains(text)
+}
+
+// readWords 读取词库文件，每行一个词，#开头的行为注释
+func readWords(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("sensitive: %w", err)
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("sensitive: read %s: %w", path, err)
+	}
+	return words, nil
+}
@@ -0,0 +1,181 @@
+package simpleDb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-skeleton/utils"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 游标分页默认每页条数
+const defaultCursorLimit = 20
+
+var schemaCache = &sync.Map{}
+
+// ErrInvalidCursor 游标无法解析或与排序字段不匹配，通常是客户端传入了伪造或过期的游标
+var ErrInvalidCursor = errors.New("simpleDb: invalid cursor")
+
+// EncodeCursor 将排序字段的值编码为不透明的游标
+func EncodeCursor(values []interface{}) (string, error) {
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return utils.Base64RawURLEncode(b), nil
+}
+
+// DecodeCursor 解析游标中排序字段的值
+func DecodeCursor(cursor string) ([]interface{}, error) {
+	b, err := utils.Base64RawURLDecode(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var values []interface{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	// 避免大整数的主键丢失精度
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		if n, ok := v.(json.Number); ok {
+			values[i] = n.String()
+		}
+	}
+	return values, nil
+}
+
+// After 从指定游标之后开始查询，配合FindCursor使用
+func (s *SqlCnd) After(cursor string) *SqlCnd {
+	if s.Paging == nil {
+		s.Paging = &Paging{Limit: defaultCursorLimit}
+	}
+	s.Paging.Cursor = cursor
+	return s
+}
+
+// FindCursor 按Orders做游标(keyset)分页查询，out必须是结构体切片的指针。
+// 会自动追加主键作为排序的最后一列，保证排序唯一；返回下一页的游标，没有更多数据时hasMore为false，
+// 游标无效时返回ErrInvalidCursor，不会忽略游标从第一页开始查询
+func (s *SqlCnd) FindCursor(db *gorm.DB, out interface{}) (nextCursor string, hasMore bool, err error) {
+	if s.Paging == nil {
+		s.Paging = &Paging{}
+	}
+	if s.Paging.Limit <= 0 {
+		s.Paging.Limit = defaultCursorLimit
+	}
+
+	sch, err := schema.Parse(out, schemaCache, db.NamingStrategy)
+	if err != nil {
+		return
+	}
+	orders := s.cursorOrders(sch)
+
+	ret := s.buildWhere(db)
+	if len(s.SelectCols) > 0 {
+		ret = ret.Select(s.SelectCols)
+	}
+	for _, preload := range s.Preloads {
+		ret = ret.Preload(preload.Query, preload.Args...)
+	}
+	if s.Paging.Cursor != "" {
+		values, err := DecodeCursor(s.Paging.Cursor)
+		if err != nil || len(values) != len(orders) {
+			return "", false, ErrInvalidCursor
+		}
+		query, args := keysetCondition(orders, values)
+		ret = ret.Where(query, args...)
+	}
+	for _, order := range orders {
+		if order.Asc {
+			ret = ret.Order(order.Column + " ASC")
+		} else {
+			ret = ret.Order(order.Column + " DESC")
+		}
+	}
+
+	// 多查一条用来判断是否还有下一页
+	if err = ret.Limit(s.Paging.Limit + 1).Find(out).Error; err != nil {
+		return
+	}
+
+	list := reflect.ValueOf(out).Elem()
+	if list.Len() <= s.Paging.Limit {
+		return
+	}
+	list.Set(list.Slice(0, s.Paging.Limit))
+	hasMore = true
+
+	last := reflect.Indirect(list.Index(list.Len() - 1))
+	values := make([]interface{}, 0, len(orders))
+	for _, order := range orders {
+		field := sch.LookUpField(columnName(order.Column))
+		if field == nil {
+			return "", false, fmt.Errorf("simpleDb: cursor column not found: %s", order.Column)
+		}
+		value, _ := field.ValueOf(last)
+		values = append(values, value)
+	}
+	if nextCursor, err = EncodeCursor(values); err != nil {
+		return "", false, err
+	}
+	return
+}
+
+// cursorOrders 返回游标分页使用的排序，最后一列为主键
+func (s *SqlCnd) cursorOrders(sch *schema.Schema) []OrderByCol {
+	orders := make([]OrderByCol, len(s.Orders))
+	copy(orders, s.Orders)
+
+	pk := sch.PrioritizedPrimaryField
+	if pk == nil {
+		return orders
+	}
+	for _, order := range orders {
+		if columnName(order.Column) == pk.DBName {
+			return orders
+		}
+	}
+
+	// 主键的排序方向与最后一个排序字段保持一致
+	asc := len(orders) == 0 || orders[len(orders)-1].Asc
+	return append(orders, OrderByCol{Column: pk.DBName, Asc: asc})
+}
+
+// keysetCondition 生成游标条件，eg: a < ? OR (a = ? AND b > ?)
+func keysetCondition(orders []OrderByCol, values []interface{}) (string, []interface{}) {
+	var (
+		ors  []string
+		args []interface{}
+	)
+	for i, order := range orders {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, orders[j].Column+" = ?")
+			args = append(args, values[j])
+		}
+		op := " < ?"
+		if order.Asc {
+			op = " > ?"
+		}
+		ands = append(ands, order.Column+op)
+		args = append(args, values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return strings.Join(ors, " OR "), args
+}
+
+// columnName 去掉字段的表名前缀
+func columnName(column string) string {
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		return strings.Trim(column[i+1:], "`")
+	}
+	return strings.Trim(column, "`")
+}
@@ -1,11 +1,14 @@
 package simpleDb
 
 import (
-	"database/sql"
 	"fmt"
 	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/metrics"
+	"go-skeleton/pkg/tracing"
+	"go-skeleton/utils"
 	"log"
 	"os"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -17,9 +20,13 @@ import (
 	"gorm.io/gorm"
 )
 
+// 默认连接名
+const defaultConn = "default"
+
 var (
-	db    *gorm.DB
-	sqlDB *sql.DB
+	db  *gorm.DB
+	dbs = make(map[string]*gorm.DB)
+	mu  sync.RWMutex
 )
 
 //appMode常量
@@ -40,19 +47,25 @@ type dbConfig struct {
 	MaxIdleConns    int
 	ConnMaxIdleTime time.Duration
 	ConnMaxLifetime time.Duration
+	QueryTimeout    time.Duration
+	IdGenerator     string
+	WorkerId        int64
 	AppMode         AppMode //根据此变量选择日志模式
+	Replicas        []config.DbConfig
+	Resolvers       []config.ResolverConfig
 }
 
-var err error
-
-func dbDial(cfg *dbConfig) error {
-	dns := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+func (cfg *dbConfig) dsn() string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		cfg.DbUser,
 		cfg.DbPassWord,
 		cfg.DbHost,
 		cfg.DbPort,
 		cfg.DbName,
 	)
+}
+
+func dbDial(cfg *dbConfig) (*gorm.DB, error) {
 
 	//根据环境选择debug
 	var newLogger logger.Interface
@@ -69,7 +82,7 @@ func dbDial(cfg *dbConfig) error {
 		newLogger = logger.Default.LogMode(logger.Silent)
 	}
 
-	db, err = gorm.Open(mysql.Open(dns), &gorm.Config{
+	conn, err := gorm.Open(mysql.Open(cfg.dsn()), &gorm.Config{
 		// gorm日志模式：silent
 		Logger: newLogger, //logger.Default.LogMode(logger.Silent)
 		// 外键约束
@@ -84,13 +97,13 @@ func dbDial(cfg *dbConfig) error {
 	})
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// 迁移数据表，在没有数据表结构变更时候，建议注释不执行
 	//_ = db.AutoMigrate(&User{}, &Article{}, &Category{}, Profile{}, Comment{})
 
 	//下面两种设置连接池的方法效果一样
-	if sqlDB, err = db.DB(); err == nil {
+	if sqlDB, err := conn.DB(); err == nil {
 		// SetMaxIdleCons 设置连接池中的最大闲置连接数。
 		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
 
@@ -106,33 +119,137 @@ func dbDial(cfg *dbConfig) error {
 	} else {
 		zap.L().Warn("mysql conns error:", zap.String("error", err.Error()))
 	}
-	//这里是多数据源的连接池
-	//db.Use(
-	//	dbresolver.Register(dbresolver.Config{}).
-	//		SetConnMaxIdleTime(time.Hour).
-	//		SetConnMaxLifetime(24 * time.Hour).
-	//		SetMaxIdleConns(10).
-	//		SetMaxOpenConns(105),
-	//)
+	//链路追踪
+	if err := conn.Use(tracing.GormPlugin{}); err != nil {
+		return nil, err
+	}
 
-	return nil
+	//sql耗时统计
+	if err := conn.Use(metrics.GormPlugin{}); err != nil {
+		return nil, err
+	}
+
+	//慢查询日志
+	if err := conn.Use(slowQueryPlugin{}); err != nil {
+		return nil, err
+	}
+
+	//sql超时，0为不限制
+	if cfg.QueryTimeout > 0 {
+		if err := conn.Use(timeoutPlugin{timeout: cfg.QueryTimeout}); err != nil {
+			return nil, err
+		}
+	}
+
+	//主键生成方式，默认数据库自增
+	switch cfg.IdGenerator {
+	case "", IdGeneratorAuto:
+	case IdGeneratorSnowflake:
+		gen, err := utils.NewIDGenerator(cfg.WorkerId)
+		if err != nil {
+			return nil, err
+		}
+		if err := conn.Use(idPlugin{gen: gen}); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown id generator %q", cfg.IdGenerator)
+	}
+
+	//读写分离，多数据源共用同样的连接池配置
+	if resolver := newResolver(cfg); resolver != nil {
+		resolver.
+			SetConnMaxIdleTime(cfg.ConnMaxIdleTime).
+			SetConnMaxLifetime(cfg.ConnMaxLifetime).
+			SetMaxIdleConns(cfg.MaxIdleConns).
+			SetMaxOpenConns(cfg.MaxOpenConns)
+		if err := conn.Use(resolver); err != nil {
+			return nil, err
+		}
+	}
+
+	return conn, nil
 }
 
+// InitDb 初始化默认连接及database.Connections中配置的命名连接
 func InitDb() error {
+	watchSlowQuery()
+	if err := initShards(config.Conf.DbConfig.Shards); err != nil {
+		return err
+	}
+	if err := Open(defaultConn, config.Conf.DbConfig); err != nil {
+		return err
+	}
+	for name, c := range config.Conf.DbConfig.Connections {
+		if err := Open(name, c); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Open 按配置创建连接并以name注册，同名连接会被替换
+func Open(name string, c config.DbConfig) error {
 	cfg := &dbConfig{
-		DbUser:          config.Conf.DbConfig.DbUser,
-		DbPassWord:      config.Conf.DbConfig.DbPassWord,
-		DbHost:          config.Conf.DbConfig.DbHost,
-		DbPort:          config.Conf.DbConfig.DbPort,
-		DbName:          config.Conf.DbConfig.DbName,
-		MaxOpenConns:    10,
-		MaxIdleConns:    100,
-		ConnMaxIdleTime: 24 * time.Hour,
-		ConnMaxLifetime: 24 * time.Hour,
+		DbUser:          c.DbUser,
+		DbPassWord:      c.DbPassWord,
+		DbHost:          c.DbHost,
+		DbPort:          c.DbPort,
+		DbName:          c.DbName,
+		MaxOpenConns:    c.MaxOpenConns,
+		MaxIdleConns:    c.MaxIdleConns,
+		ConnMaxIdleTime: c.ConnMaxIdleTime,
+		ConnMaxLifetime: c.ConnMaxLifetime,
+		QueryTimeout:    c.QueryTimeout,
+		IdGenerator:     c.IdGenerator,
+		WorkerId:        c.WorkerId,
 		AppMode:         AppMode(config.Conf.AppMode),
+		Replicas:        c.Replicas,
+		Resolvers:       c.Resolvers,
+	}
+	if cfg.MaxOpenConns <= 0 {
+		cfg.MaxOpenConns = 100
+	}
+	if cfg.MaxIdleConns <= 0 {
+		cfg.MaxIdleConns = 10
+	}
+	if cfg.ConnMaxIdleTime <= 0 {
+		cfg.ConnMaxIdleTime = 24 * time.Hour
+	}
+	if cfg.ConnMaxLifetime <= 0 {
+		cfg.ConnMaxLifetime = 24 * time.Hour
+	}
+
+	conn, err := dbDial(cfg)
+	if err != nil {
+		return err
 	}
+	Register(name, conn)
+	return nil
+}
 
-	return dbDial(cfg)
+// Register 注册已创建的连接
+func Register(name string, conn *gorm.DB) {
+	mu.Lock()
+	defer mu.Unlock()
+	if old, ok := dbs[name]; ok && old != conn {
+		closeConn(name, old)
+	}
+	dbs[name] = conn
+	if name == defaultConn {
+		db = conn
+	}
+}
+
+// Use 获取指定名称的连接，不存在时返回nil
+func Use(name string) *gorm.DB {
+	mu.RLock()
+	defer mu.RUnlock()
+	conn, ok := dbs[name]
+	if !ok {
+		zap.L().Error("database connection not found", zap.String("name", name))
+	}
+	return conn
 }
 
 // 获取数据库链接
@@ -140,12 +257,23 @@ func DB() *gorm.DB {
 	return db
 }
 
-// 关闭连接
+// 关闭所有连接
 func CloseDB() {
-	if sqlDB == nil {
+	mu.Lock()
+	defer mu.Unlock()
+	for name, conn := range dbs {
+		closeConn(name, conn)
+		delete(dbs, name)
+	}
+	db = nil
+}
+
+func closeConn(name string, conn *gorm.DB) {
+	sqlDB, err := conn.DB()
+	if err != nil {
 		return
 	}
 	if err := sqlDB.Close(); nil != err {
-		log.Printf("Disconnect from database failed: %s", err.Error())
+		log.Printf("Disconnect from database %s failed: %s", name, err.Error())
 	}
 }
@@ -1,12 +1,20 @@
 package simpleDb
 
-import "database/sql"
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
 
 // 分页请求数据
 type Paging struct {
-	Page  int   `json:"page"`  // 页码
-	Limit int   `json:"limit"` // 每页条数
-	Total int64 `json:"total"` // 总数据条数
+	Page    int    `json:"page"`              // 页码
+	Limit   int    `json:"limit"`             // 每页条数
+	Total   int64  `json:"total"`             // 总数据条数
+	Cursor  string `json:"cursor,omitempty"`  // 游标分页：请求时为当前游标，返回时为下一页游标
+	HasMore bool   `json:"hasMore,omitempty"` // 游标分页：是否还有下一页
 }
 
 func (p *Paging) Offset() int {
@@ -28,9 +36,20 @@ func (p *Paging) TotalPage() int {
 	return totalPage
 }
 
+// 条件的连接方式
+type ParamType int
+
+const (
+	ParamAnd ParamType = iota // AND
+	ParamOr                   // OR
+	ParamNot                  // NOT
+)
+
 type ParamPair struct {
 	Query string        // 查询
 	Args  []interface{} // 参数
+	Type  ParamType     // 连接方式，默认AND
+	Group *SqlCnd       // 嵌套的条件组，不为空时忽略Query和Args
 }
 
 // 排序信息
@@ -57,3 +76,12 @@ func SqlNullString(value string) sql.NullString {
 		Valid:  len(value) > 0,
 	}
 }
+
+// IsDuplicateKey 是否违反唯一索引，index不为空时只匹配该索引，eg: IsDuplicateKey(err, "uk_article_slug")
+func IsDuplicateKey(err error, index string) bool {
+	var me *mysql.MySQLError
+	if !errors.As(err, &me) || me.Number != 1062 {
+		return false
+	}
+	return index == "" || strings.Contains(me.Message, "'"+index+"'") || strings.Contains(me.Message, "."+index+"'")
+}
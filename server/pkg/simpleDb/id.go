@@ -0,0 +1,77 @@
+package simpleDb
+
+import (
+	"errors"
+	"go-skeleton/utils"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 主键生成方式
+const (
+	// IdGeneratorAuto 数据库自增
+	IdGeneratorAuto = "auto"
+	// IdGeneratorSnowflake 插入前使用snowflake生成主键，分库分表、多活时id不冲突
+	IdGeneratorSnowflake = "snowflake"
+)
+
+// idPlugin 插入前为主键为空的模型生成snowflake id，只处理int64、uint64类型的主键，
+// 已设置主键的数据不修改
+type idPlugin struct {
+	gen *utils.IDGenerator
+}
+
+func (p idPlugin) Name() string {
+	return "simpleDb:id"
+}
+
+func (p idPlugin) Initialize(db *gorm.DB) error {
+	return db.Callback().Create().Before("gorm:create").Register("simpleDb:id", p.assign)
+}
+
+func (p idPlugin) assign(db *gorm.DB) {
+	sch := db.Statement.Schema
+	if db.Error != nil || sch == nil {
+		return
+	}
+	field := sch.PrioritizedPrimaryField
+	if field == nil || field.Size != 64 || (field.DataType != schema.Int && field.DataType != schema.Uint) {
+		return
+	}
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			p.set(db, field, reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		p.set(db, field, rv)
+	}
+}
+
+func (p idPlugin) set(db *gorm.DB, field *schema.Field, rv reflect.Value) {
+	if _, zero := field.ValueOf(rv); !zero {
+		return
+	}
+	id, err := p.gen.Next()
+	if err == nil {
+		err = field.Set(rv, id)
+	}
+	if err != nil {
+		_ = db.AddError(err)
+	}
+}
+
+// ErrIdGeneratorDisabled 连接未启用snowflake主键
+var ErrIdGeneratorDisabled = errors.New("simpleDb: snowflake id generator disabled")
+
+// NextID 使用连接的id生成器生成id，eg: 插入前需要知道主键时 simpleDb.NextID(simpleDb.DB())
+func NextID(db *gorm.DB) (int64, error) {
+	p, ok := db.Config.Plugins[idPlugin{}.Name()].(idPlugin)
+	if !ok {
+		return 0, ErrIdGeneratorDisabled
+	}
+	return p.gen.Next()
+}
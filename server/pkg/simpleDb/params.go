@@ -162,5 +162,5 @@ func GetPaging(ctx *gin.Context) *Paging {
 	if limit <= 0 {
 		limit = 20
 	}
-	return &Paging{Page: page, Limit: limit}
+	return &Paging{Page: page, Limit: limit, Cursor: FormValue(ctx, "cursor")}
 }
@@ -0,0 +1,217 @@
+package simpleDb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 过滤操作符，请求参数为 字段_操作符=值，不带操作符时为eq，eg:
+//	?title_like=go&status_in=1,2&created_at_gte=1634256000&sort=-created_at,id
+type FilterOp string
+
+const (
+	OpEq      FilterOp = "eq"
+	OpNe      FilterOp = "ne"
+	OpGt      FilterOp = "gt"
+	OpGte     FilterOp = "gte"
+	OpLt      FilterOp = "lt"
+	OpLte     FilterOp = "lte"
+	OpLike    FilterOp = "like"
+	OpStarts  FilterOp = "starts"
+	OpIn      FilterOp = "in"
+	OpNotIn   FilterOp = "nin"
+	OpBetween FilterOp = "between" // 值为 开始,结束
+	OpNull    FilterOp = "null"    // 值为1时IS NULL，0时IS NOT NULL
+)
+
+var filterOps = map[FilterOp]bool{
+	OpEq: true, OpNe: true, OpGt: true, OpGte: true, OpLt: true, OpLte: true, OpLike: true,
+	OpStarts: true, OpIn: true, OpNotIn: true, OpBetween: true, OpNull: true,
+}
+
+// 不作为过滤条件的参数
+var reservedParams = map[string]bool{"sort": true, "page": true, "limit": true, "cursor": true}
+
+// FilterSpec 允许从请求参数中过滤和排序的字段，不在白名单中的字段会被忽略，eg:
+//	simpleDb.FilterSpec{
+//		Fields:      map[string][]simpleDb.FilterOp{"title": {simpleDb.OpLike}, "status": {simpleDb.OpEq, simpleDb.OpIn}},
+//		Sorts:       []string{"id", "created_at"},
+//		DefaultSort: "-id",
+//	}
+type FilterSpec struct {
+	// 允许过滤的字段及其操作符
+	Fields map[string][]FilterOp
+	// 字段对应的列名，未配置时和字段名相同，关联查询时可以带上表名
+	Columns map[string]string
+	// 允许排序的字段
+	Sorts []string
+	// 没有sort参数时的排序，-开头为倒序
+	DefaultSort string
+	// 每页的最大条数，默认100
+	MaxLimit int
+}
+
+func (f *FilterSpec) column(field string) string {
+	if col, ok := f.Columns[field]; ok {
+		return col
+	}
+	return field
+}
+
+func (f *FilterSpec) allow(field string, op FilterOp) (known, allowed bool) {
+	ops, ok := f.Fields[field]
+	if !ok {
+		return false, false
+	}
+	for _, o := range ops {
+		if o == op {
+			return true, true
+		}
+	}
+	return true, false
+}
+
+// NewSqlCndFromQuery 根据请求参数生成查询条件、排序和分页，字段允许但操作符或排序字段不允许时返回错误
+func NewSqlCndFromQuery(c *gin.Context, spec FilterSpec) (*SqlCnd, error) {
+	cnd := NewSqlCnd()
+	query := c.Request.URL.Query()
+	// 按参数名排序，同样的参数生成同样的SQL
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values := query[key]
+		if reservedParams[key] || len(values) == 0 {
+			continue
+		}
+		field, op := parseFilterKey(key)
+		known, allowed := spec.allow(field, op)
+		if !known {
+			continue
+		}
+		if !allowed {
+			return nil, fmt.Errorf("filter %s is not allowed", key)
+		}
+		if err := applyFilter(cnd, spec.column(field), op, values[0]); err != nil {
+			return nil, fmt.Errorf("filter %s: %w", key, err)
+		}
+	}
+
+	orders := c.Query("sort")
+	if orders == "" {
+		orders = spec.DefaultSort
+	}
+	for _, s := range strings.Split(orders, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		desc := strings.HasPrefix(s, "-")
+		s = strings.TrimPrefix(s, "-")
+		if !inStrings(s, spec.Sorts) && s != strings.TrimPrefix(spec.DefaultSort, "-") {
+			return nil, fmt.Errorf("sort by %s is not allowed", s)
+		}
+		if desc {
+			cnd.Desc(spec.column(s))
+		} else {
+			cnd.Asc(spec.column(s))
+		}
+	}
+
+	paging := GetPaging(c)
+	maxLimit := spec.MaxLimit
+	if maxLimit <= 0 {
+		maxLimit = 100
+	}
+	if paging.Limit > maxLimit {
+		paging.Limit = maxLimit
+	}
+	cnd.Page(paging.Page, paging.Limit)
+	return cnd, nil
+}
+
+// parseFilterKey 拆分参数名中的字段和操作符，created_at_gte => created_at, gte
+func parseFilterKey(key string) (string, FilterOp) {
+	if i := strings.LastIndex(key, "_"); i > 0 {
+		if op := FilterOp(key[i+1:]); filterOps[op] {
+			return key[:i], op
+		}
+	}
+	return key, OpEq
+}
+
+func applyFilter(cnd *SqlCnd, column string, op FilterOp, value string) error {
+	switch op {
+	case OpEq:
+		cnd.Eq(column, value)
+	case OpNe:
+		cnd.NotEq(column, value)
+	case OpGt:
+		cnd.Gt(column, value)
+	case OpGte:
+		cnd.Gte(column, value)
+	case OpLt:
+		cnd.Lt(column, value)
+	case OpLte:
+		cnd.Lte(column, value)
+	case OpLike:
+		cnd.Like(column, escapeLike(value))
+	case OpStarts:
+		cnd.Starting(column, escapeLike(value))
+	case OpIn, OpNotIn:
+		list := splitValues(value)
+		if len(list) == 0 {
+			return fmt.Errorf("empty list")
+		}
+		if op == OpIn {
+			cnd.In(column, list)
+		} else {
+			cnd.NotIn(column, list)
+		}
+	case OpBetween:
+		list := splitValues(value)
+		if len(list) != 2 {
+			return fmt.Errorf("between requires two values")
+		}
+		cnd.Between(column, list[0], list[1])
+	case OpNull:
+		switch value {
+		case "1", "true":
+			cnd.IsNull(column)
+		case "0", "false":
+			cnd.IsNotNull(column)
+		default:
+			return fmt.Errorf("invalid value %q", value)
+		}
+	}
+	return nil
+}
+
+func splitValues(value string) []string {
+	var list []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// escapeLike 转义LIKE中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func inStrings(s string, list []string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
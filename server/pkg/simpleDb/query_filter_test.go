@@ -0,0 +1,134 @@
+package simpleDb
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func queryContext(rawQuery string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/?"+rawQuery, nil)
+	return c
+}
+
+func TestParseFilterKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		field string
+		op    FilterOp
+	}{
+		{"status", "status", OpEq},
+		{"status_eq", "status", OpEq},
+		{"status_ne", "status", OpNe},
+		{"created_at_gte", "created_at", OpGte},
+		{"created_at_lt", "created_at", OpLt},
+		{"title_like", "title", OpLike},
+		{"title_starts", "title", OpStarts},
+		{"status_in", "status", OpIn},
+		{"status_nin", "status", OpNotIn},
+		{"created_at_between", "created_at", OpBetween},
+		{"deleted_at_null", "deleted_at", OpNull},
+		// 不是操作符的后缀属于字段名
+		{"user_id", "user_id", OpEq},
+		{"_in", "_in", OpEq},
+	}
+	for _, tt := range tests {
+		field, op := parseFilterKey(tt.key)
+		assert.Equal(t, tt.field, field, tt.key)
+		assert.Equal(t, tt.op, op, tt.key)
+	}
+}
+
+func TestNewSqlCndFromQuery(t *testing.T) {
+	db := dryRunDB(t)
+	spec := FilterSpec{
+		Fields: map[string][]FilterOp{
+			"status":     {OpEq, OpIn, OpNotIn},
+			"title":      {OpLike, OpStarts},
+			"user_id":    {OpEq, OpNe},
+			"created_at": {OpGte, OpLt, OpBetween},
+			"deleted_at": {OpNull},
+		},
+		Columns:     map[string]string{"title": "article.title"},
+		Sorts:       []string{"created_at"},
+		DefaultSort: "-id",
+	}
+
+	tests := []struct {
+		query string
+		where string
+		vars  []interface{}
+	}{
+		{"status=1", "status = (?)", []interface{}{"1"}},
+		{"status_in=1,2", "status in (?,?) ", []interface{}{"1", "2"}},
+		{"status_nin=1,%20,2", "status not in (?,?) ", []interface{}{"1", "2"}},
+		{"user_id_ne=3", "user_id <> (?)", []interface{}{"3"}},
+		{"title_like=50%25_off", "article.title LIKE ?", []interface{}{`%50\%\_off%`}},
+		{"title_starts=go", "article.title LIKE ?", []interface{}{"go%"}},
+		{"created_at_gte=1&created_at_lt=2", "created_at >= (?) AND created_at < (?)", []interface{}{"1", "2"}},
+		{"created_at_between=1,2", "(created_at BETWEEN ? AND ?)", []interface{}{"1", "2"}},
+		{"deleted_at_null=1", "deleted_at IS NULL", []interface{}{}},
+		{"deleted_at_null=false", "deleted_at IS NOT NULL", []interface{}{}},
+		// 不在白名单中的字段和保留参数被忽略
+		{"password=1&page=2&limit=5&cursor=x", "", []interface{}{}},
+	}
+	for _, tt := range tests {
+		cnd, err := NewSqlCndFromQuery(queryContext(tt.query), spec)
+		require.NoError(t, err, tt.query)
+		query, vars := findSQL(db, cnd)
+		assert.Contains(t, query, tt.where, tt.query)
+		if tt.where == "" {
+			assert.Equal(t, "SELECT * FROM `cnd_model` WHERE `cnd_model`.`deleted_at` IS NULL ORDER BY id DESC LIMIT 5 OFFSET 5", query)
+		}
+		assert.Equal(t, tt.vars, vars, tt.query)
+	}
+}
+
+func TestNewSqlCndFromQueryRejected(t *testing.T) {
+	spec := FilterSpec{
+		Fields:      map[string][]FilterOp{"status": {OpEq}, "created_at": {OpBetween}, "deleted_at": {OpNull}},
+		Sorts:       []string{"created_at"},
+		DefaultSort: "-id",
+	}
+	tests := []string{
+		// 字段允许但操作符不允许
+		"status_in=1,2",
+		"status_like=1",
+		// 值不合法
+		"created_at_between=1",
+		"deleted_at_null=yes",
+		// 排序字段不在白名单中
+		"sort=password",
+		"sort=-created_at,title",
+	}
+	for _, query := range tests {
+		_, err := NewSqlCndFromQuery(queryContext(query), spec)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestNewSqlCndFromQuerySort(t *testing.T) {
+	db := dryRunDB(t)
+	spec := FilterSpec{
+		Fields:      map[string][]FilterOp{"status": {OpEq}},
+		Columns:     map[string]string{"created_at": "article.created_at"},
+		Sorts:       []string{"created_at"},
+		DefaultSort: "-id",
+		MaxLimit:    50,
+	}
+
+	cnd, err := NewSqlCndFromQuery(queryContext(""), spec)
+	require.NoError(t, err)
+	query, _ := findSQL(db, cnd)
+	assert.Contains(t, query, "ORDER BY id DESC LIMIT 20")
+
+	cnd, err = NewSqlCndFromQuery(queryContext("sort=-created_at,id&limit=500&page=2"), spec)
+	require.NoError(t, err)
+	query, _ = findSQL(db, cnd)
+	assert.Contains(t, query, "ORDER BY article.created_at DESC,id ASC LIMIT 50 OFFSET 50")
+}
@@ -98,6 +98,17 @@ func (q *QueryParams) PageByReq() *QueryParams {
 	return q
 }
 
+// CursorByReq 从请求中获取游标分页参数：cursor、limit
+func (q *QueryParams) CursorByReq() *QueryParams {
+	if q.Ctx == nil {
+		return q
+	}
+	paging := GetPaging(q.Ctx)
+	q.Limit(paging.Limit)
+	q.After(paging.Cursor)
+	return q
+}
+
 func (q *QueryParams) Asc(column string) *QueryParams {
 	q.Orders = append(q.Orders, OrderByCol{Column: column, Asc: true})
 	return q
@@ -0,0 +1,76 @@
+package simpleDb
+
+import (
+	"go-skeleton/pkg/config"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// newResolver 根据配置创建读写分离插件，没有配置从库时返回nil。
+// 注册后Find/First/Take/Count等查询走从库，Create/Save/Update/Delete及事务走主库
+func newResolver(cfg *dbConfig) *dbresolver.DBResolver {
+	if len(cfg.Replicas) == 0 && len(cfg.Resolvers) == 0 {
+		return nil
+	}
+
+	resolver := &dbresolver.DBResolver{}
+	if len(cfg.Replicas) > 0 {
+		resolver = dbresolver.Register(dbresolver.Config{
+			Replicas: dialectors(cfg, cfg.Replicas),
+			Policy:   dbresolver.RandomPolicy{},
+		})
+	}
+	for _, r := range cfg.Resolvers {
+		tables := make([]interface{}, 0, len(r.Tables))
+		for _, table := range r.Tables {
+			tables = append(tables, table)
+		}
+		resolver = resolver.Register(dbresolver.Config{
+			Sources:  dialectors(cfg, r.Sources),
+			Replicas: dialectors(cfg, r.Replicas),
+			Policy:   dbresolver.RandomPolicy{},
+		}, tables...)
+	}
+	return resolver
+}
+
+// dialectors 创建数据源，未配置的账号、库名等沿用主库配置
+func dialectors(primary *dbConfig, list []config.DbConfig) []gorm.Dialector {
+	dialectors := make([]gorm.Dialector, 0, len(list))
+	for _, c := range list {
+		cfg := &dbConfig{
+			DbUser:     c.DbUser,
+			DbPassWord: c.DbPassWord,
+			DbHost:     c.DbHost,
+			DbPort:     c.DbPort,
+			DbName:     c.DbName,
+		}
+		if cfg.DbUser == "" {
+			cfg.DbUser, cfg.DbPassWord = primary.DbUser, primary.DbPassWord
+		}
+		if cfg.DbHost == "" {
+			cfg.DbHost = primary.DbHost
+		}
+		if cfg.DbPort == "" {
+			cfg.DbPort = primary.DbPort
+		}
+		if cfg.DbName == "" {
+			cfg.DbName = primary.DbName
+		}
+		dialectors = append(dialectors, mysql.Open(cfg.dsn()))
+	}
+	return dialectors
+}
+
+// Master 强制使用主库，用于写后立即读等需要避免主从延迟的场景
+// eg: dao.ArticleDao.Get(simpleDb.Master(simpleDb.DB()), id)
+func Master(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Write)
+}
+
+// Replica 强制使用从库
+func Replica(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Read)
+}
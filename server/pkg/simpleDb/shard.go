@@ -0,0 +1,342 @@
+package simpleDb
+
+import (
+	"errors"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 分表：按分片键计算数据所在的表，eg:
+//
+//	r := simpleDb.NewHashShard("notification", 16) // notification_00 ~ notification_15
+//	tx, _ := simpleDb.ShardDB(db, r, userId)
+//	tx.Create(n)
+//
+// 分表以逻辑表为模板通过CREATE TABLE ... LIKE创建，逻辑表本身不存数据，
+// 通过命令 shard:create 预先创建分表，按月分表需要定期执行
+
+// 分表类型
+const (
+	ShardTypeHash  = "hash"
+	ShardTypeMonth = "month"
+)
+
+// ErrShardKey 分片键的类型不支持
+var ErrShardKey = errors.New("simpleDb: unsupported shard key")
+
+// ShardResolver 分表规则
+type ShardResolver interface {
+	// Base 逻辑表名，也是创建分表的模板表
+	Base() string
+	// Table 分片键所在的分表
+	Table(key interface{}) (string, error)
+	// Upcoming 需要预先创建的分表，按月分表为当前月及之后months个月，按hash分表为所有分表
+	Upcoming(months int) []string
+}
+
+// HashShard 按分片键取模分表，整数直接取模，字符串按fnv hash取模，eg: 按user_id分表
+type HashShard struct {
+	base  string
+	count int
+}
+
+// NewHashShard count为分表数，上线后不能修改
+func NewHashShard(base string, count int) *HashShard {
+	if count <= 0 {
+		count = 1
+	}
+	return &HashShard{base: base, count: count}
+}
+
+func (h *HashShard) Base() string {
+	return h.base
+}
+
+func (h *HashShard) Table(key interface{}) (string, error) {
+	var n uint64
+	switch v := key.(type) {
+	case int:
+		n = uint64(v)
+	case int64:
+		n = uint64(v)
+	case uint:
+		n = uint64(v)
+	case uint64:
+		n = v
+	case string:
+		f := fnv.New32a()
+		_, _ = f.Write([]byte(v))
+		n = uint64(f.Sum32())
+	default:
+		return "", fmt.Errorf("%w: %T", ErrShardKey, key)
+	}
+	return h.table(int(n % uint64(h.count))), nil
+}
+
+// Tables 所有分表，跨分表查询时使用
+func (h *HashShard) Tables() []string {
+	tables := make([]string, h.count)
+	for i := range tables {
+		tables[i] = h.table(i)
+	}
+	return tables
+}
+
+func (h *HashShard) Upcoming(int) []string {
+	return h.Tables()
+}
+
+func (h *HashShard) table(i int) string {
+	return fmt.Sprintf("%s_%02d", h.base, i)
+}
+
+// MonthShard 按时间所在的月分表，eg: audit_log_202110
+type MonthShard struct {
+	base string
+	loc  *time.Location
+}
+
+// NewMonthShard loc为计算月份的时区，为nil时使用time.Local
+func NewMonthShard(base string, loc *time.Location) *MonthShard {
+	if loc == nil {
+		loc = time.Local
+	}
+	return &MonthShard{base: base, loc: loc}
+}
+
+func (m *MonthShard) Base() string {
+	return m.base
+}
+
+// Table key为time.Time或unix时间戳
+func (m *MonthShard) Table(key interface{}) (string, error) {
+	switch v := key.(type) {
+	case time.Time:
+		return m.table(v), nil
+	case int64:
+		return m.table(time.Unix(v, 0)), nil
+	default:
+		return "", fmt.Errorf("%w: %T", ErrShardKey, key)
+	}
+}
+
+// Between from到to之间的分表，按时间倒序，跨分表查询最近的数据时使用
+func (m *MonthShard) Between(from, to time.Time) []string {
+	from, to = from.In(m.loc), to.In(m.loc)
+	start := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, m.loc)
+	var tables []string
+	for t := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, m.loc); !t.Before(start); t = t.AddDate(0, -1, 0) {
+		tables = append(tables, m.table(t))
+	}
+	return tables
+}
+
+func (m *MonthShard) Upcoming(months int) []string {
+	now := time.Now()
+	return m.Between(now, now.AddDate(0, months, 0))
+}
+
+func (m *MonthShard) table(t time.Time) string {
+	return m.base + "_" + t.In(m.loc).Format("200601")
+}
+
+var (
+	shardsMu sync.RWMutex
+	shards   = map[string]ShardResolver{}
+)
+
+// RegisterShard 注册分表规则，同一个逻辑表只能有一个规则
+func RegisterShard(r ShardResolver) {
+	shardsMu.Lock()
+	defer shardsMu.Unlock()
+	shards[r.Base()] = r
+}
+
+// Shard 逻辑表的分表规则，未分表时返回nil
+func Shard(base string) ShardResolver {
+	shardsMu.RLock()
+	defer shardsMu.RUnlock()
+	return shards[base]
+}
+
+// Shards 所有分表规则，按逻辑表名排序
+func Shards() []ShardResolver {
+	shardsMu.RLock()
+	defer shardsMu.RUnlock()
+	list := make([]ShardResolver, 0, len(shards))
+	for _, r := range shards {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Base() < list[j].Base() })
+	return list
+}
+
+// initShards 注册database.Shards中配置的分表
+func initShards(cfg map[string]config.ShardConfig) error {
+	for base, c := range cfg {
+		switch c.Type {
+		case "", ShardTypeHash:
+			RegisterShard(NewHashShard(base, c.Count))
+		case ShardTypeMonth:
+			RegisterShard(NewMonthShard(base, nil))
+		default:
+			return fmt.Errorf("unknown shard type %q for %s", c.Type, base)
+		}
+	}
+	return nil
+}
+
+// ShardDB 返回分片键所在分表的会话
+func ShardDB(db *gorm.DB, r ShardResolver, key interface{}) (*gorm.DB, error) {
+	table, err := r.Table(key)
+	if err != nil {
+		return nil, err
+	}
+	return db.Table(table), nil
+}
+
+// CreateShardTables 以逻辑表为模板创建分表，已存在的跳过，返回新建的表
+func CreateShardTables(db *gorm.DB, r ShardResolver, tables []string) ([]string, error) {
+	var created []string
+	m := db.Migrator()
+	for _, table := range tables {
+		if m.HasTable(table) {
+			continue
+		}
+		if err := db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s` LIKE `%s`", table, r.Base())).Error; err != nil {
+			return created, fmt.Errorf("create %s: %w", table, err)
+		}
+		created = append(created, table)
+	}
+	return created, nil
+}
+
+// FindShardPage 跨分表分页查询，结果按cnd的排序合并，out必须是结构体切片的指针。
+// 每个分表查询前page*limit条再合并，页数越大代价越高，只适合查询前几页；不存在的分表跳过
+func FindShardPage(db *gorm.DB, tables []string, cnd *SqlCnd, out interface{}) (*Paging, error) {
+	paging := &Paging{Page: 1, Limit: 20}
+	if cnd.Paging != nil {
+		if cnd.Paging.Page > 0 {
+			paging.Page = cnd.Paging.Page
+		}
+		if cnd.Paging.Limit > 0 {
+			paging.Limit = cnd.Paging.Limit
+		}
+	}
+	sch, err := schema.Parse(out, schemaCache, db.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	sliceValue := reflect.ValueOf(out).Elem()
+	merged := reflect.MakeSlice(sliceValue.Type(), 0, 0)
+	model := reflect.New(sch.ModelType).Interface()
+	shardCnd := *cnd
+	shardCnd.Paging = &Paging{Page: 1, Limit: paging.Page * paging.Limit}
+	m := db.Migrator()
+	for _, table := range tables {
+		if !m.HasTable(table) {
+			continue
+		}
+		rows := reflect.New(sliceValue.Type())
+		if err := shardCnd.Build(db.Table(table)).Find(rows.Interface()).Error; err != nil {
+			return nil, err
+		}
+		merged = reflect.AppendSlice(merged, rows.Elem())
+		paging.Total += cnd.Count(db.Table(table), model)
+	}
+
+	if err := sortRows(merged, sch, cnd.Orders); err != nil {
+		return nil, err
+	}
+	start := paging.Offset()
+	if start > merged.Len() {
+		start = merged.Len()
+	}
+	end := start + paging.Limit
+	if end > merged.Len() {
+		end = merged.Len()
+	}
+	sliceValue.Set(merged.Slice(start, end))
+	return paging, nil
+}
+
+// sortRows 按排序列稳定排序，排序值相同时保持分表的顺序
+func sortRows(rows reflect.Value, sch *schema.Schema, orders []OrderByCol) error {
+	fields := make([]*schema.Field, len(orders))
+	for i, order := range orders {
+		if fields[i] = sch.LookUpField(columnName(order.Column)); fields[i] == nil {
+			return fmt.Errorf("simpleDb: order column %s not found", order.Column)
+		}
+	}
+	sort.SliceStable(rows.Interface(), func(i, j int) bool {
+		a, b := reflect.Indirect(rows.Index(i)), reflect.Indirect(rows.Index(j))
+		for k, field := range fields {
+			va, _ := field.ValueOf(a)
+			vb, _ := field.ValueOf(b)
+			if c := compareValues(va, vb); c != 0 {
+				return (c < 0) == orders[k].Asc
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+// compareValues 比较排序列的值，支持整数、浮点数、字符串和时间，其他类型按字符串比较，nil排在前面
+func compareValues(a, b interface{}) int {
+	va, vb := reflect.Indirect(reflect.ValueOf(a)), reflect.Indirect(reflect.ValueOf(b))
+	if !va.IsValid() || !vb.IsValid() {
+		return compareFloat(btof(va.IsValid()), btof(vb.IsValid()))
+	}
+	if ta, ok := va.Interface().(time.Time); ok {
+		if tb, ok := vb.Interface().(time.Time); ok {
+			return compareFloat(float64(ta.UnixNano()), float64(tb.UnixNano()))
+		}
+	}
+	switch va.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if x, y := va.Int(), vb.Int(); x != y {
+			return compareFloat(btof(x > y), btof(x < y))
+		}
+		return 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if x, y := va.Uint(), vb.Uint(); x != y {
+			return compareFloat(btof(x > y), btof(x < y))
+		}
+		return 0
+	case reflect.Float32, reflect.Float64:
+		return compareFloat(va.Float(), vb.Float())
+	case reflect.Bool:
+		return compareFloat(btof(va.Bool()), btof(vb.Bool()))
+	case reflect.String:
+		return strings.Compare(va.String(), vb.String())
+	}
+	return strings.Compare(fmt.Sprint(va.Interface()), fmt.Sprint(vb.Interface()))
+}
+
+func compareFloat(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func btof(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,115 @@
+package simpleDb
+
+import (
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/metrics"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const slowStartKey = "simpleDb:slow_start"
+
+var slowQueryConfig atomic.Value
+
+// watchSlowQuery 读取慢查询配置，配置文件修改后自动生效
+func watchSlowQuery() {
+	slowQueryConfig.Store(config.Conf.DbConfig.SlowQuery)
+	config.OnChange("database.SlowQuery", func(old, new interface{}) {
+		var cfg config.SlowQueryConfig
+		if err := config.UnmarshalKey("database.SlowQuery", &cfg); err != nil {
+			zap.L().Error("解析慢查询配置失败", zap.Error(err))
+			return
+		}
+		slowQueryConfig.Store(cfg)
+	})
+}
+
+// slowQueryPlugin 记录耗时超过阈值的sql，日志包含sql、影响行数和调用位置，并统计到db_slow_query_duration_seconds
+type slowQueryPlugin struct{}
+
+func (p slowQueryPlugin) Name() string {
+	return "simpleDb:slow_query"
+}
+
+func (p slowQueryPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, h := range hooks {
+		if err := h.before("simpleDb:slow_before_"+h.operation, slowStart); err != nil {
+			return err
+		}
+		if err := h.after("simpleDb:slow_after_"+h.operation, slowCheck(h.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func slowStart(db *gorm.DB) {
+	if cfg, ok := slowQueryConfig.Load().(config.SlowQueryConfig); ok && cfg.Enable {
+		db.InstanceSet(slowStartKey, time.Now())
+	}
+}
+
+func slowCheck(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(slowStartKey)
+		if !ok {
+			return
+		}
+		cfg, _ := slowQueryConfig.Load().(config.SlowQueryConfig)
+		elapsed := time.Since(v.(time.Time))
+		if !cfg.Enable || elapsed < cfg.Threshold {
+			return
+		}
+		metrics.DbSlowQueries.WithLabelValues(operation, db.Statement.Table).Observe(elapsed.Seconds())
+
+		sql := db.Statement.SQL.String()
+		if !cfg.RedactArgs {
+			sql = db.Dialector.Explain(sql, db.Statement.Vars...)
+		}
+		fields := []zap.Field{
+			zap.String("sql", sql),
+			zap.Duration("elapsed", elapsed),
+			zap.Int64("rows", db.RowsAffected),
+			zap.String("caller", slowCaller()),
+		}
+		if db.Error != nil {
+			fields = append(fields, zap.Error(db.Error))
+		}
+		zap.L().Warn("slow query", fields...)
+	}
+}
+
+// slowCaller 跳过gorm和simpleDb内部的调用，返回业务代码的位置
+func slowCaller() string {
+	pcs := make([]uintptr, 20)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.Contains(frame.File, "gorm.io/") && !strings.Contains(frame.File, "/pkg/simpleDb/") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
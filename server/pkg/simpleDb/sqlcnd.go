@@ -1,15 +1,36 @@
 package simpleDb
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 软删除数据的查询范围
+type TrashedScope int
+
+const (
+	TrashedExclude TrashedScope = iota // 默认，不包含已删除的数据
+	TrashedWith                        // 包含已删除的数据
+	TrashedOnly                        // 只查询已删除的数据
 )
 
+// ErrEmptyCondition 批量更新、删除时没有任何条件，防止误操作整张表
+var ErrEmptyCondition = errors.New("simpleDb: bulk update or delete without condition")
+
 type SqlCnd struct {
 	SelectCols []string     // 要查询的字段，如果为空，表示查询所有字段
+	Joins      []ParamPair  // 关联查询
+	Preloads   []ParamPair  // 预加载的关联
 	Params     []ParamPair  // 参数
+	Groups     []string     // 分组
+	Havings    []ParamPair  // 分组后的条件
 	Orders     []OrderByCol // 排序
 	Paging     *Paging      // 分页
+	Trashed    TrashedScope // 软删除数据的查询范围
 }
 
 func NewSqlCnd() *SqlCnd {
@@ -23,6 +44,11 @@ func (s *SqlCnd) Cols(selectCols ...string) *SqlCnd {
 	return s
 }
 
+// Select 只查询指定的字段，列表只需要部分字段时使用，eg: cnd.Select("id", "title", "published_at")
+func (s *SqlCnd) Select(columns ...string) *SqlCnd {
+	return s.Cols(columns...)
+}
+
 func (s *SqlCnd) Eq(column string, args ...interface{}) *SqlCnd {
 	s.Where(column+" = ?", args)
 	return s
@@ -73,8 +99,111 @@ func (s *SqlCnd) In(column string, params interface{}) *SqlCnd {
 	return s
 }
 
+func (s *SqlCnd) NotIn(column string, params interface{}) *SqlCnd {
+	s.Where(column+" not in (?) ", params)
+	return s
+}
+
+func (s *SqlCnd) Between(column string, start, end interface{}) *SqlCnd {
+	s.Where(column+" BETWEEN ? AND ?", start, end)
+	return s
+}
+
+func (s *SqlCnd) NotBetween(column string, start, end interface{}) *SqlCnd {
+	s.Where(column+" NOT BETWEEN ? AND ?", start, end)
+	return s
+}
+
+func (s *SqlCnd) IsNull(column string) *SqlCnd {
+	s.Where(column + " IS NULL")
+	return s
+}
+
+func (s *SqlCnd) IsNotNull(column string) *SqlCnd {
+	s.Where(column + " IS NOT NULL")
+	return s
+}
+
+// Join 关联查询，使用时条件和排序的字段需要带上表名，eg:
+//	cnd.Join("JOIN article_tag ON article_tag.article_id = article.id").Eq("article_tag.tag_id", 1)
+func (s *SqlCnd) Join(query string, args ...interface{}) *SqlCnd {
+	s.Joins = append(s.Joins, ParamPair{Query: query, Args: args})
+	return s
+}
+
+// Preload 预加载关联，只在查询列表时生效，eg: cnd.Preload("Category")
+func (s *SqlCnd) Preload(query string, args ...interface{}) *SqlCnd {
+	s.Preloads = append(s.Preloads, ParamPair{Query: query, Args: args})
+	return s
+}
+
+// GroupBy 分组，通常配合Select使用聚合函数，eg:
+//	cnd.Select("cid", "COUNT(*) AS count").GroupBy("cid").Having("COUNT(*) > ?", 10)
+func (s *SqlCnd) GroupBy(columns ...string) *SqlCnd {
+	s.Groups = append(s.Groups, columns...)
+	return s
+}
+
+// Having 分组后的过滤条件，多个条件之间为AND
+func (s *SqlCnd) Having(query string, args ...interface{}) *SqlCnd {
+	s.Havings = append(s.Havings, ParamPair{Query: query, Args: args})
+	return s
+}
+
+// WithTrashed 查询结果包含已软删除的数据
+func (s *SqlCnd) WithTrashed() *SqlCnd {
+	s.Trashed = TrashedWith
+	return s
+}
+
+// OnlyTrashed 只查询已软删除的数据
+func (s *SqlCnd) OnlyTrashed() *SqlCnd {
+	s.Trashed = TrashedOnly
+	return s
+}
+
 func (s *SqlCnd) Where(query string, args ...interface{}) *SqlCnd {
-	s.Params = append(s.Params, ParamPair{query, args})
+	s.Params = append(s.Params, ParamPair{Query: query, Args: args})
+	return s
+}
+
+// Or 以OR连接条件，eg: NewSqlCnd().Eq("status", 1).Or("user_id = ?", 2)
+func (s *SqlCnd) Or(query string, args ...interface{}) *SqlCnd {
+	s.Params = append(s.Params, ParamPair{Query: query, Args: args, Type: ParamOr})
+	return s
+}
+
+// Not 取反条件，eg: NewSqlCnd().Not("status = ?", 1)
+func (s *SqlCnd) Not(query string, args ...interface{}) *SqlCnd {
+	s.Params = append(s.Params, ParamPair{Query: query, Args: args, Type: ParamNot})
+	return s
+}
+
+// WhereGroup 以AND连接一组括号包裹的条件
+//	cnd.Eq("status", 1).WhereGroup(func(c *SqlCnd) {
+//		c.Like("title", "go").Or("content LIKE ?", "%go%")
+//	})
+//	// status = 1 AND (title LIKE '%go%' OR content LIKE '%go%')
+func (s *SqlCnd) WhereGroup(fn func(c *SqlCnd)) *SqlCnd {
+	return s.group(fn, ParamAnd)
+}
+
+// OrGroup 以OR连接一组括号包裹的条件
+func (s *SqlCnd) OrGroup(fn func(c *SqlCnd)) *SqlCnd {
+	return s.group(fn, ParamOr)
+}
+
+// NotGroup 对一组括号包裹的条件取反
+func (s *SqlCnd) NotGroup(fn func(c *SqlCnd)) *SqlCnd {
+	return s.group(fn, ParamNot)
+}
+
+func (s *SqlCnd) group(fn func(c *SqlCnd), paramType ParamType) *SqlCnd {
+	g := NewSqlCnd()
+	fn(g)
+	if len(g.Params) > 0 {
+		s.Params = append(s.Params, ParamPair{Type: paramType, Group: g})
+	}
 	return s
 }
 
@@ -111,10 +240,10 @@ func (s *SqlCnd) Build(db *gorm.DB) *gorm.DB {
 	}
 
 	// where
-	if len(s.Params) > 0 {
-		for _, param := range s.Params {
-			ret = ret.Where(param.Query, param.Args...)
-		}
+	ret = s.buildWhere(ret)
+	ret = s.buildGroup(ret)
+	for _, preload := range s.Preloads {
+		ret = ret.Preload(preload.Query, preload.Args...)
 	}
 
 	// order
@@ -140,6 +269,70 @@ func (s *SqlCnd) Build(db *gorm.DB) *gorm.DB {
 	return ret
 }
 
+// buildWhere 构建关联、软删除范围及where条件
+func (s *SqlCnd) buildWhere(db *gorm.DB) *gorm.DB {
+	ret := db
+	for _, join := range s.Joins {
+		ret = ret.Joins(join.Query, join.Args...)
+	}
+	switch s.Trashed {
+	case TrashedWith:
+		ret = ret.Unscoped()
+	case TrashedOnly:
+		// 带上表名，关联查询的表也有deleted_at时不会报字段不明确
+		ret = ret.Unscoped().Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: "deleted_at"}, Value: nil})
+	}
+	if s.hasOr() {
+		// 有OR条件时将所有条件放在括号内，避免与软删除、租户及db上已有的条件组合后扩大查询范围
+		return ret.Where(s.buildParams(db.Session(&gorm.Session{NewDB: true})))
+	}
+	return s.buildParams(ret)
+}
+
+func (s *SqlCnd) hasOr() bool {
+	for _, param := range s.Params {
+		if param.Type == ParamOr {
+			return true
+		}
+	}
+	return false
+}
+
+// buildParams 按顺序构建条件，嵌套的条件组使用新的会话构建后作为分组条件
+func (s *SqlCnd) buildParams(db *gorm.DB) *gorm.DB {
+	ret := db
+	for _, param := range s.Params {
+		var query interface{} = param.Query
+		args := param.Args
+		if param.Group != nil {
+			query = param.Group.buildWhere(db.Session(&gorm.Session{NewDB: true}))
+			args = nil
+		}
+
+		switch param.Type {
+		case ParamOr:
+			ret = ret.Or(query, args...)
+		case ParamNot:
+			ret = ret.Not(query, args...)
+		default:
+			ret = ret.Where(query, args...)
+		}
+	}
+	return ret
+}
+
+// buildGroup 构建group by和having
+func (s *SqlCnd) buildGroup(db *gorm.DB) *gorm.DB {
+	ret := db
+	if len(s.Groups) > 0 {
+		ret = ret.Group(strings.Join(s.Groups, ", "))
+	}
+	for _, having := range s.Havings {
+		ret = ret.Having(having.Query, having.Args...)
+	}
+	return ret
+}
+
 func (s *SqlCnd) Find(db *gorm.DB, out interface{}) {
 	if err := s.Build(db).Find(out).Error; err != nil {
 		logrus.Error(err)
@@ -154,14 +347,8 @@ func (s *SqlCnd) FindOne(db *gorm.DB, out interface{}) error {
 }
 
 func (s *SqlCnd) Count(db *gorm.DB, model interface{}) int64 {
-	ret := db.Model(model)
-
-	// where
-	if len(s.Params) > 0 {
-		for _, query := range s.Params {
-			ret = ret.Where(query.Query, query.Args...)
-		}
-	}
+	// 有分组时返回分组的数量
+	ret := s.buildGroup(s.buildWhere(db.Model(model)))
 
 	var count int64
 	if err := ret.Count(&count).Error; err != nil {
@@ -169,3 +356,50 @@ func (s *SqlCnd) Count(db *gorm.DB, model interface{}) int64 {
 	}
 	return count
 }
+
+// Updates 按条件批量更新，返回影响的行数，没有条件时返回ErrEmptyCondition，
+// 不支持关联查询，排序和分页也会被忽略
+func (s *SqlCnd) Updates(db *gorm.DB, model interface{}, columns map[string]interface{}) (int64, error) {
+	if err := s.checkBulk(); err != nil {
+		return 0, err
+	}
+	ret := s.buildWhere(db.Model(model)).Updates(columns)
+	return ret.RowsAffected, ret.Error
+}
+
+// Delete 按条件批量删除，有deleted_at字段的模型为软删除，只删除未删除的数据，忽略WithTrashed、OnlyTrashed，
+// 返回影响的行数，没有条件时返回ErrEmptyCondition
+func (s *SqlCnd) Delete(db *gorm.DB, model interface{}) (int64, error) {
+	if err := s.checkBulk(); err != nil {
+		return 0, err
+	}
+	cnd := *s
+	cnd.Trashed = TrashedExclude
+	ret := cnd.buildWhere(db).Delete(model)
+	return ret.RowsAffected, ret.Error
+}
+
+// ForceDelete 按条件批量物理删除，包含已软删除的数据，OnlyTrashed时只删除已软删除的数据，eg: 清空回收站
+//
+//	cnd.OnlyTrashed().Lt("deleted_at", time.Now().AddDate(0, 0, -30)).ForceDelete(db, &model.Article{})
+func (s *SqlCnd) ForceDelete(db *gorm.DB, model interface{}) (int64, error) {
+	if err := s.checkBulk(); err != nil {
+		return 0, err
+	}
+	cnd := *s
+	if cnd.Trashed == TrashedExclude {
+		cnd.Trashed = TrashedWith
+	}
+	ret := cnd.buildWhere(db).Unscoped().Delete(model)
+	return ret.RowsAffected, ret.Error
+}
+
+func (s *SqlCnd) checkBulk() error {
+	if len(s.Params) == 0 {
+		return ErrEmptyCondition
+	}
+	if len(s.Joins) > 0 {
+		return errors.New("simpleDb: bulk update or delete does not support joins")
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package simpleDb
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type cndModel struct {
+	ID        int64
+	Status    int
+	UserId    int64
+	DeletedAt gorm.DeletedAt
+}
+
+func (cndModel) TableName() string {
+	return "cnd_model"
+}
+
+// dryRunDB 只生成sql，不连接数据库
+func dryRunDB(t *testing.T) *gorm.DB {
+	conn, err := sql.Open("mysql", "root@tcp(127.0.0.1:3306)/test")
+	require.NoError(t, err)
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return db
+}
+
+func findSQL(db *gorm.DB, cnd *SqlCnd) (string, []interface{}) {
+	stmt := cnd.Build(db.Model(&cndModel{})).Find(&[]cndModel{}).Statement
+	return stmt.SQL.String(), stmt.Vars
+}
+
+func TestSqlCndWhere(t *testing.T) {
+	db := dryRunDB(t)
+
+	// Eq等方法以切片传入参数，展开为括号内的参数
+	query, vars := findSQL(db, NewSqlCnd().Eq("status", 1).In("id", []int64{1, 2}))
+	assert.Equal(t, "SELECT * FROM `cnd_model` WHERE status = (?) AND id in (?,?)  AND `cnd_model`.`deleted_at` IS NULL", query)
+	assert.Equal(t, []interface{}{1, int64(1), int64(2)}, vars)
+
+	query, vars = findSQL(db, NewSqlCnd().Where("status = ?", 1).Where("id > ?", 10).Desc("id").Page(2, 20))
+	assert.Equal(t, "SELECT * FROM `cnd_model` WHERE status = ? AND id > ? AND `cnd_model`.`deleted_at` IS NULL ORDER BY id DESC LIMIT 20 OFFSET 20", query)
+	assert.Equal(t, []interface{}{1, 10}, vars)
+
+	query, _ = findSQL(db, NewSqlCnd().Where("status = ?", 1).WhereGroup(func(c *SqlCnd) {
+		c.Where("user_id = ?", 2).Or("user_id = ?", 3)
+	}))
+	assert.Equal(t, "SELECT * FROM `cnd_model` WHERE status = ? AND (user_id = ? OR user_id = ?) AND `cnd_model`.`deleted_at` IS NULL", query)
+}
+
+func TestSqlCndOr(t *testing.T) {
+	db := dryRunDB(t)
+
+	query, vars := findSQL(db, NewSqlCnd().Where("status = ?", 1).Or("user_id = ?", 2))
+	assert.Equal(t, "SELECT * FROM `cnd_model` WHERE (status = ? OR user_id = ?) AND `cnd_model`.`deleted_at` IS NULL", query)
+	assert.Equal(t, []interface{}{1, 2}, vars)
+
+	// db上已有的条件不能被OR绕过
+	query, vars = findSQL(db.Where("user_id = ?", 3), NewSqlCnd().Where("status = ?", 1).Or("status = ?", 2))
+	assert.Equal(t, "SELECT * FROM `cnd_model` WHERE user_id = ? AND (status = ? OR status = ?) AND `cnd_model`.`deleted_at` IS NULL", query)
+	assert.Equal(t, []interface{}{3, 1, 2}, vars)
+
+	query, _ = findSQL(db, NewSqlCnd().OnlyTrashed().Where("status = ?", 1).Or("status = ?", 2))
+	assert.Equal(t, "SELECT * FROM `cnd_model` WHERE `cnd_model`.`deleted_at` IS NOT NULL AND (status = ? OR status = ?)", query)
+}
+
+func TestSqlCndTrashed(t *testing.T) {
+	db := dryRunDB(t)
+
+	query, _ := findSQL(db, NewSqlCnd().WithTrashed().Where("status = ?", 1))
+	assert.Equal(t, "SELECT * FROM `cnd_model` WHERE status = ?", query)
+
+	query, _ = findSQL(db, NewSqlCnd().OnlyTrashed().
+		Join("JOIN article ON article.id = cnd_model.id").Where("article.status = ?", 1))
+	assert.Equal(t, "SELECT `cnd_model`.`id`,`cnd_model`.`status`,`cnd_model`.`user_id`,`cnd_model`.`deleted_at` FROM `cnd_model` "+
+		"JOIN article ON article.id = cnd_model.id WHERE `cnd_model`.`deleted_at` IS NOT NULL AND article.status = ?", query)
+
+	// 指定分表时以分表名限定字段
+	query, _ = findSQL(db.Table("cnd_model_01"), NewSqlCnd().OnlyTrashed())
+	assert.Equal(t, "SELECT * FROM `cnd_model_01` WHERE `cnd_model_01`.`deleted_at` IS NOT NULL", query)
+}
+
+func TestSqlCndBulk(t *testing.T) {
+	db := dryRunDB(t)
+
+	_, err := NewSqlCnd().Updates(db, &cndModel{}, map[string]interface{}{"status": 1})
+	assert.Equal(t, ErrEmptyCondition, err)
+	_, err = NewSqlCnd().Delete(db, &cndModel{})
+	assert.Equal(t, ErrEmptyCondition, err)
+	_, err = NewSqlCnd().Join("JOIN article ON article.id = cnd_model.id").Eq("article.status", 1).Delete(db, &cndModel{})
+	assert.Error(t, err)
+
+	stmt := NewSqlCnd().Where("status = ?", 1).Or("user_id = ?", 2).buildWhere(db.Model(&cndModel{})).Delete(&cndModel{}).Statement
+	assert.Equal(t, "UPDATE `cnd_model` SET `deleted_at`=? WHERE (status = ? OR user_id = ?) AND `cnd_model`.`deleted_at` IS NULL", stmt.SQL.String())
+
+	// 软删除不受WithTrashed影响，物理删除需要调用ForceDelete
+	var deleted string
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:capture", func(tx *gorm.DB) {
+		deleted = tx.Statement.SQL.String()
+	}))
+	_, err = NewSqlCnd().WithTrashed().Where("status = ?", 1).Delete(db, &cndModel{})
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE `cnd_model` SET `deleted_at`=? WHERE status = ? AND `cnd_model`.`deleted_at` IS NULL", deleted)
+	_, err = NewSqlCnd().Where("status = ?", 1).ForceDelete(db, &cndModel{})
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM `cnd_model` WHERE status = ?", deleted)
+	_, err = NewSqlCnd().OnlyTrashed().Where("status = ?", 1).ForceDelete(db, &cndModel{})
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM `cnd_model` WHERE `cnd_model`.`deleted_at` IS NOT NULL AND status = ?", deleted)
+}
+
+func TestFindCursorInvalid(t *testing.T) {
+	db := dryRunDB(t)
+
+	var list []cndModel
+	_, _, err := NewSqlCnd().Desc("id").After("not-a-cursor").FindCursor(db, &list)
+	assert.Equal(t, ErrInvalidCursor, err)
+
+	// 排序字段数量与游标不一致
+	cursor, err := EncodeCursor([]interface{}{1, 2})
+	require.NoError(t, err)
+	_, _, err = NewSqlCnd().Desc("id").After(cursor).FindCursor(db, &list)
+	assert.Equal(t, ErrInvalidCursor, err)
+}
@@ -0,0 +1,69 @@
+package simpleDb
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const cancelKey = "simpleDb:cancel"
+
+// timeoutPlugin 为每条sql设置超时时间，请求的context已有更早的截止时间或被取消时以context为准，
+// 查询需要传入请求的context才能在客户端断开时取消：db.WithContext(ctx)或dao的XxxCtx方法
+type timeoutPlugin struct {
+	timeout time.Duration
+}
+
+func (p timeoutPlugin) Name() string {
+	return "simpleDb:timeout"
+}
+
+func (p timeoutPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	// db.Rows()返回后调用方才读取数据，row不能在回调结束时取消，所以不设置超时
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, h := range hooks {
+		if err := h.before("simpleDb:timeout_before_"+h.operation, p.before); err != nil {
+			return err
+		}
+		if err := h.after("simpleDb:timeout_after_"+h.operation, p.after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p timeoutPlugin) before(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= p.timeout {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	db.Statement.Context = ctx
+	db.InstanceSet(cancelKey, cancel)
+}
+
+func (p timeoutPlugin) after(db *gorm.DB) {
+	if v, ok := db.InstanceGet(cancelKey); ok {
+		v.(context.CancelFunc)()
+	}
+}
+
+// WithContext 默认连接绑定context，eg: simpleDb.WithContext(c.Request.Context())
+func WithContext(ctx context.Context) *gorm.DB {
+	return DB().WithContext(ctx)
+}
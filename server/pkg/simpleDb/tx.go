@@ -0,0 +1,46 @@
+package simpleDb
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type afterCommitKey struct{}
+
+// Transaction 同db.Transaction，提交后按顺序执行事务中通过AfterCommit注册的函数，回滚时不执行，eg:
+//
+//	err := simpleDb.Transaction(db, func(tx *gorm.DB) error {
+//		return dao.ArticleDao.Updates(tx, id, columns) // 提交后才删除缓存
+//	})
+func Transaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	// 嵌套事务由最外层的事务在提交后执行
+	if InTx(db) {
+		return db.Transaction(fn)
+	}
+	hooks := &[]func(){}
+	ctx := context.WithValue(db.Statement.Context, afterCommitKey{}, hooks)
+	if err := db.WithContext(ctx).Transaction(fn); err != nil {
+		return err
+	}
+	for _, hook := range *hooks {
+		hook()
+	}
+	return nil
+}
+
+// AfterCommit 在事务提交后执行fn，eg: 删除缓存，避免其他请求在提交前读到旧数据后重新写入缓存。
+// 不在事务中或事务不是通过Transaction开启时立即执行
+func AfterCommit(db *gorm.DB, fn func()) {
+	if hooks, ok := db.Statement.Context.Value(afterCommitKey{}).(*[]func()); ok && InTx(db) {
+		*hooks = append(*hooks, fn)
+		return
+	}
+	fn()
+}
+
+// InTx db是否在事务中
+func InTx(db *gorm.DB) bool {
+	_, ok := db.Statement.ConnPool.(gorm.TxCommitter)
+	return ok
+}
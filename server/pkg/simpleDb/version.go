@@ -0,0 +1,63 @@
+package simpleDb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// 乐观锁使用的版本号字段
+const VersionColumn = "version"
+
+// ErrStaleObject 更新时数据已被其他请求修改，需要重新读取后再修改
+var ErrStaleObject = errors.New("simpleDb: stale object, row was modified concurrently")
+
+// UpdateWithVersion 按版本号更新整条记录(同db.Save)，成功后entity的版本号加1，
+// 数据已被修改时返回ErrStaleObject，entity必须是带有Version字段的结构体指针
+func UpdateWithVersion(db *gorm.DB, entity interface{}) error {
+	sch, err := schema.Parse(entity, schemaCache, db.NamingStrategy)
+	if err != nil {
+		return err
+	}
+	field := sch.LookUpField(VersionColumn)
+	if field == nil {
+		return fmt.Errorf("simpleDb: %s has no %s field", sch.Name, VersionColumn)
+	}
+	rv := reflect.ValueOf(entity)
+	value, _ := field.ValueOf(rv)
+	version := reflect.ValueOf(value).Int()
+	if err := field.Set(rv, version+1); err != nil {
+		return err
+	}
+
+	ret := db.Model(entity).Where(field.DBName+" = ?", version).
+		Select("*").Omit(sch.PrioritizedPrimaryField.Name, "CreatedAt", clause.Associations).
+		Updates(entity)
+	if ret.Error == nil && ret.RowsAffected == 0 {
+		ret.Error = ErrStaleObject
+	}
+	if ret.Error != nil {
+		_ = field.Set(rv, version)
+	}
+	return ret.Error
+}
+
+// UpdatesWithVersion 按版本号更新部分字段，同时版本号加1，数据已被修改时返回ErrStaleObject，eg:
+//	simpleDb.UpdatesWithVersion(db, &model.Article{}, id, form.Version, map[string]interface{}{"title": form.Title})
+func UpdatesWithVersion(db *gorm.DB, model interface{}, id interface{}, version int64, columns map[string]interface{}) error {
+	values := make(map[string]interface{}, len(columns)+1)
+	for k, v := range columns {
+		values[k] = v
+	}
+	values[VersionColumn] = gorm.Expr(VersionColumn+" + ?", 1)
+
+	ret := db.Model(model).Where("id = ? AND "+VersionColumn+" = ?", id, version).Updates(values)
+	if ret.Error == nil && ret.RowsAffected == 0 {
+		return ErrStaleObject
+	}
+	return ret.Error
+}
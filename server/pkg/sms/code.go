@@ -0,0 +1,146 @@
+package sms
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/utils"
+	"math/big"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 验证码场景，对应配置中的模板
+const (
+	SceneLogin         = "login"
+	SceneRegister      = "register"
+	SceneResetPassword = "reset_password"
+)
+
+// SendCode 生成验证码并发送，同一手机号有发送间隔和每天的次数限制
+func SendCode(ctx context.Context, scene, phone string) error {
+	cfg := config.Conf.SmsConfig
+	template, ok := cfg.Templates[scene]
+	if !ok {
+		return errors.NewError(errors.InvalidParamsError.Code, "不支持的验证码场景")
+	}
+	rdb := gredis.GetRedis()
+
+	interval := cfg.SendInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ok, err := rdb.SetNX(ctx, intervalKey(phone), 1, interval).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.SmsTooFrequentError
+	}
+	if cfg.DailyLimit > 0 {
+		count, err := gredis.IncrExpire(ctx, dailyKey(phone), 1, 24*time.Hour)
+		if err != nil {
+			return err
+		}
+		if count > cfg.DailyLimit {
+			return errors.SmsTooFrequentError
+		}
+	}
+
+	code, err := randomCode(cfg.CodeLength)
+	if err != nil {
+		return err
+	}
+	ttl := codeTTL()
+	_, err = rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, codeKey(scene, phone), code, ttl)
+		pipe.Del(ctx, attemptsKey(scene, phone))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	msg := &Message{Phone: phone, Template: template, Params: []Param{{Key: "code", Value: code}}}
+	if err := Send(ctx, msg); err != nil {
+		// 发送失败时允许立即重新发送
+		rdb.Del(ctx, codeKey(scene, phone), intervalKey(phone))
+		return err
+	}
+	return nil
+}
+
+// VerifyCode 校验验证码，校验成功后验证码失效，错误次数超过MaxAttempts后验证码失效
+func VerifyCode(ctx context.Context, scene, phone, code string) error {
+	if code == "" {
+		return errors.SmsCodeError
+	}
+	rdb := gredis.GetRedis()
+	stored, err := rdb.Get(ctx, codeKey(scene, phone)).Result()
+	if err == redis.Nil {
+		return errors.SmsCodeError
+	}
+	if err != nil {
+		return err
+	}
+
+	maxAttempts := config.Conf.SmsConfig.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	attempts, err := gredis.IncrExpire(ctx, attemptsKey(scene, phone), 1, codeTTL())
+	if err != nil {
+		return err
+	}
+	if attempts > maxAttempts {
+		rdb.Del(ctx, codeKey(scene, phone), attemptsKey(scene, phone))
+		return errors.SmsCodeError
+	}
+	if !utils.SecureCompare(stored, code) {
+		return errors.SmsCodeError
+	}
+	rdb.Del(ctx, codeKey(scene, phone), attemptsKey(scene, phone))
+	return nil
+}
+
+func codeTTL() time.Duration {
+	if ttl := config.Conf.SmsConfig.CodeTTL; ttl > 0 {
+		return ttl
+	}
+	return 5 * time.Minute
+}
+
+func randomCode(length int) (string, error) {
+	if length <= 0 {
+		length = 6
+	}
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + n.Int64())
+	}
+	return string(code), nil
+}
+
+func codeKey(scene, phone string) string {
+	return fmt.Sprintf("sms:code:%s:%s", scene, phone)
+}
+
+func attemptsKey(scene, phone string) string {
+	return fmt.Sprintf("sms:attempts:%s:%s", scene, phone)
+}
+
+func intervalKey(phone string) string {
+	return "sms:interval:" + phone
+}
+
+func dailyKey(phone string) string {
+	return "sms:daily:" + phone + ":" + time.Now().Format("20060102")
+}
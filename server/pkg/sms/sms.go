@@ -0,0 +1,89 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Param 模板参数，阿里云按Key填充，腾讯云按顺序填充
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Message 短信
+type Message struct {
+	Phone    string
+	Template string
+	Params   []Param
+}
+
+// Sender 短信服务商
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Driver 根据配置创建Sender
+type Driver func(cfg config.SmsConfig) (Sender, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]Driver{
+		"aliyun":  newAliyunSender,
+		"tencent": newTencentSender,
+		"log":     newLogSender,
+	}
+
+	once      sync.Once
+	sender    Sender
+	senderErr error
+)
+
+// RegisterDriver 注册短信服务商，需要在第一次发送前注册
+func RegisterDriver(name string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers[name] = driver
+}
+
+// GetSender 按配置的Driver创建的Sender
+func GetSender() (Sender, error) {
+	once.Do(func() {
+		cfg := config.Conf.SmsConfig
+		driversMu.RLock()
+		driver, ok := drivers[cfg.Driver]
+		driversMu.RUnlock()
+		if !ok {
+			senderErr = fmt.Errorf("sms: unknown driver %q", cfg.Driver)
+			return
+		}
+		sender, senderErr = driver(cfg)
+	})
+	return sender, senderErr
+}
+
+// Send 发送短信
+func Send(ctx context.Context, msg *Message) error {
+	s, err := GetSender()
+	if err != nil {
+		return err
+	}
+	return s.Send(ctx, msg)
+}
+
+// logSender 只打印日志，用于开发环境
+type logSender struct {
+}
+
+func newLogSender(cfg config.SmsConfig) (Sender, error) {
+	return &logSender{}, nil
+}
+
+func (s *logSender) Send(ctx context.Context, msg *Message) error {
+	zap.L().Info("sms", zap.String("phone", msg.Phone), zap.String("template", msg.Template), zap.Any("params", msg.Params))
+	return nil
+}
@@ -0,0 +1,120 @@
+package sms
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/utils"
+	"go-skeleton/utils/httpclient"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const tencentSmsHost = "sms.tencentcloudapi.com"
+
+// TencentSender 腾讯云短信，使用TC3-HMAC-SHA256签名调用SendSms
+type TencentSender struct {
+	cfg    config.SmsConfig
+	client *httpclient.Client
+}
+
+func newTencentSender(cfg config.SmsConfig) (Sender, error) {
+	if cfg.Tencent.SecretId == "" || cfg.Tencent.SecretKey == "" {
+		return nil, errors.New("sms: tencent secret is empty")
+	}
+	return &TencentSender{cfg: cfg, client: httpclient.New()}, nil
+}
+
+func (s *TencentSender) Send(ctx context.Context, msg *Message) error {
+	values := make([]string, 0, len(msg.Params))
+	for _, p := range msg.Params {
+		values = append(values, p.Value)
+	}
+	phone := msg.Phone
+	if !strings.HasPrefix(phone, "+") {
+		phone = "+86" + phone
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"PhoneNumberSet":   []string{phone},
+		"SmsSdkAppId":      s.cfg.Tencent.SdkAppId,
+		"SignName":         s.cfg.SignName,
+		"TemplateId":       msg.Template,
+		"TemplateParamSet": values,
+	})
+	if err != nil {
+		return err
+	}
+	region := s.cfg.Tencent.Region
+	if region == "" {
+		region = "ap-guangzhou"
+	}
+
+	now := time.Now().Unix()
+	header := http.Header{}
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	header.Set("Host", tencentSmsHost)
+	header.Set("X-TC-Action", "SendSms")
+	header.Set("X-TC-Version", "2021-01-11")
+	header.Set("X-TC-Region", region)
+	header.Set("X-TC-Timestamp", strconv.FormatInt(now, 10))
+	header.Set("Authorization", tencentAuthorization(s.cfg.Tencent.SecretId, s.cfg.Tencent.SecretKey, now, payload))
+
+	resp, err := s.client.Do(ctx, &httpclient.Request{
+		Method:  http.MethodPost,
+		URL:     "https://" + tencentSmsHost,
+		Header:  header,
+		Body:    payload,
+		NoRetry: true,
+	})
+	if err != nil {
+		return err
+	}
+	var ret struct {
+		Response struct {
+			Error *struct {
+				Code    string
+				Message string
+			}
+			SendStatusSet []struct {
+				Code    string
+				Message string
+			}
+		}
+	}
+	if err := resp.JSON(&ret); err != nil {
+		return err
+	}
+	if e := ret.Response.Error; e != nil {
+		return fmt.Errorf("sms: tencent %s: %s", e.Code, e.Message)
+	}
+	for _, status := range ret.Response.SendStatusSet {
+		if status.Code != "Ok" {
+			return fmt.Errorf("sms: tencent %s: %s", status.Code, status.Message)
+		}
+	}
+	return nil
+}
+
+// tencentAuthorization 见https://cloud.tencent.com/document/api/382/52072
+func tencentAuthorization(secretId, secretKey string, timestamp int64, payload []byte) string {
+	date := time.Unix(timestamp, 0).UTC().Format("2006-01-02")
+	scope := date + "/sms/tc3_request"
+	canonicalRequest := "POST\n/\n\ncontent-type:application/json; charset=utf-8\nhost:" + tencentSmsHost +
+		"\n\ncontent-type;host\n" + utils.SHA256Hex(payload)
+	stringToSign := "TC3-HMAC-SHA256\n" + strconv.FormatInt(timestamp, 10) + "\n" + scope + "\n" + utils.SHA256Hex([]byte(canonicalRequest))
+
+	secretDate := hmacSha256([]byte("TC3"+secretKey), date)
+	secretService := hmacSha256(secretDate, "sms")
+	secretSigning := hmacSha256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSha256(secretSigning, stringToSign))
+	return "TC3-HMAC-SHA256 Credential=" + secretId + "/" + scope + ", SignedHeaders=content-type;host, Signature=" + signature
+}
+
+func hmacSha256(key []byte, s string) []byte {
+	return utils.HMACBytes(utils.AlgoSha256, []byte(s), key)
+}
@@ -0,0 +1,143 @@
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Server-Sent Events，eg: 导出进度
+//	events := make(chan sse.Event)
+//	go func() {
+//		defer close(events)
+//		for i := 0; i <= 100; i += 10 {
+//			events <- sse.Event{Event: "progress", Data: gin.H{"percent": i}}
+//		}
+//	}()
+//	sse.Stream(c, events)
+
+// 默认的保活间隔，小于大部分代理的空闲超时
+const defaultKeepAlive = 15 * time.Second
+
+// Event 事件，Data不是string或[]byte时编码为json
+type Event struct {
+	ID    string
+	Event string
+	Data  interface{}
+	// 断开后客户端重连的等待时间
+	Retry time.Duration
+}
+
+// Option Stream的选项
+type Option func(o *options)
+
+type options struct {
+	keepAlive time.Duration
+}
+
+// WithKeepAlive 保活注释的发送间隔，0为不发送
+func WithKeepAlive(d time.Duration) Option {
+	return func(o *options) {
+		o.keepAlive = d
+	}
+}
+
+// LastEventID 客户端重连时携带的最后一个事件id，用于从断开的位置继续推送
+func LastEventID(c *gin.Context) string {
+	if id := c.GetHeader("Last-Event-ID"); id != "" {
+		return id
+	}
+	// EventSource不支持自定义请求头，首次连接时允许通过参数指定
+	return c.Query("lastEventId")
+}
+
+// Stream 推送events中的事件，直到events关闭或客户端断开，返回时客户端是否仍然连接
+func Stream(c *gin.Context, events <-chan Event, opts ...Option) bool {
+	o := &options{keepAlive: defaultKeepAlive}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	h := c.Writer.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	// 关闭nginx缓冲
+	h.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	var keepAlive <-chan time.Time
+	if o.keepAlive > 0 {
+		ticker := time.NewTicker(o.keepAlive)
+		defer ticker.Stop()
+		keepAlive = ticker.C
+	}
+	done := c.Request.Context().Done()
+	for {
+		select {
+		case <-done:
+			return false
+		case <-keepAlive:
+			if _, err := io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+				return false
+			}
+			c.Writer.Flush()
+		case e, ok := <-events:
+			if !ok {
+				return true
+			}
+			if err := Write(c.Writer, &e); err != nil {
+				return false
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// Write 按SSE格式写入一个事件，多行数据拆分为多个data字段
+func Write(w io.Writer, e *Event) error {
+	var b strings.Builder
+	if e.ID != "" {
+		b.WriteString("id: " + oneLine(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + oneLine(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString(fmt.Sprintf("retry: %d\n", e.Retry.Milliseconds()))
+	}
+	data, err := encode(e.Data)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(data, "\n") {
+		b.WriteString("data: " + strings.TrimSuffix(line, "\r") + "\n")
+	}
+	b.WriteString("\n")
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func encode(data interface{}) (string, error) {
+	switch v := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+}
+
+func oneLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
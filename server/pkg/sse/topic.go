@@ -0,0 +1,97 @@
+package sse
+
+import (
+	"strconv"
+	"sync"
+)
+
+// Topic 保存最近的事件，客户端携带Last-Event-ID重连时补发断开期间的事件，eg:
+//	events, cancel := topic.Subscribe(sse.LastEventID(c))
+//	defer cancel()
+//	sse.Stream(c, events)
+type Topic struct {
+	mu      sync.Mutex
+	seq     uint64
+	history []Event
+	size    int
+	subs    map[chan Event]struct{}
+	closed  bool
+}
+
+// NewTopic 创建Topic，history为保留的事件数
+func NewTopic(history int) *Topic {
+	return &Topic{size: history, subs: map[chan Event]struct{}{}}
+}
+
+// Publish 发布事件，id为自增序号，订阅者处理不过来时丢弃该订阅者的事件
+func (t *Topic) Publish(e Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.seq++
+	e.ID = strconv.FormatUint(t.seq, 10)
+	if t.size > 0 {
+		if len(t.history) >= t.size {
+			t.history = t.history[1:]
+		}
+		t.history = append(t.history, e)
+	}
+	for ch := range t.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe 订阅事件，lastEventID不为空时先补发之后的历史事件，调用cancel取消订阅
+func (t *Topic) Subscribe(lastEventID string) (<-chan Event, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var replay []Event
+	if last, err := strconv.ParseUint(lastEventID, 10, 64); err == nil {
+		for _, e := range t.history {
+			if id, _ := strconv.ParseUint(e.ID, 10, 64); id > last {
+				replay = append(replay, e)
+			}
+		}
+	}
+	ch := make(chan Event, len(replay)+64)
+	for _, e := range replay {
+		ch <- e
+	}
+	if t.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	t.subs[ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if _, ok := t.subs[ch]; ok {
+				delete(t.subs, ch)
+				close(ch)
+			}
+		})
+	}
+}
+
+// Close 关闭所有订阅，Stream在推送完剩余事件后返回
+func (t *Topic) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	for ch := range t.subs {
+		close(ch)
+	}
+	t.subs = map[chan Event]struct{}{}
+}
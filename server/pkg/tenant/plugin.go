@@ -0,0 +1,110 @@
+package tenant
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Plugin 为带tenant_id列的模型加上租户条件。
+// Strict为true时context中没有租户的语句返回ErrMissingTenant，避免遗漏WithContext导致读写所有租户的数据
+type Plugin struct {
+	Strict bool
+}
+
+func (p Plugin) Name() string {
+	return "tenant"
+}
+
+func (p Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("tenant:create", p.assign); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("tenant:query", p.scope); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenant:update", p.scope); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tenant:delete", p.scope); err != nil {
+		return err
+	}
+	return cb.Row().Before("gorm:row").Register("tenant:row", p.scope)
+}
+
+// target 语句对应模型的租户列和当前租户，不需要处理时ok为false
+func (p Plugin) target(db *gorm.DB) (field *schema.Field, id int64, ok bool) {
+	sch := db.Statement.Schema
+	if db.Error != nil || sch == nil || db.Statement.SQL.Len() > 0 {
+		return nil, 0, false
+	}
+	if field = sch.LookUpField(Column); field == nil {
+		return nil, 0, false
+	}
+	ctx := db.Statement.Context
+	if Skipped(ctx) {
+		return nil, 0, false
+	}
+	if id, ok = FromContext(ctx); !ok && p.Strict {
+		_ = db.AddError(ErrMissingTenant)
+	}
+	return field, id, ok
+}
+
+// scope 查询、修改、删除时加上租户条件
+func (p Plugin) scope(db *gorm.DB) {
+	field, id, ok := p.target(db)
+	if !ok {
+		return
+	}
+	stmt := db.Statement
+	// 已有条件中包含Or时先用括号包起来，否则租户条件只会和最后一个Or条件结合，同gorm的软删除
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			for _, expr := range where.Exprs {
+				if _, ok := expr.(clause.OrConditions); ok {
+					where.Exprs = []clause.Expression{clause.AndConditions{Exprs: where.Exprs}}
+					c.Expression = where
+					stmt.Clauses["WHERE"] = c
+					break
+				}
+			}
+		}
+	}
+	stmt.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: id},
+	}})
+}
+
+// assign 新增时写入租户，已设置为其他租户时返回ErrCrossTenant
+func (p Plugin) assign(db *gorm.DB) {
+	field, id, ok := p.target(db)
+	if !ok {
+		return
+	}
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			p.set(db, field, reflect.Indirect(rv.Index(i)), id)
+		}
+	case reflect.Struct:
+		p.set(db, field, rv, id)
+	}
+}
+
+func (p Plugin) set(db *gorm.DB, field *schema.Field, rv reflect.Value, id int64) {
+	value, zero := field.ValueOf(rv)
+	if !zero {
+		if reflect.ValueOf(value).Convert(reflect.TypeOf(id)).Int() != id {
+			_ = db.AddError(ErrCrossTenant)
+		}
+		return
+	}
+	if err := field.Set(rv, id); err != nil {
+		_ = db.AddError(err)
+	}
+}
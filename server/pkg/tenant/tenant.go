@@ -0,0 +1,98 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+)
+
+// 多租户：中间件从子域名或请求头解析出租户写入请求的context，gorm插件为带tenant_id列的模型
+// 自动加上租户条件，新增时写入tenant_id，eg:
+//
+//	simpleDb.DB().Use(tenant.Plugin{Strict: true})
+//	dao.ArticleDao.Find(simpleDb.WithContext(c.Request.Context()), cnd) // WHERE ... AND article.tenant_id = 1
+//
+// 租户从语句的context中读取，需要db.WithContext(ctx)；定时任务等需要访问所有租户时使用SkipContext。
+// 通过db.Raw、db.Exec执行的sql不会处理
+
+// Column 租户列名
+const Column = "tenant_id"
+
+// IDKey 租户id在gin.Context中的key
+const IDKey = "tenant_id"
+
+var (
+	// ErrMissingTenant 严格模式下访问租户数据时context中没有租户
+	ErrMissingTenant = errors.New("tenant: missing tenant in context")
+	// ErrCrossTenant 新增的数据属于其他租户
+	ErrCrossTenant = errors.New("tenant: cross-tenant access")
+)
+
+type (
+	ctxKey  struct{}
+	skipKey struct{}
+)
+
+// WithTenant 将租户id写入context
+func WithTenant(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext 读取context中的租户id，支持gin.Context和request.Context()
+func FromContext(ctx context.Context) (int64, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	if id, ok := ctx.Value(ctxKey{}).(int64); ok && id > 0 {
+		return id, true
+	}
+	if id, ok := ctx.Value(IDKey).(int64); ok && id > 0 {
+		return id, true
+	}
+	return 0, false
+}
+
+// SkipContext 不按租户过滤，用于定时任务、后台统计等需要访问所有租户数据的场景
+func SkipContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipKey{}, true)
+}
+
+// Skipped context是否不按租户过滤
+func Skipped(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	skip, _ := ctx.Value(skipKey{}).(bool)
+	return skip
+}
+
+// Allowed 数据是否可以被ctx中的租户访问，用于缓存等绕过数据库查询的场景。
+// 不按租户过滤时返回true，ctx中没有租户时返回false
+func Allowed(ctx context.Context, tenantId int64) bool {
+	if Skipped(ctx) {
+		return true
+	}
+	id, ok := FromContext(ctx)
+	return ok && id == tenantId
+}
+
+// Subdomain 主域名前的一级子域名，eg: acme.example.com => acme，www和主域名本身返回空字符串
+func Subdomain(host, domain string) string {
+	if domain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	suffix := "." + strings.ToLower(strings.TrimPrefix(domain, "."))
+	if !strings.HasSuffix(host, suffix) {
+		return ""
+	}
+	sub := strings.TrimSuffix(host, suffix)
+	if sub == "www" || strings.Contains(sub, ".") {
+		return ""
+	}
+	return sub
+}
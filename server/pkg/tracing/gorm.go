@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// GormPlugin 为每条sql创建span，记录语句和影响行数，
+// 需要使用db.WithContext(ctx)传入请求的context才能关联到请求的链路
+type GormPlugin struct{}
+
+func (p GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		name   string
+		before func(name string, fn func(*gorm.DB)) error
+		after  func(name string, fn func(*gorm.DB)) error
+	}{
+		{"gorm.create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"gorm.query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"gorm.update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"gorm.delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"gorm.row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"gorm.raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, h := range hooks {
+		if err := h.before("tracing:before_"+h.name, before(h.name)); err != nil {
+			return err
+		}
+		if err := h.after("tracing:after_"+h.name, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func before(name string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			return
+		}
+		db.Statement.Context, _ = Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	}
+}
+
+func after(db *gorm.DB) {
+	if db.Statement.Context == nil {
+		return
+	}
+	span := trace.SpanFromContext(db.Statement.Context)
+	if !span.IsRecording() {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBSystemMySQL,
+		semconv.DBStatementKey.String(db.Statement.SQL.String()),
+		attribute.String("db.sql.table", db.Statement.Table),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
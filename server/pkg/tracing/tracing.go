@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go-skeleton/pkg/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/exporters/trace/jaeger"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 本项目创建span使用的tracer名称
+const instrumentationName = "go-skeleton"
+
+var provider *sdktrace.TracerProvider
+
+// InitTracing 根据trace配置初始化otel，未开启时使用otel默认的noop实现，不会产生span
+func InitTracing() error {
+	cfg := config.Conf.TraceConfig
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+	if !cfg.Enable {
+		return nil
+	}
+
+	exporter, err := newExporter(cfg)
+	if err != nil {
+		return err
+	}
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "go-skeleton"
+	}
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.ServiceNameKey.String(serviceName),
+			semconv.DeploymentEnvironmentKey.String(config.Env),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	return nil
+}
+
+func newExporter(cfg config.TraceConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "", "otlp":
+		driver := otlpgrpc.NewDriver(
+			otlpgrpc.WithInsecure(),
+			otlpgrpc.WithEndpoint(cfg.Endpoint),
+		)
+		return otlp.NewExporter(context.Background(), driver)
+	case "jaeger":
+		return jaeger.NewRawExporter(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(cfg.Endpoint)))
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter: %s", cfg.Exporter)
+	}
+}
+
+// Shutdown 上报剩余的span并关闭
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	return provider.Shutdown(ctx)
+}
+
+// Tracer 获取tracer，eg:
+//
+//	ctx, span := tracing.Tracer().Start(ctx, "article.publish")
+//	defer span.End()
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
@@ -0,0 +1,175 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// 分片上传id由客户端生成，eg: 文件的md5，只允许字母数字避免路径穿越
+var uploadIDRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// MaxChunks 一个文件最多的分片数
+const MaxChunks = 10000
+
+// chunkDir 分片的保存目录，按用户区分，避免其他用户覆盖或合并相同上传id的分片
+func chunkDir(userId int64, uploadID string) (string, error) {
+	if !uploadIDRegexp.MatchString(uploadID) {
+		return "", errors.InvalidParamsError
+	}
+	dir := config.Conf.UploadConfig.ChunkDir
+	if dir == "" {
+		dir = "runtime/chunks/"
+	}
+	return filepath.Join(dir, strconv.FormatInt(userId, 10), uploadID), nil
+}
+
+// SaveChunk 保存第index(从0开始)个分片，重复上传会覆盖。
+// 分片数不能超过MaxChunks，已上传的分片加上本次分片不能超过ChunkMaxSize
+func SaveChunk(userId int64, uploadID string, index int, fh *multipart.FileHeader) error {
+	if index < 0 || index >= MaxChunks {
+		return errors.InvalidParamsError
+	}
+	dir, err := chunkDir(userId, uploadID)
+	if err != nil {
+		return err
+	}
+	if maxSize := int64(config.Conf.UploadConfig.ChunkMaxSize) << 20; maxSize > 0 {
+		uploaded, err := chunksSize(dir, strconv.Itoa(index))
+		if err != nil {
+			return err
+		}
+		if uploaded+fh.Size > maxSize {
+			return errors.UploadSizeError
+		}
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	// 先写临时文件再改名，避免合并时读到未写完的分片
+	dst := filepath.Join(dir, strconv.Itoa(index))
+	tmp, err := ioutil.TempFile(dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, src); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// chunksSize 目录中已上传分片的总大小，不包括exclude(重复上传时被覆盖的分片)
+func chunksSize(dir, exclude string) (int64, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	for _, f := range files {
+		if f.Name() != exclude {
+			size += f.Size()
+		}
+	}
+	return size, nil
+}
+
+// UploadedChunks 已上传的分片序号，用于断点续传
+func UploadedChunks(userId int64, uploadID string, total int) ([]int, error) {
+	if total > MaxChunks {
+		return nil, errors.InvalidParamsError
+	}
+	dir, err := chunkDir(userId, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]int, 0, total)
+	for i := 0; i < total; i++ {
+		if _, err := os.Stat(filepath.Join(dir, strconv.Itoa(i))); err == nil {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes, nil
+}
+
+// MergeChunks 按顺序合并total个分片并保存，成功后删除分片
+func MergeChunks(ctx context.Context, userId int64, uploadID, name string, total int, dir string) (*File, error) {
+	if total <= 0 || total > MaxChunks {
+		return nil, errors.InvalidParamsError
+	}
+	chunks, err := chunkDir(userId, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize := int64(config.Conf.UploadConfig.ChunkMaxSize) << 20
+	var size int64
+	for i := 0; i < total; i++ {
+		info, err := os.Stat(filepath.Join(chunks, strconv.Itoa(i)))
+		if err != nil {
+			return nil, errors.UploadChunkError
+		}
+		size += info.Size()
+	}
+	if maxSize > 0 && size > maxSize {
+		return nil, errors.UploadSizeError
+	}
+
+	merged, err := ioutil.TempFile(chunks, "merged-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = merged.Close()
+		_ = os.Remove(merged.Name())
+	}()
+	for i := 0; i < total; i++ {
+		if err := appendChunk(merged, filepath.Join(chunks, strconv.Itoa(i))); err != nil {
+			return nil, fmt.Errorf("merge chunk %d: %w", i, err)
+		}
+	}
+	if _, err := merged.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	ret, err := store(ctx, merged, name, size, dir)
+	if err != nil {
+		return nil, err
+	}
+	_ = merged.Close()
+	_ = os.RemoveAll(chunks)
+	return ret, nil
+}
+
+func appendChunk(dst io.Writer, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(dst, f)
+	return err
+}
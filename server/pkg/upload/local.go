@@ -0,0 +1,58 @@
+package upload
+
+import (
+	"context"
+	"go-skeleton/pkg/config"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage 保存到本地磁盘
+type LocalStorage struct {
+	root    string
+	baseURL string
+}
+
+func NewLocalStorage(cfg config.LocalStorageConfig) *LocalStorage {
+	root := cfg.Root
+	if root == "" {
+		root = "runtime/upload/files/"
+	}
+	return &LocalStorage{root: root, baseURL: strings.TrimRight(cfg.BaseURL, "/")}
+}
+
+// Root 本地保存的根目录
+func (s *LocalStorage) Root() string {
+	return s.root
+}
+
+func (s *LocalStorage) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	dst := filepath.Join(s.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		_ = f.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	return f.Close()
+}
+
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	err := os.Remove(filepath.Join(s.root, filepath.FromSlash(key)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
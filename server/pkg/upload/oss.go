@@ -0,0 +1,45 @@
+package upload
+
+import (
+	"context"
+	"go-skeleton/pkg/config"
+	"io"
+	"strings"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// OssStorage 阿里云oss
+type OssStorage struct {
+	bucket  *oss.Bucket
+	baseURL string
+}
+
+func NewOssStorage(cfg config.OssStorageConfig) (*OssStorage, error) {
+	client, err := oss.New(cfg.Endpoint, cfg.AccessKeyId, cfg.AccessKeySecret)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := client.Bucket(cfg.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		endpoint := strings.TrimPrefix(strings.TrimPrefix(cfg.Endpoint, "https://"), "http://")
+		baseURL = "https://" + cfg.Bucket + "." + endpoint
+	}
+	return &OssStorage{bucket: bucket, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+func (s *OssStorage) Put(_ context.Context, key string, r io.Reader, _ int64, contentType string) error {
+	return s.bucket.PutObject(key, r, oss.ContentType(contentType))
+}
+
+func (s *OssStorage) Delete(_ context.Context, key string) error {
+	return s.bucket.DeleteObject(key)
+}
+
+func (s *OssStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
@@ -0,0 +1,72 @@
+package upload
+
+import (
+	"context"
+	"go-skeleton/pkg/config"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// S3Storage aws s3及兼容s3协议的存储，eg: minio
+type S3Storage struct {
+	client   *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+	baseURL  string
+}
+
+func NewS3Storage(cfg config.S3StorageConfig) (*S3Storage, error) {
+	awsCfg := aws.NewConfig().
+		WithRegion(cfg.Region).
+		WithCredentials(credentials.NewStaticCredentials(cfg.AccessKey, cfg.SecretKey, ""))
+	if cfg.Endpoint != "" {
+		awsCfg = awsCfg.WithEndpoint(cfg.Endpoint).WithS3ForcePathStyle(true)
+	}
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		if cfg.Endpoint != "" {
+			baseURL = strings.TrimRight(cfg.Endpoint, "/") + "/" + cfg.Bucket
+		} else {
+			baseURL = "https://" + cfg.Bucket + ".s3." + cfg.Region + ".amazonaws.com"
+		}
+	}
+	return &S3Storage{
+		client:   s3.New(sess),
+		uploader: s3manager.NewUploader(sess),
+		bucket:   cfg.Bucket,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+	}, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, _ int64, contentType string) error {
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        r,
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (s *S3Storage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
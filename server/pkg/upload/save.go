@@ -0,0 +1,208 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/utils"
+	"image"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	// 注册图片格式，用于读取图片尺寸
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// File 上传后的文件信息
+type File struct {
+	Key      string `json:"key"`
+	URL      string `json:"url"`
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	// 图片的宽高，非图片为0
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+}
+
+// Save 校验并保存上传的文件，dir为保存的目录，eg: images
+func Save(ctx context.Context, fh *multipart.FileHeader, dir string) (*File, error) {
+	maxSize := int64(config.Conf.UploadConfig.MaxSize) << 20
+	if maxSize > 0 && fh.Size > maxSize {
+		return nil, errors.UploadSizeError
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return store(ctx, f, fh.Filename, fh.Size, dir)
+}
+
+// SaveImage 校验图片并经过pipeline处理后保存，pipeline为nil时只去掉EXIF信息，eg:
+//
+//	upload.SaveImage(ctx, fh, "avatars", utils.NewImagePipeline().Thumbnail(200, 200))
+//
+// gif等不支持处理的格式原样保存
+func SaveImage(ctx context.Context, fh *multipart.FileHeader, dir string, pipeline *utils.ImagePipeline) (*File, error) {
+	maxSize := int64(config.Conf.UploadConfig.MaxSize) << 20
+	if maxSize > 0 && fh.Size > maxSize {
+		return nil, errors.UploadSizeError
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	mimeType := http.DetectContentType(head[:n])
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, errors.UploadTypeError
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if mimeType != "image/jpeg" && mimeType != "image/png" {
+		return store(ctx, f, fh.Filename, fh.Size, dir)
+	}
+
+	// 解码前先读取尺寸，避免解码尺寸很大但文件很小的图片占用大量内存
+	img, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return nil, errors.UploadTypeError
+	}
+	if imageTooLarge(img.Width, img.Height) {
+		return nil, errors.UploadImageSizeError
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if pipeline == nil {
+		pipeline = utils.NewImagePipeline().Quality(95)
+	}
+	ext, _ := Extension(mimeType)
+	var buf bytes.Buffer
+	if err := pipeline.Process(f, &buf, "image"+ext); err != nil {
+		return nil, errors.UploadTypeError
+	}
+	return store(ctx, bytes.NewReader(buf.Bytes()), fh.Filename, int64(buf.Len()), dir)
+}
+
+// store 识别文件类型、校验图片尺寸后保存到存储，不在mimeExtensions中的类型不允许保存
+func store(ctx context.Context, r io.ReadSeeker, name string, size int64, dir string) (*File, error) {
+	cfg := config.Conf.UploadConfig
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	mimeType := http.DetectContentType(head[:n])
+	if !AllowType(cfg.AllowTypes, mimeType) {
+		return nil, errors.UploadTypeError
+	}
+
+	ext, ok := Extension(mimeType)
+	if !ok {
+		return nil, errors.UploadTypeError
+	}
+
+	ret := &File{Name: name, Size: size, MimeType: mimeType}
+	if strings.HasPrefix(mimeType, "image/") {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if img, _, err := image.DecodeConfig(r); err == nil {
+			ret.Width, ret.Height = img.Width, img.Height
+			if imageTooLarge(img.Width, img.Height) {
+				return nil, errors.UploadImageSizeError
+			}
+		}
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	s, err := GetStorage()
+	if err != nil {
+		return nil, err
+	}
+	ret.Key = GenerateKey(dir, ext)
+	if err := s.Put(ctx, ret.Key, r, size, mimeType); err != nil {
+		return nil, err
+	}
+	ret.URL = s.URL(ret.Key)
+	return ret, nil
+}
+
+// 图片的最大像素数，未配置宽高限制时也不处理超过该尺寸的图片
+const maxImagePixels = 8000 * 8000
+
+// imageTooLarge 图片尺寸是否超过配置的最大宽高或maxImagePixels
+func imageTooLarge(width, height int) bool {
+	cfg := config.Conf.UploadConfig
+	return cfg.MaxWidth > 0 && width > cfg.MaxWidth || cfg.MaxHeight > 0 && height > cfg.MaxHeight ||
+		int64(width)*int64(height) > maxImagePixels
+}
+
+// 可以保存的MIME类型及对应的扩展名，扩展名按识别出的类型确定，不使用客户端的文件名，
+// 避免上传.html、.svg等文件后在站点域名下执行脚本
+var mimeExtensions = map[string]string{
+	"image/jpeg":         ".jpg",
+	"image/png":          ".png",
+	"image/gif":          ".gif",
+	"image/webp":         ".webp",
+	"image/bmp":          ".bmp",
+	"image/x-icon":       ".ico",
+	"application/pdf":    ".pdf",
+	"application/zip":    ".zip",
+	"application/x-gzip": ".gz",
+	"text/plain":         ".txt",
+	"video/mp4":          ".mp4",
+	"video/webm":         ".webm",
+	"audio/mpeg":         ".mp3",
+	"audio/wave":         ".wav",
+	"audio/ogg":          ".ogg",
+}
+
+// Extension MIME类型对应的扩展名，不在允许保存的类型中时ok为false
+func Extension(mimeType string) (ext string, ok bool) {
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	ext, ok = mimeExtensions[strings.TrimSpace(mimeType)]
+	return
+}
+
+// GenerateKey 生成保存的路径，ext为Extension返回的扩展名，eg: images/2021/06/01/{uuid}.png
+func GenerateKey(dir, ext string) string {
+	return path.Join(strings.Trim(dir, "/"), time.Now().Format("2006/01/02"), utils.UUID()+ext)
+}
+
+// AllowType MIME类型是否在允许列表中，支持image/*这样的通配，列表为空时全部允许
+func AllowType(allows []string, mimeType string) bool {
+	if len(allows) == 0 {
+		return true
+	}
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	for _, allow := range allows {
+		if allow == mimeType || strings.HasSuffix(allow, "/*") && strings.HasPrefix(mimeType, strings.TrimSuffix(allow, "*")) {
+			return true
+		}
+	}
+	return false
+}
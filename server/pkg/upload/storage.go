@@ -0,0 +1,44 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"io"
+	"sync"
+)
+
+// Storage 文件存储，key为相对路径，eg: images/2021/06/01/xxx.png
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// URL 文件的访问地址
+	URL(key string) string
+}
+
+var (
+	storageOnce sync.Once
+	storage     Storage
+	storageErr  error
+)
+
+// GetStorage 根据upload.Driver配置获取存储
+func GetStorage() (Storage, error) {
+	storageOnce.Do(func() {
+		storage, storageErr = NewStorage(config.Conf.UploadConfig)
+	})
+	return storage, storageErr
+}
+
+func NewStorage(cfg config.UploadConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStorage(cfg.Local), nil
+	case "oss":
+		return NewOssStorage(cfg.Oss)
+	case "s3":
+		return NewS3Storage(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unsupported upload driver: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,211 @@
+package wechat
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-skeleton/utils"
+	"sort"
+	"strings"
+)
+
+// 微信生态的加解密：小程序开放数据、支付APIv3回调资源、公众号消息
+
+var (
+	// ErrInvalidSignature 签名校验失败
+	ErrInvalidSignature = errors.New("wechat: invalid signature")
+	// ErrAppIdMismatch 解密后的appid与当前应用不一致
+	ErrAppIdMismatch = errors.New("wechat: appid mismatch")
+)
+
+// Watermark 小程序开放数据的水印
+type Watermark struct {
+	AppId     string `json:"appid"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// DecryptOpenData 解密小程序开放数据(wx.getUserInfo、手机号等)，参数均为base64，
+// 解密后的json解析到v，水印中的appid与appId不一致时返回ErrAppIdMismatch，appId为空时不校验
+// https://developers.weixin.qq.com/miniprogram/dev/framework/open-ability/signature.html
+func DecryptOpenData(appId, sessionKey, encryptedData, iv string, v interface{}) error {
+	key, err := utils.Base64Decode(sessionKey)
+	if err != nil {
+		return err
+	}
+	ivb, err := utils.Base64Decode(iv)
+	if err != nil {
+		return err
+	}
+	cipherText, err := utils.Base64Decode(encryptedData)
+	if err != nil {
+		return err
+	}
+	if len(key) != aes.BlockSize || len(ivb) != aes.BlockSize || len(cipherText) == 0 || len(cipherText)%aes.BlockSize != 0 {
+		return fmt.Errorf("wechat: invalid open data")
+	}
+	plain, err := utils.NewCBCCrypto(key, ivb, utils.PKCS7).Decrypt(cipherText)
+	if err != nil {
+		return err
+	}
+
+	var wm struct {
+		Watermark Watermark `json:"watermark"`
+	}
+	if err := json.Unmarshal(plain, &wm); err != nil {
+		return fmt.Errorf("wechat: decode open data: %w", err)
+	}
+	if appId != "" && wm.Watermark.AppId != appId {
+		return ErrAppIdMismatch
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(plain, v)
+}
+
+// UserInfo 小程序用户信息
+type UserInfo struct {
+	OpenId    string    `json:"openId"`
+	UnionId   string    `json:"unionId"`
+	NickName  string    `json:"nickName"`
+	Gender    int       `json:"gender"`
+	City      string    `json:"city"`
+	Province  string    `json:"province"`
+	Country   string    `json:"country"`
+	AvatarUrl string    `json:"avatarUrl"`
+	Watermark Watermark `json:"watermark"`
+}
+
+// PhoneInfo 小程序手机号
+type PhoneInfo struct {
+	PhoneNumber     string    `json:"phoneNumber"`
+	PurePhoneNumber string    `json:"purePhoneNumber"`
+	CountryCode     string    `json:"countryCode"`
+	Watermark       Watermark `json:"watermark"`
+}
+
+// Resource 支付APIv3回调通知及平台证书中的加密数据
+type Resource struct {
+	Algorithm      string `json:"algorithm"`
+	Ciphertext     string `json:"ciphertext"`
+	AssociatedData string `json:"associated_data"`
+	Nonce          string `json:"nonce"`
+	OriginalType   string `json:"original_type"`
+}
+
+// DecryptResource 使用APIv3密钥解密AEAD_AES_256_GCM加密的资源
+// https://pay.weixin.qq.com/wiki/doc/apiv3/wechatpay/wechatpay4_2.shtml
+func DecryptResource(apiV3Key string, r Resource) ([]byte, error) {
+	if len(apiV3Key) != 32 {
+		return nil, fmt.Errorf("wechat: apiv3 key must be 32 bytes")
+	}
+	cipherText, err := utils.Base64Decode(r.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher([]byte(apiV3Key))
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("wechat: invalid nonce")
+	}
+	return gcm.Open(nil, []byte(r.Nonce), cipherText, []byte(r.AssociatedData))
+}
+
+// VerifyPaySignature 校验支付APIv3应答及回调的签名，参数取自Wechatpay-Timestamp、Wechatpay-Nonce、
+// Wechatpay-Signature头和原始body，publicKey为PEM格式的平台证书公钥
+func VerifyPaySignature(timestamp, nonce string, body []byte, signature string, publicKey []byte) error {
+	sig, err := utils.Base64Decode(signature)
+	if err != nil {
+		return err
+	}
+	message := timestamp + "\n" + nonce + "\n" + string(body) + "\n"
+	if err := utils.RSAVerifyWithSha256([]byte(message), sig, publicKey); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Signature 公众号、小程序消息推送的签名：对参数字典序排序后拼接再sha1
+func Signature(params ...string) string {
+	sorted := append([]string(nil), params...)
+	sort.Strings(sorted)
+	return utils.SHA1(strings.Join(sorted, ""))
+}
+
+// VerifySignature 校验服务器配置及明文模式消息的signature
+func VerifySignature(token, timestamp, nonce, signature string) bool {
+	return utils.SecureCompare(Signature(token, timestamp, nonce), signature)
+}
+
+// MessageCrypto 公众号、小程序消息的安全模式加解密
+// https://developers.weixin.qq.com/doc/oplatform/Third-party_Platforms/2.0/api/Before_Develop/Message_encryption_and_decryption.html
+type MessageCrypto struct {
+	token string
+	appId string
+	key   []byte
+}
+
+// NewMessageCrypto encodingAESKey为后台配置的43位EncodingAESKey
+func NewMessageCrypto(token, encodingAESKey, appId string) (*MessageCrypto, error) {
+	key, err := utils.Base64Decode(encodingAESKey + "=")
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("wechat: invalid EncodingAESKey")
+	}
+	return &MessageCrypto{token: token, appId: appId, key: key}, nil
+}
+
+// Decrypt 校验msg_signature并解密消息体中的Encrypt字段，返回明文xml
+func (c *MessageCrypto) Decrypt(msgSignature, timestamp, nonce, encrypted string) ([]byte, error) {
+	if !utils.SecureCompare(Signature(c.token, timestamp, nonce, encrypted), msgSignature) {
+		return nil, ErrInvalidSignature
+	}
+	cipherText, err := utils.Base64Decode(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	if len(cipherText) == 0 || len(cipherText)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("wechat: invalid encrypted message")
+	}
+	// 明文为 16字节随机数 + 4字节消息长度 + 消息 + appid，使用32字节的PKCS#7填充
+	plain, err := utils.NewCBCCrypto(c.key, c.key[:aes.BlockSize], utils.PKCS7).Decrypt(cipherText)
+	if err != nil {
+		return nil, err
+	}
+	if len(plain) < 20 {
+		return nil, fmt.Errorf("wechat: invalid encrypted message")
+	}
+	size := int(binary.BigEndian.Uint32(plain[16:20]))
+	if size > len(plain)-20 {
+		return nil, fmt.Errorf("wechat: invalid encrypted message")
+	}
+	if c.appId != "" && string(plain[20+size:]) != c.appId {
+		return nil, ErrAppIdMismatch
+	}
+	return plain[20 : 20+size], nil
+}
+
+// Encrypt 加密回复的消息，返回Encrypt字段及对应的MsgSignature
+func (c *MessageCrypto) Encrypt(msg []byte, timestamp, nonce string) (encrypted, msgSignature string, err error) {
+	plain := make([]byte, 20, 20+len(msg)+len(c.appId))
+	if _, err = rand.Read(plain[:16]); err != nil {
+		return "", "", err
+	}
+	binary.BigEndian.PutUint32(plain[16:20], uint32(len(msg)))
+	plain = append(append(plain, msg...), c.appId...)
+	cipherText, err := utils.NewCBCCrypto(c.key, c.key[:aes.BlockSize], utils.PKCS7).Encrypt(plain)
+	if err != nil {
+		return "", "", err
+	}
+	encrypted = utils.Base64Encode(cipherText)
+	return encrypted, Signature(c.token, timestamp, nonce, encrypted), nil
+}
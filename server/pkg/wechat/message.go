@@ -0,0 +1,126 @@
+package wechat
+
+import (
+	"encoding/xml"
+	"go-skeleton/utils"
+	"strconv"
+	"time"
+)
+
+// 公众号消息类型
+const (
+	MsgTypeText  = "text"
+	MsgTypeImage = "image"
+	MsgTypeVoice = "voice"
+	MsgTypeVideo = "video"
+	MsgTypeLink  = "link"
+	MsgTypeEvent = "event"
+	MsgTypeNews  = "news"
+)
+
+// 公众号事件类型
+const (
+	EventSubscribe   = "subscribe"
+	EventUnsubscribe = "unsubscribe"
+	EventScan        = "SCAN"
+	EventClick       = "CLICK"
+	EventView        = "VIEW"
+)
+
+// EncryptedMessage 安全模式下推送的消息及被动回复的消息
+type EncryptedMessage struct {
+	XMLName      xml.Name    `xml:"xml"`
+	ToUserName   utils.CDATA `xml:"ToUserName,omitempty"`
+	Encrypt      utils.CDATA `xml:"Encrypt"`
+	MsgSignature utils.CDATA `xml:"MsgSignature,omitempty"`
+	TimeStamp    string      `xml:"TimeStamp,omitempty"`
+	Nonce        utils.CDATA `xml:"Nonce,omitempty"`
+}
+
+// Message 公众号推送的普通消息和事件
+// https://developers.weixin.qq.com/doc/offiaccount/Message_Management/Receiving_standard_messages.html
+type Message struct {
+	XMLName      xml.Name    `xml:"xml"`
+	ToUserName   utils.CDATA `xml:"ToUserName"`
+	FromUserName utils.CDATA `xml:"FromUserName"`
+	CreateTime   int64       `xml:"CreateTime"`
+	MsgType      utils.CDATA `xml:"MsgType"`
+	MsgId        int64       `xml:"MsgId,omitempty"`
+
+	// 文本、图片、语音、视频、链接消息
+	Content      utils.CDATA `xml:"Content,omitempty"`
+	PicUrl       utils.CDATA `xml:"PicUrl,omitempty"`
+	MediaId      utils.CDATA `xml:"MediaId,omitempty"`
+	Format       utils.CDATA `xml:"Format,omitempty"`
+	ThumbMediaId utils.CDATA `xml:"ThumbMediaId,omitempty"`
+	Title        utils.CDATA `xml:"Title,omitempty"`
+	Description  utils.CDATA `xml:"Description,omitempty"`
+	Url          utils.CDATA `xml:"Url,omitempty"`
+
+	// 事件
+	Event    utils.CDATA `xml:"Event,omitempty"`
+	EventKey utils.CDATA `xml:"EventKey,omitempty"`
+	Ticket   utils.CDATA `xml:"Ticket,omitempty"`
+}
+
+// Reply 被动回复的消息，只需设置对应类型的字段
+// https://developers.weixin.qq.com/doc/offiaccount/Message_Management/Passive_user_reply_message.html
+type Reply struct {
+	XMLName      xml.Name    `xml:"xml"`
+	ToUserName   utils.CDATA `xml:"ToUserName"`
+	FromUserName utils.CDATA `xml:"FromUserName"`
+	CreateTime   int64       `xml:"CreateTime"`
+	MsgType      utils.CDATA `xml:"MsgType"`
+	Content      utils.CDATA `xml:"Content,omitempty"`
+	Image        *ReplyMedia `xml:"Image,omitempty"`
+	ArticleCount int         `xml:"ArticleCount,omitempty"`
+	Articles     *Articles   `xml:"Articles,omitempty"`
+}
+
+// ReplyMedia 回复的图片
+type ReplyMedia struct {
+	MediaId utils.CDATA `xml:"MediaId"`
+}
+
+// Articles 回复的图文列表
+type Articles struct {
+	Items []Article `xml:"item"`
+}
+
+// Article 回复的图文
+type Article struct {
+	Title       utils.CDATA `xml:"Title"`
+	Description utils.CDATA `xml:"Description"`
+	PicUrl      utils.CDATA `xml:"PicUrl"`
+	Url         utils.CDATA `xml:"Url"`
+}
+
+// NewTextReply 回复文本消息，收发双方与收到的消息相反
+func NewTextReply(msg *Message, content string) *Reply {
+	return &Reply{
+		ToUserName:   msg.FromUserName,
+		FromUserName: msg.ToUserName,
+		CreateTime:   time.Now().Unix(),
+		MsgType:      MsgTypeText,
+		Content:      utils.CDATA(content),
+	}
+}
+
+// EncryptReply 安全模式下加密回复的消息
+func (c *MessageCrypto) EncryptReply(reply *Reply, nonce string) ([]byte, error) {
+	plain, err := xml.Marshal(reply)
+	if err != nil {
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	encrypted, signature, err := c.Encrypt(plain, timestamp, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return xml.Marshal(EncryptedMessage{
+		Encrypt:      utils.CDATA(encrypted),
+		MsgSignature: utils.CDATA(signature),
+		TimeStamp:    timestamp,
+		Nonce:        utils.CDATA(nonce),
+	})
+}
@@ -0,0 +1,131 @@
+package ws
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// Conn 一个websocket连接，同一个用户可以有多个连接(多个设备、标签页)
+type Conn struct {
+	hub  *Hub
+	ws   *websocket.Conn
+	uid  int64
+	send chan []byte
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// Uid 连接的用户
+func (c *Conn) Uid() int64 {
+	return c.uid
+}
+
+// Send 发送消息给当前连接，缓冲区满时断开连接
+func (c *Conn) Send(msg *Message) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		zap.L().Error("ws marshal message failed", zap.Error(err))
+		return
+	}
+	c.write(b)
+}
+
+func (c *Conn) write(b []byte) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	select {
+	case c.send <- b:
+		c.mu.Unlock()
+	default:
+		c.mu.Unlock()
+		zap.L().Warn("ws send buffer full, close slow connection", zap.Int64("uid", c.uid))
+		c.close()
+	}
+}
+
+// close 从hub中移除并关闭发送队列，writePump发送close帧后关闭连接
+func (c *Conn) close() {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	close(c.send)
+	c.mu.Unlock()
+	c.hub.remove(c)
+}
+
+// readPump 读取客户端消息，收到pong或任何消息时延长读超时，客户端也可以发送{"type":"ping"}保持连接
+func (c *Conn) readPump() {
+	cfg := c.hub.cfg
+	defer func() {
+		c.close()
+		c.ws.Close()
+	}()
+	c.ws.SetReadLimit(cfg.MaxMessageSize)
+	_ = c.ws.SetReadDeadline(time.Now().Add(cfg.PongTimeout))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(cfg.PongTimeout))
+	})
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure, websocket.CloseNoStatusReceived) {
+				zap.L().Debug("ws read failed", zap.Int64("uid", c.uid), zap.Error(err))
+			}
+			return
+		}
+		_ = c.ws.SetReadDeadline(time.Now().Add(cfg.PongTimeout))
+
+		msg := &Message{}
+		if err := json.Unmarshal(data, msg); err != nil {
+			continue
+		}
+		if msg.Type == TypePing {
+			c.Send(&Message{Type: TypePong})
+			continue
+		}
+		if handler := c.hub.handler(msg.Type); handler != nil {
+			handler(c, msg)
+		}
+	}
+}
+
+// writePump 发送消息并定时发送ping，所有写操作都在这个协程中
+func (c *Conn) writePump() {
+	cfg := c.hub.cfg
+	ticker := time.NewTicker(cfg.PingInterval)
+	defer func() {
+		ticker.Stop()
+		c.ws.Close()
+	}()
+	for {
+		select {
+		case b, ok := <-c.send:
+			_ = c.ws.SetWriteDeadline(time.Now().Add(cfg.WriteTimeout))
+			if !ok {
+				_ = c.ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			if err := c.ws.WriteMessage(websocket.TextMessage, b); err != nil {
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			_ = c.ws.SetWriteDeadline(time.Now().Add(cfg.WriteTimeout))
+			if err := c.ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
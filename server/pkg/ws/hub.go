@@ -0,0 +1,252 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"go-skeleton/pkg/config"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// 内置的消息类型
+const (
+	TypePing = "ping"
+	TypePong = "pong"
+)
+
+// Message 收发的消息，eg: {"type":"notification","data":{...}}
+type Message struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// NewMessage 创建消息，data编码为json
+func NewMessage(msgType string, data interface{}) (*Message, error) {
+	msg := &Message{Type: msgType}
+	if data != nil {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		msg.Data = b
+	}
+	return msg, nil
+}
+
+// HandlerFunc 处理客户端发送的消息
+type HandlerFunc func(c *Conn, msg *Message)
+
+// 通过redis转发的消息，UserIds为空时广播
+type envelope struct {
+	UserIds []int64  `json:"userIds,omitempty"`
+	Message *Message `json:"message"`
+}
+
+// Hub 管理本实例的连接，开启PubSub时消息通过redis发送到所有实例
+type Hub struct {
+	cfg      config.WebsocketConfig
+	upgrader websocket.Upgrader
+
+	mu    sync.RWMutex
+	users map[int64]map[*Conn]struct{}
+
+	handlersMu sync.RWMutex
+	handlers   map[string]HandlerFunc
+
+	rdb    *redis.Client
+	pubsub *redis.PubSub
+}
+
+// NewHub 按配置创建hub，checkOrigin为nil时只允许同源的连接
+func NewHub(cfg config.WebsocketConfig, checkOrigin func(r *http.Request) bool) *Hub {
+	if cfg.Channel == "" {
+		cfg.Channel = "ws:events"
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = 25 * time.Second
+	}
+	if cfg.PongTimeout <= cfg.PingInterval {
+		cfg.PongTimeout = cfg.PingInterval * 2
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 10 * time.Second
+	}
+	if cfg.MaxMessageSize <= 0 {
+		cfg.MaxMessageSize = 4096
+	}
+	if cfg.SendBuffer <= 0 {
+		cfg.SendBuffer = 64
+	}
+	return &Hub{
+		cfg: cfg,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     checkOrigin,
+		},
+		users:    map[int64]map[*Conn]struct{}{},
+		handlers: map[string]HandlerFunc{},
+	}
+}
+
+// Handle 注册客户端消息的处理方法
+func (h *Hub) Handle(msgType string, handler HandlerFunc) {
+	h.handlersMu.Lock()
+	defer h.handlersMu.Unlock()
+	h.handlers[msgType] = handler
+}
+
+func (h *Hub) handler(msgType string) HandlerFunc {
+	h.handlersMu.RLock()
+	defer h.handlersMu.RUnlock()
+	return h.handlers[msgType]
+}
+
+// Serve 升级为websocket连接，阻塞直到连接断开
+func (h *Hub) Serve(w http.ResponseWriter, r *http.Request, uid int64) error {
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+	c := &Conn{hub: h, ws: ws, uid: uid, send: make(chan []byte, h.cfg.SendBuffer)}
+	h.add(c)
+	go c.writePump()
+	c.readPump()
+	return nil
+}
+
+func (h *Hub) add(c *Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	conns, ok := h.users[c.uid]
+	if !ok {
+		conns = map[*Conn]struct{}{}
+		h.users[c.uid] = conns
+	}
+	conns[c] = struct{}{}
+}
+
+func (h *Hub) remove(c *Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if conns, ok := h.users[c.uid]; ok {
+		delete(conns, c)
+		if len(conns) == 0 {
+			delete(h.users, c.uid)
+		}
+	}
+}
+
+// Online 用户在本实例是否有连接
+func (h *Hub) Online(uid int64) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.users[uid]) > 0
+}
+
+// Count 本实例的在线用户数和连接数
+func (h *Hub) Count() (users, conns int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, c := range h.users {
+		conns += len(c)
+	}
+	return len(h.users), conns
+}
+
+// SendToUser 发送消息给用户的所有连接
+func (h *Hub) SendToUser(ctx context.Context, msg *Message, uids ...int64) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	return h.dispatch(ctx, &envelope{UserIds: uids, Message: msg})
+}
+
+// Broadcast 发送消息给所有连接
+func (h *Hub) Broadcast(ctx context.Context, msg *Message) error {
+	return h.dispatch(ctx, &envelope{Message: msg})
+}
+
+// dispatch 开启PubSub时发布到redis，由各实例的订阅协程投递，否则直接投递到本实例的连接
+func (h *Hub) dispatch(ctx context.Context, e *envelope) error {
+	if h.rdb != nil {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		return h.rdb.Publish(ctx, h.cfg.Channel, b).Err()
+	}
+	h.deliver(e)
+	return nil
+}
+
+func (h *Hub) deliver(e *envelope) {
+	b, err := json.Marshal(e.Message)
+	if err != nil {
+		return
+	}
+	var targets []*Conn
+	h.mu.RLock()
+	if len(e.UserIds) == 0 {
+		for _, conns := range h.users {
+			for c := range conns {
+				targets = append(targets, c)
+			}
+		}
+	} else {
+		for _, uid := range e.UserIds {
+			for c := range h.users[uid] {
+				targets = append(targets, c)
+			}
+		}
+	}
+	h.mu.RUnlock()
+	for _, c := range targets {
+		c.write(b)
+	}
+}
+
+// Subscribe 订阅redis频道，接收所有实例发送的消息
+func (h *Hub) Subscribe(ctx context.Context, rdb *redis.Client) error {
+	pubsub := rdb.Subscribe(ctx, h.cfg.Channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return err
+	}
+	h.rdb, h.pubsub = rdb, pubsub
+	go func() {
+		for m := range pubsub.Channel() {
+			e := &envelope{}
+			if err := json.Unmarshal([]byte(m.Payload), e); err != nil || e.Message == nil {
+				zap.L().Warn("ws invalid pubsub message", zap.String("payload", m.Payload))
+				continue
+			}
+			h.deliver(e)
+		}
+	}()
+	return nil
+}
+
+// Close 取消订阅并关闭本实例的所有连接
+func (h *Hub) Close(ctx context.Context) error {
+	if h.pubsub != nil {
+		_ = h.pubsub.Close()
+	}
+	var conns []*Conn
+	h.mu.RLock()
+	for _, cs := range h.users {
+		for c := range cs {
+			conns = append(conns, c)
+		}
+	}
+	h.mu.RUnlock()
+	for _, c := range conns {
+		c.close()
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package ws
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/gredis"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// TicketTTL 连接凭证的有效期，浏览器无法设置请求头，先用令牌换取凭证再通过url参数建立连接，
+// 避免令牌出现在url中被访问日志、代理记录
+const TicketTTL = 30 * time.Second
+
+func ticketKey(ticket string) string {
+	return "ws:ticket:" + ticket
+}
+
+// NewTicket 生成一次性的连接凭证
+func NewTicket(ctx context.Context, uid int64) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	ticket := hex.EncodeToString(b)
+	if err := gredis.GetRedis().Set(ctx, ticketKey(ticket), uid, TicketTTL).Err(); err != nil {
+		return "", err
+	}
+	return ticket, nil
+}
+
+// ConsumeTicket 校验凭证并使其失效，返回生成凭证的用户
+func ConsumeTicket(ctx context.Context, ticket string) (int64, error) {
+	if ticket == "" {
+		return 0, errors.WsTicketError
+	}
+	var get *redis.StringCmd
+	_, err := gredis.GetRedis().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, ticketKey(ticket))
+		pipe.Del(ctx, ticketKey(ticket))
+		return nil
+	})
+	if err == redis.Nil {
+		return 0, errors.WsTicketError
+	}
+	if err != nil {
+		return 0, err
+	}
+	uid, err := strconv.ParseInt(get.Val(), 10, 64)
+	if err != nil || uid <= 0 {
+		return 0, errors.WsTicketError
+	}
+	return uid, nil
+}
@@ -0,0 +1,45 @@
+package ws
+
+import (
+	"context"
+	"go-skeleton/pkg/config"
+	"net/http"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// websocket推送，eg:
+//	msg, _ := ws.NewMessage("notification", notification)
+//	ws.SendToUser(ctx, msg, uid)
+
+var defaultHub *Hub
+
+// Init 按配置创建默认的hub，开启PubSub时订阅redis频道
+func Init(rdb *redis.Client, checkOrigin func(r *http.Request) bool) error {
+	defaultHub = NewHub(config.Conf.WebsocketConfig, checkOrigin)
+	if config.Conf.WebsocketConfig.PubSub {
+		return defaultHub.Subscribe(context.Background(), rdb)
+	}
+	return nil
+}
+
+// Default 默认的hub
+func Default() *Hub {
+	return defaultHub
+}
+
+// SendToUser 通过默认的hub发送消息给用户
+func SendToUser(ctx context.Context, msg *Message, uids ...int64) error {
+	if defaultHub == nil {
+		return nil
+	}
+	return defaultHub.SendToUser(ctx, msg, uids...)
+}
+
+// Broadcast 通过默认的hub广播消息
+func Broadcast(ctx context.Context, msg *Message) error {
+	if defaultHub == nil {
+		return nil
+	}
+	return defaultHub.Broadcast(ctx, msg)
+}
@@ -10,8 +10,17 @@ import (
 
 func LoadAdminRouter(e *gin.Engine) {
 	art := admin.ArticleController{}
+	tag := admin.TagController{}
+	category := admin.CategoryController{}
+	cronJob := admin.CronController{}
+	wsAdmin := admin.WsController{}
+	user := admin.UserController{}
+	auditLog := admin.AuditLogController{}
+	dashboard := admin.DashboardController{}
+	feature := admin.FeatureFlagController{}
+	review := admin.ReviewController{}
 	//路由组
-	adminRouter := e.Group("admin").Use(middleware.RateLimitMiddleware(time.Second, 100)).Use(middleware.JwtToken())
+	adminRouter := e.Group("admin").Use(middleware.RateLimitMiddleware(time.Second, 100)).Use(middleware.JwtToken()).Use(middleware.AuditActor())
 	//adminRouter := e.Group("admin").Use(middleware.RateLimitMiddleware(time.Second, 100))
 	{
 		adminRouter.GET("/article/info", art.GetArticle)
@@ -20,9 +29,46 @@ func LoadAdminRouter(e *gin.Engine) {
 		adminRouter.GET("/article/myChan", art.MyChan)
 		adminRouter.POST("/article/edit", art.EditArticle)
 		adminRouter.GET("/article/search", art.SearchArticle)
+		adminRouter.GET("/article/trash", middleware.RequirePermission("article:delete"), art.GetTrashList)
+		adminRouter.POST("/article/delete", middleware.RequirePermission("article:delete"), art.DeleteArticle)
+		adminRouter.POST("/article/restore", middleware.RequirePermission("article:delete"), art.RestoreArticle)
+		adminRouter.POST("/article/forceDelete", middleware.RequirePermission("article:forceDelete"), art.ForceDeleteArticle)
 		adminRouter.POST("/article/uploadImg", art.UploadImg)
 		adminRouter.GET("/article/viperTest", art.ViperTest)
 		adminRouter.GET("/article/myChan2", art.MyChan2)
 		adminRouter.GET("/article/queue", art.TestQueue)
+		adminRouter.GET("/tag/list", tag.List)
+		adminRouter.POST("/tag/create", middleware.RequirePermission("tag:write"), tag.Create)
+		adminRouter.POST("/tag/update", middleware.RequirePermission("tag:write"), tag.Update)
+		adminRouter.POST("/tag/delete", middleware.RequirePermission("tag:write"), tag.Delete)
+		adminRouter.GET("/tag/export", middleware.RequirePermission("tag:write"), tag.Export)
+		adminRouter.POST("/tag/import", middleware.RequirePermission("tag:write"), tag.Import)
+		adminRouter.GET("/category/list", category.List)
+		adminRouter.POST("/category/create", middleware.RequirePermission("category:write"), category.Create)
+		adminRouter.POST("/category/update", middleware.RequirePermission("category:write"), category.Update)
+		adminRouter.POST("/category/delete", middleware.RequirePermission("category:write"), category.Delete)
+		adminRouter.GET("/cron/list", middleware.RequirePermission("cron:manage"), cronJob.List)
+		adminRouter.POST("/cron/trigger", middleware.RequirePermission("cron:manage"), cronJob.Trigger)
+		adminRouter.POST("/cron/pause", middleware.RequirePermission("cron:manage"), cronJob.Pause)
+		adminRouter.POST("/cron/resume", middleware.RequirePermission("cron:manage"), cronJob.Resume)
+		adminRouter.POST("/ws/send", middleware.RequirePermission("ws:send"), wsAdmin.Send)
+		adminRouter.GET("/ws/online", middleware.RequirePermission("ws:send"), wsAdmin.Online)
+		adminRouter.POST("/user/unlock", middleware.RequirePermission("user:manage"), user.Unlock)
+		adminRouter.GET("/user/export", middleware.RequirePermission("user:manage"), middleware.Unmask("user:manage"), user.Export)
+		adminRouter.GET("/audit/list", middleware.RequirePermission("audit:read"), auditLog.List)
+		adminRouter.GET("/audit/info", middleware.RequirePermission("audit:read"), auditLog.Info)
+		adminRouter.GET("/dashboard/overview", middleware.RequirePermission("dashboard:read"), dashboard.Overview)
+		adminRouter.GET("/dashboard/daily", middleware.RequirePermission("dashboard:read"), dashboard.Daily)
+		adminRouter.GET("/dashboard/totals", middleware.RequirePermission("dashboard:read"), dashboard.Totals)
+		adminRouter.GET("/dashboard/audit", middleware.RequirePermission("dashboard:read"), dashboard.Audit)
+		adminRouter.GET("/feature/list", middleware.RequirePermission("feature:manage"), feature.List)
+		adminRouter.POST("/feature/create", middleware.RequirePermission("feature:manage"), feature.Create)
+		adminRouter.POST("/feature/update", middleware.RequirePermission("feature:manage"), feature.Update)
+		adminRouter.POST("/feature/delete", middleware.RequirePermission("feature:manage"), feature.Delete)
+		adminRouter.GET("/review/list", middleware.RequirePermission("article:review"), review.List)
+		adminRouter.POST("/review/assign", middleware.RequirePermission("article:review"), review.Assign)
+		adminRouter.POST("/review/approve", middleware.RequirePermission("article:review"), review.Approve)
+		adminRouter.POST("/review/reject", middleware.RequirePermission("article:review"), review.Reject)
+		adminRouter.GET("/review/logs", middleware.RequirePermission("article:review"), review.Logs)
 	}
 }
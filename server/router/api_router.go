@@ -11,9 +11,20 @@ import (
 func LoadApiRouter(e *gin.Engine) {
 	art := api.ArticleController{}
 	captch := api.CaptchaController{}
+	up := api.UploadController{}
+	tag := api.TagController{}
+	comment := api.CommentController{}
+	search := api.SearchController{}
+	wsConn := api.WsController{}
+	user := api.UserController{}
+	pdf := api.PdfController{}
+	qrcode := api.QrcodeController{}
+	notification := api.NotificationController{}
+	reaction := api.ReactionController{}
 	//路由组
 	//apiRouter := e.Group("api").Use(middleware.RateLimitMiddleware(time.Second, 100)).Use(middleware.JwtToken())
-	apiRouter := e.Group("api").Use(middleware.RateLimitMiddleware(time.Second, 100))
+	//未带版本的/api请求按请求头改写到对应版本，见apiversion.Negotiate
+	apiRouter := versionGroup(e, "api", "v1").Use(middleware.RateLimitMiddleware(time.Second, 100))
 	{
 		apiRouter.GET("/article/getArticleById", art.GetArticleById)
 		apiRouter.GET("/article/getArticleByCache", art.GetArticleByCache)
@@ -24,13 +35,72 @@ func LoadApiRouter(e *gin.Engine) {
 		apiRouter.GET("/article/myChan", art.MyChan)
 		apiRouter.POST("/article/edit", art.EditArticle)
 		apiRouter.GET("/article/search", art.SearchArticle)
-		apiRouter.POST("/article/uploadImg", art.UploadImg)
+		apiRouter.POST("/article/uploadImg", middleware.JwtToken(), art.UploadImg)
 		apiRouter.GET("/article/viperTest", art.ViperTest)
 		apiRouter.GET("/article/myChan2", art.MyChan2)
+		apiRouter.GET("/article/exportProgress", art.ExportProgress)
 		apiRouter.GET("/article/queue", art.TestQueue)
 		apiRouter.GET("/article/testPanic", art.TestPanic)
 		apiRouter.GET("/captcha", captch.GenerateCaptcha)
 		apiRouter.GET("/verifyCaptcha", captch.VerifyCaptcha)
 		apiRouter.GET("/redisLock", art.TryRedisLock)
+		apiRouter.GET("/article/published", middleware.Cache(time.Minute, "article", "article_tag", "tag"), art.PublishedArticles)
+		apiRouter.GET("/article/detail", art.Detail)
+		apiRouter.GET("/article/hot", art.Hot)
+		apiRouter.POST("/article/add", middleware.JwtToken(), middleware.Idempotency(24*time.Hour), art.AddArticle)
+		apiRouter.POST("/article/update", middleware.JwtToken(), art.UpdateArticle)
+		apiRouter.POST("/article/autosave", middleware.JwtToken(), art.Autosave)
+		apiRouter.GET("/article/revisions", middleware.JwtToken(), art.Revisions)
+		apiRouter.GET("/article/revision", middleware.JwtToken(), art.Revision)
+		apiRouter.POST("/article/revision/restore", middleware.JwtToken(), art.RestoreRevision)
+		apiRouter.POST("/article/publish", middleware.JwtToken(), art.PublishArticle)
+		apiRouter.POST("/article/submit", middleware.JwtToken(), art.SubmitArticle)
+		apiRouter.POST("/article/withdraw", middleware.JwtToken(), art.WithdrawArticle)
+		apiRouter.GET("/article/statusLogs", middleware.JwtToken(), art.StatusLogs)
+		apiRouter.POST("/article/schedule", middleware.JwtToken(), art.ScheduleArticle)
+		apiRouter.POST("/article/schedule/cancel", middleware.JwtToken(), art.CancelSchedule)
+		apiRouter.POST("/article/archive", middleware.JwtToken(), art.ArchiveArticle)
+		apiRouter.GET("/article/mine", middleware.JwtToken(), art.MyArticles)
+		apiRouter.GET("/tag/hot", middleware.Cache(5*time.Minute, "tag", "article_tag", "article"), tag.Hot)
+		apiRouter.GET("/tag/articles", tag.Articles)
+		apiRouter.GET("/tag/ofArticle", tag.OfArticle)
+		apiRouter.POST("/tag/attach", middleware.JwtToken(), tag.Attach)
+		apiRouter.POST("/tag/detach", middleware.JwtToken(), tag.Detach)
+		apiRouter.GET("/categories", middleware.Cache(10*time.Minute, "category"), tag.Categories)
+		apiRouter.GET("/articles/search", search.Articles)
+		apiRouter.GET("/articles/trending", art.Trending)
+		apiRouter.GET("/comment/list", comment.List)
+		apiRouter.GET("/comment/replies", comment.Replies)
+		apiRouter.POST("/comment/create", middleware.JwtToken(), comment.Create)
+		apiRouter.POST("/comment/delete", middleware.JwtToken(), comment.Delete)
+		apiRouter.GET("/ws", wsConn.Connect)
+		apiRouter.POST("/ws/ticket", middleware.JwtToken(), wsConn.Ticket)
+		apiRouter.GET("/user/profile", middleware.JwtToken(), middleware.Unmask(), user.Profile)
+		apiRouter.POST("/user/profile", middleware.JwtToken(), middleware.Unmask(), user.UpdateProfile)
+		apiRouter.POST("/user/password", middleware.JwtToken(), user.ChangePassword)
+		apiRouter.GET("/user/oauth", middleware.JwtToken(), user.OAuthBindings)
+		apiRouter.GET("/user/oauth/authorize", middleware.JwtToken(), user.OAuthAuthorize)
+		apiRouter.POST("/user/oauth/bind", middleware.JwtToken(), user.OAuthBind)
+		apiRouter.POST("/user/oauth/unbind", middleware.JwtToken(), user.OAuthUnbind)
+		apiRouter.POST("/upload", middleware.JwtToken(), up.Upload)
+		apiRouter.POST("/upload/chunk", middleware.JwtToken(), up.UploadChunk)
+		apiRouter.GET("/upload/chunks", middleware.JwtToken(), up.UploadedChunks)
+		apiRouter.POST("/upload/merge", middleware.JwtToken(), up.MergeChunks)
+		apiRouter.POST("/pdf/create", middleware.JwtToken(), pdf.Create)
+		apiRouter.GET("/pdf/task", middleware.JwtToken(), pdf.Task)
+		apiRouter.GET("/qrcode", qrcode.QRCode)
+		apiRouter.GET("/barcode", qrcode.Barcode)
+		apiRouter.GET("/notification/list", middleware.JwtToken(), notification.List)
+		apiRouter.GET("/notification/unreadCount", middleware.JwtToken(), notification.UnreadCount)
+		apiRouter.POST("/notification/read", middleware.JwtToken(), notification.Read)
+		apiRouter.POST("/notification/readAll", middleware.JwtToken(), notification.ReadAll)
+		apiRouter.POST("/reaction/toggle", middleware.JwtToken(), reaction.Toggle)
+		apiRouter.GET("/reaction/favorites", middleware.JwtToken(), reaction.Favorites)
 	}
+	//新版本的接口注册到新的路由组，未修改的接口也需要注册，eg:
+	//v2 := versionGroup(e, "api", "v2").Use(middleware.RateLimitMiddleware(time.Second, 100))
+	//v2.GET("/article/list", artV2.GetArticleList)
+	//v2.GET("/tag/hot", tag.Hot)
+	//单个接口弃用时使用middleware.Deprecated，eg:
+	//apiRouter.GET("/article/myHttp", middleware.Deprecated(time.Time{}, sunset, ""), art.MyHttp)
 }
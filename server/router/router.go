@@ -2,6 +2,10 @@ package router
 
 import (
 	"go-skeleton/controller/site"
+	"go-skeleton/middleware"
+	"go-skeleton/pkg/health"
+	"go-skeleton/pkg/metrics"
+	"time"
 
 	_ "go-skeleton/docs" // 千万不要忘了导入把你上一步生成的docs
 
@@ -17,7 +21,22 @@ func LoadDefault(e *gin.Engine) {
 	e.GET("/site/hello", site.Hello)
 	e.POST("/site/login", site.Login)
 	e.POST("/site/refreshToken", site.RefreshAccessToken)
+	e.POST("/site/logout", middleware.JwtToken(), site.Logout)
 	e.POST("/site/register", site.Register)
+	e.GET("/site/oauth/providers", site.OAuthProviders)
+	e.GET("/site/oauth/authorize", site.OAuthAuthorize)
+	e.POST("/site/oauth/login", site.OAuthLogin)
+	//需要图形验证码时使用middleware.RequireCaptcha校验，eg:
+	//e.POST("/site/login", middleware.RequireCaptcha(), site.Login)
+	//需要短信验证时使用middleware.SmsCode校验，eg:
+	//e.POST("/site/register", middleware.SmsCode(sms.SceneRegister), site.Register)
+	//开放接口使用middleware.SignatureAuth校验合作方签名，合作方在配置文件openapi.Partners中配置，eg:
+	//open := e.Group("open").Use(middleware.SignatureAuth())
+	//open.GET("/article/list", openapi.ArticleList)
+	e.POST("/site/sms/send", middleware.RateLimit("sms", 10, time.Hour, middleware.RateLimitByIP), site.SendSmsCode)
 
 	e.GET("/swagger/*any", gs.WrapHandler(swaggerFiles.Handler))
+	e.GET("/metrics", metrics.Handler())
+	e.GET("/healthz", health.Liveness())
+	e.GET("/readyz", health.Readiness())
 }
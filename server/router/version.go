@@ -0,0 +1,15 @@
+package router
+
+import (
+	"go-skeleton/middleware"
+	"go-skeleton/pkg/apiversion"
+
+	"github.com/gin-gonic/gin"
+)
+
+// versionGroup 带版本的路由组，eg: /api/v2，配置apiversion.Deprecated中的版本响应头带上弃用信息
+func versionGroup(e *gin.Engine, prefix, version string) *gin.RouterGroup {
+	version = apiversion.Normalize(version)
+	apiversion.Register(prefix, version)
+	return e.Group(prefix+"/"+version, middleware.ApiVersion(version), middleware.DeprecatedVersion(version))
+}
@@ -0,0 +1,132 @@
+package rpc
+
+import (
+	"context"
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/rpc/pb"
+	"go-skeleton/services"
+	"go-skeleton/utils"
+
+	"go.uber.org/zap"
+)
+
+// 文章服务方法的前缀，与proto中的package和service对应
+const articleServicePrefix = "/skeleton.article.v1.ArticleService/"
+
+func init() {
+	AllowAnonymous(
+		articleServicePrefix+"GetArticle",
+		articleServicePrefix+"ListArticles",
+	)
+}
+
+// articleServer 文章服务，与api.ArticleController使用相同的services
+type articleServer struct {
+	pb.UnimplementedArticleServiceServer
+}
+
+func (s *articleServer) GetArticle(ctx context.Context, req *pb.GetArticleRequest) (*pb.Article, error) {
+	if req.Id <= 0 {
+		return nil, toStatus(ctx, errors.InvalidParamsError)
+	}
+	article := services.ArticleService.Get(ctx, req.Id)
+	if article == nil || article.Status != constants.ArticleStatusPublished {
+		return nil, toStatus(ctx, errors.NotFoundError)
+	}
+	if _, err := services.ViewService.Record(ctx, req.Id, UserId(ctx), peerIp(ctx)); err != nil {
+		utils.Logger(ctx).Warn("record article view failed", zap.Int64("id", req.Id), zap.Error(err))
+	}
+	article.ReadCount += services.ViewService.Pending(ctx, req.Id)
+	return toArticle(article), nil
+}
+
+func (s *articleServer) ListArticles(ctx context.Context, req *pb.ListArticlesRequest) (*pb.ListArticlesResponse, error) {
+	page, limit := int(req.Page), int(req.Limit)
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	list, paging := services.ArticleService.ListPublished(ctx, req.Cid, page, limit)
+	res := &pb.ListArticlesResponse{
+		Results: make([]*pb.Article, 0, len(list)),
+		Page:    int32(paging.Page),
+		Limit:   int32(paging.Limit),
+		Total:   paging.Total,
+	}
+	for i := range list {
+		res.Results = append(res.Results, toArticle(&list[i]))
+	}
+	return res, nil
+}
+
+func (s *articleServer) CreateArticle(ctx context.Context, req *pb.ArticleForm) (*pb.Article, error) {
+	article, err := services.ArticleService.Create(ctx, UserId(ctx), toForm(req))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toArticle(article), nil
+}
+
+func (s *articleServer) UpdateArticle(ctx context.Context, req *pb.UpdateArticleRequest) (*pb.Article, error) {
+	if req.Id <= 0 || req.Form == nil {
+		return nil, toStatus(ctx, errors.InvalidParamsError)
+	}
+	form := toForm(req.Form)
+	if req.Version > 0 {
+		form.Version = &req.Version
+	}
+	article, err := services.ArticleService.Edit(ctx, UserId(ctx), req.Id, form)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toArticle(article), nil
+}
+
+func (s *articleServer) PublishArticle(ctx context.Context, req *pb.PublishArticleRequest) (*pb.PublishArticleResponse, error) {
+	if req.Id <= 0 {
+		return nil, toStatus(ctx, errors.InvalidParamsError)
+	}
+	if err := services.ArticleService.Publish(ctx, UserId(ctx), req.Id); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &pb.PublishArticleResponse{}, nil
+}
+
+func toForm(f *pb.ArticleForm) *services.ArticleForm {
+	return &services.ArticleForm{
+		Title:   f.Title,
+		Cid:     f.Cid,
+		Desc:    f.Desc,
+		Content: f.Content,
+		Img:     f.Img,
+		Slug:    f.Slug,
+		Tags:    f.Tags,
+	}
+}
+
+func toArticle(a *model.Article) *pb.Article {
+	return &pb.Article{
+		Id:            int64(a.ID),
+		Title:         a.Title,
+		Cid:           a.Cid,
+		Category:      a.Category.Name,
+		Desc:          a.Desc,
+		Content:       a.Content,
+		Img:           a.Img,
+		Slug:          a.Slug,
+		Status:        int32(a.Status),
+		UserId:        a.UserId,
+		ReadCount:     a.ReadCount,
+		CommentCount:  a.CommentCount,
+		LikeCount:     a.LikeCount,
+		FavoriteCount: a.FavoriteCount,
+		Version:       a.Version,
+		PublishedAt:   a.PublishedAt,
+		CreatedAt:     a.CreatedAt.Unix(),
+		UpdatedAt:     a.UpdatedAt.Unix(),
+	}
+}
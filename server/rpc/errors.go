@@ -0,0 +1,47 @@
+package rpc
+
+import (
+	"context"
+	stderrors "errors"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/i18n"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 错误码对应的http状态码转换为grpc的状态码，业务错误(http 200)使用FailedPrecondition
+var httpToCode = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnprocessableEntity:   codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.Aborted,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusRequestEntityTooLarge: codes.ResourceExhausted,
+	http.StatusInternalServerError:   codes.Internal,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+}
+
+// toStatus 将services返回的错误转换为grpc的错误，*errors.CodeError的提示按请求的语言翻译，
+// 错误码通过status的message前缀返回，eg: [1003] 数据不存在
+func toStatus(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var ce *errors.CodeError
+	if !stderrors.As(err, &ce) {
+		return status.Error(codes.Internal, err.Error())
+	}
+	code, ok := httpToCode[errors.HttpStatus(ce.Code)]
+	if !ok {
+		code = codes.FailedPrecondition
+	}
+	ce = i18n.Error(ctx, ce)
+	return status.Errorf(code, "[%d] %s", ce.Code, ce.Message)
+}
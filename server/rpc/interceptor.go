@@ -0,0 +1,288 @@
+package rpc
+
+import (
+	"context"
+	"go-skeleton/pkg/audit"
+	"go-skeleton/pkg/auth"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/i18n"
+	"go-skeleton/pkg/tenant"
+	"go-skeleton/pkg/tracing"
+	"go-skeleton/services"
+	"go-skeleton/utils"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// 请求id的metadata，与http的X-Request-ID对应
+const requestIDMetadata = "x-request-id"
+
+type uidKey struct{}
+
+var (
+	anonymousMu      sync.RWMutex
+	anonymousMethods = make(map[string]bool)
+)
+
+// AllowAnonymous 注册允许未登录调用的方法，未注册的方法都需要登录，新增方法时默认受保护，
+// fullMethod格式为/包名.服务/方法，eg: /skeleton.article.v1.ArticleService/GetArticle
+func AllowAnonymous(fullMethods ...string) {
+	anonymousMu.Lock()
+	defer anonymousMu.Unlock()
+	for _, m := range fullMethods {
+		anonymousMethods[m] = true
+	}
+}
+
+func anonymousAllowed(fullMethod string) bool {
+	anonymousMu.RLock()
+	defer anonymousMu.RUnlock()
+	return anonymousMethods[fullMethod]
+}
+
+// UserId 登录用户的id，未登录时返回0
+func UserId(ctx context.Context) int64 {
+	uid, _ := ctx.Value(uidKey{}).(int64)
+	return uid
+}
+
+// Recovery 恢复panic并记录日志，返回Internal错误，与logger.GinRecovery对应
+func Recovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecovery 流式方法的Recovery
+func StreamRecovery() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ss.Context(), info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, method string, r interface{}) error {
+	utils.Logger(ctx).Error("[Recovery from panic]", zap.String("method", method),
+		zap.Any("error", r), zap.String("stack", string(debug.Stack())))
+	return status.Error(grpccodes.Internal, i18n.Error(ctx, errors.InternalError).Message)
+}
+
+// RequestID 读取或生成请求id，写入ctx和响应的header，同时按accept-language确定语言
+func RequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		id := firstMetadata(ctx, requestIDMetadata)
+		if id == "" || len(id) > 64 {
+			id = utils.UUID()
+		}
+		ctx = utils.WithRequestID(ctx, id)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+		if lang := firstMetadata(ctx, "accept-language"); lang != "" {
+			ctx = i18n.WithLocale(ctx, i18n.Match(lang))
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Tracing 为每个请求创建server span，从metadata中继承上游的链路信息，与middleware.Tracing对应
+func Tracing() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+		}
+		ctx, span := tracing.Tracer().Start(ctx, strings.TrimPrefix(info.FullMethod, "/"), trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return resp, err
+	}
+}
+
+// Logging 访问日志，与logger.GinLogger对应
+func Logging() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		fields := []zap.Field{
+			zap.String("method", info.FullMethod),
+			zap.String("code", status.Code(err).String()),
+			zap.String("ip", peerIp(ctx)),
+			zap.String("request-id", utils.RequestID(ctx)),
+			zap.Int64("uid", UserId(ctx)),
+			zap.Duration("cost", time.Since(start)),
+		}
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+		}
+		zap.L().Info("grpc", fields...)
+		return resp, err
+	}
+}
+
+// Tenant 按metadata中的租户code或:authority的子域名解析租户并写入ctx，严格模式下解析不到租户时返回NotFound，
+// 与middleware.Tenant对应
+func Tenant() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := resolveTenant(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamTenant 流式方法的Tenant
+func StreamTenant() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := resolveTenant(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func resolveTenant(ctx context.Context) (context.Context, error) {
+	cfg := config.Conf.TenantConfig
+	if !cfg.Enable {
+		return ctx, nil
+	}
+	code := ""
+	if cfg.Header != "" {
+		code = firstMetadata(ctx, strings.ToLower(cfg.Header))
+	}
+	if code == "" {
+		code = tenant.Subdomain(firstMetadata(ctx, ":authority"), cfg.Domain)
+	}
+	t := services.TenantService.Resolve(ctx, code)
+	if t == nil {
+		if cfg.Strict {
+			return ctx, toStatus(ctx, errors.NotFoundError)
+		}
+		return ctx, nil
+	}
+	return tenant.WithTenant(ctx, t.ID), nil
+}
+
+// Auth 校验authorization中的Bearer令牌，令牌有效时写入用户id和审计的操作人，
+// 除AllowAnonymous注册的方法外未登录时返回Unauthenticated，用户不属于Tenant解析出的租户时返回PermissionDenied，与middleware.JwtToken对应
+func Auth() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuth 流式方法的Auth
+func StreamAuth() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, method string) (context.Context, error) {
+	header := firstMetadata(ctx, "authorization")
+	if header == "" {
+		if anonymousAllowed(method) {
+			return ctx, nil
+		}
+		return ctx, toStatus(ctx, errors.TokenExistError)
+	}
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return ctx, toStatus(ctx, errors.TokenTypeWrongError)
+	}
+	claims, ce := auth.CheckToken(parts[1])
+	if ce != nil {
+		return ctx, toStatus(ctx, ce)
+	}
+	if tid, ok := tenant.FromContext(ctx); ok && !services.TenantService.IsMember(ctx, tid, int64(claims.Id)) {
+		return ctx, toStatus(ctx, errors.PermissionDeniedError)
+	}
+	ctx = context.WithValue(ctx, uidKey{}, int64(claims.Id))
+	return audit.WithActor(ctx, audit.Actor{Id: int64(claims.Id), Ip: peerIp(ctx)}), nil
+}
+
+// wrappedStream 替换ServerStream的ctx
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(key); len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// peerIp 客户端ip，不信任metadata中的x-forwarded-for
+func peerIp(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr := p.Addr.String()
+		if i := strings.LastIndex(addr, ":"); i > 0 {
+			return strings.Trim(addr[:i], "[]")
+		}
+		return addr
+	}
+	return ""
+}
+
+// metadataCarrier 使otel的propagator可以读写grpc的metadata
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
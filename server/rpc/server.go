@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/rpc/pb"
+	"net"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// gRPC服务，与gin的接口共用services，拦截器与http的中间件对应:
+// 恢复panic、请求id、链路追踪、访问日志、租户、登录校验，默认所有方法都需要登录，公开的方法通过AllowAnonymous注册
+
+func init() {
+	// 健康检查和反射供负载均衡、调试工具调用，不携带令牌
+	AllowAnonymous(
+		"/grpc.health.v1.Health/Check",
+		"/grpc.health.v1.Health/Watch",
+		"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+	)
+}
+
+var (
+	server       *grpc.Server
+	healthServer *health.Server
+)
+
+// Start 按配置grpc在单独的端口启动服务
+func Start() error {
+	cfg := config.Conf.GrpcConfig
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GrpcPort))
+	if err != nil {
+		return err
+	}
+	server = NewServer()
+	if cfg.Reflection {
+		reflection.Register(server)
+	}
+	zap.L().Info(fmt.Sprintf("Listening and serving gRPC on %s", lis.Addr()))
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			zap.L().Error("grpc server stopped", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// NewServer 创建注册了拦截器和各服务的grpc.Server
+func NewServer() *grpc.Server {
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(Recovery(), RequestID(), Tracing(), Logging(), Tenant(), Auth()),
+		grpc.ChainStreamInterceptor(StreamRecovery(), StreamTenant(), StreamAuth()),
+	)
+	pb.RegisterArticleServiceServer(s, &articleServer{})
+	healthServer = health.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, healthServer)
+	return s
+}
+
+// Stop 停止接收新的请求，等待处理中的请求完成，ctx超时后强制关闭
+func Stop(ctx context.Context) error {
+	if server == nil {
+		return nil
+	}
+	healthServer.Shutdown()
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		return ctx.Err()
+	}
+}
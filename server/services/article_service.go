@@ -1,10 +1,21 @@
 package services
 
 import (
+	"context"
+	stderrors "errors"
 	"go-skeleton/dao"
 	"go-skeleton/model"
 	"go-skeleton/model/constants"
+	"go-skeleton/pkg/audit"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/eventbus"
+	"go-skeleton/pkg/outbox"
 	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/utils"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"gorm.io/gorm"
 )
@@ -18,61 +29,87 @@ func newArticleService() *articleService {
 type articleService struct {
 }
 
-func (s *articleService) Get(id int64) *model.Article {
-	return dao.ArticleDao.Get(simpleDb.DB(), id)
+func (s *articleService) Get(ctx context.Context, id int64) *model.Article {
+	return dao.ArticleDao.Get(simpleDb.WithContext(ctx), id)
 }
 
-func (s *articleService) Take(where ...interface{}) *model.Article {
-	return dao.ArticleDao.Take(simpleDb.DB(), where...)
+func (s *articleService) Take(ctx context.Context, where ...interface{}) *model.Article {
+	return dao.ArticleDao.Take(simpleDb.WithContext(ctx), where...)
 }
 
-func (s *articleService) Find(cnd *simpleDb.SqlCnd) []model.Article {
-	return dao.ArticleDao.Find(simpleDb.DB(), cnd)
+func (s *articleService) Find(ctx context.Context, cnd *simpleDb.SqlCnd) []model.Article {
+	return dao.ArticleDao.Find(simpleDb.WithContext(ctx), cnd)
 }
 
-func (s *articleService) FindOne(cnd *simpleDb.SqlCnd) *model.Article {
-	return dao.ArticleDao.FindOne(simpleDb.DB(), cnd)
+func (s *articleService) FindOne(ctx context.Context, cnd *simpleDb.SqlCnd) *model.Article {
+	return dao.ArticleDao.FindOne(simpleDb.WithContext(ctx), cnd)
 }
 
-func (s *articleService) FindPageByParams(params *simpleDb.QueryParams) (list []model.Article, paging *simpleDb.Paging) {
-	return dao.ArticleDao.FindPageByParams(simpleDb.DB(), params)
+func (s *articleService) FindPageByParams(ctx context.Context, params *simpleDb.QueryParams) (list []model.Article, paging *simpleDb.Paging) {
+	return dao.ArticleDao.FindPageByParams(simpleDb.WithContext(ctx), params)
 }
 
-func (s *articleService) FindPageByCnd(cnd *simpleDb.SqlCnd) (list []model.Article, paging *simpleDb.Paging) {
-	return dao.ArticleDao.FindPageByCnd(simpleDb.DB(), cnd)
+func (s *articleService) FindPageByCnd(ctx context.Context, cnd *simpleDb.SqlCnd) (list []model.Article, paging *simpleDb.Paging) {
+	return dao.ArticleDao.FindPageByCnd(simpleDb.WithContext(ctx), cnd)
 }
 
-func (s *articleService) Update(t *model.Article) error {
-	err := dao.ArticleDao.Update(simpleDb.DB(), t)
+// FindCursorPage 游标分页，游标无效时返回InvalidParamsError
+func (s *articleService) FindCursorPage(ctx context.Context, cnd *simpleDb.SqlCnd) (list []model.Article, paging *simpleDb.Paging, err error) {
+	list, paging, err = dao.ArticleDao.FindCursorPage(simpleDb.WithContext(ctx), cnd)
+	if err == simpleDb.ErrInvalidCursor {
+		err = errors.InvalidParamsError
+	}
+	return
+}
+
+func (s *articleService) Update(ctx context.Context, t *model.Article) error {
+	err := dao.ArticleDao.Update(simpleDb.WithContext(ctx), t)
 	return err
 }
 
-func (s *articleService) Updates(id int64, columns map[string]interface{}) error {
-	err := dao.ArticleDao.Updates(simpleDb.DB(), id, columns)
+func (s *articleService) Updates(ctx context.Context, id int64, columns map[string]interface{}) error {
+	err := dao.ArticleDao.Updates(simpleDb.WithContext(ctx), id, columns)
 	return err
 }
 
-func (s *articleService) UpdateColumn(id int64, name string, value interface{}) error {
-	err := dao.ArticleDao.UpdateColumn(simpleDb.DB(), id, name, value)
+func (s *articleService) UpdateColumn(ctx context.Context, id int64, name string, value interface{}) error {
+	err := dao.ArticleDao.UpdateColumn(simpleDb.WithContext(ctx), id, name, value)
 	return err
 }
 
-func (s *articleService) Delete(id int64) error {
-	err := dao.ArticleDao.UpdateColumn(simpleDb.DB(), id, "status", constants.StatusDeleted)
+func (s *articleService) Delete(ctx context.Context, id int64) error {
+	err := dao.ArticleDao.Delete(simpleDb.WithContext(ctx), id)
 	if err == nil {
 		// 删掉标签文章
 		//ArticleTagService.DeleteByArticleId(id)
+		SearchService.SyncArticle(id)
+	}
+	return err
+}
+
+func (s *articleService) Restore(ctx context.Context, id int64) error {
+	err := dao.ArticleDao.Restore(simpleDb.WithContext(ctx), id)
+	if err == nil {
+		SearchService.SyncArticle(id)
+	}
+	return err
+}
+
+func (s *articleService) ForceDelete(ctx context.Context, id int64) error {
+	err := dao.ArticleDao.ForceDelete(simpleDb.WithContext(ctx), id)
+	if err == nil {
+		SearchService.SyncArticle(id)
 	}
 	return err
 }
 
 // 根据文章编号批量获取文章
-func (s *articleService) GetArticleInIds(articleIds []int64) []model.Article {
+func (s *articleService) GetArticleInIds(ctx context.Context, articleIds []int64) []model.Article {
 	if len(articleIds) == 0 {
 		return nil
 	}
 	var articles []model.Article
-	simpleDb.DB().Where("id in (?)", articleIds).Order("id desc").Find(&articles)
+	simpleDb.WithContext(ctx).Where("id in (?)", articleIds).Order("id desc").Find(&articles)
 	return articles
 }
 
@@ -101,27 +138,435 @@ func (s *articleService) GetArticleInIds(articleIds []int64) []model.Article {
 //	return
 //}
 
-// 标签文章列表
-func (s *articleService) GetTagArticles(tagId int64, cursor int64) (articles []model.Article, nextCursor int64) {
-	cnd := simpleDb.NewSqlCnd().Eq("tag_id", tagId).Eq("status", constants.StatusOk).Desc("id").Limit(20)
+// 标签文章列表，按id倒序的游标分页
+func (s *articleService) GetTagArticles(ctx context.Context, tagId int64, cursor int64) (articles []model.Article, nextCursor int64) {
+	cnd := s.tagCnd(tagId).Desc("article.id").Limit(20)
 	if cursor > 0 {
-		cnd.Lt("id", cursor)
+		cnd.Lt("article.id", cursor)
 	}
 	nextCursor = cursor
-	articleTags := dao.ArticleDao.Find(simpleDb.DB(), cnd)
-	if len(articleTags) > 0 {
-		var articleIds []int64
-		//for _, articleTag := range articleTags {
-		//	articleIds = append(articleIds, articleTag.ArticleId)
-		//	nextCursor = articleTag.Id
-		//}
-		articles = s.GetArticleInIds(articleIds)
+	articles = dao.ArticleDao.Find(simpleDb.WithContext(ctx), cnd)
+	if len(articles) > 0 {
+		nextCursor = int64(articles[len(articles)-1].ID)
 	}
 	return
 }
 
-func (s *articleService) CreateArticle(article *model.Article) error {
-	return dao.ArticleDao.Create(simpleDb.DB(), article)
+// ListByTag 标签下已发布的文章，按发布时间倒序
+func (s *articleService) ListByTag(ctx context.Context, tagId int64, page, limit int) ([]model.Article, *simpleDb.Paging) {
+	cnd := s.tagCnd(tagId).Desc("article.published_at").Desc("article.id").Page(page, limit)
+	return dao.ArticleDao.FindPageByCndCtx(ctx, simpleDb.WithContext(ctx), cnd)
+}
+
+// tagCnd 关联article_tag查询标签下已发布的文章
+func (s *articleService) tagCnd(tagId int64) *simpleDb.SqlCnd {
+	return simpleDb.NewSqlCnd().
+		Select("article.*"). // 只取文章的字段，避免和article_tag的id等同名字段冲突
+		Join("JOIN article_tag ON article_tag.article_id = article.id").
+		Eq("article_tag.tag_id", tagId).
+		Eq("article_tag.status", constants.StatusOk).
+		Eq("article.status", constants.ArticleStatusPublished)
+}
+
+func (s *articleService) CreateArticle(ctx context.Context, article *model.Article) error {
+	return dao.ArticleDao.Create(simpleDb.WithContext(ctx), article)
+}
+
+// ArticleForm 创建、修改文章的参数
+type ArticleForm struct {
+	Title   string   `json:"title" form:"title"`
+	Cid     uint64   `json:"cid" form:"cid"`
+	Desc    string   `json:"desc" form:"desc"`
+	Content string   `json:"content" form:"content"`
+	Img     string   `json:"img" form:"img"`
+	Slug    string   `json:"slug" form:"slug"`
+	Tags    []string `json:"tags" form:"tags"`
+	// 修改时读取到的版本号，为空时使用修改前的最新版本
+	Version *int64 `json:"version" form:"version"`
+}
+
+// 文章状态允许的流转，开启审核时草稿不能直接发布
+var articleTransitions = map[int][]int{
+	constants.ArticleStatusDraft:         {constants.ArticleStatusPublished, constants.ArticleStatusPendingReview},
+	constants.ArticleStatusPendingReview: {constants.ArticleStatusApproved, constants.ArticleStatusRejected, constants.ArticleStatusDraft},
+	constants.ArticleStatusApproved:      {constants.ArticleStatusPublished},
+	constants.ArticleStatusRejected:      {constants.ArticleStatusPendingReview, constants.ArticleStatusDraft},
+	constants.ArticleStatusPublished:     {constants.ArticleStatusArchived},
+}
+
+// 状态流转对应的操作，用于记录状态变更
+var articleActions = map[int]string{
+	constants.ArticleStatusDraft:         constants.ArticleActionWithdraw,
+	constants.ArticleStatusPendingReview: constants.ArticleActionSubmit,
+	constants.ArticleStatusApproved:      constants.ArticleActionApprove,
+	constants.ArticleStatusRejected:      constants.ArticleActionReject,
+	constants.ArticleStatusPublished:     constants.ArticleActionPublish,
+	constants.ArticleStatusArchived:      constants.ArticleActionArchive,
+}
+
+// 自动生成摘要的长度，加上...不超过摘要的200字限制
+const articleSummaryLength = 197
+
+// 管理其他用户文章的权限
+const articleManagePermission = "article:manage"
+
+func (s *articleService) validate(form *ArticleForm) error {
+	form.Title = strings.TrimSpace(form.Title)
+	form.Desc = strings.TrimSpace(form.Desc)
+	form.Content = strings.TrimSpace(form.Content)
+	if form.Title == "" {
+		return errors.NewError(errors.InvalidParamsError.Code, "标题不能为空")
+	}
+	if utf8.RuneCountInString(form.Title) > 100 {
+		return errors.NewError(errors.InvalidParamsError.Code, "标题不能超过100个字")
+	}
+	if utf8.RuneCountInString(form.Desc) > 200 {
+		return errors.NewError(errors.InvalidParamsError.Code, "摘要不能超过200个字")
+	}
+	if form.Content == "" {
+		return errors.NewError(errors.InvalidParamsError.Code, "内容不能为空")
+	}
+
+	//敏感词
+	for _, field := range []*string{&form.Title, &form.Desc, &form.Content} {
+		text, err := moderate(*field, errors.ContentRejectedError)
+		if err != nil {
+			return err
+		}
+		*field = text
+	}
+
+	//未填写摘要时从内容中截取
+	if form.Desc == "" {
+		form.Desc = utils.Summary(utils.MarkdownToText(form.Content), articleSummaryLength)
+	}
+	return nil
+}
+
+// Create 创建草稿，同时保存标签
+func (s *articleService) Create(ctx context.Context, userId int64, form *ArticleForm) (*model.Article, error) {
+	if err := s.validate(form); err != nil {
+		return nil, err
+	}
+	article := &model.Article{
+		UserId:  userId,
+		Title:   form.Title,
+		Cid:     form.Cid,
+		Desc:    form.Desc,
+		Content: form.Content,
+		Img:     form.Img,
+		Status:  constants.ArticleStatusDraft,
+	}
+	err := simpleDb.Transaction(audit.AsActor(simpleDb.WithContext(ctx), userId), func(tx *gorm.DB) error {
+		err := s.saveSlug(s.generateSlug(tx, form.Slug, form.Title, 0), func(slug string) error {
+			article.Slug = slug
+			return dao.ArticleDao.Create(tx, article)
+		})
+		if err != nil {
+			return err
+		}
+		if err := RevisionService.record(tx, userId, nil, article); err != nil {
+			return err
+		}
+		return s.saveTags(tx, int64(article.ID), form.Tags, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// Edit 修改文章，只有作者或拥有article:manage权限的用户可以修改，已归档的文章不能修改，每次修改记录一个历史版本
+func (s *articleService) Edit(ctx context.Context, userId, articleId int64, form *ArticleForm) (*model.Article, error) {
+	article, err := s.getOwned(ctx, userId, articleId)
+	if err != nil {
+		return nil, err
+	}
+	// 审核中的内容不能修改
+	if article.Status == constants.ArticleStatusArchived || article.Status == constants.ArticleStatusPendingReview {
+		return nil, errors.ArticleStatusError
+	}
+	if err := s.validate(form); err != nil {
+		return nil, err
+	}
+	// 开启审核时修改审核通过或已发布的文章需要重新审核，已发布的文章改为草稿直到再次审核发布
+	reReview := config.Conf.ReviewConfig.Enable &&
+		(article.Status == constants.ArticleStatusApproved || article.Status == constants.ArticleStatusPublished)
+
+	err = simpleDb.Transaction(audit.AsActor(simpleDb.WithContext(ctx), userId), func(tx *gorm.DB) error {
+		columns := map[string]interface{}{
+			"title":   form.Title,
+			"cid":     form.Cid,
+			"desc":    form.Desc,
+			"content": form.Content,
+			"img":     form.Img,
+		}
+		if reReview {
+			columns["status"] = constants.ArticleStatusDraft
+		}
+		version := article.Version
+		if form.Version != nil {
+			version = *form.Version
+		}
+		slug := article.Slug
+		if form.Slug != "" && form.Slug != article.Slug {
+			slug = s.generateSlug(tx, form.Slug, form.Title, articleId)
+		}
+		err := s.saveSlug(slug, func(slug string) error {
+			if slug != article.Slug {
+				columns["slug"] = slug
+			}
+			return dao.ArticleDao.UpdatesWithVersion(tx, articleId, version, columns)
+		})
+		if err != nil {
+			return err
+		}
+		if reReview {
+			if err := s.logStatus(tx, userId, article, constants.ArticleStatusDraft, constants.ArticleActionEdit, ""); err != nil {
+				return err
+			}
+		}
+		saved := *article
+		saved.Title, saved.Cid, saved.Desc, saved.Content, saved.Img = form.Title, form.Cid, form.Desc, form.Content, form.Img
+		saved.Version = version + 1
+		if err := RevisionService.record(tx, userId, article, &saved); err != nil {
+			return err
+		}
+		// 没有提交标签时保留原有的标签，提交空数组时清空
+		if form.Tags == nil {
+			return nil
+		}
+		return s.saveTags(tx, articleId, form.Tags, true)
+	})
+	if stderrors.Is(err, simpleDb.ErrStaleObject) {
+		return nil, errors.StaleObjectError
+	}
+	if err != nil {
+		return nil, err
+	}
+	SearchService.SyncArticle(articleId)
+	return dao.ArticleDao.Get(simpleDb.WithContext(ctx), articleId), nil
+}
+
+// Publish 发布草稿，开启审核时只能发布审核通过的文章
+func (s *articleService) Publish(ctx context.Context, userId, articleId int64) error {
+	return s.transition(ctx, userId, articleId, constants.ArticleStatusPublished)
+}
+
+// Submit 提交审核，草稿或被驳回的文章可以提交
+func (s *articleService) Submit(ctx context.Context, userId, articleId int64) error {
+	return s.transition(ctx, userId, articleId, constants.ArticleStatusPendingReview)
+}
+
+// Withdraw 撤回审核中或被驳回的文章，改为草稿
+func (s *articleService) Withdraw(ctx context.Context, userId, articleId int64) error {
+	return s.transition(ctx, userId, articleId, constants.ArticleStatusDraft)
+}
+
+// Archive 归档已发布的文章
+func (s *articleService) Archive(ctx context.Context, userId, articleId int64) error {
+	return s.transition(ctx, userId, articleId, constants.ArticleStatusArchived)
+}
+
+// transition 作者修改文章状态，审核通过和驳回由ReviewService处理
+func (s *articleService) transition(ctx context.Context, userId, articleId int64, status int) error {
+	article, err := s.getOwned(ctx, userId, articleId)
+	if err != nil {
+		return err
+	}
+	if status == constants.ArticleStatusApproved || status == constants.ArticleStatusRejected ||
+		!s.canTransition(article.Status, status) {
+		return errors.ArticleStatusError
+	}
+	return s.changeStatus(ctx, userId, article, status, "")
+}
+
+// canTransition 状态是否可以从from流转到to
+func (s *articleService) canTransition(from, to int) bool {
+	if from == constants.ArticleStatusDraft && to == constants.ArticleStatusPublished && config.Conf.ReviewConfig.Enable {
+		return false
+	}
+	for _, status := range articleTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// changeStatus 修改文章状态并记录状态变更，remark为驳回原因。
+// 发布时清除定时发布时间，提交审核时清除上次的驳回原因
+func (s *articleService) changeStatus(ctx context.Context, userId int64, article *model.Article, status int, remark string) error {
+	articleId := int64(article.ID)
+	columns := map[string]interface{}{"status": status, "version": gorm.Expr("version + 1")}
+	switch status {
+	case constants.ArticleStatusPublished:
+		columns["published_at"] = time.Now().Unix()
+		columns["publish_at"] = 0
+	case constants.ArticleStatusPendingReview:
+		columns["reject_reason"] = ""
+	case constants.ArticleStatusApproved:
+		columns["reviewer_id"] = userId
+	case constants.ArticleStatusRejected:
+		columns["reviewer_id"] = userId
+		columns["reject_reason"] = remark
+	}
+	var event eventbus.ArticlePublished
+	err := simpleDb.Transaction(audit.AsActor(simpleDb.WithContext(ctx), userId), func(tx *gorm.DB) error {
+		// 按原状态和定时发布时间更新，避免并发修改状态或定时发布已被取消
+		ret := tx.Model(&model.Article{}).
+			Where("id = ? AND status = ? AND publish_at = ?", articleId, article.Status, article.PublishAt).
+			Updates(columns)
+		if ret.Error != nil {
+			return ret.Error
+		}
+		if ret.RowsAffected == 0 {
+			return errors.ArticleStatusError
+		}
+		if err := s.logStatus(tx, userId, article, status, articleActions[status], remark); err != nil {
+			return err
+		}
+		if status != constants.ArticleStatusPublished {
+			return nil
+		}
+		event = eventbus.ArticlePublished{
+			ArticleId:   articleId,
+			UserId:      userId,
+			PublishedAt: columns["published_at"].(int64),
+		}
+		// 和状态修改在同一事务中写入发件箱，由relay发送到消息中间件
+		return outbox.AddEvent(tx, event)
+	})
+	if err != nil {
+		return err
+	}
+	dao.ArticleDao.Forget(simpleDb.WithContext(ctx), articleId)
+	SearchService.SyncArticle(articleId)
+	if status == constants.ArticleStatusPublished {
+		_ = eventbus.Publish(context.Background(), event)
+	}
+	return nil
+}
+
+// logStatus 记录状态变更，需要和状态修改在同一个事务中调用
+func (s *articleService) logStatus(tx *gorm.DB, userId int64, article *model.Article, status int, action, remark string) error {
+	return dao.ArticleStatusLogDao.Create(tx, &model.ArticleStatusLog{
+		ArticleId:  int64(article.ID),
+		ActorId:    userId,
+		Action:     action,
+		FromStatus: article.Status,
+		ToStatus:   status,
+		Remark:     remark,
+	})
+}
+
+// ListPublished 已发布的文章，按发布时间倒序
+func (s *articleService) ListPublished(ctx context.Context, cid uint64, page, limit int) ([]model.Article, *simpleDb.Paging) {
+	cnd := simpleDb.NewSqlCnd().Eq("status", constants.ArticleStatusPublished).Desc("published_at").Desc("id").Page(page, limit)
+	if cid > 0 {
+		cnd.Eq("cid", cid)
+	}
+	return dao.ArticleDao.FindPageByCndCtx(ctx, simpleDb.WithContext(ctx), cnd)
+}
+
+// ListByUser 用户自己的文章，status小于0时返回全部状态
+func (s *articleService) ListByUser(ctx context.Context, userId int64, status, page, limit int) ([]model.Article, *simpleDb.Paging) {
+	cnd := simpleDb.NewSqlCnd().Eq("user_id", userId).Desc("id").Page(page, limit)
+	if status >= 0 {
+		cnd.Eq("status", status)
+	}
+	return dao.ArticleDao.FindPageByCnd(simpleDb.WithContext(ctx), cnd)
+}
+
+// AttachTags 为文章添加标签，只有作者或拥有article:manage权限的用户可以操作
+func (s *articleService) AttachTags(ctx context.Context, userId, articleId int64, names []string) error {
+	if _, err := s.getOwned(ctx, userId, articleId); err != nil {
+		return err
+	}
+	if err := TagService.Attach(ctx, articleId, names); err != nil {
+		return err
+	}
+	SearchService.SyncArticle(articleId)
+	return nil
+}
+
+// DetachTags 移除文章的标签
+func (s *articleService) DetachTags(ctx context.Context, userId, articleId int64, tagIds []int64) error {
+	if _, err := s.getOwned(ctx, userId, articleId); err != nil {
+		return err
+	}
+	if err := TagService.Detach(ctx, articleId, tagIds); err != nil {
+		return err
+	}
+	SearchService.SyncArticle(articleId)
+	return nil
+}
+
+// GetTags 文章的标签
+func (s *articleService) GetTags(ctx context.Context, articleId int64) []model.Tag {
+	return dao.TagDao.FindByArticleId(simpleDb.WithContext(ctx), articleId)
+}
+
+// getOwned 获取文章并校验是否有权限操作
+func (s *articleService) getOwned(ctx context.Context, userId, articleId int64) (*model.Article, error) {
+	article := dao.ArticleDao.Get(simpleDb.WithContext(ctx), articleId)
+	if article == nil {
+		return nil, errors.NotFoundError
+	}
+	if article.UserId != userId && !PermissionService.HasPermission(ctx, userId, articleManagePermission) {
+		return nil, errors.PermissionDeniedError
+	}
+	return article, nil
+}
+
+// generateSlug 优先使用指定的slug，否则根据标题生成(汉字转为拼音)，重复时追加随机后缀
+func (s *articleService) generateSlug(tx *gorm.DB, slug, title string, excludeId int64) string {
+	base := utils.Slugify(slug)
+	if base == "" {
+		base = utils.PinyinSlug(title)
+	}
+	if len(base) > 100 {
+		base = strings.Trim(base[:100], "-")
+	}
+	if base == "" {
+		// 标题没有字母数字和汉字时使用随机slug
+		return utils.UUID()[:12]
+	}
+	slug = base
+	for i := 0; i < 5 && dao.ArticleDao.SlugExists(tx, slug, excludeId); i++ {
+		slug = base + "-" + utils.UUID()[:6]
+	}
+	return slug
+}
+
+// slug唯一索引冲突时的重试次数
+const slugRetries = 3
+
+// saveSlug 按slug保存文章，并发保存相同的slug违反唯一索引时追加随机后缀重试。
+// 事务中可能读不到其他事务刚写入的slug，generateSlug的检查不能保证不重复
+func (s *articleService) saveSlug(slug string, save func(slug string) error) error {
+	candidate := slug
+	for i := 0; ; i++ {
+		err := save(candidate)
+		if err == nil || i >= slugRetries || !simpleDb.IsDuplicateKey(err, "uk_article_slug") {
+			return err
+		}
+		candidate = slug + "-" + utils.UUID()[:6]
+	}
+}
+
+// saveTags 保存文章标签，replace为true时先删除原有的标签
+func (s *articleService) saveTags(tx *gorm.DB, articleId int64, tags []string, replace bool) error {
+	if replace {
+		if err := dao.ArticleDao.DeleteArticleTags(tx, articleId); err != nil {
+			return err
+		}
+	}
+	tagIds, err := dao.TagDao.GetOrCreates(tx, tags)
+	if err != nil {
+		return err
+	}
+	return dao.ArticleDao.AddArticleTags(tx, articleId, tagIds)
 }
 
 // 发布文章
@@ -201,17 +646,17 @@ func (s *articleService) CreateArticle(article *model.Article) error {
 
 //以下方法没有优化，都是刚学习gorm时候的demo
 //使用gorm.Expr使用表达式
-func (a *articleService) IncrReadCount(id int) error {
+func (a *articleService) IncrReadCount(ctx context.Context, id int) error {
 	var art model.Article
-	return simpleDb.DB().Model(&art).Where("id = ?", id).UpdateColumn("read_count", gorm.Expr("read_count + ?", 1)).Error
+	return simpleDb.WithContext(ctx).Model(&art).Where("id = ?", id).UpdateColumn("read_count", gorm.Expr("read_count + ?", 1)).Error
 }
 
 //下面这些函数 都可以迁移到services中去
 // GetFromID 通过id获取内容 Primary key
-func (a *articleService) GetArticleById(id int) (model.Article, error) {
+func (a *articleService) GetArticleById(ctx context.Context, id int) (model.Article, error) {
 	var result model.Article
 	//First、Take、Last  没有找到记录时，它会返回 ErrRecordNotFound 错误
-	res := simpleDb.DB().Preload("Category").First(&result, id)
+	res := simpleDb.WithContext(ctx).Preload("Category").First(&result, id)
 
 	//res := db.Table(obj.TableName()).Preload("Category").Where("id = ?", id).Find(&result)
 	//res.RowsAffected //返回找到的记录数，相当于 `len(users)`
@@ -222,12 +667,12 @@ func (a *articleService) GetArticleById(id int) (model.Article, error) {
 }
 
 //获取文章列表
-func (a *articleService) GetArticleList(condition map[string]string, pageSize int, page int) ([]model.Article, int64) {
+func (a *articleService) GetArticleList(ctx context.Context, condition map[string]string, pageSize int, page int) ([]model.Article, int64) {
 	var articleList []model.Article
 	var total int64
 	column := "id,title,`cid`,`desc`,img,comment_count,read_count,created_at,updated_at"
 	//column := "id,title, img,  `desc`, comment_count, read_count"
-	tx := simpleDb.DB().Table("article").
+	tx := simpleDb.WithContext(ctx).Table("article").
 		Preload("Category").
 		Select(column).
 		Limit(pageSize).
@@ -246,12 +691,12 @@ func (a *articleService) GetArticleList(condition map[string]string, pageSize in
 	return articleList, total
 }
 
-func (a *articleService) GetArticleList2(condition map[string]string, pageSize int, page int) ([]map[string]interface{}, int64) {
+func (a *articleService) GetArticleList2(ctx context.Context, condition map[string]string, pageSize int, page int) ([]map[string]interface{}, int64) {
 	var articleList []map[string]interface{}
 	var total int64
 	column := "id,title,created_at"
 	//column := "id,title, img,  `desc`, comment_count, read_count"
-	tx := simpleDb.DB().Table("article").
+	tx := simpleDb.WithContext(ctx).Table("article").
 		Select(column).
 		Limit(pageSize).
 		Offset((page - 1) * pageSize).
@@ -269,11 +714,11 @@ func (a *articleService) GetArticleList2(condition map[string]string, pageSize i
 	return articleList, total
 }
 
-func (a *articleService) SearchArticle(title string, pageSize int, page int) ([]model.Article, int64) {
+func (a *articleService) SearchArticle(ctx context.Context, title string, pageSize int, page int) ([]model.Article, int64) {
 	var articleList []model.Article
 	var total int64
 	column := "id,title,cid,`desc`,img,comment_count,read_count,created_at,updated_at"
-	err := simpleDb.DB().Table("article").
+	err := simpleDb.WithContext(ctx).Table("article").
 		Select(column).
 		Where("title Like ?", title+"%").
 		Limit(pageSize).
@@ -287,11 +732,11 @@ func (a *articleService) SearchArticle(title string, pageSize int, page int) ([]
 	return articleList, total
 }
 
-func (a *articleService) SearchArticle2(title string, pageSize int, page int) ([]model.Article, int64, error) {
+func (a *articleService) SearchArticle2(ctx context.Context, title string, pageSize int, page int) ([]model.Article, int64, error) {
 	var articleList []model.Article
 	var total int64
 	column := "id,title,cid,`desc`,img,comment_count,read_count,created_at,updated_at"
-	result := simpleDb.DB().Table("article").
+	result := simpleDb.WithContext(ctx).Table("article").
 		Select(column).
 		Where("title Like ?", title+"%").
 		Limit(pageSize).
@@ -305,15 +750,15 @@ func (a *articleService) SearchArticle2(title string, pageSize int, page int) ([
 	return articleList, total, result.Error
 }
 
-func (a *articleService) createArticle(data *model.Article) (bool, error) {
-	err := simpleDb.DB().Create(&data).Error
+func (a *articleService) createArticle(ctx context.Context, data *model.Article) (bool, error) {
+	err := simpleDb.WithContext(ctx).Create(&data).Error
 	if err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
-func (a *articleService) EditArticle(id int, data *model.Article) (bool, error) {
+func (a *articleService) EditArticle(ctx context.Context, id int, data *model.Article) (bool, error) {
 	var art model.Article
 	var column = make(map[string]interface{})
 	column["title"] = data.Title
@@ -321,7 +766,7 @@ func (a *articleService) EditArticle(id int, data *model.Article) (bool, error)
 	column["desc"] = data.Desc
 	column["content"] = data.Content
 	column["img"] = data.Img
-	result := simpleDb.DB().Debug().Model(&art).Where("id=?", id).Updates(column)
+	result := simpleDb.WithContext(ctx).Debug().Model(&art).Where("id=?", id).Updates(column)
 	if result.Error != nil {
 		return false, result.Error
 	}
@@ -332,9 +777,9 @@ func (a *articleService) EditArticle(id int, data *model.Article) (bool, error)
 }
 
 // 删除文章
-func (a *articleService) DeleteArt(id int) (bool, error) {
+func (a *articleService) DeleteArt(ctx context.Context, id int) (bool, error) {
 	var art model.Article
-	err := simpleDb.DB().Where("id = ? ", id).Delete(&art).Error
+	err := simpleDb.WithContext(ctx).Where("id = ? ", id).Delete(&art).Error
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/pkg/simpleDb"
+)
+
+var AuditLogService = newAuditLogService()
+
+func newAuditLogService() *auditLogService {
+	return &auditLogService{}
+}
+
+type auditLogService struct {
+}
+
+func (s *auditLogService) Get(ctx context.Context, id int64) *model.AuditLog {
+	return dao.AuditLogDao.Get(simpleDb.WithContext(ctx), id)
+}
+
+func (s *auditLogService) FindPageByCnd(ctx context.Context, cnd *simpleDb.SqlCnd) (list []model.AuditLog, paging *simpleDb.Paging) {
+	return dao.AuditLogDao.FindPageByCnd(simpleDb.WithContext(ctx), cnd)
+}
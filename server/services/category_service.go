@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/simpleDb"
+	"strings"
+	"unicode/utf8"
+)
+
+var CategoryService = newCategoryService()
+
+func newCategoryService() *categoryService {
+	return &categoryService{}
+}
+
+type categoryService struct {
+}
+
+// CategoryCount 分类及其已发布的文章数
+type CategoryCount struct {
+	ID           uint64 `json:"id"`
+	Name         string `json:"name"`
+	ArticleCount int64  `json:"articleCount"`
+}
+
+func (s *categoryService) Get(ctx context.Context, id uint64) *model.Category {
+	return dao.CategoryDao.Get(simpleDb.WithContext(ctx), id)
+}
+
+// List 所有分类及其已发布的文章数
+func (s *categoryService) List(ctx context.Context) []CategoryCount {
+	db := simpleDb.WithContext(ctx)
+	counts := dao.CategoryDao.CountArticles(db, constants.ArticleStatusPublished)
+	list := dao.CategoryDao.FindAll(db)
+	ret := make([]CategoryCount, 0, len(list))
+	for _, c := range list {
+		ret = append(ret, CategoryCount{ID: c.ID, Name: c.Name, ArticleCount: counts[c.ID]})
+	}
+	return ret
+}
+
+func (s *categoryService) validate(ctx context.Context, name string, excludeId uint64) error {
+	if name == "" {
+		return errors.NewError(errors.InvalidParamsError.Code, "分类名称不能为空")
+	}
+	if utf8.RuneCountInString(name) > 20 {
+		return errors.NewError(errors.InvalidParamsError.Code, "分类名称不能超过20个字")
+	}
+	if dao.CategoryDao.NameExists(simpleDb.WithContext(ctx), name, excludeId) {
+		return errors.NewError(errors.InvalidParamsError.Code, "分类已存在")
+	}
+	return nil
+}
+
+func (s *categoryService) Create(ctx context.Context, name string) (*model.Category, error) {
+	name = strings.TrimSpace(name)
+	if err := s.validate(ctx, name, 0); err != nil {
+		return nil, err
+	}
+	category := &model.Category{Name: name}
+	if err := dao.CategoryDao.Create(simpleDb.WithContext(ctx), category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *categoryService) Update(ctx context.Context, id uint64, name string) error {
+	name = strings.TrimSpace(name)
+	if s.Get(ctx, id) == nil {
+		return errors.NotFoundError
+	}
+	if err := s.validate(ctx, name, id); err != nil {
+		return err
+	}
+	return dao.CategoryDao.Updates(simpleDb.WithContext(ctx), id, map[string]interface{}{"name": name})
+}
+
+// Delete 删除分类，分类下还有文章时不能删除
+func (s *categoryService) Delete(ctx context.Context, id uint64) error {
+	var count int64
+	simpleDb.WithContext(ctx).Model(&model.Article{}).Where("cid = ?", id).Count(&count)
+	if count > 0 {
+		return errors.NewError(errors.InvalidParamsError.Code, "分类下还有文章，不能删除")
+	}
+	return dao.CategoryDao.Delete(simpleDb.WithContext(ctx), id)
+}
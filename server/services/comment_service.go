@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/eventbus"
+	"go-skeleton/pkg/simpleDb"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+var CommentService = newCommentService()
+
+func newCommentService() *commentService {
+	return &commentService{}
+}
+
+// CommentFilter 评论保存前的检查，返回错误时拒绝评论，可用于反垃圾、敏感词过滤，也可以修改评论内容
+type CommentFilter func(comment *model.Comment) error
+
+type commentService struct {
+	mu      sync.RWMutex
+	filters []CommentFilter
+}
+
+// 管理其他用户评论的权限
+const commentManagePermission = "comment:manage"
+
+// 列表中每个一级评论预览的回复数
+const commentReplyPreview = 3
+
+// AddFilter 注册评论检查，按注册顺序执行
+func (s *commentService) AddFilter(filter CommentFilter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.filters = append(s.filters, filter)
+}
+
+func (s *commentService) filter(comment *model.Comment) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, f := range s.filters {
+		if err := f(comment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *commentService) Get(ctx context.Context, id int64) *model.Comment {
+	return dao.CommentDao.Get(simpleDb.WithContext(ctx), id)
+}
+
+// Create 发表评论，parentId大于0时为回复，文章的评论数和一级评论的回复数在同一事务中原子更新
+func (s *commentService) Create(ctx context.Context, userId, articleId, parentId int64, content string) (*model.Comment, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.NewError(errors.InvalidParamsError.Code, "评论内容不能为空")
+	}
+	if utf8.RuneCountInString(content) > 1000 {
+		return nil, errors.NewError(errors.InvalidParamsError.Code, "评论内容不能超过1000个字")
+	}
+	article := dao.ArticleDao.Get(simpleDb.WithContext(ctx), articleId)
+	if article == nil || article.Status != constants.ArticleStatusPublished {
+		return nil, errors.NotFoundError
+	}
+
+	comment := &model.Comment{
+		ArticleId: articleId,
+		UserId:    userId,
+		Content:   content,
+		Status:    constants.StatusOk,
+	}
+	if parentId > 0 {
+		parent := s.Get(ctx, parentId)
+		if parent == nil || parent.ArticleId != articleId {
+			return nil, errors.NotFoundError
+		}
+		comment.ParentId = parentId
+		comment.ReplyUserId = parent.UserId
+		comment.RootId = parent.RootId
+		if comment.RootId == 0 {
+			comment.RootId = parentId
+		}
+	}
+	if err := s.filter(comment); err != nil {
+		return nil, err
+	}
+
+	err := simpleDb.Transaction(simpleDb.WithContext(ctx), func(tx *gorm.DB) error {
+		if err := dao.CommentDao.Create(tx, comment); err != nil {
+			return err
+		}
+		if comment.RootId > 0 {
+			if err := dao.CommentDao.IncrReplyCount(tx, comment.RootId, 1); err != nil {
+				return err
+			}
+		}
+		return dao.ArticleDao.IncrCommentCount(tx, articleId, 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	_ = eventbus.Publish(context.Background(), eventbus.CommentCreated{
+		CommentId:   int64(comment.ID),
+		ArticleId:   articleId,
+		UserId:      userId,
+		ReplyUserId: comment.ReplyUserId,
+		Content:     comment.Content,
+	})
+	return comment, nil
+}
+
+// Delete 软删除评论，删除一级评论时同时删除其回复，只有评论者或拥有comment:manage权限的用户可以删除
+func (s *commentService) Delete(ctx context.Context, userId, id int64) error {
+	comment := s.Get(ctx, id)
+	if comment == nil {
+		return errors.NotFoundError
+	}
+	if comment.UserId != userId && !PermissionService.HasPermission(ctx, userId, commentManagePermission) {
+		return errors.PermissionDeniedError
+	}
+
+	return simpleDb.Transaction(simpleDb.WithContext(ctx), func(tx *gorm.DB) error {
+		deleted, err := dao.CommentDao.Delete(tx, id)
+		if err != nil || deleted == 0 {
+			return err
+		}
+		if comment.RootId > 0 {
+			if err := dao.CommentDao.IncrReplyCount(tx, comment.RootId, -1); err != nil {
+				return err
+			}
+		} else {
+			replies, err := dao.CommentDao.DeleteReplies(tx, id)
+			if err != nil {
+				return err
+			}
+			deleted += replies
+		}
+		return dao.ArticleDao.IncrCommentCount(tx, comment.ArticleId, -deleted)
+	})
+}
+
+// ListByArticle 文章的一级评论，按时间倒序分页，每条附带最早的几条回复
+func (s *commentService) ListByArticle(ctx context.Context, articleId int64, page, limit int) ([]model.CommentThread, *simpleDb.Paging) {
+	cnd := simpleDb.NewSqlCnd().Eq("article_id", articleId).Eq("root_id", 0).Desc("id").Page(page, limit)
+	roots, paging := dao.CommentDao.FindPageByCnd(simpleDb.WithContext(ctx), cnd)
+
+	rootIds := make([]int64, 0, len(roots))
+	for _, root := range roots {
+		if root.ReplyCount > 0 {
+			rootIds = append(rootIds, int64(root.ID))
+		}
+	}
+	replies := dao.CommentDao.FindReplies(simpleDb.WithContext(ctx), rootIds, commentReplyPreview)
+
+	list := make([]model.CommentThread, 0, len(roots))
+	for _, root := range roots {
+		list = append(list, model.CommentThread{Comment: root, Replies: replies[int64(root.ID)]})
+	}
+	return list, paging
+}
+
+// ListReplies 一级评论下的回复，按时间正序分页
+func (s *commentService) ListReplies(ctx context.Context, rootId int64, page, limit int) ([]model.Comment, *simpleDb.Paging) {
+	cnd := simpleDb.NewSqlCnd().Eq("root_id", rootId).Asc("id").Page(page, limit)
+	return dao.CommentDao.FindPageByCnd(simpleDb.WithContext(ctx), cnd)
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/pkg/gcache"
+	"go-skeleton/pkg/simpleDb"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+var DashboardService = newDashboardService()
+
+func newDashboardService() *dashboardService {
+	return &dashboardService{
+		cache: gcache.NewCached("dashboard", 10*time.Minute).PerTenant(),
+	}
+}
+
+type dashboardService struct {
+	cache *gcache.Cached
+}
+
+const (
+	// DashboardDays 按天统计的天数，包含今天
+	DashboardDays = 30
+	// 最近审计日志的条数
+	dashboardAuditLimit = 20
+	dashboardCacheId    = "stats"
+)
+
+// DashboardDaily 某一天新增的数据量
+type DashboardDaily struct {
+	Date     string `json:"date"`
+	Articles int64  `json:"articles"`
+	Users    int64  `json:"users"`
+	Comments int64  `json:"comments"`
+}
+
+// DashboardStats 后台首页统计，由定时任务刷新到缓存
+type DashboardStats struct {
+	Totals    map[string]int64 `json:"totals"` // 表名 => 行数
+	Daily     []DashboardDaily `json:"daily"`  // 最近DashboardDays天，按日期升序
+	AuditLogs []model.AuditLog `json:"auditLogs"`
+	UpdatedAt int64            `json:"updatedAt"`
+}
+
+// 统计行数的表
+var dashboardTables = []schema.Tabler{
+	&model.User{},
+	&model.Article{},
+	&model.Comment{},
+	&model.Category{},
+	&model.Tag{},
+	&model.AuditLog{},
+}
+
+// Stats 读取统计数据，缓存未命中时实时统计并写入缓存
+func (s *dashboardService) Stats(ctx context.Context) (*DashboardStats, error) {
+	stats := &DashboardStats{}
+	err := s.cache.Get(ctx, dashboardCacheId, stats, func() (interface{}, error) {
+		return s.compute(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Refresh 重新统计并写入缓存，由定时任务dashboard.refresh调用
+func (s *dashboardService) Refresh(ctx context.Context) error {
+	stats, err := s.compute(ctx)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, dashboardCacheId, stats)
+}
+
+func (s *dashboardService) compute(ctx context.Context) (*DashboardStats, error) {
+	db := simpleDb.WithContext(ctx)
+	stats := &DashboardStats{
+		Totals:    make(map[string]int64, len(dashboardTables)),
+		UpdatedAt: time.Now().Unix(),
+	}
+
+	for _, table := range dashboardTables {
+		count, err := dao.StatDao.Count(db, table)
+		if err != nil {
+			return nil, err
+		}
+		stats.Totals[table.TableName()] = count
+	}
+
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day()-DashboardDays+1, 0, 0, 0, 0, now.Location())
+	days := make([]DashboardDaily, DashboardDays)
+	index := make(map[string]*DashboardDaily, DashboardDays)
+	for i := range days {
+		days[i].Date = since.AddDate(0, 0, i).Format("2006-01-02")
+		index[days[i].Date] = &days[i]
+	}
+	series := []struct {
+		model interface{}
+		set   func(d *DashboardDaily, n int64)
+	}{
+		{&model.Article{}, func(d *DashboardDaily, n int64) { d.Articles = n }},
+		{&model.User{}, func(d *DashboardDaily, n int64) { d.Users = n }},
+		{&model.Comment{}, func(d *DashboardDaily, n int64) { d.Comments = n }},
+	}
+	for _, item := range series {
+		list, err := dao.StatDao.CountByDay(db, item.model, since)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range list {
+			if d, ok := index[row.Date]; ok {
+				item.set(d, row.Count)
+			}
+		}
+	}
+	stats.Daily = days
+
+	stats.AuditLogs = dao.AuditLogDao.Find(db, simpleDb.NewSqlCnd().Desc("id").Limit(dashboardAuditLimit))
+	return stats, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"go-skeleton/model"
+	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/utils"
+)
+
+var EncryptService = newEncryptService()
+
+func newEncryptService() *encryptService {
+	return &encryptService{}
+}
+
+type encryptService struct {
+}
+
+// 使用model.EncryptedString保存的列
+var encryptedColumns = []struct {
+	table, column string
+}{
+	{"user", "phone"},
+}
+
+// 重新加密时每批的行数
+const rotateBatchSize = 500
+
+// Rotate 将不是当前密钥加密的值(包括未加密的旧数据)使用当前密钥重新加密，返回修改的行数，
+// 通过db.Table修改，不记录审计日志、不修改updated_at
+func (s *encryptService) Rotate(ctx context.Context) (int, error) {
+	current := model.EncryptKeyId()
+	if current == "" {
+		return 0, model.ErrNoEncryptKey
+	}
+	db := simpleDb.WithContext(ctx)
+	total := 0
+	for _, c := range encryptedColumns {
+		var lastId int64
+		for {
+			var rows []struct {
+				Id    int64
+				Value string
+			}
+			err := db.Table(c.table).Select("id, "+c.column+" AS value").
+				Where("id > ? AND "+c.column+" <> ''", lastId).
+				Order("id ASC").Limit(rotateBatchSize).Scan(&rows).Error
+			if err != nil {
+				return total, err
+			}
+			if len(rows) == 0 {
+				break
+			}
+			for _, row := range rows {
+				if id, ok := utils.KeyId(row.Value); ok && id == current {
+					continue
+				}
+				var value model.EncryptedString
+				if err := value.Scan(row.Value); err != nil {
+					return total, err
+				}
+				err := db.Table(c.table).Where("id = ?", row.Id).UpdateColumn(c.column, value).Error
+				if err != nil {
+					return total, err
+				}
+				total++
+			}
+			lastId = rows[len(rows)-1].Id
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/featureflag"
+	"go-skeleton/pkg/simpleDb"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+var FeatureFlagService = newFeatureFlagService()
+
+func newFeatureFlagService() *featureFlagService {
+	return &featureFlagService{}
+}
+
+type featureFlagService struct {
+}
+
+// 开关名称，配置文件中的key会被viper转换为小写，所以只允许小写
+var featureFlagNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_.]{0,63}$`)
+
+func (s *featureFlagService) Get(ctx context.Context, id int64) *model.FeatureFlag {
+	return dao.FeatureFlagDao.Get(simpleDb.WithContext(ctx), id)
+}
+
+// List feature_flag表中的开关，不包含配置文件中的开关
+func (s *featureFlagService) List(ctx context.Context) []model.FeatureFlag {
+	return dao.FeatureFlagDao.FindAll(simpleDb.WithContext(ctx))
+}
+
+// Create 创建开关，成功后通知所有实例重新加载
+func (s *featureFlagService) Create(ctx context.Context, flag *model.FeatureFlag) error {
+	flag.ID = 0
+	if err := s.validate(ctx, flag); err != nil {
+		return err
+	}
+	if err := dao.FeatureFlagDao.Create(simpleDb.WithContext(ctx), flag); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// Update 修改开关，成功后通知所有实例重新加载
+func (s *featureFlagService) Update(ctx context.Context, flag *model.FeatureFlag) error {
+	old := s.Get(ctx, flag.ID)
+	if old == nil {
+		return errors.NotFoundError
+	}
+	if err := s.validate(ctx, flag); err != nil {
+		return err
+	}
+	flag.CreatedAt = old.CreatedAt
+	if err := dao.FeatureFlagDao.Save(simpleDb.WithContext(ctx), flag); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// Delete 删除开关，配置文件中有同名的开关时恢复使用配置文件中的
+func (s *featureFlagService) Delete(ctx context.Context, id int64) error {
+	if s.Get(ctx, id) == nil {
+		return errors.NotFoundError
+	}
+	if err := dao.FeatureFlagDao.Delete(simpleDb.WithContext(ctx), id); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+func (s *featureFlagService) validate(ctx context.Context, flag *model.FeatureFlag) error {
+	flag.Name = strings.TrimSpace(flag.Name)
+	if !featureFlagNameRegexp.MatchString(flag.Name) {
+		return errors.NewError(errors.InvalidParamsError.Code, "开关名称只能包含小写字母、数字、_和.，并以字母开头")
+	}
+	if flag.Percentage < 0 || flag.Percentage > 100 {
+		return errors.NewError(errors.InvalidParamsError.Code, "灰度比例需要在0~100之间")
+	}
+	users := featureflag.ParseUsers(flag.Users)
+	ids := make([]string, 0, len(users))
+	for _, id := range users {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	flag.Users = strings.Join(ids, ",")
+	flag.Roles = strings.Join(featureflag.ParseRoles(flag.Roles), ",")
+	if len(flag.Roles) > 255 {
+		return errors.NewError(errors.InvalidParamsError.Code, "角色过多")
+	}
+	if dao.FeatureFlagDao.NameExists(simpleDb.WithContext(ctx), flag.Name, flag.ID) {
+		return errors.NewError(errors.InvalidParamsError.Code, "开关已存在")
+	}
+	return nil
+}
+
+// invalidate 数据已经保存，重新加载失败时等待定期加载
+func (s *featureFlagService) invalidate() {
+	if err := featureflag.Invalidate(context.Background()); err != nil {
+		zap.L().Error("invalidate feature flags failed", zap.Error(err))
+	}
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"go-skeleton/model"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/sensitive"
+)
+
+// moderate 按sensitive配置处理文本中的敏感词，reject模式下命中时返回rejectErr，replace模式下返回替换后的文本，
+// 未启用敏感词过滤时原样返回
+func moderate(text string, rejectErr *errors.CodeError) (string, error) {
+	dict := sensitive.Default()
+	if dict == nil || text == "" {
+		return text, nil
+	}
+	clean, hits := dict.Filter(text)
+	if len(hits) == 0 {
+		return text, nil
+	}
+	if dict.Mode() == sensitive.ModeReject {
+		return "", rejectErr
+	}
+	return clean, nil
+}
+
+// SensitiveCommentFilter 评论的敏感词过滤，启用sensitive时通过CommentService.AddFilter注册
+func SensitiveCommentFilter(comment *model.Comment) error {
+	content, err := moderate(comment.Content, errors.CommentRejectedError)
+	if err != nil {
+		return err
+	}
+	comment.Content = content
+	return nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/eventbus"
+	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/pkg/ws"
+	"go-skeleton/utils"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var NotificationService = newNotificationService()
+
+// 推送给websocket客户端的消息类型
+const wsNotification = "notification"
+
+// 通知中内容摘要的长度
+const notificationContentLength = 100
+
+func init() {
+	eventbus.SubscribeAsync(eventbus.CommentCreated{}, "notification", func(ctx context.Context, e eventbus.Event) error {
+		return NotificationService.onCommentCreated(ctx, e.(eventbus.CommentCreated))
+	})
+	eventbus.SubscribeAsync(eventbus.ArticleLiked{}, "notification", func(ctx context.Context, e eventbus.Event) error {
+		return NotificationService.onArticleLiked(ctx, e.(eventbus.ArticleLiked))
+	})
+}
+
+func newNotificationService() *notificationService {
+	return &notificationService{}
+}
+
+type notificationService struct {
+}
+
+// NotificationPush 推送给用户的新通知及未读数
+type NotificationPush struct {
+	Notification *model.Notification `json:"notification"`
+	Unread       int64               `json:"unread"`
+}
+
+// Notify 保存通知并通过websocket推送给在线的接收人，接收人和触发人相同时不通知
+func (s *notificationService) Notify(ctx context.Context, n *model.Notification) error {
+	if n.UserId <= 0 || n.UserId == n.ActorId {
+		return nil
+	}
+	n.Content = utils.Summary(n.Content, notificationContentLength)
+	if err := dao.NotificationDao.Create(simpleDb.WithContext(ctx), n); err != nil {
+		return err
+	}
+	s.push(ctx, n.UserId, n)
+	return nil
+}
+
+// push 推送失败不影响通知保存，用户下次打开通知列表时可以看到
+func (s *notificationService) push(ctx context.Context, userId int64, n *model.Notification) {
+	unread, err := s.UnreadCount(ctx, userId)
+	var msg *ws.Message
+	if err == nil {
+		msg, err = ws.NewMessage(wsNotification, NotificationPush{
+			Notification: n,
+			Unread:       unread,
+		})
+	}
+	if err == nil {
+		err = ws.SendToUser(ctx, msg, userId)
+	}
+	if err != nil {
+		zap.L().Warn("push notification failed", zap.Int64("userId", userId), zap.Error(err))
+	}
+}
+
+// List 用户的通知，按时间倒序，unreadOnly为true时只返回未读的
+func (s *notificationService) List(ctx context.Context, userId int64, unreadOnly bool, page, limit int) ([]model.Notification, *simpleDb.Paging, error) {
+	cnd := simpleDb.NewSqlCnd().Eq("user_id", userId).Desc("id").Page(page, limit)
+	if unreadOnly {
+		cnd.Eq("read_at", 0)
+	}
+	return dao.NotificationDao.FindPageByCnd(simpleDb.WithContext(ctx), userId, cnd)
+}
+
+// UnreadCount 用户的未读通知数
+func (s *notificationService) UnreadCount(ctx context.Context, userId int64) (int64, error) {
+	return dao.NotificationDao.CountUnread(simpleDb.WithContext(ctx), userId)
+}
+
+// Version 用户通知的版本，客户端轮询时内容未变化返回304
+func (s *notificationService) Version(ctx context.Context, userId int64) (string, error) {
+	return dao.NotificationDao.Version(simpleDb.WithContext(ctx), userId)
+}
+
+// MarkRead 将用户的通知标记为已读，只会修改属于该用户的通知
+func (s *notificationService) MarkRead(ctx context.Context, userId int64, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := dao.NotificationDao.MarkRead(simpleDb.WithContext(ctx), userId, ids, time.Now().Unix())
+	return err
+}
+
+// MarkAllRead 将用户所有未读的通知标记为已读
+func (s *notificationService) MarkAllRead(ctx context.Context, userId int64) error {
+	_, err := dao.NotificationDao.MarkRead(simpleDb.WithContext(ctx), userId, nil, time.Now().Unix())
+	return err
+}
+
+// onCommentCreated 回复时通知被回复的用户，一级评论通知文章作者
+func (s *notificationService) onCommentCreated(ctx context.Context, e eventbus.CommentCreated) error {
+	n := &model.Notification{
+		UserId:    e.ReplyUserId,
+		ActorId:   e.UserId,
+		Type:      constants.NotificationCommentReply,
+		ArticleId: e.ArticleId,
+		CommentId: e.CommentId,
+		Content:   e.Content,
+	}
+	if e.ReplyUserId == 0 {
+		article := dao.ArticleDao.Get(simpleDb.WithContext(ctx), e.ArticleId)
+		if article == nil {
+			return nil
+		}
+		n.UserId = article.UserId
+		n.Type = constants.NotificationArticleComment
+	}
+	return s.Notify(ctx, n)
+}
+
+// onArticleLiked 通知文章作者，内容为文章标题
+func (s *notificationService) onArticleLiked(ctx context.Context, e eventbus.ArticleLiked) error {
+	article := dao.ArticleDao.Get(simpleDb.WithContext(ctx), e.ArticleId)
+	if article == nil {
+		return nil
+	}
+	return s.Notify(ctx, &model.Notification{
+		UserId:    article.UserId,
+		ActorId:   e.UserId,
+		Type:      constants.NotificationArticleLiked,
+		ArticleId: e.ArticleId,
+		Content:   article.Title,
+	})
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/eventbus"
+	"go-skeleton/pkg/oauth"
+	"go-skeleton/pkg/simpleDb"
+
+	"gorm.io/gorm"
+)
+
+var OAuthService = newOAuthService()
+
+func newOAuthService() *oauthService {
+	return &oauthService{}
+}
+
+// 第三方登录及账号绑定
+type oauthService struct {
+}
+
+// AuthURL 第三方授权页面的地址及state，uid不为0时授权后绑定到该用户，
+// 调用方需要通过oauth.SetStateCookie将state绑定到浏览器
+func (s *oauthService) AuthURL(ctx context.Context, provider string, uid int64) (string, string, error) {
+	p, err := oauth.GetProvider(provider)
+	if err != nil {
+		return "", "", err
+	}
+	state, err := oauth.NewState(ctx, provider, uid)
+	if err != nil {
+		return "", "", err
+	}
+	return p.AuthURL(state), state, nil
+}
+
+// exchange 校验state并用授权码换取第三方用户信息，返回生成state时的uid
+func (s *oauthService) exchange(ctx context.Context, provider, code, state string) (*oauth.UserInfo, int64, error) {
+	p, err := oauth.GetProvider(provider)
+	if err != nil {
+		return nil, 0, err
+	}
+	uid, err := oauth.VerifyState(ctx, provider, state)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := p.Exchange(ctx, code)
+	if err != nil {
+		return nil, 0, err
+	}
+	if info.OpenId == "" {
+		return nil, 0, errors.OAuthStateError
+	}
+	return info, uid, nil
+}
+
+// Login 第三方登录，未绑定的第三方账号自动注册新用户
+func (s *oauthService) Login(ctx context.Context, provider, code, state, ip string) (*model.User, error) {
+	info, uid, err := s.exchange(ctx, provider, code, state)
+	if err != nil {
+		return nil, err
+	}
+	// 绑定用的state不能用于登录
+	if uid != 0 {
+		return nil, errors.OAuthStateError
+	}
+
+	var user *model.User
+	if binding := dao.UserOAuthDao.GetByOpenId(simpleDb.WithContext(ctx), provider, info.OpenId); binding != nil {
+		user = UserService.Get(ctx, binding.UserId)
+		_ = dao.UserOAuthDao.Updates(simpleDb.WithContext(ctx), int64(binding.ID), map[string]interface{}{
+			"union_id": info.UnionId,
+			"nickname": info.Nickname,
+			"avatar":   info.Avatar,
+		})
+	} else {
+		user, err = s.signUp(ctx, info)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if user == nil {
+		return nil, errors.NotFoundError
+	}
+	if user.Status == constants.UserStatusDisabled {
+		return nil, errors.UserDisabledError
+	}
+	UserService.recordLogin(ctx, user, ip)
+	return user, nil
+}
+
+// signUp 使用第三方账号注册，不设置密码，用户名随机生成
+func (s *oauthService) signUp(ctx context.Context, info *oauth.UserInfo) (*model.User, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	username := info.Provider
+	if len(username) > 11 {
+		username = username[:11]
+	}
+	user := &model.User{
+		Username: username + "_" + hex.EncodeToString(b),
+		Nickname: info.Nickname,
+		Avatar:   info.Avatar,
+		Email:    info.Email,
+		Status:   constants.UserStatusNormal,
+	}
+	err := simpleDb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := dao.UserDao.Create(tx, user); err != nil {
+			return err
+		}
+		if err := TenantService.joinCurrent(tx, int64(user.ID)); err != nil {
+			return err
+		}
+		return dao.UserOAuthDao.Create(tx, newUserOAuth(int64(user.ID), info))
+	})
+	if err != nil {
+		return nil, err
+	}
+	_ = eventbus.Publish(context.Background(), eventbus.UserRegistered{
+		UserId:   int64(user.ID),
+		Username: user.Username,
+		Provider: info.Provider,
+	})
+	return user, nil
+}
+
+func newUserOAuth(userId int64, info *oauth.UserInfo) *model.UserOAuth {
+	return &model.UserOAuth{
+		UserId:   userId,
+		Provider: info.Provider,
+		OpenId:   info.OpenId,
+		UnionId:  info.UnionId,
+		Nickname: info.Nickname,
+		Avatar:   info.Avatar,
+	}
+}
+
+// Bind 绑定第三方账号，state需要由同一用户生成
+func (s *oauthService) Bind(ctx context.Context, userId int64, provider, code, state string) (*model.UserOAuth, error) {
+	info, uid, err := s.exchange(ctx, provider, code, state)
+	if err != nil {
+		return nil, err
+	}
+	if uid != userId {
+		return nil, errors.OAuthStateError
+	}
+	if binding := dao.UserOAuthDao.GetByOpenId(simpleDb.WithContext(ctx), provider, info.OpenId); binding != nil {
+		if binding.UserId != userId {
+			return nil, errors.OAuthBoundError
+		}
+		return binding, nil
+	}
+	if dao.UserOAuthDao.GetByUserId(simpleDb.WithContext(ctx), userId, provider) != nil {
+		return nil, errors.NewError(errors.InvalidParamsError.Code, "已绑定该平台的其他账号，请先解绑")
+	}
+	binding := newUserOAuth(userId, info)
+	if err := dao.UserOAuthDao.Create(simpleDb.WithContext(ctx), binding); err != nil {
+		return nil, err
+	}
+	return binding, nil
+}
+
+// Unbind 解绑第三方账号，未设置密码时至少保留一个第三方账号用于登录
+func (s *oauthService) Unbind(ctx context.Context, userId int64, provider string) error {
+	if dao.UserOAuthDao.GetByUserId(simpleDb.WithContext(ctx), userId, provider) == nil {
+		return errors.NotFoundError
+	}
+	user := UserService.Get(ctx, userId)
+	if user == nil {
+		return errors.NotFoundError
+	}
+	if user.Password == "" && len(s.Bindings(ctx, userId)) <= 1 {
+		return errors.NewError(errors.InvalidParamsError.Code, "请先设置密码再解绑")
+	}
+	return dao.UserOAuthDao.Delete(simpleDb.WithContext(ctx), userId, provider)
+}
+
+// Bindings 用户绑定的第三方账号
+func (s *oauthService) Bindings(ctx context.Context, userId int64) []model.UserOAuth {
+	return dao.UserOAuthDao.FindByUserId(simpleDb.WithContext(ctx), userId)
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/pkg/pdf"
+	"go-skeleton/pkg/queue"
+	"go-skeleton/utils"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+var PdfService = newPdfService()
+
+// 异步生成pdf的任务
+const JobPdfGenerate = "pdf.generate"
+
+// pdf任务的状态
+const (
+	PdfTaskPending = "pending"
+	PdfTaskDone    = "done"
+	PdfTaskFailed  = "failed"
+)
+
+// PdfTask 生成pdf的任务，保存在redis中，过期时间见配置pdf.ResultTTL
+type PdfTask struct {
+	Id       string `json:"id"`
+	UserId   int64  `json:"userId"`
+	Template string `json:"template"`
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+	URL      string `json:"url,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	Error    string `json:"error,omitempty"`
+	// 创建时间，unix时间戳
+	CreatedAt int64 `json:"createdAt"`
+}
+
+type pdfJob struct {
+	TaskId string          `json:"taskId"`
+	Data   json.RawMessage `json:"data"`
+}
+
+func init() {
+	queue.Handle(JobPdfGenerate, func(ctx context.Context, job *queue.Job) error {
+		var p pdfJob
+		if err := job.Bind(&p); err != nil {
+			return fmt.Errorf("%w: %v", queue.ErrSkipRetry, err)
+		}
+		err := PdfService.generate(ctx, p.TaskId, p.Data)
+		if stderrors.Is(err, pdf.ErrTemplateNotFound) {
+			return fmt.Errorf("%w: %v", queue.ErrSkipRetry, err)
+		}
+		// 最后一次重试失败后标记为失败
+		if err != nil && job.Attempts >= job.MaxRetry {
+			PdfService.fail(ctx, p.TaskId, err)
+		}
+		return err
+	})
+}
+
+func newPdfService() *pdfService {
+	return &pdfService{}
+}
+
+type pdfService struct {
+}
+
+func pdfTaskKey(id string) string {
+	return "pdf:task:" + id
+}
+
+// Submit 提交生成任务，开启了任务队列时异步生成，否则同步生成，返回任务用于查询结果
+func (s *pdfService) Submit(ctx context.Context, userId int64, template, filename string, data json.RawMessage) (*PdfTask, error) {
+	if !pdf.HasTemplate(template) {
+		return nil, errors.NewError(errors.InvalidParamsError.Code, "模板不存在")
+	}
+	if filename == "" {
+		filename = template + ".pdf"
+	}
+	task := &PdfTask{
+		Id:        utils.UUID(),
+		UserId:    userId,
+		Template:  template,
+		Filename:  filename,
+		Status:    PdfTaskPending,
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := s.save(ctx, task); err != nil {
+		return nil, err
+	}
+
+	if config.Conf.QueueConfig.Enable {
+		_, err := queue.Enqueue(ctx, JobPdfGenerate, &pdfJob{TaskId: task.Id, Data: data})
+		if err == nil {
+			return task, nil
+		}
+		zap.L().Warn("enqueue pdf job failed", zap.String("id", task.Id), zap.Error(err))
+	}
+	if err := s.generate(ctx, task.Id, data); err != nil {
+		s.fail(ctx, task.Id, err)
+	}
+	return s.Task(ctx, userId, task.Id)
+}
+
+// Task 查询任务，只能查询自己提交的任务
+func (s *pdfService) Task(ctx context.Context, userId int64, id string) (*PdfTask, error) {
+	task, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil || task.UserId != userId {
+		return nil, errors.NotFoundError
+	}
+	return task, nil
+}
+
+// generate 生成pdf并保存，data为模板数据
+func (s *pdfService) generate(ctx context.Context, id string, data json.RawMessage) error {
+	task, err := s.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	if task == nil || task.Status != PdfTaskPending {
+		// 已过期或已处理
+		return nil
+	}
+	var v interface{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("%w: %v", queue.ErrSkipRetry, err)
+		}
+	}
+	file, err := pdf.Generate(ctx, task.Template, v, task.Filename)
+	if err != nil {
+		return err
+	}
+	task.Status, task.URL, task.Size = PdfTaskDone, file.URL, file.Size
+	return s.save(ctx, task)
+}
+
+func (s *pdfService) fail(ctx context.Context, id string, cause error) {
+	task, err := s.load(ctx, id)
+	if err != nil || task == nil {
+		return
+	}
+	task.Status, task.Error = PdfTaskFailed, "生成失败"
+	zap.L().Error("generate pdf failed", zap.String("id", id), zap.String("template", task.Template), zap.Error(cause))
+	if err := s.save(ctx, task); err != nil {
+		zap.L().Error("save pdf task failed", zap.String("id", id), zap.Error(err))
+	}
+}
+
+func (s *pdfService) save(ctx context.Context, task *PdfTask) error {
+	ttl := config.Conf.PdfConfig.ResultTTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	b, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	return gredis.GetRedis().Set(ctx, pdfTaskKey(task.Id), b, ttl).Err()
+}
+
+func (s *pdfService) load(ctx context.Context, id string) (*PdfTask, error) {
+	b, err := gredis.GetRedis().Get(ctx, pdfTaskKey(id)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	task := &PdfTask{}
+	if err := json.Unmarshal(b, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"go-skeleton/dao"
+	"go-skeleton/pkg/gcache"
+	"go-skeleton/pkg/simpleDb"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var PermissionService = newPermissionService()
+
+func newPermissionService() *permissionService {
+	return &permissionService{}
+}
+
+// 用户权限code的缓存
+var permissionCache = gcache.NewCached("user_permissions", 10*time.Minute)
+
+// 用户角色code的缓存
+var roleCache = gcache.NewCached("user_roles", 10*time.Minute)
+
+type permissionService struct {
+}
+
+// GetUserPermissions 用户拥有的权限code，优先读取缓存
+func (s *permissionService) GetUserPermissions(ctx context.Context, userId int64) []string {
+	var codes []string
+	err := permissionCache.Get(ctx, userId, &codes, func() (interface{}, error) {
+		db := simpleDb.WithContext(ctx)
+		codes := dao.PermissionDao.FindCodesByRoleIds(db, dao.PermissionDao.FindRoleIdsByUserId(db, userId))
+		if codes == nil {
+			codes = []string{}
+		}
+		return codes, nil
+	})
+	if err != nil {
+		zap.L().Error("get user permissions failed", zap.Int64("userId", userId), zap.Error(err))
+		return nil
+	}
+	return codes
+}
+
+// GetUserRoles 用户拥有的角色code，优先读取缓存
+func (s *permissionService) GetUserRoles(ctx context.Context, userId int64) []string {
+	var codes []string
+	err := roleCache.Get(ctx, userId, &codes, func() (interface{}, error) {
+		db := simpleDb.WithContext(ctx)
+		codes := dao.PermissionDao.FindRoleCodesByIds(db, dao.PermissionDao.FindRoleIdsByUserId(db, userId))
+		if codes == nil {
+			codes = []string{}
+		}
+		return codes, nil
+	})
+	if err != nil {
+		zap.L().Error("get user roles failed", zap.Int64("userId", userId), zap.Error(err))
+		return nil
+	}
+	return codes
+}
+
+// HasPermission 用户是否拥有权限，支持通配符，eg: 拥有article:*或*时也拥有article:write
+func (s *permissionService) HasPermission(ctx context.Context, userId int64, code string) bool {
+	for _, owned := range s.GetUserPermissions(ctx, userId) {
+		if matchPermission(owned, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// ForgetUser 用户角色变更后清除缓存
+func (s *permissionService) ForgetUser(ctx context.Context, userIds ...int64) {
+	ids := make([]interface{}, 0, len(userIds))
+	for _, id := range userIds {
+		ids = append(ids, id)
+	}
+	if err := permissionCache.Del(ctx, ids...); err != nil {
+		zap.L().Warn("delete permission cache failed", zap.Error(err))
+	}
+	if err := roleCache.Del(ctx, ids...); err != nil {
+		zap.L().Warn("delete role cache failed", zap.Error(err))
+	}
+}
+
+func (s *permissionService) AddUserRole(ctx context.Context, userId, roleId int64) error {
+	err := dao.PermissionDao.AddUserRole(simpleDb.WithContext(ctx), userId, roleId)
+	if err == nil {
+		s.ForgetUser(ctx, userId)
+	}
+	return err
+}
+
+func (s *permissionService) RemoveUserRole(ctx context.Context, userId, roleId int64) error {
+	err := dao.PermissionDao.RemoveUserRole(simpleDb.WithContext(ctx), userId, roleId)
+	if err == nil {
+		s.ForgetUser(ctx, userId)
+	}
+	return err
+}
+
+// AddRolePermission 给角色分配权限，清除拥有该角色的用户的权限缓存
+func (s *permissionService) AddRolePermission(ctx context.Context, roleId, permissionId int64) error {
+	db := simpleDb.WithContext(ctx)
+	err := dao.PermissionDao.AddRolePermission(db, roleId, permissionId)
+	if err == nil {
+		s.forgetRole(ctx, db, roleId)
+	}
+	return err
+}
+
+// RemoveRolePermission 移除角色权限，清除拥有该角色的用户的权限缓存
+func (s *permissionService) RemoveRolePermission(ctx context.Context, roleId, permissionId int64) error {
+	db := simpleDb.WithContext(ctx)
+	err := dao.PermissionDao.RemoveRolePermission(db, roleId, permissionId)
+	if err == nil {
+		s.forgetRole(ctx, db, roleId)
+	}
+	return err
+}
+
+// forgetRole 角色权限变更后清除该角色所有用户的缓存
+func (s *permissionService) forgetRole(ctx context.Context, db *gorm.DB, roleId int64) {
+	if userIds := dao.PermissionDao.FindUserIdsByRoleId(db, roleId); len(userIds) > 0 {
+		s.ForgetUser(ctx, userIds...)
+	}
+}
+
+// matchPermission owned中的*匹配一段或剩余所有段
+func matchPermission(owned, code string) bool {
+	if owned == code || owned == "*" {
+		return true
+	}
+	ownedParts := strings.Split(owned, ":")
+	codeParts := strings.Split(code, ":")
+	for i, part := range ownedParts {
+		if i >= len(codeParts) {
+			return false
+		}
+		if part == "*" {
+			if i == len(ownedParts)-1 {
+				return true
+			}
+			continue
+		}
+		if part != codeParts[i] {
+			return false
+		}
+	}
+	return len(ownedParts) == len(codeParts)
+}
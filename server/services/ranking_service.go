@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/pkg/simpleDb"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+var RankingService = newRankingService()
+
+func newRankingService() *rankingService {
+	return &rankingService{}
+}
+
+type rankingService struct {
+}
+
+// 热度分 = (浏览量*viewWeight + 点赞*likeWeight + 收藏*favoriteWeight + 评论*commentWeight) / (发布后的小时数+2)^gravity
+const (
+	rankingViewWeight     = 1
+	rankingLikeWeight     = 3
+	rankingFavoriteWeight = 4
+	rankingCommentWeight  = 5
+	rankingGravity        = 1.5
+	// 参与计算的文章数上限，按周期内的浏览量取前N篇，加上周期内发布的文章
+	rankingCandidates = 1000
+	// 排行结果保存的时间，定时任务停止后过期
+	rankingTTL = 2 * time.Hour
+)
+
+// 周期的时长，周期内发布的文章都参与计算
+var rankingPeriods = map[string]time.Duration{
+	ViewRankDay:  24 * time.Hour,
+	ViewRankWeek: 7 * 24 * time.Hour,
+}
+
+func (s *rankingService) key(period string) string {
+	return "ranking:trending:" + period
+}
+
+// Compute 重新计算所有周期的热度排行，由定时任务ranking.compute调用
+func (s *rankingService) Compute(ctx context.Context) error {
+	rdb := gredis.GetRedis()
+	if rdb == nil {
+		return nil
+	}
+	for period := range rankingPeriods {
+		if err := s.compute(ctx, rdb, period); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compute 计算结果先写入临时key再改名，读取时不会看到写了一半的排行
+func (s *rankingService) compute(ctx context.Context, rdb *redis.Client, period string) error {
+	views, err := ViewService.PeriodViews(ctx, period, rankingCandidates)
+	if err != nil {
+		return err
+	}
+	ids := make([]int64, 0, len(views))
+	for id := range views {
+		ids = append(ids, id)
+	}
+
+	now := time.Now()
+	since := now.Add(-rankingPeriods[period]).Unix()
+	cnd := simpleDb.NewSqlCnd().
+		Cols("id", "published_at", "like_count", "favorite_count", "comment_count").
+		Eq("status", constants.ArticleStatusPublished)
+	if len(ids) > 0 {
+		cnd.Where("published_at >= ? OR id IN ?", since, ids)
+	} else {
+		cnd.Gte("published_at", since)
+	}
+	articles := dao.ArticleDao.Find(simpleDb.WithContext(ctx), cnd)
+
+	key := s.key(period)
+	members := make([]*redis.Z, 0, len(articles))
+	for i := range articles {
+		a := &articles[i]
+		members = append(members, &redis.Z{
+			Score:  s.score(a, views[int64(a.ID)], now),
+			Member: strconv.FormatInt(int64(a.ID), 10),
+		})
+	}
+	tmp := key + ":tmp"
+	_, err = rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, tmp)
+		if len(members) == 0 {
+			pipe.Del(ctx, key)
+			return nil
+		}
+		pipe.ZAdd(ctx, tmp, members...)
+		pipe.Rename(ctx, tmp, key)
+		pipe.Expire(ctx, key, rankingTTL)
+		return nil
+	})
+	if err == nil {
+		zap.L().Debug("trending computed", zap.String("period", period), zap.Int("count", len(members)))
+	}
+	return err
+}
+
+// score 热度分，发布时间越久衰减越多
+func (s *rankingService) score(a *model.Article, views int64, now time.Time) float64 {
+	points := float64(views*rankingViewWeight + a.LikeCount*rankingLikeWeight +
+		a.FavoriteCount*rankingFavoriteWeight + a.CommentCount*rankingCommentWeight)
+	hours := now.Sub(time.Unix(a.PublishedAt, 0)).Hours()
+	if hours < 0 {
+		hours = 0
+	}
+	return points / math.Pow(hours+2, rankingGravity)
+}
+
+// Trending 热度排行，period为day或week，定时任务未执行前为空
+func (s *rankingService) Trending(ctx context.Context, period string, page, limit int) ([]model.Article, *simpleDb.Paging, error) {
+	if _, ok := rankingPeriods[period]; !ok {
+		period = ViewRankDay
+	}
+	if page <= 0 {
+		page = 1
+	}
+	paging := &simpleDb.Paging{Page: page, Limit: limit}
+	rdb := gredis.GetRedis()
+	if rdb == nil {
+		return []model.Article{}, paging, nil
+	}
+
+	key := s.key(period)
+	start := int64((page - 1) * limit)
+	var (
+		total   *redis.IntCmd
+		members *redis.StringSliceCmd
+	)
+	_, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		total = pipe.ZCard(ctx, key)
+		members = pipe.ZRevRange(ctx, key, start, start+int64(limit)-1)
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, nil, err
+	}
+	paging.Total = total.Val()
+
+	ids := make([]int64, 0, len(members.Val()))
+	for _, m := range members.Val() {
+		id, _ := strconv.ParseInt(m, 10, 64)
+		ids = append(ids, id)
+	}
+	articles := make(map[int64]model.Article, len(ids))
+	for _, a := range ArticleService.GetArticleInIds(ctx, ids) {
+		articles[int64(a.ID)] = a
+	}
+	list := make([]model.Article, 0, len(ids))
+	for _, id := range ids {
+		if a, ok := articles[id]; ok && a.Status == constants.ArticleStatusPublished {
+			list = append(list, a)
+		}
+	}
+	return list, paging, nil
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/eventbus"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/pkg/simpleDb"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+var ReactionService = newReactionService()
+
+func newReactionService() *reactionService {
+	return &reactionService{}
+}
+
+type reactionService struct {
+}
+
+// 支持的实体和操作
+var (
+	reactionEntities = map[string]bool{constants.EntityArticle: true, constants.EntityComment: true}
+	reactionTypes    = map[string]bool{constants.ReactionLike: true, constants.ReactionFavorite: true}
+)
+
+// ReactionState 操作后的状态，Count包含还未同步到数据库的增量
+type ReactionState struct {
+	Active bool  `json:"active"`
+	Count  int64 `json:"count"`
+}
+
+// deltaKey 还未同步到数据库的计数增量，hash的field为实体id
+func (s *reactionService) deltaKey(entityType, typ string) string {
+	return fmt.Sprintf("reaction:delta:%s:%s", entityType, typ)
+}
+
+// Toggle 点赞/收藏或取消，计数先累加到redis，由定时任务reaction.sync同步到数据库，
+// redis不可用时直接修改数据库
+func (s *reactionService) Toggle(ctx context.Context, userId int64, entityType string, entityId int64, typ string) (*ReactionState, error) {
+	if !reactionEntities[entityType] || !reactionTypes[typ] {
+		return nil, errors.InvalidParamsError
+	}
+	count, ok := s.entityCount(ctx, entityType, entityId, typ)
+	if !ok {
+		return nil, errors.NotFoundError
+	}
+
+	db := simpleDb.WithContext(ctx)
+	deleted, err := dao.ReactionDao.Delete(db, userId, entityType, entityId, typ)
+	if err != nil {
+		return nil, err
+	}
+	state := &ReactionState{Active: !deleted}
+	var n int64 = -1
+	if !deleted {
+		created, err := dao.ReactionDao.Create(db, &model.Reaction{
+			UserId:     userId,
+			EntityType: entityType,
+			EntityId:   entityId,
+			Type:       typ,
+		})
+		if err != nil {
+			return nil, err
+		}
+		// 并发请求已经添加过时不重复计数
+		n = 0
+		if created {
+			n = 1
+		}
+	}
+
+	pending, err := s.incr(ctx, entityType, entityId, typ, n)
+	if err != nil {
+		return nil, err
+	}
+	state.Count = count + pending
+	if state.Count < 0 {
+		state.Count = 0
+	}
+
+	if n > 0 && entityType == constants.EntityArticle && typ == constants.ReactionLike {
+		_ = eventbus.Publish(ctx, eventbus.ArticleLiked{ArticleId: entityId, UserId: userId})
+	}
+	return state, nil
+}
+
+// entityCount 实体在数据库中的计数，实体不存在时ok为false
+func (s *reactionService) entityCount(ctx context.Context, entityType string, entityId int64, typ string) (count int64, ok bool) {
+	switch entityType {
+	case constants.EntityArticle:
+		article := dao.ArticleDao.Get(simpleDb.WithContext(ctx), entityId)
+		if article == nil || article.Status != constants.ArticleStatusPublished {
+			return 0, false
+		}
+		if typ == constants.ReactionLike {
+			return article.LikeCount, true
+		}
+		return article.FavoriteCount, true
+	case constants.EntityComment:
+		comment := CommentService.Get(ctx, entityId)
+		if comment == nil {
+			return 0, false
+		}
+		if typ == constants.ReactionLike {
+			return comment.LikeCount, true
+		}
+		return comment.FavoriteCount, true
+	}
+	return 0, false
+}
+
+// incr 累加计数增量，返回还未同步的增量
+func (s *reactionService) incr(ctx context.Context, entityType string, entityId int64, typ string, n int64) (int64, error) {
+	rdb := gredis.GetRedis()
+	if rdb == nil {
+		if n == 0 {
+			return 0, nil
+		}
+		return 0, dao.ReactionDao.IncrCount(simpleDb.WithContext(ctx), entityType, entityId, typ, n)
+	}
+	key, field := s.deltaKey(entityType, typ), strconv.FormatInt(entityId, 10)
+	if n == 0 {
+		pending, err := rdb.HGet(ctx, key, field).Int64()
+		if err == redis.Nil {
+			err = nil
+		}
+		return pending, err
+	}
+	return rdb.HIncrBy(ctx, key, field, n).Result()
+}
+
+// Sync 将redis中的计数增量同步到数据库，由定时任务调用。
+// 先把增量hash改名再处理，处理期间新的增量写入新的hash；每同步一个实体删除一个field，中途失败下次继续
+func (s *reactionService) Sync(ctx context.Context) error {
+	rdb := gredis.GetRedis()
+	if rdb == nil {
+		return nil
+	}
+	for entityType := range reactionEntities {
+		for typ := range reactionTypes {
+			if err := s.syncKey(ctx, rdb, entityType, typ); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *reactionService) syncKey(ctx context.Context, rdb *redis.Client, entityType, typ string) error {
+	key := s.deltaKey(entityType, typ)
+	syncing := key + ":syncing"
+	// 上次同步失败时先处理剩下的
+	exists, err := rdb.Exists(ctx, syncing).Result()
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		// 定时任务使用分布式锁，同一时间只有一个实例在同步
+		if exists, err = rdb.Exists(ctx, key).Result(); err != nil || exists == 0 {
+			return err
+		}
+		if err := rdb.Rename(ctx, key, syncing).Err(); err != nil {
+			return err
+		}
+	}
+
+	deltas, err := rdb.HGetAll(ctx, syncing).Result()
+	if err != nil {
+		return err
+	}
+	db := simpleDb.WithContext(ctx)
+	for field, value := range deltas {
+		entityId, _ := strconv.ParseInt(field, 10, 64)
+		n, _ := strconv.ParseInt(value, 10, 64)
+		if entityId > 0 && n != 0 {
+			if err := dao.ReactionDao.IncrCount(db, entityType, entityId, typ, n); err != nil {
+				return err
+			}
+		}
+		if err := rdb.HDel(ctx, syncing, field).Err(); err != nil {
+			return err
+		}
+	}
+	if len(deltas) > 0 {
+		zap.L().Debug("reaction counts synced", zap.String("entity", entityType), zap.String("type", typ), zap.Int("count", len(deltas)))
+	}
+	return nil
+}
+
+// Reacted 用户对entityIds中的哪些实体点赞/收藏过，用于列表展示状态
+func (s *reactionService) Reacted(ctx context.Context, userId int64, entityType string, entityIds []int64, typ string) map[int64]bool {
+	return dao.ReactionDao.FindEntityIds(simpleDb.WithContext(ctx), userId, entityType, entityIds, typ)
+}
+
+// FavoriteArticles 用户收藏的文章，按收藏时间倒序，已删除的文章不返回
+func (s *reactionService) FavoriteArticles(ctx context.Context, userId int64, page, limit int) ([]model.Article, *simpleDb.Paging) {
+	cnd := simpleDb.NewSqlCnd().
+		Eq("user_id", userId).
+		Eq("entity_type", constants.EntityArticle).
+		Eq("type", constants.ReactionFavorite).
+		Desc("id").
+		Page(page, limit)
+	reactions, paging := dao.ReactionDao.FindPageByCnd(simpleDb.WithContext(ctx), cnd)
+	if len(reactions) == 0 {
+		return []model.Article{}, paging
+	}
+
+	ids := make([]int64, 0, len(reactions))
+	for _, r := range reactions {
+		ids = append(ids, r.EntityId)
+	}
+	articles := dao.ArticleDao.Find(simpleDb.WithContext(ctx), simpleDb.NewSqlCnd().In("id", ids))
+	byId := make(map[int64]model.Article, len(articles))
+	for _, a := range articles {
+		byId[int64(a.ID)] = a
+	}
+	list := make([]model.Article, 0, len(ids))
+	for _, id := range ids {
+		if a, ok := byId[id]; ok {
+			list = append(list, a)
+		}
+	}
+	return list, paging
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/simpleDb"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// ReviewPermission 审核文章的权限
+const ReviewPermission = "article:review"
+
+var ReviewService = newReviewService()
+
+func newReviewService() *reviewService {
+	return &reviewService{}
+}
+
+type reviewService struct {
+}
+
+// ReviewQuery 审核列表的筛选条件
+type ReviewQuery struct {
+	Status     int   `form:"status"`     // 默认为待审核
+	ReviewerId int64 `form:"reviewerId"` // 审核人，0为不限，-1为未分配
+	Page       int   `form:"page"`
+	Limit      int   `form:"limit"`
+}
+
+// List 按状态筛选文章，供审核人员使用
+func (s *reviewService) List(ctx context.Context, q *ReviewQuery) ([]model.Article, *simpleDb.Paging) {
+	status := q.Status
+	if status == 0 {
+		status = constants.ArticleStatusPendingReview
+	}
+	cnd := simpleDb.NewSqlCnd().Eq("status", status).Asc("updated_at").Asc("id").Page(q.Page, q.Limit)
+	if q.ReviewerId > 0 {
+		cnd.Eq("reviewer_id", q.ReviewerId)
+	} else if q.ReviewerId < 0 {
+		cnd.Eq("reviewer_id", 0)
+	}
+	return dao.ArticleDao.FindPageByCnd(simpleDb.WithContext(ctx), cnd)
+}
+
+// Assign 分配审核人，审核人需要有article:review权限且不能是作者
+func (s *reviewService) Assign(ctx context.Context, operatorId, articleId, reviewerId int64) error {
+	article := dao.ArticleDao.Get(simpleDb.WithContext(ctx), articleId)
+	if article == nil {
+		return errors.NotFoundError
+	}
+	if article.Status != constants.ArticleStatusPendingReview {
+		return errors.ArticleStatusError
+	}
+	if reviewerId == article.UserId || !PermissionService.HasPermission(ctx, reviewerId, ReviewPermission) {
+		return errors.NewError(errors.InvalidParamsError.Code, "审核人没有审核权限")
+	}
+	return simpleDb.Transaction(simpleDb.WithContext(ctx), func(tx *gorm.DB) error {
+		cnd := simpleDb.NewSqlCnd().Eq("id", articleId).Eq("status", constants.ArticleStatusPendingReview)
+		n, err := dao.ArticleDao.UpdatesByCnd(tx, cnd, map[string]interface{}{"reviewer_id": reviewerId})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return errors.ArticleStatusError
+		}
+		return ArticleService.logStatus(tx, operatorId, article, article.Status,
+			constants.ArticleActionAssign, strconv.FormatInt(reviewerId, 10))
+	})
+}
+
+// Approve 审核通过
+func (s *reviewService) Approve(ctx context.Context, reviewerId, articleId int64) error {
+	article, err := s.getReviewable(ctx, reviewerId, articleId)
+	if err != nil {
+		return err
+	}
+	return ArticleService.changeStatus(ctx, reviewerId, article, constants.ArticleStatusApproved, "")
+}
+
+// Reject 驳回，需要填写原因
+func (s *reviewService) Reject(ctx context.Context, reviewerId, articleId int64, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.NewError(errors.InvalidParamsError.Code, "驳回原因不能为空")
+	}
+	if utf8.RuneCountInString(reason) > 255 {
+		return errors.NewError(errors.InvalidParamsError.Code, "驳回原因不能超过255个字")
+	}
+	article, err := s.getReviewable(ctx, reviewerId, articleId)
+	if err != nil {
+		return err
+	}
+	return ArticleService.changeStatus(ctx, reviewerId, article, constants.ArticleStatusRejected, reason)
+}
+
+// getReviewable 待审核的文章，不能审核自己的文章，已分配审核人时只有审核人或拥有article:manage权限的用户可以审核
+func (s *reviewService) getReviewable(ctx context.Context, reviewerId, articleId int64) (*model.Article, error) {
+	article := dao.ArticleDao.Get(simpleDb.WithContext(ctx), articleId)
+	if article == nil {
+		return nil, errors.NotFoundError
+	}
+	if article.Status != constants.ArticleStatusPendingReview {
+		return nil, errors.ArticleStatusError
+	}
+	if article.UserId == reviewerId {
+		return nil, errors.PermissionDeniedError
+	}
+	if article.ReviewerId > 0 && article.ReviewerId != reviewerId &&
+		!PermissionService.HasPermission(ctx, reviewerId, articleManagePermission) {
+		return nil, errors.PermissionDeniedError
+	}
+	return article, nil
+}
+
+// Logs 文章的状态变更记录，作者、审核人员可以查看
+func (s *reviewService) Logs(ctx context.Context, userId, articleId int64) ([]model.ArticleStatusLog, error) {
+	if !PermissionService.HasPermission(ctx, userId, ReviewPermission) {
+		if _, err := ArticleService.getOwned(ctx, userId, articleId); err != nil {
+			return nil, err
+		}
+	}
+	return dao.ArticleStatusLogDao.FindByArticleId(simpleDb.WithContext(ctx), articleId), nil
+}
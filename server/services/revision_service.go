@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/utils"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var RevisionService = newRevisionService()
+
+func newRevisionService() *revisionService {
+	return &revisionService{}
+}
+
+type revisionService struct {
+}
+
+const (
+	// 未配置时每篇文章保留的版本数
+	revisionDefaultKeep = 20
+	// 每次清理处理的文章数
+	revisionPruneBatch = 100
+)
+
+// newRevision 根据修改前后的文章生成版本，prev为nil时为新建
+func (s *revisionService) newRevision(userId int64, prev, cur *model.Article, autosave bool) *model.ArticleRevision {
+	r := &model.ArticleRevision{
+		ArticleId: int64(cur.ID),
+		UserId:    userId,
+		Version:   cur.Version,
+		Autosave:  autosave,
+		Title:     cur.Title,
+		Cid:       cur.Cid,
+		Desc:      cur.Desc,
+		Content:   cur.Content,
+		Img:       cur.Img,
+		CreatedAt: time.Now().Unix(),
+	}
+	if prev == nil {
+		prev = &model.Article{}
+	}
+	var fields []string
+	if prev.Title != cur.Title {
+		fields = append(fields, "title")
+	}
+	if prev.Cid != cur.Cid {
+		fields = append(fields, "cid")
+	}
+	if prev.Desc != cur.Desc {
+		fields = append(fields, "desc")
+	}
+	if prev.Content != cur.Content {
+		fields = append(fields, "content")
+		r.LinesAdded, r.LinesRemoved = utils.LineDiff(prev.Content, cur.Content)
+	}
+	if prev.Img != cur.Img {
+		fields = append(fields, "img")
+	}
+	r.Fields = strings.Join(fields, ",")
+	return r
+}
+
+// record 保存文章时记录版本，需要和文章在同一个事务中调用
+func (s *revisionService) record(tx *gorm.DB, userId int64, prev, cur *model.Article) error {
+	return dao.ArticleRevisionDao.Create(tx, s.newRevision(userId, prev, cur, false))
+}
+
+// Autosave 自动保存编辑中的内容，不修改文章。
+// 同一个用户在AutosaveInterval内的自动保存覆盖上一次，避免编辑器频繁保存产生大量版本
+func (s *revisionService) Autosave(ctx context.Context, userId, articleId int64, form *ArticleForm) (*model.ArticleRevision, error) {
+	article, err := ArticleService.getOwned(ctx, userId, articleId)
+	if err != nil {
+		return nil, err
+	}
+	if article.Status == constants.ArticleStatusArchived {
+		return nil, errors.ArticleStatusError
+	}
+	// 草稿可以不完整，只检查长度
+	form.Title = strings.TrimSpace(form.Title)
+	form.Desc = strings.TrimSpace(form.Desc)
+	if utf8.RuneCountInString(form.Title) > 100 {
+		return nil, errors.NewError(errors.InvalidParamsError.Code, "标题不能超过100个字")
+	}
+	if utf8.RuneCountInString(form.Desc) > 200 {
+		return nil, errors.NewError(errors.InvalidParamsError.Code, "摘要不能超过200个字")
+	}
+
+	draft := *article
+	draft.Title, draft.Cid, draft.Desc, draft.Content, draft.Img = form.Title, form.Cid, form.Desc, form.Content, form.Img
+	r := s.newRevision(userId, article, &draft, true)
+
+	db := simpleDb.WithContext(ctx)
+	interval := config.Conf.RevisionConfig.AutosaveInterval
+	if last := dao.ArticleRevisionDao.Latest(db, articleId, nil); last != nil && last.Autosave &&
+		last.UserId == userId && time.Since(time.Unix(last.CreatedAt, 0)) < interval {
+		r.ID = last.ID
+		return r, dao.ArticleRevisionDao.Overwrite(db, r)
+	}
+	return r, dao.ArticleRevisionDao.Create(db, r)
+}
+
+// List 文章的历史版本，按时间倒序，不返回内容
+func (s *revisionService) List(ctx context.Context, userId, articleId int64, page, limit int) ([]model.ArticleRevision, *simpleDb.Paging, error) {
+	if _, err := ArticleService.getOwned(ctx, userId, articleId); err != nil {
+		return nil, nil, err
+	}
+	list, paging := dao.ArticleRevisionDao.FindPageByCnd(simpleDb.WithContext(ctx),
+		simpleDb.NewSqlCnd().Eq("article_id", articleId).Desc("id").Page(page, limit))
+	return list, paging, nil
+}
+
+// Get 预览版本，只有文章作者或拥有article:manage权限的用户可以查看
+func (s *revisionService) Get(ctx context.Context, userId, revisionId int64) (*model.ArticleRevision, error) {
+	r := dao.ArticleRevisionDao.Get(simpleDb.WithContext(ctx), revisionId)
+	if r == nil {
+		return nil, errors.NotFoundError
+	}
+	if _, err := ArticleService.getOwned(ctx, userId, r.ArticleId); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Restore 将文章恢复为指定版本的内容，标签和slug保持不变，恢复后生成新的版本
+func (s *revisionService) Restore(ctx context.Context, userId, revisionId int64, version *int64) (*model.Article, error) {
+	r, err := s.Get(ctx, userId, revisionId)
+	if err != nil {
+		return nil, err
+	}
+	tags := dao.TagDao.FindByArticleId(simpleDb.WithContext(ctx), r.ArticleId)
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return ArticleService.Edit(ctx, userId, r.ArticleId, &ArticleForm{
+		Title:   r.Title,
+		Cid:     r.Cid,
+		Desc:    r.Desc,
+		Content: r.Content,
+		Img:     r.Img,
+		Tags:    names,
+		Version: version,
+	})
+}
+
+// Prune 清理历史版本，每篇文章保留最近Keep个，更早的版本超过Retention后删除，由定时任务revision.prune调用
+func (s *revisionService) Prune(ctx context.Context) error {
+	keep := config.Conf.RevisionConfig.Keep
+	if keep <= 0 {
+		keep = revisionDefaultKeep
+	}
+	var before int64
+	if retention := config.Conf.RevisionConfig.Retention; retention > 0 {
+		before = time.Now().Add(-retention).Unix()
+	}
+
+	db := simpleDb.WithContext(ctx)
+	var afterId, total int64
+	for {
+		ids, err := dao.ArticleRevisionDao.FindPrunableArticleIds(db, keep, afterId, revisionPruneBatch)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			n, err := dao.ArticleRevisionDao.Prune(db, id, keep, before)
+			if err != nil {
+				return err
+			}
+			total += n
+		}
+		if len(ids) < revisionPruneBatch {
+			break
+		}
+		afterId = ids[len(ids)-1]
+	}
+	if total > 0 {
+		zap.L().Info("article revisions pruned", zap.Int64("count", total))
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/pkg/queue"
+	"go-skeleton/pkg/simpleDb"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// JobArticlePublish 定时发布任务，到时间后发布草稿
+const JobArticlePublish = "article.publish"
+
+// 定时任务article.publishScheduled每次处理的文章数
+const schedulePublishBatch = 100
+
+var ScheduleService = newScheduleService()
+
+func newScheduleService() *scheduleService {
+	return &scheduleService{}
+}
+
+type scheduleService struct {
+}
+
+// scheduledPublish 定时发布任务的参数，文章的定时发布时间已修改时任务不处理
+type scheduledPublish struct {
+	ArticleId int64 `json:"articleId"`
+	PublishAt int64 `json:"publishAt"`
+}
+
+func init() {
+	queue.Handle(JobArticlePublish, func(ctx context.Context, job *queue.Job) error {
+		var p scheduledPublish
+		if err := job.Bind(&p); err != nil {
+			return err
+		}
+		return ScheduleService.publish(ctx, p.ArticleId, p.PublishAt)
+	})
+}
+
+// Schedule 设置或修改定时发布时间，只有作者或拥有article:manage权限的用户可以操作，
+// 开启审核时只能定时发布审核通过的文章。
+// 开启了任务队列时投递延迟任务，定时任务article.publishScheduled每分钟检查一次作为兜底
+func (s *scheduleService) Schedule(ctx context.Context, userId, articleId int64, publishAt time.Time) (*model.Article, error) {
+	article, err := ArticleService.getOwned(ctx, userId, articleId)
+	if err != nil {
+		return nil, err
+	}
+	if !ArticleService.canTransition(article.Status, constants.ArticleStatusPublished) {
+		return nil, errors.ArticleStatusError
+	}
+	if !publishAt.After(time.Now()) {
+		return nil, errors.NewError(errors.InvalidParamsError.Code, "发布时间必须晚于当前时间")
+	}
+
+	at := publishAt.Unix()
+	if err := s.setPublishAt(ctx, article, at); err != nil {
+		return nil, err
+	}
+	if config.Conf.QueueConfig.Enable && gredis.GetRedis() != nil {
+		_, err := queue.Enqueue(ctx, JobArticlePublish, &scheduledPublish{ArticleId: articleId, PublishAt: at},
+			queue.WithDelay(time.Until(publishAt)))
+		if err != nil {
+			zap.L().Warn("enqueue article publish job failed", zap.Int64("id", articleId), zap.Error(err))
+		}
+	}
+	return dao.ArticleDao.Get(simpleDb.WithContext(ctx), articleId), nil
+}
+
+// Cancel 取消定时发布，文章状态不变
+func (s *scheduleService) Cancel(ctx context.Context, userId, articleId int64) error {
+	article, err := ArticleService.getOwned(ctx, userId, articleId)
+	if err != nil {
+		return err
+	}
+	if article.PublishAt == 0 || !ArticleService.canTransition(article.Status, constants.ArticleStatusPublished) {
+		return errors.ArticleStatusError
+	}
+	return s.setPublishAt(ctx, article, 0)
+}
+
+// setPublishAt 按原状态和版本号修改，避免和发布、修改并发
+func (s *scheduleService) setPublishAt(ctx context.Context, article *model.Article, publishAt int64) error {
+	cnd := simpleDb.NewSqlCnd().
+		Eq("id", article.ID).
+		Eq("status", article.Status).
+		Eq("version", article.Version)
+	n, err := dao.ArticleDao.UpdatesByCnd(simpleDb.WithContext(ctx), cnd, map[string]interface{}{
+		"publish_at": publishAt,
+		"version":    article.Version + 1,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.StaleObjectError
+	}
+	return nil
+}
+
+// publish 发布到时间的文章，文章已发布、已取消或修改了定时发布时间时不处理
+func (s *scheduleService) publish(ctx context.Context, articleId, publishAt int64) error {
+	article := dao.ArticleDao.Get(simpleDb.WithContext(ctx), articleId)
+	if article == nil || article.PublishAt == 0 || article.PublishAt != publishAt || article.PublishAt > time.Now().Unix() ||
+		!ArticleService.canTransition(article.Status, constants.ArticleStatusPublished) {
+		return nil
+	}
+	err := ArticleService.changeStatus(ctx, article.UserId, article, constants.ArticleStatusPublished, "")
+	if err == errors.ArticleStatusError {
+		// 已被其他实例或用户发布
+		return nil
+	}
+	if err == nil {
+		zap.L().Info("scheduled article published", zap.Int64("id", articleId))
+	}
+	return err
+}
+
+// PublishDue 发布所有到时间的文章，由定时任务article.publishScheduled调用，
+// 未开启任务队列或任务丢失时保证定时发布最多延迟一分钟
+func (s *scheduleService) PublishDue(ctx context.Context) error {
+	var afterId int64
+	for {
+		cnd := simpleDb.NewSqlCnd().
+			Cols("id", "publish_at").
+			In("status", []int{constants.ArticleStatusDraft, constants.ArticleStatusApproved}).
+			Gt("publish_at", 0).
+			Lte("publish_at", time.Now().Unix()).
+			Gt("id", afterId).
+			Asc("id").
+			Limit(schedulePublishBatch)
+		articles := dao.ArticleDao.Find(simpleDb.WithContext(ctx), cnd)
+		for _, a := range articles {
+			if err := s.publish(ctx, int64(a.ID), a.PublishAt); err != nil {
+				return err
+			}
+		}
+		if len(articles) < schedulePublishBatch {
+			return nil
+		}
+		afterId = int64(articles[len(articles)-1].ID)
+	}
+}
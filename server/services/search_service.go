@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/queue"
+	"go-skeleton/pkg/search"
+	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/pkg/tenant"
+	"go-skeleton/utils"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var SearchService = newSearchService()
+
+// 同步文章到搜索索引的任务
+const JobArticleIndex = "article.index"
+
+func init() {
+	queue.Handle(JobArticleIndex, func(ctx context.Context, job *queue.Job) error {
+		var articleId int64
+		if err := job.Bind(&articleId); err != nil {
+			return err
+		}
+		return SearchService.syncArticle(ctx, articleId)
+	})
+}
+
+func newSearchService() *searchService {
+	return &searchService{}
+}
+
+type searchService struct {
+}
+
+// 重建索引时每批的文章数
+const reindexBatchSize = 500
+
+// Search 搜索已发布的文章
+func (s *searchService) Search(ctx context.Context, q *search.Query) (*search.Result, error) {
+	return search.SearchArticles(ctx, q)
+}
+
+// SyncArticle 异步同步文章到索引，已发布的文章写入索引，其他状态或已删除的从索引中移除，
+// 开启了任务队列时投递article.index任务，失败后可以重试
+func (s *searchService) SyncArticle(articleId int64) {
+	if !search.Enabled() {
+		return
+	}
+	if config.Conf.QueueConfig.Enable {
+		_, err := queue.Enqueue(context.Background(), JobArticleIndex, articleId)
+		if err == nil {
+			return
+		}
+		zap.L().Warn("enqueue article index job failed", zap.Int64("id", articleId), zap.Error(err))
+	}
+	go func() {
+		timeout := config.Conf.SearchConfig.Timeout
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		// 索引包含所有租户的文章
+		ctx, cancel := context.WithTimeout(tenant.SkipContext(context.Background()), timeout)
+		defer cancel()
+		if err := s.syncArticle(ctx, articleId); err != nil {
+			zap.L().Error("sync article to search index failed", zap.Int64("id", articleId), zap.Error(err))
+		}
+	}()
+}
+
+func (s *searchService) syncArticle(ctx context.Context, articleId int64) error {
+	article := &model.Article{}
+	err := simpleDb.WithContext(ctx).Where("id = ?", articleId).Take(article).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		// 查询失败时保留索引，由调用方重试
+		return err
+	}
+	if err != nil || article.Status != constants.ArticleStatusPublished {
+		return search.DeleteArticle(ctx, articleId)
+	}
+	tags := dao.TagDao.FindNamesByArticleIds(simpleDb.WithContext(ctx), []int64{articleId})
+	return search.IndexArticle(ctx, s.toDoc(article, tags[articleId]))
+}
+
+// ReindexAll 删除并重建索引，按id分批写入所有已发布的文章，返回写入的文章数
+func (s *searchService) ReindexAll(ctx context.Context) (int, error) {
+	ctx = tenant.SkipContext(ctx)
+	if err := search.DeleteIndex(ctx); err != nil {
+		return 0, err
+	}
+	if err := search.EnsureIndex(ctx); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	var lastId uint
+	for {
+		var articles []model.Article
+		err := simpleDb.WithContext(ctx).Where("status = ? AND id > ?", constants.ArticleStatusPublished, lastId).
+			Order("id ASC").Limit(reindexBatchSize).Find(&articles).Error
+		if err != nil {
+			return total, err
+		}
+		if len(articles) == 0 {
+			return total, nil
+		}
+
+		ids := make([]int64, 0, len(articles))
+		for _, article := range articles {
+			ids = append(ids, int64(article.ID))
+		}
+		tags := dao.TagDao.FindNamesByArticleIds(simpleDb.WithContext(ctx), ids)
+		docs := make([]search.ArticleDoc, 0, len(articles))
+		for i := range articles {
+			docs = append(docs, *s.toDoc(&articles[i], tags[int64(articles[i].ID)]))
+		}
+		if err := search.BulkIndex(ctx, docs); err != nil {
+			return total, err
+		}
+		total += len(docs)
+		lastId = articles[len(articles)-1].ID
+	}
+}
+
+func (s *searchService) toDoc(article *model.Article, tags []string) *search.ArticleDoc {
+	return &search.ArticleDoc{
+		Id:          int64(article.ID),
+		Title:       article.Title,
+		Desc:        article.Desc,
+		Content:     utils.MarkdownToText(article.Content),
+		Img:         article.Img,
+		Cid:         article.Cid,
+		UserId:      article.UserId,
+		Slug:        article.Slug,
+		Tags:        tags,
+		PublishedAt: article.PublishedAt,
+	}
+}
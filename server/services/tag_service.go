@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/excel"
+	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/utils"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+var TagService = newTagService()
+
+func newTagService() *tagService {
+	return &tagService{}
+}
+
+type tagService struct {
+}
+
+func (s *tagService) Get(ctx context.Context, id int64) *model.Tag {
+	return dao.TagDao.Get(simpleDb.WithContext(ctx), id)
+}
+
+func (s *tagService) FindPageByCnd(ctx context.Context, cnd *simpleDb.SqlCnd) (list []model.Tag, paging *simpleDb.Paging) {
+	return dao.TagDao.FindPageByCnd(simpleDb.WithContext(ctx), cnd)
+}
+
+func (s *tagService) validate(name, description string) error {
+	if name == "" {
+		return errors.NewError(errors.InvalidParamsError.Code, "标签名称不能为空")
+	}
+	if utf8.RuneCountInString(name) > 32 {
+		return errors.NewError(errors.InvalidParamsError.Code, "标签名称不能超过32个字")
+	}
+	if utf8.RuneCountInString(description) > 1024 {
+		return errors.NewError(errors.InvalidParamsError.Code, "标签描述过长")
+	}
+	return nil
+}
+
+func (s *tagService) Create(ctx context.Context, name, description string) (*model.Tag, error) {
+	name, description = strings.TrimSpace(name), strings.TrimSpace(description)
+	if err := s.validate(name, description); err != nil {
+		return nil, err
+	}
+	if dao.TagDao.NameExists(simpleDb.WithContext(ctx), name, 0) {
+		return nil, errors.NewError(errors.InvalidParamsError.Code, "标签已存在")
+	}
+	tag := &model.Tag{Name: name, Description: description, Status: constants.StatusOk}
+	if err := dao.TagDao.Create(simpleDb.WithContext(ctx), tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (s *tagService) Update(ctx context.Context, id int64, name, description string) error {
+	name, description = strings.TrimSpace(name), strings.TrimSpace(description)
+	if err := s.validate(name, description); err != nil {
+		return err
+	}
+	if s.Get(ctx, id) == nil {
+		return errors.NotFoundError
+	}
+	if dao.TagDao.NameExists(simpleDb.WithContext(ctx), name, id) {
+		return errors.NewError(errors.InvalidParamsError.Code, "标签已存在")
+	}
+	return dao.TagDao.Updates(simpleDb.WithContext(ctx), id, map[string]interface{}{
+		"name":        name,
+		"description": description,
+	})
+}
+
+func (s *tagService) Delete(ctx context.Context, id int64) error {
+	return simpleDb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return dao.TagDao.Delete(tx, id)
+	})
+}
+
+// Attach 为文章添加标签，不存在的标签自动创建
+func (s *tagService) Attach(ctx context.Context, articleId int64, names []string) error {
+	return simpleDb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tagIds, err := dao.TagDao.GetOrCreates(tx, names)
+		if err != nil {
+			return err
+		}
+		return dao.ArticleDao.AttachTags(tx, articleId, tagIds)
+	})
+}
+
+// Detach 移除文章的标签
+func (s *tagService) Detach(ctx context.Context, articleId int64, tagIds []int64) error {
+	return dao.ArticleDao.DetachTags(simpleDb.WithContext(ctx), articleId, tagIds)
+}
+
+// HotTags 已发布文章数最多的标签
+func (s *tagService) HotTags(ctx context.Context, limit int) []model.TagCount {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return dao.TagDao.FindWithCount(simpleDb.WithContext(ctx), constants.ArticleStatusPublished, limit)
+}
+
+// CountArticles 标签下已发布的文章数
+func (s *tagService) CountArticles(ctx context.Context, tagIds ...int64) map[int64]int64 {
+	return dao.TagDao.CountArticles(simpleDb.WithContext(ctx), constants.ArticleStatusPublished, tagIds)
+}
+
+// TagRow 标签导入导出的一行，导入时忽略ID和创建时间
+type TagRow struct {
+	Id          int64  `json:"id" excel:"ID,width=10"`
+	Name        string `json:"name" excel:"名称,width=20" valid:"required,max=32"`
+	Description string `json:"description" excel:"描述,width=50" valid:"max=1024"`
+	CreateTime  string `json:"createTime" excel:"创建时间,width=20"`
+}
+
+// 导出时每次查询的条数
+const tagExportBatch = 1000
+
+// Export 导出所有标签为xlsx，按id分页查询，返回导出的条数
+func (s *tagService) Export(ctx context.Context, w io.Writer) (int, error) {
+	var lastId uint
+	return excel.Export(w, TagRow{}, func(page int) (interface{}, error) {
+		tags := dao.TagDao.Find(simpleDb.WithContext(ctx), simpleDb.NewSqlCnd().Gt("id", lastId).Asc("id").Limit(tagExportBatch))
+		rows := make([]TagRow, 0, len(tags))
+		for _, tag := range tags {
+			rows = append(rows, TagRow{
+				Id:          int64(tag.ID),
+				Name:        tag.Name,
+				Description: tag.Description,
+				CreateTime:  utils.Date(tag.CreateTime),
+			})
+			lastId = tag.ID
+		}
+		return rows, nil
+	})
+}
+
+// Import 从xlsx导入标签，已存在或不合法的行记录到导入结果中
+func (s *tagService) Import(ctx context.Context, r io.Reader) (*excel.Report, error) {
+	return excel.Import(r, &TagRow{}, func(line int, v interface{}) error {
+		row := v.(*TagRow)
+		if _, err := s.Create(ctx, row.Name, row.Description); err != nil {
+			if ce, ok := err.(*errors.CodeError); ok {
+				return stderrors.New(ce.Message)
+			}
+			return err
+		}
+		return nil
+	})
+}
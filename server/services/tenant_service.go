@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/pkg/gcache"
+	"go-skeleton/pkg/simpleDb"
+	"go-skeleton/pkg/tenant"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 租户状态
+const (
+	TenantStatusActive   = 0
+	TenantStatusDisabled = 1
+)
+
+var TenantService = newTenantService()
+
+func newTenantService() *tenantService {
+	return &tenantService{
+		cache:       gcache.NewCached("tenant", 10*time.Minute),
+		memberCache: gcache.NewCached("tenant_user", 10*time.Minute),
+	}
+}
+
+type tenantService struct {
+	cache       *gcache.Cached
+	memberCache *gcache.Cached
+}
+
+// Resolve 按code查询正常状态的租户，不存在或已禁用时返回nil
+func (s *tenantService) Resolve(ctx context.Context, code string) *model.Tenant {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		return nil
+	}
+	t := &model.Tenant{}
+	err := s.cache.Get(ctx, code, t, func() (interface{}, error) {
+		if ret := dao.TenantDao.GetByCode(simpleDb.WithContext(ctx), code); ret != nil {
+			return ret, nil
+		}
+		return nil, nil
+	})
+	if err != nil {
+		if err != gcache.ErrNotFound {
+			zap.L().Warn("resolve tenant failed", zap.String("code", code), zap.Error(err))
+		}
+		return nil
+	}
+	if t.Status != TenantStatusActive {
+		return nil
+	}
+	return t
+}
+
+// IsMember 用户是否属于租户，登录后校验请求的租户，避免通过请求头访问其他租户的数据
+func (s *tenantService) IsMember(ctx context.Context, tenantId, userId int64) bool {
+	var ok bool
+	err := s.memberCache.Get(ctx, fmt.Sprintf("%d:%d", tenantId, userId), &ok, func() (interface{}, error) {
+		if dao.TenantDao.IsMember(simpleDb.WithContext(tenant.SkipContext(ctx)), tenantId, userId) {
+			return true, nil
+		}
+		return nil, nil
+	})
+	if err != nil && err != gcache.ErrNotFound {
+		zap.L().Warn("check tenant member failed", zap.Int64("tenant", tenantId), zap.Int64("uid", userId), zap.Error(err))
+	}
+	return err == nil && ok
+}
+
+// AddMember 将用户加入租户
+func (s *tenantService) AddMember(ctx context.Context, tenantId, userId int64) error {
+	if err := dao.TenantDao.AddMember(simpleDb.WithContext(tenant.SkipContext(ctx)), tenantId, userId); err != nil {
+		return err
+	}
+	return s.memberCache.Del(ctx, fmt.Sprintf("%d:%d", tenantId, userId))
+}
+
+// joinCurrent 注册时将用户加入tx的context中的租户，需要和创建用户在同一个事务中调用
+func (s *tenantService) joinCurrent(tx *gorm.DB, userId int64) error {
+	tenantId, ok := tenant.FromContext(tx.Statement.Context)
+	if !ok {
+		return nil
+	}
+	return dao.TenantDao.AddMember(tx, tenantId, userId)
+}
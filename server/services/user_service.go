@@ -1,13 +1,24 @@
 package services
 
 import (
-	"errors"
+	"context"
 	"go-skeleton/dao"
 	"go-skeleton/model"
 	"go-skeleton/model/constants"
+	"go-skeleton/pkg/config"
+	"go-skeleton/pkg/errors"
+	"go-skeleton/pkg/eventbus"
+	"go-skeleton/pkg/excel"
+	"go-skeleton/pkg/gredis"
 	"go-skeleton/pkg/simpleDb"
 	"go-skeleton/utils"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+	"gorm.io/gorm"
 )
 
 var UserService = newUserService()
@@ -19,47 +30,56 @@ func newUserService() *userService {
 type userService struct {
 }
 
-func (s *userService) Get(id int64) *model.User {
-	return dao.UserDao.Get(simpleDb.DB(), id)
+func (s *userService) Get(ctx context.Context, id int64) *model.User {
+	return dao.UserDao.Get(simpleDb.WithContext(ctx), id)
 }
 
-func (s *userService) Take(where ...interface{}) *model.User {
-	return dao.UserDao.Take(simpleDb.DB(), where...)
+func (s *userService) Take(ctx context.Context, where ...interface{}) *model.User {
+	return dao.UserDao.Take(simpleDb.WithContext(ctx), where...)
 }
 
-func (s *userService) Find(cnd *simpleDb.SqlCnd) []model.User {
-	return dao.UserDao.Find(simpleDb.DB(), cnd)
+func (s *userService) Find(ctx context.Context, cnd *simpleDb.SqlCnd) []model.User {
+	return dao.UserDao.Find(simpleDb.WithContext(ctx), cnd)
 }
 
-func (s *userService) FindOne(cnd *simpleDb.SqlCnd) *model.User {
-	return dao.UserDao.FindOne(simpleDb.DB(), cnd)
+func (s *userService) FindOne(ctx context.Context, cnd *simpleDb.SqlCnd) *model.User {
+	return dao.UserDao.FindOne(simpleDb.WithContext(ctx), cnd)
 }
 
-func (s *userService) FindPageByParams(params *simpleDb.QueryParams) (list []model.User, paging *simpleDb.Paging) {
-	return dao.UserDao.FindPageByParams(simpleDb.DB(), params)
+func (s *userService) FindPageByParams(ctx context.Context, params *simpleDb.QueryParams) (list []model.User, paging *simpleDb.Paging) {
+	return dao.UserDao.FindPageByParams(simpleDb.WithContext(ctx), params)
 }
 
-func (s *userService) FindPageByCnd(cnd *simpleDb.SqlCnd) (list []model.User, paging *simpleDb.Paging) {
-	return dao.UserDao.FindPageByCnd(simpleDb.DB(), cnd)
+func (s *userService) FindPageByCnd(ctx context.Context, cnd *simpleDb.SqlCnd) (list []model.User, paging *simpleDb.Paging) {
+	return dao.UserDao.FindPageByCnd(simpleDb.WithContext(ctx), cnd)
 }
 
-func (s *userService) Update(t *model.User) error {
-	err := dao.UserDao.Update(simpleDb.DB(), t)
+// FindCursorPage 游标分页，游标无效时返回InvalidParamsError
+func (s *userService) FindCursorPage(ctx context.Context, cnd *simpleDb.SqlCnd) (list []model.User, paging *simpleDb.Paging, err error) {
+	list, paging, err = dao.UserDao.FindCursorPage(simpleDb.WithContext(ctx), cnd)
+	if err == simpleDb.ErrInvalidCursor {
+		err = errors.InvalidParamsError
+	}
+	return
+}
+
+func (s *userService) Update(ctx context.Context, t *model.User) error {
+	err := dao.UserDao.Update(simpleDb.WithContext(ctx), t)
 	return err
 }
 
-func (s *userService) Updates(id int64, columns map[string]interface{}) error {
-	err := dao.UserDao.Updates(simpleDb.DB(), id, columns)
+func (s *userService) Updates(ctx context.Context, id int64, columns map[string]interface{}) error {
+	err := dao.UserDao.Updates(simpleDb.WithContext(ctx), id, columns)
 	return err
 }
 
-func (s *userService) UpdateColumn(id int64, name string, value interface{}) error {
-	err := dao.UserDao.UpdateColumn(simpleDb.DB(), id, name, value)
+func (s *userService) UpdateColumn(ctx context.Context, id int64, name string, value interface{}) error {
+	err := dao.UserDao.UpdateColumn(simpleDb.WithContext(ctx), id, name, value)
 	return err
 }
 
-func (s *userService) Delete(id int64) error {
-	err := dao.UserDao.UpdateColumn(simpleDb.DB(), id, "status", constants.StatusDeleted)
+func (s *userService) Delete(ctx context.Context, id int64) error {
+	err := dao.UserDao.Delete(simpleDb.WithContext(ctx), id)
 	if err == nil {
 		// 删掉标签文章
 		//UserTagService.DeleteByUserId(id)
@@ -67,28 +87,36 @@ func (s *userService) Delete(id int64) error {
 	return err
 }
 
+func (s *userService) Restore(ctx context.Context, id int64) error {
+	return dao.UserDao.Restore(simpleDb.WithContext(ctx), id)
+}
+
+func (s *userService) ForceDelete(ctx context.Context, id int64) error {
+	return dao.UserDao.ForceDelete(simpleDb.WithContext(ctx), id)
+}
+
 // 根据id批量获取
-func (s *userService) GetUserInIds(UserIds []int64) []model.User {
+func (s *userService) GetUserInIds(ctx context.Context, UserIds []int64) []model.User {
 	if len(UserIds) == 0 {
 		return nil
 	}
 	var Users []model.User
-	simpleDb.DB().Where("id in (?)", UserIds).Order("id desc").Find(&Users)
+	simpleDb.WithContext(ctx).Where("id in (?)", UserIds).Order("id desc").Find(&Users)
 	return Users
 }
 
 // GetByUsername 根据用户名查找
-func (s *userService) GetByUsername(username string) *model.User {
-	return dao.UserDao.GetByUsername(simpleDb.DB(), username)
+func (s *userService) GetByUsername(ctx context.Context, username string) *model.User {
+	return dao.UserDao.GetByUsername(simpleDb.WithContext(ctx), username)
 }
 
 // isUsernameExists 用户名是否存在
-func (s *userService) isUsernameExists(username string) bool {
-	return s.GetByUsername(username) != nil
+func (s *userService) isUsernameExists(ctx context.Context, username string) bool {
+	return s.GetByUsername(ctx, username) != nil
 }
 
 //注册
-func (s *userService) SignUp(username, password, rePassword string) (*model.User, error) {
+func (s *userService) SignUp(ctx context.Context, username, password, rePassword string) (*model.User, error) {
 	username = strings.TrimSpace(username)
 	password = strings.TrimSpace(password)
 
@@ -101,20 +129,233 @@ func (s *userService) SignUp(username, password, rePassword string) (*model.User
 		if err := utils.IsUsername(username); err != nil {
 			return nil, err
 		}
-		if s.isUsernameExists(username) {
-			return nil, errors.New("用户名[" + username + "]已被占用")
+		if s.isUsernameExists(ctx, username) {
+			return nil, errors.NewError(errors.InvalidParamsError.Code, "用户名["+username+"]已被占用")
 		}
 	}
 
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
 	user := &model.User{
 		Username: username,
-		Password: utils.EncodePassword(password),
-		Role:     1,
+		Password: hash,
+		Nickname: username,
+		Status:   constants.UserStatusNormal,
 	}
 
-	err = dao.UserDao.Create(simpleDb.DB(), user)
+	err = simpleDb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := dao.UserDao.Create(tx, user); err != nil {
+			return err
+		}
+		return TenantService.joinCurrent(tx, int64(user.ID))
+	})
 	if err != nil {
 		return nil, err
 	}
+	_ = eventbus.Publish(context.Background(), eventbus.UserRegistered{UserId: int64(user.ID), Username: user.Username})
+	return user, nil
+}
+
+// SignIn 用户名密码登录，连续失败达到user.MaxLoginAttempts次后锁定账号user.LockDuration
+func (s *userService) SignIn(ctx context.Context, username, password, ip string) (*model.User, error) {
+	user := s.GetByUsername(ctx, strings.TrimSpace(username))
+	if user == nil {
+		return nil, errors.LoginError
+	}
+	if s.isLocked(user.ID) {
+		return nil, errors.UserLockedError
+	}
+	if !utils.CheckPassword(user.Password, password) {
+		if s.loginFailed(user.ID) {
+			return nil, errors.UserLockedError
+		}
+		return nil, errors.LoginError
+	}
+	if user.Status == constants.UserStatusDisabled {
+		return nil, errors.UserDisabledError
+	}
+	gredis.GetRedis().Del(context.TODO(), loginFailKey(user.ID))
+	// 哈希算法或参数调整后，用本次登录的明文密码更新哈希
+	if utils.PasswordNeedsRehash(user.Password) {
+		if hash, err := utils.HashPassword(password); err == nil {
+			_ = s.UpdateColumn(ctx, int64(user.ID), model.UserColumns.Password, hash)
+		}
+	}
+	s.recordLogin(ctx, user, ip)
+	return user, nil
+}
+
+// recordLogin 记录最后登录的时间和ip
+func (s *userService) recordLogin(ctx context.Context, user *model.User, ip string) {
+	now := time.Now()
+	user.LastLoginAt = &now
+	user.LastLoginIp = ip
+	_ = s.Updates(ctx, int64(user.ID), map[string]interface{}{
+		model.UserColumns.LastLoginAt: now,
+		model.UserColumns.LastLoginIp: ip,
+	})
+}
+
+// Unlock 解除登录失败导致的账号锁定
+func (s *userService) Unlock(id int64) error {
+	return gredis.GetRedis().Del(context.TODO(), loginFailKey(uint(id))).Err()
+}
+
+func loginFailKey(id uint) string {
+	return "user:login_fail:" + strconv.FormatUint(uint64(id), 10)
+}
+
+func (s *userService) isLocked(id uint) bool {
+	max := config.Conf.UserConfig.MaxLoginAttempts
+	if max <= 0 {
+		return false
+	}
+	count, _ := gredis.GetRedis().Get(context.TODO(), loginFailKey(id)).Int64()
+	return count >= max
+}
+
+// loginFailed 记录一次登录失败，返回是否因此锁定了账号
+func (s *userService) loginFailed(id uint) bool {
+	cfg := config.Conf.UserConfig
+	if cfg.MaxLoginAttempts <= 0 {
+		return false
+	}
+	lockDuration := cfg.LockDuration
+	if lockDuration <= 0 {
+		lockDuration = 15 * time.Minute
+	}
+	ctx := context.TODO()
+	count, err := gredis.IncrExpire(ctx, loginFailKey(id), 1, lockDuration)
+	if err != nil || count < cfg.MaxLoginAttempts {
+		return false
+	}
+	// 从锁定时开始计算锁定时长
+	gredis.GetRedis().Expire(ctx, loginFailKey(id), lockDuration)
+	return true
+}
+
+// UpdateProfile 修改个人资料，只修改不为空的字段
+func (s *userService) UpdateProfile(ctx context.Context, id int64, nickname, avatar, email, locale, timezone string) (*model.User, error) {
+	user := s.Get(ctx, id)
+	if user == nil {
+		return nil, errors.NotFoundError
+	}
+	columns := map[string]interface{}{}
+	if nickname = strings.TrimSpace(nickname); nickname != "" {
+		if utf8.RuneCountInString(nickname) > 32 {
+			return nil, errors.NewError(errors.InvalidParamsError.Code, "昵称不能超过32个字")
+		}
+		columns[model.UserColumns.Nickname] = nickname
+		user.Nickname = nickname
+	}
+	if avatar = strings.TrimSpace(avatar); avatar != "" {
+		columns[model.UserColumns.Avatar] = avatar
+		user.Avatar = avatar
+	}
+	if email = strings.TrimSpace(email); email != "" {
+		if err := utils.IsEmail(email); err != nil {
+			return nil, errors.NewError(errors.InvalidParamsError.Code, err.Error())
+		}
+		columns[model.UserColumns.Email] = email
+		user.Email = email
+	}
+	if locale = strings.TrimSpace(locale); locale != "" {
+		if _, err := language.Parse(locale); err != nil || len(locale) > 16 {
+			return nil, errors.NewError(errors.InvalidParamsError.Code, "语言格式错误")
+		}
+		columns[model.UserColumns.Locale] = locale
+		user.Locale = locale
+	}
+	if timezone = strings.TrimSpace(timezone); timezone != "" {
+		if _, err := utils.LoadLocation(timezone); err != nil || len(timezone) > 64 {
+			return nil, errors.NewError(errors.InvalidParamsError.Code, "时区格式错误")
+		}
+		columns[model.UserColumns.Timezone] = timezone
+		user.Timezone = timezone
+	}
+	if len(columns) == 0 {
+		return user, nil
+	}
+	if err := s.Updates(ctx, id, columns); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
+
+// ChangePassword 修改密码，需要校验原密码，第三方登录注册的用户未设置过密码时不校验
+func (s *userService) ChangePassword(ctx context.Context, id int64, oldPassword, password, rePassword string) error {
+	user := s.Get(ctx, id)
+	if user == nil {
+		return errors.NotFoundError
+	}
+	if user.Password != "" && !utils.CheckPassword(user.Password, oldPassword) {
+		return errors.OldPasswordError
+	}
+	password = strings.TrimSpace(password)
+	if err := utils.IsPassword(password, rePassword); err != nil {
+		return errors.NewError(errors.InvalidParamsError.Code, err.Error())
+	}
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.UpdateColumn(ctx, id, model.UserColumns.Password, hash)
+}
+
+// UserRow 用户导出的一行，手机号、邮箱脱敏
+type UserRow struct {
+	Id          int64      `json:"id" excel:"ID,width=10"`
+	Username    string     `json:"username" excel:"用户名,width=20"`
+	Nickname    string     `json:"nickname" excel:"昵称,width=20"`
+	Email       string     `json:"email" excel:"邮箱,width=24"`
+	Phone       string     `json:"phone" excel:"手机号"`
+	Status      string     `json:"status" excel:"状态,width=8"`
+	CreatedAt   time.Time  `json:"createdAt" excel:"注册时间,width=20"`
+	LastLoginAt *time.Time `json:"lastLoginAt" excel:"最后登录时间,width=20"`
+}
+
+// 导出时每次查询的条数
+const userExportBatch = 1000
+
+// ExportFetch 按id游标分页查询用户，用于excel.Export或resp.CSV，status小于0时导出所有状态的用户
+func (s *userService) ExportFetch(ctx context.Context, status int) excel.Fetch {
+	var (
+		cursor string
+		done   bool
+	)
+	return func(page int) (interface{}, error) {
+		if done {
+			return nil, nil
+		}
+		cnd := simpleDb.NewSqlCnd().Asc("id").Limit(userExportBatch).After(cursor)
+		if status >= 0 {
+			cnd.Eq("status", status)
+		}
+		users, paging, err := dao.UserDao.FindCursorPage(simpleDb.WithContext(ctx), cnd)
+		if err != nil {
+			return nil, err
+		}
+		cursor, done = paging.Cursor, !paging.HasMore
+
+		rows := make([]UserRow, 0, len(users))
+		for _, user := range users {
+			row := UserRow{
+				Id:          int64(user.ID),
+				Username:    user.Username,
+				Nickname:    user.Nickname,
+				Email:       utils.MaskEmail(user.Email),
+				Phone:       utils.MaskPhone(user.Phone.String()),
+				Status:      "正常",
+				CreatedAt:   user.CreatedAt,
+				LastLoginAt: user.LastLoginAt,
+			}
+			if user.Status != constants.StatusOk {
+				row.Status = "禁用"
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	}
+}
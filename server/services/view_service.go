@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"go-skeleton/dao"
+	"go-skeleton/model"
+	"go-skeleton/model/constants"
+	"go-skeleton/pkg/gredis"
+	"go-skeleton/pkg/simpleDb"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+var ViewService = newViewService()
+
+func newViewService() *viewService {
+	return &viewService{}
+}
+
+type viewService struct {
+}
+
+const (
+	// 同一个用户或ip在窗口期内重复浏览只计一次
+	viewDedupWindow = 30 * time.Minute
+	// 每次写入数据库的文章数
+	viewFlushBatch = 500
+	// 浏览量增量，hash的field为文章id
+	viewDeltaKey = "view:delta"
+	// 每日排行保留的天数，需要覆盖周排行
+	viewRankDays = 8
+	// 周排行由最近7天的日排行合并，合并结果的缓存时间
+	viewWeekRankTTL = 5 * time.Minute
+)
+
+// 排行周期
+const (
+	ViewRankDay  = "day"
+	ViewRankWeek = "week"
+)
+
+// HotArticle 浏览量排行中的文章
+type HotArticle struct {
+	Id    int64  `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+	Img   string `json:"img"`
+	Views int64  `json:"views"` // 周期内的浏览量
+}
+
+func (s *viewService) dayRankKey(t time.Time) string {
+	return "view:rank:day:" + t.Format("20060102")
+}
+
+// Record 记录一次浏览，登录用户按用户去重，否则按ip去重，返回是否计数。
+// 浏览量先累加到redis，由定时任务view.flush批量写入数据库
+func (s *viewService) Record(ctx context.Context, articleId, userId int64, ip string) (bool, error) {
+	rdb := gredis.GetRedis()
+	if rdb == nil {
+		return false, nil
+	}
+	viewer := "ip:" + ip
+	if userId > 0 {
+		viewer = "u:" + strconv.FormatInt(userId, 10)
+	}
+	ok, err := rdb.SetNX(ctx, fmt.Sprintf("view:dedup:%d:%s", articleId, viewer), 1, viewDedupWindow).Result()
+	if err != nil || !ok {
+		return false, err
+	}
+
+	field := strconv.FormatInt(articleId, 10)
+	dayKey := s.dayRankKey(time.Now())
+	_, err = rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, viewDeltaKey, field, 1)
+		pipe.ZIncrBy(ctx, dayKey, 1, field)
+		pipe.Expire(ctx, dayKey, viewRankDays*24*time.Hour)
+		return nil
+	})
+	return err == nil, err
+}
+
+// Pending 还未写入数据库的浏览量
+func (s *viewService) Pending(ctx context.Context, articleId int64) int64 {
+	rdb := gredis.GetRedis()
+	if rdb == nil {
+		return 0
+	}
+	n, err := rdb.HGet(ctx, viewDeltaKey, strconv.FormatInt(articleId, 10)).Int64()
+	if err != nil && err != redis.Nil {
+		zap.L().Warn("get pending views failed", zap.Int64("articleId", articleId), zap.Error(err))
+	}
+	return n
+}
+
+// Flush 将浏览量增量批量写入数据库，由定时任务调用。
+// 先把增量hash改名再处理，每写入一批删除对应的field，中途失败下次继续
+func (s *viewService) Flush(ctx context.Context) error {
+	rdb := gredis.GetRedis()
+	if rdb == nil {
+		return nil
+	}
+	flushing := viewDeltaKey + ":flushing"
+	exists, err := rdb.Exists(ctx, flushing).Result()
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		// 定时任务使用分布式锁，同一时间只有一个实例在写入
+		if exists, err = rdb.Exists(ctx, viewDeltaKey).Result(); err != nil || exists == 0 {
+			return err
+		}
+		if err := rdb.Rename(ctx, viewDeltaKey, flushing).Err(); err != nil {
+			return err
+		}
+	}
+
+	total := 0
+	var cursor uint64
+	for {
+		var kvs []string
+		kvs, cursor, err = rdb.HScan(ctx, flushing, cursor, "", viewFlushBatch).Result()
+		if err != nil {
+			return err
+		}
+		if len(kvs) > 0 {
+			if err := s.flushBatch(ctx, rdb, flushing, kvs); err != nil {
+				return err
+			}
+			total += len(kvs) / 2
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+	if total > 0 {
+		zap.L().Debug("article views flushed", zap.Int("count", total))
+	}
+	return rdb.Del(ctx, flushing).Err()
+}
+
+// flushBatch kvs为HSCAN返回的field、value交替的列表
+func (s *viewService) flushBatch(ctx context.Context, rdb *redis.Client, key string, kvs []string) error {
+	deltas := make(map[int64]int64, len(kvs)/2)
+	fields := make([]string, 0, len(kvs)/2)
+	for i := 0; i+1 < len(kvs); i += 2 {
+		fields = append(fields, kvs[i])
+		id, _ := strconv.ParseInt(kvs[i], 10, 64)
+		n, _ := strconv.ParseInt(kvs[i+1], 10, 64)
+		if id > 0 && n > 0 {
+			deltas[id] = n
+		}
+	}
+	if err := dao.ArticleDao.IncrReadCounts(simpleDb.WithContext(ctx), deltas); err != nil {
+		return err
+	}
+	return rdb.HDel(ctx, key, fields...).Err()
+}
+
+// Hot 浏览量排行，period为day时为今天，week时为最近7天
+func (s *viewService) Hot(ctx context.Context, period string, limit int) ([]HotArticle, error) {
+	rdb := gredis.GetRedis()
+	if rdb == nil {
+		return []HotArticle{}, nil
+	}
+	key, err := s.rankKey(ctx, rdb, period)
+	if err != nil {
+		return nil, err
+	}
+	scores, err := rdb.ZRevRangeWithScores(ctx, key, 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(scores))
+	for _, z := range scores {
+		id, _ := strconv.ParseInt(z.Member.(string), 10, 64)
+		ids = append(ids, id)
+	}
+	articles := make(map[int64]model.Article, len(ids))
+	for _, a := range ArticleService.GetArticleInIds(ctx, ids) {
+		articles[int64(a.ID)] = a
+	}
+	list := make([]HotArticle, 0, len(scores))
+	for i, id := range ids {
+		a, ok := articles[id]
+		if !ok || a.Status != constants.ArticleStatusPublished {
+			continue
+		}
+		list = append(list, HotArticle{Id: id, Title: a.Title, Slug: a.Slug, Img: a.Img, Views: int64(scores[i].Score)})
+	}
+	return list, nil
+}
+
+// PeriodViews 周期内浏览量最多的n篇文章，key为文章id
+func (s *viewService) PeriodViews(ctx context.Context, period string, n int) (map[int64]int64, error) {
+	ret := make(map[int64]int64)
+	rdb := gredis.GetRedis()
+	if rdb == nil {
+		return ret, nil
+	}
+	key, err := s.rankKey(ctx, rdb, period)
+	if err != nil {
+		return nil, err
+	}
+	scores, err := rdb.ZRevRangeWithScores(ctx, key, 0, int64(n)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	for _, z := range scores {
+		id, _ := strconv.ParseInt(z.Member.(string), 10, 64)
+		ret[id] = int64(z.Score)
+	}
+	return ret, nil
+}
+
+// rankKey 周排行不存在时合并最近7天的日排行
+func (s *viewService) rankKey(ctx context.Context, rdb *redis.Client, period string) (string, error) {
+	now := time.Now()
+	if period != ViewRankWeek {
+		return s.dayRankKey(now), nil
+	}
+	key := "view:rank:week"
+	exists, err := rdb.Exists(ctx, key).Result()
+	if err != nil || exists > 0 {
+		return key, err
+	}
+	keys := make([]string, 0, 7)
+	for i := 0; i < 7; i++ {
+		keys = append(keys, s.dayRankKey(now.AddDate(0, 0, -i)))
+	}
+	_, err = rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZUnionStore(ctx, key, &redis.ZStore{Keys: keys})
+		pipe.Expire(ctx, key, viewWeekRankTTL)
+		return nil
+	})
+	return key, err
+}
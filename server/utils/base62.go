@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// ErrInvalidBase62 不是合法的base62字符串
+var ErrInvalidBase62 = errors.New("invalid base62 string")
+
+// EncodeBase62 将数字编码为base62短码，eg: 短链接、邀请码
+func EncodeBase62(n uint64) string {
+	if n == 0 {
+		return "0"
+	}
+	var buf [11]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = base62Alphabet[n%62]
+		n /= 62
+	}
+	return string(buf[i:])
+}
+
+// DecodeBase62 解码EncodeBase62生成的短码
+func DecodeBase62(s string) (uint64, error) {
+	if s == "" || len(s) > 11 {
+		return 0, ErrInvalidBase62
+	}
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		d := strings.IndexByte(base62Alphabet, s[i])
+		if d < 0 {
+			return 0, ErrInvalidBase62
+		}
+		next := n*62 + uint64(d)
+		if next/62 != n {
+			return 0, ErrInvalidBase62
+		}
+		n = next
+	}
+	return n, nil
+}
+
+// PublicID 对外暴露的带前缀的id，避免直接暴露自增id，eg: PublicID("art", 1) => "art_1"
+func PublicID(prefix string, id int64) string {
+	return prefix + "_" + EncodeBase62(uint64(id))
+}
+
+// ParsePublicID 解析PublicID，前缀不一致时返回错误
+func ParsePublicID(prefix, s string) (int64, error) {
+	if !strings.HasPrefix(s, prefix+"_") {
+		return 0, ErrInvalidBase62
+	}
+	n, err := DecodeBase62(s[len(prefix)+1:])
+	if err != nil || n > 1<<63-1 {
+		return 0, ErrInvalidBase62
+	}
+	return int64(n), nil
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBase62(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		want string
+	}{
+		{0, "0"},
+		{61, "z"},
+		{62, "10"},
+		{1<<64 - 1, "LygHa16AHYF"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, EncodeBase62(tt.n))
+		n, err := DecodeBase62(tt.want)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.n, n)
+	}
+
+	for _, s := range []string{"", "a-b", "LygHa16AHYG", "zzzzzzzzzzzz"} {
+		_, err := DecodeBase62(s)
+		assert.Equal(t, ErrInvalidBase62, err, s)
+	}
+}
+
+func TestPublicID(t *testing.T) {
+	s := PublicID("art", 1234567890123)
+	assert.Equal(t, "art_LjaL3EZ", s)
+	id, err := ParsePublicID("art", s)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1234567890123), id)
+
+	_, err = ParsePublicID("usr", s)
+	assert.Equal(t, ErrInvalidBase62, err)
+	_, err = ParsePublicID("art", "art_LygHa16AHYF")
+	assert.Equal(t, ErrInvalidBase62, err)
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadBody 读取并缓存请求体，之后每次调用返回相同的内容，并将c.Request.Body重置为未读取的状态，
+// 绑定、验签、日志等可以各自读取一次。缓存在gin.BodyBytesKey，c.ShouldBindBodyWith也会使用
+func ReadBody(c *gin.Context) ([]byte, error) {
+	if cached, ok := c.Get(gin.BodyBytesKey); ok {
+		if body, ok := cached.([]byte); ok {
+			c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+			return body, nil
+		}
+	}
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.Set(gin.BodyBytesKey, body)
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// CachedBody 已经通过ReadBody缓存的请求体，没有缓存时返回nil，不会读取请求体
+func CachedBody(c *gin.Context) ([]byte, bool) {
+	cached, ok := c.Get(gin.BodyBytesKey)
+	if !ok {
+		return nil, false
+	}
+	body, ok := cached.([]byte)
+	return body, ok
+}
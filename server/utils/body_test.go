@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadBody(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(`{"a":1}`))
+
+	_, ok := CachedBody(c)
+	assert.False(t, ok)
+
+	body, err := ReadBody(c)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"a":1}`, string(body))
+
+	// 后续读取者仍然可以读取完整的请求体
+	rest, _ := ioutil.ReadAll(c.Request.Body)
+	assert.Equal(t, `{"a":1}`, string(rest))
+	body, err = ReadBody(c)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"a":1}`, string(body))
+
+	var v struct{ A int }
+	assert.Nil(t, c.ShouldBindJSON(&v))
+	assert.Equal(t, 1, v.A)
+
+	cached, ok := CachedBody(c)
+	assert.True(t, ok)
+	assert.Equal(t, `{"a":1}`, string(cached))
+}
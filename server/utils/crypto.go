@@ -46,13 +46,14 @@ type AESCrypto interface {
 }
 
 type cbccrypto struct {
-	key  []byte
-	iv   []byte
-	mode PaddingMode
+	newCipher func(key []byte) (cipher.Block, error)
+	key       []byte
+	iv        []byte
+	mode      PaddingMode
 }
 
 func (c *cbccrypto) Encrypt(plainText []byte) ([]byte, error) {
-	block, err := aes.NewCipher(c.key)
+	block, err := c.newCipher(c.key)
 
 	if err != nil {
 		return nil, err
@@ -80,7 +81,7 @@ func (c *cbccrypto) Encrypt(plainText []byte) ([]byte, error) {
 }
 
 func (c *cbccrypto) Decrypt(cipherText []byte) ([]byte, error) {
-	block, err := aes.NewCipher(c.key)
+	block, err := c.newCipher(c.key)
 
 	if err != nil {
 		return nil, err
@@ -110,19 +111,21 @@ func (c *cbccrypto) Decrypt(cipherText []byte) ([]byte, error) {
 // NewCBCCrypto returns a new aes-cbc crypto
 func NewCBCCrypto(key, iv []byte, mode PaddingMode) AESCrypto {
 	return &cbccrypto{
-		key:  key,
-		iv:   iv,
-		mode: mode,
+		newCipher: aes.NewCipher,
+		key:       key,
+		iv:        iv,
+		mode:      mode,
 	}
 }
 
 type ecbcrypto struct {
-	key  []byte
-	mode PaddingMode
+	newCipher func(key []byte) (cipher.Block, error)
+	key       []byte
+	mode      PaddingMode
 }
 
 func (c *ecbcrypto) Encrypt(plainText []byte) ([]byte, error) {
-	block, err := aes.NewCipher(c.key)
+	block, err := c.newCipher(c.key)
 
 	if err != nil {
 		return nil, err
@@ -146,7 +149,7 @@ func (c *ecbcrypto) Encrypt(plainText []byte) ([]byte, error) {
 }
 
 func (c *ecbcrypto) Decrypt(cipherText []byte) ([]byte, error) {
-	block, err := aes.NewCipher(c.key)
+	block, err := c.newCipher(c.key)
 
 	if err != nil {
 		return nil, err
@@ -172,8 +175,9 @@ func (c *ecbcrypto) Decrypt(cipherText []byte) ([]byte, error) {
 // NewECBCrypto returns a new aes-ecb crypto
 func NewECBCrypto(key []byte, mode PaddingMode) AESCrypto {
 	return &ecbcrypto{
-		key:  key,
-		mode: mode,
+		newCipher: aes.NewCipher,
+		key:       key,
+		mode:      mode,
 	}
 }
 
@@ -331,12 +335,13 @@ func NewCTRCrypto(key, iv []byte) AESCrypto {
 }
 
 type gcmcrypto struct {
-	key   []byte
-	nonce []byte
+	newCipher func(key []byte) (cipher.Block, error)
+	key       []byte
+	nonce     []byte
 }
 
 func (c *gcmcrypto) Encrypt(plainText []byte) ([]byte, error) {
-	block, err := aes.NewCipher(c.key)
+	block, err := c.newCipher(c.key)
 
 	if err != nil {
 		return nil, err
@@ -356,7 +361,7 @@ func (c *gcmcrypto) Encrypt(plainText []byte) ([]byte, error) {
 }
 
 func (c *gcmcrypto) Decrypt(cipherText []byte) ([]byte, error) {
-	block, err := aes.NewCipher(c.key)
+	block, err := c.newCipher(c.key)
 
 	if err != nil {
 		return nil, err
@@ -378,8 +383,9 @@ func (c *gcmcrypto) Decrypt(cipherText []byte) ([]byte, error) {
 // NewGCMCrypto returns a new aes-gcm crypto
 func NewGCMCrypto(key, nonce []byte) AESCrypto {
 	return &gcmcrypto{
-		key:   key,
-		nonce: nonce,
+		newCipher: aes.NewCipher,
+		key:       key,
+		nonce:     nonce,
 	}
 }
 
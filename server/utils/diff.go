@@ -0,0 +1,73 @@
+package utils
+
+import "strings"
+
+// 逐行比较时参与动态规划的最大行数乘积，超过时按行出现的次数估算
+const lineDiffMaxCells = 4000000
+
+// LineDiff 比较两段文本，返回新增和删除的行数，eg:
+//
+//	added, removed := utils.LineDiff("a\nb", "a\nc\nd") // 2, 1
+func LineDiff(oldText, newText string) (added, removed int) {
+	a, b := splitLines(oldText), splitLines(newText)
+	// 去掉相同的开头和结尾，通常只修改了中间的一部分
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return len(b), len(a)
+	}
+
+	var common int
+	if len(a)*len(b) <= lineDiffMaxCells {
+		common = lcsLength(a, b)
+	} else {
+		common = commonLines(a, b)
+	}
+	return len(b) - common, len(a) - common
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+// lcsLength 最长公共子序列的长度，只保留两行状态
+func lcsLength(a, b []string) int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			switch {
+			case a[i-1] == b[j-1]:
+				cur[j] = prev[j-1] + 1
+			case prev[j] >= cur[j-1]:
+				cur[j] = prev[j]
+			default:
+				cur[j] = cur[j-1]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// commonLines 不考虑顺序时两边都有的行数
+func commonLines(a, b []string) int {
+	counts := make(map[string]int, len(a))
+	for _, line := range a {
+		counts[line]++
+	}
+	n := 0
+	for _, line := range b {
+		if counts[line] > 0 {
+			counts[line]--
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		name           string
+		old, new       string
+		added, removed int
+	}{
+		{name: "same", old: "a\nb", new: "a\nb"},
+		{name: "empty", old: "", new: "a\nb", added: 2},
+		{name: "clear", old: "a\nb", new: "", removed: 2},
+		{name: "replace", old: "a\nb", new: "a\nc\nd", added: 2, removed: 1},
+		{name: "insert middle", old: "a\nb\nc", new: "a\nx\nb\nc", added: 1},
+		{name: "move", old: "a\nb\nc", new: "c\na\nb", added: 1, removed: 1},
+		{name: "crlf", old: "a\r\nb", new: "a\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := LineDiff(tt.old, tt.new)
+			assert.Equal(t, tt.added, added)
+			assert.Equal(t, tt.removed, removed)
+		})
+	}
+}
+
+func TestLineDiffLarge(t *testing.T) {
+	lines := make([]string, 3000)
+	for i := range lines {
+		lines[i] = strings.Repeat("x", i%50)
+	}
+	old := strings.Join(lines, "\n")
+	added, removed := LineDiff("head\n"+old+"\nmid\n"+old, old+"\n"+old)
+	assert.Equal(t, 0, added)
+	assert.Equal(t, 2, removed)
+}
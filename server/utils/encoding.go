@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrInvalidEncoding 解码失败，返回的错误都包装了该错误，使用errors.Is判断
+var ErrInvalidEncoding = errors.New("invalid encoding")
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Indexes [256]int8
+
+func init() {
+	for i := range base58Indexes {
+		base58Indexes[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		base58Indexes[base58Alphabet[i]] = int8(i)
+	}
+}
+
+// Base64Encode 标准base64，带"="填充
+func Base64Encode(b []byte) string {
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// Base64Decode 解码标准base64
+func Base64Decode(s string) ([]byte, error) {
+	return decodeBase64(base64.StdEncoding, s)
+}
+
+// Base64RawEncode 标准base64，不带填充
+func Base64RawEncode(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
+
+// Base64RawDecode 解码不带填充的标准base64
+func Base64RawDecode(s string) ([]byte, error) {
+	return decodeBase64(base64.RawStdEncoding, s)
+}
+
+// Base64URLEncode url安全的base64("-"、"_"代替"+"、"/")，带"="填充
+func Base64URLEncode(b []byte) string {
+	return base64.URLEncoding.EncodeToString(b)
+}
+
+// Base64URLDecode 解码url安全的base64
+func Base64URLDecode(s string) ([]byte, error) {
+	return decodeBase64(base64.URLEncoding, s)
+}
+
+// Base64RawURLEncode url安全的base64，不带填充，eg: jwt、分页游标
+func Base64RawURLEncode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Base64RawURLDecode 解码不带填充的url安全base64
+func Base64RawURLDecode(s string) ([]byte, error) {
+	return decodeBase64(base64.RawURLEncoding, s)
+}
+
+func decodeBase64(enc *base64.Encoding, s string) ([]byte, error) {
+	b, err := enc.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: base64: %v", ErrInvalidEncoding, err)
+	}
+	return b, nil
+}
+
+// HexEncode 小写hex
+func HexEncode(b []byte) string {
+	return hex.EncodeToString(b)
+}
+
+// HexDecode 解码hex，不区分大小写
+func HexDecode(s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: hex: %v", ErrInvalidEncoding, err)
+	}
+	return b, nil
+}
+
+// Base58Encode bitcoin字母表的base58，不含0、O、I、l，开头的0字节编码为"1"
+func Base58Encode(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+	n := new(big.Int).SetBytes(b)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	out := make([]byte, 0, len(b)*138/100+1)
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// Base58Decode 解码Base58Encode的结果
+func Base58Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for i := zeros; i < len(s); i++ {
+		d := base58Indexes[s[i]]
+		if d < 0 {
+			return nil, fmt.Errorf("%w: base58: illegal character %q at %d", ErrInvalidEncoding, s[i], i)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBase64(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0xbf, 'a'}
+	tests := []struct {
+		encode func([]byte) string
+		decode func(string) ([]byte, error)
+		want   string
+	}{
+		{Base64Encode, Base64Decode, "+/+/YQ=="},
+		{Base64RawEncode, Base64RawDecode, "+/+/YQ"},
+		{Base64URLEncode, Base64URLDecode, "-_-_YQ=="},
+		{Base64RawURLEncode, Base64RawURLDecode, "-_-_YQ"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.encode(data))
+		b, err := tt.decode(tt.want)
+		assert.Nil(t, err)
+		assert.Equal(t, data, b)
+		_, err = tt.decode("*")
+		assert.True(t, errors.Is(err, ErrInvalidEncoding))
+	}
+}
+
+func TestHexEncoding(t *testing.T) {
+	assert.Equal(t, "00ff10", HexEncode([]byte{0, 255, 16}))
+	b, err := HexDecode("00FF10")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0, 255, 16}, b)
+	_, err = HexDecode("0g")
+	assert.True(t, errors.Is(err, ErrInvalidEncoding))
+}
+
+func TestBase58(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want string
+	}{
+		{[]byte{}, ""},
+		{[]byte("Hello World!"), "2NEpo7TZRRrLZSi2U"},
+		{[]byte{0, 0, 0x28, 0x7f, 0xb4, 0xcd}, "11233QC4"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Base58Encode(tt.data))
+		b, err := Base58Decode(tt.want)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.data, b)
+	}
+	_, err := Base58Decode("0OIl")
+	assert.True(t, errors.Is(err, ErrInvalidEncoding))
+}
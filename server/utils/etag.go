@@ -0,0 +1,27 @@
+package utils
+
+import "strings"
+
+// ETag 内容的ETag，使用sha1的前20个hex字符，weak为true时生成弱ETag，eg: W/"8843d7f92416211de9eb"
+func ETag(weak bool, data []byte) string {
+	tag := `"` + SHA1Hex(data)[:20] + `"`
+	if weak {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// ETagMatch If-None-Match是否包含etag，按弱比较忽略W/前缀，*匹配任意ETag
+func ETagMatch(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
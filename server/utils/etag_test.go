@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETag(t *testing.T) {
+	data := []byte("iiinsomnia")
+	assert.Equal(t, `"7a4082bd79f2086af2c2"`, ETag(false, data))
+	assert.Equal(t, `W/"7a4082bd79f2086af2c2"`, ETag(true, data))
+	assert.NotEqual(t, ETag(false, data), ETag(false, []byte("iiinsomnia2")))
+}
+
+func TestETagMatch(t *testing.T) {
+	etag := `"7a4082bd79f2086af2c2"`
+	assert.True(t, ETagMatch(etag, etag))
+	assert.True(t, ETagMatch(`W/"7a4082bd79f2086af2c2"`, etag))
+	assert.True(t, ETagMatch(etag, "W/"+etag))
+	assert.True(t, ETagMatch(`"a", "7a4082bd79f2086af2c2"`, etag))
+	assert.True(t, ETagMatch("*", etag))
+	assert.False(t, ETagMatch(`"a", "b"`, etag))
+	assert.False(t, ETagMatch("", etag))
+	assert.False(t, ETagMatch(etag, ""))
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"strconv"
+)
+
+// 摘要、hmac的[]byte版本及hex、base64编码，字符串参数使用[]byte(s)转换，eg:
+//
+//	utils.SHA256Hex([]byte(body))
+//	utils.HMACBase64(utils.AlgoSha1, []byte(stringToSign), []byte(secret))
+
+// hashFuncs 支持的摘要算法
+var hashFuncs = map[HashAlgo]func() hash.Hash{
+	AlgoMD5:    md5.New,
+	AlgoSha1:   sha1.New,
+	AlgoSha224: sha256.New224,
+	AlgoSha256: sha256.New,
+	AlgoSha384: sha512.New384,
+	AlgoSha512: sha512.New,
+}
+
+// HashBytes 计算摘要，不支持的算法返回nil
+func HashBytes(algo HashAlgo, data []byte) []byte {
+	fn, ok := hashFuncs[algo]
+	if !ok {
+		return nil
+	}
+	h := fn()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// HashHex 摘要的hex编码
+func HashHex(algo HashAlgo, data []byte) string {
+	return hex.EncodeToString(HashBytes(algo, data))
+}
+
+// HashBase64 摘要的base64编码
+func HashBase64(algo HashAlgo, data []byte) string {
+	return base64.StdEncoding.EncodeToString(HashBytes(algo, data))
+}
+
+// HMACBytes 计算hmac，不支持的算法返回nil
+func HMACBytes(algo HashAlgo, data, key []byte) []byte {
+	fn, ok := hashFuncs[algo]
+	if !ok {
+		return nil
+	}
+	mac := hmac.New(fn, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// HMACHex hmac的hex编码
+func HMACHex(algo HashAlgo, data, key []byte) string {
+	return hex.EncodeToString(HMACBytes(algo, data, key))
+}
+
+// HMACBase64 hmac的base64编码
+func HMACBase64(algo HashAlgo, data, key []byte) string {
+	return base64.StdEncoding.EncodeToString(HMACBytes(algo, data, key))
+}
+
+// MD5Hex md5的hex编码
+func MD5Hex(data []byte) string {
+	return HashHex(AlgoMD5, data)
+}
+
+// SHA1Hex sha1的hex编码
+func SHA1Hex(data []byte) string {
+	return HashHex(AlgoSha1, data)
+}
+
+// SHA256Hex sha256的hex编码
+func SHA256Hex(data []byte) string {
+	return HashHex(AlgoSha256, data)
+}
+
+// SHA512Hex sha512的hex编码
+func SHA512Hex(data []byte) string {
+	return HashHex(AlgoSha512, data)
+}
+
+// CRC32 IEEE crc32校验和
+func CRC32(data []byte) uint32 {
+	return crc32.ChecksumIEEE(data)
+}
+
+// CRC32Hex crc32的8位hex，eg: 文件校验、分片
+func CRC32Hex(data []byte) string {
+	s := strconv.FormatUint(uint64(CRC32(data)), 16)
+	for len(s) < 8 {
+		s = "0" + s
+	}
+	return s
+}
+
+// SecureCompare 常量时间比较，用于签名、验证码、token的比较，避免时序攻击
+func SecureCompare(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// SecureCompareBytes []byte版本的SecureCompare
+func SecureCompareBytes(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
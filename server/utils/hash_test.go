@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashBytes(t *testing.T) {
+	data := []byte("iiinsomnia")
+	assert.Equal(t, "483367436bc9a6c5256bfc29a24f955e", MD5Hex(data))
+	assert.Equal(t, "7a4082bd79f2086af2c2b792c5e0ad06e729b9c4", SHA1Hex(data))
+	assert.Equal(t, Hash(AlgoSha256, "iiinsomnia"), SHA256Hex(data))
+	assert.Equal(t, Hash(AlgoSha512, "iiinsomnia"), SHA512Hex(data))
+	assert.Equal(t, "SDNnQ2vJpsUla/wpok+VXg==", HashBase64(AlgoMD5, data))
+	assert.Nil(t, HashBytes("sm9", data))
+}
+
+func TestHMACBytes(t *testing.T) {
+	// RFC 4231 test case 2
+	data, key := []byte("what do ya want for nothing?"), []byte("Jefe")
+	assert.Equal(t, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", HMACHex(AlgoSha256, data, key))
+	assert.Equal(t, "W9zBRr9gdU5qBCQmCJV1x1oAPwidJzmDnexYuWTsOEM=", HMACBase64(AlgoSha256, data, key))
+	assert.Equal(t, HMAC(AlgoSha1, string(data), string(key)), HMACHex(AlgoSha1, data, key))
+	assert.Nil(t, HMACBytes("sm9", data, key))
+}
+
+func TestCRC32(t *testing.T) {
+	assert.Equal(t, uint32(0xcbf43926), CRC32([]byte("123456789")))
+	assert.Equal(t, "cbf43926", CRC32Hex([]byte("123456789")))
+	assert.Equal(t, "00000000", CRC32Hex(nil))
+}
+
+func TestSecureCompare(t *testing.T) {
+	assert.True(t, SecureCompare("abc", "abc"))
+	assert.False(t, SecureCompare("abc", "abd"))
+	assert.False(t, SecureCompare("abc", "ab"))
+	assert.True(t, SecureCompareBytes([]byte{1, 2}, []byte{1, 2}))
+}
@@ -8,9 +8,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-playground/locales/en"
 	"github.com/go-playground/locales/zh"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
 	zhcn "github.com/go-playground/validator/v10/translations/zh"
 	"github.com/hashicorp/go-version"
 	"go.uber.org/zap"
@@ -35,36 +37,13 @@ func (c CDATA) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 //返回根据给定格式字符串使用给定的 int64 时间戳格式化的字符串。
 //默认格式为：2006-01-02 15:04:05。
 func Date(timestamp int64, layout ...string) string {
-	l := "2006-01-02 15:04:05"
-
-	if len(layout) != 0 {
-		l = layout[0]
-	}
-
-	date := time.Unix(timestamp, 0).Local().Format(l)
-
-	return date
+	return DateIn(timestamp, time.Local, layout...)
 }
 
 // StrToTime 将英文文本日期时间描述解析为 Unix 时间戳。
 // 默认格式为： 2006-01-02 15:04:05.
 func StrToTime(datetime string, layout ...string) int64 {
-	l := "2006-01-02 15:04:05"
-
-	if len(layout) != 0 {
-		l = layout[0]
-	}
-
-	t, err := time.ParseInLocation(l, datetime, time.Local)
-
-	// mismatch layout
-	if err != nil {
-		zap.L().Error("parse layout mismatch", zap.Error(err))
-
-		return 0
-	}
-
-	return t.Unix()
+	return StrToTimeIn(datetime, time.Local, layout...)
 }
 
 // WeekAround 返回当前周的星期一和星期日的日期
@@ -114,6 +93,8 @@ func Long2IP(ip uint32) string {
 type Validator struct {
 	validator  *validator.Validate
 	translator ut.Translator
+	// 按语言的翻译器，eg: zh, en
+	translators map[string]ut.Translator
 }
 
 // ValidateStruct receives any kind of type, but only performed struct or pointer to struct type.
@@ -142,6 +123,55 @@ func (v *Validator) ValidateStruct(obj interface{}) error {
 	return nil
 }
 
+// ValidateFields 校验结构体，返回字段名(优先使用json tag)对应的错误信息，校验通过时返回nil
+func (v *Validator) ValidateFields(obj interface{}) (map[string]string, error) {
+	if reflect.Indirect(reflect.ValueOf(obj)).Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	if err := v.validator.Struct(obj); err != nil {
+		e, ok := err.(validator.ValidationErrors)
+
+		if !ok {
+			return nil, err
+		}
+
+		return RemoveTopStruct(e.Translate(v.translator)), nil
+	}
+
+	return nil, nil
+}
+
+// ValidateFieldsLocale 同ValidateFields，错误信息使用指定语言，不支持的语言使用中文
+func (v *Validator) ValidateFieldsLocale(obj interface{}, locale string) (map[string]string, error) {
+	if reflect.Indirect(reflect.ValueOf(obj)).Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	if err := v.validator.Struct(obj); err != nil {
+		e, ok := err.(validator.ValidationErrors)
+
+		if !ok {
+			return nil, err
+		}
+
+		return RemoveTopStruct(e.Translate(findTranslator(v.translators, locale, v.translator))), nil
+	}
+
+	return nil, nil
+}
+
+// RegisterRule 注册自定义验证规则，message为翻译模板，{0}会被替换为字段名
+// eg: v.RegisterRule("qq", fn, "{0}必须是有效的QQ号")
+func (v *Validator) RegisterRule(tag string, fn validator.Func, message string) error {
+	for _, trans := range v.translators {
+		if err := registerRules(v.validator, trans, rule{tag: tag, fn: fn, message: message}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Engine returns the underlying validator engine which powers the default
 // Validator instance. This is useful if you want to register custom validations
 // or struct level validations. See validator GoDoc for more info -
@@ -153,19 +183,37 @@ func (v *Validator) Engine() interface{} {
 // NewValidator returns a new validator.
 // Used for Gin: binding.Validator = yiigo.NewValidator()
 func NewValidator() *Validator {
-	locale := zh.New()
-	uniTrans := ut.New(locale)
+	uniTrans := ut.New(zh.New(), zh.New(), en.New())
 
 	validate := validator.New()
 	validate.SetTagName("valid")
+	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
 
-	translator, _ := uniTrans.GetTranslator("zh")
+	translators := make(map[string]ut.Translator, 2)
+	for locale, register := range map[string]func(*validator.Validate, ut.Translator) error{
+		"zh": zhcn.RegisterDefaultTranslations,
+		"en": enTranslations.RegisterDefaultTranslations,
+	} {
+		translator, _ := uniTrans.GetTranslator(locale)
 
-	zhcn.RegisterDefaultTranslations(validate, translator)
+		register(validate, translator)
+
+		if err := registerRules(validate, translator, builtinRules...); err != nil {
+			zap.L().Error("register builtin validate rules error", zap.Error(err))
+		}
+		translators[locale] = translator
+	}
 
 	return &Validator{
-		validator:  validate,
-		translator: translator,
+		validator:   validate,
+		translator:  translators["zh"],
+		translators: translators,
 	}
 }
 
@@ -0,0 +1,64 @@
+package httpclient
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 熔断器打开，请求未发出
+var ErrCircuitOpen = errors.New("httpclient: circuit breaker is open")
+
+// breaker 按host熔断，连续失败threshold次后打开，cooldown后放行一个探测请求(半开)，成功则关闭
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, hosts: make(map[string]*hostState)}
+}
+
+// allow 是否允许向host发送请求
+func (b *breaker) allow(host string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.hosts[host]
+	if !ok || s.failures < b.threshold {
+		return true
+	}
+	if s.probing || time.Since(s.openedAt) < b.cooldown {
+		return false
+	}
+	s.probing = true
+	return true
+}
+
+// report 记录请求结果
+func (b *breaker) report(host string, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if success {
+		delete(b.hosts, host)
+		return
+	}
+	s, ok := b.hosts[host]
+	if !ok {
+		s = &hostState{}
+		b.hosts[host] = s
+	}
+	s.failures++
+	s.probing = false
+	if s.failures >= b.threshold {
+		s.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,305 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"go-skeleton/pkg/tracing"
+	"go-skeleton/utils"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// 请求id的header，与middleware.RequestIDHeader一致
+const requestIDHeader = "X-Request-ID"
+
+// 幂等键的header，与middleware.IdempotencyKeyHeader一致，携带时非幂等的请求也可以重试
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// Client http客户端，支持超时、失败重试、熔断，并自动透传请求id和链路信息
+type Client struct {
+	client     *http.Client
+	baseURL    string
+	header     http.Header
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	breaker    *breaker
+}
+
+type Option func(*Client)
+
+// WithTimeout 单次请求的默认超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.client.Timeout = timeout
+	}
+}
+
+// WithRetry 网络错误或5xx时的重试次数，第n次重试前等待backoff*2^n(带随机抖动)，
+// 只重试幂等的方法和携带Idempotency-Key的请求，见Request.Retry
+func WithRetry(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// WithBreaker 同一host连续失败threshold次后熔断cooldown时间
+func WithBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.breaker = newBreaker(threshold, cooldown)
+	}
+}
+
+// WithBaseURL 请求的url不是完整地址时拼接baseURL
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHeader 每个请求都携带的header
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Set(key, value)
+	}
+}
+
+// WithTransport 自定义Transport，eg: 代理、证书
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.client.Transport = transport
+	}
+}
+
+// New 创建客户端，默认超时10秒，重试2次，连续失败5次熔断30秒
+func New(opts ...Option) *Client {
+	c := &Client{
+		client:     &http.Client{Timeout: 10 * time.Second},
+		header:     make(http.Header),
+		retries:    2,
+		backoff:    100 * time.Millisecond,
+		maxBackoff: 5 * time.Second,
+		breaker:    newBreaker(5, 30*time.Second),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Default 默认客户端
+var Default = New()
+
+// Request 请求参数
+type Request struct {
+	Method string
+	URL    string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+	// 覆盖客户端的超时时间，包含重试在内的总时间
+	Timeout time.Duration
+	// 不重试
+	NoRetry bool
+	// POST、PATCH等非幂等的方法默认不重试，避免服务端已处理但响应丢失时重复执行，
+	// 确认服务端可以去重时设置为true
+	Retry bool
+}
+
+// Response 响应
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// JSON 将响应解析为json
+func (r *Response) JSON(out interface{}) error {
+	return json.Unmarshal(r.Body, out)
+}
+
+// XML 将响应解析为xml
+func (r *Response) XML(out interface{}) error {
+	return xml.Unmarshal(r.Body, out)
+}
+
+// StatusError 响应状态码不是2xx
+type StatusError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	body := string(e.Body)
+	if len(body) > 256 {
+		body = body[:256] + "..."
+	}
+	return fmt.Sprintf("httpclient: unexpected status %d: %s", e.StatusCode, body)
+}
+
+// Do 发送请求，幂等的请求在网络错误或5xx时按配置重试。ctx可以直接传入*gin.Context
+func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
+	ctx = requestContext(ctx)
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+
+	u, err := c.buildURL(req)
+	if err != nil {
+		return nil, err
+	}
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	retries := c.retries
+	if req.NoRetry || !(req.Retry || c.idempotent(method, req.Header)) {
+		retries = 0
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(ctx, method, u, req)
+		retryable := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		if c.breaker != nil && err != ErrCircuitOpen {
+			c.breaker.report(u.Host, !retryable)
+		}
+		if !retryable || attempt >= retries || err == ErrCircuitOpen || ctx.Err() != nil {
+			if err == nil && resp.StatusCode >= http.StatusBadRequest {
+				return resp, &StatusError{StatusCode: resp.StatusCode, Body: resp.Body}
+			}
+			return resp, err
+		}
+
+		wait := c.wait(attempt)
+		utils.Logger(ctx).Warn("httpclient retry",
+			zap.String("url", u.String()), zap.Int("attempt", attempt+1), zap.Duration("wait", wait), zap.Error(err))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method string, u *url.URL, req *Request) (*Response, error) {
+	if c.breaker != nil && !c.breaker.allow(u.Host) {
+		return nil, ErrCircuitOpen
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, "HTTP "+method, trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	var body *bytes.Reader
+	if req.Body != nil {
+		body = bytes.NewReader(req.Body)
+	} else {
+		body = bytes.NewReader(nil)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.header {
+		httpReq.Header[k] = v
+	}
+	for k, v := range req.Header {
+		httpReq.Header[k] = v
+	}
+	if id := utils.RequestID(ctx); id != "" && httpReq.Header.Get(requestIDHeader) == "" {
+		httpReq.Header.Set(requestIDHeader, id)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
+	span.SetAttributes(semconv.HTTPClientAttributesFromHTTPRequest(httpReq)...)
+
+	httpResp, err := c.client.Do(httpReq)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	b, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(httpResp.StatusCode)...)
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(httpResp.StatusCode))
+	return &Response{StatusCode: httpResp.StatusCode, Header: httpResp.Header, Body: b}, nil
+}
+
+// idempotent 方法是幂等的，或请求携带了Idempotency-Key
+func (c *Client) idempotent(method string, header http.Header) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return header.Get(idempotencyKeyHeader) != "" || c.header.Get(idempotencyKeyHeader) != ""
+}
+
+func (c *Client) buildURL(req *Request) (*url.URL, error) {
+	raw := req.URL
+	if c.baseURL != "" && !strings.HasPrefix(raw, "http://") && !strings.HasPrefix(raw, "https://") {
+		raw = c.baseURL + "/" + strings.TrimLeft(raw, "/")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Query) > 0 {
+		q := u.Query()
+		for k, v := range req.Query {
+			q[k] = v
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u, nil
+}
+
+// wait 指数退避，带±20%的随机抖动
+func (c *Client) wait(attempt int) time.Duration {
+	d := c.backoff << uint(attempt)
+	if d <= 0 || d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/5 + 1))
+	if rand.Intn(2) == 0 {
+		return d - jitter
+	}
+	return d + jitter
+}
+
+// requestContext *gin.Context的Value不会查找c.Request.Context()，需要取出请求的context才能关联链路
+func requestContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	if c, ok := ctx.(*gin.Context); ok && c.Request != nil {
+		reqCtx := c.Request.Context()
+		if utils.RequestID(reqCtx) == "" {
+			reqCtx = utils.WithRequestID(reqCtx, c.GetString(utils.RequestIDKey))
+		}
+		return reqCtx
+	}
+	return ctx
+}
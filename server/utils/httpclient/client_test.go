@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"context"
+	"go-skeleton/utils"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"gin"}`))
+	}))
+	defer srv.Close()
+
+	c := New(WithRetry(2, time.Millisecond), WithBaseURL(srv.URL))
+	var out struct{ Name string }
+	assert.Nil(t, c.GetJSON(context.Background(), "/", nil, &out))
+	assert.Equal(t, "gin", out.Name)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c := New(WithRetry(2, time.Millisecond), WithBaseURL(srv.URL), WithBreaker(100, time.Second))
+	_, err := c.Do(context.Background(), &Request{Method: http.MethodPost, URL: "/"})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.SwapInt32(&calls, 0))
+
+	header := http.Header{}
+	header.Set("Idempotency-Key", "order-1")
+	_, err = c.Do(context.Background(), &Request{Method: http.MethodPost, URL: "/", Header: header})
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.SwapInt32(&calls, 0))
+
+	_, err = c.Do(context.Background(), &Request{Method: http.MethodPatch, URL: "/", Retry: true})
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.SwapInt32(&calls, 0))
+}
+
+func TestStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	err := New().GetJSON(context.Background(), srv.URL, nil, nil)
+	se, ok := err.(*StatusError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, se.StatusCode)
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get(requestIDHeader)))
+	}))
+	defer srv.Close()
+
+	ctx := utils.WithRequestID(context.Background(), "req-1")
+	resp, err := New().Get(ctx, srv.URL, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "req-1", string(resp.Body))
+}
+
+func TestBreaker(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := New(WithRetry(0, 0), WithBreaker(2, time.Hour))
+	for i := 0; i < 2; i++ {
+		_, err := c.Get(context.Background(), srv.URL, nil)
+		assert.NotNil(t, err)
+	}
+	_, err := c.Get(context.Background(), srv.URL, nil)
+	assert.Equal(t, ErrCircuitOpen, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,104 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Get 发送GET请求
+func (c *Client) Get(ctx context.Context, rawURL string, query url.Values) (*Response, error) {
+	return c.Do(ctx, &Request{Method: http.MethodGet, URL: rawURL, Query: query})
+}
+
+// GetJSON 发送GET请求并将响应解析为json
+func (c *Client) GetJSON(ctx context.Context, rawURL string, query url.Values, out interface{}) error {
+	resp, err := c.Get(ctx, rawURL, query)
+	if err != nil {
+		return err
+	}
+	return decode(resp, out, resp.JSON)
+}
+
+// PostJSON 以json格式提交body，并将响应解析为json，out为nil时不解析
+func (c *Client) PostJSON(ctx context.Context, rawURL string, body, out interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.Do(ctx, &Request{
+		Method: http.MethodPost,
+		URL:    rawURL,
+		Header: http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+		Body:   b,
+	})
+	if err != nil {
+		return err
+	}
+	return decode(resp, out, resp.JSON)
+}
+
+// PostForm 以表单格式提交，并将响应解析为json，out为nil时不解析
+func (c *Client) PostForm(ctx context.Context, rawURL string, form url.Values, out interface{}) error {
+	resp, err := c.Do(ctx, &Request{
+		Method: http.MethodPost,
+		URL:    rawURL,
+		Header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+		Body:   []byte(form.Encode()),
+	})
+	if err != nil {
+		return err
+	}
+	return decode(resp, out, resp.JSON)
+}
+
+// PostXML 以xml格式提交body，并将响应解析为xml，out为nil时不解析，eg: 微信支付
+func (c *Client) PostXML(ctx context.Context, rawURL string, body, out interface{}) error {
+	var buf bytes.Buffer
+	if err := xml.NewEncoder(&buf).Encode(body); err != nil {
+		return err
+	}
+	resp, err := c.Do(ctx, &Request{
+		Method: http.MethodPost,
+		URL:    rawURL,
+		Header: http.Header{"Content-Type": {"application/xml; charset=utf-8"}},
+		Body:   buf.Bytes(),
+	})
+	if err != nil {
+		return err
+	}
+	return decode(resp, out, resp.XML)
+}
+
+func decode(resp *Response, out interface{}, fn func(interface{}) error) error {
+	if out == nil || len(strings.TrimSpace(string(resp.Body))) == 0 {
+		return nil
+	}
+	return fn(out)
+}
+
+// 以下为默认客户端的快捷方法
+
+func Get(ctx context.Context, rawURL string, query url.Values) (*Response, error) {
+	return Default.Get(ctx, rawURL, query)
+}
+
+func GetJSON(ctx context.Context, rawURL string, query url.Values, out interface{}) error {
+	return Default.GetJSON(ctx, rawURL, query, out)
+}
+
+func PostJSON(ctx context.Context, rawURL string, body, out interface{}) error {
+	return Default.PostJSON(ctx, rawURL, body, out)
+}
+
+func PostForm(ctx context.Context, rawURL string, form url.Values, out interface{}) error {
+	return Default.PostForm(ctx, rawURL, form, out)
+}
+
+func PostXML(ctx context.Context, rawURL string, body, out interface{}) error {
+	return Default.PostXML(ctx, rawURL, body, out)
+}
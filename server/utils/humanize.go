@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidDuration 时长格式错误
+var ErrInvalidDuration = errors.New("invalid duration")
+
+// 人性化时间使用的单位，按从大到小排列
+var humanUnits = []struct {
+	d      time.Duration
+	zh, en string
+}{
+	{365 * 24 * time.Hour, "年", "year"},
+	{30 * 24 * time.Hour, "个月", "month"},
+	{24 * time.Hour, "天", "day"},
+	{time.Hour, "小时", "hour"},
+	{time.Minute, "分钟", "minute"},
+	{time.Second, "秒", "second"},
+}
+
+// 解析时长支持的单位，time.ParseDuration的单位之外增加了天和周
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+}
+
+// isEnglish locale是否为英文，其他语言都使用中文
+func isEnglish(locale string) bool {
+	return strings.HasPrefix(strings.ToLower(locale), "en")
+}
+
+// TimeAgo 返回t相对于当前时间的描述，eg: 3分钟前、2 days ago、in 5 minutes
+func TimeAgo(t time.Time, locale string) string {
+	return RelativeTime(t, time.Now(), locale)
+}
+
+// RelativeTime 返回t相对于now的描述，1分钟内为刚刚，只取最大的单位，eg: 1小时59分钟前为1小时前
+func RelativeTime(t, now time.Time, locale string) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	en := isEnglish(locale)
+	if d < time.Minute {
+		if en {
+			return "just now"
+		}
+		return "刚刚"
+	}
+
+	for _, u := range humanUnits {
+		n := int64(d / u.d)
+		if n == 0 {
+			continue
+		}
+		if en {
+			text := englishUnit(n, u.en)
+			if future {
+				return "in " + text
+			}
+			return text + " ago"
+		}
+		if future {
+			return fmt.Sprintf("%d%s后", n, u.zh)
+		}
+		return fmt.Sprintf("%d%s前", n, u.zh)
+	}
+	return ""
+}
+
+// HumanizeDuration 将时长格式化为可读的描述，精确到秒，eg: 1天2小时30分钟、1 day 2 hours 30 minutes
+func HumanizeDuration(d time.Duration, locale string) string {
+	if d < 0 {
+		d = -d
+	}
+
+	en := isEnglish(locale)
+	var parts []string
+	// 月和年的长度不固定，时长最大只用天表示
+	for _, u := range humanUnits[2:] {
+		n := int64(d / u.d)
+		if n == 0 {
+			continue
+		}
+		d -= time.Duration(n) * u.d
+		if en {
+			parts = append(parts, englishUnit(n, u.en))
+		} else {
+			parts = append(parts, strconv.FormatInt(n, 10)+u.zh)
+		}
+	}
+
+	if len(parts) == 0 {
+		if en {
+			return "0 seconds"
+		}
+		return "0秒"
+	}
+	if en {
+		return strings.Join(parts, " ")
+	}
+	return strings.Join(parts, "")
+}
+
+// Countdown 格式化倒计时，超过1天时小时数累加，eg: 01:02:03、26:00:00，小于0时为00:00:00
+func Countdown(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	secs := int64(d / time.Second)
+
+	return fmt.Sprintf("%02d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+}
+
+// ParseDuration 解析时长，在time.ParseDuration的基础上支持d(天)、w(周)，eg: 1d2h30m、1w、1.5h
+func ParseDuration(s string) (time.Duration, error) {
+	orig := s
+	s = strings.TrimSpace(s)
+
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "0" {
+		return 0, nil
+	}
+	if s == "" {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, orig)
+	}
+
+	var total float64
+	for s != "" {
+		// 数字部分
+		i := 0
+		for i < len(s) && (s[i] == '.' || ('0' <= s[i] && s[i] <= '9')) {
+			i++
+		}
+		n, err := strconv.ParseFloat(s[:i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, orig)
+		}
+		s = s[i:]
+
+		// 单位部分
+		i = 0
+		for i < len(s) && s[i] != '.' && (s[i] < '0' || s[i] > '9') {
+			i++
+		}
+		unit, ok := durationUnits[s[:i]]
+		if !ok {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, orig)
+		}
+		s = s[i:]
+
+		total += n * float64(unit)
+	}
+
+	if neg {
+		total = -total
+	}
+	return time.Duration(total), nil
+}
+
+// englishUnit 英文的数量和单位，eg: 1 day、2 days
+func englishUnit(n int64, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return strconv.FormatInt(n, 10) + " " + unit + "s"
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.Local)
+
+	assert.Equal(t, "刚刚", RelativeTime(now.Add(-30*time.Second), now, "zh"))
+	assert.Equal(t, "3分钟前", RelativeTime(now.Add(-3*time.Minute), now, "zh"))
+	assert.Equal(t, "1小时前", RelativeTime(now.Add(-119*time.Minute), now, "zh-CN"))
+	assert.Equal(t, "2天后", RelativeTime(now.Add(48*time.Hour), now, ""))
+	assert.Equal(t, "1年前", RelativeTime(now.AddDate(-1, 0, -1), now, "zh"))
+
+	assert.Equal(t, "just now", RelativeTime(now, now, "en"))
+	assert.Equal(t, "1 minute ago", RelativeTime(now.Add(-time.Minute), now, "en"))
+	assert.Equal(t, "2 days ago", RelativeTime(now.Add(-49*time.Hour), now, "en-US"))
+	assert.Equal(t, "in 3 months", RelativeTime(now.AddDate(0, 3, 1), now, "en"))
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	d := 26*time.Hour + 30*time.Minute
+
+	assert.Equal(t, "1天2小时30分钟", HumanizeDuration(d, "zh"))
+	assert.Equal(t, "1 day 2 hours 30 minutes", HumanizeDuration(d, "en"))
+	assert.Equal(t, "0秒", HumanizeDuration(500*time.Millisecond, "zh"))
+}
+
+func TestCountdown(t *testing.T) {
+	assert.Equal(t, "01:02:03", Countdown(time.Hour+2*time.Minute+3*time.Second))
+	assert.Equal(t, "26:00:00", Countdown(26*time.Hour))
+	assert.Equal(t, "00:00:00", Countdown(-time.Second))
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"1d2h30m": 26*time.Hour + 30*time.Minute,
+		"1w":      7 * 24 * time.Hour,
+		"1.5h":    90 * time.Minute,
+		"-2d":     -48 * time.Hour,
+		"300ms":   300 * time.Millisecond,
+		"0":       0,
+	}
+	for s, want := range cases {
+		d, err := ParseDuration(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, want, d, s)
+	}
+
+	for _, s := range []string{"", "1x", "d", "1d2"} {
+		_, err := ParseDuration(s)
+		assert.True(t, errors.Is(err, ErrInvalidDuration), s)
+	}
+}
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"image"
+	"io"
+	"os"
+
+	"github.com/disintegration/imaging"
+)
+
+// WatermarkPosition 水印位置
+type WatermarkPosition int
+
+const (
+	BottomRight WatermarkPosition = iota
+	BottomLeft
+	TopRight
+	TopLeft
+	Center
+)
+
+// 水印距离图片边缘的距离
+const watermarkMargin = 10
+
+type imageOp func(img image.Image) image.Image
+
+// ImagePipeline 图片处理流水线，按添加顺序执行，eg:
+//
+//	err := utils.NewImagePipeline().Resize(300, 0).Watermark(logo, utils.BottomRight, 0.6).Process(r, w, "a.jpg")
+//
+// 重新编码后的图片不包含EXIF信息
+type ImagePipeline struct {
+	ops     []imageOp
+	quality int
+}
+
+func NewImagePipeline() *ImagePipeline {
+	return &ImagePipeline{quality: 85}
+}
+
+// Resize 缩放，宽或高为0时按比例计算
+func (p *ImagePipeline) Resize(width, height int) *ImagePipeline {
+	p.ops = append(p.ops, func(img image.Image) image.Image {
+		return imaging.Resize(img, width, height, imaging.Lanczos)
+	})
+	return p
+}
+
+// Fit 等比缩放到不超过width*height，图片比目标小时不处理
+func (p *ImagePipeline) Fit(width, height int) *ImagePipeline {
+	p.ops = append(p.ops, func(img image.Image) image.Image {
+		return imaging.Fit(img, width, height, imaging.Lanczos)
+	})
+	return p
+}
+
+// Thumbnail 缩放并居中裁剪为width*height的缩略图
+func (p *ImagePipeline) Thumbnail(width, height int) *ImagePipeline {
+	p.ops = append(p.ops, func(img image.Image) image.Image {
+		return imaging.Fill(img, width, height, imaging.Center, imaging.Lanczos)
+	})
+	return p
+}
+
+// Crop 从(x, y)开始裁剪width*height的区域
+func (p *ImagePipeline) Crop(x, y, width, height int) *ImagePipeline {
+	p.ops = append(p.ops, func(img image.Image) image.Image {
+		min := img.Bounds().Min
+		return imaging.Crop(img, image.Rect(min.X+x, min.Y+y, min.X+x+width, min.Y+y+height))
+	})
+	return p
+}
+
+// CropCenter 从中心裁剪width*height的区域
+func (p *ImagePipeline) CropCenter(width, height int) *ImagePipeline {
+	p.ops = append(p.ops, func(img image.Image) image.Image {
+		return imaging.CropCenter(img, width, height)
+	})
+	return p
+}
+
+// Watermark 添加图片水印，opacity为0~1的透明度
+func (p *ImagePipeline) Watermark(mark image.Image, pos WatermarkPosition, opacity float64) *ImagePipeline {
+	p.ops = append(p.ops, func(img image.Image) image.Image {
+		b, m := img.Bounds(), mark.Bounds()
+		var pt image.Point
+		switch pos {
+		case TopLeft:
+			pt = image.Pt(watermarkMargin, watermarkMargin)
+		case TopRight:
+			pt = image.Pt(b.Dx()-m.Dx()-watermarkMargin, watermarkMargin)
+		case BottomLeft:
+			pt = image.Pt(watermarkMargin, b.Dy()-m.Dy()-watermarkMargin)
+		case Center:
+			pt = image.Pt((b.Dx()-m.Dx())/2, (b.Dy()-m.Dy())/2)
+		default:
+			pt = image.Pt(b.Dx()-m.Dx()-watermarkMargin, b.Dy()-m.Dy()-watermarkMargin)
+		}
+		return imaging.Overlay(img, mark, b.Min.Add(pt), opacity)
+	})
+	return p
+}
+
+// Quality jpeg的压缩质量，1~100
+func (p *ImagePipeline) Quality(quality int) *ImagePipeline {
+	p.quality = quality
+	return p
+}
+
+// Apply 对图片执行所有处理
+func (p *ImagePipeline) Apply(img image.Image) image.Image {
+	for _, op := range p.ops {
+		img = op(img)
+	}
+	return img
+}
+
+// Process 读取图片并按EXIF方向旋转，处理后按name的扩展名编码写入w，不保留EXIF信息
+func (p *ImagePipeline) Process(r io.Reader, w io.Writer, name string) error {
+	format, err := imaging.FormatFromFilename(name)
+	if err != nil {
+		return err
+	}
+	img, err := imaging.Decode(r, imaging.AutoOrientation(true))
+	if err != nil {
+		return err
+	}
+	return imaging.Encode(w, p.Apply(img), format, imaging.JPEGQuality(p.quality))
+}
+
+// ProcessFile 处理src文件并保存到dst
+func (p *ImagePipeline) ProcessFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if err := p.Process(in, out, dst); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// StripExif 去掉图片的EXIF信息(拍摄地点、设备等)，图片会按EXIF方向旋转为正
+func StripExif(r io.Reader, w io.Writer, name string) error {
+	return NewImagePipeline().Quality(95).Process(r, w, name)
+}
+
+// OpenImage 读取图片文件，eg: 水印图片
+func OpenImage(file string) (image.Image, error) {
+	return imaging.Open(file)
+}
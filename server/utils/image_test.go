@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testImage(w, h int) image.Image {
+	return fillImage(w, h, color.White)
+}
+
+func fillImage(w, h int, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestImagePipeline(t *testing.T) {
+	img := NewImagePipeline().Resize(100, 0).Apply(testImage(400, 200))
+	assert.Equal(t, image.Rect(0, 0, 100, 50), img.Bounds())
+
+	img = NewImagePipeline().Thumbnail(60, 60).Apply(testImage(400, 200))
+	assert.Equal(t, image.Rect(0, 0, 60, 60), img.Bounds())
+
+	img = NewImagePipeline().Crop(10, 10, 30, 20).Apply(testImage(400, 200))
+	assert.Equal(t, 30, img.Bounds().Dx())
+	assert.Equal(t, 20, img.Bounds().Dy())
+
+	mark := fillImage(10, 10, color.Black)
+	img = NewImagePipeline().Watermark(mark, BottomRight, 1).Apply(testImage(100, 100))
+	r, g, b, _ := img.At(85, 85).RGBA()
+	assert.Equal(t, uint32(0), r+g+b)
+	r, _, _, _ = img.At(50, 50).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+}
+
+func TestImagePipelineProcess(t *testing.T) {
+	var src, dst bytes.Buffer
+	assert.Nil(t, png.Encode(&src, testImage(200, 100)))
+	assert.Nil(t, NewImagePipeline().Fit(50, 50).Process(&src, &dst, "a.jpg"))
+
+	cfg, format, err := image.DecodeConfig(&dst)
+	assert.Nil(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, 50, cfg.Width)
+	assert.Equal(t, 25, cfg.Height)
+}
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// 内网、回环、链路本地地址段，go1.17之前net.IP没有IsPrivate
+var (
+	privateNets   = mustParseCIDRs("10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7")
+	loopbackNets  = mustParseCIDRs("127.0.0.0/8", "::1/128")
+	linkLocalNets = mustParseCIDRs("169.254.0.0/16", "fe80::/10")
+)
+
+// IP2BigInt 将IPv4或IPv6地址转换为整数，IPv4的结果同IP2Long，地址不合法时返回nil
+func IP2BigInt(ip string) *big.Int {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return nil
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return new(big.Int).SetBytes(v4)
+	}
+	return new(big.Int).SetBytes(parsed.To16())
+}
+
+// BigInt2IP 将整数转换为IP地址，ipv6为false时按IPv4转换，超出范围时返回空字符串
+func BigInt2IP(n *big.Int, ipv6 bool) string {
+	size := net.IPv6len
+	if !ipv6 {
+		size = net.IPv4len
+	}
+	if n == nil || n.Sign() < 0 || n.BitLen() > size*8 {
+		return ""
+	}
+	b := make([]byte, size)
+	n.FillBytes(b)
+	return net.IP(b).String()
+}
+
+// IP2Bytes 将IP地址转换为16字节，IPv4转换为IPv4-mapped IPv6地址(::ffff:a.b.c.d)，可用于binary(16)字段
+func IP2Bytes(ip string) ([16]byte, bool) {
+	var b [16]byte
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return b, false
+	}
+	copy(b[:], parsed.To16())
+	return b, true
+}
+
+// Bytes2IP 将16字节转换为IP地址，IPv4-mapped地址输出为IPv4格式
+func Bytes2IP(b [16]byte) string {
+	return net.IP(b[:]).String()
+}
+
+// IsIPv4 是否是IPv4地址
+func IsIPv4(ip string) bool {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	return parsed != nil && parsed.To4() != nil
+}
+
+// IsIPv6 是否是IPv6地址，IPv4-mapped地址视为IPv4
+func IsIPv6(ip string) bool {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	return parsed != nil && parsed.To4() == nil
+}
+
+// ParseCIDRs 解析网段，不带掩码的地址视为单个IP，eg: 10.0.0.0/8, 192.168.1.1, ::1
+func ParseCIDRs(cidrs ...string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip %q", cidr)
+			}
+			if v4 := ip.To4(); v4 != nil {
+				nets = append(nets, &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)})
+			} else {
+				nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)})
+			}
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets, err := ParseCIDRs(cidrs...)
+	if err != nil {
+		panic(err)
+	}
+	return nets
+}
+
+// IPInCIDR ip是否在网段内，地址或网段不合法时返回false，eg: IPInCIDR("192.168.1.10", "192.168.1.0/24")
+func IPInCIDR(ip, cidr string) bool {
+	nets, err := ParseCIDRs(cidr)
+	if err != nil {
+		return false
+	}
+	return IPInNets(net.ParseIP(strings.TrimSpace(ip)), nets)
+}
+
+// IPInNets ip是否在任意一个网段内
+func IPInNets(ip net.IP, nets []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPrivateIP 是否是内网地址，包括RFC1918、运营商级NAT(100.64.0.0/10)和IPv6唯一本地地址(fc00::/7)
+func IsPrivateIP(ip string) bool {
+	return IPInNets(net.ParseIP(strings.TrimSpace(ip)), privateNets)
+}
+
+// IsLoopbackIP 是否是回环地址
+func IsLoopbackIP(ip string) bool {
+	return IPInNets(net.ParseIP(strings.TrimSpace(ip)), loopbackNets)
+}
+
+// IsPublicIP 是否是公网地址，排除内网、回环、链路本地、组播和未指定地址
+func IsPublicIP(ip string) bool {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil || parsed.IsUnspecified() || parsed.IsMulticast() {
+		return false
+	}
+	return !IPInNets(parsed, privateNets) && !IPInNets(parsed, loopbackNets) && !IPInNets(parsed, linkLocalNets)
+}
+
+// RealIP 获取客户端的真实ip，只有直接连接的地址在trusted中时才读取X-Forwarded-For、X-Real-IP，
+// X-Forwarded-For从右往左跳过可信代理，第一个不可信的地址即为客户端，避免客户端伪造请求头
+func RealIP(r *http.Request, trusted []*net.IPNet) string {
+	remote := strings.TrimSpace(r.RemoteAddr)
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if !IPInNets(net.ParseIP(remote), trusted) {
+		return remote
+	}
+
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		ips := strings.Split(xff, ",")
+		for i := len(ips) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(ips[i])
+			parsed := net.ParseIP(ip)
+			if parsed == nil {
+				break
+			}
+			if i == 0 || !IPInNets(parsed, trusted) {
+				return parsed.String()
+			}
+		}
+	}
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return remote
+}
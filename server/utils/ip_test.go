@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIP2BigInt(t *testing.T) {
+	assert.Equal(t, big.NewInt(3221234342), IP2BigInt("192.0.34.166"))
+	assert.Equal(t, big.NewInt(1), IP2BigInt("::1"))
+	assert.Nil(t, IP2BigInt("abc"))
+
+	n, _ := new(big.Int).SetString("42540766411282592856903984951653826561", 10)
+	assert.Equal(t, n, IP2BigInt("2001:db8::1"))
+	assert.Equal(t, "2001:db8::1", BigInt2IP(n, true))
+	assert.Equal(t, "192.0.34.166", BigInt2IP(big.NewInt(3221234342), false))
+	assert.Equal(t, "", BigInt2IP(n, false))
+}
+
+func TestIP2Bytes(t *testing.T) {
+	b, ok := IP2Bytes("192.168.1.1")
+	assert.True(t, ok)
+	assert.Equal(t, "192.168.1.1", Bytes2IP(b))
+
+	b, ok = IP2Bytes("2001:db8::1")
+	assert.True(t, ok)
+	assert.Equal(t, "2001:db8::1", Bytes2IP(b))
+
+	_, ok = IP2Bytes("")
+	assert.False(t, ok)
+}
+
+func TestIPInCIDR(t *testing.T) {
+	assert.True(t, IPInCIDR("192.168.1.10", "192.168.1.0/24"))
+	assert.False(t, IPInCIDR("192.168.2.10", "192.168.1.0/24"))
+	assert.True(t, IPInCIDR("2001:db8::ff", "2001:db8::/64"))
+	assert.True(t, IPInCIDR("10.0.0.1", "10.0.0.1"))
+	assert.False(t, IPInCIDR("10.0.0.1", "bad"))
+}
+
+func TestIPKinds(t *testing.T) {
+	assert.True(t, IsIPv4("1.2.3.4"))
+	assert.True(t, IsIPv6("fe80::1"))
+	assert.False(t, IsIPv6("::ffff:1.2.3.4"))
+
+	assert.True(t, IsPrivateIP("172.16.5.4"))
+	assert.True(t, IsPrivateIP("fd00::1"))
+	assert.False(t, IsPrivateIP("8.8.8.8"))
+	assert.True(t, IsLoopbackIP("127.0.0.1"))
+	assert.True(t, IsLoopbackIP("::1"))
+
+	assert.True(t, IsPublicIP("8.8.8.8"))
+	assert.True(t, IsPublicIP("2400:3200::1"))
+	assert.False(t, IsPublicIP("169.254.1.1"))
+	assert.False(t, IsPublicIP("0.0.0.0"))
+}
+
+func TestRealIP(t *testing.T) {
+	trusted, err := ParseCIDRs("10.0.0.0/8", "127.0.0.1")
+	assert.Nil(t, err)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.2:1234"
+	r.Header.Set("X-Forwarded-For", "1.1.1.1, 2.2.2.2, 10.0.0.3")
+	assert.Equal(t, "2.2.2.2", RealIP(r, trusted))
+
+	r.Header.Set("X-Forwarded-For", "10.0.0.5, 10.0.0.3")
+	assert.Equal(t, "10.0.0.5", RealIP(r, trusted))
+
+	r.Header.Del("X-Forwarded-For")
+	r.Header.Set("X-Real-IP", "3.3.3.3")
+	assert.Equal(t, "3.3.3.3", RealIP(r, trusted))
+
+	// 不可信的地址不读取请求头
+	r.RemoteAddr = "4.4.4.4:1234"
+	r.Header.Set("X-Forwarded-For", "1.1.1.1")
+	assert.Equal(t, "4.4.4.4", RealIP(r, trusted))
+
+	r.RemoteAddr = "[::1]:1234"
+	assert.Equal(t, "::1", RealIP(r, trusted))
+}
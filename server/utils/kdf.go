@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// key derivation parameters
+const (
+	// PBKDF2Iterations OWASP recommended iterations for PBKDF2-HMAC-SHA256
+	PBKDF2Iterations = 310000
+	// scrypt cost parameters, N=2^15 takes about 100ms and 32MB memory
+	ScryptN = 1 << 15
+	ScryptR = 8
+	ScryptP = 1
+	// SaltSize default salt length
+	SaltSize = 16
+)
+
+// ErrShortCipherText cipher text is shorter than the IV
+var ErrShortCipherText = errors.New("cipher text too short")
+
+// GenerateSalt returns n random bytes, n <= 0 uses SaltSize
+func GenerateSalt(n int) ([]byte, error) {
+	if n <= 0 {
+		n = SaltSize
+	}
+	salt := make([]byte, n)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// PBKDF2Key derives a key of keyLen bytes with PBKDF2-HMAC-SHA256, iterations <= 0 uses PBKDF2Iterations
+func PBKDF2Key(password, salt []byte, iterations, keyLen int) []byte {
+	if iterations <= 0 {
+		iterations = PBKDF2Iterations
+	}
+	return pbkdf2.Key(password, salt, iterations, keyLen, sha256.New)
+}
+
+// HKDFKey expands a high-entropy secret (eg: master key, ECDH shared secret) into a key of keyLen bytes,
+// info binds the key to its purpose, eg: []byte("cookie-encryption")
+func HKDFKey(secret, salt, info []byte, keyLen int) ([]byte, error) {
+	key := make([]byte, keyLen)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ScryptKey derives a key of keyLen bytes with scrypt(ScryptN, ScryptR, ScryptP)
+func ScryptKey(password, salt []byte, keyLen int) ([]byte, error) {
+	return scrypt.Key(password, salt, ScryptN, ScryptR, ScryptP, keyLen)
+}
+
+// passphraseCBC aes-256-cbc with a random IV for every message, the IV is prepended to the cipher text
+type passphraseCBC struct {
+	key []byte
+}
+
+func (c *passphraseCBC) Encrypt(plainText []byte) ([]byte, error) {
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	cipherText, err := NewCBCCrypto(c.key, iv, PKCS5).Encrypt(plainText)
+	if err != nil {
+		return nil, err
+	}
+	return append(iv, cipherText...), nil
+}
+
+func (c *passphraseCBC) Decrypt(cipherText []byte) ([]byte, error) {
+	if len(cipherText) < 2*aes.BlockSize || len(cipherText)%aes.BlockSize != 0 {
+		return nil, ErrShortCipherText
+	}
+	return NewCBCCrypto(c.key, cipherText[:aes.BlockSize], PKCS5).Decrypt(cipherText[aes.BlockSize:])
+}
+
+// NewCBCCryptoFromPassphrase returns an aes-256-cbc crypto whose key is derived from a user-provided passphrase
+// with PBKDF2, salt should be random (GenerateSalt) and stored with the data, eg:
+//
+//	salt, _ := utils.GenerateSalt(0)
+//	c := utils.NewCBCCryptoFromPassphrase([]byte(pass), salt)
+//	cipherText, _ := c.Encrypt(data)
+//
+// a random IV is generated for every Encrypt and prepended to the cipher text, so the same data encrypts differently
+func NewCBCCryptoFromPassphrase(pass, salt []byte) AESCrypto {
+	return &passphraseCBC{key: PBKDF2Key(pass, salt, 0, 32)}
+}
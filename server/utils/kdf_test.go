@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPBKDF2Key(t *testing.T) {
+	// RFC 7914 section 11
+	key := PBKDF2Key([]byte("passwd"), []byte("salt"), 1, 64)
+	assert.Equal(t, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783", hex.EncodeToString(key))
+	assert.Len(t, PBKDF2Key([]byte("passwd"), []byte("salt"), 1000, 32), 32)
+}
+
+func TestHKDFKey(t *testing.T) {
+	// RFC 5869 test case 1
+	ikm, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	key, err := HKDFKey(ikm, salt, info, 42)
+	assert.Nil(t, err)
+	assert.Equal(t, "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865", hex.EncodeToString(key))
+}
+
+func TestScryptKey(t *testing.T) {
+	key, err := ScryptKey([]byte("password"), []byte("salt"), 32)
+	assert.Nil(t, err)
+	assert.Len(t, key, 32)
+	again, _ := ScryptKey([]byte("password"), []byte("salt"), 32)
+	assert.Equal(t, key, again)
+}
+
+func TestNewCBCCryptoFromPassphrase(t *testing.T) {
+	salt, err := GenerateSalt(0)
+	assert.Nil(t, err)
+	assert.Len(t, salt, SaltSize)
+
+	c := NewCBCCryptoFromPassphrase([]byte("123"), salt)
+	data := []byte("Hello World")
+	a, err := c.Encrypt(data)
+	assert.Nil(t, err)
+	b, _ := c.Encrypt(data)
+	assert.NotEqual(t, a, b)
+
+	plain, err := NewCBCCryptoFromPassphrase([]byte("123"), salt).Decrypt(a)
+	assert.Nil(t, err)
+	assert.Equal(t, data, plain)
+
+	_, err = c.Decrypt(a[:16])
+	assert.Equal(t, ErrShortCipherText, err)
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// gcm standard nonce size
+const gcmNonceSize = 12
+
+// encrypted value prefix, eg: enc:k2:base64(nonce+cipher text)
+const keyringPrefix = "enc:"
+
+var (
+	// ErrUnknownKeyId cipher text was encrypted with a key which is not in the keyring
+	ErrUnknownKeyId = errors.New("keyring: unknown key id")
+	// ErrNotEncrypted value does not have the keyring prefix
+	ErrNotEncrypted = errors.New("keyring: value is not encrypted")
+)
+
+// Keyring aes-gcm keys identified by key id, Encrypt uses the current key and
+// Decrypt chooses the key by the id stored in the cipher text, so old keys can be kept for rotation
+type Keyring struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewKeyring keys are 16, 24 or 32 bytes, current must be one of keys
+func NewKeyring(current string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("keyring: current key %q not found", current)
+	}
+	k := &Keyring{current: current, keys: make(map[string][]byte, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("keyring: invalid key id %q", id)
+		}
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return nil, fmt.Errorf("keyring: key %q must be 16, 24 or 32 bytes", id)
+		}
+		k.keys[id] = key
+	}
+	return k, nil
+}
+
+// CurrentKeyId the key id used by Encrypt
+func (k *Keyring) CurrentKeyId() string {
+	return k.current
+}
+
+// Encrypt encrypts with the current key and a random nonce
+func (k *Keyring) Encrypt(plainText []byte) (string, error) {
+	nonce := make([]byte, gcmNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	cipherText, err := NewGCMCrypto(k.keys[k.current], nonce).Encrypt(plainText)
+	if err != nil {
+		return "", err
+	}
+	return keyringPrefix + k.current + ":" + Base64RawURLEncode(append(nonce, cipherText...)), nil
+}
+
+// Decrypt decrypts the value returned by Encrypt
+func (k *Keyring) Decrypt(value string) ([]byte, error) {
+	id, data, ok := parseKeyring(value)
+	if !ok {
+		return nil, ErrNotEncrypted
+	}
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyId, id)
+	}
+	b, err := Base64RawURLDecode(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < gcmNonceSize {
+		return nil, ErrShortCipherText
+	}
+	return NewGCMCrypto(key, b[:gcmNonceSize]).Decrypt(b[gcmNonceSize:])
+}
+
+// KeyId returns the key id of the encrypted value, ok is false when the value is not encrypted
+func KeyId(value string) (id string, ok bool) {
+	id, _, ok = parseKeyring(value)
+	return
+}
+
+func parseKeyring(value string) (id, data string, ok bool) {
+	if !strings.HasPrefix(value, keyringPrefix) {
+		return "", "", false
+	}
+	parts := strings.SplitN(value[len(keyringPrefix):], ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
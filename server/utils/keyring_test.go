@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyring(t *testing.T) {
+	k1 := []byte("0123456789abcdef0123456789abcdef")
+	k2 := []byte("fedcba9876543210")
+
+	old, err := NewKeyring("k1", map[string][]byte{"k1": k1})
+	assert.Nil(t, err)
+	cipherText, err := old.Encrypt([]byte("13800138000"))
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(cipherText, "enc:k1:"))
+	id, ok := KeyId(cipherText)
+	assert.True(t, ok)
+	assert.Equal(t, "k1", id)
+
+	// random nonce
+	again, _ := old.Encrypt([]byte("13800138000"))
+	assert.NotEqual(t, cipherText, again)
+
+	// rotated keyring still decrypts values of the old key
+	rotated, err := NewKeyring("k2", map[string][]byte{"k1": k1, "k2": k2})
+	assert.Nil(t, err)
+	plain, err := rotated.Decrypt(cipherText)
+	assert.Nil(t, err)
+	assert.Equal(t, "13800138000", string(plain))
+	newText, _ := rotated.Encrypt(plain)
+	id, _ = KeyId(newText)
+	assert.Equal(t, "k2", id)
+
+	_, err = old.Decrypt(newText)
+	assert.True(t, errors.Is(err, ErrUnknownKeyId))
+	_, err = old.Decrypt("13800138000")
+	assert.Equal(t, ErrNotEncrypted, err)
+	_, err = old.Decrypt(cipherText[:len(cipherText)-2] + "AA")
+	assert.NotNil(t, err)
+}
+
+func TestNewKeyring(t *testing.T) {
+	_, err := NewKeyring("k2", map[string][]byte{"k1": make([]byte, 32)})
+	assert.NotNil(t, err)
+	_, err = NewKeyring("k1", map[string][]byte{"k1": make([]byte, 10)})
+	assert.NotNil(t, err)
+	_, err = NewKeyring("a:b", map[string][]byte{"a:b": make([]byte, 16)})
+	assert.NotNil(t, err)
+}
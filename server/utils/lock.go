@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"go-skeleton/pkg/gredis"
+	"sync"
+	"time"
+
+	"github.com/bsm/redislock"
+	"go.uber.org/zap"
+)
+
+// ErrLockNotHeld 未持有锁
+var ErrLockNotHeld = errors.New("lock not held")
+
+// 加锁失败后的重试间隔
+const lockRetryInterval = 100 * time.Millisecond
+
+// DistributedLock 基于redis的分布式锁(SET NX + 随机token + Lua脚本释放)。
+// 获得锁后会启动看门狗，每ttl/3续期一次，直到Unlock，避免任务未执行完锁就过期
+type DistributedLock struct {
+	key string
+	ttl time.Duration
+
+	mu   sync.Mutex
+	lock *redislock.Lock
+	stop chan struct{}
+}
+
+// NewDistributedLock 创建分布式锁，eg:
+//
+//	lock := utils.NewDistributedLock("cron:sitemap", time.Minute)
+//	if ok, _ := lock.TryLock(ctx); !ok {
+//		return
+//	}
+//	defer lock.Unlock(ctx)
+func NewDistributedLock(key string, ttl time.Duration) *DistributedLock {
+	return &DistributedLock{
+		key: key,
+		ttl: ttl,
+	}
+}
+
+// TryLock 尝试加锁一次，锁被占用时返回false
+func (l *DistributedLock) TryLock(ctx context.Context) (bool, error) {
+	lock, err := redislock.Obtain(ctx, gredis.GetRedis(), l.key, l.ttl, nil)
+	if err == redislock.ErrNotObtained {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	l.hold(lock)
+	return true, nil
+}
+
+// Lock 阻塞直到加锁成功，或ctx取消、超时
+func (l *DistributedLock) Lock(ctx context.Context) error {
+	opt := &redislock.Options{RetryStrategy: redislock.LinearBackoff(lockRetryInterval)}
+	for {
+		lock, err := redislock.Obtain(ctx, gredis.GetRedis(), l.key, l.ttl, opt)
+		if err == nil {
+			l.hold(lock)
+			return nil
+		}
+		if err != redislock.ErrNotObtained {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// Unlock 释放锁并停止续期
+func (l *DistributedLock) Unlock(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.lock == nil {
+		return ErrLockNotHeld
+	}
+	close(l.stop)
+	lock := l.lock
+	l.lock = nil
+	if err := lock.Release(ctx); err != nil {
+		if err == redislock.ErrLockNotHeld {
+			return ErrLockNotHeld
+		}
+		return err
+	}
+	return nil
+}
+
+// hold 保存获得的锁并启动看门狗
+func (l *DistributedLock) hold(lock *redislock.Lock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lock = lock
+	l.stop = make(chan struct{})
+	go l.watchdog(lock, l.stop)
+}
+
+// watchdog 定时续期，续期失败说明锁已丢失，停止续期
+func (l *DistributedLock) watchdog(lock *redislock.Lock, stop chan struct{}) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+			err := lock.Refresh(ctx, l.ttl, nil)
+			cancel()
+			if err != nil {
+				zap.L().Error("refresh lock failed", zap.String("key", l.key), zap.Error(err))
+				return
+			}
+		}
+	}
+}
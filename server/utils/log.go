@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// RequestIDKey 请求id在gin.Context中的key
+const RequestIDKey = "requestId"
+
+type requestIDCtxKey struct{}
+
+// WithRequestID 将请求id保存到context
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, id)
+}
+
+// RequestID 获取context中的请求id，支持gin.Context和request.Context()
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(requestIDCtxKey{}).(string); ok {
+		return id
+	}
+	if id, ok := ctx.Value(RequestIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// Logger 返回带有请求id的zap logger，eg: utils.Logger(c).Info("create article", zap.Int64("id", id))
+func Logger(ctx context.Context) *zap.Logger {
+	if id := RequestID(ctx); id != "" {
+		return zap.L().With(zap.String("request-id", id))
+	}
+	return zap.L()
+}
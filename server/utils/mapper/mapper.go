@@ -0,0 +1,408 @@
+package mapper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 结构体之间的字段复制，用于请求参数、模型、输出结构之间的转换，eg:
+//	var ret UserResponse
+//	err := mapper.Copy(&ret, user, mapper.Ignore("Password"))
+//
+// 字段按名称匹配，名称不同时用mapper tag或WithMapping指定来源字段，mapper:"-"忽略该字段
+// 切片、map、指针都会新建，复制结果和src不共享数据
+
+// TimeLayout time.Time和字符串互转的格式，解析失败时再尝试RFC3339
+var TimeLayout = "2006-01-02 15:04:05"
+
+var ErrInvalidDst = errors.New("mapper: dst must be a non-nil pointer")
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type Option func(*options)
+
+type options struct {
+	mapping     map[string]string
+	ignore      map[string]bool
+	ignoreEmpty bool
+}
+
+// WithMapping 指定字段的来源，key为dst的字段，value为src的字段，嵌套字段用.连接，eg: {"Cid": "CategoryId", "Author.Name": "Nickname"}
+func WithMapping(mapping map[string]string) Option {
+	return func(o *options) {
+		for k, v := range mapping {
+			o.mapping[k] = v
+		}
+	}
+}
+
+// Ignore 不复制dst的这些字段，嵌套字段用.连接
+func Ignore(fields ...string) Option {
+	return func(o *options) {
+		for _, f := range fields {
+			o.ignore[f] = true
+		}
+	}
+}
+
+// IgnoreEmpty 跳过src中的零值字段，用于部分更新
+func IgnoreEmpty() Option {
+	return func(o *options) {
+		o.ignoreEmpty = true
+	}
+}
+
+var converters sync.Map
+
+type typePair struct {
+	src, dst reflect.Type
+}
+
+// RegisterConverter 注册自定义的类型转换，fn的格式为func(S) (D, error)，eg:
+//	mapper.RegisterConverter(func(t model.Time) (int64, error) {
+//		return t.Unix(), nil
+//	})
+func RegisterConverter(fn interface{}) {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.NumOut() != 2 || ft.Out(1) != reflect.TypeOf((*error)(nil)).Elem() {
+		panic("mapper: converter must be func(S) (D, error)")
+	}
+	converters.Store(typePair{ft.In(0), ft.Out(0)}, fv)
+}
+
+// Copy 复制src到dst，dst必须为非nil指针，支持结构体、切片、map及其指针
+func Copy(dst, src interface{}, opts ...Option) error {
+	o := &options{mapping: map[string]string{}, ignore: map[string]bool{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return ErrInvalidDst
+	}
+	sv := reflect.ValueOf(src)
+	if !sv.IsValid() {
+		return nil
+	}
+	return o.copy(dv.Elem(), sv, "")
+}
+
+func (o *options) copy(dst, src reflect.Value, path string) error {
+	if fn, ok := converters.Load(typePair{src.Type(), dst.Type()}); ok {
+		out := fn.(reflect.Value).Call([]reflect.Value{src})
+		if err, _ := out[1].Interface().(error); err != nil {
+			return err
+		}
+		dst.Set(out[0])
+		return nil
+	}
+	if src.Kind() == reflect.Ptr || src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		return o.copy(dst, src.Elem(), path)
+	}
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return o.copy(dst.Elem(), src, path)
+	}
+
+	switch {
+	case dst.Kind() == reflect.Struct && src.Kind() == reflect.Struct && dst.Type() != timeType && src.Type() != timeType:
+		// 含有未导出字段的同类型结构体(如carbon.Carbon)无法逐个字段复制，直接赋值
+		if src.Type() == dst.Type() && hasUnexported(src.Type()) {
+			dst.Set(src)
+			return nil
+		}
+		return o.copyStruct(dst, src, path)
+	case dst.Kind() == reflect.Slice && (src.Kind() == reflect.Slice || src.Kind() == reflect.Array):
+		if src.Kind() == reflect.Slice && src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		list := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := o.copy(list.Index(i), src.Index(i), path); err != nil {
+				return err
+			}
+		}
+		dst.Set(list)
+		return nil
+	case dst.Kind() == reflect.Map && src.Kind() == reflect.Map:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		m := reflect.MakeMapWithSize(dst.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			k := reflect.New(dst.Type().Key()).Elem()
+			if err := o.copy(k, iter.Key(), path); err != nil {
+				return err
+			}
+			v := reflect.New(dst.Type().Elem()).Elem()
+			if err := o.copy(v, iter.Value(), path); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, v)
+		}
+		dst.Set(m)
+		return nil
+	}
+	return convert(dst, src)
+}
+
+func (o *options) copyStruct(dst, src reflect.Value, path string) error {
+	fields := make(map[string]reflect.Value)
+	collectFields(src, fields)
+	lower := make(map[string]reflect.Value, len(fields))
+	for name, v := range fields {
+		lower[strings.ToLower(name)] = v
+	}
+
+	dt := dst.Type()
+	for i := 0; i < dt.NumField(); i++ {
+		f := dt.Field(i)
+		embedded := f.Anonymous && f.Type.Kind() == reflect.Struct
+		if f.PkgPath != "" {
+			// 未导出的嵌入结构体只展开其导出字段
+			if embedded {
+				if err := o.copyStruct(dst.Field(i), src, path); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		tag := f.Tag.Get("mapper")
+		if tag == "-" {
+			continue
+		}
+		p := f.Name
+		if path != "" {
+			p = path + "." + f.Name
+		}
+		if o.ignore[p] {
+			continue
+		}
+		name := f.Name
+		if tag != "" {
+			name = tag
+		}
+		if m, ok := o.mapping[p]; ok {
+			name = m
+		}
+		sv, ok := fields[name]
+		if !ok {
+			sv, ok = lower[strings.ToLower(name)]
+		}
+		if !ok {
+			// src中没有同名的字段时，展开嵌入的结构体
+			if embedded {
+				if err := o.copyStruct(dst.Field(i), src, path); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if o.ignoreEmpty && sv.IsZero() {
+			continue
+		}
+		if err := o.copy(dst.Field(i), sv, p); err != nil {
+			return fmt.Errorf("mapper: %s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// collectFields 收集导出字段，嵌入结构体的字段会展开，外层的同名字段优先
+func collectFields(v reflect.Value, out map[string]reflect.Value) {
+	t := v.Type()
+	var embedded []reflect.Value
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+				embedded = append(embedded, fv)
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		out[f.Name] = v.Field(i)
+	}
+	for _, fv := range embedded {
+		inner := make(map[string]reflect.Value)
+		collectFields(fv, inner)
+		for name, iv := range inner {
+			if _, ok := out[name]; !ok {
+				out[name] = iv
+			}
+		}
+	}
+}
+
+func hasUnexported(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// convert 基础类型之间的转换
+func convert(dst, src reflect.Value) error {
+	st, dt := src.Type(), dst.Type()
+	switch {
+	case st.AssignableTo(dt):
+		dst.Set(src)
+		return nil
+	case st == timeType:
+		return fromTime(dst, src.Interface().(time.Time))
+	case dt == timeType:
+		return toTime(dst, src)
+	case src.Kind() == reflect.String && dst.Kind() != reflect.String:
+		return fromString(dst, src.String())
+	case dst.Kind() == reflect.String && src.Kind() != reflect.String:
+		return toString(dst, src)
+	case st.ConvertibleTo(dt):
+		dst.Set(src.Convert(dt))
+		return nil
+	}
+	return fmt.Errorf("cannot convert %s to %s", st, dt)
+}
+
+func fromTime(dst reflect.Value, t time.Time) error {
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		if t.IsZero() {
+			dst.SetInt(0)
+		} else {
+			dst.SetInt(t.Unix())
+		}
+	case reflect.String:
+		if t.IsZero() {
+			dst.SetString("")
+		} else {
+			dst.SetString(t.Format(TimeLayout))
+		}
+	default:
+		return fmt.Errorf("cannot convert time.Time to %s", dst.Type())
+	}
+	return nil
+}
+
+func toTime(dst, src reflect.Value) error {
+	switch src.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		if src.Int() == 0 {
+			dst.Set(reflect.ValueOf(time.Time{}))
+		} else {
+			dst.Set(reflect.ValueOf(time.Unix(src.Int(), 0)))
+		}
+	case reflect.String:
+		s := strings.TrimSpace(src.String())
+		if s == "" {
+			dst.Set(reflect.ValueOf(time.Time{}))
+			return nil
+		}
+		t, err := time.ParseInLocation(TimeLayout, s, time.Local)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, s); err != nil {
+				return err
+			}
+		}
+		dst.Set(reflect.ValueOf(t))
+	default:
+		return fmt.Errorf("cannot convert %s to time.Time", src.Type())
+	}
+	return nil
+}
+
+func fromString(dst reflect.Value, s string) error {
+	s = strings.TrimSpace(s)
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s == "" {
+			dst.SetInt(0)
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s == "" {
+			dst.SetUint(0)
+			return nil
+		}
+		n, err := strconv.ParseUint(s, 10, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if s == "" {
+			dst.SetFloat(0)
+			return nil
+		}
+		n, err := strconv.ParseFloat(s, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(n)
+	case reflect.Bool:
+		if s == "" {
+			dst.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("cannot convert string to %s", dst.Type())
+		}
+		dst.SetBytes([]byte(s))
+	default:
+		return fmt.Errorf("cannot convert string to %s", dst.Type())
+	}
+	return nil
+}
+
+func toString(dst, src reflect.Value) error {
+	switch src.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		dst.SetString(strconv.FormatInt(src.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		dst.SetString(strconv.FormatUint(src.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		dst.SetString(strconv.FormatFloat(src.Float(), 'f', -1, src.Type().Bits()))
+	case reflect.Bool:
+		dst.SetString(strconv.FormatBool(src.Bool()))
+	case reflect.Slice:
+		if src.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("cannot convert %s to string", src.Type())
+		}
+		dst.SetString(string(src.Bytes()))
+	default:
+		return fmt.Errorf("cannot convert %s to string", src.Type())
+	}
+	return nil
+}
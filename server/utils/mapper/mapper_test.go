@@ -0,0 +1,112 @@
+package mapper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type base struct {
+	ID        uint
+	CreatedAt time.Time
+}
+
+type user struct {
+	base
+	Username string
+	Password string
+	Age      string
+	Tags     []string
+	Profile  *profile
+	Extra    map[string]int
+}
+
+type profile struct {
+	Nickname string
+}
+
+type userResponse struct {
+	Id        int64
+	Name      string `mapper:"Username"`
+	Password  string `mapper:"-"`
+	Age       int
+	CreatedAt int64
+	Tags      []string
+	Profile   profile
+	Extra     map[string]string
+}
+
+func TestCopy(t *testing.T) {
+	created := time.Unix(1634256000, 0)
+	u := &user{
+		base:     base{ID: 7, CreatedAt: created},
+		Username: "alice",
+		Password: "secret",
+		Age:      "18",
+		Tags:     []string{"go"},
+		Profile:  &profile{Nickname: "Alice"},
+		Extra:    map[string]int{"score": 3},
+	}
+	var ret userResponse
+	assert.Nil(t, Copy(&ret, u))
+	assert.Equal(t, userResponse{
+		Id:        7,
+		Name:      "alice",
+		Age:       18,
+		CreatedAt: 1634256000,
+		Tags:      []string{"go"},
+		Profile:   profile{Nickname: "Alice"},
+		Extra:     map[string]string{"score": "3"},
+	}, ret)
+
+	// 深复制，不共享切片
+	ret.Tags[0] = "rust"
+	assert.Equal(t, "go", u.Tags[0])
+
+	// 反向转换
+	var back user
+	assert.Nil(t, Copy(&back, &ret, WithMapping(map[string]string{"Username": "Name"}), Ignore("Extra")))
+	assert.Equal(t, uint(7), back.ID)
+	assert.Equal(t, "alice", back.Username)
+	assert.Equal(t, "18", back.Age)
+	assert.Equal(t, created, back.CreatedAt)
+	assert.Equal(t, "Alice", back.Profile.Nickname)
+	assert.Nil(t, back.Extra)
+}
+
+func TestCopySlice(t *testing.T) {
+	var list []userResponse
+	assert.Nil(t, Copy(&list, []user{{Username: "a"}, {Username: "b"}}))
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, "b", list[1].Name)
+}
+
+func TestIgnoreEmpty(t *testing.T) {
+	dst := profile{Nickname: "old"}
+	assert.Nil(t, Copy(&dst, profile{}, IgnoreEmpty()))
+	assert.Equal(t, "old", dst.Nickname)
+}
+
+func TestCopyTimeString(t *testing.T) {
+	var dst struct{ CreatedAt time.Time }
+	assert.Nil(t, Copy(&dst, struct{ CreatedAt string }{"2021-10-15 08:00:00"}))
+	assert.Equal(t, time.Date(2021, 10, 15, 8, 0, 0, 0, time.Local), dst.CreatedAt)
+}
+
+func TestCopyError(t *testing.T) {
+	var dst struct{ Age int }
+	assert.NotNil(t, Copy(&dst, struct{ Age string }{"abc"}))
+	assert.Equal(t, ErrInvalidDst, Copy(dst, struct{}{}))
+}
+
+type cents int64
+
+func TestRegisterConverter(t *testing.T) {
+	RegisterConverter(func(c cents) (string, error) {
+		return time.Duration(c).String(), nil
+	})
+	var dst struct{ Price string }
+	assert.Nil(t, Copy(&dst, struct{ Price cents }{cents(time.Second)}))
+	assert.Equal(t, "1s", dst.Price)
+}
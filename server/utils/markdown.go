@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	chromahtml "github.com/alecthomas/chroma/formatters/html"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+var (
+	// markdown 支持GFM(表格、删除线、任务列表、自动链接)，代码块使用chroma的class高亮，样式见HighlightCSS
+	markdown = goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			highlighting.NewHighlighting(
+				highlighting.WithStyle(highlightStyle),
+				highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+			),
+		),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	)
+
+	// ugcPolicy 用户内容白名单，保留代码高亮的class及任务列表的checkbox
+	ugcPolicy = newUGCPolicy()
+	// stripPolicy 去掉所有标签
+	stripPolicy = bluemonday.StrictPolicy()
+
+	spaceRegexp = regexp.MustCompile(`\s+`)
+)
+
+// highlightStyle 代码高亮的chroma样式
+const highlightStyle = "github"
+
+func newUGCPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^[\w\- ]+$`)).OnElements("span", "pre", "code", "div")
+	p.AllowAttrs("id").Matching(bluemonday.SpaceSeparatedTokens).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// MarkdownToHTML 将markdown渲染为html并过滤，可以直接输出到页面
+func MarkdownToHTML(src string) (string, error) {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(src), &buf); err != nil {
+		return "", err
+	}
+	return string(ugcPolicy.SanitizeBytes(buf.Bytes())), nil
+}
+
+// SanitizeHTML 按白名单过滤html，去掉script、事件属性、javascript:链接等
+func SanitizeHTML(s string) string {
+	return ugcPolicy.Sanitize(s)
+}
+
+// HTMLToText 去掉html标签并合并空白，用于生成摘要及搜索索引
+func HTMLToText(s string) string {
+	text := html.UnescapeString(stripPolicy.Sanitize(s))
+	return strings.TrimSpace(spaceRegexp.ReplaceAllString(text, " "))
+}
+
+// MarkdownToText 将markdown转换为纯文本，渲染失败时按html处理原文
+func MarkdownToText(src string) string {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(src), &buf); err != nil {
+		return HTMLToText(src)
+	}
+	return HTMLToText(buf.String())
+}
+
+// Summary 截取前n个字符作为摘要，超出时尽量在空白处截断并加上...
+func Summary(text string, n int) string {
+	text = strings.TrimSpace(text)
+	if n <= 0 || utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	runes := []rune(text)
+	cut := n
+	// 英文单词不截断，向前找空白，找不到时直接截断
+	if isWordRune(runes[n-1]) && isWordRune(runes[n]) {
+		for i := n - 1; i > n/2; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + "..."
+}
+
+func isWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// HighlightCSS 代码高亮的样式表，MarkdownToHTML输出的是class，需要在页面中引入
+func HighlightCSS() (string, error) {
+	var buf bytes.Buffer
+	formatter := chromahtml.New(chromahtml.WithClasses(true))
+	if err := formatter.WriteCSS(&buf, styles.Get(highlightStyle)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	out, err := MarkdownToHTML("# Title\n\n**bold** ~~del~~\n\n<script>alert(1)</script>\n\n[x](javascript:alert(1))\n\n```go\nfunc main() {}\n```\n")
+	assert.Nil(t, err)
+	assert.Contains(t, out, `<h1 id="title">Title</h1>`)
+	assert.Contains(t, out, "<strong>bold</strong>")
+	assert.Contains(t, out, "<del>del</del>")
+	assert.NotContains(t, out, "<script>")
+	assert.NotContains(t, out, "javascript:")
+	assert.Contains(t, out, `<span class="kd">func</span>`)
+}
+
+func TestSanitizeHTML(t *testing.T) {
+	out := SanitizeHTML(`<p onclick="x()">hi <a href="https://example.com">link</a><img src=x onerror=alert(1)></p>`)
+	assert.NotContains(t, out, "onclick")
+	assert.NotContains(t, out, "onerror")
+	assert.Contains(t, out, `rel="nofollow noopener"`)
+}
+
+func TestHTMLToText(t *testing.T) {
+	assert.Equal(t, "a & b c", HTMLToText("<p>a &amp; <b>b</b></p>\n\n<p> c </p>"))
+	assert.Equal(t, "Title list item", MarkdownToText("# Title\n\n- list *item*\n"))
+}
+
+func TestSummary(t *testing.T) {
+	assert.Equal(t, "短文本", Summary("短文本", 10))
+	assert.Equal(t, "这是一段很...", Summary("这是一段很长的文本", 5))
+	assert.Equal(t, "hello...", Summary("hello world", 8))
+	assert.Equal(t, "helloworld...", Summary("helloworldfoo", 10))
+	assert.Equal(t, "abc", Summary("abc", 0))
+}
+
+func TestHighlightCSS(t *testing.T) {
+	css, err := HighlightCSS()
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(css, ".chroma"))
+}
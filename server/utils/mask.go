@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MaskChar 脱敏使用的字符
+const MaskChar = '*'
+
+// MaskString 保留前keepStart个和后keepEnd个字符，中间替换为*，长度不足时全部替换
+func MaskString(s string, keepStart, keepEnd int) string {
+	runes := []rune(s)
+	if keepStart < 0 {
+		keepStart = 0
+	}
+	if keepEnd < 0 {
+		keepEnd = 0
+	}
+	if keepStart+keepEnd >= len(runes) {
+		return strings.Repeat(string(MaskChar), len(runes))
+	}
+	for i := keepStart; i < len(runes)-keepEnd; i++ {
+		runes[i] = MaskChar
+	}
+	return string(runes)
+}
+
+// MaskPhone 手机号脱敏，eg: 13812341234 => 138****1234，带+86等前缀时只处理后11位
+func MaskPhone(phone string) string {
+	n := utf8.RuneCountInString(phone)
+	if n < 7 {
+		return MaskString(phone, 0, 0)
+	}
+	if n > 11 {
+		return phone[:len(phone)-11] + MaskPhone(phone[len(phone)-11:])
+	}
+	return MaskString(phone, 3, 4)
+}
+
+// MaskEmail 邮箱脱敏，保留用户名的前几个字符和域名，eg: zhangsan@qq.com => zha****@qq.com
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return MaskString(email, 1, 0)
+	}
+	name, domain := []rune(email[:at]), email[at:]
+	keep := len(name) / 2
+	if keep > 3 {
+		keep = 3
+	}
+	return string(name[:keep]) + "****" + domain
+}
+
+// MaskIDCard 身份证号脱敏，保留前6位地区码和后4位，eg: 110101199003071234 => 110101********1234
+func MaskIDCard(id string) string {
+	if utf8.RuneCountInString(id) < 15 {
+		return MaskString(id, 1, 1)
+	}
+	return MaskString(id, 6, 4)
+}
+
+// MaskBankCard 银行卡号脱敏，只保留后4位，eg: 6222021234567890123 => ***************0123
+func MaskBankCard(card string) string {
+	return MaskString(card, 0, 4)
+}
+
+// MaskName 姓名脱敏，只保留第一个字，eg: 张三丰 => 张**
+func MaskName(name string) string {
+	if utf8.RuneCountInString(name) <= 1 {
+		return name
+	}
+	return MaskString(name, 1, 0)
+}
+
+// 脱敏规则，用于结构体标签，eg: `mask:"phone"`
+const (
+	MaskRulePhone    = "phone"
+	MaskRuleEmail    = "email"
+	MaskRuleIDCard   = "idcard"
+	MaskRuleBankCard = "bankcard"
+	MaskRuleName     = "name"
+	MaskRuleAll      = "all"
+)
+
+// MaskBy 按规则脱敏，空字符串不处理，未知的规则只保留首尾各一个字符
+func MaskBy(rule, s string) string {
+	if s == "" {
+		return s
+	}
+	switch rule {
+	case MaskRulePhone:
+		return MaskPhone(s)
+	case MaskRuleEmail:
+		return MaskEmail(s)
+	case MaskRuleIDCard:
+		return MaskIDCard(s)
+	case MaskRuleBankCard:
+		return MaskBankCard(s)
+	case MaskRuleName:
+		return MaskName(s)
+	case MaskRuleAll:
+		return MaskString(s, 0, 0)
+	}
+	return MaskString(s, 1, 1)
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskString(t *testing.T) {
+	assert.Equal(t, "ab**ef", MaskString("abcdef", 2, 2))
+	assert.Equal(t, "***", MaskString("abc", 2, 2))
+	assert.Equal(t, "张*丰", MaskString("张三丰", 1, 1))
+}
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "138****1234", MaskPhone("13812341234"))
+	assert.Equal(t, "+86138****1234", MaskPhone("+8613812341234"))
+	assert.Equal(t, "zha****@qq.com", MaskEmail("zhangsan@qq.com"))
+	assert.Equal(t, "a****@qq.com", MaskEmail("ab@qq.com"))
+	assert.Equal(t, "110101********1234", MaskIDCard("110101199003071234"))
+	assert.Equal(t, "***************0123", MaskBankCard("6222021234567890123"))
+	assert.Equal(t, "张**", MaskName("张三丰"))
+}
+
+func TestMaskBy(t *testing.T) {
+	assert.Equal(t, "138****1234", MaskBy(MaskRulePhone, "13812341234"))
+	assert.Equal(t, "zha****@qq.com", MaskBy(MaskRuleEmail, "zhangsan@qq.com"))
+	assert.Equal(t, "****", MaskBy(MaskRuleAll, "abcd"))
+	assert.Equal(t, "a**d", MaskBy("unknown", "abcd"))
+	assert.Equal(t, "", MaskBy(MaskRulePhone, ""))
+}
@@ -0,0 +1,242 @@
+package utils
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidMoney 金额格式错误
+var ErrInvalidMoney = errors.New("invalid money")
+
+// Money 金额，以分为单位保存，计算时不会有浮点数误差。
+// json中为元，eg: 12.34，数据库中为decimal(18,2)
+type Money int64
+
+// ParseMoney 解析以元为单位的金额，最多两位小数，eg: 12.34, -0.5, 100
+func ParseMoney(s string) (Money, error) {
+	orig := s
+	s = strings.TrimSpace(s)
+
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	yuan, fen := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		yuan, fen = s[:i], s[i+1:]
+	}
+	// decimal(18,4)等多余的0去掉
+	if len(fen) > 2 && strings.Trim(fen[2:], "0") == "" {
+		fen = fen[:2]
+	}
+	if yuan == "" || len(fen) > 2 || !isDigits(yuan) || !isDigits(fen) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidMoney, orig)
+	}
+	fen += strings.Repeat("0", 2-len(fen))
+
+	y, err := strconv.ParseInt(yuan, 10, 64)
+	if err != nil || y > math.MaxInt64/100 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidMoney, orig)
+	}
+	f, _ := strconv.ParseInt(fen, 10, 64)
+
+	m := Money(y*100 + f)
+	if neg {
+		m = -m
+	}
+	return m, nil
+}
+
+// IsMoney 是否是合法的金额，最多两位小数
+func IsMoney(s string) bool {
+	_, err := ParseMoney(s)
+	return err == nil
+}
+
+// MoneyFromYuan 将以元为单位的浮点数转换为金额，四舍五入到分
+func MoneyFromYuan(yuan float64) Money {
+	return Money(math.Round(yuan * 100))
+}
+
+// Cents 以分为单位的金额
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+// Yuan 以元为单位的金额，只用于展示，计算使用Money的方法
+func (m Money) Yuan() float64 {
+	return float64(m) / 100
+}
+
+// String 以元为单位，保留两位小数，eg: 12.30
+func (m Money) String() string {
+	n := int64(m)
+	if n < 0 {
+		// math.MinInt64取反会溢出，转换为uint64处理
+		return "-" + formatCents(uint64(-(n+1))+1)
+	}
+	return formatCents(uint64(n))
+}
+
+func formatCents(n uint64) string {
+	return fmt.Sprintf("%d.%02d", n/100, n%100)
+}
+
+// Add 加
+func (m Money) Add(o Money) Money {
+	return m + o
+}
+
+// Sub 减
+func (m Money) Sub(o Money) Money {
+	return m - o
+}
+
+// Mul 乘以整数，eg: 单价*数量
+func (m Money) Mul(n int64) Money {
+	return m * Money(n)
+}
+
+// MulRatio 乘以num/den，四舍五入到分，eg: 打八五折 m.MulRatio(85, 100)
+func (m Money) MulRatio(num, den int64) Money {
+	if den == 0 {
+		panic("utils: Money.MulRatio division by zero")
+	}
+	p := int64(m) * num
+	q, r := p/den, p%den
+	// 余数超过一半时进位，正负数都按远离0的方向
+	if r != 0 && 2*abs64(r) >= abs64(den) {
+		if (p < 0) != (den < 0) {
+			q--
+		} else {
+			q++
+		}
+	}
+	return Money(q)
+}
+
+// Split 平均分成n份，除不尽的分依次加到前面的份额上，各份之和等于m，eg: 10.00分3份为3.34, 3.33, 3.33
+func (m Money) Split(n int) []Money {
+	if n <= 0 {
+		return nil
+	}
+	parts := make([]Money, n)
+	each, rest := int64(m)/int64(n), int64(m)%int64(n)
+	for i := range parts {
+		parts[i] = Money(each)
+		if rest > 0 {
+			parts[i]++
+			rest--
+		} else if rest < 0 {
+			parts[i]--
+			rest++
+		}
+	}
+	return parts
+}
+
+// Neg 相反数
+func (m Money) Neg() Money {
+	return -m
+}
+
+// Abs 绝对值
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// IsZero 是否为0
+func (m Money) IsZero() bool {
+	return m == 0
+}
+
+// Cmp 比较大小，m<o返回-1，m==o返回0，m>o返回1
+func (m Money) Cmp(o Money) int {
+	switch {
+	case m < o:
+		return -1
+	case m > o:
+		return 1
+	}
+	return 0
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON 支持数字和字符串，eg: 12.34, "12.34"，null为0
+func (m *Money) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		*m = 0
+		return nil
+	}
+	v, err := ParseMoney(string(bytes.Trim(b, `"`)))
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Scan implements the sql.Scanner interface
+func (m *Money) Scan(value interface{}) error {
+	var (
+		v   Money
+		err error
+	)
+	switch val := value.(type) {
+	case nil:
+	case []byte:
+		v, err = ParseMoney(string(val))
+	case string:
+		v, err = ParseMoney(val)
+	case int64:
+		v = Money(val * 100)
+	case float64:
+		v = MoneyFromYuan(val)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidMoney, value)
+	}
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// GormDataType gorm common data type
+func (Money) GormDataType() string {
+	return "decimal(18,2)"
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	cases := map[string]Money{
+		"12.34":  1234,
+		"12.3":   1230,
+		"12":     1200,
+		"-0.5":   -50,
+		"+1.01":  101,
+		"0.1000": 10,
+	}
+	for s, want := range cases {
+		m, err := ParseMoney(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, want, m, s)
+	}
+
+	for _, s := range []string{"", ".5", "1.234", "1,000", "abc", "1e3"} {
+		_, err := ParseMoney(s)
+		assert.True(t, errors.Is(err, ErrInvalidMoney), s)
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	assert.Equal(t, "12.30", Money(1230).String())
+	assert.Equal(t, "-0.05", Money(-5).String())
+	assert.Equal(t, "0.00", Money(0).String())
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	// 0.1+0.2
+	assert.Equal(t, "0.30", MoneyFromYuan(0.1).Add(MoneyFromYuan(0.2)).String())
+	assert.Equal(t, Money(2997), Money(999).Mul(3))
+
+	assert.Equal(t, Money(850), Money(1000).MulRatio(85, 100))
+	assert.Equal(t, Money(333), Money(1000).MulRatio(1, 3))
+	assert.Equal(t, Money(667), Money(1000).MulRatio(2, 3))
+	assert.Equal(t, Money(-667), Money(-1000).MulRatio(2, 3))
+
+	assert.Equal(t, []Money{334, 333, 333}, Money(1000).Split(3))
+	assert.Equal(t, []Money{-334, -333, -333}, Money(-1000).Split(3))
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var v struct {
+		Price Money `json:"price"`
+		Total Money `json:"total"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{"price":12.34,"total":"100"}`), &v))
+	assert.Equal(t, Money(1234), v.Price)
+	assert.Equal(t, Money(10000), v.Total)
+
+	b, err := json.Marshal(v)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"price":12.34,"total":100.00}`, string(b))
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"price":1.234}`), &v))
+}
+
+func TestMoneyScan(t *testing.T) {
+	var m Money
+	assert.Nil(t, m.Scan([]byte("12.34")))
+	assert.Equal(t, Money(1234), m)
+
+	v, err := m.Value()
+	assert.Nil(t, err)
+	assert.Equal(t, "12.34", v)
+}
+
+func TestMoneyRule(t *testing.T) {
+	v := NewValidator()
+	fields, err := v.ValidateFields(struct {
+		Amount string `json:"amount" valid:"money"`
+	}{"1.234"})
+	assert.Nil(t, err)
+	assert.Equal(t, "amount必须是有效的金额，最多两位小数", fields["amount"])
+}
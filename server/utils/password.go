@@ -1,20 +1,154 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// 密码哈希算法，哈希值带有算法和参数前缀，修改算法或参数后旧密码仍可校验
+//	bcrypt:   $2a$10$...
+//	argon2id: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+const (
+	PasswordBcrypt   = "bcrypt"
+	PasswordArgon2id = "argon2id"
+)
+
+// PasswordOptions 密码哈希参数
+type PasswordOptions struct {
+	Algorithm  string
+	BcryptCost int
+	// argon2id的迭代次数、内存(KiB)和并行度
+	Argon2Time    uint32
+	Argon2Memory  uint32
+	Argon2Threads uint8
+}
+
+var passwordOptions = DefaultPasswordOptions()
+
+// DefaultPasswordOptions 默认使用bcrypt
+func DefaultPasswordOptions() PasswordOptions {
+	return PasswordOptions{
+		Algorithm:     PasswordBcrypt,
+		BcryptCost:    bcrypt.DefaultCost,
+		Argon2Time:    3,
+		Argon2Memory:  64 * 1024,
+		Argon2Threads: 2,
+	}
+}
+
+// SetPasswordOptions 设置新密码使用的算法和参数，未设置的参数使用默认值，应在启动时调用
+func SetPasswordOptions(o PasswordOptions) {
+	def := DefaultPasswordOptions()
+	if o.Algorithm == "" {
+		o.Algorithm = def.Algorithm
+	}
+	if o.BcryptCost == 0 {
+		o.BcryptCost = def.BcryptCost
+	}
+	if o.Argon2Time == 0 {
+		o.Argon2Time = def.Argon2Time
+	}
+	if o.Argon2Memory == 0 {
+		o.Argon2Memory = def.Argon2Memory
+	}
+	if o.Argon2Threads == 0 {
+		o.Argon2Threads = def.Argon2Threads
+	}
+	passwordOptions = o
+}
+
+const (
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+var errInvalidPasswordHash = errors.New("invalid password hash")
+
+// HashPassword 使用当前配置的算法计算密码哈希
+func HashPassword(rawPassword string) (string, error) {
+	o := passwordOptions
+	switch o.Algorithm {
+	case PasswordArgon2id:
+		salt := make([]byte, argon2SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(rawPassword), salt, o.Argon2Time, o.Argon2Memory, o.Argon2Threads, argon2KeyLen)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, o.Argon2Memory, o.Argon2Time, o.Argon2Threads,
+			Base64RawEncode(salt), Base64RawEncode(key)), nil
+	case PasswordBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(rawPassword), o.BcryptCost)
+		return string(hash), err
+	default:
+		return "", fmt.Errorf("unknown password algorithm %q", o.Algorithm)
+	}
+}
+
+// CheckPassword 校验密码，根据哈希值的前缀选择算法
+func CheckPassword(hash, rawPassword string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		p, salt, key, err := parseArgon2(hash)
+		if err != nil {
+			return false
+		}
+		got := argon2.IDKey([]byte(rawPassword), salt, p.Argon2Time, p.Argon2Memory, p.Argon2Threads, uint32(len(key)))
+		return subtle.ConstantTimeCompare(got, key) == 1
+	}
+	// bcrypt内部使用常量时间比较
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(rawPassword)) == nil
+}
+
+// PasswordNeedsRehash 哈希值的算法或参数与当前配置不一致，登录成功后可以用明文密码重新计算哈希
+func PasswordNeedsRehash(hash string) bool {
+	o := passwordOptions
+	if strings.HasPrefix(hash, "$argon2id$") {
+		p, _, _, err := parseArgon2(hash)
+		return err != nil || o.Algorithm != PasswordArgon2id ||
+			p.Argon2Time != o.Argon2Time || p.Argon2Memory != o.Argon2Memory || p.Argon2Threads != o.Argon2Threads
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || o.Algorithm != PasswordBcrypt || cost != o.BcryptCost
+}
+
+func parseArgon2(hash string) (p PasswordOptions, salt, key []byte, err error) {
+	// ["", "argon2id", "v=19", "m=65536,t=3,p=2", salt, hash]
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, errInvalidPasswordHash
+	}
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errInvalidPasswordHash
+	}
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Argon2Memory, &p.Argon2Time, &p.Argon2Threads); err != nil {
+		return p, nil, nil, errInvalidPasswordHash
+	}
+	if salt, err = Base64RawDecode(parts[4]); err != nil {
+		return p, nil, nil, errInvalidPasswordHash
+	}
+	if key, err = Base64RawDecode(parts[5]); err != nil || len(key) == 0 {
+		return p, nil, nil, errInvalidPasswordHash
+	}
+	p.Algorithm = PasswordArgon2id
+	return p, salt, key, nil
+}
+
+// EncodePassword 计算密码哈希，出错时返回空字符串
 func EncodePassword(rawPassword string) string {
-	hash, err := bcrypt.GenerateFromPassword([]byte(rawPassword), bcrypt.DefaultCost)
+	hash, err := HashPassword(rawPassword)
 	if err != nil {
 		fmt.Println(err)
 	}
-	return string(hash)
+	return hash
 }
 
+// ValidatePassword 同CheckPassword
 func ValidatePassword(encodePassword, inputPassword string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(encodePassword), []byte(inputPassword))
-	return err == nil
+	return CheckPassword(encodePassword, inputPassword)
 }
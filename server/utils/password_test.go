@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashPassword(t *testing.T) {
+	defer SetPasswordOptions(DefaultPasswordOptions())
+
+	SetPasswordOptions(PasswordOptions{Algorithm: PasswordBcrypt, BcryptCost: 4})
+	bcryptHash, err := HashPassword("123456")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(bcryptHash, "$2a$04$"))
+	assert.True(t, CheckPassword(bcryptHash, "123456"))
+	assert.False(t, CheckPassword(bcryptHash, "1234567"))
+	assert.False(t, PasswordNeedsRehash(bcryptHash))
+
+	SetPasswordOptions(PasswordOptions{Algorithm: PasswordArgon2id, Argon2Time: 1, Argon2Memory: 1024, Argon2Threads: 1})
+	argonHash, err := HashPassword("123456")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(argonHash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	assert.True(t, CheckPassword(argonHash, "123456"))
+	assert.False(t, CheckPassword(argonHash, "1234567"))
+	assert.False(t, PasswordNeedsRehash(argonHash))
+
+	// 切换算法后旧的哈希仍可校验，但需要重新计算
+	assert.True(t, CheckPassword(bcryptHash, "123456"))
+	assert.True(t, PasswordNeedsRehash(bcryptHash))
+
+	SetPasswordOptions(PasswordOptions{Algorithm: PasswordArgon2id, Argon2Time: 2, Argon2Memory: 1024, Argon2Threads: 1})
+	assert.True(t, PasswordNeedsRehash(argonHash))
+
+	assert.False(t, CheckPassword("$argon2id$v=19$m=1024,t=1,p=1$bad", "123456"))
+	assert.False(t, CheckPassword("", "123456"))
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// Pinyin 将汉字转换为不带声调的拼音，以空格分隔，非汉字原样保留，多音字取常用读音，eg: "Gin入门" => "Gin ru men"
+func Pinyin(s string) string {
+	return convertPinyin(s, pinyin.Normal, " ")
+}
+
+// PinyinInitials 汉字拼音的首字母，非汉字原样保留，可用于按首字母搜索，eg: "中国abc" => "zgabc"
+func PinyinInitials(s string) string {
+	return convertPinyin(s, pinyin.FirstLetter, "")
+}
+
+// PinyinSlug 将汉字转换为拼音后生成slug，eg: "Gin 入门教程" => "gin-ru-men-jiao-cheng"
+func PinyinSlug(s string) string {
+	return Slugify(Pinyin(s))
+}
+
+func convertPinyin(s string, style int, sep string) string {
+	args := pinyin.NewArgs()
+	args.Style = style
+
+	var b strings.Builder
+	// 上一个字符是否是汉字，汉字与其他字符之间也加上分隔符
+	lastHan := false
+	for _, r := range s {
+		if !unicode.Is(unicode.Han, r) {
+			if lastHan && sep != "" && !unicode.IsSpace(r) {
+				b.WriteString(sep)
+			}
+			b.WriteRune(r)
+			lastHan = false
+			continue
+		}
+		py := pinyin.SinglePinyin(r, args)
+		if len(py) == 0 {
+			b.WriteRune(r)
+			lastHan = false
+			continue
+		}
+		if b.Len() > 0 && sep != "" && !strings.HasSuffix(b.String(), sep) {
+			b.WriteString(sep)
+		}
+		b.WriteString(py[0])
+		lastHan = true
+	}
+	return b.String()
+}
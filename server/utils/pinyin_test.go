@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinyin(t *testing.T) {
+	assert.Equal(t, "zhong guo", Pinyin("中国"))
+	assert.Equal(t, "Gin ru men", Pinyin("Gin入门"))
+	assert.Equal(t, "ru men Gin", Pinyin("入门Gin"))
+	assert.Equal(t, "zgabc", PinyinInitials("中国abc"))
+	assert.Equal(t, "gin-ru-men-jiao-cheng", PinyinSlug("Gin 入门教程"))
+	assert.Equal(t, "ni-hao", PinyinSlug("你好！"))
+}
@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"github.com/disintegration/imaging"
+)
+
+// QRLevel 二维码的纠错等级，等级越高可以被遮挡的面积越大，但同样内容生成的码越密
+type QRLevel int
+
+const (
+	// QRLevelDefault 没有logo时为M，有logo时为H
+	QRLevelDefault QRLevel = iota
+	// QRLevelL 约7%
+	QRLevelL
+	// QRLevelM 约15%
+	QRLevelM
+	// QRLevelQ 约25%
+	QRLevelQ
+	// QRLevelH 约30%
+	QRLevelH
+)
+
+// 二维码四周的空白，单位为模块
+const qrQuietZone = 4
+
+var (
+	// ErrQRSizeTooSmall 尺寸小于二维码的模块数，无法绘制
+	ErrQRSizeTooSmall = errors.New("qrcode: size too small for content")
+	// ErrBarcodeContent 条形码内容不合法，code128只支持ASCII字符
+	ErrBarcodeContent = errors.New("barcode: invalid content")
+)
+
+// QROptions 二维码参数
+type QROptions struct {
+	// 边长，像素，默认256
+	Size int
+	// 纠错等级
+	Level QRLevel
+	// 中间的logo，为nil时不添加
+	Logo image.Image
+	// logo边长占二维码边长的比例，默认0.2，不超过0.3，否则可能无法识别
+	LogoRatio float64
+}
+
+// QRCode 生成二维码，四周留有空白
+func QRCode(content string, opts QROptions) (image.Image, error) {
+	if opts.Size <= 0 {
+		opts.Size = 256
+	}
+	code, err := qr.Encode(content, qrLevel(opts), qr.Auto)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := code.Bounds().Dx()
+	inner := opts.Size * modules / (modules + 2*qrQuietZone)
+	if inner < modules {
+		return nil, ErrQRSizeTooSmall
+	}
+	// 按整数倍放大，模块大小一致，识别率更高
+	inner = inner / modules * modules
+	scaled, err := barcode.Scale(code, inner, inner)
+	if err != nil {
+		return nil, err
+	}
+
+	canvas := image.NewNRGBA(image.Rect(0, 0, opts.Size, opts.Size))
+	draw.Draw(canvas, canvas.Bounds(), image.White, image.Point{}, draw.Src)
+	offset := (opts.Size - inner) / 2
+	draw.Draw(canvas, image.Rect(offset, offset, offset+inner, offset+inner), scaled, image.Point{}, draw.Src)
+
+	if opts.Logo != nil {
+		drawLogo(canvas, opts.Logo, inner, opts.LogoRatio)
+	}
+	return canvas, nil
+}
+
+func qrLevel(opts QROptions) qr.ErrorCorrectionLevel {
+	switch opts.Level {
+	case QRLevelL:
+		return qr.L
+	case QRLevelM:
+		return qr.M
+	case QRLevelQ:
+		return qr.Q
+	case QRLevelH:
+		return qr.H
+	}
+	if opts.Logo != nil {
+		return qr.H
+	}
+	return qr.M
+}
+
+// drawLogo 在中间绘制带白色边框的logo
+func drawLogo(canvas *image.NRGBA, logo image.Image, inner int, ratio float64) {
+	if ratio <= 0 {
+		ratio = 0.2
+	}
+	if ratio > 0.3 {
+		ratio = 0.3
+	}
+	side := int(float64(inner) * ratio)
+	if side <= 0 {
+		return
+	}
+	logo = imaging.Fit(logo, side, side, imaging.Lanczos)
+	b := canvas.Bounds()
+	lb := logo.Bounds()
+	pt := image.Pt((b.Dx()-lb.Dx())/2, (b.Dy()-lb.Dy())/2)
+
+	border := side / 10
+	if border < 2 {
+		border = 2
+	}
+	bg := image.Rect(pt.X-border, pt.Y-border, pt.X+lb.Dx()+border, pt.Y+lb.Dy()+border)
+	draw.Draw(canvas, bg, &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(canvas, image.Rectangle{Min: pt, Max: pt.Add(lb.Size())}, logo, lb.Min, draw.Over)
+}
+
+// QRCodePNG 生成二维码的png
+func QRCodePNG(content string, opts QROptions) ([]byte, error) {
+	img, err := QRCode(content, opts)
+	if err != nil {
+		return nil, err
+	}
+	return EncodePNG(img)
+}
+
+// Code128 生成code128条形码，width、height为像素，width小于条码的最小宽度时返回错误
+func Code128(content string, width, height int) (image.Image, error) {
+	if content == "" {
+		return nil, ErrBarcodeContent
+	}
+	for _, r := range content {
+		if r > 127 {
+			return nil, ErrBarcodeContent
+		}
+	}
+	code, err := code128.Encode(content)
+	if err != nil {
+		return nil, err
+	}
+	if width <= 0 {
+		width = code.Bounds().Dx() * 2
+	}
+	if height <= 0 {
+		height = 80
+	}
+	return barcode.Scale(code, width, height)
+}
+
+// Code128PNG 生成code128条形码的png
+func Code128PNG(content string, width, height int) ([]byte, error) {
+	img, err := Code128(content, width, height)
+	if err != nil {
+		return nil, err
+	}
+	return EncodePNG(img)
+}
+
+// EncodePNG 将图片编码为png
+func EncodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PNGDataURL png的data url，可以直接用于img标签的src，eg: data:image/png;base64,iVBORw0...
+func PNGDataURL(b []byte) string {
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(b)
+}
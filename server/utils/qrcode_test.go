@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQRCode(t *testing.T) {
+	img, err := QRCode("https://example.com/a?b=中文", QROptions{Size: 300})
+	assert.Nil(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+	assert.Equal(t, 300, img.Bounds().Dy())
+	// 四周为空白
+	r, g, b, _ := img.At(1, 1).RGBA()
+	assert.Equal(t, uint32(0xffff), r&g&b)
+
+	_, err = QRCode("https://example.com", QROptions{Size: 10})
+	assert.Equal(t, ErrQRSizeTooSmall, err)
+}
+
+func TestQRCodeLogo(t *testing.T) {
+	logo := image.NewNRGBA(image.Rect(0, 0, 100, 100))
+	for x := 0; x < 100; x++ {
+		for y := 0; y < 100; y++ {
+			logo.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	img, err := QRCode("hello", QROptions{Size: 256, Logo: logo})
+	assert.Nil(t, err)
+	r, g, _, _ := img.At(128, 128).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+	assert.Equal(t, uint32(0), g)
+
+	b, err := QRCodePNG("hello", QROptions{Level: QRLevelL})
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(PNGDataURL(b), "data:image/png;base64,iVBORw0KGgo"))
+}
+
+func TestCode128(t *testing.T) {
+	img, err := Code128("ABC-12345", 300, 60)
+	assert.Nil(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+	assert.Equal(t, 60, img.Bounds().Dy())
+
+	_, err = Code128("中文", 300, 60)
+	assert.Equal(t, ErrBarcodeContent, err)
+	_, err = Code128("ABC", 10, 60)
+	assert.NotNil(t, err)
+
+	b, err := Code128PNG("123456", 0, 0)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, b)
+}
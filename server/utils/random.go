@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"math/big"
+)
+
+// 随机字符串使用的字符集
+const (
+	CharsetDigits       = "0123456789"
+	CharsetLower        = "abcdefghijklmnopqrstuvwxyz"
+	CharsetUpper        = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	CharsetAlpha        = CharsetLower + CharsetUpper
+	CharsetAlphanumeric = CharsetDigits + CharsetAlpha
+	CharsetHex          = "0123456789abcdef"
+	// CharsetReadable 去掉了容易混淆的0、O、1、I、l，适合邀请码、兑换码
+	CharsetReadable = "23456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnpqrstuvwxyz"
+)
+
+// RandomString 使用crypto/rand从charset中随机选取n个字符，charset为空时使用CharsetAlphanumeric
+func RandomString(n int, charset ...string) string {
+	chars := CharsetAlphanumeric
+	if len(charset) > 0 && charset[0] != "" {
+		chars = charset[0]
+	}
+	runes := []rune(chars)
+	max := big.NewInt(int64(len(runes)))
+	b := make([]rune, n)
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		b[i] = runes[idx.Int64()]
+	}
+	return string(b)
+}
+
+// RandomDigits n位随机数字，eg: 短信验证码
+func RandomDigits(n int) string {
+	return RandomString(n, CharsetDigits)
+}
+
+// RandomBytes n个随机字节
+func RandomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// RandomToken n个随机字节的url安全base64编码(不带=)，可用于重置密码、邮箱验证等一次性令牌
+func RandomToken(n int) string {
+	return base64.RawURLEncoding.EncodeToString(RandomBytes(n))
+}
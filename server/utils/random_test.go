@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomString(t *testing.T) {
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-zA-Z]{16}$`), RandomString(16))
+	assert.Regexp(t, regexp.MustCompile(`^[0-9]{6}$`), RandomDigits(6))
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}$`), RandomString(8, CharsetHex))
+	assert.Equal(t, "中中中", RandomString(3, "中"))
+	assert.NotEqual(t, RandomString(32), RandomString(32))
+}
+
+func TestRandomToken(t *testing.T) {
+	token := RandomToken(32)
+	assert.Len(t, token, 43)
+	assert.Regexp(t, regexp.MustCompile(`^[\w-]+$`), token)
+	assert.Len(t, RandomBytes(10), 10)
+}
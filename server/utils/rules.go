@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	mobileRegexp   = regexp.MustCompile(`^1[3-9]\d{9}$`)
+	telRegexp      = regexp.MustCompile(`^0\d{2,3}-?\d{7,8}(-\d{1,6})?$`)
+	idCardRegexp   = regexp.MustCompile(`^\d{17}[\dXx]$`)
+	bankCardRegexp = regexp.MustCompile(`^\d{12,19}$`)
+	plateRegexp    = regexp.MustCompile(`^[京津沪渝冀豫云辽黑湘皖鲁新苏浙赣鄂桂甘晋蒙陕吉闽贵粤青藏川宁琼使领][A-HJ-NP-Z](?:[A-HJ-NP-Z0-9]{4}[A-HJ-NP-Z0-9挂学警港澳]|[DF][A-HJ-NP-Z0-9]\d{4}|\d{5}[DF])$`)
+)
+
+// 身份证校验位的加权因子和校验码
+var (
+	idCardWeights = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	idCardChecks  = "10X98765432"
+)
+
+// 统一社会信用代码的字符集和加权因子
+var (
+	usccChars   = "0123456789ABCDEFGHJKLMNPQRTUWXY"
+	usccWeights = []int{1, 3, 9, 27, 19, 26, 16, 17, 20, 29, 25, 13, 8, 24, 10, 30, 28}
+)
+
+// rule 自定义验证规则
+type rule struct {
+	tag     string
+	fn      validator.Func
+	message string
+	// 英文的提示，为空时使用message
+	enMessage string
+}
+
+// builtinRules 内置的验证规则，NewValidator和InitTrans都会注册
+var builtinRules = []rule{
+	{"mobile", stringRule(IsMobile), "{0}必须是有效的手机号码", "{0} must be a valid mobile number"},
+	{"tel", stringRule(IsTel), "{0}必须是有效的固定电话号码", "{0} must be a valid telephone number"},
+	{"idcard", stringRule(IsIDCard), "{0}必须是有效的身份证号码", "{0} must be a valid ID card number"},
+	{"bankcard", stringRule(IsBankCard), "{0}必须是有效的银行卡号", "{0} must be a valid bank card number"},
+	{"uscc", stringRule(IsUSCC), "{0}必须是有效的统一社会信用代码", "{0} must be a valid unified social credit code"},
+	{"plate", stringRule(IsPlateNumber), "{0}必须是有效的车牌号码", "{0} must be a valid plate number"},
+	{"money", moneyRule, "{0}必须是有效的金额，最多两位小数", "{0} must be a valid amount with at most 2 decimal places"},
+}
+
+// stringRule 将字符串校验方法转换为validator.Func
+func stringRule(check func(s string) bool) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return check(fl.Field().String())
+	}
+}
+
+// moneyRule 字符串需要是合法的金额，Money类型解析时已校验
+func moneyRule(fl validator.FieldLevel) bool {
+	if fl.Field().Kind() == reflect.String {
+		return IsMoney(fl.Field().String())
+	}
+	return true
+}
+
+// registerRules 注册验证规则及其翻译
+func registerRules(validate *validator.Validate, trans ut.Translator, rules ...rule) error {
+	for _, r := range rules {
+		if err := validate.RegisterValidation(r.tag, r.fn); err != nil {
+			return err
+		}
+
+		message := r.message
+		if r.enMessage != "" && strings.HasPrefix(trans.Locale(), "en") {
+			message = r.enMessage
+		}
+		if err := validate.RegisterTranslation(r.tag, trans, registerTranslator(r.tag, message), translate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsMobile 是否是合法的手机号码
+func IsMobile(s string) bool {
+	return mobileRegexp.MatchString(s)
+}
+
+// IsTel 是否是合法的固定电话号码，支持区号、分机号，eg: 010-12345678, 0755-1234567-123
+func IsTel(s string) bool {
+	return telRegexp.MatchString(s)
+}
+
+// IsIDCard 是否是合法的18位身份证号码，会校验出生日期和校验位
+func IsIDCard(s string) bool {
+	if !idCardRegexp.MatchString(s) {
+		return false
+	}
+
+	if _, err := time.ParseInLocation("20060102", s[6:14], time.Local); err != nil {
+		return false
+	}
+
+	sum := 0
+
+	for i, w := range idCardWeights {
+		sum += int(s[i]-'0') * w
+	}
+
+	return idCardChecks[sum%11] == strings.ToUpper(s[17:])[0]
+}
+
+// IsBankCard 是否是合法的银行卡号，使用Luhn算法校验
+func IsBankCard(s string) bool {
+	if !bankCardRegexp.MatchString(s) {
+		return false
+	}
+
+	sum := 0
+	double := false
+
+	for i := len(s) - 1; i >= 0; i-- {
+		n := int(s[i] - '0')
+
+		if double {
+			n *= 2
+
+			if n > 9 {
+				n -= 9
+			}
+		}
+
+		sum += n
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// IsUSCC 是否是合法的统一社会信用代码(GB 32100-2015)
+func IsUSCC(s string) bool {
+	if len(s) != 18 {
+		return false
+	}
+
+	s = strings.ToUpper(s)
+	sum := 0
+
+	for i, w := range usccWeights {
+		n := strings.IndexByte(usccChars, s[i])
+
+		if n < 0 {
+			return false
+		}
+
+		sum += n * w
+	}
+
+	check := 31 - sum%31
+
+	if check == 31 {
+		check = 0
+	}
+
+	return usccChars[check] == s[17]
+}
+
+// IsPlateNumber 是否是合法的车牌号码，支持普通车牌和新能源车牌
+func IsPlateNumber(s string) bool {
+	return plateRegexp.MatchString(s)
+}
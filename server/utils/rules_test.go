@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsMobile(t *testing.T) {
+	assert.True(t, IsMobile("13812345678"))
+	assert.False(t, IsMobile("12812345678"))
+	assert.False(t, IsMobile("1381234567"))
+}
+
+func TestIsTel(t *testing.T) {
+	assert.True(t, IsTel("010-12345678"))
+	assert.True(t, IsTel("0755-1234567-123"))
+	assert.False(t, IsTel("12345678"))
+}
+
+func TestIsIDCard(t *testing.T) {
+	assert.True(t, IsIDCard("11010519491231002X"))
+	assert.True(t, IsIDCard("11010519491231002x"))
+	assert.False(t, IsIDCard("110105194912310021"))
+	assert.False(t, IsIDCard("110105194913310023"))
+}
+
+func TestIsBankCard(t *testing.T) {
+	assert.True(t, IsBankCard("4111111111111111"))
+	assert.False(t, IsBankCard("4111111111111112"))
+	assert.False(t, IsBankCard("41111111111a1111"))
+}
+
+func TestIsUSCC(t *testing.T) {
+	assert.True(t, IsUSCC("91350100M000100Y43"))
+	assert.False(t, IsUSCC("91350100M000100Y44"))
+	assert.False(t, IsUSCC("91350100M000100Y4"))
+}
+
+func TestIsPlateNumber(t *testing.T) {
+	assert.True(t, IsPlateNumber("京A12345"))
+	assert.True(t, IsPlateNumber("粤B1234学"))
+	assert.True(t, IsPlateNumber("沪AD12345"))
+	assert.True(t, IsPlateNumber("沪A12345F"))
+	assert.False(t, IsPlateNumber("京I12345"))
+	assert.False(t, IsPlateNumber("A12345"))
+}
+
+func TestRegisterRule(t *testing.T) {
+	type form struct {
+		Phone string `valid:"mobile"`
+		QQ    string `valid:"qq"`
+	}
+
+	v := NewValidator()
+
+	err := v.RegisterRule("qq", func(fl validator.FieldLevel) bool {
+		return len(fl.Field().String()) >= 5
+	}, "{0}必须是有效的QQ号")
+	assert.Nil(t, err)
+
+	assert.Nil(t, v.ValidateStruct(&form{Phone: "13812345678", QQ: "10001"}))
+
+	err = v.ValidateStruct(&form{Phone: "1381234", QQ: "100"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Phone必须是有效的手机号码")
+	assert.Contains(t, err.Error(), "QQ必须是有效的QQ号")
+}
+
+func TestValidateFields(t *testing.T) {
+	type form struct {
+		Phone string `json:"phone" valid:"mobile"`
+		Name  string `json:"name" valid:"required"`
+	}
+
+	v := NewValidator()
+
+	fields, err := v.ValidateFields(&form{Phone: "13812345678", Name: "go"})
+	assert.Nil(t, err)
+	assert.Nil(t, fields)
+
+	fields, err = v.ValidateFields(&form{Phone: "1381234"})
+	assert.Nil(t, err)
+	assert.Len(t, fields, 2)
+	assert.Contains(t, fields["phone"], "phone必须是有效的手机号码")
+	assert.Contains(t, fields, "name")
+}
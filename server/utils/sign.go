@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SignType 签名算法
+type SignType string
+
+const (
+	// SignMD5 md5(query&key=secret)，结果为大写，兼容微信支付等旧接口
+	SignMD5 SignType = "MD5"
+	// SignHMACSHA256 hmac_sha256(query, secret)，结果为小写hex
+	SignHMACSHA256 SignType = "HMAC-SHA256"
+)
+
+// 签名常用参数名
+const (
+	SignKey      = "sign"
+	SignTypeKey  = "sign_type"
+	NonceKey     = "nonce"
+	TimestampKey = "timestamp"
+)
+
+// QueryString 将struct或map按key排序后拼接为 k1=v1&k2=v2，值为空的参数及exclude中的参数不参与拼接，值不做url编码。
+// struct按form、json标签取参数名，标签为"-"的字段忽略；支持map[string]string、map[string]interface{}、url.Values
+func QueryString(v interface{}, exclude ...string) string {
+	return queryString(ToParams(v), false, exclude...)
+}
+
+func queryString(params map[string]string, keepEmpty bool, exclude ...string) string {
+	skip := make(map[string]bool, len(exclude))
+	for _, k := range exclude {
+		skip[k] = true
+	}
+	keys := make([]string, 0, len(params))
+	for k, val := range params {
+		if (keepEmpty || val != "") && !skip[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(params[k])
+	}
+	return b.String()
+}
+
+// ToParams 将struct或map转为字符串参数，url.Values的多个值以","连接
+func ToParams(v interface{}) map[string]string {
+	switch m := v.(type) {
+	case map[string]string:
+		return m
+	case url.Values:
+		ret := make(map[string]string, len(m))
+		for k, vals := range m {
+			ret[k] = strings.Join(vals, ",")
+		}
+		return ret
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	ret := make(map[string]string)
+	switch rv.Kind() {
+	case reflect.Map:
+		iter := rv.MapRange()
+		for iter.Next() {
+			ret[fmt.Sprint(iter.Key().Interface())] = paramValue(iter.Value())
+		}
+	case reflect.Struct:
+		structParams(rv, ret)
+	}
+	return ret
+}
+
+func structParams(rv reflect.Value, ret map[string]string) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if field.Anonymous && reflect.Indirect(rv.Field(i)).Kind() == reflect.Struct {
+			structParams(reflect.Indirect(rv.Field(i)), ret)
+			continue
+		}
+		name := paramName(field)
+		if name == "-" {
+			continue
+		}
+		ret[name] = paramValue(rv.Field(i))
+	}
+}
+
+func paramName(field reflect.StructField) string {
+	for _, tag := range []string{"form", "json"} {
+		if name := strings.Split(field.Tag.Get(tag), ",")[0]; name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func paramValue(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Slice, reflect.Array:
+		vals := make([]string, v.Len())
+		for i := range vals {
+			vals[i] = paramValue(v.Index(i))
+		}
+		return strings.Join(vals, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// Sign 计算参数的签名，sign参数不参与签名，eg:
+//
+//	params := map[string]string{"app_key": "k", "nonce": utils.Nonce(), "timestamp": "1630000000"}
+//	params["sign"] = utils.Sign(utils.SignHMACSHA256, params, secret)
+func Sign(signType SignType, v interface{}, secret string) string {
+	return signString(signType, QueryString(v, SignKey), secret)
+}
+
+// VerifySign 校验签名，md5签名不区分大小写
+func VerifySign(signType SignType, v interface{}, secret, sign string) bool {
+	return verifyString(signType, Sign(signType, v, secret), sign)
+}
+
+// SignRequest 计算开放接口请求的签名，签名串为 METHOD\npath\nquery，query同QueryString但保留值为空的参数，
+// 避免请求被改到其他接口或增删空参数后签名仍然有效，eg: POST\n/open/order\napp_key=k&nonce=n&remark=&timestamp=1630000000
+func SignRequest(signType SignType, method, path string, v interface{}, secret string) string {
+	s := strings.ToUpper(method) + "\n" + path + "\n" + queryString(ToParams(v), true, SignKey)
+	return signString(signType, s, secret)
+}
+
+// VerifyRequestSign 校验SignRequest计算的签名
+func VerifyRequestSign(signType SignType, method, path string, v interface{}, secret, sign string) bool {
+	return verifyString(signType, SignRequest(signType, method, path, v, secret), sign)
+}
+
+func signString(signType SignType, s, secret string) string {
+	switch signType {
+	case SignMD5:
+		return strings.ToUpper(MD5(s + "&key=" + secret))
+	default:
+		return HMAC(AlgoSha256, s, secret)
+	}
+}
+
+func verifyString(signType SignType, expected, sign string) bool {
+	if signType == SignMD5 {
+		sign = strings.ToUpper(sign)
+	}
+	return SecureCompare(expected, sign)
+}
+
+// Nonce 随机字符串，用于签名防重放
+func Nonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
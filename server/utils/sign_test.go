@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryString(t *testing.T) {
+	type Base struct {
+		AppKey string `json:"app_key"`
+	}
+	type req struct {
+		Base
+		Name    string   `form:"name"`
+		Page    int      `json:"page,omitempty"`
+		Price   float64  `json:"price"`
+		Tags    []string `json:"tags"`
+		Ignore  string   `json:"-"`
+		Empty   string   `json:"empty"`
+		Pointer *int64   `json:"pointer"`
+		Sign    string   `json:"sign"`
+		private string
+	}
+	id := int64(3)
+	r := req{Base: Base{AppKey: "k"}, Name: "a b", Page: 2, Price: 1.5, Tags: []string{"x", "y"}, Ignore: "i", Pointer: &id, Sign: "s", private: "p"}
+	assert.Equal(t, "app_key=k&name=a b&page=2&pointer=3&price=1.5&tags=x,y", QueryString(&r, "sign"))
+
+	assert.Equal(t, "a=1&b=2", QueryString(map[string]interface{}{"b": 2, "a": "1", "c": nil}))
+	assert.Equal(t, "a=1,2&b=3", QueryString(url.Values{"a": {"1", "2"}, "b": {"3"}}))
+}
+
+func TestSign(t *testing.T) {
+	params := map[string]string{"app_key": "k", "nonce": "n", "timestamp": "1630000000", "sign": "ignored"}
+	assert.Equal(t, HMAC(AlgoSha256, "app_key=k&nonce=n&timestamp=1630000000", "secret"), Sign(SignHMACSHA256, params, "secret"))
+	md5Sign := Sign(SignMD5, params, "secret")
+	assert.Equal(t, "2DFEC283D2029EC8D584D118576D38A4", md5Sign)
+
+	assert.True(t, VerifySign(SignMD5, params, "secret", md5Sign))
+	assert.True(t, VerifySign(SignHMACSHA256, params, "secret", Sign(SignHMACSHA256, params, "secret")))
+	assert.False(t, VerifySign(SignHMACSHA256, params, "other", Sign(SignHMACSHA256, params, "secret")))
+
+	assert.Len(t, Nonce(), 32)
+	assert.NotEqual(t, Nonce(), Nonce())
+}
+
+func TestSignRequest(t *testing.T) {
+	params := map[string]string{"app_key": "k", "nonce": "n", "remark": "", "timestamp": "1630000000", "sign": "ignored"}
+	assert.Equal(t, HMAC(AlgoSha256, "POST\n/open/order\napp_key=k&nonce=n&remark=&timestamp=1630000000", "secret"),
+		SignRequest(SignHMACSHA256, "post", "/open/order", params, "secret"))
+
+	sign := SignRequest(SignHMACSHA256, "POST", "/open/order", params, "secret")
+	assert.True(t, VerifyRequestSign(SignHMACSHA256, "POST", "/open/order", params, "secret", sign))
+	assert.False(t, VerifyRequestSign(SignHMACSHA256, "POST", "/open/refund", params, "secret", sign))
+	assert.False(t, VerifyRequestSign(SignHMACSHA256, "GET", "/open/order", params, "secret", sign))
+	delete(params, "remark")
+	assert.False(t, VerifyRequestSign(SignHMACSHA256, "POST", "/open/order", params, "secret", sign))
+}
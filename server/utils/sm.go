@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+
+	"github.com/emmansun/gmsm/sm2"
+	"github.com/emmansun/gmsm/sm3"
+	"github.com/emmansun/gmsm/sm4"
+	"github.com/emmansun/gmsm/smx509"
+)
+
+// AlgoSM3 GM/T 0004-2012 hash algorithm, can be used with Hash, HMAC, HashHex, HMACHex...
+const AlgoSM3 HashAlgo = "sm3"
+
+func init() {
+	hashFuncs[AlgoSM3] = sm3.New
+}
+
+// SM3 calculate the sm3 hash of a string.
+func SM3(s string) string {
+	return HashHex(AlgoSM3, []byte(s))
+}
+
+// NewSM4CBCCrypto returns a new sm4-cbc crypto, key and iv must be 16 bytes
+func NewSM4CBCCrypto(key, iv []byte, mode PaddingMode) AESCrypto {
+	return &cbccrypto{
+		newCipher: sm4.NewCipher,
+		key:       key,
+		iv:        iv,
+		mode:      mode,
+	}
+}
+
+// NewSM4ECBCrypto returns a new sm4-ecb crypto, key must be 16 bytes
+func NewSM4ECBCrypto(key []byte, mode PaddingMode) AESCrypto {
+	return &ecbcrypto{
+		newCipher: sm4.NewCipher,
+		key:       key,
+		mode:      mode,
+	}
+}
+
+// NewSM4GCMCrypto returns a new sm4-gcm crypto, key must be 16 bytes and nonce 12 bytes
+func NewSM4GCMCrypto(key, nonce []byte) AESCrypto {
+	return &gcmcrypto{
+		newCipher: sm4.NewCipher,
+		key:       key,
+		nonce:     nonce,
+	}
+}
+
+// GenerateSM2Key returns sm2 private key in PKCS#8 and public key in PKIX
+func GenerateSM2Key() (privateKey, publicKey []byte, err error) {
+	prvKey, err := sm2.GenerateKey(rand.Reader)
+
+	if err != nil {
+		return
+	}
+
+	pkcs8b, err := smx509.MarshalPKCS8PrivateKey(prvKey)
+
+	if err != nil {
+		return
+	}
+
+	pkixb, err := smx509.MarshalPKIXPublicKey(&prvKey.PublicKey)
+
+	if err != nil {
+		return
+	}
+
+	privateKey = pem.EncodeToMemory(&pem.Block{
+		Type:  string(RSAPKCS8),
+		Bytes: pkcs8b,
+	})
+
+	publicKey = pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: pkixb,
+	})
+
+	return
+}
+
+// SM2Encrypt sm2 encrypt, the cipher text is C1C3C2 with uncompressed C1
+func SM2Encrypt(plainText, publicKey []byte) ([]byte, error) {
+	key, err := parseSM2PublicKey(publicKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sm2.Encrypt(rand.Reader, key, plainText, nil)
+}
+
+// SM2Decrypt sm2 decrypt
+func SM2Decrypt(cipherText, privateKey []byte) ([]byte, error) {
+	key, err := parseSM2PrivateKey(privateKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sm2.Decrypt(key, cipherText)
+}
+
+// SM2Sign returns ASN.1 encoded sm2 signature with sm3 and the default uid "1234567812345678"
+func SM2Sign(data, privateKey []byte) ([]byte, error) {
+	key, err := parseSM2PrivateKey(privateKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return key.SignWithSM2(rand.Reader, nil, data)
+}
+
+// SM2Verify verifies ASN.1 encoded sm2 signature with sm3 and the default uid
+func SM2Verify(data, signature, publicKey []byte) error {
+	key, err := parseSM2PublicKey(publicKey)
+
+	if err != nil {
+		return err
+	}
+
+	if !sm2.VerifyASN1WithSM2(key, nil, data, signature) {
+		return errors.New("yiigo: sm2 verification error")
+	}
+
+	return nil
+}
+
+func parseSM2PrivateKey(privateKey []byte) (*sm2.PrivateKey, error) {
+	block, _ := pem.Decode(privateKey)
+
+	if block == nil {
+		return nil, errors.New("yiigo: invalid sm2 private key")
+	}
+
+	var (
+		key interface{}
+		err error
+	)
+
+	switch block.Type {
+	case string(RSAPKCS8):
+		key, err = smx509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = smx509.ParseSM2PrivateKey(block.Bytes)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	sm2Key, ok := key.(*sm2.PrivateKey)
+
+	if !ok {
+		return nil, errors.New("yiigo: invalid sm2 private key")
+	}
+
+	return sm2Key, nil
+}
+
+func parseSM2PublicKey(publicKey []byte) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode(publicKey)
+
+	if block == nil {
+		return nil, errors.New("yiigo: invalid sm2 public key")
+	}
+
+	pubKey, err := smx509.ParsePKIXPublicKey(block.Bytes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := pubKey.(*ecdsa.PublicKey)
+
+	if !ok || !sm2.IsSM2PublicKey(key) {
+		return nil, errors.New("yiigo: invalid sm2 public key")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSM3(t *testing.T) {
+	// GM/T 0004-2012 example 1
+	assert.Equal(t, "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0", SM3("abc"))
+	assert.Equal(t, SM3("abc"), Hash(AlgoSM3, "abc"))
+	assert.Len(t, HMAC(AlgoSM3, "abc", "key"), 64)
+}
+
+func TestSM4Crypto(t *testing.T) {
+	// GM/T 0002-2012 example 1
+	key, _ := hex.DecodeString("0123456789abcdeffedcba9876543210")
+	ecb := NewSM4ECBCrypto(key, PKCS5)
+	eb, err := ecb.Encrypt(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "681edf34d206965e86b3e94f536e4246", hex.EncodeToString(eb[:16]))
+	db, err := ecb.Decrypt(eb)
+	assert.Nil(t, err)
+	assert.Equal(t, key, db)
+
+	plainText := "Iloveyiigo"
+	for _, mode := range []PaddingMode{ZERO, PKCS5, PKCS7} {
+		cbc := NewSM4CBCCrypto(key, key, mode)
+		eb, err := cbc.Encrypt([]byte(plainText))
+		assert.Nil(t, err)
+		db, err := cbc.Decrypt(eb)
+		assert.Nil(t, err)
+		assert.Equal(t, plainText, string(db))
+	}
+
+	gcm := NewSM4GCMCrypto(key, key[:12])
+	eb, err = gcm.Encrypt([]byte(plainText))
+	assert.Nil(t, err)
+	db, err = gcm.Decrypt(eb)
+	assert.Nil(t, err)
+	assert.Equal(t, plainText, string(db))
+}
+
+func TestSM2(t *testing.T) {
+	privateKey, publicKey, err := GenerateSM2Key()
+	assert.Nil(t, err)
+
+	plainText := "Iloveyiigo"
+	eb, err := SM2Encrypt([]byte(plainText), publicKey)
+	assert.Nil(t, err)
+	db, err := SM2Decrypt(eb, privateKey)
+	assert.Nil(t, err)
+	assert.Equal(t, plainText, string(db))
+
+	signature, err := SM2Sign([]byte(plainText), privateKey)
+	assert.Nil(t, err)
+	assert.Nil(t, SM2Verify([]byte(plainText), signature, publicKey))
+	assert.NotNil(t, SM2Verify([]byte("other"), signature, publicKey))
+
+	rsaPrivate, rsaPublic, _ := GenerateRSAKey(1024, RSAPKCS8)
+	_, err = SM2Encrypt([]byte(plainText), rsaPublic)
+	assert.NotNil(t, err)
+	_, err = SM2Sign([]byte(plainText), rsaPrivate)
+	assert.NotNil(t, err)
+}
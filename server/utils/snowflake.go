@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// snowflake id：1位符号位 + 41位毫秒时间戳 + 10位机器id + 12位序列号，按生成时间递增，
+// 可以使用约69年，每台机器每毫秒最多生成4096个
+const (
+	workerBits   = 10
+	sequenceBits = 12
+
+	MaxWorkerID = 1<<workerBits - 1
+	maxSequence = 1<<sequenceBits - 1
+	timeShift   = workerBits + sequenceBits
+	workerShift = sequenceBits
+	// 时钟回拨超过该值时返回错误，否则等待时钟追上
+	maxClockBack = 5 * time.Millisecond
+)
+
+// IDEpoch 时间戳的起始时间 2021-01-01 00:00:00 UTC
+var IDEpoch = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ErrClockBackwards 系统时钟回拨
+var ErrClockBackwards = errors.New("snowflake: clock moved backwards")
+
+// IDGenerator snowflake id生成器，并发安全，同一时间每个进程的workerID必须唯一
+type IDGenerator struct {
+	mu       sync.Mutex
+	workerID int64
+	epoch    int64
+	lastMs   int64
+	sequence int64
+}
+
+// NewIDGenerator 创建id生成器，workerID取值0~1023，eg:
+//
+//	gen, _ := utils.NewIDGenerator(1)
+//	id, _ := gen.Next()
+func NewIDGenerator(workerID int64) (*IDGenerator, error) {
+	if workerID < 0 || workerID > MaxWorkerID {
+		return nil, fmt.Errorf("snowflake: worker id must be between 0 and %d", MaxWorkerID)
+	}
+	return &IDGenerator{workerID: workerID, epoch: IDEpoch.UnixNano() / int64(time.Millisecond)}, nil
+}
+
+// Next 生成下一个id，同一毫秒的序列号用完时等待下一毫秒
+func (g *IDGenerator) Next() (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	if now < g.lastMs {
+		if time.Duration(g.lastMs-now)*time.Millisecond > maxClockBack {
+			return 0, ErrClockBackwards
+		}
+		now = g.waitUntil(g.lastMs)
+	}
+	if now == g.lastMs {
+		g.sequence = (g.sequence + 1) & maxSequence
+		if g.sequence == 0 {
+			now = g.waitUntil(g.lastMs + 1)
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastMs = now
+	return (now-g.epoch)<<timeShift | g.workerID<<workerShift | g.sequence, nil
+}
+
+// MustNext 生成下一个id，出错时panic
+func (g *IDGenerator) MustNext() int64 {
+	id, err := g.Next()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+func (g *IDGenerator) now() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+func (g *IDGenerator) waitUntil(ms int64) int64 {
+	now := g.now()
+	for now < ms {
+		time.Sleep(100 * time.Microsecond)
+		now = g.now()
+	}
+	return now
+}
+
+// IDTime id的生成时间
+func IDTime(id int64) time.Time {
+	ms := id>>timeShift + IDEpoch.UnixNano()/int64(time.Millisecond)
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+// IDWorker id的机器id
+func IDWorker(id int64) int64 {
+	return id >> workerShift & MaxWorkerID
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIDGenerator(t *testing.T) {
+	_, err := NewIDGenerator(-1)
+	assert.Error(t, err)
+	_, err = NewIDGenerator(MaxWorkerID + 1)
+	assert.Error(t, err)
+	_, err = NewIDGenerator(MaxWorkerID)
+	assert.Nil(t, err)
+}
+
+func TestIDGeneratorNext(t *testing.T) {
+	gen, _ := NewIDGenerator(7)
+	last := int64(0)
+	for i := 0; i < 10000; i++ {
+		id, err := gen.Next()
+		assert.Nil(t, err)
+		assert.Greater(t, id, last)
+		last = id
+	}
+	assert.Equal(t, int64(7), IDWorker(last))
+	assert.WithinDuration(t, time.Now(), IDTime(last), time.Second)
+}
+
+func TestIDGeneratorConcurrent(t *testing.T) {
+	gen, _ := NewIDGenerator(1)
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		seen = make(map[int64]bool)
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				id := gen.MustNext()
+				mu.Lock()
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, 8000)
+}
+
+func TestIDGeneratorClockBackwards(t *testing.T) {
+	gen, _ := NewIDGenerator(1)
+	gen.lastMs = gen.now() + int64(time.Second/time.Millisecond)
+	_, err := gen.Next()
+	assert.Equal(t, ErrClockBackwards, err)
+}
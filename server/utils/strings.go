@@ -1,14 +1,9 @@
 package utils
 
 import (
-	"crypto/hmac"
-	"crypto/md5"
-	"crypto/sha1"
-	"crypto/sha256"
-	"crypto/sha512"
-	"encoding/hex"
-	"hash"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // HashAlgo hash algorithm
@@ -25,70 +20,28 @@ const (
 
 // MD5 calculate the md5 hash of a string.
 func MD5(s string) string {
-	h := md5.New()
-	h.Write([]byte(s))
-
-	return hex.EncodeToString(h.Sum(nil))
+	return MD5Hex([]byte(s))
 }
 
 // SHA1 calculate the sha1 hash of a string.
 func SHA1(s string) string {
-	h := sha1.New()
-	h.Write([]byte(s))
-
-	return hex.EncodeToString(h.Sum(nil))
+	return SHA1Hex([]byte(s))
 }
 
 // Hash Generate a hash value, expects: MD5, SHA1, SHA224, SHA256, SHA384, SHA512.
 func Hash(algo HashAlgo, s string) string {
-	var h hash.Hash
-
-	switch algo {
-	case AlgoMD5:
-		h = md5.New()
-	case AlgoSha1:
-		h = sha1.New()
-	case AlgoSha224:
-		h = sha256.New224()
-	case AlgoSha256:
-		h = sha256.New()
-	case AlgoSha384:
-		h = sha512.New384()
-	case AlgoSha512:
-		h = sha512.New()
-	default:
+	if _, ok := hashFuncs[algo]; !ok {
 		return s
 	}
-
-	h.Write([]byte(s))
-
-	return hex.EncodeToString(h.Sum(nil))
+	return HashHex(algo, []byte(s))
 }
 
 // HMAC Generate a keyed hash value, expects: MD5, SHA1, SHA224, SHA256, SHA384, SHA512.
 func HMAC(algo HashAlgo, s, key string) string {
-	var mac hash.Hash
-
-	switch algo {
-	case AlgoMD5:
-		mac = hmac.New(md5.New, []byte(key))
-	case AlgoSha1:
-		mac = hmac.New(sha1.New, []byte(key))
-	case AlgoSha224:
-		mac = hmac.New(sha256.New224, []byte(key))
-	case AlgoSha256:
-		mac = hmac.New(sha256.New, []byte(key))
-	case AlgoSha384:
-		mac = hmac.New(sha512.New384, []byte(key))
-	case AlgoSha512:
-		mac = hmac.New(sha512.New, []byte(key))
-	default:
+	if _, ok := hashFuncs[algo]; !ok {
 		return s
 	}
-
-	mac.Write([]byte(s))
-
-	return hex.EncodeToString(mac.Sum(nil))
+	return HMACHex(algo, []byte(s), []byte(key))
 }
 
 // AddSlashes returns a string with backslashes added before characters that need to be escaped.
@@ -149,3 +102,98 @@ func QuoteMeta(s string) string {
 
 	return builder.String()
 }
+
+// Slugify 生成url友好的slug，只保留小写字母和数字，其他字符转为"-"，eg: "Hello, Gin 1.6!" => "hello-gin-1-6"
+func Slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// SnakeCase 驼峰转下划线，连续的大写字母视为一个单词，eg: "UserID" => "user_id", "HTTPServer" => "http_server"
+func SnakeCase(s string) string {
+	return delimitCase(s, '_')
+}
+
+// KebabCase 驼峰转中划线，eg: "UserName" => "user-name"
+func KebabCase(s string) string {
+	return delimitCase(s, '-')
+}
+
+func delimitCase(s string, sep rune) string {
+	runes := []rune(strings.TrimSpace(s))
+	var b strings.Builder
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == ' ' {
+			if b.Len() > 0 && !strings.HasSuffix(b.String(), string(sep)) {
+				b.WriteRune(sep)
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 && b.Len() > 0 && !strings.HasSuffix(b.String(), string(sep)) {
+			prev := runes[i-1]
+			// aB => a_b，ABc => a_bc
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteRune(sep)
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return strings.TrimSuffix(b.String(), string(sep))
+}
+
+// CamelCase 下划线、中划线或空格分隔的单词转大驼峰，eg: "user_id" => "UserId"
+func CamelCase(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range strings.TrimSpace(s) {
+		if r == '_' || r == '-' || r == ' ' {
+			upper = true
+			continue
+		}
+		if upper {
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// LowerCamelCase 转小驼峰，eg: "user_id" => "userId"
+func LowerCamelCase(s string) string {
+	s = CamelCase(s)
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+// Truncate 按字符截断，超出时末尾加上ellipsis(默认...)，结果包括ellipsis不超过n个字符
+func Truncate(s string, n int, ellipsis ...string) string {
+	suffix := "..."
+	if len(ellipsis) > 0 {
+		suffix = ellipsis[0]
+	}
+	runes := []rune(s)
+	if n < 0 || len(runes) <= n {
+		return s
+	}
+	keep := n - utf8.RuneCountInString(suffix)
+	if keep <= 0 {
+		return string(runes[:n])
+	}
+	return string(runes[:keep]) + suffix
+}
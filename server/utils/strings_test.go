@@ -71,3 +71,32 @@ func TestStripSlashes(t *testing.T) {
 func TestQuoteMeta(t *testing.T) {
 	assert.Equal(t, `Hello world\. \(can you hear me\?\)`, QuoteMeta("Hello world. (can you hear me?)"))
 }
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "hello-gin-1-6", Slugify("Hello, Gin 1.6!"))
+	assert.Equal(t, "go-web", Slugify("  Go   Web  "))
+	assert.Equal(t, "gin", Slugify("gin 入门"))
+	assert.Equal(t, "", Slugify("你好"))
+}
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, "user_id", SnakeCase("UserID"))
+	assert.Equal(t, "http_server", SnakeCase("HTTPServer"))
+	assert.Equal(t, "user_name", SnakeCase("userName"))
+	assert.Equal(t, "user_name", SnakeCase("user_name"))
+	assert.Equal(t, "user-name", KebabCase("UserName"))
+}
+
+func TestCamelCase(t *testing.T) {
+	assert.Equal(t, "UserId", CamelCase("user_id"))
+	assert.Equal(t, "UserName", CamelCase("user-name"))
+	assert.Equal(t, "userId", LowerCamelCase("user_id"))
+	assert.Equal(t, "", LowerCamelCase(""))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "你好", Truncate("你好", 5))
+	assert.Equal(t, "你好...", Truncate("你好世界欢迎", 5))
+	assert.Equal(t, "你好世…", Truncate("你好世界欢迎", 4, "…"))
+	assert.Equal(t, "你好", Truncate("你好世界", 2))
+}
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LocationKey 时区在gin.Context中的key
+const LocationKey = "location"
+
+type locationCtxKey struct{}
+
+// LocationResolver 延迟确定时区，eg: 登录后按用户资料中的时区
+type LocationResolver interface {
+	Location() *time.Location
+}
+
+var locations sync.Map
+
+// LoadLocation 按IANA名称加载时区并缓存，eg: Asia/Shanghai，为空或Local时返回time.Local
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return time.Local, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// WithLocation 将时区保存到context，loc可以是*time.Location或LocationResolver
+func WithLocation(ctx context.Context, loc interface{}) context.Context {
+	return context.WithValue(ctx, locationCtxKey{}, loc)
+}
+
+// LocationFrom 获取context中的时区，支持gin.Context和request.Context()，没有时返回time.Local
+func LocationFrom(ctx context.Context) *time.Location {
+	if ctx != nil {
+		v := ctx.Value(locationCtxKey{})
+		if v == nil {
+			v = ctx.Value(LocationKey)
+		}
+		switch loc := v.(type) {
+		case *time.Location:
+			if loc != nil {
+				return loc
+			}
+		case LocationResolver:
+			if l := loc.Location(); l != nil {
+				return l
+			}
+		}
+	}
+	return time.Local
+}
+
+// DateIn 同Date，按指定时区格式化
+func DateIn(timestamp int64, loc *time.Location, layout ...string) string {
+	l := "2006-01-02 15:04:05"
+
+	if len(layout) != 0 {
+		l = layout[0]
+	}
+
+	return time.Unix(timestamp, 0).In(loc).Format(l)
+}
+
+// DateInZone 同Date，按IANA名称指定时区，时区不存在时返回空字符串
+func DateInZone(timestamp int64, name string, layout ...string) string {
+	loc, err := LoadLocation(name)
+	if err != nil {
+		zap.L().Error("load location failed", zap.String("name", name), zap.Error(err))
+
+		return ""
+	}
+
+	return DateIn(timestamp, loc, layout...)
+}
+
+// StrToTimeIn 同StrToTime，按指定时区解析
+func StrToTimeIn(datetime string, loc *time.Location, layout ...string) int64 {
+	l := "2006-01-02 15:04:05"
+
+	if len(layout) != 0 {
+		l = layout[0]
+	}
+
+	t, err := time.ParseInLocation(l, datetime, loc)
+
+	// mismatch layout
+	if err != nil {
+		zap.L().Error("parse layout mismatch", zap.Error(err))
+
+		return 0
+	}
+
+	return t.Unix()
+}
+
+// StrToTimeInZone 同StrToTime，按IANA名称指定时区，时区不存在时返回0
+func StrToTimeInZone(datetime string, name string, layout ...string) int64 {
+	loc, err := LoadLocation(name)
+	if err != nil {
+		zap.L().Error("load location failed", zap.String("name", name), zap.Error(err))
+
+		return 0
+	}
+
+	return StrToTimeIn(datetime, loc, layout...)
+}
+
+// StartOfDay 返回t所在时区当天的0点
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// EndOfDay 返回t所在时区当天的23:59:59.999999999
+func EndOfDay(t time.Time) time.Time {
+	return StartOfDay(t).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// StartOfMonth 返回t所在时区当月1日的0点
+func StartOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// EndOfMonth 返回t所在时区当月最后一天的23:59:59.999999999
+func EndOfMonth(t time.Time) time.Time {
+	return StartOfMonth(t).AddDate(0, 1, 0).Add(-time.Nanosecond)
+}
+
+// MonthAround 返回当前月的第一天和最后一天的日期
+func MonthAround(t time.Time) (first, last string) {
+	first = StartOfMonth(t).Format("20060102")
+	last = EndOfMonth(t).Format("20060102")
+
+	return
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateInZone(t *testing.T) {
+	assert.Equal(t, "2016-03-19 07:03:19", DateInZone(1458370999, "UTC"))
+	assert.Equal(t, "2016-03-19 16:03:19", DateInZone(1458370999, "Asia/Tokyo"))
+	assert.Equal(t, "", DateInZone(1458370999, "Mars/Olympus"))
+}
+
+func TestStrToTimeInZone(t *testing.T) {
+	assert.Equal(t, int64(1562939119), StrToTimeInZone("2019-07-12 13:45:19", "UTC"))
+	assert.Equal(t, StrToTime("2019-07-12 13:45:19"), StrToTimeIn("2019-07-12 13:45:19", time.Local))
+	assert.Equal(t, int64(0), StrToTimeInZone("2019-07-12 13:45:19", "Mars/Olympus"))
+}
+
+func TestStartEndOfDay(t *testing.T) {
+	loc, err := LoadLocation("America/New_York")
+	assert.Nil(t, err)
+
+	now := time.Date(2021, 3, 14, 15, 4, 5, 0, loc)
+	assert.Equal(t, time.Date(2021, 3, 14, 0, 0, 0, 0, loc), StartOfDay(now))
+	assert.Equal(t, time.Date(2021, 3, 14, 23, 59, 59, 999999999, loc), EndOfDay(now))
+}
+
+func TestMonthAround(t *testing.T) {
+	first, last := MonthAround(time.Date(2020, 2, 12, 0, 0, 0, 0, time.Local))
+
+	assert.Equal(t, "20200201", first)
+	assert.Equal(t, "20200229", last)
+}
+
+func TestLocationFrom(t *testing.T) {
+	assert.Equal(t, time.Local, LocationFrom(context.Background()))
+
+	loc, _ := LoadLocation("UTC")
+	assert.Equal(t, loc, LocationFrom(WithLocation(context.Background(), loc)))
+}
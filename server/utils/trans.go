@@ -17,7 +17,10 @@ import (
 // 定义一个全局翻译器T
 var Trans ut.Translator
 
-// InitTrans 初始化翻译器
+// translators gin校验器按语言注册的翻译器
+var translators = map[string]ut.Translator{}
+
+// InitTrans 初始化翻译器，gin的校验器同时注册中文和英文的翻译，locale为默认使用的语言
 func InitTrans(locale string) (err error) {
 	// 修改gin框架中的Validator引擎属性，实现自定制
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
@@ -31,47 +34,73 @@ func InitTrans(locale string) (err error) {
 			return name
 		})
 
-		zhT := zh.New() // 中文翻译器
-		enT := en.New() // 英文翻译器
-
-		// 第一个参数是备用（fallback）的语言环境
-		// 后面的参数是应该支持的语言环境（支持多个）
-		// uni := ut.New(zhT, zhT) 也是可以的
-		uni := ut.New(enT, zhT, enT)
+		if translators, err = registerTranslations(v); err != nil {
+			return err
+		}
 
-		// locale 通常取决于 http 请求头的 'Accept-Language'
 		var ok bool
-		// 也可以使用 uni.FindTranslator(...) 传入多个locale进行查找
-		Trans, ok = uni.GetTranslator(locale)
-		if !ok {
+		if Trans, ok = translators[locale]; !ok {
 			return fmt.Errorf("uni.GetTranslator(%s) failed", locale)
 		}
+		return
+	}
+	return
+}
 
-		// 注册翻译器
-		switch locale {
-		case "en":
-			err = enTranslations.RegisterDefaultTranslations(v, Trans)
-		case "zh":
-			err = zhTranslations.RegisterDefaultTranslations(v, Trans)
-		default:
-			err = enTranslations.RegisterDefaultTranslations(v, Trans)
-		}
-		if err != nil {
-			return err
+// GetTrans 按语言获取gin校验器的翻译器，eg: zh, zh-CN, en-US，不支持的语言返回Trans
+func GetTrans(locale string) ut.Translator {
+	return findTranslator(translators, locale, Trans)
+}
+
+// registerTranslations 注册中文、英文的默认翻译及自定义规则的翻译
+func registerTranslations(v *validator.Validate) (map[string]ut.Translator, error) {
+	zhT := zh.New() // 中文翻译器
+	enT := en.New() // 英文翻译器
+
+	// 第一个参数是备用（fallback）的语言环境
+	// 后面的参数是应该支持的语言环境（支持多个）
+	uni := ut.New(enT, zhT, enT)
+
+	result := make(map[string]ut.Translator, 2)
+	for locale, register := range map[string]func(*validator.Validate, ut.Translator) error{
+		"zh": zhTranslations.RegisterDefaultTranslations,
+		"en": enTranslations.RegisterDefaultTranslations,
+	} {
+		trans, _ := uni.GetTranslator(locale)
+		if err := register(v, trans); err != nil {
+			return nil, err
 		}
 
 		//注册自定义验证方法
-		if err := v.RegisterTranslation("myValidate",
-			Trans,
-			registerTranslator("myvalidate", "{0}必须要晚于当前日期"),
-			translate,
-		); err != nil {
-			return err
+		msg := "{0}必须要晚于当前日期"
+		if locale == "en" {
+			msg = "{0} must be later than now"
+		}
+		if err := v.RegisterTranslation("myValidate", trans, registerTranslator("myvalidate", msg), translate); err != nil {
+			return nil, err
 		}
 
-		return
+		//注册内置的手机号、身份证、银行卡等验证规则
+		if err := registerRules(v, trans, builtinRules...); err != nil {
+			return nil, err
+		}
+		result[locale] = trans
 	}
-	return
+	return result, nil
+}
+
+// findTranslator 按语言的前缀查找翻译器，eg: zh-CN使用zh
+func findTranslator(m map[string]ut.Translator, locale string, fallback ut.Translator) ut.Translator {
+	locale = strings.ToLower(strings.Replace(locale, "_", "-", -1))
+	if t, ok := m[locale]; ok {
+		return t
+	}
+	if i := strings.Index(locale, "-"); i > 0 {
+		if t, ok := m[locale[:i]]; ok {
+			return t
+		}
+	}
+	return fallback
 }
 
 // registerTranslator 为自定义字段添加翻译功能
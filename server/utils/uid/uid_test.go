@@ -0,0 +1,100 @@
+package uid
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUUID(t *testing.T) {
+	u := NewV4()
+	assert.Equal(t, 4, u.Version())
+	assert.Equal(t, byte(0x80), u[8]&0xc0)
+	assert.True(t, IsUUID(u.String()))
+	assert.True(t, IsUUID(strings.ReplaceAll(u.String(), "-", "")))
+
+	v := NewV7()
+	assert.Equal(t, 7, v.Version())
+	assert.False(t, v.Time().IsZero())
+	assert.True(t, NewV7().String() >= v.String())
+
+	parsed, err := ParseUUID("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	assert.Nil(t, err)
+	assert.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", parsed.String())
+	assert.Equal(t, 1, parsed.Version())
+
+	for _, s := range []string{"", "6ba7b810-9dad-11d1-80b4", "6ba7b8109dad-11d1-80b4-00c04fd430c8-", "zba7b810-9dad-11d1-80b4-00c04fd430c8"} {
+		assert.False(t, IsUUID(s), s)
+	}
+}
+
+func TestUUIDSQLAndJSON(t *testing.T) {
+	u := NewV7()
+	value, _ := u.Value()
+	assert.Len(t, value, 16)
+
+	var scanned UUID
+	assert.Nil(t, scanned.Scan(value))
+	assert.Equal(t, u, scanned)
+	assert.Nil(t, scanned.Scan(u.String()))
+	assert.Equal(t, u, scanned)
+	assert.Nil(t, scanned.Scan(nil))
+	assert.True(t, scanned.IsNil())
+	assert.Error(t, scanned.Scan(1))
+
+	value, _ = Nil.Value()
+	assert.Nil(t, value)
+
+	b, _ := json.Marshal(struct{ ID UUID }{u})
+	assert.Equal(t, `{"ID":"`+u.String()+`"}`, string(b))
+	var got struct{ ID UUID }
+	assert.Nil(t, json.Unmarshal(b, &got))
+	assert.Equal(t, u, got.ID)
+	assert.Error(t, json.Unmarshal([]byte(`{"ID":"x"}`), &got))
+}
+
+func TestULID(t *testing.T) {
+	u, err := ParseULID("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	assert.Nil(t, err)
+	assert.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV", u.String())
+	assert.Equal(t, int64(1469922850259), u.Time().UnixNano()/1e6)
+
+	lower, err := ParseULID("01arz3ndektsv4rrffq69g5fav")
+	assert.Nil(t, err)
+	assert.Equal(t, u, lower)
+
+	for _, s := range []string{"", "01ARZ3NDEKTSV4RRFFQ69G5FA", "81ARZ3NDEKTSV4RRFFQ69G5FAV", "01ARZ3NDEKTSV4RRFFQ69G5FAU"} {
+		assert.False(t, IsULID(s), s)
+	}
+
+	// 同一毫秒内单调递增
+	last := NewULID()
+	for i := 0; i < 1000; i++ {
+		next := NewULID()
+		assert.True(t, next.String() > last.String())
+		parsed, err := ParseULID(next.String())
+		assert.Nil(t, err)
+		assert.Equal(t, next, parsed)
+		last = next
+	}
+}
+
+func TestULIDSQLAndJSON(t *testing.T) {
+	u := NewULID()
+	value, _ := u.Value()
+	var scanned ULID
+	assert.Nil(t, scanned.Scan(value))
+	assert.Equal(t, u, scanned)
+	assert.Nil(t, scanned.Scan([]byte(u.String())))
+	assert.Equal(t, u, scanned)
+
+	b, _ := json.Marshal(u)
+	assert.Equal(t, `"`+u.String()+`"`, string(b))
+	var got ULID
+	assert.Nil(t, json.Unmarshal(b, &got))
+	assert.Equal(t, u, got)
+	assert.Nil(t, json.Unmarshal([]byte(`""`), &got))
+	assert.True(t, got.IsZero())
+}
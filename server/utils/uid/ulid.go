@@ -0,0 +1,206 @@
+package uid
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// crockford base32，不含I、L、O、U
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ErrInvalidULID 不是合法的ulid
+var ErrInvalidULID = errors.New("uid: invalid ulid")
+
+// ULID 48位毫秒时间戳 + 80位随机数，字符串为26位crockford base32，按字典序即按生成时间排序
+type ULID [16]byte
+
+var (
+	ulidMu   sync.Mutex
+	lastULID ULID
+	ulidDec  [256]byte
+)
+
+func init() {
+	for i := range ulidDec {
+		ulidDec[i] = 0xff
+	}
+	for i := 0; i < len(ulidAlphabet); i++ {
+		c := ulidAlphabet[i]
+		ulidDec[c] = byte(i)
+		if c >= 'A' {
+			ulidDec[c+'a'-'A'] = byte(i)
+		}
+	}
+	// 容易混淆的字符按crockford规范转换
+	for c, v := range map[byte]byte{'I': 1, 'i': 1, 'L': 1, 'l': 1, 'O': 0, 'o': 0} {
+		ulidDec[c] = v
+	}
+}
+
+// NewULID 生成ulid，同一毫秒内生成的ulid随机部分递增，保证进程内单调递增
+func NewULID() ULID {
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	var u ULID
+	for i := 0; i < 6; i++ {
+		u[i] = byte(ms >> (40 - 8*i))
+	}
+
+	ulidMu.Lock()
+	defer ulidMu.Unlock()
+	if ms <= lastULID.ms() {
+		u = lastULID
+		if !u.increment() {
+			// 随机部分溢出的概率可以忽略，溢出时重新取随机数
+			randomBytes(u[6:])
+		}
+	} else {
+		randomBytes(u[6:])
+	}
+	lastULID = u
+	return u
+}
+
+func (u *ULID) increment() bool {
+	for i := 15; i >= 6; i-- {
+		u[i]++
+		if u[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (u ULID) ms() uint64 {
+	var ms uint64
+	for i := 0; i < 6; i++ {
+		ms = ms<<8 | uint64(u[i])
+	}
+	return ms
+}
+
+// ParseULID 解析ulid，不区分大小写
+func ParseULID(s string) (ULID, error) {
+	var u ULID
+	if len(s) != 26 {
+		return u, ErrInvalidULID
+	}
+	// 26*5=130位，第一个字符最大为7
+	var d [26]byte
+	for i := 0; i < 26; i++ {
+		if d[i] = ulidDec[s[i]]; d[i] == 0xff {
+			return u, ErrInvalidULID
+		}
+	}
+	if d[0] > 7 {
+		return u, ErrInvalidULID
+	}
+	var acc uint32
+	bits, j := 0, 15
+	for i := 25; i >= 0; i-- {
+		acc |= uint32(d[i]) << bits
+		bits += 5
+		for bits >= 8 && j >= 0 {
+			u[j] = byte(acc)
+			acc >>= 8
+			bits -= 8
+			j--
+		}
+	}
+	return u, nil
+}
+
+// IsULID 是否为合法的ulid
+func IsULID(s string) bool {
+	_, err := ParseULID(s)
+	return err == nil
+}
+
+// Time ulid的生成时间
+func (u ULID) Time() time.Time {
+	return msTime(u.ms())
+}
+
+func (u ULID) IsZero() bool {
+	return u == ULID{}
+}
+
+func (u ULID) String() string {
+	var buf [26]byte
+	var acc uint32
+	bits, j := 0, 25
+	for i := 15; i >= 0; i-- {
+		acc |= uint32(u[i]) << bits
+		bits += 8
+		for bits >= 5 {
+			buf[j] = ulidAlphabet[acc&31]
+			acc >>= 5
+			bits -= 5
+			j--
+		}
+	}
+	buf[0] = ulidAlphabet[acc&31]
+	return string(buf[:])
+}
+
+// GormDataType 数据库中的类型
+func (ULID) GormDataType() string {
+	return "binary(16)"
+}
+
+// Value 存为16字节，零值为NULL
+func (u ULID) Value() (driver.Value, error) {
+	if u.IsZero() {
+		return nil, nil
+	}
+	return u[:], nil
+}
+
+// Scan 支持binary(16)及char(26)类型的列
+func (u *ULID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*u = ULID{}
+		return nil
+	case []byte:
+		if len(v) == 16 {
+			copy(u[:], v)
+			return nil
+		}
+		return u.parse(string(v))
+	case string:
+		return u.parse(v)
+	}
+	return fmt.Errorf("uid: cannot scan %T into ULID", src)
+}
+
+func (u *ULID) parse(s string) error {
+	parsed, err := ParseULID(s)
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+func (u ULID) MarshalJSON() ([]byte, error) {
+	if u.IsZero() {
+		return []byte(`""`), nil
+	}
+	return json.Marshal(u.String())
+}
+
+func (u *ULID) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*u = ULID{}
+		return nil
+	}
+	return u.parse(s)
+}
@@ -0,0 +1,184 @@
+package uid
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// UUID/ULID的生成、解析及数据库类型：数据库中存为BINARY(16)，json中为标准字符串格式，eg:
+//
+//	type Order struct {
+//		ID  uid.UUID `gorm:"primarykey"`
+//		Key uid.ULID `gorm:"uniqueIndex"`
+//	}
+//	order := Order{ID: uid.NewV7(), Key: uid.NewULID()}
+//
+// 零值存为NULL，json中为空字符串
+
+// ErrInvalidUUID 不是合法的uuid
+var ErrInvalidUUID = errors.New("uid: invalid uuid")
+
+// UUID 16字节uuid，字符串格式为 xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+type UUID [16]byte
+
+// Nil 零值uuid
+var Nil UUID
+
+// NewV4 随机uuid
+func NewV4() UUID {
+	var u UUID
+	randomBytes(u[:])
+	u.setVersion(4)
+	return u
+}
+
+// NewV7 以毫秒时间戳开头的uuid，按生成时间递增，作为主键时插入性能比v4好
+func NewV7() UUID {
+	var u UUID
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], ms)
+	copy(u[:6], ts[2:])
+	randomBytes(u[6:])
+	u.setVersion(7)
+	return u
+}
+
+func (u *UUID) setVersion(v byte) {
+	u[6] = u[6]&0x0f | v<<4
+	// RFC 4122 variant
+	u[8] = u[8]&0x3f | 0x80
+}
+
+// ParseUUID 解析uuid，支持带或不带"-"的格式
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	switch len(s) {
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return u, ErrInvalidUUID
+		}
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	case 32:
+	default:
+		return u, ErrInvalidUUID
+	}
+	if _, err := hex.Decode(u[:], []byte(s)); err != nil {
+		return u, ErrInvalidUUID
+	}
+	return u, nil
+}
+
+// IsUUID 是否为合法的uuid
+func IsUUID(s string) bool {
+	_, err := ParseUUID(s)
+	return err == nil
+}
+
+// Version uuid的版本号
+func (u UUID) Version() int {
+	return int(u[6] >> 4)
+}
+
+// Time v7 uuid的生成时间，其他版本返回零值
+func (u UUID) Time() time.Time {
+	if u.Version() != 7 {
+		return time.Time{}
+	}
+	var ts [8]byte
+	copy(ts[2:], u[:6])
+	return msTime(binary.BigEndian.Uint64(ts[:]))
+}
+
+func (u UUID) IsNil() bool {
+	return u == Nil
+}
+
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[:8], u[:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// GormDataType 数据库中的类型
+func (UUID) GormDataType() string {
+	return "binary(16)"
+}
+
+// Value 存为16字节，零值为NULL
+func (u UUID) Value() (driver.Value, error) {
+	if u.IsNil() {
+		return nil, nil
+	}
+	return u[:], nil
+}
+
+// Scan 支持binary(16)及char(36)、char(32)类型的列
+func (u *UUID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*u = Nil
+		return nil
+	case []byte:
+		if len(v) == 16 {
+			copy(u[:], v)
+			return nil
+		}
+		return u.parse(string(v))
+	case string:
+		return u.parse(v)
+	}
+	return fmt.Errorf("uid: cannot scan %T into UUID", src)
+}
+
+func (u *UUID) parse(s string) error {
+	parsed, err := ParseUUID(s)
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+func (u UUID) MarshalJSON() ([]byte, error) {
+	if u.IsNil() {
+		return []byte(`""`), nil
+	}
+	return json.Marshal(u.String())
+}
+
+func (u *UUID) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*u = Nil
+		return nil
+	}
+	return u.parse(s)
+}
+
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Errorf("uid: read random bytes: %w", err))
+	}
+}
+
+func msTime(ms uint64) time.Time {
+	return time.Unix(0, int64(ms)*int64(time.Millisecond))
+}